@@ -75,13 +75,17 @@ func provideCleanup(
 	opsAlertEvaluator *service.OpsAlertEvaluatorService,
 	opsCleanup *service.OpsCleanupService,
 	opsScheduledReport *service.OpsScheduledReportService,
+	opsNotification *service.OpsNotificationService,
+	payloadCapture *service.PayloadCaptureService,
 	opsSystemLogSink *service.OpsSystemLogSink,
+	logShipping *service.LogShippingService,
 	soraMediaCleanup *service.SoraMediaCleanupService,
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
+	batchSvc *service.BatchService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
@@ -126,6 +130,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"LogShippingService", func() error {
+				if logShipping != nil {
+					logShipping.Stop()
+				}
+				return nil
+			}},
 			{"SoraMediaCleanupService", func() error {
 				if soraMediaCleanup != nil {
 					soraMediaCleanup.Stop()
@@ -138,6 +148,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"OpsNotificationService", func() error {
+				if opsNotification != nil {
+					opsNotification.Stop()
+				}
+				return nil
+			}},
+			{"PayloadCaptureService", func() error {
+				if payloadCapture != nil {
+					payloadCapture.Stop()
+				}
+				return nil
+			}},
 			{"OpsAggregationService", func() error {
 				if opsAggregation != nil {
 					opsAggregation.Stop()
@@ -162,6 +184,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"BatchService", func() error {
+				if batchSvc != nil {
+					batchSvc.Stop()
+				}
+				return nil
+			}},
 			{"IdempotencyCleanupService", func() error {
 				if idempotencyCleanup != nil {
 					idempotencyCleanup.Stop()
@@ -44,6 +44,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		return nil, err
 	}
 	userRepository := repository.NewUserRepository(client, db)
+	balanceLedgerRepository := repository.NewBalanceLedgerRepository(db)
 	redeemCodeRepository := repository.NewRedeemCodeRepository(client)
 	redisClient := repository.ProvideRedis(configConfig)
 	_ = repository.InitRedisHealth(redisClient) // 启动 Redis 健康检测（后台 PING，后端自动降级）
@@ -66,13 +67,13 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository, groupRepository, userSubscriptionRepository, userGroupRateRepository, apiKeyCache, configConfig)
 	apiKeyService.SetRateLimitCacheInvalidator(billingCache)
 	apiKeyAuthCacheInvalidator := service.ProvideAPIKeyAuthCacheInvalidator(apiKeyService)
-	promoService := service.NewPromoService(promoCodeRepository, userRepository, billingCacheService, client, apiKeyAuthCacheInvalidator)
+	promoService := service.NewPromoService(promoCodeRepository, userRepository, balanceLedgerRepository, billingCacheService, client, apiKeyAuthCacheInvalidator)
 	subscriptionService := service.NewSubscriptionService(groupRepository, userSubscriptionRepository, billingCacheService, client, configConfig)
 	authService := service.NewAuthService(client, userRepository, redeemCodeRepository, refreshTokenCache, configConfig, settingService, emailService, turnstileService, emailQueueService, promoService, subscriptionService)
 	userService := service.NewUserService(userRepository, apiKeyAuthCacheInvalidator, billingCache)
 	redeemCache := repository.NewRedeemCache(redisClient)
 	subscriptionPlanRepository := repository.NewSubscriptionPlanRepository(client)
-	redeemService := service.NewRedeemService(redeemCodeRepository, userRepository, subscriptionService, subscriptionPlanRepository, redeemCache, billingCacheService, client, apiKeyAuthCacheInvalidator, userGroupRateRepository)
+	redeemService := service.NewRedeemService(redeemCodeRepository, userRepository, balanceLedgerRepository, subscriptionService, subscriptionPlanRepository, redeemCache, billingCacheService, client, apiKeyAuthCacheInvalidator, userGroupRateRepository)
 	secretEncryptor, err := repository.NewAESEncryptor(configConfig)
 	if err != nil {
 		return nil, err
@@ -87,6 +88,8 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	usageService := service.NewUsageService(usageLogRepository, userRepository, client, apiKeyAuthCacheInvalidator)
 	usageHandler := handler.NewUsageHandler(usageService, apiKeyService)
 	redeemHandler := handler.NewRedeemHandler(redeemService)
+	paymentRepository := repository.NewPaymentRepository(db)
+	paymentService := service.NewPaymentService(configConfig, paymentRepository, userRepository, balanceLedgerRepository, subscriptionService, subscriptionPlanRepository, billingCacheService, client, apiKeyAuthCacheInvalidator)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
 	announcementRepository := repository.NewAnnouncementRepository(client)
 	announcementReadRepository := repository.NewAnnouncementReadRepository(client)
@@ -108,7 +111,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	proxyExitInfoProber := repository.NewProxyExitInfoProber(configConfig)
 	proxyLatencyCache := repository.NewProxyLatencyCache(redisClient)
 	privacyClientFactory := providePrivacyClientFactory()
-	adminService := service.NewAdminService(userRepository, groupRepository, accountRepository, soraAccountRepository, proxyRepository, apiKeyRepository, redeemCodeRepository, userGroupRateRepository, billingCacheService, proxyExitInfoProber, proxyLatencyCache, apiKeyAuthCacheInvalidator, client, settingService, subscriptionService, userSubscriptionRepository, privacyClientFactory)
+	adminService := service.NewAdminService(userRepository, groupRepository, accountRepository, soraAccountRepository, proxyRepository, apiKeyRepository, redeemCodeRepository, balanceLedgerRepository, userGroupRateRepository, billingCacheService, proxyExitInfoProber, proxyLatencyCache, apiKeyAuthCacheInvalidator, client, settingService, subscriptionService, userSubscriptionRepository, privacyClientFactory)
 	concurrencyCache := repository.ProvideConcurrencyCache(redisClient, configConfig)
 	concurrencyService := service.ProvideConcurrencyService(concurrencyCache, accountRepository, configConfig)
 	adminUserHandler := admin.NewUserHandler(adminService, concurrencyService)
@@ -132,7 +135,8 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	accountThrottleCache := repository.NewAccountThrottleCache(redisClient)
 	accountThrottleCounterCache := repository.NewAccountThrottleCounterCache(redisClient)
 	accountThrottleService := service.NewAccountThrottleService(accountThrottleRepository, accountThrottleCache, accountThrottleCounterCache)
-	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, settingService, compositeTokenCacheInvalidator, accountThrottleService)
+	accountHealthStore := repository.NewAccountHealthStore(redisClient)
+	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, settingService, compositeTokenCacheInvalidator, accountThrottleService, accountHealthStore)
 	httpUpstream := repository.NewHTTPUpstream(configConfig)
 	claudeUsageFetcher := repository.NewClaudeUsageFetcher(httpUpstream)
 	antigravityQuotaFetcher := service.NewAntigravityQuotaFetcher(proxyRepository)
@@ -155,7 +159,10 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	sessionLimitCache := repository.ProvideSessionLimitCache(redisClient, configConfig)
 	rpmCache := repository.NewRPMCache(redisClient)
 	groupCapacityService := service.NewGroupCapacityService(accountRepository, groupRepository, concurrencyService, sessionLimitCache, rpmCache)
-	groupHandler := admin.NewGroupHandler(adminService, dashboardService, groupCapacityService)
+	responseCache := repository.NewResponseCache(redisClient)
+	responseCacheService := service.NewResponseCacheService(responseCache, configConfig)
+	requestHedgeService := service.NewRequestHedgeService(configConfig)
+	groupHandler := admin.NewGroupHandler(adminService, dashboardService, groupCapacityService, responseCacheService)
 	accountHandler := admin.NewAccountHandler(adminService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, rateLimitService, accountUsageService, accountTestService, concurrencyService, crsSyncService, sessionLimitCache, rpmCache, compositeTokenCacheInvalidator)
 	adminAnnouncementHandler := admin.NewAnnouncementHandler(announcementService)
 	dataManagementService := service.NewDataManagementService()
@@ -181,13 +188,15 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	identityService := service.NewIdentityService(identityCache)
 	deferredService := service.ProvideDeferredService(accountRepository, timingWheelService)
 	claudeTokenProvider := service.ProvideClaudeTokenProvider(accountRepository, geminiTokenCache, oAuthService, oauthRefreshAPI)
-	digestSessionStore := service.NewDigestSessionStore()
+	digestSessionCache := repository.NewDigestSessionCache(redisClient)
+	digestSessionStore := service.ProvideDigestSessionStore(configConfig, digestSessionCache)
 	providerRegistry := service.ProvideProviderRegistry(settingRepository)
 	gatewayService := service.NewGatewayService(accountRepository, groupRepository, usageLogRepository, usageBillingRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, identityService, httpUpstream, deferredService, claudeTokenProvider, sessionLimitCache, rpmCache, digestSessionStore, settingService, tlsFingerprintProfileService, failoverPolicy, providerRegistry)
 	openAITokenProvider := service.ProvideOpenAITokenProvider(accountRepository, geminiTokenCache, openAIOAuthService, oauthRefreshAPI)
 	openAIGatewayService := service.NewOpenAIGatewayService(accountRepository, usageLogRepository, usageBillingRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, httpUpstream, deferredService, openAITokenProvider, failoverPolicy, settingService, providerRegistry)
 	geminiMessagesCompatService := service.NewGeminiMessagesCompatService(accountRepository, groupRepository, gatewayCache, schedulerSnapshotService, geminiTokenProvider, rateLimitService, httpUpstream, antigravityGatewayService, configConfig, failoverPolicy)
-	opsSystemLogSink := service.ProvideOpsSystemLogSink(opsRepository)
+	logShippingService := service.ProvideLogShippingService(configConfig)
+	opsSystemLogSink := service.ProvideOpsSystemLogSink(opsRepository, logShippingService)
 	opsService := service.NewOpsService(opsRepository, settingRepository, configConfig, accountRepository, userRepository, concurrencyService, gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, opsSystemLogSink)
 	soraS3Storage := service.NewSoraS3Storage(settingService)
 	settingService.SetOnS3UpdateCallback(soraS3Storage.RefreshClient)
@@ -196,6 +205,14 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	soraGenerationService := service.NewSoraGenerationService(soraGenerationRepository, soraS3Storage, soraQuotaService)
 	settingHandler := admin.NewSettingHandler(settingService, emailService, turnstileService, opsService, soraS3Storage)
 	opsHandler := admin.NewOpsHandler(opsService)
+	opsNotificationService := service.NewOpsNotificationService(opsRepository, configConfig)
+	opsNotificationHandler := admin.NewOpsNotificationHandler(opsService, opsNotificationService)
+	balanceLedgerService := service.ProvideBalanceLedgerService(balanceLedgerRepository, userRepository, opsNotificationService, redisClient, configConfig)
+	postpaidStatementRepository := repository.NewPostpaidStatementRepository(db)
+	postpaidBillingService := service.ProvidePostpaidBillingService(postpaidStatementRepository, userRepository, balanceLedgerRepository, billingCacheService, apiKeyAuthCacheInvalidator, client, configConfig)
+	payloadCaptureStore := repository.NewPayloadCaptureStore(configConfig, backupObjectStoreFactory)
+	payloadCaptureService := service.ProvidePayloadCaptureService(opsRepository, settingRepository, payloadCaptureStore, configConfig)
+	opsPayloadCaptureHandler := admin.NewOpsPayloadCaptureHandler(opsService, payloadCaptureService)
 	updateCache := repository.NewUpdateCache(redisClient)
 	gitHubReleaseClient := repository.ProvideGitHubReleaseClient(configConfig)
 	serviceBuildInfo := provideServiceBuildInfo(buildInfo)
@@ -224,40 +241,53 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	scheduledTestResultRepository := repository.NewScheduledTestResultRepository(db)
 	scheduledTestService := service.ProvideScheduledTestService(scheduledTestPlanRepository, scheduledTestResultRepository)
 	scheduledTestHandler := admin.NewScheduledTestHandler(scheduledTestService)
-	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, backupHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, subscriptionPlanHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, accountThrottleHandler, tlsFingerprintProfileHandler, adminAPIKeyHandler, toolsHandler, scheduledTestHandler)
+	adminPaymentHandler := admin.NewPaymentHandler(paymentService)
+	adminBalanceLedgerHandler := admin.NewBalanceLedgerHandler(balanceLedgerService)
+	adminPostpaidStatementHandler := admin.NewPostpaidStatementHandler(postpaidBillingService)
+	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, backupHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, opsNotificationHandler, opsPayloadCaptureHandler, systemHandler, adminSubscriptionHandler, subscriptionPlanHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, accountThrottleHandler, tlsFingerprintProfileHandler, adminAPIKeyHandler, toolsHandler, scheduledTestHandler, adminPaymentHandler, adminBalanceLedgerHandler, adminPostpaidStatementHandler)
 	usageRecordWorkerPool := service.NewUsageRecordWorkerPool(configConfig)
 	userMsgQueueCache := repository.NewUserMsgQueueCache(redisClient)
 	userMessageQueueService := service.ProvideUserMessageQueueService(userMsgQueueCache, rpmCache, configConfig)
-	gatewayHandler := handler.NewGatewayHandler(gatewayService, geminiMessagesCompatService, antigravityGatewayService, userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, userMessageQueueService, configConfig, settingService)
-	openAIGatewayHandler := handler.NewOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, configConfig)
-	soraSDKClient := service.ProvideSoraSDKClient(configConfig, httpUpstream, openAITokenProvider, accountRepository, soraAccountRepository)
 	soraMediaStorage := service.ProvideSoraMediaStorage(configConfig)
+	imageGenerationService := service.NewImageGenerationService(openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, soraMediaStorage, configConfig)
+	gatewayHandler := handler.NewGatewayHandler(gatewayService, geminiMessagesCompatService, antigravityGatewayService, imageGenerationService, responseCacheService, requestHedgeService, userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, userMessageQueueService, configConfig, settingService)
+	openAIGatewayHandler := handler.NewOpenAIGatewayHandler(openAIGatewayService, imageGenerationService, responseCacheService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, configConfig)
+	soraSDKClient := service.ProvideSoraSDKClient(configConfig, httpUpstream, openAITokenProvider, accountRepository, soraAccountRepository)
 	soraGatewayService := service.NewSoraGatewayService(soraSDKClient, rateLimitService, httpUpstream, configConfig, failoverPolicy)
 	soraClientHandler := handler.NewSoraClientHandler(soraGenerationService, soraQuotaService, soraS3Storage, soraGatewayService, gatewayService, soraMediaStorage, apiKeyService)
 	soraGatewayHandler := handler.NewSoraGatewayHandler(gatewayService, soraGatewayService, concurrencyService, billingCacheService, usageRecordWorkerPool, configConfig)
 	handlerSettingHandler := handler.ProvideSettingHandler(settingService, buildInfo)
 	totpHandler := handler.NewTotpHandler(totpService)
 	providerHandler := handler.NewProviderHandler(apiKeyService, settingService)
+	batchRepository := repository.NewBatchRepository(db)
+	batchFileStore := repository.NewBatchFileStore(configConfig, backupObjectStoreFactory)
+	batchService := service.ProvideBatchService(batchRepository, batchFileStore, apiKeyRepository, timingWheelService, configConfig)
+	batchHandler := handler.NewBatchHandler(batchService)
+	metricsHandler := handler.NewMetricsHandler(configConfig, usageRecordWorkerPool, gatewayService, openAIGatewayService)
+	statusHandler := handler.NewStatusHandler(opsService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	balanceLedgerHandler := handler.NewBalanceLedgerHandler(balanceLedgerService)
+	postpaidStatementHandler := handler.NewPostpaidStatementHandler(postpaidBillingService)
 	idempotencyCoordinator := service.ProvideIdempotencyCoordinator(idempotencyRepository, configConfig)
 	idempotencyCleanupService := service.ProvideIdempotencyCleanupService(idempotencyRepository, configConfig)
-	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, soraGatewayHandler, soraClientHandler, handlerSettingHandler, totpHandler, providerHandler, idempotencyCoordinator, idempotencyCleanupService)
+	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, soraGatewayHandler, soraClientHandler, handlerSettingHandler, totpHandler, providerHandler, batchHandler, metricsHandler, statusHandler, paymentHandler, balanceLedgerHandler, postpaidStatementHandler, idempotencyCoordinator, idempotencyCleanupService)
 	jwtAuthMiddleware := middleware.NewJWTAuthMiddleware(authService, userService)
 	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(authService, userService, settingService)
 	apiKeyAuthMiddleware := middleware.NewAPIKeyAuthMiddleware(apiKeyService, subscriptionService, configConfig)
-	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, apiKeyService, subscriptionService, opsService, settingService, redisClient)
+	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, apiKeyService, subscriptionService, opsService, payloadCaptureService, settingService, batchService, redisClient)
 	httpServer := server.ProvideHTTPServer(configConfig, engine)
 	opsMetricsCollector := service.ProvideOpsMetricsCollector(opsRepository, settingRepository, accountRepository, concurrencyService, db, redisClient, configConfig)
 	opsAggregationService := service.ProvideOpsAggregationService(opsRepository, settingRepository, db, redisClient, configConfig)
-	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, redisClient, configConfig)
+	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, opsNotificationService, redisClient, configConfig)
 	opsCleanupService := service.ProvideOpsCleanupService(opsRepository, db, redisClient, configConfig)
-	opsScheduledReportService := service.ProvideOpsScheduledReportService(opsService, userService, emailService, redisClient, configConfig)
+	opsScheduledReportService := service.ProvideOpsScheduledReportService(opsService, userService, emailService, opsNotificationService, backupService, redisClient, configConfig)
 	soraMediaCleanupService := service.ProvideSoraMediaCleanupService(soraMediaStorage, configConfig)
 	tokenRefreshService := service.ProvideTokenRefreshService(accountRepository, soraAccountRepository, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, compositeTokenCacheInvalidator, schedulerCache, configConfig, tempUnschedCache, privacyClientFactory, proxyRepository, oauthRefreshAPI)
 	accountExpiryService := service.ProvideAccountExpiryService(accountRepository)
 	subscriptionExpiryService := service.ProvideSubscriptionExpiryService(userSubscriptionRepository)
 	scheduledTestRunnerService := service.ProvideScheduledTestRunnerService(scheduledTestPlanRepository, scheduledTestService, accountTestService, rateLimitService, configConfig)
 	accountThrottleRecoveryService := service.ProvideAccountThrottleRecoveryService(db, accountTestService, rateLimitService, tempUnschedCache)
-	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, soraMediaCleanupService, schedulerSnapshotService, tokenRefreshService, accountExpiryService, subscriptionExpiryService, usageCleanupService, idempotencyCleanupService, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, openAIGatewayService, scheduledTestRunnerService, accountThrottleRecoveryService, backupService)
+	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsNotificationService, payloadCaptureService, opsSystemLogSink, logShippingService, soraMediaCleanupService, schedulerSnapshotService, tokenRefreshService, accountExpiryService, subscriptionExpiryService, usageCleanupService, batchService, idempotencyCleanupService, balanceLedgerService, postpaidBillingService, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, openAIGatewayService, scheduledTestRunnerService, accountThrottleRecoveryService, backupService)
 	application := &Application{
 		Server:  httpServer,
 		Cleanup: v,
@@ -291,14 +321,20 @@ func provideCleanup(
 	opsAlertEvaluator *service.OpsAlertEvaluatorService,
 	opsCleanup *service.OpsCleanupService,
 	opsScheduledReport *service.OpsScheduledReportService,
+	opsNotification *service.OpsNotificationService,
+	payloadCapture *service.PayloadCaptureService,
 	opsSystemLogSink *service.OpsSystemLogSink,
+	logShipping *service.LogShippingService,
 	soraMediaCleanup *service.SoraMediaCleanupService,
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
+	batchSvc *service.BatchService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
+	balanceLedger *service.BalanceLedgerService,
+	postpaidBilling *service.PostpaidBillingService,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
 	billingCache *service.BillingCacheService,
@@ -341,6 +377,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"LogShippingService", func() error {
+				if logShipping != nil {
+					logShipping.Stop()
+				}
+				return nil
+			}},
 			{"SoraMediaCleanupService", func() error {
 				if soraMediaCleanup != nil {
 					soraMediaCleanup.Stop()
@@ -353,6 +395,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"OpsNotificationService", func() error {
+				if opsNotification != nil {
+					opsNotification.Stop()
+				}
+				return nil
+			}},
+			{"PayloadCaptureService", func() error {
+				if payloadCapture != nil {
+					payloadCapture.Stop()
+				}
+				return nil
+			}},
 			{"OpsAggregationService", func() error {
 				if opsAggregation != nil {
 					opsAggregation.Stop()
@@ -377,12 +431,30 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"BatchService", func() error {
+				if batchSvc != nil {
+					batchSvc.Stop()
+				}
+				return nil
+			}},
 			{"IdempotencyCleanupService", func() error {
 				if idempotencyCleanup != nil {
 					idempotencyCleanup.Stop()
 				}
 				return nil
 			}},
+			{"BalanceLedgerService", func() error {
+				if balanceLedger != nil {
+					balanceLedger.Stop()
+				}
+				return nil
+			}},
+			{"PostpaidBillingService", func() error {
+				if postpaidBilling != nil {
+					postpaidBilling.Stop()
+				}
+				return nil
+			}},
 			{"TokenRefreshService", func() error {
 				tokenRefresh.Stop()
 				return nil
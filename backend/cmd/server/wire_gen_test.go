@@ -56,14 +56,20 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		&service.OpsAlertEvaluatorService{},
 		&service.OpsCleanupService{},
 		&service.OpsScheduledReportService{},
+		&service.OpsNotificationService{},
+		&service.PayloadCaptureService{},
 		opsSystemLogSinkSvc,
+		&service.LogShippingService{},
 		&service.SoraMediaCleanupService{},
 		schedulerSnapshotSvc,
 		tokenRefreshSvc,
 		accountExpirySvc,
 		subscriptionExpirySvc,
 		&service.UsageCleanupService{},
+		&service.BatchService{},
 		idempotencyCleanupSvc,
+		service.NewBalanceLedgerService(nil, nil, nil, nil, nil),
+		service.NewPostpaidBillingService(nil, nil, nil, nil, nil, nil, nil),
 		pricingSvc,
 		emailQueueSvc,
 		billingCacheSvc,
@@ -75,6 +81,7 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		antigravityOAuthSvc,
 		nil, // openAIGateway
 		nil, // scheduledTestRunner
+		nil, // throttleRecovery
 		nil, // backupSvc
 	)
 
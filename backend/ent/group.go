@@ -11,6 +11,7 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
 	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // Group is the model entity for the Group schema.
@@ -80,6 +81,8 @@ type Group struct {
 	ModelAliases map[string]string `json:"model_aliases,omitempty"`
 	// 模型别名兜底模型：未匹配到任何别名规则时映射到此模型
 	FallbackModel string `json:"fallback_model,omitempty"`
+	// 分组模型价格：模型模式 -> 每百万 token 价格
+	ModelPrices map[string]domain.GroupModelPrice `json:"model_prices,omitempty"`
 	// 是否注入 MCP XML 调用协议提示词（仅 antigravity 平台）
 	McpXMLInject bool `json:"mcp_xml_inject,omitempty"`
 	// 支持的模型系列：claude, gemini_text, gemini_image
@@ -94,6 +97,18 @@ type Group struct {
 	RequirePrivacySet bool `json:"require_privacy_set,omitempty"`
 	// 默认映射模型 ID，当账号级映射找不到时使用此值
 	DefaultMappedModel string `json:"default_mapped_model,omitempty"`
+	// 是否对确定性请求启用精确匹配响应缓存
+	ResponseCacheEnabled bool `json:"response_cache_enabled,omitempty"`
+	// 响应缓存有效期（秒）
+	ResponseCacheTTLSeconds int `json:"response_cache_ttl_seconds,omitempty"`
+	// 缓存命中计费倍率，作用于原始请求费用
+	ResponseCacheHitMultiplier float64 `json:"response_cache_hit_multiplier,omitempty"`
+	// 对冲请求占比上限（百分比），0 表示关闭对冲
+	HedgeBudgetPercent float64 `json:"hedge_budget_percent,omitempty"`
+	// 账号调度策略：priority_lru/least_load/ewma_latency/cost_aware，空表示网关默认策略
+	ScheduleStrategy string `json:"schedule_strategy,omitempty"`
+	// 计费预留模式：optimistic 请求后扣费，strict 请求前按预估最大费用预留
+	BillingReservationMode string `json:"billing_reservation_mode,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -211,15 +226,15 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case group.FieldModelRouting, group.FieldModelAliases, group.FieldSupportedModelScopes:
+		case group.FieldModelRouting, group.FieldModelAliases, group.FieldModelPrices, group.FieldSupportedModelScopes:
 			values[i] = new([]byte)
-		case group.FieldIsExclusive, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet:
+		case group.FieldIsExclusive, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldResponseCacheEnabled:
 			values[i] = new(sql.NullBool)
-		case group.FieldRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldSoraImagePrice360, group.FieldSoraImagePrice540, group.FieldSoraVideoPricePerRequest, group.FieldSoraVideoPricePerRequestHd:
+		case group.FieldRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldSoraImagePrice360, group.FieldSoraImagePrice540, group.FieldSoraVideoPricePerRequest, group.FieldSoraVideoPricePerRequestHd, group.FieldResponseCacheHitMultiplier, group.FieldHedgeBudgetPercent:
 			values[i] = new(sql.NullFloat64)
-		case group.FieldID, group.FieldDefaultValidityDays, group.FieldSoraStorageQuotaBytes, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder:
+		case group.FieldID, group.FieldDefaultValidityDays, group.FieldSoraStorageQuotaBytes, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldResponseCacheTTLSeconds:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldUseKeyInstructions, group.FieldConfigTemplates, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldFallbackModel, group.FieldDefaultMappedModel:
+		case group.FieldName, group.FieldDescription, group.FieldUseKeyInstructions, group.FieldConfigTemplates, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldFallbackModel, group.FieldDefaultMappedModel, group.FieldScheduleStrategy, group.FieldBillingReservationMode:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -450,6 +465,14 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.FallbackModel = value.String
 			}
+		case group.FieldModelPrices:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field model_prices", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ModelPrices); err != nil {
+					return fmt.Errorf("unmarshal field model_prices: %w", err)
+				}
+			}
 		case group.FieldMcpXMLInject:
 			if value, ok := values[i].(*sql.NullBool); !ok {
 				return fmt.Errorf("unexpected type %T for field mcp_xml_inject", values[i])
@@ -494,6 +517,42 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.DefaultMappedModel = value.String
 			}
+		case group.FieldResponseCacheEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_enabled", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheEnabled = value.Bool
+			}
+		case group.FieldResponseCacheTTLSeconds:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_ttl_seconds", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheTTLSeconds = int(value.Int64)
+			}
+		case group.FieldResponseCacheHitMultiplier:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_hit_multiplier", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheHitMultiplier = value.Float64
+			}
+		case group.FieldHedgeBudgetPercent:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field hedge_budget_percent", values[i])
+			} else if value.Valid {
+				_m.HedgeBudgetPercent = value.Float64
+			}
+		case group.FieldScheduleStrategy:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field schedule_strategy", values[i])
+			} else if value.Valid {
+				_m.ScheduleStrategy = value.String
+			}
+		case group.FieldBillingReservationMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field billing_reservation_mode", values[i])
+			} else if value.Valid {
+				_m.BillingReservationMode = value.String
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -700,6 +759,9 @@ func (_m *Group) String() string {
 	builder.WriteString("fallback_model=")
 	builder.WriteString(_m.FallbackModel)
 	builder.WriteString(", ")
+	builder.WriteString("model_prices=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ModelPrices))
+	builder.WriteString(", ")
 	builder.WriteString("mcp_xml_inject=")
 	builder.WriteString(fmt.Sprintf("%v", _m.McpXMLInject))
 	builder.WriteString(", ")
@@ -720,6 +782,24 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("default_mapped_model=")
 	builder.WriteString(_m.DefaultMappedModel)
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_ttl_seconds=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheTTLSeconds))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_hit_multiplier=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheHitMultiplier))
+	builder.WriteString(", ")
+	builder.WriteString("hedge_budget_percent=")
+	builder.WriteString(fmt.Sprintf("%v", _m.HedgeBudgetPercent))
+	builder.WriteString(", ")
+	builder.WriteString("schedule_strategy=")
+	builder.WriteString(_m.ScheduleStrategy)
+	builder.WriteString(", ")
+	builder.WriteString("billing_reservation_mode=")
+	builder.WriteString(_m.BillingReservationMode)
 	builder.WriteByte(')')
 	return builder.String()
 }
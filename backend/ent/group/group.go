@@ -77,6 +77,8 @@ const (
 	FieldModelAliases = "model_aliases"
 	// FieldFallbackModel holds the string denoting the fallback_model field in the database.
 	FieldFallbackModel = "fallback_model"
+	// FieldModelPrices holds the string denoting the model_prices field in the database.
+	FieldModelPrices = "model_prices"
 	// FieldMcpXMLInject holds the string denoting the mcp_xml_inject field in the database.
 	FieldMcpXMLInject = "mcp_xml_inject"
 	// FieldSupportedModelScopes holds the string denoting the supported_model_scopes field in the database.
@@ -91,6 +93,18 @@ const (
 	FieldRequirePrivacySet = "require_privacy_set"
 	// FieldDefaultMappedModel holds the string denoting the default_mapped_model field in the database.
 	FieldDefaultMappedModel = "default_mapped_model"
+	// FieldResponseCacheEnabled holds the string denoting the response_cache_enabled field in the database.
+	FieldResponseCacheEnabled = "response_cache_enabled"
+	// FieldResponseCacheTTLSeconds holds the string denoting the response_cache_ttl_seconds field in the database.
+	FieldResponseCacheTTLSeconds = "response_cache_ttl_seconds"
+	// FieldResponseCacheHitMultiplier holds the string denoting the response_cache_hit_multiplier field in the database.
+	FieldResponseCacheHitMultiplier = "response_cache_hit_multiplier"
+	// FieldHedgeBudgetPercent holds the string denoting the hedge_budget_percent field in the database.
+	FieldHedgeBudgetPercent = "hedge_budget_percent"
+	// FieldScheduleStrategy holds the string denoting the schedule_strategy field in the database.
+	FieldScheduleStrategy = "schedule_strategy"
+	// FieldBillingReservationMode holds the string denoting the billing_reservation_mode field in the database.
+	FieldBillingReservationMode = "billing_reservation_mode"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -206,6 +220,7 @@ var Columns = []string{
 	FieldModelRoutingEnabled,
 	FieldModelAliases,
 	FieldFallbackModel,
+	FieldModelPrices,
 	FieldMcpXMLInject,
 	FieldSupportedModelScopes,
 	FieldSortOrder,
@@ -213,6 +228,12 @@ var Columns = []string{
 	FieldRequireOauthOnly,
 	FieldRequirePrivacySet,
 	FieldDefaultMappedModel,
+	FieldResponseCacheEnabled,
+	FieldResponseCacheTTLSeconds,
+	FieldResponseCacheHitMultiplier,
+	FieldHedgeBudgetPercent,
+	FieldScheduleStrategy,
+	FieldBillingReservationMode,
 }
 
 var (
@@ -294,6 +315,22 @@ var (
 	DefaultDefaultMappedModel string
 	// DefaultMappedModelValidator is a validator for the "default_mapped_model" field. It is called by the builders before save.
 	DefaultMappedModelValidator func(string) error
+	// DefaultResponseCacheEnabled holds the default value on creation for the "response_cache_enabled" field.
+	DefaultResponseCacheEnabled bool
+	// DefaultResponseCacheTTLSeconds holds the default value on creation for the "response_cache_ttl_seconds" field.
+	DefaultResponseCacheTTLSeconds int
+	// DefaultResponseCacheHitMultiplier holds the default value on creation for the "response_cache_hit_multiplier" field.
+	DefaultResponseCacheHitMultiplier float64
+	// DefaultHedgeBudgetPercent holds the default value on creation for the "hedge_budget_percent" field.
+	DefaultHedgeBudgetPercent float64
+	// DefaultScheduleStrategy holds the default value on creation for the "schedule_strategy" field.
+	DefaultScheduleStrategy string
+	// ScheduleStrategyValidator is a validator for the "schedule_strategy" field. It is called by the builders before save.
+	ScheduleStrategyValidator func(string) error
+	// DefaultBillingReservationMode holds the default value on creation for the "billing_reservation_mode" field.
+	DefaultBillingReservationMode string
+	// BillingReservationModeValidator is a validator for the "billing_reservation_mode" field. It is called by the builders before save.
+	BillingReservationModeValidator func(string) error
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -479,6 +516,36 @@ func ByDefaultMappedModel(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldDefaultMappedModel, opts...).ToFunc()
 }
 
+// ByResponseCacheEnabled orders the results by the response_cache_enabled field.
+func ByResponseCacheEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheEnabled, opts...).ToFunc()
+}
+
+// ByResponseCacheTTLSeconds orders the results by the response_cache_ttl_seconds field.
+func ByResponseCacheTTLSeconds(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheTTLSeconds, opts...).ToFunc()
+}
+
+// ByResponseCacheHitMultiplier orders the results by the response_cache_hit_multiplier field.
+func ByResponseCacheHitMultiplier(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheHitMultiplier, opts...).ToFunc()
+}
+
+// ByHedgeBudgetPercent orders the results by the hedge_budget_percent field.
+func ByHedgeBudgetPercent(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldHedgeBudgetPercent, opts...).ToFunc()
+}
+
+// ByScheduleStrategy orders the results by the schedule_strategy field.
+func ByScheduleStrategy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldScheduleStrategy, opts...).ToFunc()
+}
+
+// ByBillingReservationMode orders the results by the billing_reservation_mode field.
+func ByBillingReservationMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBillingReservationMode, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -230,6 +230,36 @@ func DefaultMappedModel(v string) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldDefaultMappedModel, v))
 }
 
+// ResponseCacheEnabled applies equality check predicate on the "response_cache_enabled" field. It's identical to ResponseCacheEnabledEQ.
+func ResponseCacheEnabled(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheTTLSeconds applies equality check predicate on the "response_cache_ttl_seconds" field. It's identical to ResponseCacheTTLSecondsEQ.
+func ResponseCacheTTLSeconds(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheHitMultiplier applies equality check predicate on the "response_cache_hit_multiplier" field. It's identical to ResponseCacheHitMultiplierEQ.
+func ResponseCacheHitMultiplier(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheHitMultiplier, v))
+}
+
+// HedgeBudgetPercent applies equality check predicate on the "hedge_budget_percent" field. It's identical to HedgeBudgetPercentEQ.
+func HedgeBudgetPercent(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldHedgeBudgetPercent, v))
+}
+
+// ScheduleStrategy applies equality check predicate on the "schedule_strategy" field. It's identical to ScheduleStrategyEQ.
+func ScheduleStrategy(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldScheduleStrategy, v))
+}
+
+// BillingReservationMode applies equality check predicate on the "billing_reservation_mode" field. It's identical to BillingReservationModeEQ.
+func BillingReservationMode(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldBillingReservationMode, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -1680,6 +1710,16 @@ func FallbackModelContainsFold(v string) predicate.Group {
 	return predicate.Group(sql.FieldContainsFold(FieldFallbackModel, v))
 }
 
+// ModelPricesIsNil applies the IsNil predicate on the "model_prices" field.
+func ModelPricesIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldModelPrices))
+}
+
+// ModelPricesNotNil applies the NotNil predicate on the "model_prices" field.
+func ModelPricesNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldModelPrices))
+}
+
 // McpXMLInjectEQ applies the EQ predicate on the "mcp_xml_inject" field.
 func McpXMLInjectEQ(v bool) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldMcpXMLInject, v))
@@ -1825,6 +1865,266 @@ func DefaultMappedModelContainsFold(v string) predicate.Group {
 	return predicate.Group(sql.FieldContainsFold(FieldDefaultMappedModel, v))
 }
 
+// ResponseCacheEnabledEQ applies the EQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheEnabledNEQ applies the NEQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheTTLSecondsEQ applies the EQ predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsNEQ applies the NEQ predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsIn applies the In predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseCacheTTLSeconds, vs...))
+}
+
+// ResponseCacheTTLSecondsNotIn applies the NotIn predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseCacheTTLSeconds, vs...))
+}
+
+// ResponseCacheTTLSecondsGT applies the GT predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsGTE applies the GTE predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsLT applies the LT predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsLTE applies the LTE predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheHitMultiplierEQ applies the EQ predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierNEQ applies the NEQ predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierIn applies the In predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseCacheHitMultiplier, vs...))
+}
+
+// ResponseCacheHitMultiplierNotIn applies the NotIn predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseCacheHitMultiplier, vs...))
+}
+
+// ResponseCacheHitMultiplierGT applies the GT predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierGTE applies the GTE predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierLT applies the LT predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierLTE applies the LTE predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseCacheHitMultiplier, v))
+}
+
+// HedgeBudgetPercentEQ applies the EQ predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldHedgeBudgetPercent, v))
+}
+
+// HedgeBudgetPercentNEQ applies the NEQ predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldHedgeBudgetPercent, v))
+}
+
+// HedgeBudgetPercentIn applies the In predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldHedgeBudgetPercent, vs...))
+}
+
+// HedgeBudgetPercentNotIn applies the NotIn predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldHedgeBudgetPercent, vs...))
+}
+
+// HedgeBudgetPercentGT applies the GT predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldHedgeBudgetPercent, v))
+}
+
+// HedgeBudgetPercentGTE applies the GTE predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldHedgeBudgetPercent, v))
+}
+
+// HedgeBudgetPercentLT applies the LT predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldHedgeBudgetPercent, v))
+}
+
+// HedgeBudgetPercentLTE applies the LTE predicate on the "hedge_budget_percent" field.
+func HedgeBudgetPercentLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldHedgeBudgetPercent, v))
+}
+
+// ScheduleStrategyEQ applies the EQ predicate on the "schedule_strategy" field.
+func ScheduleStrategyEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyNEQ applies the NEQ predicate on the "schedule_strategy" field.
+func ScheduleStrategyNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyIn applies the In predicate on the "schedule_strategy" field.
+func ScheduleStrategyIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldScheduleStrategy, vs...))
+}
+
+// ScheduleStrategyNotIn applies the NotIn predicate on the "schedule_strategy" field.
+func ScheduleStrategyNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldScheduleStrategy, vs...))
+}
+
+// ScheduleStrategyGT applies the GT predicate on the "schedule_strategy" field.
+func ScheduleStrategyGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyGTE applies the GTE predicate on the "schedule_strategy" field.
+func ScheduleStrategyGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyLT applies the LT predicate on the "schedule_strategy" field.
+func ScheduleStrategyLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyLTE applies the LTE predicate on the "schedule_strategy" field.
+func ScheduleStrategyLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyContains applies the Contains predicate on the "schedule_strategy" field.
+func ScheduleStrategyContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyHasPrefix applies the HasPrefix predicate on the "schedule_strategy" field.
+func ScheduleStrategyHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyHasSuffix applies the HasSuffix predicate on the "schedule_strategy" field.
+func ScheduleStrategyHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyEqualFold applies the EqualFold predicate on the "schedule_strategy" field.
+func ScheduleStrategyEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldScheduleStrategy, v))
+}
+
+// ScheduleStrategyContainsFold applies the ContainsFold predicate on the "schedule_strategy" field.
+func ScheduleStrategyContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldScheduleStrategy, v))
+}
+
+// BillingReservationModeEQ applies the EQ predicate on the "billing_reservation_mode" field.
+func BillingReservationModeEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeNEQ applies the NEQ predicate on the "billing_reservation_mode" field.
+func BillingReservationModeNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeIn applies the In predicate on the "billing_reservation_mode" field.
+func BillingReservationModeIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldBillingReservationMode, vs...))
+}
+
+// BillingReservationModeNotIn applies the NotIn predicate on the "billing_reservation_mode" field.
+func BillingReservationModeNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldBillingReservationMode, vs...))
+}
+
+// BillingReservationModeGT applies the GT predicate on the "billing_reservation_mode" field.
+func BillingReservationModeGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeGTE applies the GTE predicate on the "billing_reservation_mode" field.
+func BillingReservationModeGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeLT applies the LT predicate on the "billing_reservation_mode" field.
+func BillingReservationModeLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeLTE applies the LTE predicate on the "billing_reservation_mode" field.
+func BillingReservationModeLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeContains applies the Contains predicate on the "billing_reservation_mode" field.
+func BillingReservationModeContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeHasPrefix applies the HasPrefix predicate on the "billing_reservation_mode" field.
+func BillingReservationModeHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeHasSuffix applies the HasSuffix predicate on the "billing_reservation_mode" field.
+func BillingReservationModeHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeEqualFold applies the EqualFold predicate on the "billing_reservation_mode" field.
+func BillingReservationModeEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldBillingReservationMode, v))
+}
+
+// BillingReservationModeContainsFold applies the ContainsFold predicate on the "billing_reservation_mode" field.
+func BillingReservationModeContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldBillingReservationMode, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
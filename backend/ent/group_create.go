@@ -19,6 +19,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
 	"github.com/Wei-Shaw/sub2api/ent/usersubscription"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // GroupCreate is the builder for creating a Group entity.
@@ -439,6 +440,12 @@ func (_c *GroupCreate) SetNillableFallbackModel(v *string) *GroupCreate {
 	return _c
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (_c *GroupCreate) SetModelPrices(v map[string]domain.GroupModelPrice) *GroupCreate {
+	_c.mutation.SetModelPrices(v)
+	return _c
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (_c *GroupCreate) SetMcpXMLInject(v bool) *GroupCreate {
 	_c.mutation.SetMcpXMLInject(v)
@@ -529,6 +536,90 @@ func (_c *GroupCreate) SetNillableDefaultMappedModel(v *string) *GroupCreate {
 	return _c
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_c *GroupCreate) SetResponseCacheEnabled(v bool) *GroupCreate {
+	_c.mutation.SetResponseCacheEnabled(v)
+	return _c
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheEnabled(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheEnabled(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_c *GroupCreate) SetResponseCacheTTLSeconds(v int) *GroupCreate {
+	_c.mutation.SetResponseCacheTTLSeconds(v)
+	return _c
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheTTLSeconds(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheTTLSeconds(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (_c *GroupCreate) SetResponseCacheHitMultiplier(v float64) *GroupCreate {
+	_c.mutation.SetResponseCacheHitMultiplier(v)
+	return _c
+}
+
+// SetNillableResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheHitMultiplier(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheHitMultiplier(*v)
+	}
+	return _c
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (_c *GroupCreate) SetHedgeBudgetPercent(v float64) *GroupCreate {
+	_c.mutation.SetHedgeBudgetPercent(v)
+	return _c
+}
+
+// SetNillableHedgeBudgetPercent sets the "hedge_budget_percent" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableHedgeBudgetPercent(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetHedgeBudgetPercent(*v)
+	}
+	return _c
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (_c *GroupCreate) SetScheduleStrategy(v string) *GroupCreate {
+	_c.mutation.SetScheduleStrategy(v)
+	return _c
+}
+
+// SetNillableScheduleStrategy sets the "schedule_strategy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableScheduleStrategy(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetScheduleStrategy(*v)
+	}
+	return _c
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (_c *GroupCreate) SetBillingReservationMode(v string) *GroupCreate {
+	_c.mutation.SetBillingReservationMode(v)
+	return _c
+}
+
+// SetNillableBillingReservationMode sets the "billing_reservation_mode" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableBillingReservationMode(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetBillingReservationMode(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -753,6 +844,30 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultDefaultMappedModel
 		_c.mutation.SetDefaultMappedModel(v)
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		v := group.DefaultResponseCacheEnabled
+		_c.mutation.SetResponseCacheEnabled(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheTTLSeconds(); !ok {
+		v := group.DefaultResponseCacheTTLSeconds
+		_c.mutation.SetResponseCacheTTLSeconds(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheHitMultiplier(); !ok {
+		v := group.DefaultResponseCacheHitMultiplier
+		_c.mutation.SetResponseCacheHitMultiplier(v)
+	}
+	if _, ok := _c.mutation.HedgeBudgetPercent(); !ok {
+		v := group.DefaultHedgeBudgetPercent
+		_c.mutation.SetHedgeBudgetPercent(v)
+	}
+	if _, ok := _c.mutation.ScheduleStrategy(); !ok {
+		v := group.DefaultScheduleStrategy
+		_c.mutation.SetScheduleStrategy(v)
+	}
+	if _, ok := _c.mutation.BillingReservationMode(); !ok {
+		v := group.DefaultBillingReservationMode
+		_c.mutation.SetBillingReservationMode(v)
+	}
 	return nil
 }
 
@@ -848,6 +963,34 @@ func (_c *GroupCreate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		return &ValidationError{Name: "response_cache_enabled", err: errors.New(`ent: missing required field "Group.response_cache_enabled"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheTTLSeconds(); !ok {
+		return &ValidationError{Name: "response_cache_ttl_seconds", err: errors.New(`ent: missing required field "Group.response_cache_ttl_seconds"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheHitMultiplier(); !ok {
+		return &ValidationError{Name: "response_cache_hit_multiplier", err: errors.New(`ent: missing required field "Group.response_cache_hit_multiplier"`)}
+	}
+	if _, ok := _c.mutation.HedgeBudgetPercent(); !ok {
+		return &ValidationError{Name: "hedge_budget_percent", err: errors.New(`ent: missing required field "Group.hedge_budget_percent"`)}
+	}
+	if _, ok := _c.mutation.ScheduleStrategy(); !ok {
+		return &ValidationError{Name: "schedule_strategy", err: errors.New(`ent: missing required field "Group.schedule_strategy"`)}
+	}
+	if v, ok := _c.mutation.ScheduleStrategy(); ok {
+		if err := group.ScheduleStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "schedule_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.schedule_strategy": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.BillingReservationMode(); !ok {
+		return &ValidationError{Name: "billing_reservation_mode", err: errors.New(`ent: missing required field "Group.billing_reservation_mode"`)}
+	}
+	if v, ok := _c.mutation.BillingReservationMode(); ok {
+		if err := group.BillingReservationModeValidator(v); err != nil {
+			return &ValidationError{Name: "billing_reservation_mode", err: fmt.Errorf(`ent: validator failed for field "Group.billing_reservation_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -999,6 +1142,10 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldFallbackModel, field.TypeString, value)
 		_node.FallbackModel = value
 	}
+	if value, ok := _c.mutation.ModelPrices(); ok {
+		_spec.SetField(group.FieldModelPrices, field.TypeJSON, value)
+		_node.ModelPrices = value
+	}
 	if value, ok := _c.mutation.McpXMLInject(); ok {
 		_spec.SetField(group.FieldMcpXMLInject, field.TypeBool, value)
 		_node.McpXMLInject = value
@@ -1027,6 +1174,30 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 		_node.DefaultMappedModel = value
 	}
+	if value, ok := _c.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+		_node.ResponseCacheEnabled = value
+	}
+	if value, ok := _c.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+		_node.ResponseCacheTTLSeconds = value
+	}
+	if value, ok := _c.mutation.ResponseCacheHitMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+		_node.ResponseCacheHitMultiplier = value
+	}
+	if value, ok := _c.mutation.HedgeBudgetPercent(); ok {
+		_spec.SetField(group.FieldHedgeBudgetPercent, field.TypeFloat64, value)
+		_node.HedgeBudgetPercent = value
+	}
+	if value, ok := _c.mutation.ScheduleStrategy(); ok {
+		_spec.SetField(group.FieldScheduleStrategy, field.TypeString, value)
+		_node.ScheduleStrategy = value
+	}
+	if value, ok := _c.mutation.BillingReservationMode(); ok {
+		_spec.SetField(group.FieldBillingReservationMode, field.TypeString, value)
+		_node.BillingReservationMode = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1757,6 +1928,24 @@ func (u *GroupUpsert) UpdateFallbackModel() *GroupUpsert {
 	return u
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (u *GroupUpsert) SetModelPrices(v map[string]domain.GroupModelPrice) *GroupUpsert {
+	u.Set(group.FieldModelPrices, v)
+	return u
+}
+
+// UpdateModelPrices sets the "model_prices" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateModelPrices() *GroupUpsert {
+	u.SetExcluded(group.FieldModelPrices)
+	return u
+}
+
+// ClearModelPrices clears the value of the "model_prices" field.
+func (u *GroupUpsert) ClearModelPrices() *GroupUpsert {
+	u.SetNull(group.FieldModelPrices)
+	return u
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (u *GroupUpsert) SetMcpXMLInject(v bool) *GroupUpsert {
 	u.Set(group.FieldMcpXMLInject, v)
@@ -1847,6 +2036,96 @@ func (u *GroupUpsert) UpdateDefaultMappedModel() *GroupUpsert {
 	return u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsert) SetResponseCacheEnabled(v bool) *GroupUpsert {
+	u.Set(group.FieldResponseCacheEnabled, v)
+	return u
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheEnabled() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheEnabled)
+	return u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsert) SetResponseCacheTTLSeconds(v int) *GroupUpsert {
+	u.Set(group.FieldResponseCacheTTLSeconds, v)
+	return u
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheTTLSeconds() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheTTLSeconds)
+	return u
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsert) AddResponseCacheTTLSeconds(v int) *GroupUpsert {
+	u.Add(group.FieldResponseCacheTTLSeconds, v)
+	return u
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (u *GroupUpsert) SetResponseCacheHitMultiplier(v float64) *GroupUpsert {
+	u.Set(group.FieldResponseCacheHitMultiplier, v)
+	return u
+}
+
+// UpdateResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheHitMultiplier() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheHitMultiplier)
+	return u
+}
+
+// AddResponseCacheHitMultiplier adds v to the "response_cache_hit_multiplier" field.
+func (u *GroupUpsert) AddResponseCacheHitMultiplier(v float64) *GroupUpsert {
+	u.Add(group.FieldResponseCacheHitMultiplier, v)
+	return u
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (u *GroupUpsert) SetHedgeBudgetPercent(v float64) *GroupUpsert {
+	u.Set(group.FieldHedgeBudgetPercent, v)
+	return u
+}
+
+// UpdateHedgeBudgetPercent sets the "hedge_budget_percent" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateHedgeBudgetPercent() *GroupUpsert {
+	u.SetExcluded(group.FieldHedgeBudgetPercent)
+	return u
+}
+
+// AddHedgeBudgetPercent adds v to the "hedge_budget_percent" field.
+func (u *GroupUpsert) AddHedgeBudgetPercent(v float64) *GroupUpsert {
+	u.Add(group.FieldHedgeBudgetPercent, v)
+	return u
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (u *GroupUpsert) SetScheduleStrategy(v string) *GroupUpsert {
+	u.Set(group.FieldScheduleStrategy, v)
+	return u
+}
+
+// UpdateScheduleStrategy sets the "schedule_strategy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateScheduleStrategy() *GroupUpsert {
+	u.SetExcluded(group.FieldScheduleStrategy)
+	return u
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (u *GroupUpsert) SetBillingReservationMode(v string) *GroupUpsert {
+	u.Set(group.FieldBillingReservationMode, v)
+	return u
+}
+
+// UpdateBillingReservationMode sets the "billing_reservation_mode" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateBillingReservationMode() *GroupUpsert {
+	u.SetExcluded(group.FieldBillingReservationMode)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2543,6 +2822,27 @@ func (u *GroupUpsertOne) UpdateFallbackModel() *GroupUpsertOne {
 	})
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (u *GroupUpsertOne) SetModelPrices(v map[string]domain.GroupModelPrice) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelPrices(v)
+	})
+}
+
+// UpdateModelPrices sets the "model_prices" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateModelPrices() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelPrices()
+	})
+}
+
+// ClearModelPrices clears the value of the "model_prices" field.
+func (u *GroupUpsertOne) ClearModelPrices() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearModelPrices()
+	})
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (u *GroupUpsertOne) SetMcpXMLInject(v bool) *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
@@ -2648,6 +2948,111 @@ func (u *GroupUpsertOne) UpdateDefaultMappedModel() *GroupUpsertOne {
 	})
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsertOne) SetResponseCacheEnabled(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheEnabled() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertOne) SetResponseCacheTTLSeconds(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheTTLSeconds(v)
+	})
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertOne) AddResponseCacheTTLSeconds(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheTTLSeconds(v)
+	})
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheTTLSeconds() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheTTLSeconds()
+	})
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertOne) SetResponseCacheHitMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheHitMultiplier(v)
+	})
+}
+
+// AddResponseCacheHitMultiplier adds v to the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertOne) AddResponseCacheHitMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheHitMultiplier(v)
+	})
+}
+
+// UpdateResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheHitMultiplier() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheHitMultiplier()
+	})
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (u *GroupUpsertOne) SetHedgeBudgetPercent(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetHedgeBudgetPercent(v)
+	})
+}
+
+// AddHedgeBudgetPercent adds v to the "hedge_budget_percent" field.
+func (u *GroupUpsertOne) AddHedgeBudgetPercent(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddHedgeBudgetPercent(v)
+	})
+}
+
+// UpdateHedgeBudgetPercent sets the "hedge_budget_percent" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateHedgeBudgetPercent() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateHedgeBudgetPercent()
+	})
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (u *GroupUpsertOne) SetScheduleStrategy(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetScheduleStrategy(v)
+	})
+}
+
+// UpdateScheduleStrategy sets the "schedule_strategy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateScheduleStrategy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateScheduleStrategy()
+	})
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (u *GroupUpsertOne) SetBillingReservationMode(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetBillingReservationMode(v)
+	})
+}
+
+// UpdateBillingReservationMode sets the "billing_reservation_mode" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateBillingReservationMode() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateBillingReservationMode()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -3510,6 +3915,27 @@ func (u *GroupUpsertBulk) UpdateFallbackModel() *GroupUpsertBulk {
 	})
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (u *GroupUpsertBulk) SetModelPrices(v map[string]domain.GroupModelPrice) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelPrices(v)
+	})
+}
+
+// UpdateModelPrices sets the "model_prices" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateModelPrices() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelPrices()
+	})
+}
+
+// ClearModelPrices clears the value of the "model_prices" field.
+func (u *GroupUpsertBulk) ClearModelPrices() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearModelPrices()
+	})
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (u *GroupUpsertBulk) SetMcpXMLInject(v bool) *GroupUpsertBulk {
 	return u.Update(func(s *GroupUpsert) {
@@ -3615,6 +4041,111 @@ func (u *GroupUpsertBulk) UpdateDefaultMappedModel() *GroupUpsertBulk {
 	})
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsertBulk) SetResponseCacheEnabled(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheEnabled() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertBulk) SetResponseCacheTTLSeconds(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheTTLSeconds(v)
+	})
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertBulk) AddResponseCacheTTLSeconds(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheTTLSeconds(v)
+	})
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheTTLSeconds() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheTTLSeconds()
+	})
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertBulk) SetResponseCacheHitMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheHitMultiplier(v)
+	})
+}
+
+// AddResponseCacheHitMultiplier adds v to the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertBulk) AddResponseCacheHitMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheHitMultiplier(v)
+	})
+}
+
+// UpdateResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheHitMultiplier() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheHitMultiplier()
+	})
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (u *GroupUpsertBulk) SetHedgeBudgetPercent(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetHedgeBudgetPercent(v)
+	})
+}
+
+// AddHedgeBudgetPercent adds v to the "hedge_budget_percent" field.
+func (u *GroupUpsertBulk) AddHedgeBudgetPercent(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddHedgeBudgetPercent(v)
+	})
+}
+
+// UpdateHedgeBudgetPercent sets the "hedge_budget_percent" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateHedgeBudgetPercent() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateHedgeBudgetPercent()
+	})
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (u *GroupUpsertBulk) SetScheduleStrategy(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetScheduleStrategy(v)
+	})
+}
+
+// UpdateScheduleStrategy sets the "schedule_strategy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateScheduleStrategy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateScheduleStrategy()
+	})
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (u *GroupUpsertBulk) SetBillingReservationMode(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetBillingReservationMode(v)
+	})
+}
+
+// UpdateBillingReservationMode sets the "billing_reservation_mode" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateBillingReservationMode() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateBillingReservationMode()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -21,6 +21,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
 	"github.com/Wei-Shaw/sub2api/ent/usersubscription"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // GroupUpdate is the builder for updating Group entities.
@@ -645,6 +646,18 @@ func (_u *GroupUpdate) SetNillableFallbackModel(v *string) *GroupUpdate {
 	return _u
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (_u *GroupUpdate) SetModelPrices(v map[string]domain.GroupModelPrice) *GroupUpdate {
+	_u.mutation.SetModelPrices(v)
+	return _u
+}
+
+// ClearModelPrices clears the value of the "model_prices" field.
+func (_u *GroupUpdate) ClearModelPrices() *GroupUpdate {
+	_u.mutation.ClearModelPrices()
+	return _u
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (_u *GroupUpdate) SetMcpXMLInject(v bool) *GroupUpdate {
 	_u.mutation.SetMcpXMLInject(v)
@@ -748,6 +761,111 @@ func (_u *GroupUpdate) SetNillableDefaultMappedModel(v *string) *GroupUpdate {
 	return _u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *GroupUpdate) SetResponseCacheEnabled(v bool) *GroupUpdate {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheEnabled(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdate) SetResponseCacheTTLSeconds(v int) *GroupUpdate {
+	_u.mutation.ResetResponseCacheTTLSeconds()
+	_u.mutation.SetResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheTTLSeconds(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheTTLSeconds(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheTTLSeconds adds value to the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdate) AddResponseCacheTTLSeconds(v int) *GroupUpdate {
+	_u.mutation.AddResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdate) SetResponseCacheHitMultiplier(v float64) *GroupUpdate {
+	_u.mutation.ResetResponseCacheHitMultiplier()
+	_u.mutation.SetResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetNillableResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheHitMultiplier(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheHitMultiplier(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheHitMultiplier adds value to the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdate) AddResponseCacheHitMultiplier(v float64) *GroupUpdate {
+	_u.mutation.AddResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (_u *GroupUpdate) SetHedgeBudgetPercent(v float64) *GroupUpdate {
+	_u.mutation.ResetHedgeBudgetPercent()
+	_u.mutation.SetHedgeBudgetPercent(v)
+	return _u
+}
+
+// SetNillableHedgeBudgetPercent sets the "hedge_budget_percent" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableHedgeBudgetPercent(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetHedgeBudgetPercent(*v)
+	}
+	return _u
+}
+
+// AddHedgeBudgetPercent adds value to the "hedge_budget_percent" field.
+func (_u *GroupUpdate) AddHedgeBudgetPercent(v float64) *GroupUpdate {
+	_u.mutation.AddHedgeBudgetPercent(v)
+	return _u
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (_u *GroupUpdate) SetScheduleStrategy(v string) *GroupUpdate {
+	_u.mutation.SetScheduleStrategy(v)
+	return _u
+}
+
+// SetNillableScheduleStrategy sets the "schedule_strategy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableScheduleStrategy(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetScheduleStrategy(*v)
+	}
+	return _u
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (_u *GroupUpdate) SetBillingReservationMode(v string) *GroupUpdate {
+	_u.mutation.SetBillingReservationMode(v)
+	return _u
+}
+
+// SetNillableBillingReservationMode sets the "billing_reservation_mode" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableBillingReservationMode(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetBillingReservationMode(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -1079,6 +1197,16 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ScheduleStrategy(); ok {
+		if err := group.ScheduleStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "schedule_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.schedule_strategy": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BillingReservationMode(); ok {
+		if err := group.BillingReservationModeValidator(v); err != nil {
+			return &ValidationError{Name: "billing_reservation_mode", err: fmt.Errorf(`ent: validator failed for field "Group.billing_reservation_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1283,6 +1411,12 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.FallbackModel(); ok {
 		_spec.SetField(group.FieldFallbackModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.ModelPrices(); ok {
+		_spec.SetField(group.FieldModelPrices, field.TypeJSON, value)
+	}
+	if _u.mutation.ModelPricesCleared() {
+		_spec.ClearField(group.FieldModelPrices, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.McpXMLInject(); ok {
 		_spec.SetField(group.FieldMcpXMLInject, field.TypeBool, value)
 	}
@@ -1312,6 +1446,33 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.DefaultMappedModel(); ok {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheTTLSeconds(); ok {
+		_spec.AddField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheHitMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheHitMultiplier(); ok {
+		_spec.AddField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.HedgeBudgetPercent(); ok {
+		_spec.SetField(group.FieldHedgeBudgetPercent, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedHedgeBudgetPercent(); ok {
+		_spec.AddField(group.FieldHedgeBudgetPercent, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.ScheduleStrategy(); ok {
+		_spec.SetField(group.FieldScheduleStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BillingReservationMode(); ok {
+		_spec.SetField(group.FieldBillingReservationMode, field.TypeString, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2280,6 +2441,18 @@ func (_u *GroupUpdateOne) SetNillableFallbackModel(v *string) *GroupUpdateOne {
 	return _u
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (_u *GroupUpdateOne) SetModelPrices(v map[string]domain.GroupModelPrice) *GroupUpdateOne {
+	_u.mutation.SetModelPrices(v)
+	return _u
+}
+
+// ClearModelPrices clears the value of the "model_prices" field.
+func (_u *GroupUpdateOne) ClearModelPrices() *GroupUpdateOne {
+	_u.mutation.ClearModelPrices()
+	return _u
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (_u *GroupUpdateOne) SetMcpXMLInject(v bool) *GroupUpdateOne {
 	_u.mutation.SetMcpXMLInject(v)
@@ -2383,6 +2556,111 @@ func (_u *GroupUpdateOne) SetNillableDefaultMappedModel(v *string) *GroupUpdateO
 	return _u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *GroupUpdateOne) SetResponseCacheEnabled(v bool) *GroupUpdateOne {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheEnabled(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdateOne) SetResponseCacheTTLSeconds(v int) *GroupUpdateOne {
+	_u.mutation.ResetResponseCacheTTLSeconds()
+	_u.mutation.SetResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheTTLSeconds(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheTTLSeconds(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheTTLSeconds adds value to the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdateOne) AddResponseCacheTTLSeconds(v int) *GroupUpdateOne {
+	_u.mutation.AddResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdateOne) SetResponseCacheHitMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.ResetResponseCacheHitMultiplier()
+	_u.mutation.SetResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetNillableResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheHitMultiplier(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheHitMultiplier(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheHitMultiplier adds value to the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdateOne) AddResponseCacheHitMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.AddResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (_u *GroupUpdateOne) SetHedgeBudgetPercent(v float64) *GroupUpdateOne {
+	_u.mutation.ResetHedgeBudgetPercent()
+	_u.mutation.SetHedgeBudgetPercent(v)
+	return _u
+}
+
+// SetNillableHedgeBudgetPercent sets the "hedge_budget_percent" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableHedgeBudgetPercent(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetHedgeBudgetPercent(*v)
+	}
+	return _u
+}
+
+// AddHedgeBudgetPercent adds value to the "hedge_budget_percent" field.
+func (_u *GroupUpdateOne) AddHedgeBudgetPercent(v float64) *GroupUpdateOne {
+	_u.mutation.AddHedgeBudgetPercent(v)
+	return _u
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (_u *GroupUpdateOne) SetScheduleStrategy(v string) *GroupUpdateOne {
+	_u.mutation.SetScheduleStrategy(v)
+	return _u
+}
+
+// SetNillableScheduleStrategy sets the "schedule_strategy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableScheduleStrategy(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetScheduleStrategy(*v)
+	}
+	return _u
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (_u *GroupUpdateOne) SetBillingReservationMode(v string) *GroupUpdateOne {
+	_u.mutation.SetBillingReservationMode(v)
+	return _u
+}
+
+// SetNillableBillingReservationMode sets the "billing_reservation_mode" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableBillingReservationMode(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetBillingReservationMode(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2727,6 +3005,16 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ScheduleStrategy(); ok {
+		if err := group.ScheduleStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "schedule_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.schedule_strategy": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BillingReservationMode(); ok {
+		if err := group.BillingReservationModeValidator(v); err != nil {
+			return &ValidationError{Name: "billing_reservation_mode", err: fmt.Errorf(`ent: validator failed for field "Group.billing_reservation_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2948,6 +3236,12 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.FallbackModel(); ok {
 		_spec.SetField(group.FieldFallbackModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.ModelPrices(); ok {
+		_spec.SetField(group.FieldModelPrices, field.TypeJSON, value)
+	}
+	if _u.mutation.ModelPricesCleared() {
+		_spec.ClearField(group.FieldModelPrices, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.McpXMLInject(); ok {
 		_spec.SetField(group.FieldMcpXMLInject, field.TypeBool, value)
 	}
@@ -2977,6 +3271,33 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.DefaultMappedModel(); ok {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheTTLSeconds(); ok {
+		_spec.AddField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheHitMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheHitMultiplier(); ok {
+		_spec.AddField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.HedgeBudgetPercent(); ok {
+		_spec.SetField(group.FieldHedgeBudgetPercent, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedHedgeBudgetPercent(); ok {
+		_spec.AddField(group.FieldHedgeBudgetPercent, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.ScheduleStrategy(); ok {
+		_spec.SetField(group.FieldScheduleStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BillingReservationMode(); ok {
+		_spec.SetField(group.FieldBillingReservationMode, field.TypeString, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
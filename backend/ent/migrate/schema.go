@@ -456,6 +456,7 @@ var (
 		{Name: "model_routing_enabled", Type: field.TypeBool, Default: false},
 		{Name: "model_aliases", Type: field.TypeJSON, Nullable: true, SchemaType: map[string]string{"postgres": "jsonb"}},
 		{Name: "fallback_model", Type: field.TypeString, Size: 100, Default: ""},
+		{Name: "model_prices", Type: field.TypeJSON, Nullable: true, SchemaType: map[string]string{"postgres": "jsonb"}},
 		{Name: "mcp_xml_inject", Type: field.TypeBool, Default: true},
 		{Name: "supported_model_scopes", Type: field.TypeJSON, SchemaType: map[string]string{"postgres": "jsonb"}},
 		{Name: "sort_order", Type: field.TypeInt, Default: 0},
@@ -463,6 +464,12 @@ var (
 		{Name: "require_oauth_only", Type: field.TypeBool, Default: false},
 		{Name: "require_privacy_set", Type: field.TypeBool, Default: false},
 		{Name: "default_mapped_model", Type: field.TypeString, Size: 100, Default: ""},
+		{Name: "response_cache_enabled", Type: field.TypeBool, Default: false},
+		{Name: "response_cache_ttl_seconds", Type: field.TypeInt, Default: 3600},
+		{Name: "response_cache_hit_multiplier", Type: field.TypeFloat64, Default: 0.1, SchemaType: map[string]string{"postgres": "decimal(10,4)"}},
+		{Name: "hedge_budget_percent", Type: field.TypeFloat64, Default: 0, SchemaType: map[string]string{"postgres": "decimal(5,2)"}},
+		{Name: "schedule_strategy", Type: field.TypeString, Size: 32, Default: ""},
+		{Name: "billing_reservation_mode", Type: field.TypeString, Size: 16, Default: "optimistic"},
 	}
 	// GroupsTable holds the schema information for the "groups" table.
 	GroupsTable = &schema.Table{
@@ -498,7 +505,7 @@ var (
 			{
 				Name:    "group_sort_order",
 				Unique:  false,
-				Columns: []*schema.Column{GroupsColumns[34]},
+				Columns: []*schema.Column{GroupsColumns[35]},
 			},
 		},
 	}
@@ -1005,6 +1012,9 @@ var (
 		{Name: "totp_enabled_at", Type: field.TypeTime, Nullable: true},
 		{Name: "sora_storage_quota_bytes", Type: field.TypeInt64, Default: 0},
 		{Name: "sora_storage_used_bytes", Type: field.TypeInt64, Default: 0},
+		{Name: "billing_mode", Type: field.TypeString, Size: 16, Default: "prepaid"},
+		{Name: "credit_limit", Type: field.TypeFloat64, Default: 0, SchemaType: map[string]string{"postgres": "decimal(20,8)"}},
+		{Name: "billing_cycle_day", Type: field.TypeInt, Default: 1},
 	}
 	// UsersTable holds the schema information for the "users" table.
 	UsersTable = &schema.Table{
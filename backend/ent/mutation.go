@@ -10023,6 +10023,7 @@ type GroupMutation struct {
 	model_routing_enabled                   *bool
 	model_aliases                           *map[string]string
 	fallback_model                          *string
+	model_prices                            *map[string]domain.GroupModelPrice
 	mcp_xml_inject                          *bool
 	supported_model_scopes                  *[]string
 	appendsupported_model_scopes            []string
@@ -10032,6 +10033,15 @@ type GroupMutation struct {
 	require_oauth_only                      *bool
 	require_privacy_set                     *bool
 	default_mapped_model                    *string
+	response_cache_enabled                  *bool
+	response_cache_ttl_seconds              *int
+	addresponse_cache_ttl_seconds           *int
+	response_cache_hit_multiplier           *float64
+	addresponse_cache_hit_multiplier        *float64
+	hedge_budget_percent                    *float64
+	addhedge_budget_percent                 *float64
+	schedule_strategy                       *string
+	billing_reservation_mode                *string
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -11819,6 +11829,55 @@ func (m *GroupMutation) ResetFallbackModel() {
 	m.fallback_model = nil
 }
 
+// SetModelPrices sets the "model_prices" field.
+func (m *GroupMutation) SetModelPrices(mmp map[string]domain.GroupModelPrice) {
+	m.model_prices = &mmp
+}
+
+// ModelPrices returns the value of the "model_prices" field in the mutation.
+func (m *GroupMutation) ModelPrices() (r map[string]domain.GroupModelPrice, exists bool) {
+	v := m.model_prices
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelPrices returns the old "model_prices" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldModelPrices(ctx context.Context) (v map[string]domain.GroupModelPrice, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelPrices is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelPrices requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelPrices: %w", err)
+	}
+	return oldValue.ModelPrices, nil
+}
+
+// ClearModelPrices clears the value of the "model_prices" field.
+func (m *GroupMutation) ClearModelPrices() {
+	m.model_prices = nil
+	m.clearedFields[group.FieldModelPrices] = struct{}{}
+}
+
+// ModelPricesCleared returns if the "model_prices" field was cleared in this mutation.
+func (m *GroupMutation) ModelPricesCleared() bool {
+	_, ok := m.clearedFields[group.FieldModelPrices]
+	return ok
+}
+
+// ResetModelPrices resets all changes to the "model_prices" field.
+func (m *GroupMutation) ResetModelPrices() {
+	m.model_prices = nil
+	delete(m.clearedFields, group.FieldModelPrices)
+}
+
 // SetMcpXMLInject sets the "mcp_xml_inject" field.
 func (m *GroupMutation) SetMcpXMLInject(b bool) {
 	m.mcp_xml_inject = &b
@@ -12106,6 +12165,282 @@ func (m *GroupMutation) ResetDefaultMappedModel() {
 	m.default_mapped_model = nil
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (m *GroupMutation) SetResponseCacheEnabled(b bool) {
+	m.response_cache_enabled = &b
+}
+
+// ResponseCacheEnabled returns the value of the "response_cache_enabled" field in the mutation.
+func (m *GroupMutation) ResponseCacheEnabled() (r bool, exists bool) {
+	v := m.response_cache_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheEnabled returns the old "response_cache_enabled" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheEnabled: %w", err)
+	}
+	return oldValue.ResponseCacheEnabled, nil
+}
+
+// ResetResponseCacheEnabled resets all changes to the "response_cache_enabled" field.
+func (m *GroupMutation) ResetResponseCacheEnabled() {
+	m.response_cache_enabled = nil
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) SetResponseCacheTTLSeconds(i int) {
+	m.response_cache_ttl_seconds = &i
+	m.addresponse_cache_ttl_seconds = nil
+}
+
+// ResponseCacheTTLSeconds returns the value of the "response_cache_ttl_seconds" field in the mutation.
+func (m *GroupMutation) ResponseCacheTTLSeconds() (r int, exists bool) {
+	v := m.response_cache_ttl_seconds
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheTTLSeconds returns the old "response_cache_ttl_seconds" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheTTLSeconds(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheTTLSeconds is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheTTLSeconds requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheTTLSeconds: %w", err)
+	}
+	return oldValue.ResponseCacheTTLSeconds, nil
+}
+
+// AddResponseCacheTTLSeconds adds i to the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) AddResponseCacheTTLSeconds(i int) {
+	if m.addresponse_cache_ttl_seconds != nil {
+		*m.addresponse_cache_ttl_seconds += i
+	} else {
+		m.addresponse_cache_ttl_seconds = &i
+	}
+}
+
+// AddedResponseCacheTTLSeconds returns the value that was added to the "response_cache_ttl_seconds" field in this mutation.
+func (m *GroupMutation) AddedResponseCacheTTLSeconds() (r int, exists bool) {
+	v := m.addresponse_cache_ttl_seconds
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseCacheTTLSeconds resets all changes to the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) ResetResponseCacheTTLSeconds() {
+	m.response_cache_ttl_seconds = nil
+	m.addresponse_cache_ttl_seconds = nil
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (m *GroupMutation) SetResponseCacheHitMultiplier(f float64) {
+	m.response_cache_hit_multiplier = &f
+	m.addresponse_cache_hit_multiplier = nil
+}
+
+// ResponseCacheHitMultiplier returns the value of the "response_cache_hit_multiplier" field in the mutation.
+func (m *GroupMutation) ResponseCacheHitMultiplier() (r float64, exists bool) {
+	v := m.response_cache_hit_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheHitMultiplier returns the old "response_cache_hit_multiplier" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheHitMultiplier(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheHitMultiplier is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheHitMultiplier requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheHitMultiplier: %w", err)
+	}
+	return oldValue.ResponseCacheHitMultiplier, nil
+}
+
+// AddResponseCacheHitMultiplier adds f to the "response_cache_hit_multiplier" field.
+func (m *GroupMutation) AddResponseCacheHitMultiplier(f float64) {
+	if m.addresponse_cache_hit_multiplier != nil {
+		*m.addresponse_cache_hit_multiplier += f
+	} else {
+		m.addresponse_cache_hit_multiplier = &f
+	}
+}
+
+// AddedResponseCacheHitMultiplier returns the value that was added to the "response_cache_hit_multiplier" field in this mutation.
+func (m *GroupMutation) AddedResponseCacheHitMultiplier() (r float64, exists bool) {
+	v := m.addresponse_cache_hit_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseCacheHitMultiplier resets all changes to the "response_cache_hit_multiplier" field.
+func (m *GroupMutation) ResetResponseCacheHitMultiplier() {
+	m.response_cache_hit_multiplier = nil
+	m.addresponse_cache_hit_multiplier = nil
+}
+
+// SetHedgeBudgetPercent sets the "hedge_budget_percent" field.
+func (m *GroupMutation) SetHedgeBudgetPercent(f float64) {
+	m.hedge_budget_percent = &f
+	m.addhedge_budget_percent = nil
+}
+
+// HedgeBudgetPercent returns the value of the "hedge_budget_percent" field in the mutation.
+func (m *GroupMutation) HedgeBudgetPercent() (r float64, exists bool) {
+	v := m.hedge_budget_percent
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldHedgeBudgetPercent returns the old "hedge_budget_percent" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldHedgeBudgetPercent(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldHedgeBudgetPercent is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldHedgeBudgetPercent requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldHedgeBudgetPercent: %w", err)
+	}
+	return oldValue.HedgeBudgetPercent, nil
+}
+
+// AddHedgeBudgetPercent adds f to the "hedge_budget_percent" field.
+func (m *GroupMutation) AddHedgeBudgetPercent(f float64) {
+	if m.addhedge_budget_percent != nil {
+		*m.addhedge_budget_percent += f
+	} else {
+		m.addhedge_budget_percent = &f
+	}
+}
+
+// AddedHedgeBudgetPercent returns the value that was added to the "hedge_budget_percent" field in this mutation.
+func (m *GroupMutation) AddedHedgeBudgetPercent() (r float64, exists bool) {
+	v := m.addhedge_budget_percent
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetHedgeBudgetPercent resets all changes to the "hedge_budget_percent" field.
+func (m *GroupMutation) ResetHedgeBudgetPercent() {
+	m.hedge_budget_percent = nil
+	m.addhedge_budget_percent = nil
+}
+
+// SetScheduleStrategy sets the "schedule_strategy" field.
+func (m *GroupMutation) SetScheduleStrategy(s string) {
+	m.schedule_strategy = &s
+}
+
+// ScheduleStrategy returns the value of the "schedule_strategy" field in the mutation.
+func (m *GroupMutation) ScheduleStrategy() (r string, exists bool) {
+	v := m.schedule_strategy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldScheduleStrategy returns the old "schedule_strategy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldScheduleStrategy(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldScheduleStrategy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldScheduleStrategy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldScheduleStrategy: %w", err)
+	}
+	return oldValue.ScheduleStrategy, nil
+}
+
+// ResetScheduleStrategy resets all changes to the "schedule_strategy" field.
+func (m *GroupMutation) ResetScheduleStrategy() {
+	m.schedule_strategy = nil
+}
+
+// SetBillingReservationMode sets the "billing_reservation_mode" field.
+func (m *GroupMutation) SetBillingReservationMode(s string) {
+	m.billing_reservation_mode = &s
+}
+
+// BillingReservationMode returns the value of the "billing_reservation_mode" field in the mutation.
+func (m *GroupMutation) BillingReservationMode() (r string, exists bool) {
+	v := m.billing_reservation_mode
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBillingReservationMode returns the old "billing_reservation_mode" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldBillingReservationMode(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBillingReservationMode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBillingReservationMode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBillingReservationMode: %w", err)
+	}
+	return oldValue.BillingReservationMode, nil
+}
+
+// ResetBillingReservationMode resets all changes to the "billing_reservation_mode" field.
+func (m *GroupMutation) ResetBillingReservationMode() {
+	m.billing_reservation_mode = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *GroupMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -12518,7 +12853,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 38)
+	fields := make([]string, 0, 45)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -12612,6 +12947,9 @@ func (m *GroupMutation) Fields() []string {
 	if m.fallback_model != nil {
 		fields = append(fields, group.FieldFallbackModel)
 	}
+	if m.model_prices != nil {
+		fields = append(fields, group.FieldModelPrices)
+	}
 	if m.mcp_xml_inject != nil {
 		fields = append(fields, group.FieldMcpXMLInject)
 	}
@@ -12633,6 +12971,24 @@ func (m *GroupMutation) Fields() []string {
 	if m.default_mapped_model != nil {
 		fields = append(fields, group.FieldDefaultMappedModel)
 	}
+	if m.response_cache_enabled != nil {
+		fields = append(fields, group.FieldResponseCacheEnabled)
+	}
+	if m.response_cache_ttl_seconds != nil {
+		fields = append(fields, group.FieldResponseCacheTTLSeconds)
+	}
+	if m.response_cache_hit_multiplier != nil {
+		fields = append(fields, group.FieldResponseCacheHitMultiplier)
+	}
+	if m.hedge_budget_percent != nil {
+		fields = append(fields, group.FieldHedgeBudgetPercent)
+	}
+	if m.schedule_strategy != nil {
+		fields = append(fields, group.FieldScheduleStrategy)
+	}
+	if m.billing_reservation_mode != nil {
+		fields = append(fields, group.FieldBillingReservationMode)
+	}
 	return fields
 }
 
@@ -12703,6 +13059,8 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.ModelAliases()
 	case group.FieldFallbackModel:
 		return m.FallbackModel()
+	case group.FieldModelPrices:
+		return m.ModelPrices()
 	case group.FieldMcpXMLInject:
 		return m.McpXMLInject()
 	case group.FieldSupportedModelScopes:
@@ -12717,6 +13075,18 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.RequirePrivacySet()
 	case group.FieldDefaultMappedModel:
 		return m.DefaultMappedModel()
+	case group.FieldResponseCacheEnabled:
+		return m.ResponseCacheEnabled()
+	case group.FieldResponseCacheTTLSeconds:
+		return m.ResponseCacheTTLSeconds()
+	case group.FieldResponseCacheHitMultiplier:
+		return m.ResponseCacheHitMultiplier()
+	case group.FieldHedgeBudgetPercent:
+		return m.HedgeBudgetPercent()
+	case group.FieldScheduleStrategy:
+		return m.ScheduleStrategy()
+	case group.FieldBillingReservationMode:
+		return m.BillingReservationMode()
 	}
 	return nil, false
 }
@@ -12788,6 +13158,8 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldModelAliases(ctx)
 	case group.FieldFallbackModel:
 		return m.OldFallbackModel(ctx)
+	case group.FieldModelPrices:
+		return m.OldModelPrices(ctx)
 	case group.FieldMcpXMLInject:
 		return m.OldMcpXMLInject(ctx)
 	case group.FieldSupportedModelScopes:
@@ -12802,6 +13174,18 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldRequirePrivacySet(ctx)
 	case group.FieldDefaultMappedModel:
 		return m.OldDefaultMappedModel(ctx)
+	case group.FieldResponseCacheEnabled:
+		return m.OldResponseCacheEnabled(ctx)
+	case group.FieldResponseCacheTTLSeconds:
+		return m.OldResponseCacheTTLSeconds(ctx)
+	case group.FieldResponseCacheHitMultiplier:
+		return m.OldResponseCacheHitMultiplier(ctx)
+	case group.FieldHedgeBudgetPercent:
+		return m.OldHedgeBudgetPercent(ctx)
+	case group.FieldScheduleStrategy:
+		return m.OldScheduleStrategy(ctx)
+	case group.FieldBillingReservationMode:
+		return m.OldBillingReservationMode(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -13028,6 +13412,13 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetFallbackModel(v)
 		return nil
+	case group.FieldModelPrices:
+		v, ok := value.(map[string]domain.GroupModelPrice)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelPrices(v)
+		return nil
 	case group.FieldMcpXMLInject:
 		v, ok := value.(bool)
 		if !ok {
@@ -13077,6 +13468,48 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetDefaultMappedModel(v)
 		return nil
+	case group.FieldResponseCacheEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheEnabled(v)
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheTTLSeconds(v)
+		return nil
+	case group.FieldResponseCacheHitMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheHitMultiplier(v)
+		return nil
+	case group.FieldHedgeBudgetPercent:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHedgeBudgetPercent(v)
+		return nil
+	case group.FieldScheduleStrategy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetScheduleStrategy(v)
+		return nil
+	case group.FieldBillingReservationMode:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBillingReservationMode(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -13133,6 +13566,15 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addsort_order != nil {
 		fields = append(fields, group.FieldSortOrder)
 	}
+	if m.addresponse_cache_ttl_seconds != nil {
+		fields = append(fields, group.FieldResponseCacheTTLSeconds)
+	}
+	if m.addresponse_cache_hit_multiplier != nil {
+		fields = append(fields, group.FieldResponseCacheHitMultiplier)
+	}
+	if m.addhedge_budget_percent != nil {
+		fields = append(fields, group.FieldHedgeBudgetPercent)
+	}
 	return fields
 }
 
@@ -13173,6 +13615,12 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedFallbackGroupIDOnInvalidRequest()
 	case group.FieldSortOrder:
 		return m.AddedSortOrder()
+	case group.FieldResponseCacheTTLSeconds:
+		return m.AddedResponseCacheTTLSeconds()
+	case group.FieldResponseCacheHitMultiplier:
+		return m.AddedResponseCacheHitMultiplier()
+	case group.FieldHedgeBudgetPercent:
+		return m.AddedHedgeBudgetPercent()
 	}
 	return nil, false
 }
@@ -13294,6 +13742,27 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddSortOrder(v)
 		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseCacheTTLSeconds(v)
+		return nil
+	case group.FieldResponseCacheHitMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseCacheHitMultiplier(v)
+		return nil
+	case group.FieldHedgeBudgetPercent:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddHedgeBudgetPercent(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -13356,6 +13825,9 @@ func (m *GroupMutation) ClearedFields() []string {
 	if m.FieldCleared(group.FieldModelAliases) {
 		fields = append(fields, group.FieldModelAliases)
 	}
+	if m.FieldCleared(group.FieldModelPrices) {
+		fields = append(fields, group.FieldModelPrices)
+	}
 	return fields
 }
 
@@ -13424,6 +13896,9 @@ func (m *GroupMutation) ClearField(name string) error {
 	case group.FieldModelAliases:
 		m.ClearModelAliases()
 		return nil
+	case group.FieldModelPrices:
+		m.ClearModelPrices()
+		return nil
 	}
 	return fmt.Errorf("unknown Group nullable field %s", name)
 }
@@ -13525,6 +14000,9 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldFallbackModel:
 		m.ResetFallbackModel()
 		return nil
+	case group.FieldModelPrices:
+		m.ResetModelPrices()
+		return nil
 	case group.FieldMcpXMLInject:
 		m.ResetMcpXMLInject()
 		return nil
@@ -13546,6 +14024,24 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldDefaultMappedModel:
 		m.ResetDefaultMappedModel()
 		return nil
+	case group.FieldResponseCacheEnabled:
+		m.ResetResponseCacheEnabled()
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		m.ResetResponseCacheTTLSeconds()
+		return nil
+	case group.FieldResponseCacheHitMultiplier:
+		m.ResetResponseCacheHitMultiplier()
+		return nil
+	case group.FieldHedgeBudgetPercent:
+		m.ResetHedgeBudgetPercent()
+		return nil
+	case group.FieldScheduleStrategy:
+		m.ResetScheduleStrategy()
+		return nil
+	case group.FieldBillingReservationMode:
+		m.ResetBillingReservationMode()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -26487,6 +26983,11 @@ type UserMutation struct {
 	addsora_storage_quota_bytes   *int64
 	sora_storage_used_bytes       *int64
 	addsora_storage_used_bytes    *int64
+	billing_mode                  *string
+	credit_limit                  *float64
+	addcredit_limit               *float64
+	billing_cycle_day             *int
+	addbilling_cycle_day          *int
 	clearedFields                 map[string]struct{}
 	api_keys                      map[int64]struct{}
 	removedapi_keys               map[int64]struct{}
@@ -27313,6 +27814,154 @@ func (m *UserMutation) ResetSoraStorageUsedBytes() {
 	m.addsora_storage_used_bytes = nil
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (m *UserMutation) SetBillingMode(s string) {
+	m.billing_mode = &s
+}
+
+// BillingMode returns the value of the "billing_mode" field in the mutation.
+func (m *UserMutation) BillingMode() (r string, exists bool) {
+	v := m.billing_mode
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBillingMode returns the old "billing_mode" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldBillingMode(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBillingMode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBillingMode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBillingMode: %w", err)
+	}
+	return oldValue.BillingMode, nil
+}
+
+// ResetBillingMode resets all changes to the "billing_mode" field.
+func (m *UserMutation) ResetBillingMode() {
+	m.billing_mode = nil
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (m *UserMutation) SetCreditLimit(f float64) {
+	m.credit_limit = &f
+	m.addcredit_limit = nil
+}
+
+// CreditLimit returns the value of the "credit_limit" field in the mutation.
+func (m *UserMutation) CreditLimit() (r float64, exists bool) {
+	v := m.credit_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreditLimit returns the old "credit_limit" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldCreditLimit(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreditLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreditLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreditLimit: %w", err)
+	}
+	return oldValue.CreditLimit, nil
+}
+
+// AddCreditLimit adds f to the "credit_limit" field.
+func (m *UserMutation) AddCreditLimit(f float64) {
+	if m.addcredit_limit != nil {
+		*m.addcredit_limit += f
+	} else {
+		m.addcredit_limit = &f
+	}
+}
+
+// AddedCreditLimit returns the value that was added to the "credit_limit" field in this mutation.
+func (m *UserMutation) AddedCreditLimit() (r float64, exists bool) {
+	v := m.addcredit_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetCreditLimit resets all changes to the "credit_limit" field.
+func (m *UserMutation) ResetCreditLimit() {
+	m.credit_limit = nil
+	m.addcredit_limit = nil
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (m *UserMutation) SetBillingCycleDay(i int) {
+	m.billing_cycle_day = &i
+	m.addbilling_cycle_day = nil
+}
+
+// BillingCycleDay returns the value of the "billing_cycle_day" field in the mutation.
+func (m *UserMutation) BillingCycleDay() (r int, exists bool) {
+	v := m.billing_cycle_day
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBillingCycleDay returns the old "billing_cycle_day" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldBillingCycleDay(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBillingCycleDay is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBillingCycleDay requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBillingCycleDay: %w", err)
+	}
+	return oldValue.BillingCycleDay, nil
+}
+
+// AddBillingCycleDay adds i to the "billing_cycle_day" field.
+func (m *UserMutation) AddBillingCycleDay(i int) {
+	if m.addbilling_cycle_day != nil {
+		*m.addbilling_cycle_day += i
+	} else {
+		m.addbilling_cycle_day = &i
+	}
+}
+
+// AddedBillingCycleDay returns the value that was added to the "billing_cycle_day" field in this mutation.
+func (m *UserMutation) AddedBillingCycleDay() (r int, exists bool) {
+	v := m.addbilling_cycle_day
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetBillingCycleDay resets all changes to the "billing_cycle_day" field.
+func (m *UserMutation) ResetBillingCycleDay() {
+	m.billing_cycle_day = nil
+	m.addbilling_cycle_day = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *UserMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -27833,7 +28482,7 @@ func (m *UserMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *UserMutation) Fields() []string {
-	fields := make([]string, 0, 16)
+	fields := make([]string, 0, 19)
 	if m.created_at != nil {
 		fields = append(fields, user.FieldCreatedAt)
 	}
@@ -27882,6 +28531,15 @@ func (m *UserMutation) Fields() []string {
 	if m.sora_storage_used_bytes != nil {
 		fields = append(fields, user.FieldSoraStorageUsedBytes)
 	}
+	if m.billing_mode != nil {
+		fields = append(fields, user.FieldBillingMode)
+	}
+	if m.credit_limit != nil {
+		fields = append(fields, user.FieldCreditLimit)
+	}
+	if m.billing_cycle_day != nil {
+		fields = append(fields, user.FieldBillingCycleDay)
+	}
 	return fields
 }
 
@@ -27922,6 +28580,12 @@ func (m *UserMutation) Field(name string) (ent.Value, bool) {
 		return m.SoraStorageQuotaBytes()
 	case user.FieldSoraStorageUsedBytes:
 		return m.SoraStorageUsedBytes()
+	case user.FieldBillingMode:
+		return m.BillingMode()
+	case user.FieldCreditLimit:
+		return m.CreditLimit()
+	case user.FieldBillingCycleDay:
+		return m.BillingCycleDay()
 	}
 	return nil, false
 }
@@ -27963,6 +28627,12 @@ func (m *UserMutation) OldField(ctx context.Context, name string) (ent.Value, er
 		return m.OldSoraStorageQuotaBytes(ctx)
 	case user.FieldSoraStorageUsedBytes:
 		return m.OldSoraStorageUsedBytes(ctx)
+	case user.FieldBillingMode:
+		return m.OldBillingMode(ctx)
+	case user.FieldCreditLimit:
+		return m.OldCreditLimit(ctx)
+	case user.FieldBillingCycleDay:
+		return m.OldBillingCycleDay(ctx)
 	}
 	return nil, fmt.Errorf("unknown User field %s", name)
 }
@@ -28084,6 +28754,27 @@ func (m *UserMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetSoraStorageUsedBytes(v)
 		return nil
+	case user.FieldBillingMode:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBillingMode(v)
+		return nil
+	case user.FieldCreditLimit:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreditLimit(v)
+		return nil
+	case user.FieldBillingCycleDay:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBillingCycleDay(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
@@ -28104,6 +28795,12 @@ func (m *UserMutation) AddedFields() []string {
 	if m.addsora_storage_used_bytes != nil {
 		fields = append(fields, user.FieldSoraStorageUsedBytes)
 	}
+	if m.addcredit_limit != nil {
+		fields = append(fields, user.FieldCreditLimit)
+	}
+	if m.addbilling_cycle_day != nil {
+		fields = append(fields, user.FieldBillingCycleDay)
+	}
 	return fields
 }
 
@@ -28120,6 +28817,10 @@ func (m *UserMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedSoraStorageQuotaBytes()
 	case user.FieldSoraStorageUsedBytes:
 		return m.AddedSoraStorageUsedBytes()
+	case user.FieldCreditLimit:
+		return m.AddedCreditLimit()
+	case user.FieldBillingCycleDay:
+		return m.AddedBillingCycleDay()
 	}
 	return nil, false
 }
@@ -28157,6 +28858,20 @@ func (m *UserMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddSoraStorageUsedBytes(v)
 		return nil
+	case user.FieldCreditLimit:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddCreditLimit(v)
+		return nil
+	case user.FieldBillingCycleDay:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddBillingCycleDay(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User numeric field %s", name)
 }
@@ -28253,6 +28968,15 @@ func (m *UserMutation) ResetField(name string) error {
 	case user.FieldSoraStorageUsedBytes:
 		m.ResetSoraStorageUsedBytes()
 		return nil
+	case user.FieldBillingMode:
+		m.ResetBillingMode()
+		return nil
+	case user.FieldCreditLimit:
+		m.ResetCreditLimit()
+		return nil
+	case user.FieldBillingCycleDay:
+		m.ResetBillingCycleDay()
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
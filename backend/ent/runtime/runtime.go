@@ -542,35 +542,63 @@ func init() {
 	// group.FallbackModelValidator is a validator for the "fallback_model" field. It is called by the builders before save.
 	group.FallbackModelValidator = groupDescFallbackModel.Validators[0].(func(string) error)
 	// groupDescMcpXMLInject is the schema descriptor for mcp_xml_inject field.
-	groupDescMcpXMLInject := groupFields[28].Descriptor()
+	groupDescMcpXMLInject := groupFields[29].Descriptor()
 	// group.DefaultMcpXMLInject holds the default value on creation for the mcp_xml_inject field.
 	group.DefaultMcpXMLInject = groupDescMcpXMLInject.Default.(bool)
 	// groupDescSupportedModelScopes is the schema descriptor for supported_model_scopes field.
-	groupDescSupportedModelScopes := groupFields[29].Descriptor()
+	groupDescSupportedModelScopes := groupFields[30].Descriptor()
 	// group.DefaultSupportedModelScopes holds the default value on creation for the supported_model_scopes field.
 	group.DefaultSupportedModelScopes = groupDescSupportedModelScopes.Default.([]string)
 	// groupDescSortOrder is the schema descriptor for sort_order field.
-	groupDescSortOrder := groupFields[30].Descriptor()
+	groupDescSortOrder := groupFields[31].Descriptor()
 	// group.DefaultSortOrder holds the default value on creation for the sort_order field.
 	group.DefaultSortOrder = groupDescSortOrder.Default.(int)
 	// groupDescAllowMessagesDispatch is the schema descriptor for allow_messages_dispatch field.
-	groupDescAllowMessagesDispatch := groupFields[31].Descriptor()
+	groupDescAllowMessagesDispatch := groupFields[32].Descriptor()
 	// group.DefaultAllowMessagesDispatch holds the default value on creation for the allow_messages_dispatch field.
 	group.DefaultAllowMessagesDispatch = groupDescAllowMessagesDispatch.Default.(bool)
 	// groupDescRequireOauthOnly is the schema descriptor for require_oauth_only field.
-	groupDescRequireOauthOnly := groupFields[32].Descriptor()
+	groupDescRequireOauthOnly := groupFields[33].Descriptor()
 	// group.DefaultRequireOauthOnly holds the default value on creation for the require_oauth_only field.
 	group.DefaultRequireOauthOnly = groupDescRequireOauthOnly.Default.(bool)
 	// groupDescRequirePrivacySet is the schema descriptor for require_privacy_set field.
-	groupDescRequirePrivacySet := groupFields[33].Descriptor()
+	groupDescRequirePrivacySet := groupFields[34].Descriptor()
 	// group.DefaultRequirePrivacySet holds the default value on creation for the require_privacy_set field.
 	group.DefaultRequirePrivacySet = groupDescRequirePrivacySet.Default.(bool)
 	// groupDescDefaultMappedModel is the schema descriptor for default_mapped_model field.
-	groupDescDefaultMappedModel := groupFields[34].Descriptor()
+	groupDescDefaultMappedModel := groupFields[35].Descriptor()
 	// group.DefaultDefaultMappedModel holds the default value on creation for the default_mapped_model field.
 	group.DefaultDefaultMappedModel = groupDescDefaultMappedModel.Default.(string)
 	// group.DefaultMappedModelValidator is a validator for the "default_mapped_model" field. It is called by the builders before save.
 	group.DefaultMappedModelValidator = groupDescDefaultMappedModel.Validators[0].(func(string) error)
+	// groupDescResponseCacheEnabled is the schema descriptor for response_cache_enabled field.
+	groupDescResponseCacheEnabled := groupFields[36].Descriptor()
+	// group.DefaultResponseCacheEnabled holds the default value on creation for the response_cache_enabled field.
+	group.DefaultResponseCacheEnabled = groupDescResponseCacheEnabled.Default.(bool)
+	// groupDescResponseCacheTTLSeconds is the schema descriptor for response_cache_ttl_seconds field.
+	groupDescResponseCacheTTLSeconds := groupFields[37].Descriptor()
+	// group.DefaultResponseCacheTTLSeconds holds the default value on creation for the response_cache_ttl_seconds field.
+	group.DefaultResponseCacheTTLSeconds = groupDescResponseCacheTTLSeconds.Default.(int)
+	// groupDescResponseCacheHitMultiplier is the schema descriptor for response_cache_hit_multiplier field.
+	groupDescResponseCacheHitMultiplier := groupFields[38].Descriptor()
+	// group.DefaultResponseCacheHitMultiplier holds the default value on creation for the response_cache_hit_multiplier field.
+	group.DefaultResponseCacheHitMultiplier = groupDescResponseCacheHitMultiplier.Default.(float64)
+	// groupDescHedgeBudgetPercent is the schema descriptor for hedge_budget_percent field.
+	groupDescHedgeBudgetPercent := groupFields[39].Descriptor()
+	// group.DefaultHedgeBudgetPercent holds the default value on creation for the hedge_budget_percent field.
+	group.DefaultHedgeBudgetPercent = groupDescHedgeBudgetPercent.Default.(float64)
+	// groupDescScheduleStrategy is the schema descriptor for schedule_strategy field.
+	groupDescScheduleStrategy := groupFields[40].Descriptor()
+	// group.DefaultScheduleStrategy holds the default value on creation for the schedule_strategy field.
+	group.DefaultScheduleStrategy = groupDescScheduleStrategy.Default.(string)
+	// group.ScheduleStrategyValidator is a validator for the "schedule_strategy" field. It is called by the builders before save.
+	group.ScheduleStrategyValidator = groupDescScheduleStrategy.Validators[0].(func(string) error)
+	// groupDescBillingReservationMode is the schema descriptor for billing_reservation_mode field.
+	groupDescBillingReservationMode := groupFields[41].Descriptor()
+	// group.DefaultBillingReservationMode holds the default value on creation for the billing_reservation_mode field.
+	group.DefaultBillingReservationMode = groupDescBillingReservationMode.Default.(string)
+	// group.BillingReservationModeValidator is a validator for the "billing_reservation_mode" field. It is called by the builders before save.
+	group.BillingReservationModeValidator = groupDescBillingReservationMode.Validators[0].(func(string) error)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
@@ -1214,6 +1242,20 @@ func init() {
 	userDescSoraStorageUsedBytes := userFields[12].Descriptor()
 	// user.DefaultSoraStorageUsedBytes holds the default value on creation for the sora_storage_used_bytes field.
 	user.DefaultSoraStorageUsedBytes = userDescSoraStorageUsedBytes.Default.(int64)
+	// userDescBillingMode is the schema descriptor for billing_mode field.
+	userDescBillingMode := userFields[13].Descriptor()
+	// user.DefaultBillingMode holds the default value on creation for the billing_mode field.
+	user.DefaultBillingMode = userDescBillingMode.Default.(string)
+	// user.BillingModeValidator is a validator for the "billing_mode" field. It is called by the builders before save.
+	user.BillingModeValidator = userDescBillingMode.Validators[0].(func(string) error)
+	// userDescCreditLimit is the schema descriptor for credit_limit field.
+	userDescCreditLimit := userFields[14].Descriptor()
+	// user.DefaultCreditLimit holds the default value on creation for the credit_limit field.
+	user.DefaultCreditLimit = userDescCreditLimit.Default.(float64)
+	// userDescBillingCycleDay is the schema descriptor for billing_cycle_day field.
+	userDescBillingCycleDay := userFields[15].Descriptor()
+	// user.DefaultBillingCycleDay holds the default value on creation for the billing_cycle_day field.
+	user.DefaultBillingCycleDay = userDescBillingCycleDay.Default.(int)
 	userallowedgroupFields := schema.UserAllowedGroup{}.Fields()
 	_ = userallowedgroupFields
 	// userallowedgroupDescCreatedAt is the schema descriptor for created_at field.
@@ -155,6 +155,12 @@ func (Group) Fields() []ent.Field {
 			Default("").
 			Comment("模型别名兜底模型：未匹配到任何别名规则时映射到此模型"),
 
+		// 分组自定义模型价格（支持通配符，如 "claude-opus-*"），优先于全局价格表
+		field.JSON("model_prices", map[string]domain.GroupModelPrice{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("分组模型价格：模型模式 -> 每百万 token 价格"),
+
 		// MCP XML 协议注入开关 (added by migration 042)
 		field.Bool("mcp_xml_inject").
 			Default(true).
@@ -185,6 +191,36 @@ func (Group) Fields() []ent.Field {
 			MaxLen(100).
 			Default("").
 			Comment("默认映射模型 ID，当账号级映射找不到时使用此值"),
+
+		// 响应缓存配置 (added by migration 094)
+		field.Bool("response_cache_enabled").
+			Default(false).
+			Comment("是否对确定性请求启用精确匹配响应缓存"),
+		field.Int("response_cache_ttl_seconds").
+			Default(3600).
+			Comment("响应缓存有效期（秒）"),
+		field.Float("response_cache_hit_multiplier").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
+			Default(0.1).
+			Comment("缓存命中计费倍率，作用于原始请求费用"),
+
+		// 对冲请求预算 (added by migration 095)
+		field.Float("hedge_budget_percent").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(5,2)"}).
+			Default(0).
+			Comment("对冲请求占比上限（百分比），0 表示关闭对冲"),
+
+		// 账号调度策略 (added by migration 096)
+		field.String("schedule_strategy").
+			MaxLen(32).
+			Default("").
+			Comment("账号调度策略：priority_lru/least_load/ewma_latency/cost_aware，空表示网关默认策略"),
+
+		// 计费预留模式 (added by migration 103)
+		field.String("billing_reservation_mode").
+			MaxLen(16).
+			Default("optimistic").
+			Comment("计费预留模式：optimistic 请求后扣费，strict 请求前按预估最大费用预留"),
 	}
 }
 
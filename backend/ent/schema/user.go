@@ -78,6 +78,16 @@ func (User) Fields() []ent.Field {
 			Default(0),
 		field.Int64("sora_storage_used_bytes").
 			Default(0),
+
+		// 后付费（月结）字段
+		field.String("billing_mode").
+			MaxLen(16).
+			Default(domain.BillingModePrepaid),
+		field.Float("credit_limit").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0),
+		field.Int("billing_cycle_day").
+			Default(1),
 	}
 }
 
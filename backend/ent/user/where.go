@@ -135,6 +135,21 @@ func SoraStorageUsedBytes(v int64) predicate.User {
 	return predicate.User(sql.FieldEQ(FieldSoraStorageUsedBytes, v))
 }
 
+// BillingMode applies equality check predicate on the "billing_mode" field. It's identical to BillingModeEQ.
+func BillingMode(v string) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldBillingMode, v))
+}
+
+// CreditLimit applies equality check predicate on the "credit_limit" field. It's identical to CreditLimitEQ.
+func CreditLimit(v float64) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldCreditLimit, v))
+}
+
+// BillingCycleDay applies equality check predicate on the "billing_cycle_day" field. It's identical to BillingCycleDayEQ.
+func BillingCycleDay(v int) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldBillingCycleDay, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.User {
 	return predicate.User(sql.FieldEQ(FieldCreatedAt, v))
@@ -950,6 +965,151 @@ func SoraStorageUsedBytesLTE(v int64) predicate.User {
 	return predicate.User(sql.FieldLTE(FieldSoraStorageUsedBytes, v))
 }
 
+// BillingModeEQ applies the EQ predicate on the "billing_mode" field.
+func BillingModeEQ(v string) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldBillingMode, v))
+}
+
+// BillingModeNEQ applies the NEQ predicate on the "billing_mode" field.
+func BillingModeNEQ(v string) predicate.User {
+	return predicate.User(sql.FieldNEQ(FieldBillingMode, v))
+}
+
+// BillingModeIn applies the In predicate on the "billing_mode" field.
+func BillingModeIn(vs ...string) predicate.User {
+	return predicate.User(sql.FieldIn(FieldBillingMode, vs...))
+}
+
+// BillingModeNotIn applies the NotIn predicate on the "billing_mode" field.
+func BillingModeNotIn(vs ...string) predicate.User {
+	return predicate.User(sql.FieldNotIn(FieldBillingMode, vs...))
+}
+
+// BillingModeGT applies the GT predicate on the "billing_mode" field.
+func BillingModeGT(v string) predicate.User {
+	return predicate.User(sql.FieldGT(FieldBillingMode, v))
+}
+
+// BillingModeGTE applies the GTE predicate on the "billing_mode" field.
+func BillingModeGTE(v string) predicate.User {
+	return predicate.User(sql.FieldGTE(FieldBillingMode, v))
+}
+
+// BillingModeLT applies the LT predicate on the "billing_mode" field.
+func BillingModeLT(v string) predicate.User {
+	return predicate.User(sql.FieldLT(FieldBillingMode, v))
+}
+
+// BillingModeLTE applies the LTE predicate on the "billing_mode" field.
+func BillingModeLTE(v string) predicate.User {
+	return predicate.User(sql.FieldLTE(FieldBillingMode, v))
+}
+
+// BillingModeContains applies the Contains predicate on the "billing_mode" field.
+func BillingModeContains(v string) predicate.User {
+	return predicate.User(sql.FieldContains(FieldBillingMode, v))
+}
+
+// BillingModeHasPrefix applies the HasPrefix predicate on the "billing_mode" field.
+func BillingModeHasPrefix(v string) predicate.User {
+	return predicate.User(sql.FieldHasPrefix(FieldBillingMode, v))
+}
+
+// BillingModeHasSuffix applies the HasSuffix predicate on the "billing_mode" field.
+func BillingModeHasSuffix(v string) predicate.User {
+	return predicate.User(sql.FieldHasSuffix(FieldBillingMode, v))
+}
+
+// BillingModeEqualFold applies the EqualFold predicate on the "billing_mode" field.
+func BillingModeEqualFold(v string) predicate.User {
+	return predicate.User(sql.FieldEqualFold(FieldBillingMode, v))
+}
+
+// BillingModeContainsFold applies the ContainsFold predicate on the "billing_mode" field.
+func BillingModeContainsFold(v string) predicate.User {
+	return predicate.User(sql.FieldContainsFold(FieldBillingMode, v))
+}
+
+// CreditLimitEQ applies the EQ predicate on the "credit_limit" field.
+func CreditLimitEQ(v float64) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldCreditLimit, v))
+}
+
+// CreditLimitNEQ applies the NEQ predicate on the "credit_limit" field.
+func CreditLimitNEQ(v float64) predicate.User {
+	return predicate.User(sql.FieldNEQ(FieldCreditLimit, v))
+}
+
+// CreditLimitIn applies the In predicate on the "credit_limit" field.
+func CreditLimitIn(vs ...float64) predicate.User {
+	return predicate.User(sql.FieldIn(FieldCreditLimit, vs...))
+}
+
+// CreditLimitNotIn applies the NotIn predicate on the "credit_limit" field.
+func CreditLimitNotIn(vs ...float64) predicate.User {
+	return predicate.User(sql.FieldNotIn(FieldCreditLimit, vs...))
+}
+
+// CreditLimitGT applies the GT predicate on the "credit_limit" field.
+func CreditLimitGT(v float64) predicate.User {
+	return predicate.User(sql.FieldGT(FieldCreditLimit, v))
+}
+
+// CreditLimitGTE applies the GTE predicate on the "credit_limit" field.
+func CreditLimitGTE(v float64) predicate.User {
+	return predicate.User(sql.FieldGTE(FieldCreditLimit, v))
+}
+
+// CreditLimitLT applies the LT predicate on the "credit_limit" field.
+func CreditLimitLT(v float64) predicate.User {
+	return predicate.User(sql.FieldLT(FieldCreditLimit, v))
+}
+
+// CreditLimitLTE applies the LTE predicate on the "credit_limit" field.
+func CreditLimitLTE(v float64) predicate.User {
+	return predicate.User(sql.FieldLTE(FieldCreditLimit, v))
+}
+
+// BillingCycleDayEQ applies the EQ predicate on the "billing_cycle_day" field.
+func BillingCycleDayEQ(v int) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldBillingCycleDay, v))
+}
+
+// BillingCycleDayNEQ applies the NEQ predicate on the "billing_cycle_day" field.
+func BillingCycleDayNEQ(v int) predicate.User {
+	return predicate.User(sql.FieldNEQ(FieldBillingCycleDay, v))
+}
+
+// BillingCycleDayIn applies the In predicate on the "billing_cycle_day" field.
+func BillingCycleDayIn(vs ...int) predicate.User {
+	return predicate.User(sql.FieldIn(FieldBillingCycleDay, vs...))
+}
+
+// BillingCycleDayNotIn applies the NotIn predicate on the "billing_cycle_day" field.
+func BillingCycleDayNotIn(vs ...int) predicate.User {
+	return predicate.User(sql.FieldNotIn(FieldBillingCycleDay, vs...))
+}
+
+// BillingCycleDayGT applies the GT predicate on the "billing_cycle_day" field.
+func BillingCycleDayGT(v int) predicate.User {
+	return predicate.User(sql.FieldGT(FieldBillingCycleDay, v))
+}
+
+// BillingCycleDayGTE applies the GTE predicate on the "billing_cycle_day" field.
+func BillingCycleDayGTE(v int) predicate.User {
+	return predicate.User(sql.FieldGTE(FieldBillingCycleDay, v))
+}
+
+// BillingCycleDayLT applies the LT predicate on the "billing_cycle_day" field.
+func BillingCycleDayLT(v int) predicate.User {
+	return predicate.User(sql.FieldLT(FieldBillingCycleDay, v))
+}
+
+// BillingCycleDayLTE applies the LTE predicate on the "billing_cycle_day" field.
+func BillingCycleDayLTE(v int) predicate.User {
+	return predicate.User(sql.FieldLTE(FieldBillingCycleDay, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.User {
 	return predicate.User(func(s *sql.Selector) {
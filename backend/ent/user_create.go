@@ -238,6 +238,48 @@ func (_c *UserCreate) SetNillableSoraStorageUsedBytes(v *int64) *UserCreate {
 	return _c
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (_c *UserCreate) SetBillingMode(v string) *UserCreate {
+	_c.mutation.SetBillingMode(v)
+	return _c
+}
+
+// SetNillableBillingMode sets the "billing_mode" field if the given value is not nil.
+func (_c *UserCreate) SetNillableBillingMode(v *string) *UserCreate {
+	if v != nil {
+		_c.SetBillingMode(*v)
+	}
+	return _c
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (_c *UserCreate) SetCreditLimit(v float64) *UserCreate {
+	_c.mutation.SetCreditLimit(v)
+	return _c
+}
+
+// SetNillableCreditLimit sets the "credit_limit" field if the given value is not nil.
+func (_c *UserCreate) SetNillableCreditLimit(v *float64) *UserCreate {
+	if v != nil {
+		_c.SetCreditLimit(*v)
+	}
+	return _c
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (_c *UserCreate) SetBillingCycleDay(v int) *UserCreate {
+	_c.mutation.SetBillingCycleDay(v)
+	return _c
+}
+
+// SetNillableBillingCycleDay sets the "billing_cycle_day" field if the given value is not nil.
+func (_c *UserCreate) SetNillableBillingCycleDay(v *int) *UserCreate {
+	if v != nil {
+		_c.SetBillingCycleDay(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *UserCreate) AddAPIKeyIDs(ids ...int64) *UserCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -460,6 +502,18 @@ func (_c *UserCreate) defaults() error {
 		v := user.DefaultSoraStorageUsedBytes
 		_c.mutation.SetSoraStorageUsedBytes(v)
 	}
+	if _, ok := _c.mutation.BillingMode(); !ok {
+		v := user.DefaultBillingMode
+		_c.mutation.SetBillingMode(v)
+	}
+	if _, ok := _c.mutation.CreditLimit(); !ok {
+		v := user.DefaultCreditLimit
+		_c.mutation.SetCreditLimit(v)
+	}
+	if _, ok := _c.mutation.BillingCycleDay(); !ok {
+		v := user.DefaultBillingCycleDay
+		_c.mutation.SetBillingCycleDay(v)
+	}
 	return nil
 }
 
@@ -529,6 +583,20 @@ func (_c *UserCreate) check() error {
 	if _, ok := _c.mutation.SoraStorageUsedBytes(); !ok {
 		return &ValidationError{Name: "sora_storage_used_bytes", err: errors.New(`ent: missing required field "User.sora_storage_used_bytes"`)}
 	}
+	if _, ok := _c.mutation.BillingMode(); !ok {
+		return &ValidationError{Name: "billing_mode", err: errors.New(`ent: missing required field "User.billing_mode"`)}
+	}
+	if v, ok := _c.mutation.BillingMode(); ok {
+		if err := user.BillingModeValidator(v); err != nil {
+			return &ValidationError{Name: "billing_mode", err: fmt.Errorf(`ent: validator failed for field "User.billing_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.CreditLimit(); !ok {
+		return &ValidationError{Name: "credit_limit", err: errors.New(`ent: missing required field "User.credit_limit"`)}
+	}
+	if _, ok := _c.mutation.BillingCycleDay(); !ok {
+		return &ValidationError{Name: "billing_cycle_day", err: errors.New(`ent: missing required field "User.billing_cycle_day"`)}
+	}
 	return nil
 }
 
@@ -620,6 +688,18 @@ func (_c *UserCreate) createSpec() (*User, *sqlgraph.CreateSpec) {
 		_spec.SetField(user.FieldSoraStorageUsedBytes, field.TypeInt64, value)
 		_node.SoraStorageUsedBytes = value
 	}
+	if value, ok := _c.mutation.BillingMode(); ok {
+		_spec.SetField(user.FieldBillingMode, field.TypeString, value)
+		_node.BillingMode = value
+	}
+	if value, ok := _c.mutation.CreditLimit(); ok {
+		_spec.SetField(user.FieldCreditLimit, field.TypeFloat64, value)
+		_node.CreditLimit = value
+	}
+	if value, ok := _c.mutation.BillingCycleDay(); ok {
+		_spec.SetField(user.FieldBillingCycleDay, field.TypeInt, value)
+		_node.BillingCycleDay = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1042,6 +1122,54 @@ func (u *UserUpsert) AddSoraStorageUsedBytes(v int64) *UserUpsert {
 	return u
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (u *UserUpsert) SetBillingMode(v string) *UserUpsert {
+	u.Set(user.FieldBillingMode, v)
+	return u
+}
+
+// UpdateBillingMode sets the "billing_mode" field to the value that was provided on create.
+func (u *UserUpsert) UpdateBillingMode() *UserUpsert {
+	u.SetExcluded(user.FieldBillingMode)
+	return u
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (u *UserUpsert) SetCreditLimit(v float64) *UserUpsert {
+	u.Set(user.FieldCreditLimit, v)
+	return u
+}
+
+// UpdateCreditLimit sets the "credit_limit" field to the value that was provided on create.
+func (u *UserUpsert) UpdateCreditLimit() *UserUpsert {
+	u.SetExcluded(user.FieldCreditLimit)
+	return u
+}
+
+// AddCreditLimit adds v to the "credit_limit" field.
+func (u *UserUpsert) AddCreditLimit(v float64) *UserUpsert {
+	u.Add(user.FieldCreditLimit, v)
+	return u
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (u *UserUpsert) SetBillingCycleDay(v int) *UserUpsert {
+	u.Set(user.FieldBillingCycleDay, v)
+	return u
+}
+
+// UpdateBillingCycleDay sets the "billing_cycle_day" field to the value that was provided on create.
+func (u *UserUpsert) UpdateBillingCycleDay() *UserUpsert {
+	u.SetExcluded(user.FieldBillingCycleDay)
+	return u
+}
+
+// AddBillingCycleDay adds v to the "billing_cycle_day" field.
+func (u *UserUpsert) AddBillingCycleDay(v int) *UserUpsert {
+	u.Add(user.FieldBillingCycleDay, v)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1346,6 +1474,62 @@ func (u *UserUpsertOne) UpdateSoraStorageUsedBytes() *UserUpsertOne {
 	})
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (u *UserUpsertOne) SetBillingMode(v string) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.SetBillingMode(v)
+	})
+}
+
+// UpdateBillingMode sets the "billing_mode" field to the value that was provided on create.
+func (u *UserUpsertOne) UpdateBillingMode() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateBillingMode()
+	})
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (u *UserUpsertOne) SetCreditLimit(v float64) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.SetCreditLimit(v)
+	})
+}
+
+// AddCreditLimit adds v to the "credit_limit" field.
+func (u *UserUpsertOne) AddCreditLimit(v float64) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.AddCreditLimit(v)
+	})
+}
+
+// UpdateCreditLimit sets the "credit_limit" field to the value that was provided on create.
+func (u *UserUpsertOne) UpdateCreditLimit() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateCreditLimit()
+	})
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (u *UserUpsertOne) SetBillingCycleDay(v int) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.SetBillingCycleDay(v)
+	})
+}
+
+// AddBillingCycleDay adds v to the "billing_cycle_day" field.
+func (u *UserUpsertOne) AddBillingCycleDay(v int) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.AddBillingCycleDay(v)
+	})
+}
+
+// UpdateBillingCycleDay sets the "billing_cycle_day" field to the value that was provided on create.
+func (u *UserUpsertOne) UpdateBillingCycleDay() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateBillingCycleDay()
+	})
+}
+
 // Exec executes the query.
 func (u *UserUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1816,6 +2000,62 @@ func (u *UserUpsertBulk) UpdateSoraStorageUsedBytes() *UserUpsertBulk {
 	})
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (u *UserUpsertBulk) SetBillingMode(v string) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.SetBillingMode(v)
+	})
+}
+
+// UpdateBillingMode sets the "billing_mode" field to the value that was provided on create.
+func (u *UserUpsertBulk) UpdateBillingMode() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateBillingMode()
+	})
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (u *UserUpsertBulk) SetCreditLimit(v float64) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.SetCreditLimit(v)
+	})
+}
+
+// AddCreditLimit adds v to the "credit_limit" field.
+func (u *UserUpsertBulk) AddCreditLimit(v float64) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.AddCreditLimit(v)
+	})
+}
+
+// UpdateCreditLimit sets the "credit_limit" field to the value that was provided on create.
+func (u *UserUpsertBulk) UpdateCreditLimit() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateCreditLimit()
+	})
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (u *UserUpsertBulk) SetBillingCycleDay(v int) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.SetBillingCycleDay(v)
+	})
+}
+
+// AddBillingCycleDay adds v to the "billing_cycle_day" field.
+func (u *UserUpsertBulk) AddBillingCycleDay(v int) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.AddBillingCycleDay(v)
+	})
+}
+
+// UpdateBillingCycleDay sets the "billing_cycle_day" field to the value that was provided on create.
+func (u *UserUpsertBulk) UpdateBillingCycleDay() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateBillingCycleDay()
+	})
+}
+
 // Exec executes the query.
 func (u *UserUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -284,6 +284,62 @@ func (_u *UserUpdate) AddSoraStorageUsedBytes(v int64) *UserUpdate {
 	return _u
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (_u *UserUpdate) SetBillingMode(v string) *UserUpdate {
+	_u.mutation.SetBillingMode(v)
+	return _u
+}
+
+// SetNillableBillingMode sets the "billing_mode" field if the given value is not nil.
+func (_u *UserUpdate) SetNillableBillingMode(v *string) *UserUpdate {
+	if v != nil {
+		_u.SetBillingMode(*v)
+	}
+	return _u
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (_u *UserUpdate) SetCreditLimit(v float64) *UserUpdate {
+	_u.mutation.ResetCreditLimit()
+	_u.mutation.SetCreditLimit(v)
+	return _u
+}
+
+// SetNillableCreditLimit sets the "credit_limit" field if the given value is not nil.
+func (_u *UserUpdate) SetNillableCreditLimit(v *float64) *UserUpdate {
+	if v != nil {
+		_u.SetCreditLimit(*v)
+	}
+	return _u
+}
+
+// AddCreditLimit adds value to the "credit_limit" field.
+func (_u *UserUpdate) AddCreditLimit(v float64) *UserUpdate {
+	_u.mutation.AddCreditLimit(v)
+	return _u
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (_u *UserUpdate) SetBillingCycleDay(v int) *UserUpdate {
+	_u.mutation.ResetBillingCycleDay()
+	_u.mutation.SetBillingCycleDay(v)
+	return _u
+}
+
+// SetNillableBillingCycleDay sets the "billing_cycle_day" field if the given value is not nil.
+func (_u *UserUpdate) SetNillableBillingCycleDay(v *int) *UserUpdate {
+	if v != nil {
+		_u.SetBillingCycleDay(*v)
+	}
+	return _u
+}
+
+// AddBillingCycleDay adds value to the "billing_cycle_day" field.
+func (_u *UserUpdate) AddBillingCycleDay(v int) *UserUpdate {
+	_u.mutation.AddBillingCycleDay(v)
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *UserUpdate) AddAPIKeyIDs(ids ...int64) *UserUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -682,6 +738,11 @@ func (_u *UserUpdate) check() error {
 			return &ValidationError{Name: "username", err: fmt.Errorf(`ent: validator failed for field "User.username": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.BillingMode(); ok {
+		if err := user.BillingModeValidator(v); err != nil {
+			return &ValidationError{Name: "billing_mode", err: fmt.Errorf(`ent: validator failed for field "User.billing_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -763,6 +824,21 @@ func (_u *UserUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedSoraStorageUsedBytes(); ok {
 		_spec.AddField(user.FieldSoraStorageUsedBytes, field.TypeInt64, value)
 	}
+	if value, ok := _u.mutation.BillingMode(); ok {
+		_spec.SetField(user.FieldBillingMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.CreditLimit(); ok {
+		_spec.SetField(user.FieldCreditLimit, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedCreditLimit(); ok {
+		_spec.AddField(user.FieldCreditLimit, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BillingCycleDay(); ok {
+		_spec.SetField(user.FieldBillingCycleDay, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedBillingCycleDay(); ok {
+		_spec.AddField(user.FieldBillingCycleDay, field.TypeInt, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1448,6 +1524,62 @@ func (_u *UserUpdateOne) AddSoraStorageUsedBytes(v int64) *UserUpdateOne {
 	return _u
 }
 
+// SetBillingMode sets the "billing_mode" field.
+func (_u *UserUpdateOne) SetBillingMode(v string) *UserUpdateOne {
+	_u.mutation.SetBillingMode(v)
+	return _u
+}
+
+// SetNillableBillingMode sets the "billing_mode" field if the given value is not nil.
+func (_u *UserUpdateOne) SetNillableBillingMode(v *string) *UserUpdateOne {
+	if v != nil {
+		_u.SetBillingMode(*v)
+	}
+	return _u
+}
+
+// SetCreditLimit sets the "credit_limit" field.
+func (_u *UserUpdateOne) SetCreditLimit(v float64) *UserUpdateOne {
+	_u.mutation.ResetCreditLimit()
+	_u.mutation.SetCreditLimit(v)
+	return _u
+}
+
+// SetNillableCreditLimit sets the "credit_limit" field if the given value is not nil.
+func (_u *UserUpdateOne) SetNillableCreditLimit(v *float64) *UserUpdateOne {
+	if v != nil {
+		_u.SetCreditLimit(*v)
+	}
+	return _u
+}
+
+// AddCreditLimit adds value to the "credit_limit" field.
+func (_u *UserUpdateOne) AddCreditLimit(v float64) *UserUpdateOne {
+	_u.mutation.AddCreditLimit(v)
+	return _u
+}
+
+// SetBillingCycleDay sets the "billing_cycle_day" field.
+func (_u *UserUpdateOne) SetBillingCycleDay(v int) *UserUpdateOne {
+	_u.mutation.ResetBillingCycleDay()
+	_u.mutation.SetBillingCycleDay(v)
+	return _u
+}
+
+// SetNillableBillingCycleDay sets the "billing_cycle_day" field if the given value is not nil.
+func (_u *UserUpdateOne) SetNillableBillingCycleDay(v *int) *UserUpdateOne {
+	if v != nil {
+		_u.SetBillingCycleDay(*v)
+	}
+	return _u
+}
+
+// AddBillingCycleDay adds value to the "billing_cycle_day" field.
+func (_u *UserUpdateOne) AddBillingCycleDay(v int) *UserUpdateOne {
+	_u.mutation.AddBillingCycleDay(v)
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *UserUpdateOne) AddAPIKeyIDs(ids ...int64) *UserUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -1859,6 +1991,11 @@ func (_u *UserUpdateOne) check() error {
 			return &ValidationError{Name: "username", err: fmt.Errorf(`ent: validator failed for field "User.username": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.BillingMode(); ok {
+		if err := user.BillingModeValidator(v); err != nil {
+			return &ValidationError{Name: "billing_mode", err: fmt.Errorf(`ent: validator failed for field "User.billing_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1957,6 +2094,21 @@ func (_u *UserUpdateOne) sqlSave(ctx context.Context) (_node *User, err error) {
 	if value, ok := _u.mutation.AddedSoraStorageUsedBytes(); ok {
 		_spec.AddField(user.FieldSoraStorageUsedBytes, field.TypeInt64, value)
 	}
+	if value, ok := _u.mutation.BillingMode(); ok {
+		_spec.SetField(user.FieldBillingMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.CreditLimit(); ok {
+		_spec.SetField(user.FieldCreditLimit, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedCreditLimit(); ok {
+		_spec.AddField(user.FieldCreditLimit, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BillingCycleDay(); ok {
+		_spec.SetField(user.FieldBillingCycleDay, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedBillingCycleDay(); ok {
+		_spec.AddField(user.FieldBillingCycleDay, field.TypeInt, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -77,6 +77,10 @@ type Config struct {
 	Dashboard               DashboardCacheConfig          `mapstructure:"dashboard_cache"`
 	DashboardAgg            DashboardAggregationConfig    `mapstructure:"dashboard_aggregation"`
 	UsageCleanup            UsageCleanupConfig            `mapstructure:"usage_cleanup"`
+	Batch                   BatchConfig                   `mapstructure:"batch"`
+	Payment                 PaymentConfig                 `mapstructure:"payment"`
+	BalanceLedger           BalanceLedgerConfig           `mapstructure:"balance_ledger"`
+	Postpaid                PostpaidConfig                `mapstructure:"postpaid"`
 	Concurrency             ConcurrencyConfig             `mapstructure:"concurrency"`
 	TokenRefresh            TokenRefreshConfig            `mapstructure:"token_refresh"`
 	Sora                    SoraConfig                    `mapstructure:"sora"`
@@ -85,6 +89,7 @@ type Config struct {
 	Gemini                  GeminiConfig                  `mapstructure:"gemini"`
 	Update                  UpdateConfig                  `mapstructure:"update"`
 	Idempotency             IdempotencyConfig             `mapstructure:"idempotency"`
+	Tracing                 TracingConfig                 `mapstructure:"tracing"`
 }
 
 type LogConfig struct {
@@ -97,6 +102,7 @@ type LogConfig struct {
 	Output          LogOutputConfig   `mapstructure:"output"`
 	Rotation        LogRotationConfig `mapstructure:"rotation"`
 	Sampling        LogSamplingConfig `mapstructure:"sampling"`
+	Shipping        LogShippingConfig `mapstructure:"shipping"`
 }
 
 type LogOutputConfig struct {
@@ -119,6 +125,53 @@ type LogSamplingConfig struct {
 	Thereafter int  `mapstructure:"thereafter"`
 }
 
+// LogShippingConfig 结构化日志外部投递（Loki / Elasticsearch / HTTP / syslog）
+type LogShippingConfig struct {
+	Sinks []LogShippingSinkConfig `mapstructure:"sinks"`
+}
+
+const (
+	LogShippingSinkLoki          = "loki"
+	LogShippingSinkElasticsearch = "elasticsearch"
+	LogShippingSinkHTTP          = "http"
+	LogShippingSinkSyslog        = "syslog"
+)
+
+type LogShippingSinkConfig struct {
+	// Name 唯一名称，用于健康状态展示；为空时使用 type
+	Name    string `mapstructure:"name"`
+	Type    string `mapstructure:"type"`
+	Enabled bool   `mapstructure:"enabled"`
+
+	// URL loki: push 接口地址；elasticsearch: 集群地址（自动追加 /_bulk）；http: 接收地址
+	URL         string            `mapstructure:"url"`
+	Username    string            `mapstructure:"username"`
+	Password    string            `mapstructure:"password"`
+	BearerToken string            `mapstructure:"bearer_token"`
+	Headers     map[string]string `mapstructure:"headers"`
+	// Labels loki 静态标签
+	Labels map[string]string `mapstructure:"labels"`
+	// Index elasticsearch 索引名，支持 {date} 占位符（按事件 UTC 日期替换为 2006.01.02）
+	Index string `mapstructure:"index"`
+
+	// Network/Address syslog 传输（tcp/udp）与地址 host:port
+	Network  string `mapstructure:"network"`
+	Address  string `mapstructure:"address"`
+	Facility int    `mapstructure:"facility"`
+	AppName  string `mapstructure:"app_name"`
+
+	// MinLevel 最低投递级别：debug/info/warn/error
+	MinLevel string `mapstructure:"min_level"`
+	// Modules 仅投递组件名以这些前缀开头的日志（为空表示全部）
+	Modules        []string `mapstructure:"modules"`
+	ExcludeModules []string `mapstructure:"exclude_modules"`
+
+	QueueSize       int `mapstructure:"queue_size"`
+	BatchSize       int `mapstructure:"batch_size"`
+	FlushIntervalMs int `mapstructure:"flush_interval_ms"`
+	TimeoutSeconds  int `mapstructure:"timeout_seconds"`
+}
+
 type GeminiConfig struct {
 	OAuth GeminiOAuthConfig `mapstructure:"oauth"`
 	Quota GeminiQuotaConfig `mapstructure:"quota"`
@@ -167,6 +220,25 @@ type IdempotencyConfig struct {
 	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
 }
 
+// TracingConfig OpenTelemetry 分布式追踪配置
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ServiceName 上报的 service.name，为空时使用 log.service_name
+	ServiceName string `mapstructure:"service_name"`
+	// Exporter 导出方式：otlp_http / stdout
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint OTLP/HTTP 地址，host:port 或完整 URL（如 http://otel-collector:4318/v1/traces）
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure 使用明文 HTTP 连接 collector（Endpoint 为完整 URL 时以其 scheme 为准）
+	Insecure bool `mapstructure:"insecure"`
+	// Headers 导出请求附加的 HTTP 头（如鉴权）
+	Headers map[string]string `mapstructure:"headers"`
+	// SampleRatio 根 span 采样率（0-1）；携带 traceparent 的请求沿用上游采样决定
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	// ExportTimeoutSeconds 单次导出超时（秒）
+	ExportTimeoutSeconds int `mapstructure:"export_timeout_seconds"`
+}
+
 type LinuxDoConnectConfig struct {
 	Enabled             bool   `mapstructure:"enabled"`
 	ClientID            string `mapstructure:"client_id"`
@@ -290,7 +362,18 @@ type ProxyProbeConfig struct {
 }
 
 type BillingConfig struct {
-	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	CircuitBreaker CircuitBreakerConfig     `mapstructure:"circuit_breaker"`
+	Reservation    BillingReservationConfig `mapstructure:"reservation"`
+}
+
+// BillingReservationConfig 严格预留模式（分组 billing_reservation_mode=strict）配置
+type BillingReservationConfig struct {
+	// HoldTTLSeconds: 预留额度的最长持有时间（秒），超时未结算的预留自动释放
+	HoldTTLSeconds int `mapstructure:"hold_ttl_seconds"`
+	// DefaultMaxOutputTokens: 请求未声明 max_tokens 时用于估算最大费用的输出 token 数
+	DefaultMaxOutputTokens int `mapstructure:"default_max_output_tokens"`
+	// AudioMinBitrateKbps: 估算转写/翻译音频时长上限时假定的最低码率（kbps），时长上限 = 文件大小 / 码率
+	AudioMinBitrateKbps int `mapstructure:"audio_min_bitrate_kbps"`
 }
 
 type CircuitBreakerConfig struct {
@@ -426,6 +509,12 @@ type GatewayConfig struct {
 	// 是否允许对部分 400 错误触发 failover（默认关闭以避免改变语义）
 	FailoverOn400 bool `mapstructure:"failover_on_400"`
 
+	// AudioMaxBodySize: /v1/audio/* 请求体最大字节数（0 表示使用 gateway.max_body_size）
+	AudioMaxBodySize int64 `mapstructure:"audio_max_body_size"`
+
+	// ResponseCacheMaxEntryBytes: 精确匹配响应缓存单条响应体上限（超过则不缓存）
+	ResponseCacheMaxEntryBytes int `mapstructure:"response_cache_max_entry_bytes"`
+
 	// Sora 专用配置
 	// SoraMaxBodySize: Sora 请求体最大字节数（0 表示使用 gateway.max_body_size）
 	SoraMaxBodySize int64 `mapstructure:"sora_max_body_size"`
@@ -474,6 +563,46 @@ type GatewayConfig struct {
 	// 当客户端在短时间内累积过多 4xx 错误（401/403/429）时，
 	// 直接返回 429，避免无效请求反复消耗服务器资源。
 	ErrorThrottle GatewayErrorThrottleConfig `mapstructure:"error_throttle"`
+
+	// Hedging: 对冲请求配置（Anthropic/Gemini 网关）
+	// 主请求在延迟阈值内未产生首字节时，选择另一账号并发发起第二次请求，先成功者胜出。
+	// 是否对某个分组生效由分组的 hedge_budget_percent 决定。
+	Hedging GatewayHedgingConfig `mapstructure:"hedging"`
+
+	// DigestSession: Gemini/Anthropic 摘要链会话粘性存储配置
+	DigestSession GatewayDigestSessionConfig `mapstructure:"digest_session"`
+}
+
+const (
+	DigestSessionBackendMemory = "memory"
+	DigestSessionBackendRedis  = "redis"
+)
+
+// GatewayDigestSessionConfig 摘要链会话存储配置
+type GatewayDigestSessionConfig struct {
+	// Backend: 存储后端（memory/redis，默认 memory）
+	// memory 仅在进程内生效；多实例部署时使用 redis 让各实例共享会话粘性，
+	// Redis 不可用时自动回退到本地内存存储。
+	Backend string `mapstructure:"backend"`
+}
+
+// GatewayHedgingConfig 对冲请求配置
+type GatewayHedgingConfig struct {
+	// Enabled: 全局总开关（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// DelayPercentile: 对冲延迟取近期首字节延迟的分位数（0-100，默认 95）
+	DelayPercentile float64 `mapstructure:"delay_percentile"`
+	// MinDelayMs / MaxDelayMs: 对冲延迟上下限（毫秒）
+	MinDelayMs int `mapstructure:"min_delay_ms"`
+	MaxDelayMs int `mapstructure:"max_delay_ms"`
+	// InitialDelayMs: 样本不足时使用的对冲延迟（毫秒）
+	InitialDelayMs int `mapstructure:"initial_delay_ms"`
+	// MinSamples: 使用分位数延迟所需的最少样本数
+	MinSamples int `mapstructure:"min_samples"`
+	// SampleSize: 每个分组+模型保留的最近延迟样本数
+	SampleSize int `mapstructure:"sample_size"`
+	// BudgetWindowSeconds: 对冲预算统计窗口（秒）
+	BudgetWindowSeconds int `mapstructure:"budget_window_seconds"`
 }
 
 // GatewayErrorThrottleConfig 网关错误惩罚限流配置
@@ -745,8 +874,18 @@ type GatewaySchedulingConfig struct {
 	// 全量重建周期配置
 	// 全量重建周期（秒），0 表示禁用
 	FullRebuildIntervalSeconds int `mapstructure:"full_rebuild_interval_seconds"`
+
+	// 账号熔断/健康度存储模式: "local"(进程内，默认) 或 "redis"(多实例共享熔断状态与健康度计数)
+	AccountHealthMode string `mapstructure:"account_health_mode"`
+	// redis 模式下熔断状态全量同步周期（秒），用于兜底丢失的 pub/sub 消息
+	AccountHealthSyncIntervalSeconds int `mapstructure:"account_health_sync_interval_seconds"`
 }
 
+const (
+	AccountHealthModeLocal = "local"
+	AccountHealthModeRedis = "redis"
+)
+
 func (s *ServerConfig) Address() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
 }
@@ -847,6 +986,59 @@ type OpsConfig struct {
 
 	// Pre-aggregation configuration.
 	Aggregation OpsAggregationConfig `mapstructure:"aggregation"`
+
+	// Prometheus exposes a token-protected /metrics endpoint in Prometheus text format.
+	Prometheus OpsPrometheusConfig `mapstructure:"prometheus"`
+
+	// PayloadCapture stores sampled request/response payloads for debugging.
+	// Capture rules are managed at runtime via the admin API; this section only controls storage.
+	PayloadCapture OpsPayloadCaptureConfig `mapstructure:"payload_capture"`
+
+	// CustomerSLA controls the user-facing monthly SLA reports.
+	CustomerSLA OpsCustomerSLAConfig `mapstructure:"customer_sla"`
+
+	// StatusPage exposes an unauthenticated service status endpoint (GET /api/v1/status).
+	StatusPage OpsStatusPageConfig `mapstructure:"status_page"`
+}
+
+type OpsCustomerSLAConfig struct {
+	// TargetAvailability is the monthly availability objective in percent (e.g. 99.9).
+	// The error budget is derived from it.
+	TargetAvailability float64 `mapstructure:"target_availability"`
+}
+
+type OpsStatusPageConfig struct {
+	// Enabled exposes the public status endpoint; it returns 404 when disabled.
+	Enabled bool `mapstructure:"enabled"`
+	// ShowModels lists per-model components (derived from recent traffic) under each platform.
+	ShowModels bool `mapstructure:"show_models"`
+	// IncidentDays controls how many days of incident history are shown.
+	IncidentDays int `mapstructure:"incident_days"`
+	// IncidentSeverities limits which alert severities are published as incidents.
+	IncidentSeverities []string `mapstructure:"incident_severities"`
+	// CacheTTLSeconds caches the computed status to shield the database from anonymous traffic.
+	CacheTTLSeconds int `mapstructure:"cache_ttl_seconds"`
+}
+
+type OpsPayloadCaptureConfig struct {
+	// Storage backend for captured payloads: local / s3.
+	Storage string `mapstructure:"storage"`
+	// LocalPath is the local storage root (defaults to DATA_DIR/payload_captures).
+	LocalPath string `mapstructure:"local_path"`
+	// S3 settings (used when storage=s3).
+	S3 BatchS3Config `mapstructure:"s3"`
+	// RetentionHours controls how long captures are kept before being deleted.
+	RetentionHours int `mapstructure:"retention_hours"`
+	// MaxBodyBytes caps each captured body (inbound / upstream / response); larger bodies are truncated.
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// QueueSize bounds pending uploads; new captures are dropped when the queue is full.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+type OpsPrometheusConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Token is required as "Authorization: Bearer <token>" when scraping /metrics.
+	Token string `mapstructure:"token"`
 }
 
 type OpsCleanupConfig struct {
@@ -989,6 +1181,162 @@ type UsageCleanupConfig struct {
 	TaskTimeoutSeconds int `mapstructure:"task_timeout_seconds"`
 }
 
+// BatchConfig OpenAI Batch API（/v1/files + /v1/batches）配置
+type BatchConfig struct {
+	// Enabled: 是否启用 Files/Batches 接口与后台执行器
+	Enabled bool `mapstructure:"enabled"`
+	// Storage: 文件存储类型（local / s3）
+	Storage string `mapstructure:"storage"`
+	// LocalPath: 本地存储目录（为空时使用 DATA_DIR/batches）
+	LocalPath string `mapstructure:"local_path"`
+	// S3: S3 兼容存储配置（storage=s3 时生效）
+	S3 BatchS3Config `mapstructure:"s3"`
+	// MaxFileSizeMB: 单个上传文件大小上限（MB）
+	MaxFileSizeMB int `mapstructure:"max_file_size_mb"`
+	// MaxRequestsPerBatch: 单个 batch 最多包含的请求行数
+	MaxRequestsPerBatch int `mapstructure:"max_requests_per_batch"`
+	// MaxConcurrentJobs: 单实例同时执行的 batch 数量
+	MaxConcurrentJobs int `mapstructure:"max_concurrent_jobs"`
+	// GroupConcurrency: 每个分组同时在途的 batch 请求数上限，避免挤占交互流量
+	GroupConcurrency int `mapstructure:"group_concurrency"`
+	// DiscountMultiplier: batch 请求计费折扣倍率（0.5 表示半价）
+	DiscountMultiplier float64 `mapstructure:"discount_multiplier"`
+	// MessageBatchDiscountMultiplier: Anthropic Message Batches 请求计费折扣倍率
+	MessageBatchDiscountMultiplier float64 `mapstructure:"message_batch_discount_multiplier"`
+	// WorkerIntervalSeconds: 后台执行器轮询间隔（秒）
+	WorkerIntervalSeconds int `mapstructure:"worker_interval_seconds"`
+	// RequestTimeoutSeconds: 单条请求最大执行时长（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// StaleJobSeconds: 执行中的 batch 超过该时长无进度更新视为中断（进程退出/崩溃）
+	StaleJobSeconds int `mapstructure:"stale_job_seconds"`
+}
+
+// BatchS3Config Batch 文件的 S3 兼容存储配置
+type BatchS3Config struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	Prefix          string `mapstructure:"prefix"`
+	ForcePathStyle  bool   `mapstructure:"force_path_style"`
+}
+
+// PaymentConfig 原生支付订单配置
+type PaymentConfig struct {
+	// Enabled: 是否启用自助下单（充值余额 / 购买订阅计划）
+	Enabled bool `mapstructure:"enabled"`
+	// Currency: 下单币种（如 CNY / USD），订阅计划售价须使用同一币种
+	Currency string `mapstructure:"currency"`
+	// BalanceRate: 每 1 单位支付金额到账的余额（USD），如 CNY 充值按 7.2:1 时填 0.1389
+	BalanceRate float64 `mapstructure:"balance_rate"`
+	// MinAmount / MaxAmount: 余额充值单笔金额范围（MaxAmount 为 0 表示不限制）
+	MinAmount float64 `mapstructure:"min_amount"`
+	MaxAmount float64 `mapstructure:"max_amount"`
+	// OrderExpireMinutes: 待支付订单有效期（分钟），过期后不再展示支付链接
+	OrderExpireMinutes int `mapstructure:"order_expire_minutes"`
+	// PublicBaseURL: 站点对外地址，用于拼接支付回调地址（如 https://api.example.com）
+	PublicBaseURL string `mapstructure:"public_base_url"`
+	// ReturnURL: 支付完成后浏览器跳转地址（为空时使用 PublicBaseURL）
+	ReturnURL string `mapstructure:"return_url"`
+
+	Stripe  PaymentStripeConfig  `mapstructure:"stripe"`
+	EPay    PaymentEPayConfig    `mapstructure:"epay"`
+	Webhook PaymentWebhookConfig `mapstructure:"webhook"`
+	Fake    PaymentFakeConfig    `mapstructure:"fake"`
+}
+
+// PaymentStripeConfig Stripe Checkout 配置
+type PaymentStripeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SecretKey: Stripe API 密钥（sk_live_... / sk_test_...）
+	SecretKey string `mapstructure:"secret_key"`
+	// WebhookSecret: Webhook 签名密钥（whsec_...）
+	WebhookSecret string `mapstructure:"webhook_secret"`
+	// APIBase: API 地址（测试时可指向 stripe-mock）
+	APIBase string `mapstructure:"api_base"`
+}
+
+// PaymentEPayConfig 易支付类聚合支付（支付宝 / 微信）配置
+type PaymentEPayConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Gateway: 聚合支付网关地址（如 https://pay.example.com），下单跳转 {gateway}/submit.php
+	Gateway string `mapstructure:"gateway"`
+	// PID: 商户 ID
+	PID string `mapstructure:"pid"`
+	// Key: 商户密钥（MD5 签名）
+	Key string `mapstructure:"key"`
+	// Channels: 允许的支付方式（alipay / wxpay / qqpay）
+	Channels []string `mapstructure:"channels"`
+}
+
+// PaymentWebhookConfig 通用 HMAC Webhook 支付渠道配置（自建收银台或第三方中转）
+type PaymentWebhookConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CheckoutURL: 收银台地址模板，支持 {order_no} {amount} {currency} {notify_url} {return_url} 占位符
+	CheckoutURL string `mapstructure:"checkout_url"`
+	// Secret: 回调签名密钥（HMAC-SHA256）
+	Secret string `mapstructure:"secret"`
+	// TimestampToleranceSeconds: 回调时间戳允许偏差（秒）
+	TimestampToleranceSeconds int `mapstructure:"timestamp_tolerance_seconds"`
+}
+
+// PaymentFakeConfig 本地联调用的模拟支付渠道，回调无签名，server.mode=release 时禁止开启
+type PaymentFakeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// BalanceLedgerConfig 余额流水账本配置
+type BalanceLedgerConfig struct {
+	// UsageFlushIntervalSeconds: 请求扣费汇总写入账本的周期（秒）
+	UsageFlushIntervalSeconds int `mapstructure:"usage_flush_interval_seconds"`
+	// UsageFlushBatchSize: 每轮最多汇总的用户数
+	UsageFlushBatchSize int `mapstructure:"usage_flush_batch_size"`
+	// ReconcileIntervalMinutes: 余额对账周期（分钟），0 表示关闭定时对账
+	ReconcileIntervalMinutes int `mapstructure:"reconcile_interval_minutes"`
+	// DriftTolerance: 允许的余额偏差（USD），超出视为不一致
+	DriftTolerance float64 `mapstructure:"drift_tolerance"`
+	// AlertChannelIDs: 发现偏差时通知的运维通知渠道 ID
+	AlertChannelIDs []int64 `mapstructure:"alert_channel_ids"`
+}
+
+// PostpaidConfig 后付费（月结）账单配置
+type PostpaidConfig struct {
+	// CheckIntervalSeconds: 出账与逾期检查周期（秒）
+	CheckIntervalSeconds int `mapstructure:"check_interval_seconds"`
+	// TaxRate: 账单税率（0.08 表示 8%），按小计计算税额
+	TaxRate float64 `mapstructure:"tax_rate"`
+	// DueDays: 账单出具后的付款期限（天），逾期后暂停该用户的 API Key
+	DueDays int `mapstructure:"due_days"`
+	// IssuerName / IssuerDetails: 账单抬头（名称、地址、税号等，多行文本）
+	IssuerName    string `mapstructure:"issuer_name"`
+	IssuerDetails string `mapstructure:"issuer_details"`
+}
+
+// ResolveBatchStorageRoot 返回 Batch 文件本地存储根目录。
+func ResolveBatchStorageRoot(localPath string) string {
+	return resolveStorageRoot(localPath, "batches")
+}
+
+// ResolvePayloadCaptureStorageRoot 返回请求载荷抓取文件本地存储根目录。
+func ResolvePayloadCaptureStorageRoot(localPath string) string {
+	return resolveStorageRoot(localPath, "payload_captures")
+}
+
+func resolveStorageRoot(localPath, defaultDir string) string {
+	root := strings.TrimSpace(localPath)
+	if root == "" {
+		root = filepath.Join(ResolveDataDir(), defaultDir)
+	}
+	root = filepath.Clean(root)
+	if !filepath.IsAbs(root) {
+		if absRoot, err := filepath.Abs(root); err == nil {
+			root = absRoot
+		}
+	}
+	return root
+}
+
 func NormalizeRunMode(value string) string {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	switch normalized {
@@ -1101,6 +1449,30 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.Log.Environment = strings.TrimSpace(cfg.Log.Environment)
 	cfg.Log.StacktraceLevel = strings.ToLower(strings.TrimSpace(cfg.Log.StacktraceLevel))
 	cfg.Log.Output.FilePath = strings.TrimSpace(cfg.Log.Output.FilePath)
+	for i := range cfg.Log.Shipping.Sinks {
+		sink := &cfg.Log.Shipping.Sinks[i]
+		sink.Type = strings.ToLower(strings.TrimSpace(sink.Type))
+		sink.Name = strings.TrimSpace(sink.Name)
+		if sink.Name == "" {
+			sink.Name = sink.Type
+		}
+		sink.URL = strings.TrimSpace(sink.URL)
+		sink.Network = strings.ToLower(strings.TrimSpace(sink.Network))
+		sink.Address = strings.TrimSpace(sink.Address)
+		sink.MinLevel = strings.ToLower(strings.TrimSpace(sink.MinLevel))
+	}
+	cfg.Payment.Currency = strings.ToUpper(strings.TrimSpace(cfg.Payment.Currency))
+	cfg.Payment.PublicBaseURL = strings.TrimRight(strings.TrimSpace(cfg.Payment.PublicBaseURL), "/")
+	cfg.Payment.ReturnURL = strings.TrimSpace(cfg.Payment.ReturnURL)
+	cfg.Payment.Stripe.SecretKey = strings.TrimSpace(cfg.Payment.Stripe.SecretKey)
+	cfg.Payment.Stripe.WebhookSecret = strings.TrimSpace(cfg.Payment.Stripe.WebhookSecret)
+	cfg.Payment.Stripe.APIBase = strings.TrimRight(strings.TrimSpace(cfg.Payment.Stripe.APIBase), "/")
+	cfg.Payment.EPay.Gateway = strings.TrimRight(strings.TrimSpace(cfg.Payment.EPay.Gateway), "/")
+	cfg.Payment.EPay.PID = strings.TrimSpace(cfg.Payment.EPay.PID)
+	cfg.Payment.EPay.Key = strings.TrimSpace(cfg.Payment.EPay.Key)
+	cfg.Payment.EPay.Channels = normalizeStringSlice(cfg.Payment.EPay.Channels)
+	cfg.Payment.Webhook.CheckoutURL = strings.TrimSpace(cfg.Payment.Webhook.CheckoutURL)
+	cfg.Payment.Webhook.Secret = strings.TrimSpace(cfg.Payment.Webhook.Secret)
 
 	// 兼容旧键 gateway.openai_ws.sticky_previous_response_ttl_seconds。
 	// 新键未配置（<=0）时回退旧键；新键优先。
@@ -1240,6 +1612,9 @@ func setDefaults() {
 	viper.SetDefault("billing.circuit_breaker.failure_threshold", 5)
 	viper.SetDefault("billing.circuit_breaker.reset_timeout_seconds", 30)
 	viper.SetDefault("billing.circuit_breaker.half_open_requests", 3)
+	viper.SetDefault("billing.reservation.hold_ttl_seconds", 900)
+	viper.SetDefault("billing.reservation.default_max_output_tokens", 8192)
+	viper.SetDefault("billing.reservation.audio_min_bitrate_kbps", 32)
 
 	// Turnstile
 	viper.SetDefault("turnstile.required", false)
@@ -1297,6 +1672,19 @@ func setDefaults() {
 	viper.SetDefault("ops.metrics_collector_cache.enabled", true)
 	// TTL should be slightly larger than collection interval (1m) to maximize cross-replica cache hits.
 	viper.SetDefault("ops.metrics_collector_cache.ttl", 65*time.Second)
+	viper.SetDefault("ops.prometheus.enabled", false)
+	viper.SetDefault("ops.prometheus.token", "")
+	viper.SetDefault("ops.payload_capture.storage", "local")
+	viper.SetDefault("ops.payload_capture.local_path", "")
+	viper.SetDefault("ops.payload_capture.retention_hours", 72)
+	viper.SetDefault("ops.payload_capture.max_body_bytes", 1<<20)
+	viper.SetDefault("ops.payload_capture.queue_size", 256)
+	viper.SetDefault("ops.customer_sla.target_availability", 99.9)
+	viper.SetDefault("ops.status_page.enabled", false)
+	viper.SetDefault("ops.status_page.show_models", false)
+	viper.SetDefault("ops.status_page.incident_days", 14)
+	viper.SetDefault("ops.status_page.incident_severities", []string{"P0", "P1"})
+	viper.SetDefault("ops.status_page.cache_ttl_seconds", 30)
 
 	// JWT
 	viper.SetDefault("jwt.secret", "")
@@ -1372,6 +1760,53 @@ func setDefaults() {
 	viper.SetDefault("usage_cleanup.worker_interval_seconds", 10)
 	viper.SetDefault("usage_cleanup.task_timeout_seconds", 1800)
 
+	// Batch API
+	viper.SetDefault("batch.enabled", true)
+	viper.SetDefault("batch.storage", "local")
+	viper.SetDefault("batch.local_path", "")
+	viper.SetDefault("batch.s3.region", "auto")
+	viper.SetDefault("batch.s3.prefix", "batches/")
+	viper.SetDefault("batch.max_file_size_mb", 200)
+	viper.SetDefault("batch.max_requests_per_batch", 50000)
+	viper.SetDefault("batch.max_concurrent_jobs", 2)
+	viper.SetDefault("batch.group_concurrency", 4)
+	viper.SetDefault("batch.discount_multiplier", 0.5)
+	viper.SetDefault("batch.message_batch_discount_multiplier", 0.5)
+	viper.SetDefault("batch.worker_interval_seconds", 5)
+	viper.SetDefault("batch.request_timeout_seconds", 600)
+	viper.SetDefault("batch.stale_job_seconds", 600)
+
+	// Payment
+	viper.SetDefault("payment.enabled", false)
+	viper.SetDefault("payment.currency", "CNY")
+	viper.SetDefault("payment.balance_rate", 1.0)
+	viper.SetDefault("payment.min_amount", 1.0)
+	viper.SetDefault("payment.max_amount", 10000.0)
+	viper.SetDefault("payment.order_expire_minutes", 30)
+	viper.SetDefault("payment.public_base_url", "")
+	viper.SetDefault("payment.return_url", "")
+	viper.SetDefault("payment.stripe.enabled", false)
+	viper.SetDefault("payment.stripe.api_base", "https://api.stripe.com")
+	viper.SetDefault("payment.epay.enabled", false)
+	viper.SetDefault("payment.epay.channels", []string{"alipay", "wxpay"})
+	viper.SetDefault("payment.webhook.enabled", false)
+	viper.SetDefault("payment.webhook.timestamp_tolerance_seconds", 300)
+	viper.SetDefault("payment.fake.enabled", false)
+
+	// Balance ledger
+	viper.SetDefault("balance_ledger.usage_flush_interval_seconds", 60)
+	viper.SetDefault("balance_ledger.usage_flush_batch_size", 500)
+	viper.SetDefault("balance_ledger.reconcile_interval_minutes", 60)
+	viper.SetDefault("balance_ledger.drift_tolerance", 0.000001)
+	viper.SetDefault("balance_ledger.alert_channel_ids", []int64{})
+
+	// Postpaid
+	viper.SetDefault("postpaid.check_interval_seconds", 3600)
+	viper.SetDefault("postpaid.tax_rate", 0.0)
+	viper.SetDefault("postpaid.due_days", 15)
+	viper.SetDefault("postpaid.issuer_name", "Sub2API")
+	viper.SetDefault("postpaid.issuer_details", "")
+
 	// Idempotency
 	viper.SetDefault("idempotency.observe_only", true)
 	viper.SetDefault("idempotency.default_ttl_seconds", 86400)
@@ -1382,6 +1817,15 @@ func setDefaults() {
 	viper.SetDefault("idempotency.cleanup_interval_seconds", 60)
 	viper.SetDefault("idempotency.cleanup_batch_size", 500)
 
+	// Tracing
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.service_name", "")
+	viper.SetDefault("tracing.exporter", "otlp_http")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.export_timeout_seconds", 10)
+
 	// Gateway
 	viper.SetDefault("gateway.response_header_timeout", 600) // 600秒(10分钟)等待上游响应头，LLM高负载时可能排队较久
 	viper.SetDefault("gateway.log_upstream_error_body", true)
@@ -1444,6 +1888,8 @@ func setDefaults() {
 	viper.SetDefault("gateway.upstream_response_read_max_bytes", int64(8*1024*1024))
 	viper.SetDefault("gateway.proxy_probe_response_read_max_bytes", int64(1024*1024))
 	viper.SetDefault("gateway.gemini_debug_response_headers", false)
+	viper.SetDefault("gateway.audio_max_body_size", int64(32*1024*1024))
+	viper.SetDefault("gateway.response_cache_max_entry_bytes", 2*1024*1024)
 	viper.SetDefault("gateway.sora_max_body_size", int64(256*1024*1024))
 	viper.SetDefault("gateway.sora_stream_timeout_seconds", 900)
 	viper.SetDefault("gateway.sora_request_timeout_seconds", 180)
@@ -1479,6 +1925,8 @@ func setDefaults() {
 	viper.SetDefault("gateway.scheduling.outbox_lag_rebuild_failures", 3)
 	viper.SetDefault("gateway.scheduling.outbox_backlog_rebuild_rows", 10000)
 	viper.SetDefault("gateway.scheduling.full_rebuild_interval_seconds", 300)
+	viper.SetDefault("gateway.scheduling.account_health_mode", AccountHealthModeLocal)
+	viper.SetDefault("gateway.scheduling.account_health_sync_interval_seconds", 15)
 	viper.SetDefault("gateway.usage_record.worker_count", 128)
 	viper.SetDefault("gateway.usage_record.queue_size", 16384)
 	viper.SetDefault("gateway.usage_record.task_timeout_seconds", 5)
@@ -1510,6 +1958,17 @@ func setDefaults() {
 	viper.SetDefault("gateway.error_throttle.window_seconds", 60)
 	viper.SetDefault("gateway.error_throttle.cooldown_seconds", 60)
 
+	// 对冲请求默认值（默认关闭）
+	viper.SetDefault("gateway.digest_session.backend", DigestSessionBackendMemory)
+	viper.SetDefault("gateway.hedging.enabled", false)
+	viper.SetDefault("gateway.hedging.delay_percentile", 95)
+	viper.SetDefault("gateway.hedging.min_delay_ms", 500)
+	viper.SetDefault("gateway.hedging.max_delay_ms", 30000)
+	viper.SetDefault("gateway.hedging.initial_delay_ms", 8000)
+	viper.SetDefault("gateway.hedging.min_samples", 20)
+	viper.SetDefault("gateway.hedging.sample_size", 200)
+	viper.SetDefault("gateway.hedging.budget_window_seconds", 60)
+
 	viper.SetDefault("gateway.tls_fingerprint.enabled", true)
 	viper.SetDefault("concurrency.ping_interval", 10)
 
@@ -1626,6 +2085,45 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	shippingNames := make(map[string]struct{}, len(c.Log.Shipping.Sinks))
+	for i, sink := range c.Log.Shipping.Sinks {
+		field := fmt.Sprintf("log.shipping.sinks[%d]", i)
+		if _, ok := shippingNames[sink.Name]; ok {
+			return fmt.Errorf("%s.name %q is duplicated", field, sink.Name)
+		}
+		shippingNames[sink.Name] = struct{}{}
+		switch sink.Type {
+		case LogShippingSinkLoki, LogShippingSinkElasticsearch, LogShippingSinkHTTP:
+			if sink.Enabled {
+				if err := ValidateAbsoluteHTTPURL(sink.URL); err != nil {
+					return fmt.Errorf("%s.url invalid: %w", field, err)
+				}
+			}
+		case LogShippingSinkSyslog:
+			switch sink.Network {
+			case "", "tcp", "udp":
+			default:
+				return fmt.Errorf("%s.network must be one of: tcp/udp", field)
+			}
+			if sink.Enabled && sink.Address == "" {
+				return fmt.Errorf("%s.address is required for syslog", field)
+			}
+			if sink.Facility < 0 || sink.Facility > 23 {
+				return fmt.Errorf("%s.facility must be between 0-23", field)
+			}
+		default:
+			return fmt.Errorf("%s.type must be one of: loki/elasticsearch/http/syslog", field)
+		}
+		switch sink.MinLevel {
+		case "", "debug", "info", "warn", "error":
+		default:
+			return fmt.Errorf("%s.min_level must be one of: debug/info/warn/error", field)
+		}
+		if sink.QueueSize < 0 || sink.BatchSize < 0 || sink.FlushIntervalMs < 0 || sink.TimeoutSeconds < 0 {
+			return fmt.Errorf("%s queue_size/batch_size/flush_interval_ms/timeout_seconds must be non-negative", field)
+		}
+	}
+
 	if c.SubscriptionMaintenance.WorkerCount < 0 {
 		return fmt.Errorf("subscription_maintenance.worker_count must be non-negative")
 	}
@@ -1751,6 +2249,15 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("billing.circuit_breaker.half_open_requests must be positive")
 		}
 	}
+	if c.Billing.Reservation.HoldTTLSeconds <= 0 {
+		return fmt.Errorf("billing.reservation.hold_ttl_seconds must be positive")
+	}
+	if c.Billing.Reservation.DefaultMaxOutputTokens < 0 {
+		return fmt.Errorf("billing.reservation.default_max_output_tokens must be non-negative")
+	}
+	if c.Billing.Reservation.AudioMinBitrateKbps <= 0 {
+		return fmt.Errorf("billing.reservation.audio_min_bitrate_kbps must be positive")
+	}
 	if c.Database.MaxOpenConns <= 0 {
 		return fmt.Errorf("database.max_open_conns must be positive")
 	}
@@ -1897,6 +2404,105 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("usage_cleanup.task_timeout_seconds must be non-negative")
 		}
 	}
+	if c.Batch.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.Batch.Storage)) {
+		case "local", "":
+		case "s3":
+			if strings.TrimSpace(c.Batch.S3.Bucket) == "" {
+				return fmt.Errorf("batch.s3.bucket is required when batch.storage is s3")
+			}
+		default:
+			return fmt.Errorf("batch.storage must be one of: local/s3")
+		}
+		if c.Batch.MaxFileSizeMB <= 0 {
+			return fmt.Errorf("batch.max_file_size_mb must be positive")
+		}
+		if c.Batch.MaxRequestsPerBatch <= 0 {
+			return fmt.Errorf("batch.max_requests_per_batch must be positive")
+		}
+		if c.Batch.MaxConcurrentJobs <= 0 {
+			return fmt.Errorf("batch.max_concurrent_jobs must be positive")
+		}
+		if c.Batch.GroupConcurrency <= 0 {
+			return fmt.Errorf("batch.group_concurrency must be positive")
+		}
+		if c.Batch.WorkerIntervalSeconds <= 0 {
+			return fmt.Errorf("batch.worker_interval_seconds must be positive")
+		}
+	}
+	if c.Batch.DiscountMultiplier < 0 {
+		return fmt.Errorf("batch.discount_multiplier must be non-negative")
+	}
+	if c.Batch.MessageBatchDiscountMultiplier < 0 {
+		return fmt.Errorf("batch.message_batch_discount_multiplier must be non-negative")
+	}
+	// 模拟支付回调无签名即可入账，生产（release）模式下禁止开启
+	if c.Payment.Fake.Enabled && c.Server.Mode == "release" {
+		return fmt.Errorf("payment.fake.enabled is not allowed when server.mode is release")
+	}
+	if c.Payment.Enabled {
+		if c.Payment.Currency == "" {
+			return fmt.Errorf("payment.currency is required when payment is enabled")
+		}
+		if c.Payment.BalanceRate <= 0 {
+			return fmt.Errorf("payment.balance_rate must be positive")
+		}
+		if c.Payment.MinAmount < 0 || c.Payment.MaxAmount < 0 {
+			return fmt.Errorf("payment.min_amount and payment.max_amount must be non-negative")
+		}
+		if c.Payment.MaxAmount > 0 && c.Payment.MaxAmount < c.Payment.MinAmount {
+			return fmt.Errorf("payment.max_amount must be greater than or equal to payment.min_amount")
+		}
+		if c.Payment.OrderExpireMinutes <= 0 {
+			return fmt.Errorf("payment.order_expire_minutes must be positive")
+		}
+		if !c.Payment.Stripe.Enabled && !c.Payment.EPay.Enabled && !c.Payment.Webhook.Enabled && !c.Payment.Fake.Enabled {
+			return fmt.Errorf("payment requires at least one provider (stripe/epay/webhook/fake) to be enabled")
+		}
+		if (c.Payment.Stripe.Enabled || c.Payment.EPay.Enabled || c.Payment.Webhook.Enabled) && c.Payment.PublicBaseURL == "" {
+			return fmt.Errorf("payment.public_base_url is required for payment callbacks")
+		}
+		if c.Payment.Stripe.Enabled && (c.Payment.Stripe.SecretKey == "" || c.Payment.Stripe.WebhookSecret == "") {
+			return fmt.Errorf("payment.stripe.secret_key and payment.stripe.webhook_secret are required when stripe is enabled")
+		}
+		if c.Payment.EPay.Enabled {
+			if c.Payment.EPay.Gateway == "" || c.Payment.EPay.PID == "" || c.Payment.EPay.Key == "" {
+				return fmt.Errorf("payment.epay.gateway, payment.epay.pid and payment.epay.key are required when epay is enabled")
+			}
+			if len(c.Payment.EPay.Channels) == 0 {
+				return fmt.Errorf("payment.epay.channels must not be empty when epay is enabled")
+			}
+		}
+		if c.Payment.Webhook.Enabled {
+			if c.Payment.Webhook.CheckoutURL == "" || c.Payment.Webhook.Secret == "" {
+				return fmt.Errorf("payment.webhook.checkout_url and payment.webhook.secret are required when webhook provider is enabled")
+			}
+			if c.Payment.Webhook.TimestampToleranceSeconds <= 0 {
+				return fmt.Errorf("payment.webhook.timestamp_tolerance_seconds must be positive")
+			}
+		}
+	}
+	if c.BalanceLedger.UsageFlushIntervalSeconds <= 0 {
+		return fmt.Errorf("balance_ledger.usage_flush_interval_seconds must be positive")
+	}
+	if c.BalanceLedger.UsageFlushBatchSize <= 0 {
+		return fmt.Errorf("balance_ledger.usage_flush_batch_size must be positive")
+	}
+	if c.BalanceLedger.ReconcileIntervalMinutes < 0 {
+		return fmt.Errorf("balance_ledger.reconcile_interval_minutes must be non-negative")
+	}
+	if c.BalanceLedger.DriftTolerance < 0 {
+		return fmt.Errorf("balance_ledger.drift_tolerance must be non-negative")
+	}
+	if c.Postpaid.CheckIntervalSeconds <= 0 {
+		return fmt.Errorf("postpaid.check_interval_seconds must be positive")
+	}
+	if c.Postpaid.TaxRate < 0 || c.Postpaid.TaxRate >= 1 {
+		return fmt.Errorf("postpaid.tax_rate must be in [0, 1)")
+	}
+	if c.Postpaid.DueDays < 0 {
+		return fmt.Errorf("postpaid.due_days must be non-negative")
+	}
 	if c.Idempotency.DefaultTTLSeconds <= 0 {
 		return fmt.Errorf("idempotency.default_ttl_seconds must be positive")
 	}
@@ -1918,6 +2524,19 @@ func (c *Config) Validate() error {
 	if c.Idempotency.CleanupBatchSize <= 0 {
 		return fmt.Errorf("idempotency.cleanup_batch_size must be positive")
 	}
+	if c.Tracing.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.Tracing.Exporter)) {
+		case "otlp_http", "stdout":
+		default:
+			return fmt.Errorf("tracing.exporter must be one of: otlp_http, stdout")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+		}
+		if c.Tracing.ExportTimeoutSeconds < 0 {
+			return fmt.Errorf("tracing.export_timeout_seconds must be non-negative")
+		}
+	}
 	if c.Gateway.MaxBodySize <= 0 {
 		return fmt.Errorf("gateway.max_body_size must be positive")
 	}
@@ -1927,9 +2546,31 @@ func (c *Config) Validate() error {
 	if c.Gateway.ProxyProbeResponseReadMaxBytes <= 0 {
 		return fmt.Errorf("gateway.proxy_probe_response_read_max_bytes must be positive")
 	}
+	if c.Gateway.AudioMaxBodySize < 0 {
+		return fmt.Errorf("gateway.audio_max_body_size must be non-negative")
+	}
+	if c.Gateway.ResponseCacheMaxEntryBytes < 0 {
+		return fmt.Errorf("gateway.response_cache_max_entry_bytes must be non-negative")
+	}
 	if c.Gateway.SoraMaxBodySize < 0 {
 		return fmt.Errorf("gateway.sora_max_body_size must be non-negative")
 	}
+	if c.Gateway.Hedging.Enabled {
+		if p := c.Gateway.Hedging.DelayPercentile; p <= 0 || p > 100 {
+			return fmt.Errorf("gateway.hedging.delay_percentile must be between 0 and 100")
+		}
+		if c.Gateway.Hedging.MinDelayMs < 0 || c.Gateway.Hedging.MaxDelayMs < c.Gateway.Hedging.MinDelayMs {
+			return fmt.Errorf("gateway.hedging.min_delay_ms must be non-negative and not greater than max_delay_ms")
+		}
+		if c.Gateway.Hedging.SampleSize <= 0 || c.Gateway.Hedging.BudgetWindowSeconds <= 0 {
+			return fmt.Errorf("gateway.hedging.sample_size and budget_window_seconds must be positive")
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Gateway.DigestSession.Backend)) {
+	case "", DigestSessionBackendMemory, DigestSessionBackendRedis:
+	default:
+		return fmt.Errorf("gateway.digest_session.backend must be one of: memory/redis")
+	}
 	if c.Gateway.SoraStreamTimeoutSeconds < 0 {
 		return fmt.Errorf("gateway.sora_stream_timeout_seconds must be non-negative")
 	}
@@ -2276,6 +2917,14 @@ func (c *Config) Validate() error {
 	if c.Gateway.Scheduling.FullRebuildIntervalSeconds < 0 {
 		return fmt.Errorf("gateway.scheduling.full_rebuild_interval_seconds must be non-negative")
 	}
+	switch strings.ToLower(strings.TrimSpace(c.Gateway.Scheduling.AccountHealthMode)) {
+	case "", AccountHealthModeLocal, AccountHealthModeRedis:
+	default:
+		return fmt.Errorf("gateway.scheduling.account_health_mode must be one of: local, redis")
+	}
+	if c.Gateway.Scheduling.AccountHealthSyncIntervalSeconds < 0 {
+		return fmt.Errorf("gateway.scheduling.account_health_sync_interval_seconds must be non-negative")
+	}
 	if c.Gateway.Scheduling.OutboxLagWarnSeconds > 0 &&
 		c.Gateway.Scheduling.OutboxLagRebuildSeconds > 0 &&
 		c.Gateway.Scheduling.OutboxLagRebuildSeconds < c.Gateway.Scheduling.OutboxLagWarnSeconds {
@@ -2284,6 +2933,9 @@ func (c *Config) Validate() error {
 	if c.Ops.MetricsCollectorCache.TTL < 0 {
 		return fmt.Errorf("ops.metrics_collector_cache.ttl must be non-negative")
 	}
+	if c.Ops.Prometheus.Enabled && strings.TrimSpace(c.Ops.Prometheus.Token) == "" {
+		return fmt.Errorf("ops.prometheus.token is required when ops.prometheus.enabled=true")
+	}
 	if c.Ops.Cleanup.ErrorLogRetentionDays < 0 {
 		return fmt.Errorf("ops.cleanup.error_log_retention_days must be non-negative")
 	}
@@ -2296,6 +2948,30 @@ func (c *Config) Validate() error {
 	if c.Ops.Cleanup.Enabled && strings.TrimSpace(c.Ops.Cleanup.Schedule) == "" {
 		return fmt.Errorf("ops.cleanup.schedule is required when ops.cleanup.enabled=true")
 	}
+	switch strings.ToLower(strings.TrimSpace(c.Ops.PayloadCapture.Storage)) {
+	case "", "local":
+	case "s3":
+		if strings.TrimSpace(c.Ops.PayloadCapture.S3.Bucket) == "" {
+			return fmt.Errorf("ops.payload_capture.s3.bucket is required when ops.payload_capture.storage=s3")
+		}
+	default:
+		return fmt.Errorf("ops.payload_capture.storage must be one of: local/s3")
+	}
+	if c.Ops.PayloadCapture.RetentionHours < 0 {
+		return fmt.Errorf("ops.payload_capture.retention_hours must be non-negative")
+	}
+	if c.Ops.PayloadCapture.MaxBodyBytes < 0 || c.Ops.PayloadCapture.QueueSize < 0 {
+		return fmt.Errorf("ops.payload_capture.max_body_bytes and queue_size must be non-negative")
+	}
+	if t := c.Ops.CustomerSLA.TargetAvailability; t != 0 && (t <= 0 || t >= 100) {
+		return fmt.Errorf("ops.customer_sla.target_availability must be between 0 and 100 (exclusive)")
+	}
+	if c.Ops.StatusPage.IncidentDays < 0 || c.Ops.StatusPage.IncidentDays > 90 {
+		return fmt.Errorf("ops.status_page.incident_days must be between 0-90")
+	}
+	if c.Ops.StatusPage.CacheTTLSeconds < 0 {
+		return fmt.Errorf("ops.status_page.cache_ttl_seconds must be non-negative")
+	}
 	if c.Concurrency.PingInterval < 5 || c.Concurrency.PingInterval > 30 {
 		return fmt.Errorf("concurrency.ping_interval must be between 5-30 seconds")
 	}
@@ -1289,6 +1289,37 @@ func TestValidateConfigErrors(t *testing.T) {
 			},
 			wantErr: "log.sampling.initial",
 		},
+		{
+			name: "log shipping type invalid",
+			mutate: func(c *Config) {
+				c.Log.Shipping.Sinks = []LogShippingSinkConfig{{Name: "x", Type: "kafka"}}
+			},
+			wantErr: "log.shipping.sinks[0].type",
+		},
+		{
+			name: "log shipping enabled without url",
+			mutate: func(c *Config) {
+				c.Log.Shipping.Sinks = []LogShippingSinkConfig{{Name: "loki", Type: LogShippingSinkLoki, Enabled: true}}
+			},
+			wantErr: "log.shipping.sinks[0].url",
+		},
+		{
+			name: "log shipping syslog without address",
+			mutate: func(c *Config) {
+				c.Log.Shipping.Sinks = []LogShippingSinkConfig{{Name: "syslog", Type: LogShippingSinkSyslog, Enabled: true, Network: "tcp"}}
+			},
+			wantErr: "log.shipping.sinks[0].address",
+		},
+		{
+			name: "log shipping duplicate name",
+			mutate: func(c *Config) {
+				c.Log.Shipping.Sinks = []LogShippingSinkConfig{
+					{Name: "a", Type: LogShippingSinkHTTP},
+					{Name: "a", Type: LogShippingSinkHTTP},
+				}
+			},
+			wantErr: "log.shipping.sinks[1].name",
+		},
 		{
 			name:    "ops metrics collector ttl",
 			mutate:  func(c *Config) { c.Ops.MetricsCollectorCache.TTL = -1 },
@@ -1707,3 +1738,112 @@ func TestLoad_DefaultGatewayUsageRecordConfig(t *testing.T) {
 		t.Fatalf("auto_scale_cooldown_seconds = %d, want 10", cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds)
 	}
 }
+
+func TestValidatePaymentConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Payment.Enabled || cfg.Payment.Currency != "CNY" || cfg.Payment.OrderExpireMinutes != 30 {
+		t.Fatalf("unexpected payment defaults: %+v", cfg.Payment)
+	}
+
+	cfg.Payment.Enabled = true
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "at least one provider") {
+		t.Fatalf("Validate() expected provider error, got: %v", err)
+	}
+
+	cfg.Payment.Stripe.Enabled = true
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "payment.public_base_url") {
+		t.Fatalf("Validate() expected public_base_url error, got: %v", err)
+	}
+
+	cfg.Payment.PublicBaseURL = "https://api.example.com"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "payment.stripe.secret_key") {
+		t.Fatalf("Validate() expected stripe key error, got: %v", err)
+	}
+
+	cfg.Payment.Stripe.SecretKey = "sk_test"
+	cfg.Payment.Stripe.WebhookSecret = "whsec"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	cfg.Payment.Fake.Enabled = true
+	cfg.Server.Mode = "release"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "payment.fake.enabled") {
+		t.Fatalf("Validate() expected fake provider release error, got: %v", err)
+	}
+
+	cfg.Server.Mode = "debug"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+}
+
+func TestValidateBalanceLedgerConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.BalanceLedger.UsageFlushIntervalSeconds != 60 || cfg.BalanceLedger.UsageFlushBatchSize != 500 || cfg.BalanceLedger.ReconcileIntervalMinutes != 60 {
+		t.Fatalf("unexpected balance ledger defaults: %+v", cfg.BalanceLedger)
+	}
+
+	cfg.BalanceLedger.UsageFlushBatchSize = 0
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "balance_ledger.usage_flush_batch_size") {
+		t.Fatalf("Validate() expected batch size error, got: %v", err)
+	}
+
+	cfg.BalanceLedger.UsageFlushBatchSize = 100
+	cfg.BalanceLedger.DriftTolerance = -1
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "balance_ledger.drift_tolerance") {
+		t.Fatalf("Validate() expected drift tolerance error, got: %v", err)
+	}
+
+	cfg.BalanceLedger.DriftTolerance = 0
+	cfg.BalanceLedger.ReconcileIntervalMinutes = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidatePostpaidConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Postpaid.CheckIntervalSeconds != 3600 || cfg.Postpaid.DueDays != 15 || cfg.Postpaid.TaxRate != 0 {
+		t.Fatalf("unexpected postpaid defaults: %+v", cfg.Postpaid)
+	}
+
+	cfg.Postpaid.TaxRate = 1
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "postpaid.tax_rate") {
+		t.Fatalf("Validate() expected tax rate error, got: %v", err)
+	}
+
+	cfg.Postpaid.TaxRate = 0.2
+	cfg.Postpaid.DueDays = -1
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "postpaid.due_days") {
+		t.Fatalf("Validate() expected due days error, got: %v", err)
+	}
+
+	cfg.Postpaid.DueDays = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
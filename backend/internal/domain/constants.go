@@ -61,6 +61,12 @@ const (
 	SubscriptionTypeSubscription = "subscription" // 订阅模式（按限额控制）
 )
 
+// User billing mode constants
+const (
+	BillingModePrepaid  = "prepaid"  // 预付费：余额不足即拒绝请求
+	BillingModePostpaid = "postpaid" // 后付费：允许透支至信用额度，按月出账
+)
+
 // Subscription status constants
 const (
 	SubscriptionStatusActive    = "active"
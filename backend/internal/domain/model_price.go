@@ -0,0 +1,17 @@
+package domain
+
+// 价格来源（记录在 usage_logs.price_source）
+const (
+	PriceSourceGroup    = "group"    // 分组自定义模型价格
+	PriceSourceLiteLLM  = "litellm"  // 远程 LiteLLM 价格表
+	PriceSourceFallback = "fallback" // 内置回退价格
+)
+
+// GroupModelPrice 分组自定义模型价格（USD per MTok）。
+// 字段为 nil 时该项沿用全局价格表。
+type GroupModelPrice struct {
+	InputPrice      *float64 `json:"input_price,omitempty"`
+	OutputPrice     *float64 `json:"output_price,omitempty"`
+	CacheReadPrice  *float64 `json:"cache_read_price,omitempty"`
+	CacheWritePrice *float64 `json:"cache_write_price,omitempty"`
+}
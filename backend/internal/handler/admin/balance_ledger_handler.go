@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceLedgerHandler handles admin balance ledger queries and reconciliation
+type BalanceLedgerHandler struct {
+	balanceLedgerService *service.BalanceLedgerService
+}
+
+// NewBalanceLedgerHandler creates a new admin balance ledger handler
+func NewBalanceLedgerHandler(balanceLedgerService *service.BalanceLedgerService) *BalanceLedgerHandler {
+	return &BalanceLedgerHandler{balanceLedgerService: balanceLedgerService}
+}
+
+// ListUserEntries handles listing a user's balance ledger entries
+// GET /api/v1/admin/users/:id/balance-ledger?entry_type=&start_date=&end_date=&timezone=
+func (h *BalanceLedgerHandler) ListUserEntries(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	filter := service.BalanceLedgerFilter{EntryType: strings.TrimSpace(c.Query("entry_type"))}
+	userTZ := c.Query("timezone")
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		filter.StartTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		t = t.AddDate(0, 0, 1)
+		filter.EndTime = &t
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	entries, result, err := h.balanceLedgerService.ListEntries(c.Request.Context(), userID, pagination.PaginationParams{Page: page, PageSize: pageSize}, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.AdminBalanceLedgerEntry, 0, len(entries))
+	for i := range entries {
+		out = append(out, *dto.AdminBalanceLedgerEntryFromService(&entries[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Reconcile runs a balance reconciliation immediately and returns the users whose
+// balance does not match the ledger. Drift alerts are dispatched as in scheduled runs.
+// POST /api/v1/admin/balance-ledger/reconcile
+func (h *BalanceLedgerHandler) Reconcile(c *gin.Context) {
+	result, err := h.balanceLedgerService.Reconcile(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
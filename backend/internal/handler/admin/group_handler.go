@@ -20,6 +20,7 @@ type GroupHandler struct {
 	adminService         service.AdminService
 	dashboardService     *service.DashboardService
 	groupCapacityService *service.GroupCapacityService
+	responseCacheService *service.ResponseCacheService
 }
 
 type optionalLimitField struct {
@@ -72,11 +73,12 @@ func (f optionalLimitField) ToServiceInput() *float64 {
 }
 
 // NewGroupHandler creates a new admin group handler
-func NewGroupHandler(adminService service.AdminService, dashboardService *service.DashboardService, groupCapacityService *service.GroupCapacityService) *GroupHandler {
+func NewGroupHandler(adminService service.AdminService, dashboardService *service.DashboardService, groupCapacityService *service.GroupCapacityService, responseCacheService *service.ResponseCacheService) *GroupHandler {
 	return &GroupHandler{
 		adminService:         adminService,
 		dashboardService:     dashboardService,
 		groupCapacityService: groupCapacityService,
+		responseCacheService: responseCacheService,
 	}
 }
 
@@ -111,6 +113,8 @@ type CreateGroupRequest struct {
 	ModelAliases  map[string]string `json:"model_aliases"`
 	FallbackModel string            `json:"fallback_model"`
 	MCPXMLInject  *bool             `json:"mcp_xml_inject"`
+	// 分组自定义模型价格（USD per MTok，支持通配符，如 "claude-opus-*"），优先于全局价格表
+	ModelPrices map[string]service.GroupModelPrice `json:"model_prices"`
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string `json:"supported_model_scopes"`
 	// Sora 存储配额
@@ -120,6 +124,16 @@ type CreateGroupRequest struct {
 	RequireOAuthOnly      bool   `json:"require_oauth_only"`
 	RequirePrivacySet     bool   `json:"require_privacy_set"`
 	DefaultMappedModel    string `json:"default_mapped_model"`
+	// 精确匹配响应缓存配置
+	ResponseCacheEnabled       bool     `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds    *int     `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier *float64 `json:"response_cache_hit_multiplier"`
+	// 对冲请求预算（百分比，0 表示关闭）
+	HedgeBudgetPercent float64 `json:"hedge_budget_percent"`
+	// 账号调度策略（空表示网关默认策略）
+	ScheduleStrategy string `json:"schedule_strategy"`
+	// 计费预留模式：optimistic / strict（空表示 optimistic）
+	BillingReservationMode string `json:"billing_reservation_mode"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -156,6 +170,8 @@ type UpdateGroupRequest struct {
 	ModelAliases  map[string]string `json:"model_aliases"`
 	FallbackModel *string           `json:"fallback_model"`
 	MCPXMLInject  *bool             `json:"mcp_xml_inject"`
+	// 分组自定义模型价格（空对象表示清除全部自定义价格）
+	ModelPrices map[string]service.GroupModelPrice `json:"model_prices"`
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes *[]string `json:"supported_model_scopes"`
 	// Sora 存储配额
@@ -165,6 +181,16 @@ type UpdateGroupRequest struct {
 	RequireOAuthOnly      *bool   `json:"require_oauth_only"`
 	RequirePrivacySet     *bool   `json:"require_privacy_set"`
 	DefaultMappedModel    *string `json:"default_mapped_model"`
+	// 精确匹配响应缓存配置
+	ResponseCacheEnabled       *bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds    *int     `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier *float64 `json:"response_cache_hit_multiplier"`
+	// 对冲请求预算（百分比，0 表示关闭）
+	HedgeBudgetPercent *float64 `json:"hedge_budget_percent"`
+	// 账号调度策略（空字符串表示恢复网关默认策略）
+	ScheduleStrategy *string `json:"schedule_strategy"`
+	// 计费预留模式：optimistic / strict
+	BillingReservationMode *string `json:"billing_reservation_mode"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -280,6 +306,7 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		ModelRouting:                    req.ModelRouting,
 		ModelRoutingEnabled:             req.ModelRoutingEnabled,
 		ModelAliases:                    req.ModelAliases,
+		ModelPrices:                     req.ModelPrices,
 		FallbackModel:                   req.FallbackModel,
 		MCPXMLInject:                    req.MCPXMLInject,
 		SupportedModelScopes:            req.SupportedModelScopes,
@@ -288,6 +315,12 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		RequireOAuthOnly:                req.RequireOAuthOnly,
 		RequirePrivacySet:               req.RequirePrivacySet,
 		DefaultMappedModel:              req.DefaultMappedModel,
+		ResponseCacheEnabled:            req.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         req.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      req.ResponseCacheHitMultiplier,
+		HedgeBudgetPercent:              req.HedgeBudgetPercent,
+		ScheduleStrategy:                req.ScheduleStrategy,
+		BillingReservationMode:          req.BillingReservationMode,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -339,6 +372,7 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		ModelRouting:                    req.ModelRouting,
 		ModelRoutingEnabled:             req.ModelRoutingEnabled,
 		ModelAliases:                    req.ModelAliases,
+		ModelPrices:                     req.ModelPrices,
 		FallbackModel:                   req.FallbackModel,
 		MCPXMLInject:                    req.MCPXMLInject,
 		SupportedModelScopes:            req.SupportedModelScopes,
@@ -347,6 +381,12 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		RequireOAuthOnly:                req.RequireOAuthOnly,
 		RequirePrivacySet:               req.RequirePrivacySet,
 		DefaultMappedModel:              req.DefaultMappedModel,
+		ResponseCacheEnabled:            req.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         req.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      req.ResponseCacheHitMultiplier,
+		HedgeBudgetPercent:              req.HedgeBudgetPercent,
+		ScheduleStrategy:                req.ScheduleStrategy,
+		BillingReservationMode:          req.BillingReservationMode,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -483,6 +523,36 @@ func (h *GroupHandler) ClearGroupRateMultipliers(c *gin.Context) {
 	response.Success(c, gin.H{"message": "Rate multipliers cleared successfully"})
 }
 
+// PurgeResponseCache handles purging the exact-match response cache of a group
+// DELETE /api/v1/admin/groups/:id/response-cache
+func (h *GroupHandler) PurgeResponseCache(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	deleted, err := h.responseCacheService.PurgeGroup(c.Request.Context(), groupID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"deleted": deleted})
+}
+
+// PurgeAllResponseCache handles purging the exact-match response cache of all groups
+// DELETE /api/v1/admin/groups/response-cache
+func (h *GroupHandler) PurgeAllResponseCache(c *gin.Context) {
+	deleted, err := h.responseCacheService.PurgeAll(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"deleted": deleted})
+}
+
 // BatchSetGroupRateMultipliersRequest represents batch set rate multipliers request
 type BatchSetGroupRateMultipliersRequest struct {
 	Entries []service.GroupRateMultiplierInput `json:"entries" binding:"required"`
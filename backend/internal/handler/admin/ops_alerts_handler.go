@@ -30,6 +30,9 @@ var validOpsAlertMetricTypes = []string{
 	"account_error_count",
 	"account_error_ratio",
 	"overload_account_count",
+	"error_rate_baseline_zscore",
+	"token_throughput_drop_percent",
+	"cost_per_user_spike_percent",
 }
 
 var validOpsAlertMetricTypeSet = func() map[string]struct{} {
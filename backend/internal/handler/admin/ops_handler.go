@@ -1126,10 +1126,32 @@ func buildRequestTraceResponse(queryKey, queryKeyType string, trace *service.Ops
 		"timeline":         buildTraceTimeline(trace.TraceEvents),
 		"usage":            usagePayload,
 		"result":           resultPayload,
+		"payload_captures": extractTracePayloadCaptures(trace.TraceEvents),
 		"trace_incomplete": trace.TraceIncomplete,
 	}
 }
 
+// extractTracePayloadCaptures 提取请求关联的载荷抓取，前端据 capture_id 调用 payload-captures 接口查看内容。
+func extractTracePayloadCaptures(events []*service.OpsRequestTraceEvent) []gin.H {
+	out := []gin.H{}
+	for _, event := range events {
+		if event == nil || event.Type != service.OpsPayloadCaptureTraceEvent {
+			continue
+		}
+		id, _ := event.Data["capture_id"].(string)
+		if strings.TrimSpace(id) == "" {
+			continue
+		}
+		rule, _ := event.Data["rule"].(string)
+		out = append(out, gin.H{
+			"capture_id":  id,
+			"rule":        rule,
+			"captured_at": event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		})
+	}
+	return out
+}
+
 func resolveRequestTraceMatchedBy(queryKey string, trace *service.OpsRequestTrace) string {
 	queryKey = strings.TrimSpace(queryKey)
 	switch {
@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OpsNotificationHandler 运维告警通知渠道管理
+type OpsNotificationHandler struct {
+	opsService          *service.OpsService
+	notificationService *service.OpsNotificationService
+}
+
+func NewOpsNotificationHandler(opsService *service.OpsService, notificationService *service.OpsNotificationService) *OpsNotificationHandler {
+	return &OpsNotificationHandler{opsService: opsService, notificationService: notificationService}
+}
+
+type opsNotificationChannelRequest struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Enabled     *bool             `json:"enabled"`
+	URL         string            `json:"url"`
+	Secret      string            `json:"secret"`
+	BotToken    string            `json:"bot_token"`
+	ChatID      string            `json:"chat_id"`
+	Headers     map[string]string `json:"headers"`
+	Template    string            `json:"template"`
+	MinSeverity string            `json:"min_severity"`
+}
+
+func (r *opsNotificationChannelRequest) toChannel(id int64) *service.OpsNotificationChannel {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &service.OpsNotificationChannel{
+		ID:          id,
+		Name:        r.Name,
+		Type:        r.Type,
+		Enabled:     enabled,
+		URL:         r.URL,
+		Secret:      r.Secret,
+		BotToken:    r.BotToken,
+		ChatID:      r.ChatID,
+		Headers:     r.Headers,
+		Template:    r.Template,
+		MinSeverity: r.MinSeverity,
+	}
+}
+
+func (h *OpsNotificationHandler) ready(c *gin.Context) bool {
+	if h == nil || h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return false
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return false
+	}
+	return true
+}
+
+// ListChannels returns all notification channels (credentials masked).
+// GET /api/v1/admin/ops/notification-channels
+func (h *OpsNotificationHandler) ListChannels(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	channels, err := h.opsService.ListNotificationChannels(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, channels)
+}
+
+// CreateChannel creates a notification channel.
+// POST /api/v1/admin/ops/notification-channels
+func (h *OpsNotificationHandler) CreateChannel(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	var req opsNotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	created, err := h.opsService.CreateNotificationChannel(c.Request.Context(), req.toChannel(0))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, created)
+}
+
+// UpdateChannel updates a notification channel. Empty secret/bot_token keeps the stored value.
+// PUT /api/v1/admin/ops/notification-channels/:id
+func (h *OpsNotificationHandler) UpdateChannel(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid channel ID")
+		return
+	}
+	var req opsNotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	updated, err := h.opsService.UpdateNotificationChannel(c.Request.Context(), req.toChannel(id))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// DeleteChannel deletes a notification channel and removes it from alert rule routing.
+// DELETE /api/v1/admin/ops/notification-channels/:id
+func (h *OpsNotificationHandler) DeleteChannel(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid channel ID")
+		return
+	}
+	if err := h.opsService.DeleteNotificationChannel(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"deleted": true})
+}
+
+// TestChannel sends a test message through a notification channel.
+// POST /api/v1/admin/ops/notification-channels/:id/test
+func (h *OpsNotificationHandler) TestChannel(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	if h.notificationService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Notification service not available")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid channel ID")
+		return
+	}
+	delivery, err := h.notificationService.SendTest(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, delivery)
+}
+
+// ListDeliveries lists recent notification deliveries.
+// GET /api/v1/admin/ops/notification-deliveries?channel_id=&alert_event_id=&limit=
+func (h *OpsNotificationHandler) ListDeliveries(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	filter := &service.OpsNotificationDeliveryFilter{Limit: 50}
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		filter.Limit = n
+	}
+	if raw := strings.TrimSpace(c.Query("channel_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid channel_id")
+			return
+		}
+		filter.ChannelID = &id
+	}
+	if raw := strings.TrimSpace(c.Query("alert_event_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid alert_event_id")
+			return
+		}
+		filter.AlertEventID = &id
+	}
+	deliveries, err := h.opsService.ListNotificationDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, deliveries)
+}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OpsPayloadCaptureHandler 运维载荷抓取规则与抓取内容查询
+type OpsPayloadCaptureHandler struct {
+	opsService     *service.OpsService
+	captureService *service.PayloadCaptureService
+}
+
+func NewOpsPayloadCaptureHandler(opsService *service.OpsService, captureService *service.PayloadCaptureService) *OpsPayloadCaptureHandler {
+	return &OpsPayloadCaptureHandler{opsService: opsService, captureService: captureService}
+}
+
+func (h *OpsPayloadCaptureHandler) ready(c *gin.Context) bool {
+	if h == nil || h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return false
+	}
+	if h.captureService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Payload capture service not available")
+		return false
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return false
+	}
+	return true
+}
+
+// GetSettings returns payload capture rules.
+// GET /api/v1/admin/ops/payload-capture/settings
+func (h *OpsPayloadCaptureHandler) GetSettings(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	settings, err := h.captureService.GetSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// UpdateSettings replaces payload capture rules.
+// PUT /api/v1/admin/ops/payload-capture/settings
+func (h *OpsPayloadCaptureHandler) UpdateSettings(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	var req service.OpsPayloadCaptureSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	updated, err := h.captureService.UpdateSettings(c.Request.Context(), &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// GetCapture returns capture metadata.
+// GET /api/v1/admin/ops/payload-captures/:id
+func (h *OpsPayloadCaptureHandler) GetCapture(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	capture, err := h.captureService.GetCapture(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, capture)
+}
+
+// DownloadContent streams the decompressed, redacted capture document (JSON).
+// GET /api/v1/admin/ops/payload-captures/:id/content
+func (h *OpsPayloadCaptureHandler) DownloadContent(c *gin.Context) {
+	if !h.ready(c) {
+		return
+	}
+	id := c.Param("id")
+	rc, err := h.captureService.OpenCaptureContent(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	defer func() { _ = rc.Close() }()
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="payload-capture-`+id+`.json"`)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, rc); err != nil {
+		logger.LegacyPrintf("handler.admin.ops_payload_capture", "[OpsPayloadCapture] stream capture %s failed: %v", id, err)
+	}
+}
@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type opsMaintenanceWindowRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Platforms   []string  `json:"platforms"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+}
+
+func (r *opsMaintenanceWindowRequest) toService(id int64) *service.OpsMaintenanceWindow {
+	return &service.OpsMaintenanceWindow{
+		ID:          id,
+		Title:       r.Title,
+		Description: r.Description,
+		Platforms:   r.Platforms,
+		StartsAt:    r.StartsAt,
+		EndsAt:      r.EndsAt,
+	}
+}
+
+// GetCustomerSLAReport returns the monthly SLA report for any user and/or group.
+// GET /api/v1/admin/ops/sla/customers?user_id=&group_id=&month=YYYY-MM
+func (h *OpsHandler) GetCustomerSLAReport(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	query := &service.OpsCustomerSLAQuery{Month: c.Query("month")}
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		query.UserID = &id
+	}
+	if raw := strings.TrimSpace(c.Query("group_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid group_id")
+			return
+		}
+		query.GroupID = &id
+	}
+
+	report, err := h.opsService.GetCustomerSLAReport(c.Request.Context(), query)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// ListMaintenanceWindows returns current and upcoming maintenance windows (include_past=true for all).
+// GET /api/v1/admin/ops/maintenance-windows
+func (h *OpsHandler) ListMaintenanceWindows(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	includePast, _ := strconv.ParseBool(strings.TrimSpace(c.Query("include_past")))
+	windows, err := h.opsService.ListMaintenanceWindows(c.Request.Context(), includePast)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, windows)
+}
+
+// CreateMaintenanceWindow schedules a maintenance window.
+// POST /api/v1/admin/ops/maintenance-windows
+func (h *OpsHandler) CreateMaintenanceWindow(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	var req opsMaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	created, err := h.opsService.CreateMaintenanceWindow(c.Request.Context(), req.toService(0))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, created)
+}
+
+// UpdateMaintenanceWindow updates a maintenance window.
+// PUT /api/v1/admin/ops/maintenance-windows/:id
+func (h *OpsHandler) UpdateMaintenanceWindow(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid maintenance window ID")
+		return
+	}
+
+	var req opsMaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	updated, err := h.opsService.UpdateMaintenanceWindow(c.Request.Context(), req.toService(id))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// DeleteMaintenanceWindow deletes a maintenance window.
+// DELETE /api/v1/admin/ops/maintenance-windows/:id
+func (h *OpsHandler) DeleteMaintenanceWindow(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid maintenance window ID")
+		return
+	}
+
+	if err := h.opsService.DeleteMaintenanceWindow(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"deleted": true})
+}
@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentHandler handles admin payment order management
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+// NewPaymentHandler creates a new admin payment handler
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// RefundPaymentOrderRequest represents the refund request payload
+type RefundPaymentOrderRequest struct {
+	Reason string `json:"reason"`
+	// ViaProvider 通过渠道 API 原路退款（仅 Stripe 支持）；为 false 时仅回收本地权益
+	ViaProvider bool `json:"via_provider"`
+}
+
+// UpsertPaymentPlanPriceRequest represents the plan price payload
+type UpsertPaymentPlanPriceRequest struct {
+	Price    float64 `json:"price" binding:"required,gt=0"`
+	Currency string  `json:"currency"`
+	Enabled  *bool   `json:"enabled"`
+}
+
+// ListOrders handles listing payment orders
+// GET /api/v1/admin/payments/orders?user_id=&status=&provider=&search=
+func (h *PaymentHandler) ListOrders(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.PaymentOrderFilter{
+		Status:   strings.TrimSpace(c.Query("status")),
+		Provider: strings.TrimSpace(c.Query("provider")),
+		Search:   strings.TrimSpace(c.Query("search")),
+	}
+	if len(filter.Search) > 100 {
+		filter.Search = filter.Search[:100]
+	}
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || userID <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	orders, result, err := h.paymentService.ListOrders(c.Request.Context(), pagination.PaginationParams{Page: page, PageSize: pageSize}, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.AdminPaymentOrder, 0, len(orders))
+	for i := range orders {
+		out = append(out, *dto.AdminPaymentOrderFromService(&orders[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// GetOrder handles getting a payment order by ID
+// GET /api/v1/admin/payments/orders/:id
+func (h *PaymentHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+
+	order, err := h.paymentService.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminPaymentOrderFromService(order))
+}
+
+// RefundOrder handles refunding a paid order and reversing its credit
+// POST /api/v1/admin/payments/orders/:id/refund
+func (h *PaymentHandler) RefundOrder(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+
+	var req RefundPaymentOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	idempotencyPayload := struct {
+		OrderID int64                     `json:"order_id"`
+		Body    RefundPaymentOrderRequest `json:"body"`
+	}{OrderID: id, Body: req}
+	executeAdminIdempotentJSON(c, "admin.payments.orders.refund", idempotencyPayload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		order, err := h.paymentService.RefundOrder(ctx, id, req.Reason, req.ViaProvider)
+		if err != nil {
+			return nil, err
+		}
+		return dto.AdminPaymentOrderFromService(order), nil
+	})
+}
+
+// ListPlanPrices handles listing subscription plan prices
+// GET /api/v1/admin/payments/plan-prices
+func (h *PaymentHandler) ListPlanPrices(c *gin.Context) {
+	prices, err := h.paymentService.ListPlanPrices(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.PaymentPlanPrice, 0, len(prices))
+	for i := range prices {
+		out = append(out, *dto.PaymentPlanPriceFromService(&prices[i]))
+	}
+	response.Success(c, out)
+}
+
+// UpsertPlanPrice handles setting the price of a subscription plan
+// PUT /api/v1/admin/payments/plan-prices/:plan_id
+func (h *PaymentHandler) UpsertPlanPrice(c *gin.Context) {
+	planID, err := strconv.ParseInt(c.Param("plan_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid plan ID")
+		return
+	}
+
+	var req UpsertPaymentPlanPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	price, err := h.paymentService.UpsertPlanPrice(c.Request.Context(), &service.PaymentPlanPrice{
+		PlanID:   planID,
+		Price:    req.Price,
+		Currency: req.Currency,
+		Enabled:  enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.PaymentPlanPriceFromService(price))
+}
+
+// DeletePlanPrice handles removing a subscription plan from sale
+// DELETE /api/v1/admin/payments/plan-prices/:plan_id
+func (h *PaymentHandler) DeletePlanPrice(c *gin.Context) {
+	planID, err := strconv.ParseInt(c.Param("plan_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid plan ID")
+		return
+	}
+
+	if err := h.paymentService.DeletePlanPrice(c.Request.Context(), planID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Plan price deleted"})
+}
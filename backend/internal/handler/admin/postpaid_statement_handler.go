@@ -0,0 +1,165 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostpaidStatementHandler handles admin postpaid statement management
+type PostpaidStatementHandler struct {
+	postpaidBillingService *service.PostpaidBillingService
+}
+
+// NewPostpaidStatementHandler creates a new admin postpaid statement handler
+func NewPostpaidStatementHandler(postpaidBillingService *service.PostpaidBillingService) *PostpaidStatementHandler {
+	return &PostpaidStatementHandler{postpaidBillingService: postpaidBillingService}
+}
+
+// MarkPostpaidStatementPaidRequest represents the mark-paid request body
+type MarkPostpaidStatementPaidRequest struct {
+	Reference string `json:"reference" binding:"max=128"`
+	Notes     string `json:"notes" binding:"max=1000"`
+}
+
+// List handles listing postpaid statements
+// GET /api/v1/admin/postpaid-statements?user_id=&status=
+func (h *PostpaidStatementHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.PostpaidStatementFilter{Status: strings.TrimSpace(c.Query("status"))}
+	if raw := strings.TrimSpace(c.Query("user_id")); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || userID <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.UserID = userID
+	}
+
+	statements, result, err := h.postpaidBillingService.ListStatements(c.Request.Context(), pagination.PaginationParams{Page: page, PageSize: pageSize}, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.AdminPostpaidStatement, 0, len(statements))
+	for i := range statements {
+		out = append(out, *dto.AdminPostpaidStatementFromService(&statements[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// GetByID handles getting a statement with its line items
+// GET /api/v1/admin/postpaid-statements/:id
+func (h *PostpaidStatementHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+
+	statement, err := h.postpaidBillingService.GetStatement(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminPostpaidStatementFromService(statement))
+}
+
+// Download handles rendering a statement as HTML or PDF
+// GET /api/v1/admin/postpaid-statements/:id/document?format=html|pdf
+func (h *PostpaidStatementHandler) Download(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+
+	statement, err := h.postpaidBillingService.GetStatement(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	doc, err := h.postpaidBillingService.RenderStatement(statement, c.Query("format"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+doc.Filename+`"`)
+	c.Data(http.StatusOK, doc.ContentType, doc.Content)
+}
+
+// MarkPaid handles marking a statement as paid. The statement subtotal is credited
+// back to the user's balance and suspended API keys are restored once no overdue
+// statements remain.
+// POST /api/v1/admin/postpaid-statements/:id/mark-paid
+func (h *PostpaidStatementHandler) MarkPaid(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+
+	var req MarkPostpaidStatementPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	var operatorID int64
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok {
+		operatorID = subject.UserID
+	}
+
+	idempotencyPayload := struct {
+		StatementID int64                            `json:"statement_id"`
+		Body        MarkPostpaidStatementPaidRequest `json:"body"`
+	}{StatementID: id, Body: req}
+	executeAdminIdempotentJSON(c, "admin.postpaid_statements.mark_paid", idempotencyPayload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		statement, err := h.postpaidBillingService.MarkPaid(ctx, id, operatorID, &service.MarkPostpaidStatementPaidInput{
+			Reference: strings.TrimSpace(req.Reference),
+			Notes:     strings.TrimSpace(req.Notes),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.AdminPostpaidStatementFromService(statement), nil
+	})
+}
+
+// Run issues statements for closed billing cycles and processes overdue statements immediately
+// POST /api/v1/admin/postpaid-statements/run
+func (h *PostpaidStatementHandler) Run(c *gin.Context) {
+	result, err := h.postpaidBillingService.RunOnce(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// GenerateForUser issues the statement for the user's last closed billing cycle
+// POST /api/v1/admin/users/:id/postpaid-statements
+func (h *PostpaidStatementHandler) GenerateForUser(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	statement, err := h.postpaidBillingService.GenerateForUser(c.Request.Context(), userID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminPostpaidStatementFromService(statement))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -42,6 +43,9 @@ type CreateUserRequest struct {
 	Concurrency           int     `json:"concurrency"`
 	AllowedGroups         []int64 `json:"allowed_groups"`
 	SoraStorageQuotaBytes int64   `json:"sora_storage_quota_bytes"`
+	BillingMode           string  `json:"billing_mode" binding:"omitempty,oneof=prepaid postpaid"`
+	CreditLimit           float64 `json:"credit_limit"`
+	BillingCycleDay       int     `json:"billing_cycle_day"`
 }
 
 // UpdateUserRequest represents admin update user request
@@ -60,6 +64,10 @@ type UpdateUserRequest struct {
 	GroupRates            map[int64]*float64 `json:"group_rates"`
 	GroupRequestQuotas    map[int64]*int64   `json:"group_request_quotas"`
 	SoraStorageQuotaBytes *int64             `json:"sora_storage_quota_bytes"`
+	// 后付费（月结）设置
+	BillingMode     *string  `json:"billing_mode" binding:"omitempty,oneof=prepaid postpaid"`
+	CreditLimit     *float64 `json:"credit_limit"`
+	BillingCycleDay *int     `json:"billing_cycle_day"`
 }
 
 // UpdateBalanceRequest represents balance update request
@@ -191,6 +199,9 @@ func (h *UserHandler) Create(c *gin.Context) {
 		Concurrency:           req.Concurrency,
 		AllowedGroups:         req.AllowedGroups,
 		SoraStorageQuotaBytes: req.SoraStorageQuotaBytes,
+		BillingMode:           req.BillingMode,
+		CreditLimit:           req.CreditLimit,
+		BillingCycleDay:       req.BillingCycleDay,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
@@ -228,6 +239,9 @@ func (h *UserHandler) Update(c *gin.Context) {
 		GroupRates:            req.GroupRates,
 		GroupRequestQuotas:    req.GroupRequestQuotas,
 		SoraStorageQuotaBytes: req.SoraStorageQuotaBytes,
+		BillingMode:           req.BillingMode,
+		CreditLimit:           req.CreditLimit,
+		BillingCycleDay:       req.BillingCycleDay,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
@@ -266,6 +280,9 @@ func (h *UserHandler) BatchUpdate(c *gin.Context) {
 		GroupRates:            req.Fields.GroupRates,
 		GroupRequestQuotas:    req.Fields.GroupRequestQuotas,
 		SoraStorageQuotaBytes: req.Fields.SoraStorageQuotaBytes,
+		BillingMode:           req.Fields.BillingMode,
+		CreditLimit:           req.Fields.CreditLimit,
+		BillingCycleDay:       req.Fields.BillingCycleDay,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
@@ -312,6 +329,11 @@ func (h *UserHandler) UpdateBalance(c *gin.Context) {
 		return
 	}
 
+	var operatorID int64
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok {
+		operatorID = subject.UserID
+	}
+
 	idempotencyPayload := struct {
 		UserID int64                `json:"user_id"`
 		Body   UpdateBalanceRequest `json:"body"`
@@ -320,7 +342,7 @@ func (h *UserHandler) UpdateBalance(c *gin.Context) {
 		Body:   req,
 	}
 	executeAdminIdempotentJSON(c, "admin.users.balance.update", idempotencyPayload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
-		user, execErr := h.adminService.UpdateUserBalance(ctx, userID, req.Balance, req.Operation, req.Notes)
+		user, execErr := h.adminService.UpdateUserBalance(ctx, userID, req.Balance, req.Operation, req.Notes, operatorID)
 		if execErr != nil {
 			return nil, execErr
 		}
@@ -372,7 +394,8 @@ func (h *UserHandler) GetUserUsage(c *gin.Context) {
 	response.Success(c, stats)
 }
 
-// GetBalanceHistory handles getting user's balance/concurrency change history
+// GetBalanceHistory handles getting user's balance/concurrency change history.
+// Balance changes are served from the balance ledger; concurrency and subscription changes from redeem codes.
 // GET /api/v1/admin/users/:id/balance-history
 // Query params:
 //   - type: filter by record type (balance, admin_balance, concurrency, admin_concurrency, subscription)
@@ -386,16 +409,18 @@ func (h *UserHandler) GetBalanceHistory(c *gin.Context) {
 	page, pageSize := response.ParsePagination(c)
 	codeType := c.Query("type")
 
-	codes, total, totalRecharged, err := h.adminService.GetUserBalanceHistory(c.Request.Context(), userID, page, pageSize, codeType)
+	records, total, totalRecharged, err := h.adminService.GetUserBalanceHistory(c.Request.Context(), userID, page, pageSize, codeType)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
 
 	// Convert to admin DTO (includes notes field for admin visibility)
-	out := make([]dto.AdminRedeemCode, 0, len(codes))
-	for i := range codes {
-		out = append(out, *dto.RedeemCodeFromServiceAdmin(&codes[i]))
+	out := make([]dto.AdminBalanceHistoryItem, 0, len(records))
+	for i := range records {
+		if item := dto.AdminBalanceHistoryItemFromService(&records[i]); item != nil {
+			out = append(out, *item)
+		}
 	}
 
 	// Custom response with total_recharged alongside pagination
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceLedgerHandler handles the user's balance statement
+type BalanceLedgerHandler struct {
+	balanceLedgerService *service.BalanceLedgerService
+}
+
+// NewBalanceLedgerHandler creates a new BalanceLedgerHandler
+func NewBalanceLedgerHandler(balanceLedgerService *service.BalanceLedgerService) *BalanceLedgerHandler {
+	return &BalanceLedgerHandler{balanceLedgerService: balanceLedgerService}
+}
+
+// GetStatement returns the current user's balance, pending usage charges,
+// per-type totals and paginated ledger entries.
+// GET /api/v1/user/balance-statement?entry_type=&start_date=&end_date=&timezone=
+func (h *BalanceLedgerHandler) GetStatement(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	filter, ok := parseBalanceLedgerFilter(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	statement, err := h.balanceLedgerService.GetStatement(c.Request.Context(), subject.UserID, pagination.PaginationParams{Page: page, PageSize: pageSize}, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BalanceStatementFromService(statement, page, pageSize))
+}
+
+// parseBalanceLedgerFilter parses entry_type, start_date and end_date (YYYY-MM-DD, end inclusive).
+func parseBalanceLedgerFilter(c *gin.Context) (service.BalanceLedgerFilter, bool) {
+	filter := service.BalanceLedgerFilter{EntryType: strings.TrimSpace(c.Query("entry_type"))}
+	userTZ := c.Query("timezone")
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return filter, false
+		}
+		filter.StartTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return filter, false
+		}
+		// 半开区间 [start, end)：移到次日零点
+		t = t.AddDate(0, 0, 1)
+		filter.EndTime = &t
+	}
+	return filter, true
+}
@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// BatchHandler 处理 OpenAI 兼容的 Files / Batches API
+type BatchHandler struct {
+	batchService *service.BatchService
+}
+
+// NewBatchHandler creates a new BatchHandler
+func NewBatchHandler(batchService *service.BatchService) *BatchHandler {
+	return &BatchHandler{batchService: batchService}
+}
+
+type createBatchRequest struct {
+	InputFileID      string            `json:"input_file_id" binding:"required"`
+	Endpoint         string            `json:"endpoint" binding:"required"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// UploadFile handles file upload
+// POST /v1/files
+func (h *BatchHandler) UploadFile(c *gin.Context) {
+	apiKey, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "file is required")
+		return
+	}
+	if fileHeader.Size > h.batchService.MaxFileSizeBytes() {
+		h.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", "file exceeds the maximum allowed size")
+		return
+	}
+	src, err := fileHeader.Open()
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "failed to read file")
+		return
+	}
+	defer func() { _ = src.Close() }()
+
+	file, err := h.batchService.UploadFile(c.Request.Context(), &service.BatchFileUploadInput{
+		UserID:   subject.UserID,
+		APIKeyID: apiKey.ID,
+		Filename: fileHeader.Filename,
+		Purpose:  c.PostForm("purpose"),
+		Size:     fileHeader.Size,
+		Body:     src,
+	})
+	if err != nil {
+		h.serviceError(c, "batch.upload_file_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, batchFileResponse(file))
+}
+
+// ListFiles lists the caller's files
+// GET /v1/files
+func (h *BatchHandler) ListFiles(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	files, hasMore, err := h.batchService.ListFiles(c.Request.Context(), subject.UserID, c.Query("purpose"), c.Query("after"), limit)
+	if err != nil {
+		h.serviceError(c, "batch.list_files_failed", err)
+		return
+	}
+	data := make([]gin.H, 0, len(files))
+	for i := range files {
+		data = append(data, batchFileResponse(&files[i]))
+	}
+	c.JSON(http.StatusOK, batchListResponse(data, hasMore))
+}
+
+// GetFile returns file metadata
+// GET /v1/files/:file_id
+func (h *BatchHandler) GetFile(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	file, err := h.batchService.GetFile(c.Request.Context(), subject.UserID, c.Param("file_id"))
+	if err != nil {
+		h.serviceError(c, "batch.get_file_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, batchFileResponse(file))
+}
+
+// GetFileContent streams file content
+// GET /v1/files/:file_id/content
+func (h *BatchHandler) GetFileContent(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	file, rc, err := h.batchService.OpenFileContent(c.Request.Context(), subject.UserID, c.Param("file_id"))
+	if err != nil {
+		h.serviceError(c, "batch.get_file_content_failed", err)
+		return
+	}
+	defer func() { _ = rc.Close() }()
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Length", strconv.FormatInt(file.Bytes, 10))
+	c.Header("Content-Disposition", `attachment; filename="`+file.Filename+`"`)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, rc); err != nil {
+		logger.L().Warn("batch.file_content_copy_failed", zap.String("file_id", file.ID), zap.Error(err))
+	}
+}
+
+// DeleteFile deletes a file
+// DELETE /v1/files/:file_id
+func (h *BatchHandler) DeleteFile(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	fileID := c.Param("file_id")
+	if err := h.batchService.DeleteFile(c.Request.Context(), subject.UserID, fileID); err != nil {
+		h.serviceError(c, "batch.delete_file_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": fileID, "object": "file", "deleted": true})
+}
+
+// CreateBatch creates a batch job
+// POST /v1/batches
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	apiKey, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	var req createBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "input_file_id and endpoint are required")
+		return
+	}
+	platform := ""
+	if apiKey.Group != nil {
+		platform = apiKey.Group.Platform
+	}
+	job, err := h.batchService.CreateBatch(c.Request.Context(), &service.BatchCreateInput{
+		UserID:           subject.UserID,
+		APIKeyID:         apiKey.ID,
+		GroupID:          apiKey.GroupID,
+		GroupPlatform:    platform,
+		ClientIP:         ip.GetClientIP(c),
+		InputFileID:      req.InputFileID,
+		Endpoint:         req.Endpoint,
+		CompletionWindow: req.CompletionWindow,
+		Metadata:         req.Metadata,
+	})
+	if err != nil {
+		h.serviceError(c, "batch.create_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, batchJobResponse(job))
+}
+
+// ListBatches lists the caller's batch jobs
+// GET /v1/batches
+func (h *BatchHandler) ListBatches(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	jobs, hasMore, err := h.batchService.ListBatches(c.Request.Context(), subject.UserID, c.Query("after"), limit)
+	if err != nil {
+		h.serviceError(c, "batch.list_failed", err)
+		return
+	}
+	data := make([]gin.H, 0, len(jobs))
+	for i := range jobs {
+		data = append(data, batchJobResponse(&jobs[i]))
+	}
+	c.JSON(http.StatusOK, batchListResponse(data, hasMore))
+}
+
+// GetBatch returns a batch job
+// GET /v1/batches/:batch_id
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	job, err := h.batchService.GetBatch(c.Request.Context(), subject.UserID, c.Param("batch_id"))
+	if err != nil {
+		h.serviceError(c, "batch.get_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, batchJobResponse(job))
+}
+
+// CancelBatch cancels a batch job
+// POST /v1/batches/:batch_id/cancel
+func (h *BatchHandler) CancelBatch(c *gin.Context) {
+	_, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	job, err := h.batchService.CancelBatch(c.Request.Context(), subject.UserID, c.Param("batch_id"))
+	if err != nil {
+		h.serviceError(c, "batch.cancel_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, batchJobResponse(job))
+}
+
+func (h *BatchHandler) authContext(c *gin.Context) (*service.APIKey, middleware2.AuthSubject, bool) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return nil, middleware2.AuthSubject{}, false
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return nil, middleware2.AuthSubject{}, false
+	}
+	return apiKey, subject, true
+}
+
+func (h *BatchHandler) serviceError(c *gin.Context, event string, err error) {
+	status := infraerrors.Code(err)
+	if status >= http.StatusInternalServerError {
+		logger.L().Error(event, zap.Error(err))
+		h.errorResponse(c, status, "api_error", "Internal server error")
+		return
+	}
+	errType := "invalid_request_error"
+	if status == http.StatusNotFound {
+		errType = "not_found_error"
+	}
+	h.errorResponse(c, status, errType, infraerrors.Message(err))
+}
+
+func (h *BatchHandler) errorResponse(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{
+		"error": gin.H{
+			"type":    errType,
+			"message": message,
+		},
+	})
+}
+
+func batchListResponse(data []gin.H, hasMore bool) gin.H {
+	resp := gin.H{
+		"object":   "list",
+		"data":     data,
+		"has_more": hasMore,
+		"first_id": nil,
+		"last_id":  nil,
+	}
+	if len(data) > 0 {
+		resp["first_id"] = data[0]["id"]
+		resp["last_id"] = data[len(data)-1]["id"]
+	}
+	return resp
+}
+
+func batchFileResponse(file *service.BatchFile) gin.H {
+	return gin.H{
+		"id":         file.ID,
+		"object":     "file",
+		"bytes":      file.Bytes,
+		"created_at": file.CreatedAt.Unix(),
+		"filename":   file.Filename,
+		"purpose":    file.Purpose,
+		"status":     "processed",
+	}
+}
+
+func batchJobResponse(job *service.BatchJob) gin.H {
+	var errs any
+	if len(job.Errors) > 0 {
+		errs = gin.H{"object": "list", "data": job.Errors}
+	}
+	var metadata any
+	if len(job.Metadata) > 0 {
+		metadata = job.Metadata
+	}
+	return gin.H{
+		"id":                job.ID,
+		"object":            "batch",
+		"endpoint":          job.Endpoint,
+		"errors":            errs,
+		"input_file_id":     job.InputFileID,
+		"completion_window": job.CompletionWindow,
+		"status":            job.Status,
+		"output_file_id":    job.OutputFileID,
+		"error_file_id":     job.ErrorFileID,
+		"created_at":        job.CreatedAt.Unix(),
+		"in_progress_at":    unixOrNil(job.InProgressAt),
+		"expires_at":        job.ExpiresAt.Unix(),
+		"finalizing_at":     unixOrNil(job.FinalizingAt),
+		"completed_at":      unixOrNil(job.CompletedAt),
+		"failed_at":         unixOrNil(job.FailedAt),
+		"expired_at":        unixOrNil(job.ExpiredAt),
+		"cancelling_at":     unixOrNil(job.CancellingAt),
+		"cancelled_at":      unixOrNil(job.CancelledAt),
+		"request_counts": gin.H{
+			"total":     job.TotalCount,
+			"completed": job.CompletedCount,
+			"failed":    job.FailedCount,
+		},
+		"metadata": metadata,
+	}
+}
+
+func unixOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Unix()
+}
@@ -0,0 +1,156 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// BalanceLedgerEntry 是用户账单中的一条余额流水。
+type BalanceLedgerEntry struct {
+	ID            int64      `json:"id"`
+	EntryType     string     `json:"entry_type"`
+	Amount        float64    `json:"amount"`
+	BalanceAfter  float64    `json:"balance_after"`
+	ReferenceType string     `json:"reference_type,omitempty"`
+	ReferenceID   string     `json:"reference_id,omitempty"`
+	ActorType     string     `json:"actor_type"`
+	RequestCount  int64      `json:"request_count,omitempty"`
+	PeriodStart   *time.Time `json:"period_start,omitempty"`
+	PeriodEnd     *time.Time `json:"period_end,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// AdminBalanceLedgerEntry 是管理员接口使用的余额流水（包含操作人与备注）。
+type AdminBalanceLedgerEntry struct {
+	BalanceLedgerEntry
+	UserID  int64  `json:"user_id"`
+	ActorID *int64 `json:"actor_id,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// AdminBalanceHistoryItem 是管理员余额/并发变动历史中的一条记录。
+// 沿用兑换码记录的字段（余额历史页面依赖）；余额变动来自账本流水，额外附带流水类型与账本余额。
+type AdminBalanceHistoryItem struct {
+	AdminRedeemCode
+	Source        string   `json:"source"`
+	EntryType     string   `json:"entry_type,omitempty"`
+	BalanceAfter  *float64 `json:"balance_after,omitempty"`
+	ReferenceType string   `json:"reference_type,omitempty"`
+}
+
+// BalanceStatement 是用户账单：余额、待汇总扣费、按类型汇总与分页流水。
+type BalanceStatement struct {
+	Balance      float64                          `json:"balance"`
+	PendingUsage *service.BalancePendingUsage     `json:"pending_usage"`
+	Totals       []service.BalanceLedgerTypeTotal `json:"totals"`
+
+	Items    []BalanceLedgerEntry `json:"items"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+	Pages    int                  `json:"pages"`
+}
+
+func BalanceLedgerEntryFromService(e *service.BalanceLedgerEntry) *BalanceLedgerEntry {
+	if e == nil {
+		return nil
+	}
+	return &BalanceLedgerEntry{
+		ID:            e.ID,
+		EntryType:     e.EntryType,
+		Amount:        e.Amount,
+		BalanceAfter:  e.BalanceAfter,
+		ReferenceType: e.ReferenceType,
+		ReferenceID:   e.ReferenceID,
+		ActorType:     e.ActorType,
+		RequestCount:  e.RequestCount,
+		PeriodStart:   e.PeriodStart,
+		PeriodEnd:     e.PeriodEnd,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func AdminBalanceLedgerEntryFromService(e *service.BalanceLedgerEntry) *AdminBalanceLedgerEntry {
+	if e == nil {
+		return nil
+	}
+	return &AdminBalanceLedgerEntry{
+		BalanceLedgerEntry: *BalanceLedgerEntryFromService(e),
+		UserID:             e.UserID,
+		ActorID:            e.ActorID,
+		Notes:              e.Notes,
+	}
+}
+
+func BalanceStatementFromService(s *service.BalanceStatement, page, pageSize int) *BalanceStatement {
+	if s == nil {
+		return nil
+	}
+	out := &BalanceStatement{
+		Balance:      s.Balance,
+		PendingUsage: s.PendingUsage,
+		Totals:       s.Totals,
+		Items:        make([]BalanceLedgerEntry, 0, len(s.Entries)),
+		Total:        s.Total,
+		Page:         page,
+		PageSize:     pageSize,
+		Pages:        1,
+	}
+	if out.Totals == nil {
+		out.Totals = []service.BalanceLedgerTypeTotal{}
+	}
+	for i := range s.Entries {
+		out.Items = append(out.Items, *BalanceLedgerEntryFromService(&s.Entries[i]))
+	}
+	if pageSize > 0 && s.Total > 0 {
+		out.Pages = int((s.Total + int64(pageSize) - 1) / int64(pageSize))
+	}
+	return out
+}
+
+func AdminBalanceHistoryItemFromService(r *service.BalanceHistoryRecord) *AdminBalanceHistoryItem {
+	if r == nil {
+		return nil
+	}
+	if r.RedeemCode != nil {
+		return &AdminBalanceHistoryItem{
+			AdminRedeemCode: *RedeemCodeFromServiceAdmin(r.RedeemCode),
+			Source:          service.BalanceHistorySourceRedeemCode,
+		}
+	}
+	e := r.LedgerEntry
+	if e == nil {
+		return nil
+	}
+	historyType := service.RedeemTypeBalance
+	if e.EntryType == service.BalanceLedgerEntryAdminAdjust {
+		historyType = service.AdjustmentTypeAdminBalance
+	}
+	userID := e.UserID
+	usedAt := e.CreatedAt
+	balanceAfter := e.BalanceAfter
+	item := &AdminBalanceHistoryItem{
+		AdminRedeemCode: AdminRedeemCode{
+			RedeemCode: RedeemCode{
+				ID:        e.ID,
+				Code:      e.ReferenceID,
+				Type:      historyType,
+				Value:     e.Amount,
+				Status:    service.StatusUsed,
+				UsedBy:    &userID,
+				UsedAt:    &usedAt,
+				CreatedAt: e.CreatedAt,
+			},
+			Notes: e.Notes,
+		},
+		Source:        service.BalanceHistorySourceLedger,
+		EntryType:     e.EntryType,
+		BalanceAfter:  &balanceAfter,
+		ReferenceType: e.ReferenceType,
+	}
+	if historyType == service.AdjustmentTypeAdminBalance && e.Notes != "" {
+		item.RedeemCode.Notes = &item.AdminRedeemCode.Notes
+	}
+	return item
+}
@@ -65,6 +65,9 @@ func UserFromServiceAdmin(u *service.User) *AdminUser {
 		GroupRequestQuotas:    u.GroupRequestQuotas,
 		SoraStorageQuotaBytes: u.SoraStorageQuotaBytes,
 		SoraStorageUsedBytes:  u.SoraStorageUsedBytes,
+		BillingMode:           u.BillingMode,
+		CreditLimit:           u.CreditLimit,
+		BillingCycleDay:       u.BillingCycleDay,
 	}
 }
 
@@ -142,6 +145,7 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		ModelRouting:            g.ModelRouting,
 		ModelRoutingEnabled:     g.ModelRoutingEnabled,
 		ModelAliases:            g.ModelAliases,
+		ModelPrices:             g.ModelPrices,
 		FallbackModel:           g.FallbackModel,
 		MCPXMLInject:            g.MCPXMLInject,
 		DefaultMappedModel:      g.DefaultMappedModel,
@@ -150,6 +154,9 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		ActiveAccountCount:      g.ActiveAccountCount,
 		RateLimitedAccountCount: g.RateLimitedAccountCount,
 		SortOrder:               g.SortOrder,
+		HedgeBudgetPercent:      g.HedgeBudgetPercent,
+		ScheduleStrategy:        g.ScheduleStrategy,
+		BillingReservationMode:  g.BillingReservationMode,
 	}
 	if len(g.AccountGroups) > 0 {
 		out.AccountGroups = make([]AccountGroup, 0, len(g.AccountGroups))
@@ -191,6 +198,9 @@ func groupFromServiceBase(g *service.Group) Group {
 		DefaultMappedModel:              g.DefaultMappedModel,
 		RequireOAuthOnly:                g.RequireOAuthOnly,
 		RequirePrivacySet:               g.RequirePrivacySet,
+		ResponseCacheEnabled:            g.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         g.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      g.ResponseCacheHitMultiplier,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
@@ -585,8 +595,12 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		ImageCount:            l.ImageCount,
 		ImageSize:             l.ImageSize,
 		MediaType:             l.MediaType,
+		AudioDurationSeconds:  l.AudioDurationSeconds,
+		AudioCharacters:       l.AudioCharacters,
 		UserAgent:             l.UserAgent,
+		TraceID:               l.TraceID,
 		CacheTTLOverridden:    l.CacheTTLOverridden,
+		ResponseCacheHit:      l.ResponseCacheHit,
 		CreatedAt:             l.CreatedAt,
 		User:                  UserFromServiceShallow(l.User),
 		APIKey:                APIKeyFromService(l.APIKey),
@@ -616,6 +630,7 @@ func UsageLogFromServiceAdmin(l *service.UsageLog) *AdminUsageLog {
 		UpstreamModel:         l.UpstreamModel,
 		AccountRateMultiplier: l.AccountRateMultiplier,
 		IPAddress:             l.IPAddress,
+		PriceSource:           l.PriceSource,
 		Account:               AccountSummaryFromService(l.Account),
 	}
 }
@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// PaymentOrder 是用户接口使用的支付订单 DTO。
+type PaymentOrder struct {
+	OrderNo      string  `json:"order_no"`
+	Kind         string  `json:"kind"`
+	PlanID       *int64  `json:"plan_id,omitempty"`
+	Provider     string  `json:"provider"`
+	Channel      string  `json:"channel,omitempty"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	CreditAmount float64 `json:"credit_amount"`
+	Status       string  `json:"status"`
+	// PaymentURL 仅待支付订单返回
+	PaymentURL string `json:"payment_url,omitempty"`
+
+	ExpiresAt  time.Time  `json:"expires_at"`
+	PaidAt     *time.Time `json:"paid_at,omitempty"`
+	RefundedAt *time.Time `json:"refunded_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AdminPaymentOrder 是管理员接口使用的支付订单 DTO（包含渠道流水号与发放记录）。
+type AdminPaymentOrder struct {
+	PaymentOrder
+	ID              int64     `json:"id"`
+	UserID          int64     `json:"user_id"`
+	ProviderTradeNo string    `json:"provider_trade_no"`
+	SubscriptionID  *int64    `json:"subscription_id,omitempty"`
+	GrantedDays     int       `json:"granted_days"`
+	RefundReason    string    `json:"refund_reason,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type PaymentPlanPrice struct {
+	PlanID    int64     `json:"plan_id"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func PaymentOrderFromService(o *service.PaymentOrder) *PaymentOrder {
+	if o == nil {
+		return nil
+	}
+	out := &PaymentOrder{
+		OrderNo:      o.OrderNo,
+		Kind:         o.Kind,
+		PlanID:       o.PlanID,
+		Provider:     o.Provider,
+		Channel:      o.Channel,
+		Amount:       o.Amount,
+		Currency:     o.Currency,
+		CreditAmount: o.CreditAmount,
+		Status:       o.Status,
+		ExpiresAt:    o.ExpiresAt,
+		PaidAt:       o.PaidAt,
+		RefundedAt:   o.RefundedAt,
+		CreatedAt:    o.CreatedAt,
+	}
+	if o.Status == service.PaymentOrderStatusPending {
+		out.PaymentURL = o.PaymentURL
+	}
+	return out
+}
+
+func AdminPaymentOrderFromService(o *service.PaymentOrder) *AdminPaymentOrder {
+	if o == nil {
+		return nil
+	}
+	return &AdminPaymentOrder{
+		PaymentOrder:    *PaymentOrderFromService(o),
+		ID:              o.ID,
+		UserID:          o.UserID,
+		ProviderTradeNo: o.ProviderTradeNo,
+		SubscriptionID:  o.SubscriptionID,
+		GrantedDays:     o.GrantedDays,
+		RefundReason:    o.RefundReason,
+		UpdatedAt:       o.UpdatedAt,
+	}
+}
+
+func PaymentPlanPriceFromService(p *service.PaymentPlanPrice) *PaymentPlanPrice {
+	if p == nil {
+		return nil
+	}
+	return &PaymentPlanPrice{
+		PlanID:    p.PlanID,
+		Price:     p.Price,
+		Currency:  p.Currency,
+		Enabled:   p.Enabled,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
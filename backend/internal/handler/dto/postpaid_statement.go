@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// PostpaidStatement 是后付费用户的月度账单；列表接口不返回明细（LineItems 为空）。
+type PostpaidStatement struct {
+	ID           int64                           `json:"id"`
+	StatementNo  string                          `json:"statement_no"`
+	PeriodStart  time.Time                       `json:"period_start"`
+	PeriodEnd    time.Time                       `json:"period_end"`
+	Status       string                          `json:"status"`
+	Subtotal     float64                         `json:"subtotal"`
+	TaxRate      float64                         `json:"tax_rate"`
+	TaxAmount    float64                         `json:"tax_amount"`
+	Total        float64                         `json:"total"`
+	RequestCount int64                           `json:"request_count"`
+	LineItems    []service.PostpaidStatementLine `json:"line_items,omitempty"`
+	DueAt        time.Time                       `json:"due_at"`
+	PaidAt       *time.Time                      `json:"paid_at,omitempty"`
+	CreatedAt    time.Time                       `json:"created_at"`
+}
+
+// AdminPostpaidStatement 是管理员接口使用的账单（包含用户、付款人与付款备注）。
+type AdminPostpaidStatement struct {
+	PostpaidStatement
+	UserID           int64     `json:"user_id"`
+	UserEmail        string    `json:"user_email"`
+	PaidBy           *int64    `json:"paid_by,omitempty"`
+	PaymentReference string    `json:"payment_reference,omitempty"`
+	Notes            string    `json:"notes,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func PostpaidStatementFromService(s *service.PostpaidStatement) *PostpaidStatement {
+	if s == nil {
+		return nil
+	}
+	return &PostpaidStatement{
+		ID:           s.ID,
+		StatementNo:  s.StatementNo,
+		PeriodStart:  s.PeriodStart,
+		PeriodEnd:    s.PeriodEnd,
+		Status:       s.Status,
+		Subtotal:     s.Subtotal,
+		TaxRate:      s.TaxRate,
+		TaxAmount:    s.TaxAmount,
+		Total:        s.Total,
+		RequestCount: s.RequestCount,
+		LineItems:    s.LineItems,
+		DueAt:        s.DueAt,
+		PaidAt:       s.PaidAt,
+		CreatedAt:    s.CreatedAt,
+	}
+}
+
+func AdminPostpaidStatementFromService(s *service.PostpaidStatement) *AdminPostpaidStatement {
+	if s == nil {
+		return nil
+	}
+	return &AdminPostpaidStatement{
+		PostpaidStatement: *PostpaidStatementFromService(s),
+		UserID:            s.UserID,
+		UserEmail:         s.UserEmail,
+		PaidBy:            s.PaidBy,
+		PaymentReference:  s.PaymentReference,
+		Notes:             s.Notes,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
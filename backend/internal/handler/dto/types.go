@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
 
 type User struct {
 	ID            int64     `json:"id"`
@@ -30,6 +34,9 @@ type AdminUser struct {
 	GroupRequestQuotas    map[int64]int64   `json:"group_request_quotas,omitempty"`
 	SoraStorageQuotaBytes int64             `json:"sora_storage_quota_bytes"`
 	SoraStorageUsedBytes  int64             `json:"sora_storage_used_bytes"`
+	BillingMode           string            `json:"billing_mode"`
+	CreditLimit           float64           `json:"credit_limit"`
+	BillingCycleDay       int               `json:"billing_cycle_day"`
 }
 
 type APIKey struct {
@@ -112,6 +119,11 @@ type Group struct {
 	RequireOAuthOnly  bool `json:"require_oauth_only"`
 	RequirePrivacySet bool `json:"require_privacy_set"`
 
+	// 精确匹配响应缓存配置
+	ResponseCacheEnabled       bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds    int     `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier float64 `json:"response_cache_hit_multiplier"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -129,6 +141,9 @@ type AdminGroup struct {
 	ModelAliases  map[string]string `json:"model_aliases"`
 	FallbackModel string            `json:"fallback_model"`
 
+	// 分组自定义模型价格（USD per MTok，支持通配符），优先于全局价格表
+	ModelPrices map[string]service.GroupModelPrice `json:"model_prices"`
+
 	// MCP XML 协议注入（仅 antigravity 平台使用）
 	MCPXMLInject bool `json:"mcp_xml_inject"`
 
@@ -144,6 +159,15 @@ type AdminGroup struct {
 
 	// 分组排序
 	SortOrder int `json:"sort_order"`
+
+	// 对冲请求预算（百分比，0 表示关闭）
+	HedgeBudgetPercent float64 `json:"hedge_budget_percent"`
+
+	// 账号调度策略（空表示网关默认策略）
+	ScheduleStrategy string `json:"schedule_strategy"`
+
+	// 计费预留模式：optimistic 请求后扣费，strict 请求前预留预估最大费用
+	BillingReservationMode string `json:"billing_reservation_mode"`
 }
 
 type Account struct {
@@ -396,12 +420,22 @@ type UsageLog struct {
 	ImageSize  *string `json:"image_size"`
 	MediaType  *string `json:"media_type"`
 
+	// 音频字段
+	AudioDurationSeconds float64 `json:"audio_duration_seconds"`
+	AudioCharacters      int     `json:"audio_characters"`
+
 	// User-Agent
 	UserAgent *string `json:"user_agent"`
 
+	// TraceID OpenTelemetry trace id，与响应头 X-Trace-Id 一致
+	TraceID *string `json:"trace_id,omitempty"`
+
 	// Cache TTL Override 标记
 	CacheTTLOverridden bool `json:"cache_ttl_overridden"`
 
+	// ResponseCacheHit 是否为响应缓存命中
+	ResponseCacheHit bool `json:"response_cache_hit"`
+
 	CreatedAt time.Time `json:"created_at"`
 
 	User         *User             `json:"user,omitempty"`
@@ -424,6 +458,9 @@ type AdminUsageLog struct {
 	// IPAddress 用户请求 IP（仅管理员可见）
 	IPAddress *string `json:"ip_address,omitempty"`
 
+	// PriceSource 计费价格来源：group / litellm / fallback（仅管理员可见）
+	PriceSource *string `json:"price_source,omitempty"`
+
 	// Account 最小账号信息（避免泄露敏感字段）
 	Account *AccountSummary `json:"account,omitempty"`
 }
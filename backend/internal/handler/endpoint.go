@@ -18,6 +18,12 @@ const (
 	EndpointMessages        = "/v1/messages"
 	EndpointChatCompletions = "/v1/chat/completions"
 	EndpointResponses       = "/v1/responses"
+	EndpointEmbeddings      = "/v1/embeddings"
+	EndpointImagesGenerate  = "/v1/images/generations"
+	EndpointImagesEdit      = "/v1/images/edits"
+	EndpointAudioTranscribe = "/v1/audio/transcriptions"
+	EndpointAudioTranslate  = "/v1/audio/translations"
+	EndpointAudioSpeech     = "/v1/audio/speech"
 	EndpointGeminiModels    = "/v1beta/models"
 )
 
@@ -46,6 +52,18 @@ func NormalizeInboundEndpoint(path string) string {
 		return EndpointMessages
 	case strings.Contains(path, EndpointResponses):
 		return EndpointResponses
+	case strings.Contains(path, EndpointEmbeddings):
+		return EndpointEmbeddings
+	case strings.Contains(path, EndpointImagesGenerate):
+		return EndpointImagesGenerate
+	case strings.Contains(path, EndpointImagesEdit):
+		return EndpointImagesEdit
+	case strings.Contains(path, EndpointAudioTranscribe):
+		return EndpointAudioTranscribe
+	case strings.Contains(path, EndpointAudioTranslate):
+		return EndpointAudioTranslate
+	case strings.Contains(path, EndpointAudioSpeech):
+		return EndpointAudioSpeech
 	case strings.Contains(path, EndpointGeminiModels):
 		return EndpointGeminiModels
 	default:
@@ -57,19 +75,26 @@ func NormalizeInboundEndpoint(path string) string {
 // account platform and the normalized inbound endpoint.
 //
 // Platform-specific rules:
-//   - OpenAI always forwards to /v1/responses (with optional subpath
-//     such as /v1/responses/compact preserved from the raw URL).
+//   - OpenAI forwards to /v1/responses (with optional subpath
+//     such as /v1/responses/compact preserved from the raw URL), except
+//     /v1/embeddings, /v1/images/* and /v1/audio/* which are forwarded as-is.
 //   - Anthropic  → /v1/messages
 //   - Gemini     → /v1beta/models
 //   - Sora       → /v1/chat/completions
 //   - Antigravity routes may target either Claude or Gemini, so the
-//     inbound endpoint is used to distinguish.
+//     inbound endpoint is used to distinguish (images are served by
+//     Gemini image models).
 func DeriveUpstreamEndpoint(inbound, rawRequestPath, platform string) string {
 	inbound = strings.TrimSpace(inbound)
 
 	switch platform {
 	case service.PlatformOpenAI:
-		// OpenAI forwards everything to the Responses API.
+		switch inbound {
+		case EndpointEmbeddings, EndpointImagesGenerate, EndpointImagesEdit,
+			EndpointAudioTranscribe, EndpointAudioTranslate, EndpointAudioSpeech:
+			return inbound
+		}
+		// OpenAI forwards everything else to the Responses API.
 		// Preserve subresource suffix (e.g. /v1/responses/compact).
 		if suffix := responsesSubpathSuffix(rawRequestPath); suffix != "" {
 			return EndpointResponses + suffix
@@ -87,7 +112,8 @@ func DeriveUpstreamEndpoint(inbound, rawRequestPath, platform string) string {
 
 	case service.PlatformAntigravity:
 		// Antigravity accounts serve both Claude and Gemini.
-		if inbound == EndpointGeminiModels {
+		switch inbound {
+		case EndpointGeminiModels, EndpointImagesGenerate, EndpointImagesEdit:
 			return EndpointGeminiModels
 		}
 		return EndpointMessages
@@ -38,8 +38,21 @@ func TestNormalizeInboundEndpoint(t *testing.T) {
 		{"/v1beta/models/*modelAction", EndpointGeminiModels},
 		{"/v1/responses/*subpath", EndpointResponses},
 
+		// Embeddings (with and without /v1 prefix).
+		{"/v1/embeddings", EndpointEmbeddings},
+		{"/openai/v1/embeddings", EndpointEmbeddings},
+
+		// Images.
+		{"/v1/images/generations", EndpointImagesGenerate},
+		{"/v1/images/edits", EndpointImagesEdit},
+
+		// Audio.
+		{"/v1/audio/transcriptions", EndpointAudioTranscribe},
+		{"/v1/audio/translations", EndpointAudioTranslate},
+		{"/v1/audio/speech", EndpointAudioSpeech},
+
 		// Unknown path is returned as-is.
-		{"/v1/embeddings", "/v1/embeddings"},
+		{"/v1/models", "/v1/models"},
 		{"", ""},
 		{"  /v1/messages  ", EndpointMessages},
 	}
@@ -71,16 +84,21 @@ func TestDeriveUpstreamEndpoint(t *testing.T) {
 		// Sora.
 		{"sora completions", EndpointChatCompletions, "/sora/v1/chat/completions", service.PlatformSora, EndpointChatCompletions},
 
-		// OpenAI — always /v1/responses.
+		// OpenAI — /v1/responses except embeddings.
 		{"openai responses root", EndpointResponses, "/v1/responses", service.PlatformOpenAI, EndpointResponses},
 		{"openai responses compact", EndpointResponses, "/openai/v1/responses/compact", service.PlatformOpenAI, "/v1/responses/compact"},
 		{"openai responses nested", EndpointResponses, "/openai/v1/responses/compact/detail", service.PlatformOpenAI, "/v1/responses/compact/detail"},
 		{"openai from messages", EndpointMessages, "/v1/messages", service.PlatformOpenAI, EndpointResponses},
 		{"openai from completions", EndpointChatCompletions, "/v1/chat/completions", service.PlatformOpenAI, EndpointResponses},
+		{"openai embeddings", EndpointEmbeddings, "/v1/embeddings", service.PlatformOpenAI, EndpointEmbeddings},
+		{"openai images", EndpointImagesGenerate, "/v1/images/generations", service.PlatformOpenAI, EndpointImagesGenerate},
+		{"openai audio speech", EndpointAudioSpeech, "/v1/audio/speech", service.PlatformOpenAI, EndpointAudioSpeech},
+		{"gemini images", EndpointImagesEdit, "/v1/images/edits", service.PlatformGemini, EndpointGeminiModels},
 
 		// Antigravity — uses inbound to pick Claude vs Gemini upstream.
 		{"antigravity claude", EndpointMessages, "/antigravity/v1/messages", service.PlatformAntigravity, EndpointMessages},
 		{"antigravity gemini", EndpointGeminiModels, "/antigravity/v1beta/models", service.PlatformAntigravity, EndpointGeminiModels},
+		{"antigravity images", EndpointImagesGenerate, "/v1/images/generations", service.PlatformAntigravity, EndpointGeminiModels},
 
 		// Unknown platform — passthrough.
 		{"unknown platform", "/v1/embeddings", "/v1/embeddings", "unknown", "/v1/embeddings"},
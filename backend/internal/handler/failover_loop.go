@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -16,6 +18,11 @@ type TempUnscheduler interface {
 	TempUnscheduleRetryableError(ctx context.Context, accountID int64, failoverErr *service.UpstreamFailoverError)
 }
 
+// accountSwitchRecorder 可选接口：记录账号切换次数（GatewayService 实现，用于调度器指标）。
+type accountSwitchRecorder interface {
+	RecordAccountSwitch()
+}
+
 // FailoverAction 表示 failover 错误处理后的下一步动作
 type FailoverAction int
 
@@ -28,6 +35,19 @@ const (
 	FailoverCanceled
 )
 
+func (a FailoverAction) String() string {
+	switch a {
+	case FailoverContinue:
+		return "continue"
+	case FailoverExhausted:
+		return "exhausted"
+	case FailoverCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
 const (
 	// maxSameAccountRetries 同账号重试次数上限（针对 RetryableOnSameAccount 错误）
 	maxSameAccountRetries = 3
@@ -68,7 +88,21 @@ func (s *FailoverState) HandleFailoverError(
 	accountID int64,
 	platform string,
 	failoverErr *service.UpstreamFailoverError,
-) FailoverAction {
+) (action FailoverAction) {
+	ctx, span := tracing.Start(ctx, "gateway.failover",
+		attribute.Int64("account.id", accountID),
+		attribute.String("account.platform", platform),
+		attribute.Int("upstream.status_code", failoverErr.StatusCode),
+	)
+	defer func() {
+		span.SetAttributes(
+			attribute.String("failover.action", action.String()),
+			attribute.Int("failover.switch_count", s.SwitchCount),
+			attribute.Int("failover.same_account_retry_count", s.SameAccountRetryCount[accountID]),
+		)
+		span.End()
+	}()
+
 	s.LastFailoverErr = failoverErr
 
 	// 缓存计费判断
@@ -113,6 +147,10 @@ func (s *FailoverState) HandleFailoverError(
 
 	// 递增切换计数
 	s.SwitchCount++
+	if recorder, ok := gatewayService.(accountSwitchRecorder); ok {
+		recorder.RecordAccountSwitch()
+	}
+	service.RecordGatewayFailoverMetrics(platform, failoverErr.StatusCode)
 	appendRequestTraceEvent(ctx, "account_failover", "account", map[string]any{
 		"account_id":   accountID,
 		"status_code":  failoverErr.StatusCode,
@@ -49,6 +49,9 @@ type GatewayHandler struct {
 	gatewayService            *service.GatewayService
 	geminiCompatService       *service.GeminiMessagesCompatService
 	antigravityGatewayService *service.AntigravityGatewayService
+	imageGenerationService    *service.ImageGenerationService
+	responseCacheService      *service.ResponseCacheService
+	hedgeService              *service.RequestHedgeService
 	userService               *service.UserService
 	billingCacheService       *service.BillingCacheService
 	usageService              *service.UsageService
@@ -68,6 +71,9 @@ func NewGatewayHandler(
 	gatewayService *service.GatewayService,
 	geminiCompatService *service.GeminiMessagesCompatService,
 	antigravityGatewayService *service.AntigravityGatewayService,
+	imageGenerationService *service.ImageGenerationService,
+	responseCacheService *service.ResponseCacheService,
+	hedgeService *service.RequestHedgeService,
 	userService *service.UserService,
 	concurrencyService *service.ConcurrencyService,
 	billingCacheService *service.BillingCacheService,
@@ -102,6 +108,9 @@ func NewGatewayHandler(
 		gatewayService:            gatewayService,
 		geminiCompatService:       geminiCompatService,
 		antigravityGatewayService: antigravityGatewayService,
+		imageGenerationService:    imageGenerationService,
+		responseCacheService:      responseCacheService,
+		hedgeService:              hedgeService,
 		userService:               userService,
 		billingCacheService:       billingCacheService,
 		usageService:              usageService,
@@ -276,6 +285,26 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 精确匹配响应缓存：命中时直接回放，不占用上游账号
+	responseCache := beginResponseCache(c, h.responseCacheService, apiKey.Group, body, reqStream)
+	if entry := responseCache.lookup(c); entry != nil {
+		h.serveResponseCacheHit(c, responseCache, entry, apiKey, subscription, body)
+		return
+	}
+
+	// 严格预留模式：转发前按预估最大费用预留余额/额度，防止并发请求透支
+	reservation, err := h.gatewayService.ReserveRequestCost(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.billing_reservation_rejected", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.handleStreamingAwareError(c, status, code, message, streamStarted)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
 	// 计算粘性会话hash
 	parsedReq.SessionContext = &service.SessionContext{
 		ClientIP:  ip.GetClientIP(c),
@@ -311,6 +340,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	}
 	// 判断是否真的绑定了粘性会话：有 sessionKey 且已经绑定到某个账号
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
+	// 对冲计划：分组配置了对冲预算时，慢请求可并发发往另一账号
+	hedgePlan := h.hedgeService.Plan(apiKey.Group, reqModel, reqStream)
 
 	if platform == service.PlatformGemini {
 		fs := NewFailoverState(h.maxAccountSwitchesGemini, hasBoundSession)
@@ -448,15 +479,27 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			// 记录 Forward 前已写入字节数，Forward 后若增加则说明 SSE 内容已发，禁止 failover
 			writerSizeBeforeForward := c.Writer.Size()
-			if account.Platform == service.PlatformAntigravity {
-				result, err = h.antigravityGatewayService.ForwardGemini(requestCtx, c, account, reqModel, "generateContent", reqStream, body, hasBoundSession)
-			} else {
-				result, err = h.geminiCompatService.Forward(requestCtx, c, account, body)
-			}
+			primaryAccount := account
+			result, account, err = h.forwardWithHedge(requestCtx, c, account, &hedgeRequest{
+				plan:       hedgePlan,
+				groupID:    apiKey.GroupID,
+				sessionKey: sessionKey,
+				excluded:   fs.FailedAccountIDs,
+				selectAccount: func(ctx context.Context, excluded map[int64]struct{}) (*service.AccountSelectionResult, error) {
+					return h.gatewayService.SelectAccountWithLoadAwareness(ctx, apiKey.GroupID, "", reqModel, excluded, "")
+				},
+				forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+					if acc.Platform == service.PlatformAntigravity {
+						return h.antigravityGatewayService.ForwardGemini(ctx, fc, acc, reqModel, "generateContent", reqStream, body, hasBoundSession && acc.ID == primaryAccount.ID)
+					}
+					return h.geminiCompatService.Forward(ctx, fc, acc, body)
+				},
+			})
 			if accountReleaseFunc != nil {
 				accountReleaseFunc()
 			}
 			if err != nil {
+				h.reportAccountScheduleResult(account, nil, err)
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
 					// 流式内容已写入客户端，无法撤销，禁止 failover 以防止流拼接腐化
@@ -506,6 +549,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				}
 			}
 
+			h.reportAccountScheduleResult(account, result, nil)
+			responseCache.store(c, newGatewayResponseCacheEntry(result, account.ID))
+
 			// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 			userAgent := c.GetHeader("User-Agent")
 			clientIP := ip.GetClientIP(c)
@@ -518,7 +564,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 
 			// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-			h.submitUsageRecordTask(func(ctx context.Context) {
+			submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 					Result:             result,
 					APIKey:             apiKey,
@@ -745,11 +791,33 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			// 记录 Forward 前已写入字节数，Forward 后若增加则说明 SSE 内容已发，禁止 failover
 			writerSizeBeforeForward := c.Writer.Size()
-			if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
-				result, err = h.antigravityGatewayService.Forward(requestCtx, c, account, body, hasBoundSession)
-			} else {
-				result, err = h.gatewayService.Forward(requestCtx, c, account, parsedReq)
+			// 兜底分组重试不再对冲，避免额外消耗兜底分组配额
+			currentHedgePlan := hedgePlan
+			if fallbackUsed {
+				currentHedgePlan = nil
 			}
+			primaryAccount := account
+			result, account, err = h.forwardWithHedge(requestCtx, c, account, &hedgeRequest{
+				plan:       currentHedgePlan,
+				groupID:    currentAPIKey.GroupID,
+				sessionKey: sessionKey,
+				excluded:   fs.FailedAccountIDs,
+				selectAccount: func(ctx context.Context, excluded map[int64]struct{}) (*service.AccountSelectionResult, error) {
+					return h.gatewayService.SelectAccountWithLoadAwareness(ctx, currentAPIKey.GroupID, "", reqModel, excluded, parsedReq.MetadataUserID)
+				},
+				forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+					if acc.Platform == service.PlatformAntigravity && acc.Type != service.AccountTypeAPIKey {
+						return h.antigravityGatewayService.Forward(ctx, fc, acc, body, hasBoundSession && acc.ID == primaryAccount.ID)
+					}
+					if acc.ID == primaryAccount.ID {
+						return h.gatewayService.Forward(ctx, fc, acc, parsedReq)
+					}
+					// 对冲请求不参与用户消息串行队列
+					hedgeReq := *parsedReq
+					hedgeReq.OnUpstreamAccepted = nil
+					return h.gatewayService.Forward(ctx, fc, acc, &hedgeReq)
+				},
+			})
 
 			// 兜底释放串行锁（正常情况已通过回调提前释放）
 			if queueRelease != nil {
@@ -812,6 +880,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					_ = h.antigravityGatewayService.WriteMappedClaudeError(c, account, promptTooLongErr.StatusCode, promptTooLongErr.RequestID, promptTooLongErr.Body)
 					return
 				}
+				h.reportAccountScheduleResult(account, nil, err)
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
 					// 流式内容已写入客户端，无法撤销，禁止 failover 以防止流拼接腐化
@@ -861,6 +930,13 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				}
 			}
 
+			h.reportAccountScheduleResult(account, result, nil)
+
+			// 兜底分组重试的响应不写入原分组缓存
+			if !fallbackUsed {
+				responseCache.store(c, newGatewayResponseCacheEntry(result, account.ID))
+			}
+
 			// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 			userAgent := c.GetHeader("User-Agent")
 			clientIP := ip.GetClientIP(c)
@@ -873,7 +949,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 
 			// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-			h.submitUsageRecordTask(func(ctx context.Context) {
+			submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 					Result:             result,
 					APIKey:             currentAPIKey,
@@ -1112,7 +1188,7 @@ func (h *GatewayHandler) buildUsageData(ctx context.Context, apiKeyID int64) gin
 func (h *GatewayHandler) usageQuotaLimited(c *gin.Context, ctx context.Context, apiKey *service.APIKey, usageData gin.H, modelStats any) {
 	resp := gin.H{
 		"mode":    "quota_limited",
-		"isValid": apiKey.Status == service.StatusAPIKeyActive || apiKey.Status == service.StatusAPIKeyQuotaExhausted || apiKey.Status == service.StatusAPIKeyExpired,
+		"isValid": apiKey.Status == service.StatusAPIKeyActive || apiKey.Status == service.StatusAPIKeyQuotaExhausted || apiKey.Status == service.StatusAPIKeyExpired || apiKey.Status == service.StatusAPIKeyOverdue,
 		"status":  apiKey.Status,
 	}
 
@@ -1364,6 +1440,18 @@ func (h *GatewayHandler) tryHandleGLM1302Failover(c *gin.Context, statusCode int
 	return true
 }
 
+// reportAccountScheduleResult 上报账号请求结果，供调度器更新错误率与首 Token 耗时 EWMA
+func (h *GatewayHandler) reportAccountScheduleResult(account *service.Account, result *service.ForwardResult, err error) {
+	if account == nil {
+		return
+	}
+	var firstTokenMs *int
+	if err == nil && result != nil {
+		firstTokenMs = result.FirstTokenMs
+	}
+	h.gatewayService.ReportAccountScheduleResult(account.ID, err == nil, firstTokenMs)
+}
+
 func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, platform string, streamStarted bool) {
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
@@ -1600,6 +1688,12 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 		return
 	}
 
+	// OpenAI 分组没有 count_tokens 上游，直接使用本地估算
+	if apiKey.Group != nil && apiKey.Group.Platform == service.PlatformOpenAI {
+		h.gatewayService.RespondCountTokensEstimate(c, parsedReq)
+		return
+	}
+
 	// 计算粘性会话 hash
 	parsedReq.SessionContext = &service.SessionContext{
 		ClientIP:  ip.GetClientIP(c),
@@ -1608,11 +1702,11 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
-	// 选择支持该模型的账号
+	// 选择支持该模型的账号；无可用账号时回退本地估算，避免客户端上下文管理中断
 	account, err := h.gatewayService.SelectAccountForModel(c.Request.Context(), apiKey.GroupID, sessionHash, parsedReq.Model)
 	if err != nil {
-		reqLog.Warn("gateway.count_tokens_select_account_failed", zap.Error(err))
-		h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "Service temporarily unavailable")
+		reqLog.Warn("gateway.count_tokens_select_account_failed_local_estimate", zap.Error(err))
+		h.gatewayService.RespondCountTokensEstimate(c, parsedReq)
 		return
 	}
 	setOpsSelectedAccount(c, account.ID, account.Platform)
@@ -1859,6 +1953,10 @@ func billingErrorDetails(err error) (status int, code, message string) {
 		msg := pkgerrors.Message(err)
 		return http.StatusTooManyRequests, "rate_limit_exceeded", msg
 	}
+	if errors.Is(err, service.ErrReservationAPIKeyRateLimit) {
+		msg := pkgerrors.Message(err)
+		return http.StatusTooManyRequests, "rate_limit_exceeded", msg
+	}
 	msg := pkgerrors.Message(err)
 	if msg == "" {
 		logger.L().With(
@@ -1894,13 +1992,12 @@ func (h *GatewayHandler) maybeLogCompatibilityFallbackMetrics(reqLog *zap.Logger
 	)
 }
 
-func (h *GatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) {
+func (h *GatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) service.UsageRecordSubmitMode {
 	if task == nil {
-		return
+		return service.UsageRecordSubmitModeDropped
 	}
 	if h.usageRecordWorkerPool != nil {
-		h.usageRecordWorkerPool.Submit(task)
-		return
+		return h.usageRecordWorkerPool.Submit(task)
 	}
 	// 回退路径：worker 池未注入时同步执行，避免退回到无界 goroutine 模式。
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -1914,6 +2011,7 @@ func (h *GatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) {
 		}
 	}()
 	task(ctx)
+	return service.UsageRecordSubmitModeSync
 }
 
 // getUserMsgQueueMode 获取当前请求的 UMQ 模式
@@ -16,10 +16,12 @@ import (
 	"go.uber.org/zap"
 )
 
-// ChatCompletions handles OpenAI Chat Completions API endpoint for Anthropic platform groups.
+// ChatCompletions handles OpenAI Chat Completions API endpoint for Anthropic, Gemini
+// and Antigravity platform groups.
 // POST /v1/chat/completions
-// This converts Chat Completions requests to Anthropic format (via Responses format chain),
-// forwards to Anthropic upstream, and converts responses back to Chat Completions format.
+// This converts Chat Completions requests to the upstream format (Anthropic via the
+// Responses format chain, or Gemini generateContent for Gemini/Antigravity accounts),
+// forwards upstream, and converts responses back to Chat Completions format.
 func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 	streamStarted := false
 
@@ -155,6 +157,19 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	// 严格预留模式：转发前按预估最大费用预留余额/额度，防止并发请求透支
+	reservation, err := h.gatewayService.ReserveRequestCost(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.cc.billing_reservation_rejected", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.chatCompletionsErrorResponse(c, status, code, message)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
 	// Parse request for session hash
 	parsedReq, _ := service.ParseGatewayRequest(body, "chat_completions")
 	if parsedReq == nil {
@@ -223,13 +238,22 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 
 		// 5. Forward request
 		writerSizeBeforeForward := c.Writer.Size()
-		result, err := h.gatewayService.ForwardAsChatCompletions(c.Request.Context(), c, account, body, parsedReq)
+		var result *service.ForwardResult
+		switch account.Platform {
+		case service.PlatformGemini:
+			result, err = h.geminiCompatService.ForwardAsChatCompletions(c.Request.Context(), c, account, body)
+		case service.PlatformAntigravity:
+			result, err = h.antigravityGatewayService.ForwardAsChatCompletions(c.Request.Context(), c, account, body, false)
+		default:
+			result, err = h.gatewayService.ForwardAsChatCompletions(c.Request.Context(), c, account, body, parsedReq)
+		}
 
 		if accountReleaseFunc != nil {
 			accountReleaseFunc()
 		}
 
 		if err != nil {
+			h.reportAccountScheduleResult(account, nil, err)
 			var failoverErr *service.UpstreamFailoverError
 			if errors.As(err, &failoverErr) {
 				if c.Writer.Size() != writerSizeBeforeForward {
@@ -255,6 +279,8 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 			return
 		}
 
+		h.reportAccountScheduleResult(account, result, nil)
+
 		// 6. Record usage
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
@@ -262,7 +288,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
 
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 				Result:             result,
 				APIKey:             apiKey,
@@ -16,10 +16,12 @@ import (
 	"go.uber.org/zap"
 )
 
-// Responses handles OpenAI Responses API endpoint for Anthropic platform groups.
+// Responses handles OpenAI Responses API endpoint for Anthropic, Gemini and
+// Antigravity platform groups.
 // POST /v1/responses
-// This converts Responses API requests to Anthropic format, forwards to Anthropic
-// upstream, and converts responses back to Responses format.
+// This converts Responses API requests to the upstream format (Anthropic Messages,
+// or Gemini generateContent for Gemini/Antigravity accounts), forwards upstream,
+// and converts responses back to Responses format.
 func (h *GatewayHandler) Responses(c *gin.Context) {
 	streamStarted := false
 
@@ -160,6 +162,19 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	// 严格预留模式：转发前按预估最大费用预留余额/额度，防止并发请求透支
+	reservation, err := h.gatewayService.ReserveRequestCost(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("gateway.responses.billing_reservation_rejected", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.responsesErrorResponse(c, status, code, message)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
 	// Parse request for session hash
 	parsedReq, _ := service.ParseGatewayRequest(body, "responses")
 	if parsedReq == nil {
@@ -228,13 +243,22 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 		// 5. Forward request
 		writerSizeBeforeForward := c.Writer.Size()
-		result, err := h.gatewayService.ForwardAsResponses(c.Request.Context(), c, account, body, parsedReq)
+		var result *service.ForwardResult
+		switch account.Platform {
+		case service.PlatformGemini:
+			result, err = h.geminiCompatService.ForwardAsResponses(c.Request.Context(), c, account, body)
+		case service.PlatformAntigravity:
+			result, err = h.antigravityGatewayService.ForwardAsResponses(c.Request.Context(), c, account, body, false)
+		default:
+			result, err = h.gatewayService.ForwardAsResponses(c.Request.Context(), c, account, body, parsedReq)
+		}
 
 		if accountReleaseFunc != nil {
 			accountReleaseFunc()
 		}
 
 		if err != nil {
+			h.reportAccountScheduleResult(account, nil, err)
 			var failoverErr *service.UpstreamFailoverError
 			if errors.As(err, &failoverErr) {
 				// Can't failover if streaming content already sent
@@ -261,6 +285,8 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 			return
 		}
 
+		h.reportAccountScheduleResult(account, result, nil)
+
 		// 6. Record usage
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
@@ -268,7 +294,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
 
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 				Result:             result,
 				APIKey:             apiKey,
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -398,3 +399,39 @@ func nextBackoff(current time.Duration) time.Duration {
 	}
 	return jittered
 }
+
+// submitBoundUsageRecordTask 绑定请求上下文后提交使用记录任务。
+// 任务被 worker 池接受（入队或同步执行）后计费预留交由任务结算；任务被丢弃时不标记，
+// 由 handler 退出时的 ReleaseIfUnrecorded 立即释放，避免预留一直占用到 TTL 过期。
+func submitBoundUsageRecordTask(c *gin.Context, submit func(service.UsageRecordTask) service.UsageRecordSubmitMode, task service.UsageRecordTask) {
+	mode := submit(bindUsageRecordTask(c, task))
+	if mode == service.UsageRecordSubmitModeDropped || c == nil || c.Request == nil {
+		return
+	}
+	service.BillingReservationFromContext(c.Request.Context()).MarkRecorded()
+}
+
+// bindUsageRecordTask 将请求 context 中的 batch 计费、响应缓存命中标记、计费预留以及 trace 上下文传递给异步使用记录任务。
+// 使用记录任务在 worker 池中以独立 context 执行，不携带请求 context 的值；任务结束后释放未结算的预留。
+func bindUsageRecordTask(c *gin.Context, task service.UsageRecordTask) service.UsageRecordTask {
+	if task == nil || c == nil || c.Request == nil {
+		return task
+	}
+	info := service.BatchBillingFromContext(c.Request.Context())
+	cacheHit := service.ResponseCacheHitFromContext(c.Request.Context())
+	reservation := service.BillingReservationFromContext(c.Request.Context())
+	// 只保留 span 上下文，避免异步任务持有整个请求 context
+	traceCtx := tracing.ContextWithSpanFrom(context.Background(), c.Request.Context())
+	return func(ctx context.Context) {
+		ctx, span := tracing.Start(tracing.ContextWithSpanFrom(ctx, traceCtx), "billing.record_usage")
+		defer span.End()
+		if info != nil || cacheHit != nil {
+			ctx = service.WithResponseCacheHit(service.WithBatchBilling(ctx, info), cacheHit)
+		}
+		if reservation != nil {
+			ctx = service.WithBillingReservation(ctx, reservation)
+			defer reservation.Release(ctx)
+		}
+		task(ctx)
+	}
+}
@@ -239,6 +239,19 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		return
 	}
 
+	// 严格预留模式：转发前按预估最大费用预留余额/额度，防止并发请求透支
+	reservation, err := h.gatewayService.ReserveRequestCost(c.Request.Context(), apiKey, subscription, modelName, body)
+	if err != nil {
+		reqLog.Info("gemini.billing_reservation_rejected", zap.Error(err))
+		status, _, message := billingErrorDetails(err)
+		googleError(c, status, message)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
 	// 3) select account (sticky session based on request body)
 	// 优先使用 Gemini CLI 的会话标识（privileged-user-id + tmp 目录哈希）
 	sessionHash := extractGeminiCLISessionHash(c, body)
@@ -343,6 +356,12 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
 	cleanedForUnknownBinding := false
 
+	// 对冲计划：仅对生成类请求生效（countTokens/embedContent 等不对冲）
+	var hedgePlan *service.HedgePlan
+	if action == "generateContent" || action == "streamGenerateContent" {
+		hedgePlan = h.hedgeService.Plan(apiKey.Group, modelName, stream)
+	}
+
 	fs := NewFailoverState(h.maxAccountSwitchesGemini, hasBoundSession)
 
 	// 单账号分组提前设置 SingleAccountRetry 标记，让 Service 层首次 503 就不设模型限流标记。
@@ -373,6 +392,14 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			}
 		}
 		account := selection.Account
+		// Antigravity OAuth 上游不提供 embedContent，直接排除该账号。
+		if service.IsGeminiEmbedAction(action) && account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
+			if selection.Acquired && selection.ReleaseFunc != nil {
+				selection.ReleaseFunc()
+			}
+			fs.FailedAccountIDs[account.ID] = struct{}{}
+			continue
+		}
 		setOpsSelectedAccount(c, account.ID, account.Platform)
 		setOpsSelectedAccountName(c, account.Name)
 
@@ -458,15 +485,32 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		if fs.SwitchCount > 0 {
 			requestCtx = service.WithAccountSwitchCount(requestCtx, fs.SwitchCount, h.metadataBridgeEnabled())
 		}
-		if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
-			result, err = h.antigravityGatewayService.ForwardGemini(requestCtx, c, account, modelName, action, stream, body, hasBoundSession)
-		} else {
-			result, err = h.geminiCompatService.ForwardNative(requestCtx, c, account, modelName, action, stream, body)
-		}
+		primaryAccount := account
+		result, account, err = h.forwardWithHedge(requestCtx, c, account, &hedgeRequest{
+			plan:       hedgePlan,
+			groupID:    apiKey.GroupID,
+			sessionKey: sessionKey,
+			excluded:   fs.FailedAccountIDs,
+			selectAccount: func(ctx context.Context, excluded map[int64]struct{}) (*service.AccountSelectionResult, error) {
+				return h.gatewayService.SelectAccountWithLoadAwareness(ctx, apiKey.GroupID, "", modelName, excluded, "")
+			},
+			forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+				forwardBody := body
+				if acc.ID != primaryAccount.ID {
+					// thoughtSignature 与上游账号强相关，对冲账号需清理后再发送
+					forwardBody = service.CleanGeminiNativeThoughtSignatures(body)
+				}
+				if acc.Platform == service.PlatformAntigravity && acc.Type != service.AccountTypeAPIKey {
+					return h.antigravityGatewayService.ForwardGemini(ctx, fc, acc, modelName, action, stream, forwardBody, hasBoundSession && acc.ID == primaryAccount.ID)
+				}
+				return h.geminiCompatService.ForwardNative(ctx, fc, acc, modelName, action, stream, forwardBody)
+			},
+		})
 		if accountReleaseFunc != nil {
 			accountReleaseFunc()
 		}
 		if err != nil {
+			h.reportAccountScheduleResult(account, nil, err)
 			var failoverErr *service.UpstreamFailoverError
 			if errors.As(err, &failoverErr) {
 				failoverAction := fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, failoverErr)
@@ -485,6 +529,8 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			return
 		}
 
+		h.reportAccountScheduleResult(account, result, nil)
+
 		// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
@@ -508,7 +554,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		requestPayloadHash := service.HashUsageRequestPayload(body)
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsageWithLongContext(ctx, &service.RecordUsageLongContextInput{
 				Result:                result,
 				APIKey:                apiKey,
@@ -22,6 +22,8 @@ type AdminHandlers struct {
 	Promo                 *admin.PromoHandler
 	Setting               *admin.SettingHandler
 	Ops                   *admin.OpsHandler
+	OpsNotification       *admin.OpsNotificationHandler
+	OpsPayloadCapture     *admin.OpsPayloadCaptureHandler
 	System                *admin.SystemHandler
 	Subscription          *admin.SubscriptionHandler
 	SubscriptionPlan      *admin.SubscriptionPlanHandler
@@ -33,25 +35,34 @@ type AdminHandlers struct {
 	APIKey                *admin.AdminAPIKeyHandler
 	Tools                 *admin.ToolsHandler
 	ScheduledTest         *admin.ScheduledTestHandler
+	Payment               *admin.PaymentHandler
+	BalanceLedger         *admin.BalanceLedgerHandler
+	PostpaidStatement     *admin.PostpaidStatementHandler
 }
 
 // Handlers contains all HTTP handlers
 type Handlers struct {
-	Auth          *AuthHandler
-	User          *UserHandler
-	APIKey        *APIKeyHandler
-	Usage         *UsageHandler
-	Redeem        *RedeemHandler
-	Subscription  *SubscriptionHandler
-	Announcement  *AnnouncementHandler
-	Admin         *AdminHandlers
-	Gateway       *GatewayHandler
-	OpenAIGateway *OpenAIGatewayHandler
-	SoraGateway   *SoraGatewayHandler
-	SoraClient    *SoraClientHandler
-	Setting       *SettingHandler
-	Totp          *TotpHandler
-	Provider      *ProviderHandler
+	Auth              *AuthHandler
+	User              *UserHandler
+	APIKey            *APIKeyHandler
+	Usage             *UsageHandler
+	Redeem            *RedeemHandler
+	Subscription      *SubscriptionHandler
+	Announcement      *AnnouncementHandler
+	Admin             *AdminHandlers
+	Gateway           *GatewayHandler
+	OpenAIGateway     *OpenAIGatewayHandler
+	SoraGateway       *SoraGatewayHandler
+	SoraClient        *SoraClientHandler
+	Setting           *SettingHandler
+	Totp              *TotpHandler
+	Provider          *ProviderHandler
+	Batch             *BatchHandler
+	Metrics           *MetricsHandler
+	Status            *StatusHandler
+	Payment           *PaymentHandler
+	BalanceLedger     *BalanceLedgerHandler
+	PostpaidStatement *PostpaidStatementHandler
 }
 
 // BuildInfo contains build-time information
@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var errHedgeAttemptLost = errors.New("hedged attempt lost the race")
+
+// hedgeForwardFunc 使用指定账号转发一次请求；c 为该次尝试独占的 gin.Context。
+type hedgeForwardFunc func(ctx context.Context, c *gin.Context, account *service.Account) (*service.ForwardResult, error)
+
+// hedgeSelectFunc 为对冲请求选择账号（需排除 excluded 中的账号）。
+type hedgeSelectFunc func(ctx context.Context, excluded map[int64]struct{}) (*service.AccountSelectionResult, error)
+
+// hedgeRequest 一次可对冲转发的参数。
+type hedgeRequest struct {
+	plan          *service.HedgePlan
+	groupID       *int64
+	sessionKey    string
+	excluded      map[int64]struct{}
+	selectAccount hedgeSelectFunc
+	forward       hedgeForwardFunc
+}
+
+// hedgeAttempt 一次转发尝试（主请求或对冲请求）。
+type hedgeAttempt struct {
+	hedge     bool
+	account   *service.Account
+	c         *gin.Context
+	writer    *hedgeAttemptWriter
+	cancel    context.CancelFunc
+	startedAt time.Time
+	result    *service.ForwardResult
+	err       error
+	duration  time.Duration
+}
+
+func (a *hedgeAttempt) label() string {
+	if a.hedge {
+		return "hedge"
+	}
+	return "primary"
+}
+
+// hedgeRace 对冲竞争状态：首个成功写出响应的尝试胜出，接管客户端连接并取消其余尝试。
+type hedgeRace struct {
+	target gin.ResponseWriter
+
+	mu        sync.Mutex
+	attempts  []*hedgeAttempt
+	winner    *hedgeAttempt
+	claimedAt time.Time
+}
+
+// add 登记尝试；若竞争已有胜出者，则直接取消该尝试。
+func (r *hedgeRace) add(a *hedgeAttempt) {
+	r.mu.Lock()
+	r.attempts = append(r.attempts, a)
+	decided := r.winner != nil
+	r.mu.Unlock()
+	if decided {
+		a.cancel()
+	}
+}
+
+func (r *hedgeRace) claim(a *hedgeAttempt) bool {
+	r.mu.Lock()
+	if r.winner != nil {
+		won := r.winner == a
+		r.mu.Unlock()
+		return won
+	}
+	r.winner = a
+	r.claimedAt = time.Now()
+	losers := make([]*hedgeAttempt, 0, len(r.attempts))
+	for _, other := range r.attempts {
+		if other != a {
+			losers = append(losers, other)
+		}
+	}
+	r.mu.Unlock()
+	for _, loser := range losers {
+		loser.cancel()
+	}
+	return true
+}
+
+func (r *hedgeRace) result() (*hedgeAttempt, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.winner, r.claimedAt
+}
+
+// forwardWithHedge 转发请求，并在主请求超过对冲延迟仍未产生首字节时，
+// 选择另一账号并发发起对冲请求：先成功写出响应者胜出，另一方被取消且不计费。
+// 未配置对冲计划时等同于直接转发。返回胜出（或最终失败）的结果与对应账号。
+func (h *GatewayHandler) forwardWithHedge(ctx context.Context, c *gin.Context, primary *service.Account, req *hedgeRequest) (*service.ForwardResult, *service.Account, error) {
+	if req.plan == nil {
+		result, err := req.forward(ctx, c, primary)
+		return result, primary, err
+	}
+
+	// 两次尝试的 gin.Context 副本与响应头快照需在主请求开始写出前准备好，避免与胜出者并发访问
+	header := c.Writer.Header().Clone()
+	primaryCtx, hedgeCtx := c.Copy(), c.Copy()
+	race := &hedgeRace{target: c.Writer}
+	done := make(chan *hedgeAttempt, 2)
+	primaryAttempt := startHedgeAttempt(ctx, primaryCtx, header, race, primary, false, nil, req.forward, done)
+	running := 1
+	hedged := false
+
+	timer := time.NewTimer(req.plan.Delay)
+	defer timer.Stop()
+	for running > 0 {
+		select {
+		case <-timer.C:
+			if winner, _ := race.result(); winner != nil || hedged {
+				continue
+			}
+			hedged = true
+			account, release, reason := h.acquireHedgeAccount(c, primary, req)
+			if account == nil {
+				appendRequestTraceEvent(c.Request.Context(), "hedge_skipped", "forward", map[string]any{
+					"primary_account_id": primary.ID,
+					"delay_ms":           req.plan.Delay.Milliseconds(),
+					"reason":             reason,
+				})
+				continue
+			}
+			appendRequestTraceEvent(c.Request.Context(), "hedge_started", "forward", map[string]any{
+				"primary_account_id": primary.ID,
+				"hedge_account_id":   account.ID,
+				"hedge_account_name": account.Name,
+				"delay_ms":           req.plan.Delay.Milliseconds(),
+			})
+			startHedgeAttempt(ctx, hedgeCtx, header, race, account, true, release, req.forward, done)
+			running++
+		case attempt := <-done:
+			running--
+			// 成功但未写出任何内容（如空响应）时，在结束时认领
+			if attempt.err == nil && race.claim(attempt) && !attempt.writer.committed {
+				_ = attempt.writer.commit()
+			}
+			if hedged {
+				recordHedgeAttemptFinished(c, race, attempt)
+			}
+		}
+	}
+
+	winner, claimedAt := race.result()
+	if winner == nil {
+		// 全部失败：以主请求为准，写出其缓存的错误响应，交由上层按原有逻辑处理 failover
+		winner = primaryAttempt
+		if winner.writer.status != 0 || winner.writer.buf.Len() > 0 {
+			_ = winner.writer.commit()
+		}
+	} else if winner.err == nil {
+		h.hedgeService.ObserveFirstByte(req.plan, claimedAt.Sub(winner.startedAt))
+	}
+
+	for k, v := range winner.c.Keys {
+		c.Set(k, v)
+	}
+	if winner.hedge {
+		setOpsSelectedAccount(c, winner.account.ID, winner.account.Platform)
+		setOpsSelectedAccountName(c, winner.account.Name)
+		// 对冲胜出后粘性会话改绑到胜出账号，避免后续请求回到慢账号（Gemini 签名也与账号强相关）
+		if req.sessionKey != "" && winner.err == nil {
+			if err := h.gatewayService.BindStickySession(c.Request.Context(), req.groupID, req.sessionKey, winner.account.ID); err != nil {
+				logger.L().With(zap.String("component", "handler.gateway.hedge")).
+					Warn("gateway.hedge_bind_sticky_session_failed", zap.Int64("account_id", winner.account.ID), zap.Error(err))
+			}
+		}
+	}
+	return winner.result, winner.account, winner.err
+}
+
+// acquireHedgeAccount 为对冲请求选择另一账号并立即占用并发槽位（不排队等待），同时占用分组对冲预算。
+func (h *GatewayHandler) acquireHedgeAccount(c *gin.Context, primary *service.Account, req *hedgeRequest) (*service.Account, func(), string) {
+	excluded := make(map[int64]struct{}, len(req.excluded)+1)
+	for id := range req.excluded {
+		excluded[id] = struct{}{}
+	}
+	excluded[primary.ID] = struct{}{}
+
+	selection, err := req.selectAccount(c.Request.Context(), excluded)
+	if err != nil || selection == nil || selection.Account == nil {
+		return nil, nil, "no_available_account"
+	}
+	if !selection.Acquired || selection.Account.ID == primary.ID {
+		if selection.Acquired && selection.ReleaseFunc != nil {
+			selection.ReleaseFunc()
+		}
+		return nil, nil, "no_free_slot"
+	}
+	if !h.hedgeService.TryAcquireBudget(req.plan) {
+		if selection.ReleaseFunc != nil {
+			selection.ReleaseFunc()
+		}
+		return nil, nil, "budget_exhausted"
+	}
+	return selection.Account, selection.ReleaseFunc, ""
+}
+
+// startHedgeAttempt 在独立 goroutine 中发起一次转发尝试；cp 为该尝试独占的 gin.Context 副本。
+func startHedgeAttempt(ctx context.Context, cp *gin.Context, header http.Header, race *hedgeRace, account *service.Account, hedge bool, release func(), forward hedgeForwardFunc, done chan<- *hedgeAttempt) *hedgeAttempt {
+	attemptCtx, cancel := context.WithCancel(ctx)
+	cp.Request = cp.Request.WithContext(attemptCtx)
+	attempt := &hedgeAttempt{
+		hedge:     hedge,
+		account:   account,
+		c:         cp,
+		cancel:    cancel,
+		startedAt: time.Now(),
+	}
+	attempt.writer = &hedgeAttemptWriter{race: race, attempt: attempt, header: header.Clone()}
+	cp.Writer = attempt.writer
+	race.add(attempt)
+
+	go func() {
+		defer func() {
+			cancel()
+			if release != nil {
+				release()
+			}
+			done <- attempt
+		}()
+		attempt.result, attempt.err = forward(attemptCtx, cp, account)
+		attempt.duration = time.Since(attempt.startedAt)
+	}()
+	return attempt
+}
+
+func recordHedgeAttemptFinished(c *gin.Context, race *hedgeRace, attempt *hedgeAttempt) {
+	winner, _ := race.result()
+	outcome := "failed"
+	switch {
+	case winner == attempt:
+		outcome = "won"
+	case winner != nil:
+		outcome = "cancelled"
+	}
+	data := map[string]any{
+		"attempt":      attempt.label(),
+		"account_id":   attempt.account.ID,
+		"account_name": attempt.account.Name,
+		"outcome":      outcome,
+		"duration_ms":  attempt.duration.Milliseconds(),
+	}
+	if attempt.err != nil && outcome == "failed" {
+		data["error"] = attempt.err.Error()
+	}
+	appendRequestTraceEvent(c.Request.Context(), "hedge_attempt_finished", "forward", data)
+}
+
+// hedgeAttemptWriter 对冲尝试的响应写入器：
+// 2xx 内容首次写出时尝试认领竞争，认领成功后写入客户端连接；错误响应先缓存，由最终结果决定是否写出。
+type hedgeAttemptWriter struct {
+	race      *hedgeRace
+	attempt   *hedgeAttempt
+	header    http.Header
+	status    int
+	buf       bytes.Buffer
+	committed bool
+	lost      bool
+}
+
+// commit 接管客户端连接：同步响应头、状态码与已缓存的内容。
+func (w *hedgeAttemptWriter) commit() error {
+	w.committed = true
+	target := w.race.target
+	dst := target.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	if w.status != 0 {
+		target.WriteHeader(w.status)
+	}
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := target.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *hedgeAttemptWriter) Header() http.Header {
+	if w.committed {
+		return w.race.target.Header()
+	}
+	return w.header
+}
+
+func (w *hedgeAttemptWriter) WriteHeader(code int) {
+	if w.committed {
+		w.race.target.WriteHeader(code)
+		return
+	}
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *hedgeAttemptWriter) WriteHeaderNow() {
+	if w.committed {
+		w.race.target.WriteHeaderNow()
+	}
+}
+
+func (w *hedgeAttemptWriter) Write(data []byte) (int, error) {
+	if w.committed {
+		return w.race.target.Write(data)
+	}
+	if w.lost {
+		return 0, errHedgeAttemptLost
+	}
+	if w.status >= http.StatusBadRequest {
+		return w.buf.Write(data)
+	}
+	if !w.race.claim(w.attempt) {
+		w.lost = true
+		return 0, errHedgeAttemptLost
+	}
+	if err := w.commit(); err != nil {
+		return 0, err
+	}
+	return w.race.target.Write(data)
+}
+
+func (w *hedgeAttemptWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *hedgeAttemptWriter) Status() int {
+	if w.committed {
+		return w.race.target.Status()
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *hedgeAttemptWriter) Size() int {
+	if w.committed {
+		return w.race.target.Size()
+	}
+	if w.buf.Len() == 0 {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *hedgeAttemptWriter) Written() bool {
+	if w.committed {
+		return w.race.target.Written()
+	}
+	return w.buf.Len() > 0
+}
+
+func (w *hedgeAttemptWriter) Flush() {
+	if w.committed {
+		w.race.target.Flush()
+	}
+}
+
+func (w *hedgeAttemptWriter) Pusher() http.Pusher {
+	return nil
+}
+
+func (w *hedgeAttemptWriter) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+func (w *hedgeAttemptWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijack not supported for hedged attempts")
+}
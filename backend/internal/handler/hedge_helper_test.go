@@ -0,0 +1,185 @@
+//go:build unit
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newHedgeTestHandler(budgetPercent float64) (*GatewayHandler, *service.HedgePlan) {
+	cfg := &config.Config{}
+	cfg.Gateway.Hedging = config.GatewayHedgingConfig{
+		Enabled:             true,
+		DelayPercentile:     95,
+		MinDelayMs:          1,
+		MaxDelayMs:          1000,
+		InitialDelayMs:      20,
+		MinSamples:          100,
+		SampleSize:          100,
+		BudgetWindowSeconds: 60,
+	}
+	hedgeService := service.NewRequestHedgeService(cfg)
+	plan := hedgeService.Plan(&service.Group{ID: 1, HedgeBudgetPercent: budgetPercent}, "claude-sonnet-4", false)
+	return &GatewayHandler{hedgeService: hedgeService}, plan
+}
+
+func newHedgeTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	return c, rec
+}
+
+func hedgeSelectFixed(account *service.Account, calls *atomic.Int32) hedgeSelectFunc {
+	return func(_ context.Context, excluded map[int64]struct{}) (*service.AccountSelectionResult, error) {
+		calls.Add(1)
+		if _, ok := excluded[account.ID]; ok {
+			return nil, context.Canceled
+		}
+		return &service.AccountSelectionResult{Account: account, Acquired: true, ReleaseFunc: func() {}}, nil
+	}
+}
+
+func TestForwardWithHedge_HedgeWinsAndPrimaryIsCancelled(t *testing.T) {
+	h, plan := newHedgeTestHandler(100)
+	c, rec := newHedgeTestContext()
+	primary := &service.Account{ID: 1, Name: "slow"}
+	hedgeAccount := &service.Account{ID: 2, Name: "fast"}
+	var selectCalls atomic.Int32
+	primaryCancelled := make(chan struct{})
+
+	result, account, err := h.forwardWithHedge(context.Background(), c, primary, &hedgeRequest{
+		plan:          plan,
+		selectAccount: hedgeSelectFixed(hedgeAccount, &selectCalls),
+		forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+			if acc.ID == primary.ID {
+				<-ctx.Done()
+				close(primaryCancelled)
+				return nil, ctx.Err()
+			}
+			fc.Header("X-Upstream", "fast")
+			fc.JSON(http.StatusOK, gin.H{"id": "msg_fast"})
+			return &service.ForwardResult{Model: "claude-sonnet-4"}, nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, hedgeAccount, account)
+	require.Equal(t, "claude-sonnet-4", result.Model)
+	require.EqualValues(t, 1, selectCalls.Load())
+	require.JSONEq(t, `{"id":"msg_fast"}`, rec.Body.String())
+	require.Equal(t, "fast", rec.Header().Get("X-Upstream"))
+	select {
+	case <-primaryCancelled:
+	default:
+		t.Fatal("losing primary attempt must be cancelled")
+	}
+}
+
+func TestForwardWithHedge_FastPrimaryDoesNotHedge(t *testing.T) {
+	h, plan := newHedgeTestHandler(100)
+	c, rec := newHedgeTestContext()
+	primary := &service.Account{ID: 1}
+	var selectCalls atomic.Int32
+
+	_, account, err := h.forwardWithHedge(context.Background(), c, primary, &hedgeRequest{
+		plan:          plan,
+		selectAccount: hedgeSelectFixed(&service.Account{ID: 2}, &selectCalls),
+		forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+			fc.String(http.StatusOK, "data: first\n\n")
+			fc.Writer.Flush()
+			// 首字节已写出后即使耗时超过对冲延迟也不再对冲
+			time.Sleep(50 * time.Millisecond)
+			fc.String(http.StatusOK, "data: done\n\n")
+			return &service.ForwardResult{}, nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, primary, account)
+	require.Zero(t, selectCalls.Load())
+	require.Equal(t, "data: first\n\ndata: done\n\n", rec.Body.String())
+}
+
+func TestForwardWithHedge_AllFailedWritesPrimaryError(t *testing.T) {
+	h, plan := newHedgeTestHandler(100)
+	c, rec := newHedgeTestContext()
+	primary := &service.Account{ID: 1}
+	hedgeAccount := &service.Account{ID: 2}
+	var selectCalls atomic.Int32
+	primaryErr := context.DeadlineExceeded
+
+	_, account, err := h.forwardWithHedge(context.Background(), c, primary, &hedgeRequest{
+		plan:          plan,
+		selectAccount: hedgeSelectFixed(hedgeAccount, &selectCalls),
+		forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+			if acc.ID == primary.ID {
+				time.Sleep(60 * time.Millisecond)
+				fc.JSON(http.StatusBadGateway, gin.H{"error": "primary"})
+				return nil, primaryErr
+			}
+			fc.JSON(http.StatusServiceUnavailable, gin.H{"error": "hedge"})
+			return nil, context.Canceled
+		},
+	})
+
+	require.ErrorIs(t, err, primaryErr)
+	require.Equal(t, primary, account)
+	require.EqualValues(t, 1, selectCalls.Load())
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.JSONEq(t, `{"error":"primary"}`, rec.Body.String())
+}
+
+func TestForwardWithHedge_BudgetExhaustedSkipsHedge(t *testing.T) {
+	h, plan := newHedgeTestHandler(1)
+	c, rec := newHedgeTestContext()
+	primary := &service.Account{ID: 1}
+	var selectCalls atomic.Int32
+
+	_, account, err := h.forwardWithHedge(context.Background(), c, primary, &hedgeRequest{
+		plan:          plan,
+		selectAccount: hedgeSelectFixed(&service.Account{ID: 2}, &selectCalls),
+		forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+			if acc.ID != primary.ID {
+				t.Errorf("unexpected hedge attempt on account %d", acc.ID)
+			}
+			time.Sleep(60 * time.Millisecond)
+			fc.String(http.StatusOK, "ok")
+			return &service.ForwardResult{}, nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, primary, account)
+	require.EqualValues(t, 1, selectCalls.Load())
+	require.Equal(t, "ok", rec.Body.String())
+}
+
+func TestForwardWithHedge_NoPlanForwardsDirectly(t *testing.T) {
+	h, _ := newHedgeTestHandler(0)
+	c, rec := newHedgeTestContext()
+	primary := &service.Account{ID: 1}
+
+	_, account, err := h.forwardWithHedge(context.Background(), c, primary, &hedgeRequest{
+		forward: func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.ForwardResult, error) {
+			require.Same(t, c, fc)
+			fc.String(http.StatusOK, "direct")
+			return &service.ForwardResult{}, nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, primary, account)
+	require.Equal(t, "direct", rec.Body.String())
+}
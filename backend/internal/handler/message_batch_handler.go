@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type createMessageBatchRequest struct {
+	Requests []service.MessageBatchRequest `json:"requests"`
+}
+
+// CreateMessageBatch creates an Anthropic message batch
+// POST /v1/messages/batches
+func (h *BatchHandler) CreateMessageBatch(c *gin.Context) {
+	apiKey, subject, ok := h.messageBatchAuthContext(c)
+	if !ok {
+		return
+	}
+	var req createMessageBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.messageBatchErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	job, err := h.batchService.CreateMessageBatch(c.Request.Context(), &service.MessageBatchCreateInput{
+		UserID:   subject.UserID,
+		APIKeyID: apiKey.ID,
+		GroupID:  apiKey.GroupID,
+		ClientIP: ip.GetClientIP(c),
+		Requests: req.Requests,
+	})
+	if err != nil {
+		h.messageBatchServiceError(c, "message_batch.create_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, messageBatchResponse(c, job))
+}
+
+// ListMessageBatches lists the caller's message batches
+// GET /v1/messages/batches
+func (h *BatchHandler) ListMessageBatches(c *gin.Context) {
+	_, subject, ok := h.messageBatchAuthContext(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	jobs, hasMore, err := h.batchService.ListMessageBatches(c.Request.Context(), subject.UserID, c.Query("after_id"), limit)
+	if err != nil {
+		h.messageBatchServiceError(c, "message_batch.list_failed", err)
+		return
+	}
+	data := make([]gin.H, 0, len(jobs))
+	for i := range jobs {
+		data = append(data, messageBatchResponse(c, &jobs[i]))
+	}
+	resp := gin.H{"data": data, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(data) > 0 {
+		resp["first_id"] = data[0]["id"]
+		resp["last_id"] = data[len(data)-1]["id"]
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetMessageBatch returns a message batch
+// GET /v1/messages/batches/:batch_id
+func (h *BatchHandler) GetMessageBatch(c *gin.Context) {
+	_, subject, ok := h.messageBatchAuthContext(c)
+	if !ok {
+		return
+	}
+	job, err := h.batchService.GetMessageBatch(c.Request.Context(), subject.UserID, c.Param("batch_id"))
+	if err != nil {
+		h.messageBatchServiceError(c, "message_batch.get_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, messageBatchResponse(c, job))
+}
+
+// CancelMessageBatch cancels a message batch
+// POST /v1/messages/batches/:batch_id/cancel
+func (h *BatchHandler) CancelMessageBatch(c *gin.Context) {
+	_, subject, ok := h.messageBatchAuthContext(c)
+	if !ok {
+		return
+	}
+	job, err := h.batchService.CancelMessageBatch(c.Request.Context(), subject.UserID, c.Param("batch_id"))
+	if err != nil {
+		h.messageBatchServiceError(c, "message_batch.cancel_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, messageBatchResponse(c, job))
+}
+
+// GetMessageBatchResults streams the results JSONL of an ended message batch
+// GET /v1/messages/batches/:batch_id/results
+func (h *BatchHandler) GetMessageBatchResults(c *gin.Context) {
+	_, subject, ok := h.messageBatchAuthContext(c)
+	if !ok {
+		return
+	}
+	file, rc, err := h.batchService.OpenMessageBatchResults(c.Request.Context(), subject.UserID, c.Param("batch_id"))
+	if err != nil {
+		h.messageBatchServiceError(c, "message_batch.results_failed", err)
+		return
+	}
+	defer func() { _ = rc.Close() }()
+
+	c.Header("Content-Type", "application/binary")
+	c.Header("Content-Length", strconv.FormatInt(file.Bytes, 10))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, rc); err != nil {
+		logger.L().Warn("message_batch.results_copy_failed", zap.String("file_id", file.ID), zap.Error(err))
+	}
+}
+
+func (h *BatchHandler) messageBatchAuthContext(c *gin.Context) (*service.APIKey, middleware2.AuthSubject, bool) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.messageBatchErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return nil, middleware2.AuthSubject{}, false
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.messageBatchErrorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return nil, middleware2.AuthSubject{}, false
+	}
+	return apiKey, subject, true
+}
+
+func (h *BatchHandler) messageBatchServiceError(c *gin.Context, event string, err error) {
+	status := infraerrors.Code(err)
+	if status >= http.StatusInternalServerError {
+		logger.L().Error(event, zap.Error(err))
+		h.messageBatchErrorResponse(c, status, "api_error", "Internal server error")
+		return
+	}
+	errType := "invalid_request_error"
+	switch status {
+	case http.StatusNotFound:
+		errType = "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		errType = "request_too_large"
+	}
+	h.messageBatchErrorResponse(c, status, errType, infraerrors.Message(err))
+}
+
+// messageBatchErrorResponse 返回 Anthropic 格式的错误响应
+func (h *BatchHandler) messageBatchErrorResponse(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{
+		"type": "error",
+		"error": gin.H{
+			"type":    errType,
+			"message": message,
+		},
+	})
+}
+
+func messageBatchResponse(c *gin.Context, job *service.BatchJob) gin.H {
+	processingStatus := service.MessageBatchProcessingStatus(job)
+	var resultsURL any
+	if processingStatus == service.MessageBatchStatusEnded && job.OutputFileID != nil {
+		scheme := "http"
+		if isRequestHTTPS(c) {
+			scheme = "https"
+		}
+		resultsURL = scheme + "://" + c.Request.Host + "/v1/messages/batches/" + job.ID + "/results"
+	}
+	return gin.H{
+		"id":                  job.ID,
+		"type":                "message_batch",
+		"processing_status":   processingStatus,
+		"request_counts":      service.MessageBatchCounts(job),
+		"created_at":          job.CreatedAt.UTC().Format(time.RFC3339),
+		"expires_at":          job.ExpiresAt.UTC().Format(time.RFC3339),
+		"ended_at":            rfc3339OrNil(service.MessageBatchEndedAt(job)),
+		"cancel_initiated_at": rfc3339OrNil(job.CancellingAt),
+		"archived_at":         nil,
+		"results_url":         resultsURL,
+	}
+}
+
+func rfc3339OrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
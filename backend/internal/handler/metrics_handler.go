@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// metricsModelLabelMaxLen 模型标签最大长度，避免异常请求撑大指标体积
+const metricsModelLabelMaxLen = 64
+
+// MetricsHandler 提供 Prometheus 文本格式的 /metrics 端点
+type MetricsHandler struct {
+	enabled bool
+	token   string
+}
+
+// NewMetricsHandler 创建 MetricsHandler，并注册抓取时读取的运行时指标
+func NewMetricsHandler(
+	cfg *config.Config,
+	usageRecordWorkerPool *service.UsageRecordWorkerPool,
+	gatewayService *service.GatewayService,
+	openaiGatewayService *service.OpenAIGatewayService,
+) *MetricsHandler {
+	h := &MetricsHandler{}
+	if cfg != nil {
+		h.enabled = cfg.Ops.Prometheus.Enabled
+		h.token = strings.TrimSpace(cfg.Ops.Prometheus.Token)
+	}
+	if h.enabled {
+		service.RegisterRuntimeMetricsCollectors(usageRecordWorkerPool, gatewayService, openaiGatewayService)
+	}
+	return h
+}
+
+// Enabled 返回是否启用 /metrics 端点
+func (h *MetricsHandler) Enabled() bool {
+	return h != nil && h.enabled
+}
+
+// Metrics handles scraping metrics
+// GET /metrics
+func (h *MetricsHandler) Metrics(c *gin.Context) {
+	if !h.Enabled() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if !h.authorized(c.GetHeader("Authorization")) {
+		c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+		c.String(http.StatusUnauthorized, "unauthorized\n")
+		return
+	}
+	c.Header("Content-Type", metrics.ContentType)
+	c.Status(http.StatusOK)
+	_ = metrics.Default.WriteText(c.Writer)
+}
+
+func (h *MetricsHandler) authorized(header string) bool {
+	if h.token == "" {
+		return false
+	}
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(h.token)) == 1
+}
+
+// GatewayMetricsMiddleware 记录网关请求的计数与耗时。
+// 平台取自 API Key 所属分组，模型取自 handler 解析请求时写入的 ops 上下文。
+func GatewayMetricsMiddleware(enabled bool) gin.HandlerFunc {
+	if !enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		var groupID *int64
+		apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+		if apiKey != nil {
+			groupID = apiKey.GroupID
+		}
+		platform := resolveOpsPlatform(apiKey, guessPlatformFromPath(c.Request.URL.Path))
+		var model string
+		if v, ok := c.Get(opsModelKey); ok {
+			model, _ = v.(string)
+		}
+		service.RecordGatewayRequestMetrics(platform, groupID, truncateString(model, metricsModelLabelMaxLen), c.Writer.Status(), time.Since(start))
+	}
+}
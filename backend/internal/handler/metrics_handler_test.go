@@ -0,0 +1,94 @@
+//go:build unit
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetricsTestHandler(enabled bool) *MetricsHandler {
+	cfg := &config.Config{}
+	cfg.Ops.Prometheus.Enabled = enabled
+	cfg.Ops.Prometheus.Token = "secret-token"
+	return NewMetricsHandler(cfg, nil, nil, nil)
+}
+
+func TestMetricsHandler_TokenCheck(t *testing.T) {
+	h := newMetricsTestHandler(true)
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "missing", header: "", want: http.StatusUnauthorized},
+		{name: "wrong token", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Basic secret-token", want: http.StatusUnauthorized},
+		{name: "ok", header: "Bearer secret-token", want: http.StatusOK},
+		{name: "case-insensitive scheme", header: "bearer secret-token", want: http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tc.header != "" {
+				c.Request.Header.Set("Authorization", tc.header)
+			}
+			h.Metrics(c)
+			require.Equal(t, tc.want, rec.Code)
+			if tc.want == http.StatusOK {
+				require.Contains(t, rec.Body.String(), "sub2api_worker_pool_queue_depth")
+			}
+		})
+	}
+}
+
+func TestMetricsHandler_Disabled(t *testing.T) {
+	h := newMetricsTestHandler(false)
+	require.False(t, h.Enabled())
+	var nilHandler *MetricsHandler
+	require.False(t, nilHandler.Enabled())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	c.Request.Header.Set("Authorization", "Bearer secret-token")
+	h.Metrics(c)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGatewayMetricsMiddleware_RecordsRequest(t *testing.T) {
+	h := newMetricsTestHandler(true)
+	groupID := int64(4242)
+	r := gin.New()
+	r.Use(GatewayMetricsMiddleware(true))
+	r.POST("/v1/messages", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+			GroupID: &groupID,
+			Group:   &service.Group{ID: groupID, Platform: service.PlatformGemini},
+		})
+		c.Set(opsModelKey, "gemini-test-model")
+		c.Status(http.StatusTooManyRequests)
+	})
+	r.GET("/metrics", h.Metrics)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `sub2api_gateway_requests_total{platform="gemini",group="4242",model="gemini-test-model",status="429"} 1`)
+	require.Contains(t, rec.Body.String(), `sub2api_gateway_request_duration_seconds_count{platform="gemini",group="4242",model="gemini-test-model",status="429"} 1`)
+}
@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// audioForwardFunc 将 audio 请求转发到选中的账号
+type audioForwardFunc func(ctx context.Context, c *gin.Context, account *service.Account) (*service.OpenAIForwardResult, error)
+
+// audioReserveFunc 严格预留模式下在转发前为 audio 请求预留额度
+type audioReserveFunc func(ctx context.Context, apiKey *service.APIKey, subscription *service.UserSubscription, model string) (*service.BillingReservation, error)
+
+// AudioTranscriptions handles OpenAI audio transcription and translation requests.
+// POST /v1/audio/transcriptions
+// POST /v1/audio/translations
+// The multipart upload is spooled to a temp file instead of being buffered in memory.
+func (h *OpenAIGatewayHandler) AudioTranscriptions(c *gin.Context) {
+	endpoint := service.AudioEndpointTranscriptions
+	if strings.HasSuffix(strings.TrimRight(c.Request.URL.Path, "/"), "/translations") {
+		endpoint = service.AudioEndpointTranslations
+	}
+
+	upload, err := service.SpoolAudioUpload(c.Request.Body, c.GetHeader("Content-Type"))
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse multipart request: "+err.Error())
+		return
+	}
+	defer upload.Close()
+
+	if upload.File == nil || upload.File.Size == 0 {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "file is required")
+		return
+	}
+	reqModel := upload.Field("model")
+	if reqModel == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+	stream := strings.EqualFold(upload.Field("stream"), "true")
+
+	h.serveAudio(c, "openai_audio."+endpoint, reqModel, stream, nil, func(ctx context.Context, c *gin.Context, account *service.Account) (*service.OpenAIForwardResult, error) {
+		return h.gatewayService.ForwardAudioTranscription(ctx, c, account, endpoint, upload)
+	}, func(ctx context.Context, apiKey *service.APIKey, subscription *service.UserSubscription, model string) (*service.BillingReservation, error) {
+		// 转写/翻译按音频时长计费，转发前按文件大小估算时长上限
+		return h.gatewayService.ReserveTranscriptionCost(ctx, apiKey, subscription, model, upload.File.Size)
+	}, func(model string) {
+		for i := range upload.Fields {
+			if upload.Fields[i].Name == "model" {
+				upload.Fields[i].Value = model
+			}
+		}
+	})
+}
+
+// AudioSpeech handles OpenAI text-to-speech requests. The generated audio is streamed
+// to the client as it arrives and billed by input characters.
+// POST /v1/audio/speech
+func (h *OpenAIGatewayHandler) AudioSpeech(c *gin.Context) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+	if len(body) == 0 {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		return
+	}
+	if !gjson.ValidBytes(body) {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	modelResult := gjson.GetBytes(body, "model")
+	if !modelResult.Exists() || modelResult.Type != gjson.String || modelResult.String() == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+	if gjson.GetBytes(body, "input").String() == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "input is required")
+		return
+	}
+
+	h.serveAudio(c, "openai_audio.speech", modelResult.String(), true, body, func(ctx context.Context, c *gin.Context, account *service.Account) (*service.OpenAIForwardResult, error) {
+		return h.gatewayService.ForwardAudioSpeech(ctx, c, account, body)
+	}, func(ctx context.Context, apiKey *service.APIKey, subscription *service.UserSubscription, model string) (*service.BillingReservation, error) {
+		return h.gatewayService.ReserveSpeechCost(ctx, apiKey, subscription, model, body)
+	}, func(model string) {
+		if updated, err := sjson.SetBytes(body, "model", model); err == nil {
+			body = updated
+		}
+	})
+}
+
+// serveAudio 执行 audio 请求的公共流程：鉴权上下文、并发槽位、计费校验、
+// 仅 API Key 账号的调度 + failover 循环以及用量记录。
+// payload 为可哈希的请求体（multipart 上传为 nil）；reserve 在严格预留模式下预留额度；
+// setModel 在分组模型别名生效时改写请求中的模型。
+func (h *OpenAIGatewayHandler) serveAudio(
+	c *gin.Context,
+	component string,
+	reqModel string,
+	stream bool,
+	payload []byte,
+	forward audioForwardFunc,
+	reserve audioReserveFunc,
+	setModel func(model string),
+) {
+	streamStarted := false
+	defer h.recoverResponsesPanic(c, &streamStarted)
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.openai_gateway.audio",
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+	startRequestTraceFromGin(c, c.Request.URL.Path, reqModel, stream)
+
+	// 模型别名解析
+	if apiKey.Group != nil {
+		if resolved := apiKey.Group.ResolveModelAlias(reqModel); resolved != reqModel {
+			reqLog.Info(component+".model_alias_resolved", zap.String("original_model", reqModel), zap.String("resolved_model", resolved))
+			c.Set(gatewayUserOriginalModelKey, reqModel)
+			recordGroupResolved(c, reqModel, resolved, "group_alias")
+			reqModel = resolved
+			setModel(resolved)
+		}
+	}
+
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.Bool("stream", stream))
+
+	setOpsRequestContext(c, reqModel, stream, payload)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(stream, false)))
+
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+	routingStart := time.Now()
+
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, stream, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info(component+".billing_eligibility_check_failed", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.errorResponse(c, status, code, message)
+		return
+	}
+
+	// 严格预留模式：转发前按预估费用预留余额/额度，防止并发请求透支
+	reservation, err := reserve(c.Request.Context(), apiKey, subscription, reqModel)
+	if err != nil {
+		reqLog.Info(component+".billing_reservation_rejected", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.errorResponse(c, status, code, message)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
+	maxAccountSwitches := h.maxAccountSwitches
+	switchCount := 0
+	failedAccountIDs := make(map[int64]struct{})
+	sameAccountRetryCount := make(map[int64]int)
+	var lastFailoverErr *service.UpstreamFailoverError
+
+	for {
+		recordSelectionStarted(c, reqModel, failedAccountIDs)
+		selection, _, err := h.gatewayService.SelectAccountWithScheduler(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			"",
+			reqModel,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportAny,
+		)
+		if err != nil {
+			reqLog.Warn(component+".account_select_failed",
+				zap.Error(err),
+				zap.Int("excluded_account_count", len(failedAccountIDs)),
+			)
+			if lastFailoverErr != nil {
+				h.handleFailoverExhausted(c, lastFailoverErr, false)
+			} else {
+				h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts support audio")
+			}
+			return
+		}
+		if selection == nil || selection.Account == nil {
+			h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts")
+			return
+		}
+		account := selection.Account
+		// OAuth 账号无法调用 audio：直接排除，不计入切换次数。
+		if !account.IsOpenAIApiKey() {
+			if selection.Acquired && selection.ReleaseFunc != nil {
+				selection.ReleaseFunc()
+			}
+			failedAccountIDs[account.ID] = struct{}{}
+			continue
+		}
+		recordSelectionResult(c, selection)
+		reqLog.Debug(component+".account_selected", zap.Int64("account_id", account.ID), zap.String("account_name", account.Name))
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedAccountName(c, account.Name)
+
+		accountReleaseFunc, acquired, accountBusy := h.acquireResponsesAccountSlot(c, apiKey.GroupID, "", selection, stream, &streamStarted, reqLog)
+		if !acquired {
+			if accountBusy {
+				failedAccountIDs[account.ID] = struct{}{}
+				if switchCount < maxAccountSwitches {
+					switchCount++
+					continue
+				}
+				h.errorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "All accounts are busy, please retry later")
+			}
+			return
+		}
+
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+		forwardStart := time.Now()
+
+		result, err := forward(c.Request.Context(), c, account)
+
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+		service.SetOpsLatencyMs(c, service.OpsResponseLatencyMsKey, time.Since(forwardStart).Milliseconds())
+		if err != nil && result == nil {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				if failoverErr.RetryableOnSameAccount && sameAccountRetryCount[account.ID] < account.GetPoolModeRetryCount() {
+					sameAccountRetryCount[account.ID]++
+					if !sleepWithContext(c.Request.Context(), sameAccountRetryDelay) {
+						return
+					}
+					continue
+				}
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				service.RecordGatewayFailoverMetrics(service.PlatformOpenAI, failoverErr.StatusCode)
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				if switchCount >= maxAccountSwitches {
+					h.handleFailoverExhausted(c, failoverErr, false)
+					return
+				}
+				switchCount++
+				reqLog.Warn(component+".upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+					zap.Int("max_switches", maxAccountSwitches),
+				)
+				continue
+			}
+			wroteFallback := h.ensureForwardErrorResponse(c, false)
+			reqLog.Warn(component+".forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("fallback_error_response_written", wroteFallback),
+				zap.Error(err),
+			)
+			return
+		}
+		if err != nil {
+			// 音频已部分写出后上游中断：不再 failover，已输出部分仍记录用量
+			reqLog.Warn(component+".stream_interrupted", zap.Int64("account_id", account.ID), zap.Error(err))
+		}
+		h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, err == nil, nil)
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+		requestPayloadHash := service.HashUsageRequestPayload(payload)
+		inboundEndpoint := GetInboundEndpoint(c)
+		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
+
+		submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+				Result:             result,
+				APIKey:             apiKey,
+				User:               apiKey.User,
+				Account:            account,
+				Subscription:       subscription,
+				InboundEndpoint:    inboundEndpoint,
+				UpstreamEndpoint:   upstreamEndpoint,
+				UserAgent:          userAgent,
+				IPAddress:          clientIP,
+				RequestPayloadHash: requestPayloadHash,
+				APIKeyService:      h.apiKeyService,
+			}); err != nil {
+				logger.L().With(
+					zap.String("component", "handler.openai_gateway.audio"),
+					zap.Int64("user_id", subject.UserID),
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.Any("group_id", apiKey.GroupID),
+					zap.String("model", reqModel),
+					zap.Int64("account_id", account.ID),
+				).Error(component+".record_usage_failed", zap.Error(err))
+			}
+		})
+		reqLog.Debug(component+".request_completed",
+			zap.Int64("account_id", account.ID),
+			zap.Int("switch_count", switchCount),
+		)
+		return
+	}
+}
@@ -131,6 +131,25 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	responseCache := beginResponseCache(c, h.responseCacheService, apiKey.Group, body, reqStream)
+	if entry := responseCache.lookup(c); entry != nil {
+		h.serveResponseCacheHit(c, responseCache, entry, apiKey, subscription, body)
+		return
+	}
+
+	// 严格预留模式：转发前按预估最大费用预留余额/额度，防止并发请求透支
+	reservation, err := h.gatewayService.ReserveRequestCost(c.Request.Context(), apiKey, subscription, reqModel, body)
+	if err != nil {
+		reqLog.Info("openai_chat_completions.billing_reservation_rejected", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.handleStreamingAwareError(c, status, code, message, streamStarted)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
 	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
 	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
 
@@ -272,6 +291,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 					}
 				}
 				h.gatewayService.RecordOpenAIAccountSwitch()
+				service.RecordGatewayFailoverMetrics(service.PlatformOpenAI, failoverErr.StatusCode)
 				failedAccountIDs[account.ID] = struct{}{}
 				lastFailoverErr = failoverErr
 				if switchCount >= maxAccountSwitches {
@@ -301,11 +321,14 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		} else {
 			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
 		}
+		if result != nil {
+			responseCache.store(c, newOpenAIResponseCacheEntry(result, account.ID))
+		}
 
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
 
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
 				Result:           result,
 				APIKey:           apiKey,
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// imagesRequestError Images 请求校验失败
+type imagesRequestError struct {
+	status  int
+	message string
+}
+
+// readImagesInput 读取并校验 OpenAI Images 请求（generations 为 JSON，edits 为 multipart），
+// 并应用分组模型别名。
+func readImagesInput(c *gin.Context, endpoint string, group *service.Group) (*service.ImageGenerationInput, *imagesRequestError) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			return nil, &imagesRequestError{http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit)}
+		}
+		return nil, &imagesRequestError{http.StatusBadRequest, "Failed to read request body"}
+	}
+	if len(body) == 0 {
+		return nil, &imagesRequestError{http.StatusBadRequest, "Request body is empty"}
+	}
+
+	contentType := c.GetHeader("Content-Type")
+	req, err := apicompat.ParseImagesRequest(contentType, body)
+	if err != nil {
+		return nil, &imagesRequestError{http.StatusBadRequest, "Failed to parse request body"}
+	}
+	if req.Model == "" {
+		return nil, &imagesRequestError{http.StatusBadRequest, "model is required"}
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, &imagesRequestError{http.StatusBadRequest, "prompt is required"}
+	}
+	if endpoint == service.ImagesEndpointEdits && len(req.Images) == 0 {
+		return nil, &imagesRequestError{http.StatusBadRequest, "image is required"}
+	}
+	if req.N < 0 || req.N > service.MaxImagesPerRequest {
+		return nil, &imagesRequestError{http.StatusBadRequest, "n must be between 1 and 10"}
+	}
+	switch req.ResponseFormat {
+	case "", service.ImagesResponseFormatB64JSON, service.ImagesResponseFormatURL:
+	default:
+		return nil, &imagesRequestError{http.StatusBadRequest, "response_format must be one of b64_json, url"}
+	}
+
+	input := &service.ImageGenerationInput{
+		Endpoint:    endpoint,
+		Body:        body,
+		ContentType: contentType,
+		Request:     req,
+		BodyModel:   req.Model,
+		BaseURL:     requestBaseURL(c),
+	}
+	if group != nil {
+		if resolved := group.ResolveModelAlias(req.Model); resolved != req.Model {
+			c.Set(gatewayUserOriginalModelKey, req.Model)
+			recordGroupResolved(c, req.Model, resolved, "group_alias")
+			req.Model = resolved
+		}
+	}
+	return input, nil
+}
+
+// requestBaseURL 网关对外地址（scheme://host），用于拼接 response_format=url 的图片地址
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if isRequestHTTPS(c) {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// imagesEndpointFromPath 根据路由判断 generations / edits
+func imagesEndpointFromPath(c *gin.Context) string {
+	if strings.HasSuffix(strings.TrimRight(c.Request.URL.Path, "/"), "/edits") {
+		return service.ImagesEndpointEdits
+	}
+	return service.ImagesEndpointGenerations
+}
+
+// toOpenAIImagesForwardResult 将 Images 转发结果转换为 OpenAI 计费结构
+func toOpenAIImagesForwardResult(result *service.ForwardResult) *service.OpenAIForwardResult {
+	return &service.OpenAIForwardResult{
+		RequestID: result.RequestID,
+		Usage: service.OpenAIUsage{
+			InputTokens:  result.Usage.InputTokens,
+			OutputTokens: result.Usage.OutputTokens,
+		},
+		Model:         result.Model,
+		UpstreamModel: result.UpstreamModel,
+		Duration:      result.Duration,
+		ImageCount:    result.ImageCount,
+		ImageSize:     result.ImageSize,
+	}
+}
+
+// Images handles OpenAI Images API requests for OpenAI platform groups.
+// POST /v1/images/generations
+// POST /v1/images/edits
+func (h *OpenAIGatewayHandler) Images(c *gin.Context) {
+	streamStarted := false
+	defer h.recoverResponsesPanic(c, &streamStarted)
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.openai_gateway.images",
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+
+	endpoint := imagesEndpointFromPath(c)
+	input, reqErr := readImagesInput(c, endpoint, apiKey.Group)
+	if reqErr != nil {
+		h.errorResponse(c, reqErr.status, "invalid_request_error", reqErr.message)
+		return
+	}
+	reqModel := input.Request.Model
+	startRequestTraceFromGin(c, c.Request.URL.Path, reqModel, false)
+
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.String("endpoint", endpoint))
+
+	setOpsRequestContext(c, reqModel, false, input.Body)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeSync))
+
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+	routingStart := time.Now()
+
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, false, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info("openai_images.billing_eligibility_check_failed", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.errorResponse(c, status, code, message)
+		return
+	}
+
+	// 严格预留模式：转发前按图片数量与尺寸预留余额/额度，防止并发请求透支
+	reservation, err := h.gatewayService.ReserveImageCost(c.Request.Context(), apiKey, subscription, reqModel, apicompat.ImageSizeTier(input.Request.Size), input.Request.N)
+	if err != nil {
+		reqLog.Info("openai_images.billing_reservation_rejected", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.errorResponse(c, status, code, message)
+		return
+	}
+	if reservation != nil {
+		c.Request = c.Request.WithContext(service.WithBillingReservation(c.Request.Context(), reservation))
+		defer reservation.ReleaseIfUnrecorded(context.Background())
+	}
+
+	maxAccountSwitches := h.maxAccountSwitches
+	switchCount := 0
+	failedAccountIDs := make(map[int64]struct{})
+	sameAccountRetryCount := make(map[int64]int)
+	var lastFailoverErr *service.UpstreamFailoverError
+
+	for {
+		recordSelectionStarted(c, reqModel, failedAccountIDs)
+		selection, _, err := h.gatewayService.SelectAccountWithScheduler(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			"",
+			reqModel,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportAny,
+		)
+		if err != nil {
+			reqLog.Warn("openai_images.account_select_failed",
+				zap.Error(err),
+				zap.Int("excluded_account_count", len(failedAccountIDs)),
+			)
+			if lastFailoverErr != nil {
+				h.handleFailoverExhausted(c, lastFailoverErr, false)
+			} else {
+				h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts support images")
+			}
+			return
+		}
+		if selection == nil || selection.Account == nil {
+			h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts")
+			return
+		}
+		account := selection.Account
+		// OAuth 账号无法调用 Images API：直接排除，不计入切换次数。
+		if !account.IsOpenAIApiKey() {
+			if selection.Acquired && selection.ReleaseFunc != nil {
+				selection.ReleaseFunc()
+			}
+			failedAccountIDs[account.ID] = struct{}{}
+			continue
+		}
+		recordSelectionResult(c, selection)
+		reqLog.Debug("openai_images.account_selected", zap.Int64("account_id", account.ID), zap.String("account_name", account.Name))
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+		setOpsSelectedAccountName(c, account.Name)
+
+		accountReleaseFunc, acquired, accountBusy := h.acquireResponsesAccountSlot(c, apiKey.GroupID, "", selection, false, &streamStarted, reqLog)
+		if !acquired {
+			if accountBusy {
+				failedAccountIDs[account.ID] = struct{}{}
+				if switchCount < maxAccountSwitches {
+					switchCount++
+					continue
+				}
+				h.errorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "All accounts are busy, please retry later")
+			}
+			return
+		}
+
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+		forwardStart := time.Now()
+
+		result, err := h.imageGenerationService.Forward(c.Request.Context(), c, account, input)
+
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+		service.SetOpsLatencyMs(c, service.OpsResponseLatencyMsKey, time.Since(forwardStart).Milliseconds())
+		if err != nil {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				if failoverErr.RetryableOnSameAccount && sameAccountRetryCount[account.ID] < account.GetPoolModeRetryCount() {
+					sameAccountRetryCount[account.ID]++
+					if !sleepWithContext(c.Request.Context(), sameAccountRetryDelay) {
+						return
+					}
+					continue
+				}
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				service.RecordGatewayFailoverMetrics(service.PlatformOpenAI, failoverErr.StatusCode)
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				if switchCount >= maxAccountSwitches {
+					h.handleFailoverExhausted(c, failoverErr, false)
+					return
+				}
+				switchCount++
+				reqLog.Warn("openai_images.upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+					zap.Int("max_switches", maxAccountSwitches),
+				)
+				continue
+			}
+			wroteFallback := h.ensureForwardErrorResponse(c, false)
+			reqLog.Warn("openai_images.forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("fallback_error_response_written", wroteFallback),
+				zap.Error(err),
+			)
+			return
+		}
+		h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+		requestPayloadHash := service.HashUsageRequestPayload(input.Body)
+		inboundEndpoint := GetInboundEndpoint(c)
+		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
+
+		submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+				Result:             toOpenAIImagesForwardResult(result),
+				APIKey:             apiKey,
+				User:               apiKey.User,
+				Account:            account,
+				Subscription:       subscription,
+				InboundEndpoint:    inboundEndpoint,
+				UpstreamEndpoint:   upstreamEndpoint,
+				UserAgent:          userAgent,
+				IPAddress:          clientIP,
+				RequestPayloadHash: requestPayloadHash,
+				APIKeyService:      h.apiKeyService,
+			}); err != nil {
+				logger.L().With(
+					zap.String("component", "handler.openai_gateway.images"),
+					zap.Int64("user_id", subject.UserID),
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.Any("group_id", apiKey.GroupID),
+					zap.String("model", reqModel),
+					zap.Int64("account_id", account.ID),
+				).Error("openai_images.record_usage_failed", zap.Error(err))
+			}
+		})
+		reqLog.Debug("openai_images.request_completed",
+			zap.Int64("account_id", account.ID),
+			zap.Int("image_count", result.ImageCount),
+			zap.Int("switch_count", switchCount),
+		)
+		return
+	}
+}
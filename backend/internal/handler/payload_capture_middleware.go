@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// payloadCaptureWriter 在首次写出响应时匹配抓取规则（此时鉴权、模型与状态码均已确定），
+// 命中后才开始缓存响应（含 SSE 流），未命中的请求不产生额外内存开销。
+type payloadCaptureWriter struct {
+	gin.ResponseWriter
+	c   *gin.Context
+	svc *service.PayloadCaptureService
+
+	decided   bool
+	rule      *service.OpsPayloadCaptureRule
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (w *payloadCaptureWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	w.rule = w.svc.Match(buildPayloadCaptureMatchInput(w.c, w.ResponseWriter.Status()))
+}
+
+func (w *payloadCaptureWriter) capture(n int, write func(remaining int)) {
+	w.decide()
+	if w.rule == nil || w.truncated {
+		return
+	}
+	remaining := w.limit - w.buf.Len()
+	if n > remaining {
+		w.truncated = true
+		n = remaining
+	}
+	if n > 0 {
+		write(n)
+	}
+}
+
+func (w *payloadCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(len(b), func(n int) { _, _ = w.buf.Write(b[:n]) })
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *payloadCaptureWriter) WriteString(s string) (int, error) {
+	w.capture(len(s), func(n int) { _, _ = w.buf.WriteString(s[:n]) })
+	return w.ResponseWriter.WriteString(s)
+}
+
+// PayloadCaptureMiddleware 按运维配置的规则抽样抓取请求/响应载荷，并在请求追踪中记录 capture_id。
+// 需注册在 OpsErrorLoggerMiddleware 之后，以便追踪在最终落库前带上抓取事件。
+func PayloadCaptureMiddleware(svc *service.PayloadCaptureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !svc.Active() {
+			c.Next()
+			return
+		}
+
+		originalWriter := c.Writer
+		w := &payloadCaptureWriter{ResponseWriter: originalWriter, c: c, svc: svc, limit: svc.MaxBodyBytes()}
+		c.Writer = w
+		c.Next()
+		if c.Writer == w {
+			c.Writer = originalWriter
+		}
+
+		// 无响应体的请求（如客户端提前断开）在结束时再匹配一次
+		w.decide()
+		if w.rule == nil {
+			return
+		}
+		input := buildPayloadCaptureInput(c, w)
+		id, ok := svc.Submit(input)
+		if !ok {
+			return
+		}
+		service.AppendOpsRequestTraceEvent(c.Request.Context(), service.OpsPayloadCaptureTraceEvent, time.Now().UTC(), map[string]any{
+			"phase":      "capture",
+			"capture_id": id,
+			"rule":       w.rule.Name,
+		})
+	}
+}
+
+func buildPayloadCaptureMatchInput(c *gin.Context, status int) *service.OpsPayloadCaptureMatchInput {
+	in := &service.OpsPayloadCaptureMatchInput{StatusCode: status}
+	if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+		in.APIKeyID = apiKey.ID
+		if apiKey.User != nil {
+			in.UserID = apiKey.User.ID
+		}
+		if apiKey.GroupID != nil {
+			in.GroupID = *apiKey.GroupID
+		}
+	}
+	if v, ok := c.Get(opsModelKey); ok {
+		in.Model, _ = v.(string)
+	}
+	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
+		if events, ok := v.([]*service.OpsUpstreamErrorEvent); ok && len(events) > 0 {
+			in.UpstreamError = true
+		}
+	}
+	if !in.UpstreamError {
+		if v, ok := c.Get(service.OpsUpstreamStatusCodeKey); ok {
+			if code, ok := v.(int); ok && code > 0 {
+				in.UpstreamError = true
+			}
+		}
+	}
+	return in
+}
+
+func buildPayloadCaptureInput(c *gin.Context, w *payloadCaptureWriter) *service.OpsPayloadCaptureInput {
+	in := &service.OpsPayloadCaptureInput{
+		RuleName:          w.rule.Name,
+		Method:            c.Request.Method,
+		StatusCode:        w.ResponseWriter.Status(),
+		RequestHeaders:    c.Request.Header.Clone(),
+		ResponseHeaders:   w.Header().Clone(),
+		ResponseBody:      w.buf.Bytes(),
+		ResponseTruncated: w.truncated,
+		CapturedAt:        time.Now().UTC(),
+	}
+	if c.Request.URL != nil {
+		in.RequestPath = c.Request.URL.Path
+	}
+	if id, _ := c.Request.Context().Value(ctxkey.ClientRequestID).(string); id != "" {
+		in.ClientRequestID = strings.TrimSpace(id)
+	}
+	if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+		in.APIKeyID = &apiKey.ID
+		if apiKey.User != nil {
+			in.UserID = &apiKey.User.ID
+		}
+		in.GroupID = apiKey.GroupID
+		if apiKey.Group != nil {
+			in.Platform = strings.TrimSpace(apiKey.Group.Platform)
+		}
+	}
+	if in.Platform == "" {
+		if platform, _ := c.Request.Context().Value(ctxkey.Platform).(string); platform != "" {
+			in.Platform = strings.TrimSpace(platform)
+		}
+	}
+	if v, ok := c.Get(opsModelKey); ok {
+		in.Model, _ = v.(string)
+	}
+	if v, ok := c.Get(opsStreamKey); ok {
+		in.Stream, _ = v.(bool)
+	}
+	if v, ok := c.Get(opsRequestBodyKey); ok {
+		in.RequestBody, _ = v.([]byte)
+	}
+	if v, ok := c.Get(service.OpsUpstreamRequestBodyKey); ok {
+		switch body := v.(type) {
+		case []byte:
+			in.UpstreamRequestBody = body
+		case string:
+			in.UpstreamRequestBody = []byte(body)
+		}
+	}
+	return in
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const paymentCallbackMaxBodyBytes = 1 << 20
+
+// PaymentHandler handles self-service payment orders and provider callbacks
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+// NewPaymentHandler creates a new PaymentHandler
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// CreatePaymentOrderRequest represents the create order request payload
+type CreatePaymentOrderRequest struct {
+	Kind     string  `json:"kind" binding:"required,oneof=balance subscription"`
+	Amount   float64 `json:"amount"`
+	PlanID   int64   `json:"plan_id"`
+	Provider string  `json:"provider" binding:"required"`
+	Channel  string  `json:"channel"`
+}
+
+// GetOptions returns enabled providers, top-up limits and purchasable plans.
+// GET /api/v1/payments/options
+func (h *PaymentHandler) GetOptions(c *gin.Context) {
+	options, err := h.paymentService.GetOptions(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, options)
+}
+
+// CreateOrder creates a payment order and returns its checkout URL.
+// POST /api/v1/payments/orders
+func (h *PaymentHandler) CreateOrder(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreatePaymentOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	executeUserIdempotentJSON(c, "user.payments.orders.create", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		order, err := h.paymentService.CreateOrder(ctx, subject.UserID, &service.CreatePaymentOrderInput{
+			Kind:     req.Kind,
+			Amount:   req.Amount,
+			PlanID:   req.PlanID,
+			Provider: req.Provider,
+			Channel:  req.Channel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.PaymentOrderFromService(order), nil
+	})
+}
+
+// ListOrders returns the current user's payment orders.
+// GET /api/v1/payments/orders?status=
+func (h *PaymentHandler) ListOrders(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	orders, result, err := h.paymentService.ListUserOrders(c.Request.Context(), subject.UserID, pagination.PaginationParams{Page: page, PageSize: pageSize}, c.Query("status"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.PaymentOrder, 0, len(orders))
+	for i := range orders {
+		out = append(out, *dto.PaymentOrderFromService(&orders[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// GetOrder returns one of the current user's payment orders.
+// GET /api/v1/payments/orders/:order_no
+func (h *PaymentHandler) GetOrder(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	order, err := h.paymentService.GetUserOrder(c.Request.Context(), subject.UserID, c.Param("order_no"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.PaymentOrderFromService(order))
+}
+
+// CancelOrder cancels one of the current user's pending orders.
+// POST /api/v1/payments/orders/:order_no/cancel
+func (h *PaymentHandler) CancelOrder(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.paymentService.CancelUserOrder(c.Request.Context(), subject.UserID, c.Param("order_no")); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Order canceled"})
+}
+
+// Callback receives asynchronous payment notifications from a provider.
+// GET/POST /api/v1/payments/callback/:provider
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, paymentCallbackMaxBodyBytes))
+	if err != nil {
+		response.BadRequest(c, "Failed to read request body")
+		return
+	}
+
+	req := &service.PaymentCallbackRequest{
+		Method: c.Request.Method,
+		Header: c.Request.Header,
+		Query:  c.Request.URL.Query(),
+		Body:   body,
+	}
+	if strings.HasPrefix(c.ContentType(), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			req.Form = form
+		}
+	}
+
+	contentType, ack, err := h.paymentService.HandleCallback(c.Request.Context(), c.Param("provider"), req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Data(http.StatusOK, contentType, []byte(ack))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostpaidStatementHandler handles the user's postpaid statements
+type PostpaidStatementHandler struct {
+	postpaidBillingService *service.PostpaidBillingService
+}
+
+// NewPostpaidStatementHandler creates a new PostpaidStatementHandler
+func NewPostpaidStatementHandler(postpaidBillingService *service.PostpaidBillingService) *PostpaidStatementHandler {
+	return &PostpaidStatementHandler{postpaidBillingService: postpaidBillingService}
+}
+
+// List returns the current user's postpaid statements
+// GET /api/v1/user/postpaid-statements?status=
+func (h *PostpaidStatementHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	filter := service.PostpaidStatementFilter{UserID: subject.UserID, Status: strings.TrimSpace(c.Query("status"))}
+	statements, result, err := h.postpaidBillingService.ListStatements(c.Request.Context(), pagination.PaginationParams{Page: page, PageSize: pageSize}, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.PostpaidStatement, 0, len(statements))
+	for i := range statements {
+		out = append(out, *dto.PostpaidStatementFromService(&statements[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// GetByID returns one of the current user's statements with its line items
+// GET /api/v1/user/postpaid-statements/:id
+func (h *PostpaidStatementHandler) GetByID(c *gin.Context) {
+	statement, ok := h.loadOwnStatement(c)
+	if !ok {
+		return
+	}
+	response.Success(c, dto.PostpaidStatementFromService(statement))
+}
+
+// Download renders one of the current user's statements as HTML or PDF
+// GET /api/v1/user/postpaid-statements/:id/document?format=html|pdf
+func (h *PostpaidStatementHandler) Download(c *gin.Context) {
+	statement, ok := h.loadOwnStatement(c)
+	if !ok {
+		return
+	}
+	doc, err := h.postpaidBillingService.RenderStatement(statement, c.Query("format"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+doc.Filename+`"`)
+	c.Data(http.StatusOK, doc.ContentType, doc.Content)
+}
+
+func (h *PostpaidStatementHandler) loadOwnStatement(c *gin.Context) (*service.PostpaidStatement, bool) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return nil, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid statement ID")
+		return nil, false
+	}
+	statement, err := h.postpaidBillingService.GetUserStatement(c.Request.Context(), subject.UserID, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return nil, false
+	}
+	return statement, true
+}
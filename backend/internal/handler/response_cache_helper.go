@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// responseCacheRequest 单次请求的响应缓存上下文：
+// 命中时直接回放缓存；未命中时旁路记录写给客户端的响应，转发成功后写入缓存。
+type responseCacheRequest struct {
+	svc         *service.ResponseCacheService
+	group       *service.Group
+	fingerprint string
+	stream      bool
+	writer      *responseCaptureWriter
+}
+
+// beginResponseCache 计算请求指纹；分组未开启缓存或请求不可缓存（非确定性）时返回 nil。
+func beginResponseCache(c *gin.Context, svc *service.ResponseCacheService, group *service.Group, body []byte, stream bool) *responseCacheRequest {
+	if !svc.Enabled(group) {
+		return nil
+	}
+	fingerprint, err := service.ResponseCacheFingerprint(GetInboundEndpoint(c), body)
+	if err != nil {
+		return nil
+	}
+	return &responseCacheRequest{svc: svc, group: group, fingerprint: fingerprint, stream: stream}
+}
+
+// lookup 查询缓存；未命中时开始记录响应，并设置 miss 响应头。
+func (r *responseCacheRequest) lookup(c *gin.Context) *service.ResponseCacheEntry {
+	if r == nil {
+		return nil
+	}
+	if entry := r.svc.Get(c.Request.Context(), r.group, r.fingerprint); entry != nil && entry.Stream == r.stream {
+		return entry
+	}
+	c.Header(service.ResponseCacheHeader, "miss")
+	r.writer = &responseCaptureWriter{ResponseWriter: c.Writer, limit: r.svc.MaxEntryBytes()}
+	c.Writer = r.writer
+	return nil
+}
+
+// store 转发成功后写入缓存；仅缓存完整写出的 200 响应。
+func (r *responseCacheRequest) store(c *gin.Context, entry *service.ResponseCacheEntry) {
+	if r == nil || r.writer == nil || entry == nil {
+		return
+	}
+	if r.writer.Status() != http.StatusOK || r.writer.overflow || r.writer.failed || r.writer.buf.Len() == 0 {
+		return
+	}
+	entry.StatusCode = http.StatusOK
+	entry.ContentType = r.writer.Header().Get("Content-Type")
+	entry.Body = bytes.Clone(r.writer.buf.Bytes())
+	entry.Stream = r.stream
+	r.svc.Store(context.WithoutCancel(c.Request.Context()), r.group, r.fingerprint, entry)
+}
+
+// markHit 回放缓存响应，并在请求 context 中标记缓存命中，供异步计费按命中倍率折算。
+func (r *responseCacheRequest) markHit(c *gin.Context, entry *service.ResponseCacheEntry) {
+	writeResponseCacheEntry(c, entry)
+	c.Request = c.Request.WithContext(service.WithResponseCacheHit(c.Request.Context(), &service.ResponseCacheHitInfo{
+		Multiplier: r.svc.HitMultiplier(r.group),
+	}))
+}
+
+// writeResponseCacheEntry 按原协议回放缓存响应：流式响应逐个 SSE 事件写出并 flush。
+func writeResponseCacheEntry(c *gin.Context, entry *service.ResponseCacheEntry) {
+	c.Header(service.ResponseCacheHeader, "hit")
+	if !entry.Stream {
+		c.Data(entry.StatusCode, entry.ContentType, entry.Body)
+		return
+	}
+
+	contentType := entry.ContentType
+	if contentType == "" {
+		contentType = "text/event-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(entry.StatusCode)
+
+	body := entry.Body
+	for len(body) > 0 {
+		end := bytes.Index(body, []byte("\n\n"))
+		if end < 0 {
+			end = len(body)
+		} else {
+			end += 2
+		}
+		if _, err := c.Writer.Write(body[:end]); err != nil {
+			return
+		}
+		c.Writer.Flush()
+		body = body[end:]
+	}
+}
+
+// responseCaptureWriter 旁路记录写给客户端的响应体（超过上限或写失败后停止记录）。
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+	failed   bool
+}
+
+func (w *responseCaptureWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.capture(data[:n], err)
+	return n, err
+}
+
+func (w *responseCaptureWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.capture([]byte(s[:n]), err)
+	return n, err
+}
+
+func (w *responseCaptureWriter) capture(data []byte, err error) {
+	if err != nil {
+		w.failed = true
+	}
+	if w.overflow || w.failed {
+		return
+	}
+	if w.buf.Len()+len(data) > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(data)
+}
+
+// serveResponseCacheHit 回放缓存的 Anthropic/Gemini 兼容响应并记录使用量。
+func (h *GatewayHandler) serveResponseCacheHit(c *gin.Context, rc *responseCacheRequest, entry *service.ResponseCacheEntry, apiKey *service.APIKey, subscription *service.UserSubscription, body []byte) {
+	startTime := time.Now()
+	rc.markHit(c, entry)
+
+	result := &service.ForwardResult{
+		Usage: service.ClaudeUsage{
+			InputTokens:              entry.InputTokens,
+			OutputTokens:             entry.OutputTokens,
+			CacheCreationInputTokens: entry.CacheCreationTokens,
+			CacheReadInputTokens:     entry.CacheReadTokens,
+		},
+		Model:         entry.Model,
+		UpstreamModel: entry.UpstreamModel,
+		Stream:        entry.Stream,
+		Duration:      time.Since(startTime),
+	}
+	// 缓存命中不经过上游账号，仅沿用生成该响应的账号 ID 作为使用记录归属
+	account := &service.Account{ID: entry.AccountID}
+	userAgent := c.GetHeader("User-Agent")
+	clientIP := ip.GetClientIP(c)
+	inboundEndpoint := GetInboundEndpoint(c)
+	requestPayloadHash := service.HashUsageRequestPayload(body)
+
+	submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
+		if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
+			Result:             result,
+			APIKey:             apiKey,
+			User:               apiKey.User,
+			Account:            account,
+			Subscription:       subscription,
+			InboundEndpoint:    inboundEndpoint,
+			UserAgent:          userAgent,
+			IPAddress:          clientIP,
+			RequestPayloadHash: requestPayloadHash,
+			APIKeyService:      h.apiKeyService,
+		}); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.gateway.response_cache"),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.Any("group_id", apiKey.GroupID),
+				zap.String("model", entry.Model),
+			).Error("gateway.record_usage_failed", zap.Error(err))
+		}
+	})
+}
+
+// serveResponseCacheHit 回放缓存的 OpenAI 兼容响应并记录使用量。
+func (h *OpenAIGatewayHandler) serveResponseCacheHit(c *gin.Context, rc *responseCacheRequest, entry *service.ResponseCacheEntry, apiKey *service.APIKey, subscription *service.UserSubscription, body []byte) {
+	startTime := time.Now()
+	rc.markHit(c, entry)
+
+	result := &service.OpenAIForwardResult{
+		Usage: service.OpenAIUsage{
+			InputTokens:              entry.InputTokens,
+			OutputTokens:             entry.OutputTokens,
+			CacheCreationInputTokens: entry.CacheCreationTokens,
+			CacheReadInputTokens:     entry.CacheReadTokens,
+		},
+		Model:         entry.Model,
+		BillingModel:  entry.BillingModel,
+		UpstreamModel: entry.UpstreamModel,
+		Stream:        entry.Stream,
+		Duration:      time.Since(startTime),
+	}
+	// 缓存命中不经过上游账号，仅沿用生成该响应的账号 ID 作为使用记录归属
+	account := &service.Account{ID: entry.AccountID}
+	userAgent := c.GetHeader("User-Agent")
+	clientIP := ip.GetClientIP(c)
+	inboundEndpoint := GetInboundEndpoint(c)
+	requestPayloadHash := service.HashUsageRequestPayload(body)
+
+	submitBoundUsageRecordTask(c, h.submitUsageRecordTask, func(ctx context.Context) {
+		if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+			Result:             result,
+			APIKey:             apiKey,
+			User:               apiKey.User,
+			Account:            account,
+			Subscription:       subscription,
+			InboundEndpoint:    inboundEndpoint,
+			UserAgent:          userAgent,
+			IPAddress:          clientIP,
+			RequestPayloadHash: requestPayloadHash,
+			APIKeyService:      h.apiKeyService,
+		}); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.openai_gateway.response_cache"),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.Any("group_id", apiKey.GroupID),
+				zap.String("model", entry.Model),
+			).Error("openai.record_usage_failed", zap.Error(err))
+		}
+	})
+}
+
+func newGatewayResponseCacheEntry(result *service.ForwardResult, accountID int64) *service.ResponseCacheEntry {
+	return &service.ResponseCacheEntry{
+		Model:               result.Model,
+		UpstreamModel:       result.UpstreamModel,
+		AccountID:           accountID,
+		InputTokens:         result.Usage.InputTokens,
+		OutputTokens:        result.Usage.OutputTokens,
+		CacheCreationTokens: result.Usage.CacheCreationInputTokens,
+		CacheReadTokens:     result.Usage.CacheReadInputTokens,
+	}
+}
+
+func newOpenAIResponseCacheEntry(result *service.OpenAIForwardResult, accountID int64) *service.ResponseCacheEntry {
+	return &service.ResponseCacheEntry{
+		Model:               result.Model,
+		BillingModel:        result.BillingModel,
+		UpstreamModel:       result.UpstreamModel,
+		AccountID:           accountID,
+		InputTokens:         result.Usage.InputTokens,
+		OutputTokens:        result.Usage.OutputTokens,
+		CacheCreationTokens: result.Usage.CacheCreationInputTokens,
+		CacheReadTokens:     result.Usage.CacheReadInputTokens,
+	}
+}
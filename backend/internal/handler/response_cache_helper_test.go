@@ -0,0 +1,71 @@
+//go:build unit
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteResponseCacheEntry_ReplaysStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	body := "event: message_start\ndata: {}\n\nevent: message_stop\ndata: {}\n\n"
+	writeResponseCacheEntry(c, &service.ResponseCacheEntry{StatusCode: http.StatusOK, Stream: true, Body: []byte(body)})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "hit", rec.Header().Get(service.ResponseCacheHeader))
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	require.Equal(t, body, rec.Body.String())
+	require.True(t, rec.Flushed)
+}
+
+func TestWriteResponseCacheEntry_NonStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	writeResponseCacheEntry(c, &service.ResponseCacheEntry{StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte(`{"id":"msg_1"}`)})
+
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"id":"msg_1"}`, rec.Body.String())
+}
+
+func TestResponseCaptureWriter_StopsAtLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	w := &responseCaptureWriter{ResponseWriter: c.Writer, limit: 8}
+	_, _ = w.WriteString("abcd")
+	_, _ = w.Write([]byte("efgh"))
+	require.Equal(t, "abcdefgh", w.buf.String())
+	require.False(t, w.overflow)
+
+	_, _ = w.Write([]byte("i"))
+	require.True(t, w.overflow)
+	require.Zero(t, w.buf.Len())
+	require.Equal(t, "abcdefghi", rec.Body.String(), "client output must not be affected by capture limit")
+}
+
+func TestBeginResponseCache_DisabledOrNonDeterministic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	group := &service.Group{ID: 1, ResponseCacheEnabled: true}
+	body := []byte(`{"model":"m","temperature":0}`)
+
+	require.Nil(t, beginResponseCache(c, nil, group, body, false))
+	require.Nil(t, beginResponseCache(c, service.NewResponseCacheService(nil, nil), group, body, false))
+
+	var rc *responseCacheRequest
+	require.Nil(t, rc.lookup(c))
+	rc.store(c, &service.ResponseCacheEntry{})
+}
@@ -933,7 +933,6 @@ func (r *stubUserRepoForHandler) List(context.Context, pagination.PaginationPara
 func (r *stubUserRepoForHandler) ListWithFilters(context.Context, pagination.PaginationParams, service.UserListFilters) ([]service.User, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
-func (r *stubUserRepoForHandler) UpdateBalance(context.Context, int64, float64) error { return nil }
 func (r *stubUserRepoForHandler) DeductBalance(context.Context, int64, float64) error { return nil }
 func (r *stubUserRepoForHandler) UpdateConcurrency(context.Context, int64, int) error { return nil }
 func (r *stubUserRepoForHandler) ExistsByEmail(context.Context, string) (bool, error) {
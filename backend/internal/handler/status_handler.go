@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusHandler handles the public status page and user-facing SLA reports
+type StatusHandler struct {
+	opsService *service.OpsService
+}
+
+// NewStatusHandler creates a new StatusHandler
+func NewStatusHandler(opsService *service.OpsService) *StatusHandler {
+	return &StatusHandler{opsService: opsService}
+}
+
+// GetPublicStatus returns the sanitized public service status.
+// GET /api/v1/status
+func (h *StatusHandler) GetPublicStatus(c *gin.Context) {
+	if h.opsService == nil {
+		response.ErrorFrom(c, service.ErrStatusPageDisabled)
+		return
+	}
+	status, err := h.opsService.GetPublicStatus(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// GetSLAReport returns the current user's SLA report for a calendar month.
+// GET /api/v1/user/sla?month=YYYY-MM&group_id=
+func (h *StatusHandler) GetSLAReport(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	if h.opsService == nil {
+		response.ErrorFrom(c, service.ErrOpsDisabled)
+		return
+	}
+	groupID, ok := parseOptionalGroupIDQuery(c)
+	if !ok {
+		return
+	}
+
+	userID := subject.UserID
+	report, err := h.opsService.GetCustomerSLAReport(c.Request.Context(), &service.OpsCustomerSLAQuery{
+		UserID:  &userID,
+		GroupID: groupID,
+		Month:   c.Query("month"),
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// GetSLAHistory returns the current user's SLA reports for the most recent months.
+// GET /api/v1/user/sla/history?months=3&group_id=
+func (h *StatusHandler) GetSLAHistory(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	if h.opsService == nil {
+		response.ErrorFrom(c, service.ErrOpsDisabled)
+		return
+	}
+	groupID, ok := parseOptionalGroupIDQuery(c)
+	if !ok {
+		return
+	}
+
+	months := 0
+	if raw := strings.TrimSpace(c.Query("months")); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			response.BadRequest(c, "Invalid months")
+			return
+		}
+		months = v
+	}
+
+	userID := subject.UserID
+	reports, err := h.opsService.GetCustomerSLAHistory(c.Request.Context(), &userID, groupID, months)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, reports)
+}
+
+func parseOptionalGroupIDQuery(c *gin.Context) (*int64, bool) {
+	raw := strings.TrimSpace(c.Query("group_id"))
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid group_id")
+		return nil, false
+	}
+	return &id, true
+}
@@ -25,6 +25,8 @@ func ProvideAdminHandlers(
 	promoHandler *admin.PromoHandler,
 	settingHandler *admin.SettingHandler,
 	opsHandler *admin.OpsHandler,
+	opsNotificationHandler *admin.OpsNotificationHandler,
+	opsPayloadCaptureHandler *admin.OpsPayloadCaptureHandler,
 	systemHandler *admin.SystemHandler,
 	subscriptionHandler *admin.SubscriptionHandler,
 	subscriptionPlanHandler *admin.SubscriptionPlanHandler,
@@ -36,6 +38,9 @@ func ProvideAdminHandlers(
 	apiKeyHandler *admin.AdminAPIKeyHandler,
 	toolsHandler *admin.ToolsHandler,
 	scheduledTestHandler *admin.ScheduledTestHandler,
+	paymentHandler *admin.PaymentHandler,
+	balanceLedgerHandler *admin.BalanceLedgerHandler,
+	postpaidStatementHandler *admin.PostpaidStatementHandler,
 ) *AdminHandlers {
 	return &AdminHandlers{
 		Dashboard:             dashboardHandler,
@@ -54,6 +59,8 @@ func ProvideAdminHandlers(
 		Promo:                 promoHandler,
 		Setting:               settingHandler,
 		Ops:                   opsHandler,
+		OpsNotification:       opsNotificationHandler,
+		OpsPayloadCapture:     opsPayloadCaptureHandler,
 		System:                systemHandler,
 		Subscription:          subscriptionHandler,
 		SubscriptionPlan:      subscriptionPlanHandler,
@@ -65,6 +72,9 @@ func ProvideAdminHandlers(
 		APIKey:                apiKeyHandler,
 		Tools:                 toolsHandler,
 		ScheduledTest:         scheduledTestHandler,
+		Payment:               paymentHandler,
+		BalanceLedger:         balanceLedgerHandler,
+		PostpaidStatement:     postpaidStatementHandler,
 	}
 }
 
@@ -95,25 +105,37 @@ func ProvideHandlers(
 	settingHandler *SettingHandler,
 	totpHandler *TotpHandler,
 	providerHandler *ProviderHandler,
+	batchHandler *BatchHandler,
+	metricsHandler *MetricsHandler,
+	statusHandler *StatusHandler,
+	paymentHandler *PaymentHandler,
+	balanceLedgerHandler *BalanceLedgerHandler,
+	postpaidStatementHandler *PostpaidStatementHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
 	return &Handlers{
-		Auth:          authHandler,
-		User:          userHandler,
-		APIKey:        apiKeyHandler,
-		Usage:         usageHandler,
-		Redeem:        redeemHandler,
-		Subscription:  subscriptionHandler,
-		Announcement:  announcementHandler,
-		Admin:         adminHandlers,
-		Gateway:       gatewayHandler,
-		OpenAIGateway: openaiGatewayHandler,
-		SoraGateway:   soraGatewayHandler,
-		SoraClient:    soraClientHandler,
-		Setting:       settingHandler,
-		Totp:          totpHandler,
-		Provider:      providerHandler,
+		Auth:              authHandler,
+		User:              userHandler,
+		APIKey:            apiKeyHandler,
+		Usage:             usageHandler,
+		Redeem:            redeemHandler,
+		Subscription:      subscriptionHandler,
+		Announcement:      announcementHandler,
+		Admin:             adminHandlers,
+		Gateway:           gatewayHandler,
+		OpenAIGateway:     openaiGatewayHandler,
+		SoraGateway:       soraGatewayHandler,
+		SoraClient:        soraClientHandler,
+		Setting:           settingHandler,
+		Totp:              totpHandler,
+		Provider:          providerHandler,
+		Batch:             batchHandler,
+		Metrics:           metricsHandler,
+		Status:            statusHandler,
+		Payment:           paymentHandler,
+		BalanceLedger:     balanceLedgerHandler,
+		PostpaidStatement: postpaidStatementHandler,
 	}
 }
 
@@ -133,6 +155,12 @@ var ProviderSet = wire.NewSet(
 	NewTotpHandler,
 	ProvideSettingHandler,
 	NewProviderHandler,
+	NewBatchHandler,
+	NewMetricsHandler,
+	NewStatusHandler,
+	NewPaymentHandler,
+	NewBalanceLedgerHandler,
+	NewPostpaidStatementHandler,
 
 	// Admin handlers
 	admin.NewDashboardHandler,
@@ -151,6 +179,8 @@ var ProviderSet = wire.NewSet(
 	admin.NewPromoHandler,
 	admin.NewSettingHandler,
 	admin.NewOpsHandler,
+	admin.NewOpsNotificationHandler,
+	admin.NewOpsPayloadCaptureHandler,
 	ProvideSystemHandler,
 	admin.NewSubscriptionHandler,
 	admin.NewSubscriptionPlanHandler,
@@ -162,6 +192,9 @@ var ProviderSet = wire.NewSet(
 	admin.NewAdminAPIKeyHandler,
 	admin.NewToolsHandler,
 	admin.NewScheduledTestHandler,
+	admin.NewPaymentHandler,
+	admin.NewBalanceLedgerHandler,
+	admin.NewPostpaidStatementHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
@@ -0,0 +1,108 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ChatCompletionsToGeminiRequest converts a Chat Completions request into a
+// Gemini generateContent request. The conversion is chained through the
+// Responses format (CC → Responses → Gemini), like the Anthropic path;
+// parameters the Responses format cannot carry (stop, exact max_tokens) are
+// applied directly.
+func ChatCompletionsToGeminiRequest(req *ChatCompletionsRequest) (*GeminiRequest, error) {
+	responsesReq, err := ChatCompletionsToResponses(req)
+	if err != nil {
+		return nil, fmt.Errorf("convert chat completions to responses: %w", err)
+	}
+	out, err := ResponsesToGeminiRequest(responsesReq)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTokens := 0
+	if req.MaxTokens != nil {
+		maxTokens = *req.MaxTokens
+	}
+	if req.MaxCompletionTokens != nil {
+		maxTokens = *req.MaxCompletionTokens
+	}
+	stop, err := parseChatStop(req.Stop)
+	if err != nil {
+		return nil, fmt.Errorf("parse stop: %w", err)
+	}
+	if maxTokens > 0 || len(stop) > 0 {
+		if out.GenerationConfig == nil {
+			out.GenerationConfig = &GeminiGenerationConfig{}
+		}
+		if maxTokens > 0 {
+			out.GenerationConfig.MaxOutputTokens = maxTokens
+		}
+		out.GenerationConfig.StopSequences = stop
+	}
+	return out, nil
+}
+
+// parseChatStop parses the Chat Completions stop field (string or []string).
+func parseChatStop(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if s == "" {
+			return nil, nil
+		}
+		return []string{s}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GeminiToChatCompletions converts a Gemini generateContent response into a
+// Chat Completions response (Gemini → Responses → CC).
+func GeminiToChatCompletions(resp *GeminiResponse, model string) *ChatCompletionsResponse {
+	return ResponsesToChatCompletions(GeminiToResponsesResponse(resp, model), model)
+}
+
+// GeminiEventToChatState tracks state for converting Gemini stream chunks
+// into Chat Completions chunks, chaining the Gemini → Responses and
+// Responses → CC state machines.
+type GeminiEventToChatState struct {
+	responses *GeminiEventToResponsesState
+	chat      *ResponsesEventToChatState
+}
+
+// NewGeminiEventToChatState returns an initialised stream state.
+func NewGeminiEventToChatState(model string, includeUsage bool) *GeminiEventToChatState {
+	responses := NewGeminiEventToResponsesState()
+	responses.Model = model
+	chat := NewResponsesEventToChatState()
+	chat.Model = model
+	chat.IncludeUsage = includeUsage
+	return &GeminiEventToChatState{responses: responses, chat: chat}
+}
+
+// GeminiChunkToChatChunks converts a single Gemini stream chunk into zero or
+// more Chat Completions chunks.
+func GeminiChunkToChatChunks(chunk *GeminiResponse, state *GeminiEventToChatState) []ChatCompletionsChunk {
+	return state.toChatChunks(GeminiChunkToResponsesEvents(chunk, state.responses))
+}
+
+// FinalizeGeminiChatStream emits the finish chunk (and usage chunk when
+// requested) once the upstream stream ends. It is idempotent.
+func FinalizeGeminiChatStream(state *GeminiEventToChatState) []ChatCompletionsChunk {
+	chunks := state.toChatChunks(FinalizeGeminiResponsesStream(state.responses))
+	return append(chunks, FinalizeResponsesChatStream(state.chat)...)
+}
+
+func (s *GeminiEventToChatState) toChatChunks(events []ResponsesStreamEvent) []ChatCompletionsChunk {
+	var chunks []ChatCompletionsChunk
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToChatChunks(&events[i], s.chat)...)
+	}
+	return chunks
+}
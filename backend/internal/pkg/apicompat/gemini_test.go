@@ -0,0 +1,312 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// ResponsesToGeminiRequest tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesToGeminiRequest_SystemAndText(t *testing.T) {
+	maxTokens := 512
+	temp := 0.3
+	req := &ResponsesRequest{
+		Model:           "gemini-2.5-pro",
+		Instructions:    "Be brief.",
+		Input:           json.RawMessage(`[{"role":"system","content":"You are helpful."},{"role":"user","content":[{"type":"input_text","text":"Hi"}]},{"role":"assistant","content":[{"type":"output_text","text":"Hello!"}]},{"role":"user","content":"How are you?"}]`),
+		MaxOutputTokens: &maxTokens,
+		Temperature:     &temp,
+		Reasoning:       &ResponsesReasoning{Effort: "medium"},
+	}
+
+	out, err := ResponsesToGeminiRequest(req)
+	require.NoError(t, err)
+	require.NotNil(t, out.SystemInstruction)
+	require.Len(t, out.SystemInstruction.Parts, 2)
+	assert.Equal(t, "Be brief.", out.SystemInstruction.Parts[0].Text)
+	assert.Equal(t, "You are helpful.", out.SystemInstruction.Parts[1].Text)
+
+	require.Len(t, out.Contents, 3)
+	assert.Equal(t, "user", out.Contents[0].Role)
+	assert.Equal(t, "model", out.Contents[1].Role)
+	assert.Equal(t, "Hello!", out.Contents[1].Parts[0].Text)
+	assert.Equal(t, "How are you?", out.Contents[2].Parts[0].Text)
+
+	require.NotNil(t, out.GenerationConfig)
+	assert.Equal(t, 512, out.GenerationConfig.MaxOutputTokens)
+	assert.Equal(t, 0.3, *out.GenerationConfig.Temperature)
+	require.NotNil(t, out.GenerationConfig.ThinkingConfig)
+	assert.True(t, out.GenerationConfig.ThinkingConfig.IncludeThoughts)
+	assert.Equal(t, 4096, *out.GenerationConfig.ThinkingConfig.ThinkingBudget)
+}
+
+func TestResponsesToGeminiRequest_ToolsAndCalls(t *testing.T) {
+	req := &ResponsesRequest{
+		Model: "gemini-2.5-flash",
+		Input: json.RawMessage(`[
+			{"role":"user","content":"Weather in Paris and Rome?"},
+			{"type":"function_call","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"},
+			{"type":"function_call","call_id":"call_2","name":"get_weather","arguments":"{\"city\":\"Rome\"}"},
+			{"type":"function_call_output","call_id":"call_1","output":"Sunny"},
+			{"type":"function_call_output","call_id":"call_2","output":"{\"temp\":21}"}
+		]`),
+		Tools: []ResponsesTool{
+			{Type: "function", Name: "get_weather", Description: "Get weather", Parameters: json.RawMessage(`{"type":"object","additionalProperties":false,"properties":{"city":{"type":"string"}}}`)},
+			{Type: "web_search"},
+		},
+		ToolChoice: json.RawMessage(`{"type":"function","name":"get_weather"}`),
+	}
+
+	out, err := ResponsesToGeminiRequest(req)
+	require.NoError(t, err)
+
+	// 并行调用与多个结果分别合并为一条 model / user 消息
+	require.Len(t, out.Contents, 3)
+	calls := out.Contents[1]
+	assert.Equal(t, "model", calls.Role)
+	require.Len(t, calls.Parts, 2)
+	assert.Equal(t, "get_weather", calls.Parts[0].FunctionCall.Name)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(calls.Parts[0].FunctionCall.Args))
+	assert.Empty(t, calls.Parts[0].FunctionCall.ID)
+
+	results := out.Contents[2]
+	assert.Equal(t, "user", results.Role)
+	require.Len(t, results.Parts, 2)
+	assert.Equal(t, "get_weather", results.Parts[0].FunctionResponse.Name)
+	assert.JSONEq(t, `{"result":"Sunny"}`, string(results.Parts[0].FunctionResponse.Response))
+	assert.JSONEq(t, `{"temp":21}`, string(results.Parts[1].FunctionResponse.Response))
+
+	require.Len(t, out.Tools, 2)
+	require.Len(t, out.Tools[0].FunctionDeclarations, 1)
+	assert.JSONEq(t, `{"type":"OBJECT","properties":{"city":{"type":"STRING"}}}`, string(out.Tools[0].FunctionDeclarations[0].Parameters))
+	assert.NotNil(t, out.Tools[1].GoogleSearch)
+
+	require.NotNil(t, out.ToolConfig)
+	assert.Equal(t, "ANY", out.ToolConfig.FunctionCallingConfig.Mode)
+	assert.Equal(t, []string{"get_weather"}, out.ToolConfig.FunctionCallingConfig.AllowedFunctionNames)
+}
+
+func TestResponsesToGeminiRequest_Images(t *testing.T) {
+	req := &ResponsesRequest{
+		Model: "gemini-2.5-flash",
+		Input: json.RawMessage(`[{"role":"user","content":[
+			{"type":"input_text","text":"Compare"},
+			{"type":"input_image","image_url":"data:image/png;base64,iVBORw0KGgo="},
+			{"type":"input_image","image_url":"https://example.com/cat.webp?size=large"}
+		]}]`),
+	}
+
+	out, err := ResponsesToGeminiRequest(req)
+	require.NoError(t, err)
+	require.Len(t, out.Contents, 1)
+	parts := out.Contents[0].Parts
+	require.Len(t, parts, 3)
+	require.NotNil(t, parts[1].InlineData)
+	assert.Equal(t, "image/png", parts[1].InlineData.MimeType)
+	assert.Equal(t, "iVBORw0KGgo=", parts[1].InlineData.Data)
+	require.NotNil(t, parts[2].FileData)
+	assert.Equal(t, "image/webp", parts[2].FileData.MimeType)
+	assert.Equal(t, "https://example.com/cat.webp?size=large", parts[2].FileData.FileURI)
+}
+
+func TestResponsesToGeminiRequest_ToolChoiceStrings(t *testing.T) {
+	for choice, mode := range map[string]string{`"auto"`: "AUTO", `"required"`: "ANY", `"none"`: "NONE"} {
+		out, err := ResponsesToGeminiRequest(&ResponsesRequest{Input: json.RawMessage(`"hi"`), ToolChoice: json.RawMessage(choice)})
+		require.NoError(t, err)
+		require.NotNil(t, out.ToolConfig, choice)
+		assert.Equal(t, mode, out.ToolConfig.FunctionCallingConfig.Mode)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ChatCompletionsToGeminiRequest tests
+// ---------------------------------------------------------------------------
+
+func TestChatCompletionsToGeminiRequest(t *testing.T) {
+	maxTokens := 64
+	req := &ChatCompletionsRequest{
+		Model: "gemini-2.5-flash",
+		Messages: []ChatMessage{
+			{Role: "system", Content: json.RawMessage(`"You are helpful."`)},
+			{Role: "user", Content: json.RawMessage(`[{"type":"text","text":"What's this?"},{"type":"image_url","image_url":{"url":"data:image/jpeg;base64,/9j/4AAQ"}}]`)},
+			{Role: "assistant", ToolCalls: []ChatToolCall{{ID: "call_1", Type: "function", Function: ChatFunctionCall{Name: "lookup", Arguments: `{"q":"x"}`}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: json.RawMessage(`"found"`)},
+		},
+		MaxTokens: &maxTokens,
+		Stop:      json.RawMessage(`["END"]`),
+		Tools:     []ChatTool{{Type: "function", Function: &ChatFunction{Name: "lookup", Parameters: json.RawMessage(`{"type":"object"}`)}}},
+	}
+
+	out, err := ChatCompletionsToGeminiRequest(req)
+	require.NoError(t, err)
+	require.NotNil(t, out.SystemInstruction)
+	assert.Equal(t, "You are helpful.", out.SystemInstruction.Parts[0].Text)
+	require.Len(t, out.Contents, 3)
+	require.Len(t, out.Contents[0].Parts, 2)
+	assert.Equal(t, "image/jpeg", out.Contents[0].Parts[1].InlineData.MimeType)
+	assert.Equal(t, "lookup", out.Contents[1].Parts[0].FunctionCall.Name)
+	assert.Equal(t, "lookup", out.Contents[2].Parts[0].FunctionResponse.Name)
+
+	// max_tokens 不受 Responses 最小值限制，stop 直接映射
+	assert.Equal(t, 64, out.GenerationConfig.MaxOutputTokens)
+	assert.Equal(t, []string{"END"}, out.GenerationConfig.StopSequences)
+	require.Len(t, out.Tools, 1)
+}
+
+// ---------------------------------------------------------------------------
+// Gemini response conversion tests
+// ---------------------------------------------------------------------------
+
+func TestGeminiToResponsesResponse(t *testing.T) {
+	resp := &GeminiResponse{
+		ResponseID: "abc",
+		Candidates: []GeminiCandidate{{
+			Content: &GeminiContent{Role: "model", Parts: []GeminiPart{
+				{Text: "Thinking...", Thought: true},
+				{Text: "Hello "},
+				{Text: "world"},
+				{FunctionCall: &GeminiFunctionCall{Name: "lookup", Args: json.RawMessage(`{"q":"x"}`)}},
+			}},
+			FinishReason: "STOP",
+		}},
+		UsageMetadata: &GeminiUsageMetadata{PromptTokenCount: 100, CandidatesTokenCount: 20, ThoughtsTokenCount: 30, CachedContentTokenCount: 40},
+	}
+
+	out := GeminiToResponsesResponse(resp, "gemini-2.5-pro")
+	assert.Equal(t, "resp_abc", out.ID)
+	assert.Equal(t, "gemini-2.5-pro", out.Model)
+	assert.Equal(t, "completed", out.Status)
+	require.Len(t, out.Output, 3)
+	assert.Equal(t, "reasoning", out.Output[0].Type)
+	assert.Equal(t, "Thinking...", out.Output[0].Summary[0].Text)
+	assert.Equal(t, "function_call", out.Output[1].Type)
+	assert.Equal(t, "lookup", out.Output[1].Name)
+	assert.NotEmpty(t, out.Output[1].CallID)
+	assert.Equal(t, "message", out.Output[2].Type)
+	require.Len(t, out.Output[2].Content, 2)
+
+	require.NotNil(t, out.Usage)
+	assert.Equal(t, 100, out.Usage.InputTokens)
+	assert.Equal(t, 50, out.Usage.OutputTokens)
+	assert.Equal(t, 150, out.Usage.TotalTokens)
+	assert.Equal(t, 40, out.Usage.InputTokensDetails.CachedTokens)
+	assert.Equal(t, 30, out.Usage.OutputTokensDetails.ReasoningTokens)
+}
+
+func TestGeminiToResponsesResponse_MaxTokens(t *testing.T) {
+	out := GeminiToResponsesResponse(&GeminiResponse{Candidates: []GeminiCandidate{{FinishReason: "MAX_TOKENS"}}}, "m")
+	assert.Equal(t, "incomplete", out.Status)
+	assert.Equal(t, "max_output_tokens", out.IncompleteDetails.Reason)
+	require.Len(t, out.Output, 1)
+	assert.Equal(t, "message", out.Output[0].Type)
+}
+
+func TestGeminiToChatCompletions(t *testing.T) {
+	resp := &GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content: &GeminiContent{Parts: []GeminiPart{
+				{FunctionCall: &GeminiFunctionCall{Name: "lookup", Args: json.RawMessage(`{"q":"x"}`)}},
+			}},
+			FinishReason: "STOP",
+		}},
+		UsageMetadata: &GeminiUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5},
+	}
+
+	out := GeminiToChatCompletions(resp, "gemini-2.5-flash")
+	assert.Equal(t, "gemini-2.5-flash", out.Model)
+	require.Len(t, out.Choices, 1)
+	assert.Equal(t, "tool_calls", out.Choices[0].FinishReason)
+	require.Len(t, out.Choices[0].Message.ToolCalls, 1)
+	assert.Equal(t, `{"q":"x"}`, out.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, 15, out.Usage.TotalTokens)
+}
+
+// ---------------------------------------------------------------------------
+// Gemini streaming conversion tests
+// ---------------------------------------------------------------------------
+
+func TestGeminiChunkToResponsesEvents_Stream(t *testing.T) {
+	state := NewGeminiEventToResponsesState()
+	state.Model = "gemini-2.5-pro"
+
+	var events []ResponsesStreamEvent
+	events = append(events, GeminiChunkToResponsesEvents(&GeminiResponse{Candidates: []GeminiCandidate{{Content: &GeminiContent{Parts: []GeminiPart{{Text: "hmm", Thought: true}}}}}}, state)...)
+	events = append(events, GeminiChunkToResponsesEvents(&GeminiResponse{Candidates: []GeminiCandidate{{Content: &GeminiContent{Parts: []GeminiPart{{Text: "Hel"}}}}}}, state)...)
+	events = append(events, GeminiChunkToResponsesEvents(&GeminiResponse{Candidates: []GeminiCandidate{{Content: &GeminiContent{Parts: []GeminiPart{{Text: "lo"}}}, FinishReason: "STOP"}}, UsageMetadata: &GeminiUsageMetadata{PromptTokenCount: 7, CandidatesTokenCount: 2}}, state)...)
+	events = append(events, FinalizeGeminiResponsesStream(state)...)
+	assert.Nil(t, FinalizeGeminiResponsesStream(state))
+
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		"response.created",
+		"response.output_item.added",
+		"response.reasoning_summary_text.delta",
+		"response.reasoning_summary_text.done",
+		"response.output_item.done",
+		"response.output_item.added",
+		"response.output_text.delta",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.output_item.done",
+		"response.completed",
+	}, types)
+
+	for i, e := range events {
+		assert.Equal(t, i, e.SequenceNumber)
+	}
+	assert.Equal(t, "Hello", events[8].Text)
+	last := events[len(events)-1]
+	assert.Equal(t, "completed", last.Response.Status)
+	assert.Equal(t, 7, last.Response.Usage.InputTokens)
+	require.Len(t, last.Response.Output, 2)
+	assert.Equal(t, 1, events[5].OutputIndex)
+}
+
+func TestGeminiChunkToChatChunks_StreamWithToolCall(t *testing.T) {
+	state := NewGeminiEventToChatState("gemini-2.5-flash", true)
+
+	var chunks []ChatCompletionsChunk
+	chunks = append(chunks, GeminiChunkToChatChunks(&GeminiResponse{Candidates: []GeminiCandidate{{Content: &GeminiContent{Parts: []GeminiPart{{Text: "Let me check."}}}}}}, state)...)
+	chunks = append(chunks, GeminiChunkToChatChunks(&GeminiResponse{
+		Candidates:    []GeminiCandidate{{Content: &GeminiContent{Parts: []GeminiPart{{FunctionCall: &GeminiFunctionCall{Name: "lookup", Args: json.RawMessage(`{"q":"x"}`)}}}}, FinishReason: "STOP"}},
+		UsageMetadata: &GeminiUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, ThoughtsTokenCount: 3},
+	}, state)...)
+	chunks = append(chunks, FinalizeGeminiChatStream(state)...)
+	assert.Nil(t, FinalizeGeminiChatStream(state))
+
+	require.NotEmpty(t, chunks)
+	assert.Equal(t, "assistant", chunks[0].Choices[0].Delta.Role)
+
+	var text, args string
+	var finish string
+	for _, c := range chunks {
+		assert.Equal(t, "gemini-2.5-flash", c.Model)
+		for _, choice := range c.Choices {
+			if choice.Delta.Content != nil {
+				text += *choice.Delta.Content
+			}
+			for _, tc := range choice.Delta.ToolCalls {
+				args += tc.Function.Arguments
+			}
+			if choice.FinishReason != nil {
+				finish = *choice.FinishReason
+			}
+		}
+	}
+	assert.Equal(t, "Let me check.", text)
+	assert.Equal(t, `{"q":"x"}`, args)
+	assert.Equal(t, "tool_calls", finish)
+
+	usageChunk := chunks[len(chunks)-1]
+	require.NotNil(t, usageChunk.Usage)
+	assert.Equal(t, 10, usageChunk.Usage.PromptTokens)
+	assert.Equal(t, 8, usageChunk.Usage.CompletionTokens)
+}
@@ -0,0 +1,400 @@
+package apicompat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Non-streaming: GeminiResponse → ResponsesResponse
+// ---------------------------------------------------------------------------
+
+// GeminiToResponsesResponse converts a Gemini generateContent response into a
+// Responses API response. Thought parts become reasoning items, text parts are
+// merged into one assistant message and functionCall parts become
+// function_call items.
+func GeminiToResponsesResponse(resp *GeminiResponse, model string) *ResponsesResponse {
+	if model == "" {
+		model = resp.ModelVersion
+	}
+	out := &ResponsesResponse{
+		ID:     geminiResponsesID(resp.ResponseID),
+		Object: "response",
+		Model:  model,
+	}
+
+	var outputs []ResponsesOutput
+	var reasoningText strings.Builder
+	var msgParts []ResponsesContentPart
+	finishReason := ""
+
+	if len(resp.Candidates) > 0 {
+		cand := resp.Candidates[0]
+		finishReason = cand.FinishReason
+		if cand.Content != nil {
+			for _, part := range cand.Content.Parts {
+				switch {
+				case part.FunctionCall != nil:
+					outputs = append(outputs, geminiFunctionCallToResponsesOutput(part.FunctionCall))
+				case part.Thought:
+					reasoningText.WriteString(part.Text)
+				case part.Text != "":
+					msgParts = append(msgParts, ResponsesContentPart{Type: "output_text", Text: part.Text})
+				}
+			}
+		}
+	}
+
+	if reasoningText.Len() > 0 {
+		outputs = append([]ResponsesOutput{{
+			Type:    "reasoning",
+			ID:      generateItemID(),
+			Summary: []ResponsesSummary{{Type: "summary_text", Text: reasoningText.String()}},
+		}}, outputs...)
+	}
+	if len(msgParts) > 0 || len(outputs) == 0 {
+		if len(msgParts) == 0 {
+			msgParts = []ResponsesContentPart{{Type: "output_text", Text: ""}}
+		}
+		outputs = append(outputs, ResponsesOutput{
+			Type:    "message",
+			ID:      generateItemID(),
+			Role:    "assistant",
+			Content: msgParts,
+			Status:  "completed",
+		})
+	}
+	out.Output = outputs
+
+	out.Status, out.IncompleteDetails = geminiFinishReasonToResponsesStatus(finishReason)
+	out.Usage = geminiUsageToResponses(resp.UsageMetadata)
+	return out
+}
+
+// geminiFinishReasonToResponsesStatus maps Gemini finishReason to Responses status.
+func geminiFinishReasonToResponsesStatus(finishReason string) (string, *ResponsesIncompleteDetails) {
+	switch finishReason {
+	case "MAX_TOKENS":
+		return "incomplete", &ResponsesIncompleteDetails{Reason: "max_output_tokens"}
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "incomplete", &ResponsesIncompleteDetails{Reason: "content_filter"}
+	default:
+		return "completed", nil
+	}
+}
+
+// geminiUsageToResponses maps Gemini usageMetadata to Responses usage.
+// Thinking tokens are billed as output, so they are added to output_tokens
+// and reported in output_tokens_details.reasoning_tokens.
+func geminiUsageToResponses(u *GeminiUsageMetadata) *ResponsesUsage {
+	if u == nil {
+		return &ResponsesUsage{}
+	}
+	outputTokens := u.CandidatesTokenCount + u.ThoughtsTokenCount
+	usage := &ResponsesUsage{
+		InputTokens:  u.PromptTokenCount,
+		OutputTokens: outputTokens,
+		TotalTokens:  u.PromptTokenCount + outputTokens,
+	}
+	if u.CachedContentTokenCount > 0 {
+		usage.InputTokensDetails = &ResponsesInputTokensDetails{CachedTokens: u.CachedContentTokenCount}
+	}
+	if u.ThoughtsTokenCount > 0 {
+		usage.OutputTokensDetails = &ResponsesOutputTokensDetails{ReasoningTokens: u.ThoughtsTokenCount}
+	}
+	return usage
+}
+
+func geminiFunctionCallToResponsesOutput(fc *GeminiFunctionCall) ResponsesOutput {
+	args := "{}"
+	if len(fc.Args) > 0 && string(fc.Args) != "null" {
+		args = string(fc.Args)
+	}
+	return ResponsesOutput{
+		Type:      "function_call",
+		ID:        generateItemID(),
+		CallID:    geminiCallID(fc.ID),
+		Name:      fc.Name,
+		Arguments: args,
+		Status:    "completed",
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: GeminiResponse chunk → []ResponsesStreamEvent (stateful converter)
+// ---------------------------------------------------------------------------
+
+// GeminiEventToResponsesState tracks state for converting a sequence of
+// Gemini streamGenerateContent chunks into Responses SSE events.
+type GeminiEventToResponsesState struct {
+	ResponseID     string
+	Model          string
+	Created        int64
+	SequenceNumber int
+
+	// CreatedSent tracks whether response.created has been emitted.
+	CreatedSent bool
+	// CompletedSent tracks whether the terminal event has been emitted.
+	CompletedSent bool
+
+	// Current output tracking
+	OutputIndex     int
+	CurrentItemID   string
+	CurrentItemType string // "message" | "reasoning"
+	currentText     strings.Builder
+
+	// Outputs collects finished items for the terminal response.
+	Outputs []ResponsesOutput
+
+	// FinishReason and Usage are taken from the latest chunk carrying them.
+	FinishReason string
+	Usage        *GeminiUsageMetadata
+}
+
+// NewGeminiEventToResponsesState returns an initialised stream state.
+func NewGeminiEventToResponsesState() *GeminiEventToResponsesState {
+	return &GeminiEventToResponsesState{
+		Created: time.Now().Unix(),
+	}
+}
+
+// GeminiChunkToResponsesEvents converts a single Gemini stream chunk into
+// zero or more Responses SSE events, updating state as it goes.
+func GeminiChunkToResponsesEvents(chunk *GeminiResponse, state *GeminiEventToResponsesState) []ResponsesStreamEvent {
+	if state.CompletedSent {
+		return nil
+	}
+
+	var events []ResponsesStreamEvent
+	if !state.CreatedSent {
+		state.ResponseID = geminiResponsesID(chunk.ResponseID)
+		if state.Model == "" {
+			state.Model = chunk.ModelVersion
+		}
+		state.CreatedSent = true
+		events = append(events, makeGeminiResponsesEvent(state, "response.created", &ResponsesStreamEvent{
+			Response: &ResponsesResponse{
+				ID:     state.ResponseID,
+				Object: "response",
+				Model:  state.Model,
+				Status: "in_progress",
+				Output: []ResponsesOutput{},
+			},
+		}))
+	}
+
+	if chunk.UsageMetadata != nil {
+		state.Usage = chunk.UsageMetadata
+	}
+	if len(chunk.Candidates) == 0 {
+		return events
+	}
+	cand := chunk.Candidates[0]
+	if cand.FinishReason != "" {
+		state.FinishReason = cand.FinishReason
+	}
+	if cand.Content == nil {
+		return events
+	}
+
+	for _, part := range cand.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			events = append(events, closeCurrentGeminiResponsesItem(state)...)
+			events = append(events, geminiFunctionCallToResponsesEvents(part.FunctionCall, state)...)
+
+		case part.Thought:
+			if part.Text == "" {
+				continue
+			}
+			events = append(events, openGeminiResponsesItem(state, "reasoning")...)
+			state.currentText.WriteString(part.Text)
+			events = append(events, makeGeminiResponsesEvent(state, "response.reasoning_summary_text.delta", &ResponsesStreamEvent{
+				OutputIndex:  state.OutputIndex,
+				SummaryIndex: 0,
+				Delta:        part.Text,
+				ItemID:       state.CurrentItemID,
+			}))
+
+		case part.Text != "":
+			events = append(events, openGeminiResponsesItem(state, "message")...)
+			state.currentText.WriteString(part.Text)
+			events = append(events, makeGeminiResponsesEvent(state, "response.output_text.delta", &ResponsesStreamEvent{
+				OutputIndex:  state.OutputIndex,
+				ContentIndex: 0,
+				Delta:        part.Text,
+				ItemID:       state.CurrentItemID,
+			}))
+		}
+	}
+	return events
+}
+
+// FinalizeGeminiResponsesStream closes any open item and emits the terminal
+// response event. Gemini has no explicit stop event, so callers invoke this
+// once the upstream stream ends. It is idempotent.
+func FinalizeGeminiResponsesStream(state *GeminiEventToResponsesState) []ResponsesStreamEvent {
+	if state.CompletedSent {
+		return nil
+	}
+
+	var events []ResponsesStreamEvent
+	if !state.CreatedSent {
+		events = append(events, GeminiChunkToResponsesEvents(&GeminiResponse{}, state)...)
+	}
+	events = append(events, closeCurrentGeminiResponsesItem(state)...)
+
+	status, details := geminiFinishReasonToResponsesStatus(state.FinishReason)
+	eventType := "response.completed"
+	if status == "incomplete" {
+		eventType = "response.incomplete"
+	}
+	outputs := state.Outputs
+	if outputs == nil {
+		outputs = []ResponsesOutput{}
+	}
+	events = append(events, makeGeminiResponsesEvent(state, eventType, &ResponsesStreamEvent{
+		Response: &ResponsesResponse{
+			ID:                state.ResponseID,
+			Object:            "response",
+			Model:             state.Model,
+			Status:            status,
+			Output:            outputs,
+			Usage:             geminiUsageToResponses(state.Usage),
+			IncompleteDetails: details,
+		},
+	}))
+	state.CompletedSent = true
+	return events
+}
+
+// --- helper functions ---
+
+// openGeminiResponsesItem opens a message or reasoning item unless one of the
+// same type is already open.
+func openGeminiResponsesItem(state *GeminiEventToResponsesState, itemType string) []ResponsesStreamEvent {
+	if state.CurrentItemType == itemType {
+		return nil
+	}
+	events := closeCurrentGeminiResponsesItem(state)
+
+	state.CurrentItemID = generateItemID()
+	state.CurrentItemType = itemType
+	item := &ResponsesOutput{Type: itemType, ID: state.CurrentItemID}
+	if itemType == "message" {
+		item.Role = "assistant"
+		item.Status = "in_progress"
+	}
+	return append(events, makeGeminiResponsesEvent(state, "response.output_item.added", &ResponsesStreamEvent{
+		OutputIndex: state.OutputIndex,
+		Item:        item,
+	}))
+}
+
+func closeCurrentGeminiResponsesItem(state *GeminiEventToResponsesState) []ResponsesStreamEvent {
+	if state.CurrentItemType == "" {
+		return nil
+	}
+
+	text := state.currentText.String()
+	item := ResponsesOutput{Type: state.CurrentItemType, ID: state.CurrentItemID, Status: "completed"}
+	var events []ResponsesStreamEvent
+	switch state.CurrentItemType {
+	case "reasoning":
+		item.Summary = []ResponsesSummary{{Type: "summary_text", Text: text}}
+		events = append(events, makeGeminiResponsesEvent(state, "response.reasoning_summary_text.done", &ResponsesStreamEvent{
+			OutputIndex:  state.OutputIndex,
+			SummaryIndex: 0,
+			Text:         text,
+			ItemID:       state.CurrentItemID,
+		}))
+	case "message":
+		item.Role = "assistant"
+		item.Content = []ResponsesContentPart{{Type: "output_text", Text: text}}
+		events = append(events, makeGeminiResponsesEvent(state, "response.output_text.done", &ResponsesStreamEvent{
+			OutputIndex:  state.OutputIndex,
+			ContentIndex: 0,
+			Text:         text,
+			ItemID:       state.CurrentItemID,
+		}))
+	}
+	events = append(events, makeGeminiResponsesEvent(state, "response.output_item.done", &ResponsesStreamEvent{
+		OutputIndex: state.OutputIndex,
+		Item:        &item,
+	}))
+
+	state.Outputs = append(state.Outputs, item)
+	state.CurrentItemType = ""
+	state.CurrentItemID = ""
+	state.currentText.Reset()
+	state.OutputIndex++
+	return events
+}
+
+// geminiFunctionCallToResponsesEvents emits a complete function_call item.
+// Gemini delivers function calls whole, so added/delta/done are sent together.
+func geminiFunctionCallToResponsesEvents(fc *GeminiFunctionCall, state *GeminiEventToResponsesState) []ResponsesStreamEvent {
+	item := geminiFunctionCallToResponsesOutput(fc)
+	added := item
+	added.Arguments = ""
+	added.Status = "in_progress"
+
+	events := []ResponsesStreamEvent{
+		makeGeminiResponsesEvent(state, "response.output_item.added", &ResponsesStreamEvent{
+			OutputIndex: state.OutputIndex,
+			Item:        &added,
+		}),
+		makeGeminiResponsesEvent(state, "response.function_call_arguments.delta", &ResponsesStreamEvent{
+			OutputIndex: state.OutputIndex,
+			Delta:       item.Arguments,
+			ItemID:      item.ID,
+			CallID:      item.CallID,
+			Name:        item.Name,
+		}),
+		makeGeminiResponsesEvent(state, "response.function_call_arguments.done", &ResponsesStreamEvent{
+			OutputIndex: state.OutputIndex,
+			Arguments:   item.Arguments,
+			ItemID:      item.ID,
+			CallID:      item.CallID,
+			Name:        item.Name,
+		}),
+		makeGeminiResponsesEvent(state, "response.output_item.done", &ResponsesStreamEvent{
+			OutputIndex: state.OutputIndex,
+			Item:        &item,
+		}),
+	}
+	state.Outputs = append(state.Outputs, item)
+	state.OutputIndex++
+	return events
+}
+
+func makeGeminiResponsesEvent(state *GeminiEventToResponsesState, eventType string, template *ResponsesStreamEvent) ResponsesStreamEvent {
+	seq := state.SequenceNumber
+	state.SequenceNumber++
+
+	evt := *template
+	evt.Type = eventType
+	evt.SequenceNumber = seq
+	return evt
+}
+
+// geminiResponsesID derives a Responses ID from the Gemini responseId.
+func geminiResponsesID(responseID string) string {
+	if responseID == "" {
+		return generateResponsesID()
+	}
+	return "resp_" + responseID
+}
+
+// geminiCallID returns the Gemini function call ID, or a synthetic one when
+// the upstream omits it (Gemini API usually does).
+func geminiCallID(id string) string {
+	if id != "" {
+		return id
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
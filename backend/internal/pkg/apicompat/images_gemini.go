@@ -0,0 +1,227 @@
+package apicompat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxImagesMultipartMemory bounds the in-memory part of multipart parsing;
+// the request body is already limited by the gateway body-size middleware.
+const maxImagesMultipartMemory = 32 << 20
+
+// ParseImagesRequest parses an OpenAI images request body. JSON bodies are
+// used by generations; edits use multipart/form-data with image / image[]
+// file fields and an optional mask.
+func ParseImagesRequest(contentType string, body []byte) (*ImagesRequest, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType != "multipart/form-data" {
+		var req ImagesRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("parse images request: %w", err)
+		}
+		return &req, nil
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("multipart boundary is missing")
+	}
+	form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(maxImagesMultipartMemory)
+	if err != nil {
+		return nil, fmt.Errorf("parse multipart form: %w", err)
+	}
+	defer func() { _ = form.RemoveAll() }()
+
+	field := func(name string) string {
+		if values := form.Value[name]; len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	req := &ImagesRequest{
+		Model:          field("model"),
+		Prompt:         field("prompt"),
+		Size:           field("size"),
+		Quality:        field("quality"),
+		ResponseFormat: field("response_format"),
+		User:           field("user"),
+	}
+	if n := field("n"); n != "" {
+		if req.N, err = strconv.Atoi(n); err != nil {
+			return nil, fmt.Errorf("invalid n: %q", n)
+		}
+	}
+
+	for _, name := range []string{"image", "image[]"} {
+		for _, fh := range form.File[name] {
+			img, err := readImageInput(fh)
+			if err != nil {
+				return nil, err
+			}
+			req.Images = append(req.Images, *img)
+		}
+	}
+	if files := form.File["mask"]; len(files) > 0 {
+		if req.Mask, err = readImageInput(files[0]); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func readImageInput(fh *multipart.FileHeader) (*ImageInput, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", fh.Filename, err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", fh.Filename, err)
+	}
+	mimeType := fh.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	return &ImageInput{MimeType: mimeType, Data: data}, nil
+}
+
+// geminiAspectRatios lists the aspect ratios accepted by Gemini image models.
+var geminiAspectRatios = []struct {
+	name  string
+	ratio float64
+}{
+	{"1:1", 1}, {"2:3", 2.0 / 3}, {"3:2", 3.0 / 2}, {"3:4", 3.0 / 4}, {"4:3", 4.0 / 3},
+	{"4:5", 4.0 / 5}, {"5:4", 5.0 / 4}, {"9:16", 9.0 / 16}, {"16:9", 16.0 / 9}, {"21:9", 21.0 / 9},
+}
+
+// ImageSizeTier maps an OpenAI size ("1024x1024", "1536x1024", ...) to the
+// "1K" / "2K" / "4K" tier used by Gemini imageConfig and image billing.
+// Empty or "auto" sizes map to "1K".
+func ImageSizeTier(size string) string {
+	w, h, ok := parseImageSize(size)
+	if !ok {
+		return "1K"
+	}
+	switch longest := max(w, h); {
+	case longest <= 1024:
+		return "1K"
+	case longest <= 2048:
+		return "2K"
+	default:
+		return "4K"
+	}
+}
+
+// imageSizeToAspectRatio returns the Gemini aspect ratio closest to an
+// OpenAI size, or "" when the size is empty or "auto".
+func imageSizeToAspectRatio(size string) string {
+	w, h, ok := parseImageSize(size)
+	if !ok {
+		return ""
+	}
+	target := float64(w) / float64(h)
+	best, bestDiff := "", math.MaxFloat64
+	for _, ar := range geminiAspectRatios {
+		if diff := math.Abs(math.Log(target / ar.ratio)); diff < bestDiff {
+			best, bestDiff = ar.name, diff
+		}
+	}
+	return best
+}
+
+func parseImageSize(size string) (int, int, bool) {
+	ws, hs, found := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+	if !found {
+		return 0, 0, false
+	}
+	w, err1 := strconv.Atoi(ws)
+	h, err2 := strconv.Atoi(hs)
+	if err1 != nil || err2 != nil || w <= 0 || h <= 0 {
+		return 0, 0, false
+	}
+	return w, h, true
+}
+
+// ImagesToGeminiRequest converts an OpenAI images request into a Gemini
+// generateContent request for an image model. Gemini returns one image per
+// call, so callers issue N requests for n > 1. Gemini has no mask support;
+// the mask is sent as an extra image with an instruction.
+func ImagesToGeminiRequest(req *ImagesRequest) *GeminiRequest {
+	var parts []GeminiPart
+	for _, img := range req.Images {
+		parts = append(parts, GeminiPart{InlineData: &GeminiInlineData{
+			MimeType: img.MimeType,
+			Data:     base64.StdEncoding.EncodeToString(img.Data),
+		}})
+	}
+	if req.Mask != nil {
+		parts = append(parts,
+			GeminiPart{Text: "The next image is a mask: only edit the regions where the mask is transparent."},
+			GeminiPart{InlineData: &GeminiInlineData{
+				MimeType: req.Mask.MimeType,
+				Data:     base64.StdEncoding.EncodeToString(req.Mask.Data),
+			}},
+		)
+	}
+	parts = append(parts, GeminiPart{Text: req.Prompt})
+
+	return &GeminiRequest{
+		Contents: []GeminiContent{{Role: "user", Parts: parts}},
+		GenerationConfig: &GeminiGenerationConfig{
+			ResponseModalities: []string{"IMAGE"},
+			ImageConfig: &GeminiImageConfig{
+				AspectRatio: imageSizeToAspectRatio(req.Size),
+				ImageSize:   ImageSizeTier(req.Size),
+			},
+		},
+	}
+}
+
+// GeminiToImagesResponse extracts the inline images of a Gemini response as
+// b64_json entries. Text parts, if any, become the revised prompt.
+func GeminiToImagesResponse(resp *GeminiResponse) *ImagesResponse {
+	out := &ImagesResponse{Created: time.Now().Unix(), Data: []ImageData{}}
+	if resp == nil {
+		return out
+	}
+	for _, cand := range resp.Candidates {
+		if cand.Content == nil {
+			continue
+		}
+		var text strings.Builder
+		for _, part := range cand.Content.Parts {
+			if part.Text != "" && !part.Thought {
+				text.WriteString(part.Text)
+			}
+		}
+		for _, part := range cand.Content.Parts {
+			if part.InlineData == nil || part.InlineData.Data == "" || part.Thought {
+				continue
+			}
+			out.Data = append(out.Data, ImageData{
+				B64JSON:       part.InlineData.Data,
+				RevisedPrompt: strings.TrimSpace(text.String()),
+			})
+		}
+	}
+	if u := resp.UsageMetadata; u != nil {
+		out.Usage = &ImagesUsage{
+			InputTokens:  u.PromptTokenCount,
+			OutputTokens: u.CandidatesTokenCount,
+			TotalTokens:  u.TotalTokenCount,
+		}
+	}
+	return out
+}
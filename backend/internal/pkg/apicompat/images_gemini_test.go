@@ -0,0 +1,98 @@
+package apicompat
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImagesRequest_JSON(t *testing.T) {
+	req, err := ParseImagesRequest("application/json", []byte(`{"model":"gpt-image-1","prompt":"a cat","n":2,"size":"1024x1536","response_format":"url"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-image-1", req.Model)
+	assert.Equal(t, "a cat", req.Prompt)
+	assert.Equal(t, 2, req.N)
+	assert.Equal(t, "url", req.ResponseFormat)
+}
+
+func TestParseImagesRequest_Multipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("model", "gemini-2.5-flash-image"))
+	require.NoError(t, mw.WriteField("prompt", "add a hat"))
+	require.NoError(t, mw.WriteField("n", "3"))
+	for _, name := range []string{"image[]", "image[]", "mask"} {
+		fw, err := mw.CreateFormFile(name, "f.png")
+		require.NoError(t, err)
+		_, _ = fw.Write([]byte("\x89PNG\r\n\x1a\n" + name))
+	}
+	require.NoError(t, mw.Close())
+
+	req, err := ParseImagesRequest(mw.FormDataContentType(), buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-2.5-flash-image", req.Model)
+	assert.Equal(t, 3, req.N)
+	require.Len(t, req.Images, 2)
+	assert.Equal(t, "image/png", req.Images[0].MimeType)
+	require.NotNil(t, req.Mask)
+
+	_, err = ParseImagesRequest("multipart/form-data", buf.Bytes())
+	require.Error(t, err)
+}
+
+func TestImageSizeTierAndAspectRatio(t *testing.T) {
+	tests := []struct {
+		size, tier, ratio string
+	}{
+		{"", "1K", ""},
+		{"auto", "1K", ""},
+		{"1024x1024", "1K", "1:1"},
+		{"1536x1024", "2K", "3:2"},
+		{"1024x1792", "2K", "9:16"},
+		{"4096x2304", "4K", "16:9"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.tier, ImageSizeTier(tt.size), tt.size)
+		assert.Equal(t, tt.ratio, imageSizeToAspectRatio(tt.size), tt.size)
+	}
+}
+
+func TestImagesToGeminiRequest_Edit(t *testing.T) {
+	out := ImagesToGeminiRequest(&ImagesRequest{
+		Prompt: "add a hat",
+		Size:   "1024x1024",
+		Images: []ImageInput{{MimeType: "image/png", Data: []byte("img")}},
+		Mask:   &ImageInput{MimeType: "image/png", Data: []byte("mask")},
+	})
+	require.Len(t, out.Contents, 1)
+	parts := out.Contents[0].Parts
+	require.Len(t, parts, 4)
+	assert.Equal(t, "aW1n", parts[0].InlineData.Data)
+	assert.NotEmpty(t, parts[1].Text)
+	assert.Equal(t, "bWFzaw==", parts[2].InlineData.Data)
+	assert.Equal(t, "add a hat", parts[3].Text)
+	assert.Equal(t, []string{"IMAGE"}, out.GenerationConfig.ResponseModalities)
+	assert.Equal(t, "1:1", out.GenerationConfig.ImageConfig.AspectRatio)
+	assert.Equal(t, "1K", out.GenerationConfig.ImageConfig.ImageSize)
+}
+
+func TestGeminiToImagesResponse(t *testing.T) {
+	out := GeminiToImagesResponse(&GeminiResponse{
+		Candidates: []GeminiCandidate{{Content: &GeminiContent{Parts: []GeminiPart{
+			{Text: "thinking", Thought: true},
+			{Text: "A cat with a hat"},
+			{InlineData: &GeminiInlineData{MimeType: "image/png", Data: "aW1n"}},
+		}}}},
+		UsageMetadata: &GeminiUsageMetadata{PromptTokenCount: 5, CandidatesTokenCount: 1290, TotalTokenCount: 1295},
+	})
+	require.Len(t, out.Data, 1)
+	assert.Equal(t, "aW1n", out.Data[0].B64JSON)
+	assert.Equal(t, "A cat with a hat", out.Data[0].RevisedPrompt)
+	require.NotNil(t, out.Usage)
+	assert.Equal(t, 1295, out.Usage.TotalTokens)
+
+	assert.Empty(t, GeminiToImagesResponse(nil).Data)
+}
@@ -0,0 +1,354 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ResponsesToGeminiRequest converts a Responses API request into a Gemini
+// generateContent request. It enables Gemini / Antigravity platform groups to
+// accept OpenAI Responses API requests by converting them to the native
+// generateContent format before forwarding upstream. The model is not part of
+// the Gemini body; callers carry it in the upstream URL.
+func ResponsesToGeminiRequest(req *ResponsesRequest) (*GeminiRequest, error) {
+	system, contents, err := convertResponsesInputToGemini(req.Input)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Instructions) != "" {
+		system = append([]GeminiPart{{Text: req.Instructions}}, system...)
+	}
+
+	out := &GeminiRequest{Contents: contents}
+	if len(system) > 0 {
+		out.SystemInstruction = &GeminiContent{Parts: system}
+	}
+
+	gen := &GeminiGenerationConfig{
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+	}
+	if req.MaxOutputTokens != nil && *req.MaxOutputTokens > 0 {
+		gen.MaxOutputTokens = *req.MaxOutputTokens
+	}
+	// reasoning.effort → thinkingConfig（复用 Anthropic 的预算映射）
+	if req.Reasoning != nil && req.Reasoning.Effort != "" {
+		budget := defaultThinkingBudget(mapResponsesEffortToAnthropic(req.Reasoning.Effort))
+		gen.ThinkingConfig = &GeminiThinkingConfig{
+			IncludeThoughts: true,
+			ThinkingBudget:  &budget,
+		}
+	}
+	if gen.Temperature != nil || gen.TopP != nil || gen.MaxOutputTokens > 0 || gen.ThinkingConfig != nil {
+		out.GenerationConfig = gen
+	}
+
+	if len(req.Tools) > 0 {
+		out.Tools = convertResponsesToGeminiTools(req.Tools)
+	}
+
+	if len(req.ToolChoice) > 0 {
+		tc, err := convertResponsesToGeminiToolConfig(req.ToolChoice)
+		if err != nil {
+			return nil, fmt.Errorf("convert tool_choice: %w", err)
+		}
+		out.ToolConfig = tc
+	}
+
+	return out, nil
+}
+
+// convertResponsesInputToGemini extracts system parts and conversation
+// contents from a Responses API input array.
+func convertResponsesInputToGemini(inputRaw json.RawMessage) ([]GeminiPart, []GeminiContent, error) {
+	// Try as plain string input.
+	var inputStr string
+	if err := json.Unmarshal(inputRaw, &inputStr); err == nil {
+		return nil, []GeminiContent{{Role: "user", Parts: []GeminiPart{{Text: inputStr}}}}, nil
+	}
+
+	var items []ResponsesInputItem
+	if err := json.Unmarshal(inputRaw, &items); err != nil {
+		return nil, nil, fmt.Errorf("parse responses input: %w", err)
+	}
+
+	var system []GeminiPart
+	var contents []GeminiContent
+	// Gemini functionResponse 需要函数名，按 call_id 记录前面的 function_call。
+	// 不透传 call_id：部分上游会拒绝 functionCall/functionResponse 中的 id 字段。
+	callNames := make(map[string]string)
+
+	for _, item := range items {
+		switch {
+		case item.Role == "system" || item.Role == "developer":
+			if text := extractTextFromContent(item.Content); text != "" {
+				system = append(system, GeminiPart{Text: text})
+			}
+
+		case item.Type == "function_call":
+			callNames[item.CallID] = item.Name
+			contents = append(contents, GeminiContent{
+				Role: "model",
+				Parts: []GeminiPart{{FunctionCall: &GeminiFunctionCall{
+					Name: item.Name,
+					Args: normalizeGeminiFunctionArgs(item.Arguments),
+				}}},
+			})
+
+		case item.Type == "function_call_output":
+			name := callNames[item.CallID]
+			if name == "" {
+				name = item.CallID
+			}
+			contents = append(contents, GeminiContent{
+				Role: "user",
+				Parts: []GeminiPart{{FunctionResponse: &GeminiFunctionResponse{
+					Name:     name,
+					Response: wrapGeminiFunctionOutput(item.Output),
+				}}},
+			})
+
+		case item.Role == "assistant":
+			text := extractTextFromContent(item.Content)
+			if text == "" {
+				continue
+			}
+			contents = append(contents, GeminiContent{
+				Role:  "model",
+				Parts: []GeminiPart{{Text: text}},
+			})
+
+		default:
+			// user 消息及未知 role/type 均按 user 处理
+			parts := convertResponsesUserToGeminiParts(item.Content)
+			if len(parts) == 0 {
+				continue
+			}
+			contents = append(contents, GeminiContent{Role: "user", Parts: parts})
+		}
+	}
+
+	return system, mergeConsecutiveGeminiContents(contents), nil
+}
+
+// convertResponsesUserToGeminiParts converts a Responses user message content
+// field into Gemini parts. Empty content yields no parts because Gemini
+// rejects empty text parts.
+func convertResponsesUserToGeminiParts(raw json.RawMessage) []GeminiPart {
+	if len(raw) == 0 {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if s == "" {
+			return nil
+		}
+		return []GeminiPart{{Text: s}}
+	}
+
+	var parts []ResponsesContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return nil
+	}
+
+	var out []GeminiPart
+	for _, p := range parts {
+		switch p.Type {
+		case "input_text", "text", "output_text":
+			if p.Text != "" {
+				out = append(out, GeminiPart{Text: p.Text})
+			}
+		case "input_image":
+			if part := imageURLToGeminiPart(p.ImageURL); part != nil {
+				out = append(out, *part)
+			}
+		}
+	}
+	return out
+}
+
+// imageURLToGeminiPart converts a data URI into inlineData, or a remote URL
+// into fileData.
+func imageURLToGeminiPart(imageURL string) *GeminiPart {
+	if src := dataURIToAnthropicImageSource(imageURL); src != nil {
+		return &GeminiPart{InlineData: &GeminiInlineData{MimeType: src.MediaType, Data: src.Data}}
+	}
+	if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
+		return nil
+	}
+	mimeType := "image/jpeg"
+	if u, err := url.Parse(imageURL); err == nil {
+		if t := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path))); strings.HasPrefix(t, "image/") {
+			mimeType = t
+		}
+	}
+	return &GeminiPart{FileData: &GeminiFileData{MimeType: mimeType, FileURI: imageURL}}
+}
+
+// normalizeGeminiFunctionArgs parses function call arguments into a JSON
+// object; Gemini rejects non-object args.
+func normalizeGeminiFunctionArgs(args string) json.RawMessage {
+	trimmed := strings.TrimSpace(args)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	return json.RawMessage(`{}`)
+}
+
+// wrapGeminiFunctionOutput converts a function output string into the object
+// Gemini expects in functionResponse.response.
+func wrapGeminiFunctionOutput(output string) json.RawMessage {
+	trimmed := strings.TrimSpace(output)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	if output == "" {
+		output = "(empty)"
+	}
+	wrapped, _ := json.Marshal(map[string]string{"result": output})
+	return wrapped
+}
+
+// mergeConsecutiveGeminiContents merges consecutive contents with the same
+// role, e.g. parallel function calls or multiple function responses.
+func mergeConsecutiveGeminiContents(contents []GeminiContent) []GeminiContent {
+	if len(contents) <= 1 {
+		return contents
+	}
+	var merged []GeminiContent
+	for _, content := range contents {
+		if len(merged) > 0 && merged[len(merged)-1].Role == content.Role {
+			last := &merged[len(merged)-1]
+			last.Parts = append(last.Parts, content.Parts...)
+			continue
+		}
+		merged = append(merged, content)
+	}
+	return merged
+}
+
+// convertResponsesToGeminiTools maps Responses API tools to Gemini format.
+// All function tools share a single functionDeclarations entry.
+func convertResponsesToGeminiTools(tools []ResponsesTool) []GeminiTool {
+	var decls []GeminiFunctionDeclaration
+	var out []GeminiTool
+	for _, t := range tools {
+		switch t.Type {
+		case "function":
+			decls = append(decls, GeminiFunctionDeclaration{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  normalizeGeminiParameters(t.Parameters),
+			})
+		case "web_search", "web_search_preview":
+			out = append(out, GeminiTool{GoogleSearch: &struct{}{}})
+		}
+	}
+	if len(decls) > 0 {
+		out = append([]GeminiTool{{FunctionDeclarations: decls}}, out...)
+	}
+	return out
+}
+
+// normalizeGeminiParameters drops empty schemas (Gemini accepts omitted
+// parameters for zero-argument functions but rejects null) and strips JSON
+// Schema keywords that Gemini does not support.
+func normalizeGeminiParameters(schema json.RawMessage) json.RawMessage {
+	if len(schema) == 0 || string(schema) == "null" {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(schema, &v); err != nil {
+		return schema
+	}
+	cleaned, err := json.Marshal(cleanGeminiSchema(v))
+	if err != nil {
+		return schema
+	}
+	return cleaned
+}
+
+// geminiUnsupportedSchemaKeys lists JSON Schema keywords rejected by Gemini
+// function declarations.
+var geminiUnsupportedSchemaKeys = map[string]struct{}{
+	"$schema": {}, "$id": {}, "$ref": {}, "additionalProperties": {}, "patternProperties": {},
+	"minLength": {}, "maxLength": {}, "minItems": {}, "maxItems": {}, "strict": {},
+}
+
+// cleanGeminiSchema recursively removes unsupported keywords and upper-cases
+// type names, matching the Anthropic → Gemini tool conversion.
+func cleanGeminiSchema(schema any) any {
+	switch v := schema.(type) {
+	case map[string]any:
+		cleaned := make(map[string]any, len(v))
+		for key, value := range v {
+			if _, skip := geminiUnsupportedSchemaKeys[key]; skip {
+				continue
+			}
+			cleaned[key] = cleanGeminiSchema(value)
+		}
+		if typeVal, ok := cleaned["type"].(string); ok {
+			cleaned["type"] = strings.ToUpper(typeVal)
+		}
+		return cleaned
+	case []any:
+		cleaned := make([]any, len(v))
+		for i, item := range v {
+			cleaned[i] = cleanGeminiSchema(item)
+		}
+		return cleaned
+	default:
+		return v
+	}
+}
+
+// convertResponsesToGeminiToolConfig maps Responses tool_choice to Gemini toolConfig.
+//
+//	"auto"                                     → AUTO
+//	"required"                                 → ANY
+//	"none"                                     → NONE
+//	{"type":"function","name":"X"}             → ANY + allowedFunctionNames=[X]
+//	{"type":"function","function":{"name":"X"}} → ANY + allowedFunctionNames=[X]
+func convertResponsesToGeminiToolConfig(raw json.RawMessage) (*GeminiToolConfig, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		mode := ""
+		switch s {
+		case "auto":
+			mode = "AUTO"
+		case "required":
+			mode = "ANY"
+		case "none":
+			mode = "NONE"
+		default:
+			return nil, nil
+		}
+		return &GeminiToolConfig{FunctionCallingConfig: &GeminiFunctionCallingConfig{Mode: mode}}, nil
+	}
+
+	var tc struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &tc); err != nil {
+		return nil, err
+	}
+	name := tc.Name
+	if name == "" {
+		name = tc.Function.Name
+	}
+	if tc.Type != "function" || name == "" {
+		return nil, nil
+	}
+	return &GeminiToolConfig{FunctionCallingConfig: &GeminiFunctionCallingConfig{
+		Mode:                 "ANY",
+		AllowedFunctionNames: []string{name},
+	}}, nil
+}
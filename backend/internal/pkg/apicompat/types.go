@@ -1,7 +1,7 @@
 // Package apicompat provides type definitions and conversion utilities for
-// translating between Anthropic Messages and OpenAI Responses API formats.
-// It enables multi-protocol support so that clients using different API
-// formats can be served through a unified gateway.
+// translating between Anthropic Messages, OpenAI Responses / Chat Completions
+// and Gemini generateContent API formats. It enables multi-protocol support so
+// that clients using different API formats can be served through a unified gateway.
 package apicompat
 
 import "encoding/json"
@@ -474,6 +474,185 @@ type ChatDelta struct {
 	ToolCalls        []ChatToolCall `json:"tool_calls,omitempty"`
 }
 
+// ---------------------------------------------------------------------------
+// Gemini generateContent API types
+// ---------------------------------------------------------------------------
+
+// GeminiRequest is the request body for models/{model}:generateContent and
+// models/{model}:streamGenerateContent.
+type GeminiRequest struct {
+	Contents          []GeminiContent         `json:"contents"`
+	SystemInstruction *GeminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []GeminiTool            `json:"tools,omitempty"`
+	ToolConfig        *GeminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// GeminiContent is a single turn (or the system instruction) in a Gemini conversation.
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" | "model"
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiPart is one part inside a Gemini content. Exactly one of the data
+// fields is populated.
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *GeminiInlineData       `json:"inlineData,omitempty"`
+	FileData         *GeminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// GeminiInlineData carries base64-encoded media.
+type GeminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// GeminiFileData references remote media by URI.
+type GeminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// GeminiFunctionCall is a function call emitted by the model.
+type GeminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse carries a function result back to the model.
+type GeminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+// GeminiTool describes tools available to the model.
+type GeminiTool struct {
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations,omitempty"`
+	GoogleSearch         *struct{}                   `json:"googleSearch,omitempty"`
+}
+
+// GeminiFunctionDeclaration describes a single callable function.
+type GeminiFunctionDeclaration struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// GeminiToolConfig controls function calling behaviour.
+type GeminiToolConfig struct {
+	FunctionCallingConfig *GeminiFunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// GeminiFunctionCallingConfig selects the function calling mode.
+type GeminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode"` // "AUTO" | "ANY" | "NONE"
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GeminiGenerationConfig holds sampling and output parameters.
+type GeminiGenerationConfig struct {
+	Temperature     *float64              `json:"temperature,omitempty"`
+	TopP            *float64              `json:"topP,omitempty"`
+	MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string              `json:"stopSequences,omitempty"`
+	ThinkingConfig  *GeminiThinkingConfig `json:"thinkingConfig,omitempty"`
+	// ResponseModalities / ImageConfig are used by image generation models.
+	ResponseModalities []string           `json:"responseModalities,omitempty"`
+	ImageConfig        *GeminiImageConfig `json:"imageConfig,omitempty"`
+}
+
+// GeminiImageConfig configures image generation output.
+type GeminiImageConfig struct {
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	ImageSize   string `json:"imageSize,omitempty"`
+}
+
+// GeminiThinkingConfig configures thinking for Gemini 2.5+ models.
+type GeminiThinkingConfig struct {
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+	ThinkingBudget  *int `json:"thinkingBudget,omitempty"`
+}
+
+// GeminiResponse is a generateContent response, or a single chunk of a
+// streamGenerateContent response.
+type GeminiResponse struct {
+	Candidates    []GeminiCandidate    `json:"candidates,omitempty"`
+	UsageMetadata *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion  string               `json:"modelVersion,omitempty"`
+	ResponseID    string               `json:"responseId,omitempty"`
+}
+
+// GeminiCandidate is one generated candidate.
+type GeminiCandidate struct {
+	Content      *GeminiContent `json:"content,omitempty"`
+	FinishReason string         `json:"finishReason,omitempty"` // "STOP" | "MAX_TOKENS" | "SAFETY" | ...
+	Index        int            `json:"index,omitempty"`
+}
+
+// GeminiUsageMetadata holds token counts in Gemini format. PromptTokenCount
+// includes CachedContentTokenCount; ThoughtsTokenCount is billed as output
+// but is not part of CandidatesTokenCount.
+type GeminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount,omitempty"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount,omitempty"`
+	TotalTokenCount         int `json:"totalTokenCount,omitempty"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// OpenAI Images API types
+// ---------------------------------------------------------------------------
+
+// ImagesRequest is the parsed form of an OpenAI /v1/images/generations or
+// /v1/images/edits request. Edits arrive as multipart/form-data; their input
+// images and optional mask are carried in Images and Mask.
+type ImagesRequest struct {
+	Model          string       `json:"model"`
+	Prompt         string       `json:"prompt"`
+	N              int          `json:"n,omitempty"`
+	Size           string       `json:"size,omitempty"`
+	Quality        string       `json:"quality,omitempty"`
+	ResponseFormat string       `json:"response_format,omitempty"`
+	User           string       `json:"user,omitempty"`
+	Images         []ImageInput `json:"-"`
+	Mask           *ImageInput  `json:"-"`
+}
+
+// ImageInput is an input image of an images edit request.
+type ImageInput struct {
+	MimeType string
+	Data     []byte
+}
+
+// ImagesResponse is the OpenAI Images API response body.
+type ImagesResponse struct {
+	Created int64        `json:"created"`
+	Data    []ImageData  `json:"data"`
+	Usage   *ImagesUsage `json:"usage,omitempty"`
+}
+
+// ImageData is a single generated image.
+type ImageData struct {
+	B64JSON       string `json:"b64_json,omitempty"`
+	URL           string `json:"url,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// ImagesUsage is the token usage reported by gpt-image models.
+type ImagesUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
 // ---------------------------------------------------------------------------
 // Shared constants
 // ---------------------------------------------------------------------------
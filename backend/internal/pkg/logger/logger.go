@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -130,6 +131,38 @@ func SetSink(sink Sink) {
 	currentSink.Store(sinkState{sink: sink})
 }
 
+type multiSink []Sink
+
+func (m multiSink) WriteLogEvent(event *LogEvent) {
+	for _, s := range m {
+		s.WriteLogEvent(event)
+	}
+}
+
+// MultiSink 将同一事件依次分发给多个 sink（nil 会被忽略）。
+// 各 sink 需自行保证非阻塞，且不得修改 event。
+func MultiSink(sinks ...Sink) Sink {
+	out := make(multiSink, 0, len(sinks))
+	for _, s := range sinks {
+		if s == nil || isNilSink(s) {
+			continue
+		}
+		out = append(out, s)
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	}
+	return out
+}
+
+func isNilSink(s Sink) bool {
+	v := reflect.ValueOf(s)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
 func loadSink() Sink {
 	v := currentSink.Load()
 	if v == nil {
@@ -190,3 +190,30 @@ func TestInit_CallerShouldPointToCallsite(t *testing.T) {
 		t.Fatalf("caller should point to this test file, got: %s", caller)
 	}
 }
+
+type countingSink struct {
+	events []*LogEvent
+}
+
+func (s *countingSink) WriteLogEvent(event *LogEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestMultiSink_FansOutAndSkipsNil(t *testing.T) {
+	var nilSink *countingSink
+	if MultiSink() != nil || MultiSink(nil, nilSink) != nil {
+		t.Fatalf("MultiSink without sinks should be nil")
+	}
+
+	a := &countingSink{}
+	if got := MultiSink(nilSink, a); got != Sink(a) {
+		t.Fatalf("MultiSink with single sink should return it directly")
+	}
+
+	b := &countingSink{}
+	event := &LogEvent{Level: "info", Message: "hello"}
+	MultiSink(a, nil, b).WriteLogEvent(event)
+	if len(a.events) != 1 || len(b.events) != 1 || a.events[0] != event || b.events[0] != event {
+		t.Fatalf("event not delivered to every sink: a=%d b=%d", len(a.events), len(b.events))
+	}
+}
@@ -0,0 +1,436 @@
+// Package metrics 提供一个轻量的 Prometheus 文本格式指标注册表。
+//
+// 仅实现网关需要的 Counter / Gauge / Histogram 及抓取时回调，
+// 每个指标的时间序列数受 MaxSeries 限制，超出后归入 OverflowLabelValue。
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxSeries 单个指标默认允许的最大时间序列数
+	DefaultMaxSeries = 1000
+	// OverflowLabelValue 超出序列上限后统一使用的标签值
+	OverflowLabelValue = "__overflow__"
+	// ContentType Prometheus 文本格式的 Content-Type
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// DefaultDurationBuckets 请求耗时直方图默认分桶（秒）
+var DefaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Default 进程级默认注册表
+var Default = NewRegistry()
+
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// Sample 抓取时回调返回的单个样本，LabelValues 顺序与注册时的标签名一致
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry 指标注册表，按名称唯一；重复注册同名指标会替换旧实现
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry 创建空注册表
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	r.collectors[name] = c
+	r.mu.Unlock()
+}
+
+// Unregister 移除指定名称的指标
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.collectors, name)
+	r.mu.Unlock()
+}
+
+// WriteText 按名称排序输出 Prometheus 文本格式
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	collectors := make(map[string]collector, len(r.collectors))
+	for name, c := range r.collectors {
+		collectors[name] = c
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		collectors[name].write(bw)
+	}
+	return bw.Flush()
+}
+
+// desc 指标元数据与序列上限控制
+type desc struct {
+	name      string
+	help      string
+	typ       metricType
+	labels    []string
+	maxSeries int
+}
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.typ)
+}
+
+// seriesKey 校验标签数量并生成序列 key；超出上限时返回溢出 key
+func (d *desc) seriesKey(existing int, has func(string) bool, values []string) (string, []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	if has(key) || d.maxSeries <= 0 || existing < d.maxSeries {
+		return key, values
+	}
+	overflow := make([]string, len(values))
+	for i := range overflow {
+		overflow[i] = OverflowLabelValue
+	}
+	return strings.Join(overflow, "\xff"), overflow
+}
+
+// CounterVec 带标签的单调递增计数器
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*floatSeries
+}
+
+// GaugeVec 带标签的可增减数值
+type GaugeVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*floatSeries
+}
+
+type floatSeries struct {
+	labels []string
+	value  float64
+}
+
+// NewCounterVec 在注册表中创建计数器
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{name: name, help: help, typ: typeCounter, labels: labels, maxSeries: DefaultMaxSeries},
+		series: make(map[string]*floatSeries),
+	}
+	r.register(name, c)
+	return c
+}
+
+// NewGaugeVec 在注册表中创建 Gauge
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		desc:   desc{name: name, help: help, typ: typeGauge, labels: labels, maxSeries: DefaultMaxSeries},
+		series: make(map[string]*floatSeries),
+	}
+	r.register(name, g)
+	return g
+}
+
+func addFloatSeries(d *desc, mu *sync.Mutex, series map[string]*floatSeries, values []string, delta float64, set bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	key, lv := d.seriesKey(len(series), func(k string) bool { _, ok := series[k]; return ok }, values)
+	s, ok := series[key]
+	if !ok {
+		s = &floatSeries{labels: append([]string(nil), lv...)}
+		series[key] = s
+	}
+	if set {
+		s.value = delta
+	} else {
+		s.value += delta
+	}
+}
+
+func writeFloatSeries(w *bufio.Writer, d *desc, mu *sync.Mutex, series map[string]*floatSeries) {
+	mu.Lock()
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rows := make([]floatSeries, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, *series[k])
+	}
+	mu.Unlock()
+
+	d.writeHeader(w)
+	for _, row := range rows {
+		writeSample(w, d.name, d.labels, row.labels, "", "", row.value)
+	}
+}
+
+// WithMaxSeries 设置序列上限（<=0 表示不限制）
+func (c *CounterVec) WithMaxSeries(n int) *CounterVec {
+	c.mu.Lock()
+	c.maxSeries = n
+	c.mu.Unlock()
+	return c
+}
+
+// Inc 计数加一
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加 delta，负数会被忽略
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if c == nil || delta < 0 {
+		return
+	}
+	addFloatSeries(&c.desc, &c.mu, c.series, labelValues, delta, false)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	writeFloatSeries(w, &c.desc, &c.mu, c.series)
+}
+
+// WithMaxSeries 设置序列上限（<=0 表示不限制）
+func (g *GaugeVec) WithMaxSeries(n int) *GaugeVec {
+	g.mu.Lock()
+	g.maxSeries = n
+	g.mu.Unlock()
+	return g
+}
+
+// Set 设置当前值
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	if g == nil {
+		return
+	}
+	addFloatSeries(&g.desc, &g.mu, g.series, labelValues, value, true)
+}
+
+// Add 当前值增加 delta（可为负数）
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	if g == nil {
+		return
+	}
+	addFloatSeries(&g.desc, &g.mu, g.series, labelValues, delta, false)
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	writeFloatSeries(w, &g.desc, &g.mu, g.series)
+}
+
+// HistogramVec 带标签的累积分桶直方图
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec 在注册表中创建直方图，buckets 为空时使用 DefaultDurationBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, typ: typeHistogram, labels: labels, maxSeries: DefaultMaxSeries},
+		buckets: sorted,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(name, h)
+	return h
+}
+
+// WithMaxSeries 设置序列上限（<=0 表示不限制）
+func (h *HistogramVec) WithMaxSeries(n int) *HistogramVec {
+	h.mu.Lock()
+	h.maxSeries = n
+	h.mu.Unlock()
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if h == nil || math.IsNaN(value) {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key, lv := h.seriesKey(len(h.series), func(k string) bool { _, ok := h.series[k]; return ok }, labelValues)
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labels: append([]string(nil), lv...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rows := make([]histogramSeries, 0, len(keys))
+	for _, k := range keys {
+		s := h.series[k]
+		rows = append(rows, histogramSeries{
+			labels: s.labels,
+			counts: append([]uint64(nil), s.counts...),
+			count:  s.count,
+			sum:    s.sum,
+		})
+	}
+	h.mu.Unlock()
+
+	h.writeHeader(w)
+	for _, row := range rows {
+		for i, upper := range h.buckets {
+			writeSample(w, h.name+"_bucket", h.labels, row.labels, "le", formatFloat(upper), float64(row.counts[i]))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, row.labels, "le", "+Inf", float64(row.count))
+		writeSample(w, h.name+"_sum", h.labels, row.labels, "", "", row.sum)
+		writeSample(w, h.name+"_count", h.labels, row.labels, "", "", float64(row.count))
+	}
+}
+
+// funcCollector 抓取时调用回调生成样本，适合从已有快照导出指标
+type funcCollector struct {
+	desc
+	fn func() []Sample
+}
+
+// RegisterGaugeFunc 注册抓取时计算的 Gauge
+func (r *Registry) RegisterGaugeFunc(name, help string, labels []string, fn func() []Sample) {
+	r.registerFunc(name, help, typeGauge, labels, fn)
+}
+
+// RegisterCounterFunc 注册抓取时读取的计数器（值需单调递增）
+func (r *Registry) RegisterCounterFunc(name, help string, labels []string, fn func() []Sample) {
+	r.registerFunc(name, help, typeCounter, labels, fn)
+}
+
+func (r *Registry) registerFunc(name, help string, typ metricType, labels []string, fn func() []Sample) {
+	if fn == nil {
+		return
+	}
+	r.register(name, &funcCollector{
+		desc: desc{name: name, help: help, typ: typ, labels: labels, maxSeries: DefaultMaxSeries},
+		fn:   fn,
+	})
+}
+
+func (f *funcCollector) write(w *bufio.Writer) {
+	samples := f.fn()
+	f.writeHeader(w)
+	for i, s := range samples {
+		if f.maxSeries > 0 && i >= f.maxSeries {
+			break
+		}
+		if len(s.LabelValues) != len(f.labels) {
+			continue
+		}
+		writeSample(w, f.name, f.labels, s.LabelValues, "", "", s.Value)
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labelNames, labelValues []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labelNames) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, ln := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(ln)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabelValue(labelValues[i]))
+			w.WriteByte('"')
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraName)
+			w.WriteString(`="`)
+			w.WriteString(extraValue)
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpReplacer.Replace(v)
+}
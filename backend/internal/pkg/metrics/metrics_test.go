@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	return sb.String()
+}
+
+func TestCounterAndGaugeText(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_requests_total", "Total requests.", "platform", "status")
+	c.Inc("openai", "200")
+	c.Add(2, "openai", "200")
+	c.Add(-5, "openai", "200")
+	c.Inc("gemini", "500")
+
+	g := r.NewGaugeVec("test_in_use", "Slots in use.")
+	g.Add(3)
+	g.Add(-1)
+
+	out := render(t, r)
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{platform="openai",status="200"} 3` + "\n",
+		`test_requests_total{platform="gemini",status="500"} 1` + "\n",
+		"# TYPE test_in_use gauge\ntest_in_use 2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "test_in_use") > strings.Index(out, "test_requests_total") {
+		t.Fatalf("metrics should be sorted by name:\n%s", out)
+	}
+}
+
+func TestHistogramText(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("test_duration_seconds", "Duration.", []float64{1, 0.1}, "model")
+	h.Observe(0.05, "m")
+	h.Observe(0.5, "m")
+	h.Observe(3, "m")
+
+	out := render(t, r)
+	for _, want := range []string{
+		`test_duration_seconds_bucket{model="m",le="0.1"} 1`,
+		`test_duration_seconds_bucket{model="m",le="1"} 2`,
+		`test_duration_seconds_bucket{model="m",le="+Inf"} 3`,
+		`test_duration_seconds_sum{model="m"} 3.55`,
+		`test_duration_seconds_count{model="m"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMaxSeriesOverflow(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_overflow_total", "Overflow.", "model").WithMaxSeries(2)
+	c.Inc("a")
+	c.Inc("b")
+	c.Inc("c")
+	c.Inc("d")
+	c.Inc("a")
+
+	out := render(t, r)
+	if !strings.Contains(out, `test_overflow_total{model="a"} 2`) {
+		t.Fatalf("existing series should keep counting:\n%s", out)
+	}
+	if !strings.Contains(out, `test_overflow_total{model="`+OverflowLabelValue+`"} 2`) {
+		t.Fatalf("new series beyond cap should be folded:\n%s", out)
+	}
+	if strings.Contains(out, `model="c"`) {
+		t.Fatalf("series beyond cap must not be exported:\n%s", out)
+	}
+}
+
+func TestGaugeFuncReplaceAndEscape(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterGaugeFunc("test_queue_depth", "Queue depth.", []string{"pool"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"old"}, Value: 1}}
+	})
+	r.RegisterGaugeFunc("test_queue_depth", "Queue depth.", []string{"pool"}, func() []Sample {
+		return []Sample{
+			{LabelValues: []string{"a\"b\\c\nd"}, Value: 7},
+			{LabelValues: []string{"bad", "arity"}, Value: 1},
+		}
+	})
+
+	out := render(t, r)
+	if strings.Contains(out, `pool="old"`) {
+		t.Fatalf("re-registered collector should replace old one:\n%s", out)
+	}
+	if !strings.Contains(out, `test_queue_depth{pool="a\"b\\c\nd"} 7`) {
+		t.Fatalf("label value not escaped:\n%s", out)
+	}
+	if strings.Contains(out, `pool="bad"`) {
+		t.Fatalf("samples with wrong label arity should be dropped:\n%s", out)
+	}
+
+	r.Unregister("test_queue_depth")
+	if strings.Contains(render(t, r), "test_queue_depth") {
+		t.Fatal("unregistered metric still exported")
+	}
+}
+
+func TestLabelArityPanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_arity_total", "Arity.", "a", "b")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on label arity mismatch")
+		}
+	}()
+	c.Inc("only-one")
+}
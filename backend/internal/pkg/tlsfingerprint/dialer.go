@@ -12,7 +12,10 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	utls "github.com/refraction-networking/utls"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/proxy"
 )
 
@@ -140,7 +143,10 @@ func NewSOCKS5ProxyDialer(profile *Profile, proxyURL *url.URL) *SOCKS5ProxyDiale
 
 // DialTLSContext establishes a TLS connection through SOCKS5 proxy with the configured fingerprint.
 // Flow: SOCKS5 CONNECT to target -> TLS handshake with utls on the tunnel
-func (d *SOCKS5ProxyDialer) DialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
+func (d *SOCKS5ProxyDialer) DialTLSContext(ctx context.Context, network, addr string) (tlsConn net.Conn, err error) {
+	ctx, span := startDialSpan(ctx, d.profile, "socks5", addr)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	slog.Debug("tls_fingerprint_socks5_connecting", "proxy", d.proxyURL.Host, "target", addr)
 
 	// Step 1: Create SOCKS5 dialer
@@ -181,7 +187,10 @@ func (d *SOCKS5ProxyDialer) DialTLSContext(ctx context.Context, network, addr st
 
 // DialTLSContext establishes a TLS connection through HTTP proxy with the configured fingerprint.
 // Flow: TCP connect to proxy -> CONNECT tunnel -> TLS handshake with utls
-func (d *HTTPProxyDialer) DialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
+func (d *HTTPProxyDialer) DialTLSContext(ctx context.Context, network, addr string) (tlsConn net.Conn, err error) {
+	ctx, span := startDialSpan(ctx, d.profile, d.proxyURL.Scheme, addr)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	slog.Debug("tls_fingerprint_http_proxy_connecting", "proxy", d.proxyURL.Host, "target", addr)
 
 	// Step 1: TCP connect to proxy server
@@ -252,7 +261,10 @@ func (d *HTTPProxyDialer) DialTLSContext(ctx context.Context, network, addr stri
 
 // DialTLSContext establishes a TLS connection with the configured fingerprint.
 // This method is designed to be used as http.Transport.DialTLSContext.
-func (d *Dialer) DialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
+func (d *Dialer) DialTLSContext(ctx context.Context, network, addr string) (tlsConn net.Conn, err error) {
+	ctx, span := startDialSpan(ctx, d.profile, "direct", addr)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	// Establish TCP connection using base dialer (supports proxy)
 	slog.Debug("tls_fingerprint_dialing_tcp", "addr", addr)
 	conn, err := d.baseDialer(ctx, network, addr)
@@ -266,6 +278,20 @@ func (d *Dialer) DialTLSContext(ctx context.Context, network, addr string) (net.
 	return performTLSHandshake(ctx, conn, d.profile, addr)
 }
 
+// startDialSpan starts a span covering the proxy tunnel and uTLS handshake.
+// Only the proxy type is recorded; proxy credentials never reach span attributes.
+func startDialSpan(ctx context.Context, profile *Profile, proxyType, addr string) (context.Context, trace.Span) {
+	profileName := ""
+	if profile != nil {
+		profileName = profile.Name
+	}
+	return tracing.Start(ctx, "tls_fingerprint.dial",
+		attribute.String("net.peer", addr),
+		attribute.String("proxy.type", proxyType),
+		attribute.String("tls.fingerprint_profile", profileName),
+	)
+}
+
 // performTLSHandshake performs the uTLS handshake on an established connection.
 // It builds a ClientHello spec from the profile, applies it, and completes the handshake.
 // On failure, conn is closed and an error is returned.
@@ -0,0 +1,35 @@
+package tokenestimate
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+var (
+	o200kOnce    sync.Once
+	o200kEncoder *tiktoken.Tiktoken
+)
+
+// o200k 懒加载 o200k_base 词表。词表随二进制内置，不在运行时下载；
+// 加载失败时返回 nil，由调用方回退到启发式估算。
+func o200k() *tiktoken.Tiktoken {
+	o200kOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+		enc, err := tiktoken.GetEncoding(tiktoken.MODEL_O200K_BASE)
+		if err == nil {
+			o200kEncoder = enc
+		}
+	})
+	return o200kEncoder
+}
+
+// countO200K 使用 o200k_base BPE 精确计算文本 token 数；特殊 token 字面量按普通文本处理。
+func countO200K(text string) (int, bool) {
+	enc := o200k()
+	if enc == nil {
+		return 0, false
+	}
+	return len(enc.EncodeOrdinary(text)), true
+}
@@ -0,0 +1,172 @@
+// Package tokenestimate 提供本地 token 计数，用于上游不支持 count_tokens 时的兜底与请求前费用预估。
+//
+// OpenAI 模型（o200k 编码族）使用内置的 o200k_base BPE 词表分词，文本计数与 tiktoken 一致。
+// Anthropic 未公开 Claude 分词器，Claude 及其他模型只能启发式估算：按 tiktoken 的预分词规则
+// 将文本切分为片段（单词、数字、标点、空白、CJK 字符），再按经验压缩率计算每个片段的 token 数。
+// 两者都只计算文本，消息结构、图片与文档按公开规则近似；结果与上游真实计数存在偏差，
+// 不能用于精确计费，对外返回的计数需标记为估算值。
+package tokenestimate
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encoding 表示 token 估算使用的编码族
+type Encoding string
+
+const (
+	// EncodingClaude Anthropic Claude 系列模型
+	EncodingClaude Encoding = "claude"
+	// EncodingO200K OpenAI o200k_base（gpt-4o / gpt-4.1 / gpt-5 / o 系列）
+	EncodingO200K Encoding = "o200k"
+)
+
+// encodingParams 启发式估算使用的经验压缩率
+type encodingParams struct {
+	// asciiWordChars 每个 token 覆盖的 ASCII 字母数
+	asciiWordChars float64
+	// latinWordChars 每个 token 覆盖的非 ASCII 字母数（西里尔、带重音拉丁字母等）
+	latinWordChars float64
+	// cjkTokensPerChar 每个 CJK/假名/谚文字符的 token 数
+	cjkTokensPerChar float64
+	// punctChars 每个 token 覆盖的连续标点数
+	punctChars float64
+	// spaceChars 每个 token 覆盖的连续空白数（不含单词前的单个空格）
+	spaceChars float64
+}
+
+var encodings = map[Encoding]encodingParams{
+	EncodingClaude: {asciiWordChars: 5, latinWordChars: 2.5, cjkTokensPerChar: 1.1, punctChars: 2, spaceChars: 4},
+	// 仅在 o200k_base 词表加载失败时使用
+	EncodingO200K: {asciiWordChars: 6, latinWordChars: 3.5, cjkTokensPerChar: 0.8, punctChars: 3, spaceChars: 8},
+}
+
+// ForModel 根据模型名选择编码族；未知模型按 Claude 编码（偏保守）估算。
+func ForModel(model string) Encoding {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if idx := strings.LastIndex(m, "/"); idx >= 0 {
+		m = m[idx+1:]
+	}
+	switch {
+	case strings.HasPrefix(m, "gpt-"), strings.HasPrefix(m, "chatgpt"), strings.HasPrefix(m, "codex"),
+		strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"), strings.HasPrefix(m, "o4"),
+		strings.HasPrefix(m, "text-embedding-3"), strings.HasPrefix(m, "omni-"):
+		return EncodingO200K
+	default:
+		return EncodingClaude
+	}
+}
+
+// CountText 计算一段文本的 token 数：o200k 编码族使用 BPE 分词，其余编码族启发式估算。
+func CountText(enc Encoding, text string) int {
+	if text == "" {
+		return 0
+	}
+	if enc == EncodingO200K {
+		if n, ok := countO200K(text); ok {
+			return n
+		}
+	}
+	return countHeuristic(enc, text)
+}
+
+// countHeuristic 按预分词片段与经验压缩率估算 token 数。
+func countHeuristic(enc Encoding, text string) int {
+	p, ok := encodings[enc]
+	if !ok {
+		p = encodings[EncodingClaude]
+	}
+
+	total := 0.0
+	i := 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == ' ' && i+size < len(text) && isWordStart(text[i+size:]):
+			// 与后续单词合并（" hello" 为一个片段）
+			i += size
+			continue
+		case unicode.IsSpace(r):
+			n := 0
+			for i < len(text) {
+				r2, s2 := utf8.DecodeRuneInString(text[i:])
+				if !unicode.IsSpace(r2) {
+					break
+				}
+				n++
+				i += s2
+			}
+			total += math.Ceil(float64(n) / p.spaceChars)
+		case isCJK(r):
+			n := 0
+			for i < len(text) {
+				r2, s2 := utf8.DecodeRuneInString(text[i:])
+				if !isCJK(r2) {
+					break
+				}
+				n++
+				i += s2
+			}
+			total += math.Ceil(float64(n) * p.cjkTokensPerChar)
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			ascii, other := 0, 0
+			for i < len(text) {
+				r2, s2 := utf8.DecodeRuneInString(text[i:])
+				if !(unicode.IsLetter(r2) || unicode.IsMark(r2)) || isCJK(r2) {
+					break
+				}
+				if r2 < utf8.RuneSelf {
+					ascii++
+				} else {
+					other++
+				}
+				i += s2
+			}
+			total += math.Max(1, math.Ceil(float64(ascii)/p.asciiWordChars+float64(other)/p.latinWordChars))
+		case unicode.IsDigit(r):
+			n := 0
+			for i < len(text) {
+				r2, s2 := utf8.DecodeRuneInString(text[i:])
+				if !unicode.IsDigit(r2) {
+					break
+				}
+				n++
+				i += s2
+			}
+			// tiktoken 将数字按 1~3 位切分
+			total += math.Ceil(float64(n) / 3)
+		case r < utf8.RuneSelf:
+			n := 0
+			for i < len(text) && text[i] < utf8.RuneSelf {
+				c := rune(text[i])
+				if unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.IsSpace(c) {
+					break
+				}
+				n++
+				i++
+			}
+			total += math.Ceil(float64(n) / p.punctChars)
+		case unicode.IsPunct(r):
+			// 全角标点等通常为单个 token
+			total++
+			i += size
+		default:
+			// emoji 与其他符号：按 UTF-8 字节回退，约 2 字节一个 token
+			total += math.Ceil(float64(size) / 2)
+			i += size
+		}
+	}
+	return int(total)
+}
+
+func isWordStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
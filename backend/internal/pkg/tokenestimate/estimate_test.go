@@ -0,0 +1,121 @@
+package tokenestimate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForModel(t *testing.T) {
+	cases := map[string]Encoding{
+		"claude-sonnet-4-5":         EncodingClaude,
+		"gemini-2.5-pro":            EncodingClaude,
+		"gpt-4o-mini":               EncodingO200K,
+		"GPT-5":                     EncodingO200K,
+		"o3-mini":                   EncodingO200K,
+		"openai/gpt-4.1":            EncodingO200K,
+		"codex-mini-latest":         EncodingO200K,
+		"text-embedding-3-small":    EncodingO200K,
+		"":                          EncodingClaude,
+		"anthropic/claude-opus-4-1": EncodingClaude,
+	}
+	for model, want := range cases {
+		require.Equal(t, want, ForModel(model), model)
+	}
+}
+
+func TestCountText(t *testing.T) {
+	require.Equal(t, 0, CountText(EncodingClaude, ""))
+	require.Equal(t, 4, CountText(EncodingClaude, "Hello, world!"))
+	require.Equal(t, 10, CountText(EncodingClaude, "The quick brown fox jumps over the lazy dog."))
+
+	// 数字按 3 位切分
+	require.Equal(t, 3, CountText(EncodingClaude, "1234567"))
+	// CJK 文本每字约一个 token 以上
+	require.GreaterOrEqual(t, CountText(EncodingClaude, "你好世界"), 4)
+	// o200k 对 CJK 压缩率更高
+	require.Less(t, CountText(EncodingO200K, "今天天气很好，我们去公园散步吧"), CountText(EncodingClaude, "今天天气很好，我们去公园散步吧"))
+	// 未知编码按 Claude 估算
+	require.Equal(t, CountText(EncodingClaude, "Hello"), CountText(Encoding("unknown"), "Hello"))
+}
+
+func TestCountText_O200KUsesBPE(t *testing.T) {
+	require.NotNil(t, o200k())
+	require.Equal(t, 4, CountText(EncodingO200K, "Hello, world!"))
+	require.Equal(t, 10, CountText(EncodingO200K, "The quick brown fox jumps over the lazy dog."))
+	require.Equal(t, 1, CountText(EncodingO200K, "你好"))
+	// 特殊 token 字面量按普通文本计数
+	require.Greater(t, CountText(EncodingO200K, "<|endoftext|>"), 1)
+}
+
+func TestCountRequest_ClaudeMessages(t *testing.T) {
+	body := []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"Hello, world"}]}`)
+	require.Equal(t, 10, CountRequest("", body))
+
+	withSystem := []byte(`{"model":"claude-sonnet-4-5","system":[{"type":"text","text":"You are a helpful assistant."}],"messages":[{"role":"user","content":"Hello, world"}]}`)
+	require.Greater(t, CountRequest("", withSystem), CountRequest("", body))
+
+	withTools := []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"Hello, world"}],"tools":[{"name":"get_weather","description":"Get the weather","input_schema":{"type":"object","properties":{"city":{"type":"string"}}}}]}`)
+	require.Greater(t, CountRequest("", withTools)-CountRequest("", body), claudeToolSystemPromptTokens)
+
+	toolTurn := []byte(`{"model":"claude-sonnet-4-5","messages":[
+		{"role":"assistant","content":[{"type":"thinking","thinking":"Need the weather."},{"type":"tool_use","id":"t1","name":"get_weather","input":{"city":"Paris"}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"Sunny, 21C"}]}]}
+	]}`)
+	require.Greater(t, CountRequest("", toolTurn), 2*overheads[EncodingClaude].message+overheads[EncodingClaude].request)
+
+	require.Equal(t, 0, CountRequest("", nil))
+	require.Equal(t, 0, CountRequest("", []byte("not json")))
+}
+
+func TestCountRequest_Images(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 300, 200))))
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	claudeBody := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + data + `"}}]}]}`)
+	base := CountRequest("claude-sonnet-4-5", []byte(`{"messages":[{"role":"user","content":[]}]}`))
+	require.Equal(t, 80, CountRequest("claude-sonnet-4-5", claudeBody)-base) // 300*200/750
+
+	unknown := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"url","url":"https://example.com/a.png"}}]}]}`)
+	require.Equal(t, claudeDefaultImageTokens, CountRequest("claude-sonnet-4-5", unknown)-base)
+
+	openAIBase := CountRequest("gpt-4o", []byte(`{"messages":[{"role":"user","content":[]}]}`))
+	high := []byte(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,` + data + `"}}]}]}`)
+	require.Equal(t, openAIImageBaseTokens+openAIImageTileTokens, CountRequest("gpt-4o", high)-openAIBase)
+
+	low := []byte(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://example.com/a.png","detail":"low"}}]}]}`)
+	require.Equal(t, openAIImageBaseTokens, CountRequest("gpt-4o", low)-openAIBase)
+}
+
+func TestCountRequest_PDFDocument(t *testing.T) {
+	pdf := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\n1 0 obj <</Type /Page >>\n2 0 obj <</Type /Page >>\n3 0 obj <</Type /Pages /Count 2>>\n"))
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"` + pdf + `"}}]}]}`)
+	base := CountRequest("claude-sonnet-4-5", []byte(`{"messages":[{"role":"user","content":[]}]}`))
+	require.Equal(t, 2*claudePDFPageTokens, CountRequest("claude-sonnet-4-5", body)-base)
+}
+
+func TestCountRequest_OpenAIAndGemini(t *testing.T) {
+	responses := []byte(`{"model":"gpt-5","instructions":"Be brief.","input":[
+		{"role":"user","content":[{"type":"input_text","text":"What is the capital of France?"}]},
+		{"type":"function_call","name":"lookup","arguments":"{\"q\":\"France\"}"},
+		{"type":"function_call_output","call_id":"c1","output":"Paris"}
+	]}`)
+	require.Greater(t, CountRequest("", responses), 3*overheads[EncodingO200K].message)
+
+	gemini := []byte(`{"systemInstruction":{"parts":[{"text":"You are a helpful assistant."}]},"contents":[{"role":"user","parts":[{"text":"Hello, how are you?"}]}]}`)
+	require.Greater(t, CountRequest("gemini-2.5-pro", gemini), CountText(EncodingClaude, "Hello, how are you?"))
+}
+
+func TestMaxOutputTokens(t *testing.T) {
+	require.Equal(t, 1024, MaxOutputTokens([]byte(`{"max_tokens":1024}`)))
+	require.Equal(t, 256, MaxOutputTokens([]byte(`{"max_output_tokens":256}`)))
+	require.Equal(t, 64, MaxOutputTokens([]byte(`{"max_completion_tokens":64}`)))
+	require.Equal(t, 32, MaxOutputTokens([]byte(`{"generationConfig":{"maxOutputTokens":32}}`)))
+	require.Equal(t, 0, MaxOutputTokens([]byte(`{}`)))
+	require.Equal(t, 0, MaxOutputTokens(nil))
+}
@@ -0,0 +1,316 @@
+package tokenestimate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	_ "image/gif"  // 注册 GIF 解码器（仅读取尺寸）
+	_ "image/jpeg" // 注册 JPEG 解码器（仅读取尺寸）
+	_ "image/png"  // 注册 PNG 解码器（仅读取尺寸）
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// claudeToolSystemPromptTokens 启用工具时 Anthropic 注入的工具系统提示词 token 数
+	claudeToolSystemPromptTokens = 346
+	// claudeDefaultImageTokens 无法获取尺寸时按最大分辨率（约 1.15MP）估算
+	claudeDefaultImageTokens = 1600
+	// claudePDFPageTokens PDF 每页（文本 + 页面图像）的估算 token 数
+	claudePDFPageTokens = 1500
+
+	openAIImageBaseTokens = 85
+	openAIImageTileTokens = 170
+)
+
+var pdfPagePattern = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+// overhead 各编码族的消息结构开销
+type overhead struct {
+	request int
+	message int
+	tool    int
+}
+
+var overheads = map[Encoding]overhead{
+	EncodingClaude: {request: 3, message: 4, tool: 8},
+	EncodingO200K:  {request: 3, message: 3, tool: 8},
+}
+
+// CountRequest 估算请求体的输入 token 数。
+// 支持 Anthropic Messages、OpenAI Chat Completions / Responses 以及 Gemini generateContent 请求，
+// 涵盖 system、消息文本、工具定义、工具调用/结果、thinking、图片与 PDF 文档。
+// model 为空时使用请求体中的 model 字段选择编码族。
+func CountRequest(model string, body []byte) int {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return 0
+	}
+	root := gjson.ParseBytes(body)
+	if strings.TrimSpace(model) == "" {
+		model = root.Get("model").String()
+	}
+	c := &counter{enc: ForModel(model)}
+	oh := overheads[c.enc]
+
+	total := oh.request
+	// Anthropic system / OpenAI Responses instructions / Gemini systemInstruction
+	total += c.content(root.Get("system"))
+	total += c.content(root.Get("instructions"))
+	total += c.content(root.Get("systemInstruction.parts"))
+
+	for _, key := range []string{"messages", "input", "contents"} {
+		items := root.Get(key)
+		if items.Type == gjson.String {
+			total += oh.message + c.text(items.String())
+			continue
+		}
+		items.ForEach(func(_, item gjson.Result) bool {
+			total += oh.message + c.item(item)
+			return true
+		})
+	}
+
+	tools := root.Get("tools")
+	if tools.IsArray() && len(tools.Array()) > 0 {
+		if c.enc == EncodingClaude {
+			total += claudeToolSystemPromptTokens
+		}
+		tools.ForEach(func(_, tool gjson.Result) bool {
+			total += oh.tool + c.tool(tool)
+			return true
+		})
+	}
+	return total
+}
+
+// MaxOutputTokens 读取请求体中声明的最大输出 token 数；未声明时返回 0。
+// 支持 max_tokens、max_output_tokens、max_completion_tokens 与 generationConfig.maxOutputTokens。
+func MaxOutputTokens(body []byte) int {
+	if len(body) == 0 {
+		return 0
+	}
+	for _, path := range []string{"max_tokens", "max_output_tokens", "max_completion_tokens", "generationConfig.maxOutputTokens"} {
+		if v := gjson.GetBytes(body, path); v.Exists() && v.Int() > 0 {
+			return int(v.Int())
+		}
+	}
+	return 0
+}
+
+type counter struct {
+	enc Encoding
+}
+
+func (c *counter) text(s string) int {
+	return CountText(c.enc, s)
+}
+
+func (c *counter) json(v gjson.Result) int {
+	if !v.Exists() {
+		return 0
+	}
+	if v.Type == gjson.String {
+		return c.text(v.String())
+	}
+	return c.text(v.Raw)
+}
+
+// item 统计一条消息（或 Responses input item / Gemini content）。
+func (c *counter) item(item gjson.Result) int {
+	total := c.content(item.Get("content"))
+	total += c.content(item.Get("parts"))
+	// OpenAI Chat tool_calls
+	item.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+		total += c.text(call.Get("function.name").String()) + c.json(call.Get("function.arguments"))
+		return true
+	})
+	// OpenAI Responses 非消息 item（function_call / function_call_output / reasoning 等）
+	if !item.Get("content").Exists() && !item.Get("parts").Exists() {
+		total += c.block(item)
+	}
+	if name := item.Get("name"); name.Exists() && item.Get("role").Exists() {
+		total += c.text(name.String())
+	}
+	return total
+}
+
+// content 统计 content 字段：字符串、块数组或单个块对象。
+func (c *counter) content(v gjson.Result) int {
+	switch {
+	case !v.Exists():
+		return 0
+	case v.Type == gjson.String:
+		return c.text(v.String())
+	case v.IsArray():
+		total := 0
+		v.ForEach(func(_, block gjson.Result) bool {
+			total += c.block(block)
+			return true
+		})
+		return total
+	case v.IsObject():
+		return c.block(v)
+	}
+	return 0
+}
+
+func (c *counter) block(block gjson.Result) int {
+	if block.Type == gjson.String {
+		return c.text(block.String())
+	}
+	switch block.Get("type").String() {
+	case "text", "input_text", "output_text", "summary_text":
+		return c.text(block.Get("text").String())
+	case "thinking":
+		return c.text(block.Get("thinking").String())
+	case "redacted_thinking":
+		return c.text(block.Get("data").String())
+	case "image", "image_url", "input_image":
+		return c.image(block)
+	case "document", "input_file", "file":
+		return c.document(block)
+	case "tool_use", "server_tool_use", "function_call", "custom_tool_call":
+		return c.text(block.Get("name").String()) + c.json(block.Get("input")) + c.json(block.Get("arguments"))
+	case "tool_result", "web_search_tool_result":
+		return c.content(block.Get("content"))
+	case "function_call_output", "custom_tool_call_output":
+		return c.content(block.Get("output"))
+	case "reasoning":
+		return c.content(block.Get("summary"))
+	}
+	// Gemini parts
+	if t := block.Get("text"); t.Exists() {
+		return c.text(t.String())
+	}
+	if call := block.Get("functionCall"); call.Exists() {
+		return c.text(call.Get("name").String()) + c.json(call.Get("args"))
+	}
+	if resp := block.Get("functionResponse"); resp.Exists() {
+		return c.text(resp.Get("name").String()) + c.json(resp.Get("response"))
+	}
+	if inline := block.Get("inlineData"); inline.Exists() {
+		if strings.HasPrefix(inline.Get("mimeType").String(), "image/") {
+			return c.imageTokens(decodeImageSize(inline.Get("data").String()), "")
+		}
+	}
+	return 0
+}
+
+func (c *counter) tool(tool gjson.Result) int {
+	fn := tool
+	if f := tool.Get("function"); f.Exists() {
+		fn = f
+	}
+	total := c.text(fn.Get("name").String()) + c.text(fn.Get("description").String())
+	total += c.json(fn.Get("input_schema")) + c.json(fn.Get("parameters"))
+	// Gemini functionDeclarations
+	tool.Get("functionDeclarations").ForEach(func(_, decl gjson.Result) bool {
+		total += c.text(decl.Get("name").String()) + c.text(decl.Get("description").String()) + c.json(decl.Get("parameters"))
+		return true
+	})
+	return total
+}
+
+func (c *counter) image(block gjson.Result) int {
+	var data string
+	switch {
+	case block.Get("source.type").String() == "base64":
+		data = block.Get("source.data").String()
+	case block.Get("image_url.url").Exists():
+		data = block.Get("image_url.url").String()
+	case block.Get("image_url").Type == gjson.String:
+		data = block.Get("image_url").String()
+	}
+	detail := block.Get("image_url.detail").String()
+	if detail == "" {
+		detail = block.Get("detail").String()
+	}
+	return c.imageTokens(decodeImageSize(data), detail)
+}
+
+// imageTokens 按各家公开的图片计费规则估算；尺寸未知时取常见上限。
+func (c *counter) imageTokens(size *image.Config, detail string) int {
+	if c.enc == EncodingO200K {
+		if detail == "low" {
+			return openAIImageBaseTokens
+		}
+		w, h := 1024.0, 1024.0
+		if size != nil {
+			w, h = float64(size.Width), float64(size.Height)
+		}
+		if scale := math.Min(2048/w, 2048/h); scale < 1 {
+			w, h = w*scale, h*scale
+		}
+		if scale := 768 / math.Min(w, h); scale < 1 {
+			w, h = w*scale, h*scale
+		}
+		tiles := math.Ceil(w/512) * math.Ceil(h/512)
+		return openAIImageBaseTokens + openAIImageTileTokens*int(tiles)
+	}
+
+	if size == nil {
+		return claudeDefaultImageTokens
+	}
+	w, h := float64(size.Width), float64(size.Height)
+	if scale := 1568 / math.Max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	if pixels := w * h; pixels > 1150000 {
+		scale := math.Sqrt(1150000 / pixels)
+		w, h = w*scale, h*scale
+	}
+	return int(math.Ceil(w * h / 750))
+}
+
+func (c *counter) document(block gjson.Result) int {
+	source := block.Get("source")
+	switch source.Get("type").String() {
+	case "text":
+		return c.text(source.Get("data").String())
+	case "content":
+		return c.content(source.Get("content"))
+	case "base64":
+		if pages := countPDFPages(source.Get("data").String()); pages > 0 {
+			return pages * claudePDFPageTokens
+		}
+	}
+	if data := block.Get("file_data").String(); data != "" {
+		if pages := countPDFPages(data); pages > 0 {
+			return pages * claudePDFPageTokens
+		}
+	}
+	return claudePDFPageTokens
+}
+
+// decodeImageSize 从 base64 或 data URL 中读取图片尺寸；远程 URL 或不支持的格式返回 nil。
+func decodeImageSize(data string) *image.Config {
+	data = stripDataURLPrefix(data)
+	if data == "" || strings.HasPrefix(data, "http://") || strings.HasPrefix(data, "https://") {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil
+	}
+	return &cfg
+}
+
+func countPDFPages(data string) int {
+	decoded, err := base64.StdEncoding.DecodeString(stripDataURLPrefix(data))
+	if err != nil || !bytes.HasPrefix(decoded, []byte("%PDF")) {
+		return 0
+	}
+	return len(pdfPagePattern.FindAllIndex(decoded, -1))
+}
+
+func stripDataURLPrefix(data string) string {
+	if strings.HasPrefix(data, "data:") {
+		if idx := strings.Index(data, ","); idx >= 0 {
+			return data[idx+1:]
+		}
+	}
+	return data
+}
@@ -0,0 +1,30 @@
+package tracing
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+func OptionsFromConfig(cfg *config.Config, serviceVersion string) Options {
+	if cfg == nil {
+		return Options{}
+	}
+	serviceName := strings.TrimSpace(cfg.Tracing.ServiceName)
+	if serviceName == "" {
+		serviceName = cfg.Log.ServiceName
+	}
+	return Options{
+		Enabled:        cfg.Tracing.Enabled,
+		ServiceName:    serviceName,
+		ServiceVersion: serviceVersion,
+		Environment:    cfg.Log.Environment,
+		Exporter:       cfg.Tracing.Exporter,
+		Endpoint:       cfg.Tracing.Endpoint,
+		Insecure:       cfg.Tracing.Insecure,
+		Headers:        cfg.Tracing.Headers,
+		SampleRatio:    cfg.Tracing.SampleRatio,
+		ExportTimeout:  time.Duration(cfg.Tracing.ExportTimeoutSeconds) * time.Second,
+	}
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithClientTrace 将 DNS、建连（含代理）、TLS 握手等阶段作为事件记录到 ctx 当前 span 上。
+// span 未被采样时直接返回原 ctx。
+func WithClientTrace(ctx context.Context) context.Context {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			span.AddEvent("http.get_conn", trace.WithAttributes(attribute.String("net.host_port", hostPort)))
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			span.AddEvent("dns.start", trace.WithAttributes(attribute.String("net.host", info.Host)))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			span.AddEvent("dns.done", trace.WithAttributes(attribute.Bool("error", info.Err != nil)))
+		},
+		ConnectStart: func(network, addr string) {
+			span.AddEvent("connect.start", trace.WithAttributes(attribute.String("net.peer", addr)))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			span.AddEvent("connect.done", trace.WithAttributes(attribute.String("net.peer", addr), attribute.Bool("error", err != nil)))
+		},
+		TLSHandshakeStart: func() {
+			span.AddEvent("tls.handshake.start")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			span.AddEvent("tls.handshake.done", trace.WithAttributes(
+				attribute.String("tls.version", tls.VersionName(state.Version)),
+				attribute.Bool("tls.resumed", state.DidResume),
+				attribute.Bool("error", err != nil),
+			))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			span.AddEvent("http.got_conn", trace.WithAttributes(attribute.Bool("net.conn.reused", info.Reused)))
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			span.AddEvent("http.wrote_request", trace.WithAttributes(attribute.Bool("error", info.Err != nil)))
+		},
+		GotFirstResponseByte: func() {
+			span.AddEvent("http.first_response_byte")
+		},
+	})
+}
@@ -0,0 +1,203 @@
+// Package tracing 封装 OpenTelemetry 分布式追踪的初始化与常用辅助函数。
+//
+// 未启用时全局 TracerProvider 为 no-op，所有 Start/TraceID 调用均为零开销空操作。
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterOTLPHTTP = "otlp_http"
+	ExporterStdout   = "stdout"
+
+	// TraceIDHeader 响应头中返回的 trace id
+	TraceIDHeader = "X-Trace-Id"
+
+	instrumentationName   = "github.com/Wei-Shaw/sub2api"
+	defaultServiceName    = "sub2api"
+	defaultOTLPEndpoint   = "localhost:4318"
+	defaultExportTimeout  = 10 * time.Second
+	defaultShutdownBudget = 5 * time.Second
+)
+
+// Options 追踪初始化参数
+type Options struct {
+	Enabled bool
+	// ServiceName 上报的 service.name
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Exporter 导出方式：otlp_http / stdout
+	Exporter string
+	// Endpoint OTLP/HTTP 地址，可为 host:port 或完整 URL（如 http://collector:4318/v1/traces）
+	Endpoint string
+	Insecure bool
+	Headers  map[string]string
+	// SampleRatio 根 span 采样率（0-1）；携带 traceparent 的请求沿用上游采样决定
+	SampleRatio   float64
+	ExportTimeout time.Duration
+}
+
+// ShutdownFunc 刷新并关闭 exporter
+type ShutdownFunc func(ctx context.Context) error
+
+func noopShutdown(context.Context) error { return nil }
+
+func init() {
+	// 即便未启用导出，也需要能解析入站 traceparent 并透传 trace id
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Init 按配置安装全局 TracerProvider。未启用时返回空操作的 ShutdownFunc。
+func Init(ctx context.Context, opts Options) (ShutdownFunc, error) {
+	if !opts.Enabled {
+		return noopShutdown, nil
+	}
+	exporter, err := newExporter(ctx, opts)
+	if err != nil {
+		return noopShutdown, err
+	}
+
+	serviceName := strings.TrimSpace(opts.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	if v := strings.TrimSpace(opts.ServiceVersion); v != "" {
+		attrs = append(attrs, semconv.ServiceVersion(v))
+	}
+	if env := strings.TrimSpace(opts.Environment); env != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentName(env))
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		attrs = append(attrs, semconv.HostName(host))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, attrs...))
+	if err != nil {
+		res = resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(clampRatio(opts.SampleRatio)))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, defaultShutdownBudget)
+			defer cancel()
+		}
+		return tp.Shutdown(ctx)
+	}, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(strings.TrimSpace(opts.Exporter)) {
+	case "", ExporterOTLPHTTP:
+		timeout := opts.ExportTimeout
+		if timeout <= 0 {
+			timeout = defaultExportTimeout
+		}
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithTimeout(timeout)}
+		endpoint := strings.TrimSpace(opts.Endpoint)
+		if endpoint == "" {
+			endpoint = defaultOTLPEndpoint
+		}
+		if strings.Contains(endpoint, "://") {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(endpoint))
+		} else {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		if len(opts.Headers) > 0 {
+			clientOpts = append(clientOpts, otlptracehttp.WithHeaders(opts.Headers))
+		}
+		return otlptracehttp.New(ctx, clientOpts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", opts.Exporter)
+	}
+}
+
+func clampRatio(ratio float64) float64 {
+	switch {
+	case ratio <= 0:
+		return 0
+	case ratio >= 1:
+		return 1
+	default:
+		return ratio
+	}
+}
+
+// Tracer 返回项目统一的 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 创建子 span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan 记录错误（如有）并结束 span
+func EndSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceIDFromContext 返回当前 span 的 trace id，无有效 span 时返回空字符串
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// ContextWithSpanFrom 将 src 中的 span 上下文挂到 dst 上（dst 的取消/超时语义保持不变），
+// 用于异步任务（如计费）在请求结束后继续挂在同一条 trace 下。
+func ContextWithSpanFrom(dst, src context.Context) context.Context {
+	if dst == nil || src == nil {
+		return dst
+	}
+	sc := trace.SpanContextFromContext(src)
+	if !sc.IsValid() {
+		return dst
+	}
+	return trace.ContextWithSpanContext(dst, sc)
+}
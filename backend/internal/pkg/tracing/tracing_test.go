@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestInit_DisabledIsNoop(t *testing.T) {
+	shutdown, err := Init(context.Background(), Options{Enabled: false})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	ctx, span := Start(context.Background(), "noop")
+	defer span.End()
+	require.False(t, span.SpanContext().IsValid())
+	require.Empty(t, TraceIDFromContext(ctx))
+}
+
+func TestInit_UnsupportedExporter(t *testing.T) {
+	_, err := Init(context.Background(), Options{Enabled: true, Exporter: "zipkin"})
+	require.Error(t, err)
+}
+
+func TestInit_ExportsToLocalCollector(t *testing.T) {
+	var requests atomic.Int32
+	var gotPath, gotHeader atomic.Value
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		gotPath.Store(r.URL.Path)
+		gotHeader.Store(r.Header.Get("X-Collector-Token"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	shutdown, err := Init(context.Background(), Options{
+		Enabled:     true,
+		ServiceName: "sub2api-test",
+		Exporter:    ExporterOTLPHTTP,
+		Endpoint:    collector.URL + "/v1/traces",
+		Headers:     map[string]string{"X-Collector-Token": "secret"},
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	EndSpan(child, errors.New("boom"))
+	EndSpan(parent, nil)
+	require.NotEmpty(t, TraceIDFromContext(ctx))
+
+	require.NoError(t, shutdown(context.Background()))
+	require.GreaterOrEqual(t, requests.Load(), int32(1))
+	require.Equal(t, "/v1/traces", gotPath.Load())
+	require.Equal(t, "secret", gotHeader.Load())
+}
+
+func TestTraceIDFromContext_HonoursTraceparent(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	carrier := propagation.HeaderCarrier(http.Header{})
+	carrier.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+
+	ctx, span := Start(ctx, "server")
+	defer span.End()
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceIDFromContext(ctx))
+}
+
+func TestContextWithSpanFrom(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	src := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	srcCtx, cancel := context.WithCancel(src)
+	dst := ContextWithSpanFrom(context.Background(), srcCtx)
+	cancel()
+
+	require.NoError(t, dst.Err(), "dst must not inherit src cancellation")
+	require.Equal(t, traceID.String(), TraceIDFromContext(dst))
+
+	plain := context.Background()
+	require.Equal(t, plain, ContextWithSpanFrom(plain, context.Background()))
+}
+
+func TestClampRatio(t *testing.T) {
+	require.Equal(t, 0.0, clampRatio(-1))
+	require.Equal(t, 0.25, clampRatio(0.25))
+	require.Equal(t, 1.0, clampRatio(3))
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// accountTrippedKey 熔断账号有序集合：member=account_id，score=到期时间（Unix 毫秒）
+	accountTrippedKey = "account_cb:tripped"
+	// accountTripEventChannel 熔断/恢复事件频道，payload 为 "trip:<id>:<until_ms>" 或 "reset:<id>"
+	accountTripEventChannel = "account_cb:events"
+	// accountHealthBucketPrefix 分钟级健康度时间桶：hash field 为 "<id>:s" / "<id>:f"
+	accountHealthBucketPrefix = "account_health:minute:"
+	// accountHealthBucketTTL 时间桶保留时长，需大于健康度滑动窗口
+	accountHealthBucketTTL = 10 * time.Minute
+	// accountTrippedKeyTTL 有序集合整体过期时间，长时间无熔断时自动回收
+	accountTrippedKeyTTL = 24 * time.Hour
+)
+
+type accountHealthStore struct {
+	rdb *redis.Client
+}
+
+func NewAccountHealthStore(rdb *redis.Client) service.AccountHealthStore {
+	return &accountHealthStore{rdb: rdb}
+}
+
+func (s *accountHealthStore) TripAccount(ctx context.Context, accountID int64, until time.Time) error {
+	member := strconv.FormatInt(accountID, 10)
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, accountTrippedKey, redis.Z{Score: float64(until.UnixMilli()), Member: member})
+		pipe.ZRemRangeByScore(ctx, accountTrippedKey, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10))
+		pipe.Expire(ctx, accountTrippedKey, accountTrippedKeyTTL)
+		pipe.Publish(ctx, accountTripEventChannel, fmt.Sprintf("trip:%d:%d", accountID, until.UnixMilli()))
+		return nil
+	})
+	return err
+}
+
+func (s *accountHealthStore) ResetAccount(ctx context.Context, accountID int64) error {
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, accountTrippedKey, strconv.FormatInt(accountID, 10))
+		pipe.Publish(ctx, accountTripEventChannel, fmt.Sprintf("reset:%d", accountID))
+		return nil
+	})
+	return err
+}
+
+func (s *accountHealthStore) ListTrippedAccounts(ctx context.Context, now time.Time) (map[int64]time.Time, error) {
+	entries, err := s.rdb.ZRangeByScoreWithScores(ctx, accountTrippedKey, &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(now.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]time.Time, len(entries))
+	for _, z := range entries {
+		member, _ := z.Member.(string)
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil || id <= 0 {
+			continue
+		}
+		out[id] = time.UnixMilli(int64(z.Score))
+	}
+	return out, nil
+}
+
+func (s *accountHealthStore) SubscribeTripEvents(ctx context.Context, handler func(service.AccountTripEvent)) {
+	go func() {
+		sub := s.rdb.Subscribe(ctx, accountTripEventChannel)
+		defer func() { _ = sub.Close() }()
+
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				if msg == nil {
+					continue
+				}
+				event, err := parseAccountTripEvent(msg.Payload)
+				if err != nil {
+					log.Printf("[AccountHealthStore] ignore malformed trip event %q: %v", msg.Payload, err)
+					continue
+				}
+				handler(event)
+			}
+		}
+	}()
+}
+
+func (s *accountHealthStore) AddHealthCounts(ctx context.Context, minute int64, deltas map[int64]service.AccountHealthCounts) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	key := accountHealthBucketKey(minute)
+	_, err := s.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for id, c := range deltas {
+			if c.Success > 0 {
+				pipe.HIncrBy(ctx, key, accountHealthField(id, "s"), c.Success)
+			}
+			if c.Failures > 0 {
+				pipe.HIncrBy(ctx, key, accountHealthField(id, "f"), c.Failures)
+			}
+		}
+		pipe.Expire(ctx, key, accountHealthBucketTTL)
+		return nil
+	})
+	return err
+}
+
+func (s *accountHealthStore) GetHealthCounts(ctx context.Context, accountIDs []int64, fromMinute, toMinute int64) (map[int64]service.AccountHealthCounts, error) {
+	out := make(map[int64]service.AccountHealthCounts, len(accountIDs))
+	if len(accountIDs) == 0 || toMinute < fromMinute {
+		return out, nil
+	}
+
+	fields := make([]string, 0, len(accountIDs)*2)
+	for _, id := range accountIDs {
+		fields = append(fields, accountHealthField(id, "s"), accountHealthField(id, "f"))
+	}
+
+	cmds := make([]*redis.SliceCmd, 0, toMinute-fromMinute+1)
+	_, err := s.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for minute := fromMinute; minute <= toMinute; minute++ {
+			cmds = append(cmds, pipe.HMGet(ctx, accountHealthBucketKey(minute), fields...))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	for _, cmd := range cmds {
+		values, err := cmd.Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		for i, id := range accountIDs {
+			c := out[id]
+			c.Success += parseRedisInt64(values, i*2)
+			c.Failures += parseRedisInt64(values, i*2+1)
+			out[id] = c
+		}
+	}
+	return out, nil
+}
+
+func accountHealthBucketKey(minute int64) string {
+	return accountHealthBucketPrefix + strconv.FormatInt(minute, 10)
+}
+
+func accountHealthField(accountID int64, kind string) string {
+	return strconv.FormatInt(accountID, 10) + ":" + kind
+}
+
+func parseRedisInt64(values []any, idx int) int64 {
+	if idx >= len(values) || values[idx] == nil {
+		return 0
+	}
+	raw, ok := values[idx].(string)
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func parseAccountTripEvent(payload string) (service.AccountTripEvent, error) {
+	parts := strings.Split(payload, ":")
+	switch {
+	case len(parts) == 3 && parts[0] == "trip":
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return service.AccountTripEvent{}, err
+		}
+		untilMs, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return service.AccountTripEvent{}, err
+		}
+		return service.AccountTripEvent{AccountID: id, Until: time.UnixMilli(untilMs)}, nil
+	case len(parts) == 2 && parts[0] == "reset":
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return service.AccountTripEvent{}, err
+		}
+		return service.AccountTripEvent{AccountID: id}, nil
+	default:
+		return service.AccountTripEvent{}, fmt.Errorf("unknown event format")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build unit
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccountTripEvent(t *testing.T) {
+	event, err := parseAccountTripEvent("trip:42:1700000000123")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), event.AccountID)
+	require.Equal(t, time.UnixMilli(1700000000123), event.Until)
+
+	event, err = parseAccountTripEvent("reset:7")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), event.AccountID)
+	require.True(t, event.Until.IsZero())
+
+	for _, payload := range []string{"", "trip:1", "trip:x:1", "reset:x", "other:1"} {
+		_, err := parseAccountTripEvent(payload)
+		require.Error(t, err, payload)
+	}
+}
+
+func TestParseRedisInt64(t *testing.T) {
+	values := []any{"12", nil, "bad", int64(3)}
+	require.Equal(t, int64(12), parseRedisInt64(values, 0))
+	require.Equal(t, int64(0), parseRedisInt64(values, 1))
+	require.Equal(t, int64(0), parseRedisInt64(values, 2))
+	require.Equal(t, int64(0), parseRedisInt64(values, 3))
+	require.Equal(t, int64(0), parseRedisInt64(values, 9))
+}
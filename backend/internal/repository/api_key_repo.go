@@ -143,6 +143,8 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				user.FieldRole,
 				user.FieldBalance,
 				user.FieldConcurrency,
+				user.FieldBillingMode,
+				user.FieldCreditLimit,
 			)
 		}).
 		WithGroup(func(q *dbent.GroupQuery) {
@@ -169,11 +171,18 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldModelRoutingEnabled,
 				group.FieldModelRouting,
 				group.FieldModelAliases,
+				group.FieldModelPrices,
 				group.FieldConfigTemplates,
 				group.FieldMcpXMLInject,
 				group.FieldSupportedModelScopes,
 				group.FieldAllowMessagesDispatch,
 				group.FieldDefaultMappedModel,
+				group.FieldResponseCacheEnabled,
+				group.FieldResponseCacheTTLSeconds,
+				group.FieldResponseCacheHitMultiplier,
+				group.FieldHedgeBudgetPercent,
+				group.FieldScheduleStrategy,
+				group.FieldBillingReservationMode,
 			)
 		}).
 		Only(ctx)
@@ -607,6 +616,31 @@ func (r *apiKeyRepository) GetRateLimitData(ctx context.Context, id int64) (resu
 	return data, rows.Err()
 }
 
+// GetQuotaUsage returns the current quota limit and usage of an API key straight from the database,
+// bypassing the auth cache whose quota_used may lag behind recorded usage.
+func (r *apiKeyRepository) GetQuotaUsage(ctx context.Context, id int64) (quota, quotaUsed float64, err error) {
+	rows, err := r.sql.QueryContext(ctx, `
+		SELECT quota, quota_used
+		FROM api_keys
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	if !rows.Next() {
+		return 0, 0, service.ErrAPIKeyNotFound
+	}
+	if err := rows.Scan(&quota, &quotaUsed); err != nil {
+		return 0, 0, err
+	}
+	return quota, quotaUsed, rows.Err()
+}
+
 func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 	if m == nil {
 		return nil
@@ -663,6 +697,9 @@ func userEntityToService(u *dbent.User) *service.User {
 		Status:                u.Status,
 		SoraStorageQuotaBytes: u.SoraStorageQuotaBytes,
 		SoraStorageUsedBytes:  u.SoraStorageUsedBytes,
+		BillingMode:           u.BillingMode,
+		CreditLimit:           u.CreditLimit,
+		BillingCycleDay:       u.BillingCycleDay,
 		TotpSecretEncrypted:   u.TotpSecretEncrypted,
 		TotpEnabled:           u.TotpEnabled,
 		TotpEnabledAt:         u.TotpEnabledAt,
@@ -705,6 +742,7 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		ModelRouting:                    g.ModelRouting,
 		ModelRoutingEnabled:             g.ModelRoutingEnabled,
 		ModelAliases:                    g.ModelAliases,
+		ModelPrices:                     g.ModelPrices,
 		FallbackModel:                   g.FallbackModel,
 		MCPXMLInject:                    g.McpXMLInject,
 		SupportedModelScopes:            g.SupportedModelScopes,
@@ -713,6 +751,12 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		RequireOAuthOnly:                g.RequireOauthOnly,
 		RequirePrivacySet:               g.RequirePrivacySet,
 		DefaultMappedModel:              g.DefaultMappedModel,
+		ResponseCacheEnabled:            g.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         g.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      g.ResponseCacheHitMultiplier,
+		HedgeBudgetPercent:              g.HedgeBudgetPercent,
+		ScheduleStrategy:                g.ScheduleStrategy,
+		BillingReservationMode:          g.BillingReservationMode,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
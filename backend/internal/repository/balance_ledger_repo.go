@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type balanceLedgerRepository struct {
+	sql sqlExecutor
+}
+
+// NewBalanceLedgerRepository 余额账本仓储（原生 SQL，支持通过 context 参与 ent 事务）
+func NewBalanceLedgerRepository(sqlDB *sql.DB) service.BalanceLedgerRepository {
+	return &balanceLedgerRepository{sql: sqlDB}
+}
+
+const balanceLedgerColumns = `id, user_id, entry_type, amount, balance_after, reference_type, reference_id,
+	actor_type, actor_id, request_count, period_start, period_end, notes, created_at`
+
+// Apply 余额调整与流水写入放在同一条语句中，唯一索引冲突时整条语句回滚，余额不会被重复调整。
+// balance_after 为账本余额（上一条流水的 balance_after + 本次金额），在 users 行锁内计算。
+func (r *balanceLedgerRepository) Apply(ctx context.Context, entry *service.BalanceLedgerEntry) error {
+	if entry == nil {
+		return nil
+	}
+	if entry.Amount == 0 {
+		return service.ErrBalanceLedgerAmount
+	}
+	if entry.ActorType == "" {
+		entry.ActorType = service.BalanceLedgerActorSystem
+	}
+	err := r.withUserLock(ctx, entry.UserID, func(exec sqlExecutor) error {
+		return scanSingleRow(ctx, exec, `
+			WITH u AS (
+				UPDATE users
+				SET balance = balance + $2,
+					updated_at = NOW()
+				WHERE id = $1 AND deleted_at IS NULL
+				RETURNING id
+			)
+			INSERT INTO balance_ledger (
+				user_id, entry_type, amount, balance_after, reference_type, reference_id,
+				actor_type, actor_id, notes
+			)
+			SELECT u.id, $3, $2, `+balanceLedgerPrevBalance+` + $2, $4, $5, $6, $7, $8
+			FROM u
+			RETURNING id, balance_after, created_at
+		`, []any{
+			entry.UserID, entry.Amount, entry.EntryType, entry.ReferenceType, entry.ReferenceID,
+			entry.ActorType, nullInt64(entry.ActorID), entry.Notes,
+		}, &entry.ID, &entry.BalanceAfter, &entry.CreatedAt)
+	})
+	return translatePersistenceError(err, service.ErrUserNotFound, service.ErrBalanceLedgerDuplicate)
+}
+
+// balanceLedgerPrevBalance 用户最近一条流水的 balance_after（$1 为用户 ID），无流水时为 0
+const balanceLedgerPrevBalance = `COALESCE((SELECT balance_after FROM balance_ledger WHERE user_id = $1 ORDER BY id DESC LIMIT 1), 0)`
+
+// withUserLock 在事务中先锁定 users 行再执行 fn，使同一用户的流水按加锁顺序串行写入。
+// 加锁与写入分两条语句执行：READ COMMITTED 下后一条语句可见此前持锁事务已提交的流水。
+// 请求扣费同样先锁 users 行再累加待汇总表，加锁顺序一致，不会死锁。
+func (r *balanceLedgerRepository) withUserLock(ctx context.Context, userID int64, fn func(exec sqlExecutor) error) error {
+	lock := func(exec sqlExecutor) error {
+		_, err := exec.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID)
+		return err
+	}
+	if dbent.TxFromContext(ctx) != nil {
+		exec := sqlExecutorFromContext(ctx, r.sql)
+		if err := lock(exec); err != nil {
+			return err
+		}
+		return fn(exec)
+	}
+
+	db, ok := r.sql.(*sql.DB)
+	if !ok {
+		return errors.New("balance ledger repository requires *sql.DB for locked writes")
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lock(tx); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deductBalanceWithPendingUsage 请求扣费：扣减余额并在同一条语句中累加到待汇总表，
+// 由 BalanceLedgerService 周期性汇总为 usage 流水，避免每个请求写一条账本记录。
+func deductBalanceWithPendingUsage(ctx context.Context, exec sqlExecutor, userID int64, amount float64) error {
+	var updatedUserID int64
+	err := scanSingleRow(ctx, exec, `
+		WITH u AS (
+			UPDATE users
+			SET balance = balance - $2,
+				updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id
+		)
+		INSERT INTO balance_ledger_usage_pending (user_id, amount, request_count, first_at, last_at)
+		SELECT u.id, $2, 1, NOW(), NOW()
+		FROM u
+		ON CONFLICT (user_id) DO UPDATE
+		SET amount = balance_ledger_usage_pending.amount + EXCLUDED.amount,
+			request_count = balance_ledger_usage_pending.request_count + 1,
+			last_at = EXCLUDED.last_at
+		RETURNING user_id
+	`, []any{userID, amount}, &updatedUserID)
+	if err == sql.ErrNoRows {
+		return service.ErrUserNotFound
+	}
+	return err
+}
+
+// insertOpeningBalanceEntry 新建用户带初始余额时写入 opening 流水，作为该用户账本的起点
+func insertOpeningBalanceEntry(ctx context.Context, exec sqlExecutor, userID int64, balance float64) error {
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO balance_ledger (
+			user_id, entry_type, amount, balance_after, reference_type, reference_id, actor_type, notes
+		) VALUES ($1, $2, $3, $3, $4, $5, $6, 'initial balance')
+	`, userID, service.BalanceLedgerEntryOpening, balance, service.BalanceLedgerRefUser, fmt.Sprintf("%d", userID), service.BalanceLedgerActorSystem)
+	return err
+}
+
+func (r *balanceLedgerRepository) ListByUser(ctx context.Context, userID int64, params pagination.PaginationParams, filter service.BalanceLedgerFilter) ([]service.BalanceLedgerEntry, *pagination.PaginationResult, error) {
+	where, args := balanceLedgerWhere(userID, filter)
+	exec := sqlExecutorFromContext(ctx, r.sql)
+
+	var total int64
+	if err := scanSingleRow(ctx, exec, `SELECT COUNT(*) FROM balance_ledger `+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+
+	limitArgs := append(append([]any{}, args...), params.Limit(), params.Offset())
+	rows, err := exec.QueryContext(ctx, fmt.Sprintf(
+		`SELECT %s FROM balance_ledger %s ORDER BY id DESC LIMIT $%d OFFSET $%d`,
+		balanceLedgerColumns, where, len(args)+1, len(args)+2,
+	), limitArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]service.BalanceLedgerEntry, 0)
+	for rows.Next() {
+		entry, err := scanBalanceLedgerEntry(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return entries, paginationResultFromTotal(total, params), nil
+}
+
+func (r *balanceLedgerRepository) SummarizeByUser(ctx context.Context, userID int64, filter service.BalanceLedgerFilter) ([]service.BalanceLedgerTypeTotal, error) {
+	where, args := balanceLedgerWhere(userID, filter)
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT entry_type, COALESCE(SUM(amount), 0), COUNT(*)
+		FROM balance_ledger `+where+`
+		GROUP BY entry_type
+		ORDER BY entry_type
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	totals := make([]service.BalanceLedgerTypeTotal, 0)
+	for rows.Next() {
+		var t service.BalanceLedgerTypeTotal
+		if err := rows.Scan(&t.EntryType, &t.Amount, &t.Count); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+func (r *balanceLedgerRepository) GetPendingUsage(ctx context.Context, userID int64) (*service.BalancePendingUsage, error) {
+	out := &service.BalancePendingUsage{}
+	var firstAt, lastAt sql.NullTime
+	err := scanSingleRow(ctx, sqlExecutorFromContext(ctx, r.sql), `
+		SELECT amount, request_count, first_at, last_at
+		FROM balance_ledger_usage_pending
+		WHERE user_id = $1
+	`, []any{userID}, &out.Amount, &out.RequestCount, &firstAt, &lastAt)
+	if err == sql.ErrNoRows {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if firstAt.Valid {
+		out.FirstAt = &firstAt.Time
+	}
+	if lastAt.Valid {
+		out.LastAt = &lastAt.Time
+	}
+	return out, nil
+}
+
+func (r *balanceLedgerRepository) ListByIDs(ctx context.Context, ids []int64) ([]service.BalanceLedgerEntry, error) {
+	entries := make([]service.BalanceLedgerEntry, 0, len(ids))
+	if len(ids) == 0 {
+		return entries, nil
+	}
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx,
+		`SELECT `+balanceLedgerColumns+` FROM balance_ledger WHERE id = ANY($1) ORDER BY id DESC`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		entry, err := scanBalanceLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *balanceLedgerRepository) SumCreditsByUser(ctx context.Context, userID int64) (float64, error) {
+	var total float64
+	err := scanSingleRow(ctx, sqlExecutorFromContext(ctx, r.sql), `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM balance_ledger
+		WHERE user_id = $1 AND amount > 0 AND entry_type = ANY($2)
+	`, []any{userID, pq.Array([]string{
+		service.BalanceLedgerEntryTopup,
+		service.BalanceLedgerEntryRedeem,
+		service.BalanceLedgerEntryPromo,
+		service.BalanceLedgerEntryAdminAdjust,
+	})}, &total)
+	return total, err
+}
+
+// ListHistoryByUser 账本流水与已使用的非余额类兑换码按发生时间合并分页；
+// 余额类兑换码（balance / admin_balance）的入账已记录在账本中，不再重复列出。
+func (r *balanceLedgerRepository) ListHistoryByUser(ctx context.Context, userID int64, params pagination.PaginationParams, historyType string) ([]service.BalanceHistoryRef, *pagination.PaginationResult, error) {
+	args := []any{userID}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	ledgerPart := `SELECT '` + service.BalanceHistorySourceLedger + `' AS source, id, created_at AS occurred_at
+		FROM balance_ledger WHERE user_id = $1`
+	redeemPart := `SELECT '` + service.BalanceHistorySourceRedeemCode + `' AS source, id, COALESCE(used_at, created_at) AS occurred_at
+		FROM redeem_codes WHERE used_by = $1`
+
+	var parts []string
+	switch historyType {
+	case "":
+		parts = []string{ledgerPart, redeemPart + " AND type <> ALL(" + addArg(pq.Array([]string{service.RedeemTypeBalance, service.AdjustmentTypeAdminBalance})) + ")"}
+	case service.RedeemTypeBalance:
+		parts = []string{ledgerPart + " AND entry_type <> " + addArg(service.BalanceLedgerEntryAdminAdjust)}
+	case service.AdjustmentTypeAdminBalance:
+		parts = []string{ledgerPart + " AND entry_type = " + addArg(service.BalanceLedgerEntryAdminAdjust)}
+	default:
+		parts = []string{redeemPart + " AND type = " + addArg(historyType)}
+	}
+	union := strings.Join(parts, " UNION ALL ")
+	exec := sqlExecutorFromContext(ctx, r.sql)
+
+	var total int64
+	if err := scanSingleRow(ctx, exec, `SELECT COUNT(*) FROM (`+union+`) h`, args, &total); err != nil {
+		return nil, nil, err
+	}
+
+	limitArgs := append(append([]any{}, args...), params.Limit(), params.Offset())
+	rows, err := exec.QueryContext(ctx, fmt.Sprintf(
+		`SELECT source, id FROM (%s) h ORDER BY occurred_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+		union, len(args)+1, len(args)+2,
+	), limitArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	refs := make([]service.BalanceHistoryRef, 0)
+	for rows.Next() {
+		var ref service.BalanceHistoryRef
+		if err := rows.Scan(&ref.Source, &ref.ID); err != nil {
+			return nil, nil, err
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return refs, paginationResultFromTotal(total, params), nil
+}
+
+func (r *balanceLedgerRepository) ListPendingUsageUsers(ctx context.Context, limit int) ([]int64, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT user_id FROM balance_ledger_usage_pending ORDER BY first_at LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	userIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// FlushPendingUsage 删除待汇总行并写入 usage 流水在同一条语句中完成，balance_after 在 users 行锁内
+// 按上一条流水累计；持锁期间没有新的扣费，汇总的正是截至此刻的全部待汇总扣费。
+func (r *balanceLedgerRepository) FlushPendingUsage(ctx context.Context, userID int64) (*service.BalanceLedgerEntry, error) {
+	var entry *service.BalanceLedgerEntry
+	err := r.withUserLock(ctx, userID, func(exec sqlExecutor) error {
+		rows, err := exec.QueryContext(ctx, `
+			WITH p AS (
+				DELETE FROM balance_ledger_usage_pending
+				WHERE user_id = $1
+				RETURNING user_id, amount, request_count, first_at, last_at
+			)
+			INSERT INTO balance_ledger (
+				user_id, entry_type, amount, balance_after, actor_type,
+				request_count, period_start, period_end
+			)
+			SELECT p.user_id, $2, -p.amount, `+balanceLedgerPrevBalance+` - p.amount, $3,
+				p.request_count, p.first_at, p.last_at
+			FROM p
+			WHERE p.amount <> 0
+			RETURNING `+balanceLedgerColumns,
+			userID, service.BalanceLedgerEntryUsage, service.BalanceLedgerActorSystem)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+		if rows.Next() {
+			if entry, err = scanBalanceLedgerEntry(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// FindDrift 在同一快照内比较 users.balance 与 SUM(账本) - 待汇总扣费。
+// 余额调整与流水 / 待汇总累加始终在同一语句或事务中提交，因此一致时二者严格相等。
+func (r *balanceLedgerRepository) FindDrift(ctx context.Context, tolerance float64, limit int) ([]service.BalanceDrift, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT u.id, u.email, u.balance, COALESCE(l.total, 0), COALESCE(p.amount, 0),
+			u.balance - (COALESCE(l.total, 0) - COALESCE(p.amount, 0)) AS drift
+		FROM users u
+		LEFT JOIN (
+			SELECT user_id, SUM(amount) AS total FROM balance_ledger GROUP BY user_id
+		) l ON l.user_id = u.id
+		LEFT JOIN balance_ledger_usage_pending p ON p.user_id = u.id
+		WHERE u.deleted_at IS NULL
+			AND ABS(u.balance - (COALESCE(l.total, 0) - COALESCE(p.amount, 0))) > $1
+		ORDER BY ABS(u.balance - (COALESCE(l.total, 0) - COALESCE(p.amount, 0))) DESC
+		LIMIT $2
+	`, tolerance, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	drifts := make([]service.BalanceDrift, 0)
+	for rows.Next() {
+		var d service.BalanceDrift
+		if err := rows.Scan(&d.UserID, &d.Email, &d.Balance, &d.LedgerTotal, &d.PendingUsage, &d.Drift); err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, d)
+	}
+	return drifts, rows.Err()
+}
+
+func balanceLedgerWhere(userID int64, filter service.BalanceLedgerFilter) (string, []any) {
+	conds := []string{"user_id = $1"}
+	args := []any{userID}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if t := strings.TrimSpace(filter.EntryType); t != "" {
+		conds = append(conds, "entry_type = "+addArg(t))
+	}
+	if filter.StartTime != nil {
+		conds = append(conds, "created_at >= "+addArg(*filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		conds = append(conds, "created_at < "+addArg(*filter.EndTime))
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func scanBalanceLedgerEntry(rows *sql.Rows) (*service.BalanceLedgerEntry, error) {
+	var (
+		e           service.BalanceLedgerEntry
+		actorID     sql.NullInt64
+		periodStart sql.NullTime
+		periodEnd   sql.NullTime
+	)
+	if err := rows.Scan(
+		&e.ID, &e.UserID, &e.EntryType, &e.Amount, &e.BalanceAfter, &e.ReferenceType, &e.ReferenceID,
+		&e.ActorType, &actorID, &e.RequestCount, &periodStart, &periodEnd, &e.Notes, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if actorID.Valid {
+		e.ActorID = &actorID.Int64
+	}
+	if periodStart.Valid {
+		t := periodStart.Time
+		e.PeriodStart = &t
+	}
+	if periodEnd.Valid {
+		t := periodEnd.Time
+		e.PeriodEnd = &t
+	}
+	return &e, nil
+}
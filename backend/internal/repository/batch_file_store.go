@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// NewBatchFileStore 根据 batch.storage 配置返回本地磁盘或 S3 文件存储。
+func NewBatchFileStore(cfg *config.Config, s3Factory service.BackupObjectStoreFactory) service.BatchFileStore {
+	if cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Batch.Storage), "s3") {
+		return newS3FileStore(s3Factory, cfg.Batch.S3, "application/jsonl")
+	}
+	localPath := ""
+	if cfg != nil {
+		localPath = cfg.Batch.LocalPath
+	}
+	return &localFileStore{root: config.ResolveBatchStorageRoot(localPath)}
+}
+
+// localFileStore 将文件存放在本地磁盘（batch 文件 / 载荷抓取共用）
+type localFileStore struct {
+	root string
+}
+
+func (s *localFileStore) resolve(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if cleaned == "." || filepath.IsAbs(cleaned) || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) || cleaned == ".." {
+		return "", fmt.Errorf("invalid file key: %q", key)
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
+
+func (s *localFileStore) Put(_ context.Context, key string, body io.Reader) (int64, error) {
+	target, err := s.resolve(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, fmt.Errorf("create file dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("create temp file: %w", err)
+	}
+	n, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, fmt.Errorf("write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, fmt.Errorf("rename file: %w", err)
+	}
+	return n, nil
+}
+
+func (s *localFileStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(target)
+}
+
+func (s *localFileStore) Delete(_ context.Context, key string) error {
+	target, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// s3FileStore 复用备份使用的 S3 兼容存储，客户端按需初始化
+type s3FileStore struct {
+	factory     service.BackupObjectStoreFactory
+	cfg         *service.BackupS3Config
+	prefix      string
+	contentType string
+
+	mu    sync.Mutex
+	store service.BackupObjectStore
+}
+
+func newS3FileStore(factory service.BackupObjectStoreFactory, s3 config.BatchS3Config, contentType string) *s3FileStore {
+	return &s3FileStore{
+		factory:     factory,
+		prefix:      s3.Prefix,
+		contentType: contentType,
+		cfg: &service.BackupS3Config{
+			Endpoint:        s3.Endpoint,
+			Region:          s3.Region,
+			Bucket:          s3.Bucket,
+			AccessKeyID:     s3.AccessKeyID,
+			SecretAccessKey: s3.SecretAccessKey,
+			Prefix:          s3.Prefix,
+			ForcePathStyle:  s3.ForcePathStyle,
+		},
+	}
+}
+
+func (s *s3FileStore) client(ctx context.Context) (service.BackupObjectStore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != nil {
+		return s.store, nil
+	}
+	if s.factory == nil {
+		return nil, fmt.Errorf("s3 store factory not configured")
+	}
+	store, err := s.factory(ctx, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.store = store
+	return store, nil
+}
+
+func (s *s3FileStore) objectKey(key string) string {
+	prefix := strings.Trim(strings.TrimSpace(s.prefix), "/")
+	if prefix == "" {
+		return key
+	}
+	return path.Join(prefix, key)
+}
+
+func (s *s3FileStore) Put(ctx context.Context, key string, body io.Reader) (int64, error) {
+	store, err := s.client(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return store.Upload(ctx, s.objectKey(key), body, s.contentType)
+}
+
+func (s *s3FileStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	store, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Download(ctx, s.objectKey(key))
+}
+
+func (s *s3FileStore) Delete(ctx context.Context, key string) error {
+	store, err := s.client(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, s.objectKey(key))
+}
@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type batchRepository struct {
+	db *sql.DB
+}
+
+func NewBatchRepository(db *sql.DB) service.BatchRepository {
+	return &batchRepository{db: db}
+}
+
+// --- Files ---
+
+const batchFileColumns = `id, user_id, api_key_id, purpose, filename, bytes, storage_key, created_at`
+
+func (r *batchRepository) CreateFile(ctx context.Context, file *service.BatchFile) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO batch_files (id, user_id, api_key_id, purpose, filename, bytes, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`, file.ID, file.UserID, file.APIKeyID, file.Purpose, file.Filename, file.Bytes, file.StorageKey).Scan(&file.CreatedAt)
+}
+
+func (r *batchRepository) GetFile(ctx context.Context, userID int64, fileID string) (*service.BatchFile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+batchFileColumns+`
+		FROM batch_files
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, fileID, userID)
+	return scanBatchFile(row)
+}
+
+func (r *batchRepository) ListFiles(ctx context.Context, userID int64, purpose string, after string, limit int) ([]service.BatchFile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+batchFileColumns+`
+		FROM batch_files
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND ($2 = '' OR purpose = $2) AND purpose <> $5
+		  AND ($3 = '' OR created_at < (SELECT created_at FROM batch_files WHERE id = $3 AND user_id = $1))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, userID, purpose, after, limit, service.BatchFilePurposeMessageBatch)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var files []service.BatchFile
+	for rows.Next() {
+		f, err := scanBatchFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *f)
+	}
+	return files, rows.Err()
+}
+
+func (r *batchRepository) DeleteFile(ctx context.Context, userID int64, fileID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE batch_files SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, fileID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// --- Jobs ---
+
+const batchJobColumns = `id, api_format, user_id, api_key_id, group_id, endpoint, input_file_id, output_file_id, error_file_id,
+	completion_window, status, total_count, completed_count, failed_count, metadata, errors, client_ip,
+	created_at, in_progress_at, finalizing_at, completed_at, failed_at, cancelling_at, cancelled_at, expired_at,
+	expires_at, updated_at`
+
+func (r *batchRepository) CreateJob(ctx context.Context, job *service.BatchJob) error {
+	metadata, err := marshalNullableJSON(job.Metadata, len(job.Metadata) > 0)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO batch_jobs (id, api_format, user_id, api_key_id, group_id, endpoint, input_file_id, completion_window, status, metadata, client_ip, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, job.ID, job.APIFormat, job.UserID, job.APIKeyID, job.GroupID, job.Endpoint, job.InputFileID, job.CompletionWindow, job.Status, metadata, job.ClientIP, job.ExpiresAt).
+		Scan(&job.CreatedAt, &job.UpdatedAt)
+}
+
+func (r *batchRepository) GetJob(ctx context.Context, userID int64, batchID string) (*service.BatchJob, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+batchJobColumns+`
+		FROM batch_jobs
+		WHERE id = $1 AND user_id = $2
+	`, batchID, userID)
+	return scanBatchJob(row)
+}
+
+func (r *batchRepository) ListJobs(ctx context.Context, userID int64, apiFormat string, after string, limit int) ([]service.BatchJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+batchJobColumns+`
+		FROM batch_jobs
+		WHERE user_id = $1 AND api_format = $2
+		  AND ($3 = '' OR created_at < (SELECT created_at FROM batch_jobs WHERE id = $3 AND user_id = $1))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, userID, apiFormat, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []service.BatchJob
+	for rows.Next() {
+		job, err := scanBatchJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *batchRepository) ClaimNextJob(ctx context.Context) (*service.BatchJob, error) {
+	row := r.db.QueryRowContext(ctx, `
+		WITH next AS (
+			SELECT id AS next_id
+			FROM batch_jobs
+			WHERE status = $1
+			ORDER BY created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE batch_jobs
+		SET status = $2, in_progress_at = NOW(), updated_at = NOW()
+		FROM next
+		WHERE batch_jobs.id = next.next_id
+		RETURNING `+batchJobColumns+`
+	`, service.BatchStatusValidating, service.BatchStatusInProgress)
+	job, err := scanBatchJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *batchRepository) GetJobStatus(ctx context.Context, batchID string) (string, error) {
+	var status string
+	err := r.db.QueryRowContext(ctx, `SELECT status FROM batch_jobs WHERE id = $1`, batchID).Scan(&status)
+	return status, err
+}
+
+func (r *batchRepository) UpdateJobProgress(ctx context.Context, batchID string, total, completed, failed int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE batch_jobs
+		SET total_count = $2, completed_count = $3, failed_count = $4, updated_at = NOW()
+		WHERE id = $1
+	`, batchID, total, completed, failed)
+	return err
+}
+
+func (r *batchRepository) RequestCancel(ctx context.Context, userID int64, batchID string) (string, bool, error) {
+	var status string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE batch_jobs
+		SET status = CASE WHEN status = $3 THEN $5 ELSE $6 END,
+			cancelled_at = CASE WHEN status = $3 THEN NOW() ELSE cancelled_at END,
+			cancelling_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status IN ($3, $4)
+		RETURNING status
+	`, batchID, userID, service.BatchStatusValidating, service.BatchStatusInProgress, service.BatchStatusCancelled, service.BatchStatusCancelling).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return status, true, nil
+}
+
+func (r *batchRepository) FinishJob(ctx context.Context, batchID string, result *service.BatchJobResult) error {
+	errs, err := marshalNullableJSON(result.Errors, len(result.Errors) > 0)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE batch_jobs
+		SET status = $2,
+			output_file_id = $3,
+			error_file_id = $4,
+			total_count = $5,
+			completed_count = $6,
+			failed_count = $7,
+			errors = $8,
+			finalizing_at = CASE WHEN $2 = 'completed' THEN NOW() ELSE finalizing_at END,
+			completed_at = CASE WHEN $2 = 'completed' THEN NOW() ELSE completed_at END,
+			failed_at = CASE WHEN $2 = 'failed' THEN NOW() ELSE failed_at END,
+			cancelled_at = CASE WHEN $2 = 'cancelled' THEN NOW() ELSE cancelled_at END,
+			expired_at = CASE WHEN $2 = 'expired' THEN NOW() ELSE expired_at END,
+			updated_at = NOW()
+		WHERE id = $1
+	`, batchID, result.Status, result.OutputFileID, result.ErrorFileID, result.TotalCount, result.CompletedCount, result.FailedCount, errs)
+	return err
+}
+
+func (r *batchRepository) FailStaleJobs(ctx context.Context, staleAfterSeconds int64, message string) (int64, error) {
+	errs, err := json.Marshal([]service.BatchError{{Code: "interrupted", Message: message}})
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE batch_jobs
+		SET status = $1, failed_at = NOW(), errors = $2, updated_at = NOW()
+		WHERE status IN ($3, $4) AND updated_at < NOW() - ($5 * interval '1 second')
+	`, service.BatchStatusFailed, errs, service.BatchStatusInProgress, service.BatchStatusCancelling, staleAfterSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// --- scan helpers ---
+
+func scanBatchFile(row scannable) (*service.BatchFile, error) {
+	f := &service.BatchFile{}
+	if err := row.Scan(&f.ID, &f.UserID, &f.APIKeyID, &f.Purpose, &f.Filename, &f.Bytes, &f.StorageKey, &f.CreatedAt); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func scanBatchJob(row scannable) (*service.BatchJob, error) {
+	var (
+		job      service.BatchJob
+		groupID  sql.NullInt64
+		outputID sql.NullString
+		errorID  sql.NullString
+		metadata []byte
+		errs     []byte
+	)
+	if err := row.Scan(
+		&job.ID, &job.APIFormat, &job.UserID, &job.APIKeyID, &groupID, &job.Endpoint, &job.InputFileID, &outputID, &errorID,
+		&job.CompletionWindow, &job.Status, &job.TotalCount, &job.CompletedCount, &job.FailedCount, &metadata, &errs, &job.ClientIP,
+		&job.CreatedAt, &job.InProgressAt, &job.FinalizingAt, &job.CompletedAt, &job.FailedAt, &job.CancellingAt, &job.CancelledAt, &job.ExpiredAt,
+		&job.ExpiresAt, &job.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if groupID.Valid {
+		job.GroupID = &groupID.Int64
+	}
+	if outputID.Valid {
+		job.OutputFileID = &outputID.String
+	}
+	if errorID.Valid {
+		job.ErrorFileID = &errorID.String
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &job.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if len(errs) > 0 {
+		if err := json.Unmarshal(errs, &job.Errors); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
+
+func marshalNullableJSON(v any, present bool) (any, error) {
+	if !present {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
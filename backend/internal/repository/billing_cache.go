@@ -17,6 +17,7 @@ const (
 	billingBalanceKeyPrefix   = "billing:balance:"
 	billingSubKeyPrefix       = "billing:sub:"
 	billingRateLimitKeyPrefix = "apikey:rate:"
+	billingHoldKeyPrefix      = "billing:hold:"
 	billingCacheTTL           = 5 * time.Minute
 	billingCacheJitter        = 30 * time.Second
 	rateLimitCacheTTL         = 7 * 24 * time.Hour // 7 days matches the longest window
@@ -61,6 +62,12 @@ func billingRateLimitKey(keyID int64) string {
 	return fmt.Sprintf("%s%d", billingRateLimitKeyPrefix, keyID)
 }
 
+// billingHoldKey generates the Redis key for reservation holds of a pool owner.
+// Each hash field is a hold ID with value "amount:expires_at_unix".
+func billingHoldKey(pool string, ownerID int64) string {
+	return fmt.Sprintf("%s%s:%d", billingHoldKeyPrefix, pool, ownerID)
+}
+
 const (
 	rateLimitFieldUsage5h  = "usage_5h"
 	rateLimitFieldUsage1d  = "usage_1d"
@@ -132,6 +139,83 @@ var (
 		redis.call('EXPIRE', KEYS[1], ARGV[2])
 		return 1
 	`)
+
+	// reserveBillingHoldsScript atomically reserves an amount on every pool, or nothing.
+	// For each pool the available amount minus active (non-expired) holds must cover the
+	// requested amount; expired holds are pruned on the way. Balance pools read the
+	// available amount from the balance cache when present, plus the pool's credit
+	// (postpaid users may go negative down to -credit).
+	//
+	// Only real keys are passed in KEYS (Redis Cluster rejects empty key names); which
+	// pool reads a balance cache key is signalled through ARGV.
+	//
+	// KEYS: [1..n]=hold hash key per pool, followed by the balance cache keys of balance pools
+	// ARGV: [1]=hold_id, [2]=now_unix, [3]=expires_at_unix, [4]=key_ttl_seconds,
+	//       per pool i: [1+4i]=amount, [2+4i]=available, [3+4i]=credit,
+	//       [4+4i]=KEYS index of the pool's balance cache key (0 when none)
+	// Returns the 1-based index of the first rejected pool, or 0 on success.
+	reserveBillingHoldsScript = redis.NewScript(`
+		local n = (#ARGV - 4) / 4
+		local now = tonumber(ARGV[2])
+		for i = 1, n do
+			local holdKey = KEYS[i]
+			local amount = tonumber(ARGV[1 + 4 * i])
+			local available = tonumber(ARGV[2 + 4 * i])
+			local sourceIndex = tonumber(ARGV[4 + 4 * i])
+			if sourceIndex > 0 then
+				local cached = redis.call('GET', KEYS[sourceIndex])
+				if cached then
+					available = tonumber(cached) + tonumber(ARGV[3 + 4 * i])
+				end
+			end
+			local held = 0
+			local entries = redis.call('HGETALL', holdKey)
+			for j = 1, #entries, 2 do
+				local value = entries[j + 1]
+				local sep = string.find(value, ':', 1, true)
+				local expiresAt = sep and tonumber(string.sub(value, sep + 1)) or 0
+				if expiresAt <= now then
+					redis.call('HDEL', holdKey, entries[j])
+				else
+					held = held + tonumber(string.sub(value, 1, sep - 1))
+				end
+			end
+			if available - held < amount then
+				return i
+			end
+		end
+		for i = 1, n do
+			redis.call('HSET', KEYS[i], ARGV[1], ARGV[1 + 4 * i] .. ':' .. ARGV[3])
+			redis.call('EXPIRE', KEYS[i], ARGV[4])
+		end
+		return 0
+	`)
+
+	// settleBillingHoldsScript deducts the actual cost from the balance cache (if cached)
+	// and removes the hold from every pool in one step, so the hold is never released
+	// before the charge becomes visible to other reservations.
+	//
+	// KEYS: hold hash keys, followed by the balance cache key when ARGV[4] is 1
+	// ARGV: [1]=hold_id, [2]=balance_cost, [3]=balance_ttl_seconds, [4]=1 if a balance cache key is passed
+	settleBillingHoldsScript = redis.NewScript(`
+		local holdCount = #KEYS
+		if ARGV[4] == '1' then
+			holdCount = holdCount - 1
+			local cost = tonumber(ARGV[2])
+			local balanceKey = KEYS[#KEYS]
+			if cost > 0 then
+				local current = redis.call('GET', balanceKey)
+				if current then
+					redis.call('SET', balanceKey, tonumber(current) - cost)
+					redis.call('EXPIRE', balanceKey, ARGV[3])
+				end
+			end
+		end
+		for i = 1, holdCount do
+			redis.call('HDEL', KEYS[i], ARGV[1])
+		end
+		return 1
+	`)
 )
 
 type billingCache struct {
@@ -328,3 +412,70 @@ func (c *billingCache) InvalidateAPIKeyRateLimit(ctx context.Context, keyID int6
 	key := billingRateLimitKey(keyID)
 	return c.rdb.Del(ctx, key).Err()
 }
+
+func (c *billingCache) ReserveBillingHolds(ctx context.Context, holdID string, holds []service.BillingHold, ttl time.Duration) (int, error) {
+	if len(holds) == 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	keys := make([]string, 0, len(holds)+1)
+	for _, h := range holds {
+		keys = append(keys, billingHoldKey(h.Pool, h.OwnerID))
+	}
+	args := make([]any, 0, 4+len(holds)*4)
+	args = append(args, holdID, now.Unix(), now.Add(ttl).Unix(), int((ttl + time.Minute).Seconds()))
+	for _, h := range holds {
+		sourceIndex := 0
+		if h.Pool == service.BillingHoldPoolBalance {
+			keys = append(keys, billingBalanceKey(h.OwnerID))
+			sourceIndex = len(keys)
+		}
+		args = append(args,
+			strconv.FormatFloat(h.Amount, 'f', -1, 64),
+			strconv.FormatFloat(h.Available, 'f', -1, 64),
+			strconv.FormatFloat(h.Credit, 'f', -1, 64),
+			sourceIndex,
+		)
+	}
+	rejected, err := reserveBillingHoldsScript.Run(ctx, c.rdb, keys, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("reserve billing holds: %w", err)
+	}
+	return rejected, nil
+}
+
+func (c *billingCache) SettleBillingHolds(ctx context.Context, holdID string, holds []service.BillingHold, balanceCost float64) error {
+	if len(holds) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(holds)+1)
+	balanceKey := ""
+	for _, h := range holds {
+		if h.Pool == service.BillingHoldPoolBalance {
+			balanceKey = billingBalanceKey(h.OwnerID)
+		}
+		keys = append(keys, billingHoldKey(h.Pool, h.OwnerID))
+	}
+	hasBalanceKey := 0
+	if balanceKey != "" {
+		keys = append(keys, balanceKey)
+		hasBalanceKey = 1
+	}
+	_, err := settleBillingHoldsScript.Run(ctx, c.rdb, keys, holdID, strconv.FormatFloat(balanceCost, 'f', -1, 64), int(jitteredTTL().Seconds()), hasBalanceKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("settle billing holds: %w", err)
+	}
+	return nil
+}
+
+func (c *billingCache) ReleaseBillingHolds(ctx context.Context, holdID string, holds []service.BillingHold) error {
+	if len(holds) == 0 {
+		return nil
+	}
+	pipe := c.rdb.Pipeline()
+	for _, h := range holds {
+		pipe.HDel(ctx, billingHoldKey(h.Pool, h.OwnerID), holdID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -362,6 +362,107 @@ func (s *BillingCacheSuite) TestUpdateSubscriptionUsage_ErrorPropagation() {
 	})
 }
 
+func (s *BillingCacheSuite) TestBillingHolds() {
+	tests := []struct {
+		name string
+		fn   func(ctx context.Context, rdb *redis.Client, cache service.BillingCache)
+	}{
+		{
+			name: "reserve_uses_cached_balance_and_rejects_all_or_nothing",
+			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+				require.NoError(s.T(), cache.SetUserBalance(ctx, 501, 10))
+
+				holds := []service.BillingHold{
+					{Pool: service.BillingHoldPoolBalance, OwnerID: 501, Amount: 6, Available: 100},
+					{Pool: service.BillingHoldPoolAPIKeyQuota, OwnerID: 601, Amount: 6, Available: 20},
+				}
+				rejected, err := cache.ReserveBillingHolds(ctx, "h1", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 0, rejected)
+
+				// 缓存余额 10 - 已预留 6 < 6：余额池拒绝，额度池不应产生部分预留
+				rejected, err = cache.ReserveBillingHolds(ctx, "h2", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 1, rejected)
+				exists, err := rdb.HExists(ctx, billingHoldKey(service.BillingHoldPoolAPIKeyQuota, 601), "h2").Result()
+				require.NoError(s.T(), err)
+				require.False(s.T(), exists)
+
+				require.NoError(s.T(), cache.ReleaseBillingHolds(ctx, "h1", holds))
+				rejected, err = cache.ReserveBillingHolds(ctx, "h2", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 0, rejected)
+			},
+		},
+		{
+			name: "postpaid_credit_extends_cached_balance",
+			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+				require.NoError(s.T(), cache.SetUserBalance(ctx, 502, -3))
+
+				holds := []service.BillingHold{{Pool: service.BillingHoldPoolBalance, OwnerID: 502, Amount: 4, Available: 100, Credit: 10}}
+				rejected, err := cache.ReserveBillingHolds(ctx, "p1", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 0, rejected)
+
+				// -3 + 10 - 4 < 4：超出信用额度
+				rejected, err = cache.ReserveBillingHolds(ctx, "p2", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 1, rejected)
+			},
+		},
+		{
+			name: "expired_holds_are_ignored",
+			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+				key := billingHoldKey(service.BillingHoldPoolAPIKeyRate, 701)
+				require.NoError(s.T(), rdb.HSet(ctx, key, "stale", fmt.Sprintf("5:%d", time.Now().Add(-time.Minute).Unix())).Err())
+
+				holds := []service.BillingHold{{Pool: service.BillingHoldPoolAPIKeyRate, OwnerID: 701, Amount: 5, Available: 5}}
+				rejected, err := cache.ReserveBillingHolds(ctx, "fresh", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 0, rejected)
+
+				exists, err := rdb.HExists(ctx, key, "stale").Result()
+				require.NoError(s.T(), err)
+				require.False(s.T(), exists, "expired hold should be pruned")
+			},
+		},
+		{
+			name: "settle_deducts_balance_and_removes_holds",
+			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+				require.NoError(s.T(), cache.SetUserBalance(ctx, 801, 10))
+				holds := []service.BillingHold{
+					{Pool: service.BillingHoldPoolBalance, OwnerID: 801, Amount: 4},
+					{Pool: service.BillingHoldPoolAPIKeyQuota, OwnerID: 901, Amount: 4, Available: 4},
+				}
+				rejected, err := cache.ReserveBillingHolds(ctx, "h", holds, time.Minute)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 0, rejected)
+
+				require.NoError(s.T(), cache.SettleBillingHolds(ctx, "h", holds, 1.5))
+
+				balance, err := cache.GetUserBalance(ctx, 801)
+				require.NoError(s.T(), err)
+				require.Equal(s.T(), 8.5, balance)
+				for _, h := range holds {
+					exists, err := rdb.HExists(ctx, billingHoldKey(h.Pool, h.OwnerID), "h").Result()
+					require.NoError(s.T(), err)
+					require.False(s.T(), exists)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			rdb := testRedis(s.T())
+			cache := NewBillingCache(rdb)
+			ctx := context.Background()
+
+			tt.fn(ctx, rdb, cache)
+		})
+	}
+}
+
 func TestBillingCacheSuite(t *testing.T) {
 	suite.Run(t, new(BillingCacheSuite))
 }
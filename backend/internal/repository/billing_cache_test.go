@@ -109,3 +109,8 @@ func TestJitteredTTL_HasVariation(t *testing.T) {
 	// 50 次调用中应该至少有 2 个不同的值
 	require.Greater(t, len(seen), 1, "jitteredTTL() 应产生不同的 TTL 值")
 }
+
+func TestBillingHoldKey(t *testing.T) {
+	require.Equal(t, "billing:hold:balance:7", billingHoldKey("balance", 7))
+	require.Equal(t, "billing:hold:rate:42", billingHoldKey("rate", 42))
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// digestSessionKeyPrefix 摘要会话 key 前缀。
+// 完整 key："digest_session:{<groupID>:<prefixHash>}|<digestChain>"，
+// hash tag 保证同一会话的所有截断前缀落在同一 slot，可一次 MGET 探测。
+const digestSessionKeyPrefix = "digest_session:"
+
+type digestSessionCache struct {
+	rdb *redis.Client
+}
+
+func NewDigestSessionCache(rdb *redis.Client) service.DigestSessionCache {
+	return &digestSessionCache{rdb: rdb}
+}
+
+func (c *digestSessionCache) FindDigestSession(ctx context.Context, groupID int64, prefixHash string, chains []string) (string, int64, string, bool, error) {
+	if len(chains) == 0 {
+		return "", 0, "", false, nil
+	}
+	keys := make([]string, len(chains))
+	for i, chain := range chains {
+		keys[i] = digestSessionKey(groupID, prefixHash, chain)
+	}
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return "", 0, "", false, err
+	}
+	// chains 按长度降序，第一个命中即最长前缀匹配
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok || i >= len(chains) {
+			continue
+		}
+		uuid, accountID, err := parseDigestSessionValue(raw)
+		if err != nil {
+			continue
+		}
+		return uuid, accountID, chains[i], true, nil
+	}
+	return "", 0, "", false, nil
+}
+
+func (c *digestSessionCache) SaveDigestSession(ctx context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string, ttl time.Duration) error {
+	if digestChain == "" {
+		return nil
+	}
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, digestSessionKey(groupID, prefixHash, digestChain), formatDigestSessionValue(uuid, accountID), ttl)
+		if oldDigestChain != "" && oldDigestChain != digestChain {
+			pipe.Del(ctx, digestSessionKey(groupID, prefixHash, oldDigestChain))
+		}
+		return nil
+	})
+	return err
+}
+
+func digestSessionKey(groupID int64, prefixHash, chain string) string {
+	return digestSessionKeyPrefix + "{" + strconv.FormatInt(groupID, 10) + ":" + prefixHash + "}|" + chain
+}
+
+func formatDigestSessionValue(uuid string, accountID int64) string {
+	return strconv.FormatInt(accountID, 10) + ":" + uuid
+}
+
+func parseDigestSessionValue(raw string) (string, int64, error) {
+	idPart, uuid, ok := strings.Cut(raw, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid digest session value")
+	}
+	accountID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return uuid, accountID, nil
+}
@@ -0,0 +1,25 @@
+//go:build unit
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestSessionKey_UsesHashTag(t *testing.T) {
+	require.Equal(t, "digest_session:{3:abc}|u:a-m:b", digestSessionKey(3, "abc", "u:a-m:b"))
+}
+
+func TestDigestSessionValueRoundTrip(t *testing.T) {
+	uuid, accountID, err := parseDigestSessionValue(formatDigestSessionValue("uuid:with:colons", 42))
+	require.NoError(t, err)
+	require.Equal(t, "uuid:with:colons", uuid)
+	require.Equal(t, int64(42), accountID)
+
+	_, _, err = parseDigestSessionValue("garbage")
+	require.Error(t, err)
+	_, _, err = parseDigestSessionValue("x:uuid")
+	require.Error(t, err)
+}
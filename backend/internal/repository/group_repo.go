@@ -65,7 +65,13 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		SetAllowMessagesDispatch(groupIn.AllowMessagesDispatch).
 		SetRequireOauthOnly(groupIn.RequireOAuthOnly).
 		SetRequirePrivacySet(groupIn.RequirePrivacySet).
-		SetDefaultMappedModel(groupIn.DefaultMappedModel)
+		SetDefaultMappedModel(groupIn.DefaultMappedModel).
+		SetResponseCacheEnabled(groupIn.ResponseCacheEnabled).
+		SetResponseCacheTTLSeconds(groupIn.ResponseCacheTTLSeconds).
+		SetResponseCacheHitMultiplier(groupIn.ResponseCacheHitMultiplier).
+		SetHedgeBudgetPercent(groupIn.HedgeBudgetPercent).
+		SetScheduleStrategy(groupIn.ScheduleStrategy).
+		SetBillingReservationMode(service.NormalizeBillingReservationMode(groupIn.BillingReservationMode))
 
 	// 设置模型路由配置
 	if groupIn.ModelRouting != nil {
@@ -77,6 +83,11 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		builder = builder.SetModelAliases(groupIn.ModelAliases)
 	}
 
+	// 设置分组自定义模型价格
+	if groupIn.ModelPrices != nil {
+		builder = builder.SetModelPrices(groupIn.ModelPrices)
+	}
+
 	// 设置兜底模型
 	builder = builder.SetFallbackModel(groupIn.FallbackModel)
 
@@ -146,7 +157,13 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetAllowMessagesDispatch(groupIn.AllowMessagesDispatch).
 		SetRequireOauthOnly(groupIn.RequireOAuthOnly).
 		SetRequirePrivacySet(groupIn.RequirePrivacySet).
-		SetDefaultMappedModel(groupIn.DefaultMappedModel)
+		SetDefaultMappedModel(groupIn.DefaultMappedModel).
+		SetResponseCacheEnabled(groupIn.ResponseCacheEnabled).
+		SetResponseCacheTTLSeconds(groupIn.ResponseCacheTTLSeconds).
+		SetResponseCacheHitMultiplier(groupIn.ResponseCacheHitMultiplier).
+		SetHedgeBudgetPercent(groupIn.HedgeBudgetPercent).
+		SetScheduleStrategy(groupIn.ScheduleStrategy).
+		SetBillingReservationMode(service.NormalizeBillingReservationMode(groupIn.BillingReservationMode))
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
 	if groupIn.DailyLimitUSD != nil {
@@ -207,6 +224,13 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		builder = builder.ClearModelAliases()
 	}
 
+	// 处理 ModelPrices：nil 时清除，否则设置
+	if groupIn.ModelPrices != nil {
+		builder = builder.SetModelPrices(groupIn.ModelPrices)
+	} else {
+		builder = builder.ClearModelPrices()
+	}
+
 	// 兜底模型（始终设置，空字符串表示不启用兜底）
 	builder = builder.SetFallbackModel(groupIn.FallbackModel)
 
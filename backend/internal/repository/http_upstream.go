@@ -21,8 +21,11 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyurl"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyutil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // 默认配置常量
@@ -138,14 +141,18 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 		return nil, err
 	}
 
+	req, span := startUpstreamSpan(req, proxyURL, accountID, nil)
+
 	// 执行请求
 	resp, err := entry.client.Do(req)
 	if err != nil {
 		// 请求失败，立即减少计数
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	// 如果上游返回了压缩内容，解压后再交给业务层
 	decompressResponseBody(resp)
@@ -155,6 +162,7 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	resp.Body = wrapTrackedBody(resp.Body, func() {
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
+		span.End()
 	})
 
 	return resp, nil
@@ -189,24 +197,56 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 		return nil, err
 	}
 
+	req, span := startUpstreamSpan(req, proxyURL, accountID, profile)
+
 	resp, err := entry.client.Do(req)
 	if err != nil {
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
 		slog.Debug("tls_fingerprint_request_failed", "account_id", accountID, "error", err)
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	decompressResponseBody(resp)
 
 	resp.Body = wrapTrackedBody(resp.Body, func() {
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
+		span.End()
 	})
 
 	return resp, nil
 }
 
+// startUpstreamSpan 创建上游 HTTP 调用 span，span 在响应体关闭时结束（覆盖流式响应全程）。
+// 建连、代理隧道与 TLS 握手阶段以事件形式记录；代理仅记录类型，不记录地址与凭据。
+func startUpstreamSpan(req *http.Request, proxyURL string, accountID int64, profile *tlsfingerprint.Profile) (*http.Request, trace.Span) {
+	proxyType := directProxyKey
+	if proxyURL != "" {
+		proxyType = "unknown"
+		if parsed, err := url.Parse(proxyURL); err == nil && parsed.Scheme != "" {
+			proxyType = strings.ToLower(parsed.Scheme)
+		}
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.String("url.path", req.URL.Path),
+		attribute.Int64("account.id", accountID),
+		attribute.String("proxy.type", proxyType),
+	}
+	if profile != nil {
+		attrs = append(attrs, attribute.String("tls.fingerprint_profile", profile.Name))
+	}
+	ctx, span := tracing.Tracer().Start(req.Context(), "upstream.http",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return req.WithContext(tracing.WithClientTrace(ctx)), span
+}
+
 // acquireClientWithTLS 获取或创建带 TLS 指纹的客户端
 func (s *httpUpstreamService) acquireClientWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*upstreamClientEntry, error) {
 	return s.getClientEntryWithTLS(proxyURL, accountID, accountConcurrency, profile, true, true)
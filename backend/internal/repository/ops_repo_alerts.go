@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
 )
 
 func (r *opsRepository) ListAlertRules(ctx context.Context) ([]*service.OpsAlertRule, error) {
@@ -30,6 +31,7 @@ SELECT
   sustained_minutes,
   cooldown_minutes,
   COALESCE(notify_email, true),
+  notify_channel_ids,
   filters,
   last_triggered_at,
   created_at,
@@ -61,6 +63,7 @@ ORDER BY id DESC`
 			&rule.SustainedMinutes,
 			&rule.CooldownMinutes,
 			&rule.NotifyEmail,
+			(*pq.Int64Array)(&rule.NotifyChannelIDs),
 			&filtersRaw,
 			&lastTriggeredAt,
 			&rule.CreatedAt,
@@ -112,11 +115,12 @@ INSERT INTO ops_alert_rules (
   sustained_minutes,
   cooldown_minutes,
   notify_email,
+  notify_channel_ids,
   filters,
   created_at,
   updated_at
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,NOW(),NOW()
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NOW(),NOW()
 )
 RETURNING
   id,
@@ -131,6 +135,7 @@ RETURNING
   sustained_minutes,
   cooldown_minutes,
   COALESCE(notify_email, true),
+  notify_channel_ids,
   filters,
   last_triggered_at,
   created_at,
@@ -154,6 +159,7 @@ RETURNING
 		input.SustainedMinutes,
 		input.CooldownMinutes,
 		input.NotifyEmail,
+		opsInt64ArrayArg(input.NotifyChannelIDs),
 		filtersArg,
 	).Scan(
 		&out.ID,
@@ -168,6 +174,7 @@ RETURNING
 		&out.SustainedMinutes,
 		&out.CooldownMinutes,
 		&out.NotifyEmail,
+		(*pq.Int64Array)(&out.NotifyChannelIDs),
 		&filtersRaw,
 		&lastTriggeredAt,
 		&out.CreatedAt,
@@ -219,7 +226,8 @@ SET
   sustained_minutes = $10,
   cooldown_minutes = $11,
   notify_email = $12,
-  filters = $13,
+  notify_channel_ids = $13,
+  filters = $14,
   updated_at = NOW()
 WHERE id = $1
 RETURNING
@@ -235,6 +243,7 @@ RETURNING
   sustained_minutes,
   cooldown_minutes,
   COALESCE(notify_email, true),
+  notify_channel_ids,
   filters,
   last_triggered_at,
   created_at,
@@ -259,6 +268,7 @@ RETURNING
 		input.SustainedMinutes,
 		input.CooldownMinutes,
 		input.NotifyEmail,
+		opsInt64ArrayArg(input.NotifyChannelIDs),
 		filtersArg,
 	).Scan(
 		&out.ID,
@@ -273,6 +283,7 @@ RETURNING
 		&out.SustainedMinutes,
 		&out.CooldownMinutes,
 		&out.NotifyEmail,
+		(*pq.Int64Array)(&out.NotifyChannelIDs),
 		&filtersRaw,
 		&lastTriggeredAt,
 		&out.CreatedAt,
@@ -851,3 +862,11 @@ func opsNullJSONMap(v map[string]any) (any, error) {
 	}
 	return sql.NullString{String: string(b), Valid: true}, nil
 }
+
+// opsInt64ArrayArg 将 nil 切片写为空数组，避免违反 NOT NULL 约束
+func opsInt64ArrayArg(v []int64) pq.Int64Array {
+	if v == nil {
+		return pq.Int64Array{}
+	}
+	return pq.Int64Array(v)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// ListHourlyBaselineSamples 按基线时段汇总 ops_metrics_hourly。
+// 每个时段覆盖 [start, start+1h)，没有数据的时段不返回。
+func (r *opsRepository) ListHourlyBaselineSamples(ctx context.Context, windowStarts []time.Time, platform string, groupID *int64) ([]*service.OpsHourlyBaselineSample, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if len(windowStarts) == 0 {
+		return []*service.OpsHourlyBaselineSample{}, nil
+	}
+
+	args := []any{opsBaselineWindowArg(windowStarts)}
+	where := ""
+	platform = strings.TrimSpace(strings.ToLower(platform))
+
+	// 维度过滤与 listHourlyMetricsRows 保持一致：overall / platform / group 三种粒度互斥
+	switch {
+	case groupID != nil && *groupID > 0:
+		args = append(args, *groupID)
+		where = " AND h.group_id = $" + itoa(len(args))
+		if platform != "" {
+			args = append(args, platform)
+			where += " AND h.platform = $" + itoa(len(args))
+		}
+	case platform != "":
+		args = append(args, platform)
+		where = " AND h.platform = $" + itoa(len(args)) + " AND h.group_id IS NULL"
+	default:
+		where = " AND h.platform IS NULL AND h.group_id IS NULL"
+	}
+
+	q := `
+SELECT
+  w.window_start,
+  COALESCE(SUM(h.success_count), 0),
+  COALESCE(SUM(h.error_count_sla), 0),
+  COALESCE(SUM(h.token_consumed), 0)
+FROM unnest($1::timestamptz[]) AS w(window_start)
+JOIN ops_metrics_hourly h
+  ON h.bucket_start >= w.window_start
+ AND h.bucket_start < w.window_start + INTERVAL '1 hour'
+WHERE TRUE` + where + `
+GROUP BY w.window_start
+ORDER BY w.window_start ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsHourlyBaselineSample, 0, len(windowStarts))
+	for rows.Next() {
+		var sample service.OpsHourlyBaselineSample
+		if err := rows.Scan(&sample.WindowStart, &sample.SuccessCount, &sample.ErrorCountSLA, &sample.TokenConsumed); err != nil {
+			return nil, err
+		}
+		out = append(out, &sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListUsageCostBaselineSamples 按基线时段汇总 usage_dashboard_hourly（全局维度）。
+func (r *opsRepository) ListUsageCostBaselineSamples(ctx context.Context, windowStarts []time.Time) ([]*service.OpsUsageCostBaselineSample, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if len(windowStarts) == 0 {
+		return []*service.OpsUsageCostBaselineSample{}, nil
+	}
+
+	q := `
+SELECT
+  w.window_start,
+  COALESCE(SUM(d.actual_cost), 0)::double precision,
+  COALESCE(SUM(d.active_users), 0)
+FROM unnest($1::timestamptz[]) AS w(window_start)
+JOIN usage_dashboard_hourly d
+  ON d.bucket_start >= w.window_start
+ AND d.bucket_start < w.window_start + INTERVAL '1 hour'
+GROUP BY w.window_start
+ORDER BY w.window_start ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, opsBaselineWindowArg(windowStarts))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsUsageCostBaselineSample, 0, len(windowStarts))
+	for rows.Next() {
+		var sample service.OpsUsageCostBaselineSample
+		if err := rows.Scan(&sample.WindowStart, &sample.ActualCost, &sample.ActiveUsers); err != nil {
+			return nil, err
+		}
+		out = append(out, &sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func opsBaselineWindowArg(windowStarts []time.Time) any {
+	values := make(pq.StringArray, 0, len(windowStarts))
+	for _, t := range windowStarts {
+		values = append(values, t.UTC().Format(time.RFC3339Nano))
+	}
+	return values
+}
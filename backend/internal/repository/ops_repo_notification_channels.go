@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// opsNotificationChannelConfig ops_notification_channels.config 列的 JSON 结构
+type opsNotificationChannelConfig struct {
+	URL      string            `json:"url,omitempty"`
+	Secret   string            `json:"secret,omitempty"`
+	BotToken string            `json:"bot_token,omitempty"`
+	ChatID   string            `json:"chat_id,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+const opsNotificationChannelColumns = `
+  id,
+  name,
+  channel_type,
+  enabled,
+  config,
+  COALESCE(template, ''),
+  COALESCE(min_severity, ''),
+  created_at,
+  updated_at`
+
+func (r *opsRepository) ListNotificationChannels(ctx context.Context) ([]*service.OpsNotificationChannel, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT`+opsNotificationChannelColumns+`
+FROM ops_notification_channels
+ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsNotificationChannel{}
+	for rows.Next() {
+		ch, err := scanOpsNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) GetNotificationChannelByID(ctx context.Context, id int64) (*service.OpsNotificationChannel, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+
+	row := r.db.QueryRowContext(ctx, `SELECT`+opsNotificationChannelColumns+`
+FROM ops_notification_channels
+WHERE id = $1`, id)
+	ch, err := scanOpsNotificationChannel(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (r *opsRepository) CreateNotificationChannel(ctx context.Context, input *service.OpsNotificationChannel) (*service.OpsNotificationChannel, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("nil input")
+	}
+
+	configArg, err := marshalOpsNotificationChannelConfig(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+INSERT INTO ops_notification_channels (
+  name,
+  channel_type,
+  enabled,
+  config,
+  template,
+  min_severity,
+  created_at,
+  updated_at
+) VALUES (
+  $1,$2,$3,$4,$5,$6,NOW(),NOW()
+)
+RETURNING` + opsNotificationChannelColumns
+
+	row := r.db.QueryRowContext(
+		ctx,
+		q,
+		strings.TrimSpace(input.Name),
+		strings.TrimSpace(input.Type),
+		input.Enabled,
+		configArg,
+		input.Template,
+		strings.TrimSpace(input.MinSeverity),
+	)
+	return scanOpsNotificationChannel(row)
+}
+
+func (r *opsRepository) UpdateNotificationChannel(ctx context.Context, input *service.OpsNotificationChannel) (*service.OpsNotificationChannel, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("nil input")
+	}
+	if input.ID <= 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+
+	configArg, err := marshalOpsNotificationChannelConfig(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+UPDATE ops_notification_channels
+SET
+  name = $2,
+  channel_type = $3,
+  enabled = $4,
+  config = $5,
+  template = $6,
+  min_severity = $7,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING` + opsNotificationChannelColumns
+
+	row := r.db.QueryRowContext(
+		ctx,
+		q,
+		input.ID,
+		strings.TrimSpace(input.Name),
+		strings.TrimSpace(input.Type),
+		input.Enabled,
+		configArg,
+		input.Template,
+		strings.TrimSpace(input.MinSeverity),
+	)
+	return scanOpsNotificationChannel(row)
+}
+
+// DeleteNotificationChannel 删除渠道并将其从所有告警规则的路由中移除
+func (r *opsRepository) DeleteNotificationChannel(ctx context.Context, id int64) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	if id <= 0 {
+		return fmt.Errorf("invalid id")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM ops_notification_channels WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE ops_alert_rules
+SET notify_channel_ids = array_remove(notify_channel_ids, $1::bigint), updated_at = NOW()
+WHERE $1::bigint = ANY(notify_channel_ids)`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *opsRepository) InsertNotificationDelivery(ctx context.Context, input *service.OpsNotificationDelivery) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return 0, fmt.Errorf("nil input")
+	}
+
+	q := `
+INSERT INTO ops_notification_deliveries (
+  channel_id,
+  channel_name,
+  channel_type,
+  alert_event_id,
+  source,
+  status,
+  attempts,
+  http_status,
+  error_message,
+  created_at
+) VALUES (
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,NOW()
+)
+RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(
+		ctx,
+		q,
+		input.ChannelID,
+		input.ChannelName,
+		input.ChannelType,
+		opsNullInt64(input.AlertEventID),
+		input.Source,
+		input.Status,
+		input.Attempts,
+		opsNullInt(input.HTTPStatus),
+		opsNullString(input.ErrorMessage),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *opsRepository) ListNotificationDeliveries(ctx context.Context, filter *service.OpsNotificationDeliveryFilter) ([]*service.OpsNotificationDelivery, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		filter = &service.OpsNotificationDeliveryFilter{}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	clauses := []string{}
+	args := []any{}
+	if filter.AlertEventID != nil && *filter.AlertEventID > 0 {
+		args = append(args, *filter.AlertEventID)
+		clauses = append(clauses, "alert_event_id = $"+itoa(len(args)))
+	}
+	if filter.ChannelID != nil && *filter.ChannelID > 0 {
+		args = append(args, *filter.ChannelID)
+		clauses = append(clauses, "channel_id = $"+itoa(len(args)))
+	}
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit)
+
+	q := `
+SELECT
+  id,
+  channel_id,
+  channel_name,
+  channel_type,
+  alert_event_id,
+  source,
+  status,
+  attempts,
+  http_status,
+  COALESCE(error_message, ''),
+  created_at
+FROM ops_notification_deliveries
+` + where + `
+ORDER BY created_at DESC, id DESC
+LIMIT $` + itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsNotificationDelivery{}
+	for rows.Next() {
+		var d service.OpsNotificationDelivery
+		var eventID sql.NullInt64
+		var httpStatus sql.NullInt64
+		if err := rows.Scan(
+			&d.ID,
+			&d.ChannelID,
+			&d.ChannelName,
+			&d.ChannelType,
+			&eventID,
+			&d.Source,
+			&d.Status,
+			&d.Attempts,
+			&httpStatus,
+			&d.ErrorMessage,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if eventID.Valid {
+			v := eventID.Int64
+			d.AlertEventID = &v
+		}
+		if httpStatus.Valid {
+			v := int(httpStatus.Int64)
+			d.HTTPStatus = &v
+		}
+		out = append(out, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func scanOpsNotificationChannel(row opsAlertEventRow) (*service.OpsNotificationChannel, error) {
+	var ch service.OpsNotificationChannel
+	var configRaw []byte
+	if err := row.Scan(
+		&ch.ID,
+		&ch.Name,
+		&ch.Type,
+		&ch.Enabled,
+		&configRaw,
+		&ch.Template,
+		&ch.MinSeverity,
+		&ch.CreatedAt,
+		&ch.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(configRaw) > 0 && string(configRaw) != "null" {
+		var cfg opsNotificationChannelConfig
+		if err := json.Unmarshal(configRaw, &cfg); err == nil {
+			ch.URL = cfg.URL
+			ch.Secret = cfg.Secret
+			ch.BotToken = cfg.BotToken
+			ch.ChatID = cfg.ChatID
+			ch.Headers = cfg.Headers
+		}
+	}
+	ch.SecretConfigured = ch.Secret != ""
+	ch.BotTokenConfigured = ch.BotToken != ""
+	return &ch, nil
+}
+
+func marshalOpsNotificationChannelConfig(input *service.OpsNotificationChannel) (string, error) {
+	b, err := json.Marshal(opsNotificationChannelConfig{
+		URL:      strings.TrimSpace(input.URL),
+		Secret:   input.Secret,
+		BotToken: input.BotToken,
+		ChatID:   strings.TrimSpace(input.ChatID),
+		Headers:  input.Headers,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+const opsPayloadCaptureColumns = `
+  id,
+  client_request_id,
+  rule_name,
+  storage_key,
+  platform,
+  model,
+  request_path,
+  status_code,
+  user_id,
+  api_key_id,
+  group_id,
+  size_bytes,
+  truncated,
+  created_at,
+  expires_at`
+
+func (r *opsRepository) InsertPayloadCapture(ctx context.Context, input *service.OpsPayloadCapture) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	if input == nil || strings.TrimSpace(input.ID) == "" {
+		return fmt.Errorf("invalid payload capture")
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO ops_payload_captures (`+opsPayloadCaptureColumns+`
+) VALUES (
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15
+)
+ON CONFLICT (id) DO NOTHING`,
+		input.ID,
+		truncateOpsPayloadCaptureField(input.ClientRequestID, 64),
+		truncateOpsPayloadCaptureField(input.RuleName, 128),
+		input.StorageKey,
+		truncateOpsPayloadCaptureField(input.Platform, 32),
+		truncateOpsPayloadCaptureField(input.Model, 128),
+		truncateOpsPayloadCaptureField(input.RequestPath, 256),
+		input.StatusCode,
+		opsNullInt64(input.UserID),
+		opsNullInt64(input.APIKeyID),
+		opsNullInt64(input.GroupID),
+		input.SizeBytes,
+		input.Truncated,
+		input.CreatedAt,
+		input.ExpiresAt,
+	)
+	return err
+}
+
+func (r *opsRepository) GetPayloadCaptureByID(ctx context.Context, id string) (*service.OpsPayloadCapture, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT`+opsPayloadCaptureColumns+`
+FROM ops_payload_captures
+WHERE id = $1`, strings.TrimSpace(id))
+	capture, err := scanOpsPayloadCapture(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return capture, nil
+}
+
+func (r *opsRepository) ListExpiredPayloadCaptures(ctx context.Context, before time.Time, limit int) ([]*service.OpsPayloadCapture, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if limit <= 0 {
+		limit = 500
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT`+opsPayloadCaptureColumns+`
+FROM ops_payload_captures
+WHERE expires_at <= $1
+ORDER BY expires_at ASC
+LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsPayloadCapture{}
+	for rows.Next() {
+		capture, err := scanOpsPayloadCapture(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, capture)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) DeletePayloadCaptures(ctx context.Context, ids []string) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ops_payload_captures WHERE id = ANY($1)`, pq.Array(ids))
+	return err
+}
+
+func scanOpsPayloadCapture(row opsAlertEventRow) (*service.OpsPayloadCapture, error) {
+	var (
+		capture  service.OpsPayloadCapture
+		userID   sql.NullInt64
+		apiKeyID sql.NullInt64
+		groupID  sql.NullInt64
+	)
+	if err := row.Scan(
+		&capture.ID,
+		&capture.ClientRequestID,
+		&capture.RuleName,
+		&capture.StorageKey,
+		&capture.Platform,
+		&capture.Model,
+		&capture.RequestPath,
+		&capture.StatusCode,
+		&userID,
+		&apiKeyID,
+		&groupID,
+		&capture.SizeBytes,
+		&capture.Truncated,
+		&capture.CreatedAt,
+		&capture.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		capture.UserID = &userID.Int64
+	}
+	if apiKeyID.Valid {
+		capture.APIKeyID = &apiKeyID.Int64
+	}
+	if groupID.Valid {
+		capture.GroupID = &groupID.Int64
+	}
+	return &capture, nil
+}
+
+// truncateOpsPayloadCaptureField 按列宽截断（按字符，避免截断多字节字符）
+func truncateOpsPayloadCaptureField(value string, maxChars int) string {
+	value = strings.TrimSpace(value)
+	runes := []rune(value)
+	if len(runes) <= maxChars {
+		return value
+	}
+	return string(runes[:maxChars])
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+func (r *opsRepository) ListUserSpendReport(ctx context.Context, start, end time.Time) ([]*service.OpsUserSpendReportRow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+
+	q := `
+SELECT
+  ul.user_id,
+  COALESCE(u.email, ''),
+  COALESCE(u.username, ''),
+  COUNT(*) AS requests,
+  COALESCE(SUM(ul.input_tokens), 0),
+  COALESCE(SUM(ul.output_tokens), 0),
+  COALESCE(SUM(ul.cache_creation_tokens), 0),
+  COALESCE(SUM(ul.cache_read_tokens), 0),
+  COALESCE(SUM(ul.total_cost), 0),
+  COALESCE(SUM(ul.actual_cost), 0) AS actual_cost
+FROM usage_logs ul
+LEFT JOIN users u ON u.id = ul.user_id
+WHERE ul.created_at >= $1 AND ul.created_at < $2
+GROUP BY ul.user_id, u.email, u.username
+ORDER BY actual_cost DESC, ul.user_id ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsUserSpendReportRow{}
+	for rows.Next() {
+		var item service.OpsUserSpendReportRow
+		if err := rows.Scan(
+			&item.UserID,
+			&item.Email,
+			&item.Username,
+			&item.Requests,
+			&item.InputTokens,
+			&item.OutputTokens,
+			&item.CacheCreationTokens,
+			&item.CacheReadTokens,
+			&item.StandardCost,
+			&item.ActualCost,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) ListGroupRevenueReport(ctx context.Context, start, end time.Time) ([]*service.OpsGroupRevenueReportRow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+
+	q := `
+SELECT
+  COALESCE(ul.group_id, 0),
+  COALESCE(g.name, ''),
+  COALESCE(g.platform, ''),
+  COUNT(*) AS requests,
+  COALESCE(SUM(ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens), 0),
+  COALESCE(SUM(ul.actual_cost), 0) AS revenue,
+  COALESCE(SUM(ul.total_cost), 0),
+  COALESCE(SUM(ul.total_cost * COALESCE(ul.account_rate_multiplier, 1)), 0)
+FROM usage_logs ul
+LEFT JOIN groups g ON g.id = ul.group_id
+WHERE ul.created_at >= $1 AND ul.created_at < $2
+GROUP BY ul.group_id, g.name, g.platform
+ORDER BY revenue DESC, 1 ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsGroupRevenueReportRow{}
+	for rows.Next() {
+		var item service.OpsGroupRevenueReportRow
+		if err := rows.Scan(
+			&item.GroupID,
+			&item.GroupName,
+			&item.Platform,
+			&item.Requests,
+			&item.TotalTokens,
+			&item.Revenue,
+			&item.StandardCost,
+			&item.UpstreamCost,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) ListAccountUtilizationReport(ctx context.Context, start, end time.Time) ([]*service.OpsAccountUtilizationReportRow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+
+	q := `
+WITH usage AS (
+  SELECT
+    account_id,
+    COUNT(*) AS requests,
+    COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0) AS total_tokens,
+    COALESCE(SUM(total_cost * COALESCE(account_rate_multiplier, 1)), 0) AS upstream_cost,
+    COUNT(DISTINCT date_trunc('hour', created_at)) AS active_hours
+  FROM usage_logs
+  WHERE created_at >= $1 AND created_at < $2
+  GROUP BY account_id
+), failed AS (
+  SELECT
+    account_id,
+    COUNT(*) AS cnt
+  FROM ops_error_logs
+  WHERE created_at >= $1 AND created_at < $2
+    AND account_id IS NOT NULL
+    AND ` + opsCustomerFacingErrorPredicate + `
+  GROUP BY account_id
+)
+SELECT
+  COALESCE(usage.account_id, failed.account_id) AS account_id,
+  COALESCE(a.name, ''),
+  COALESCE(a.platform, ''),
+  COALESCE(a.concurrency, 0),
+  COALESCE(usage.requests, 0) AS requests,
+  COALESCE(failed.cnt, 0),
+  COALESCE(usage.total_tokens, 0),
+  COALESCE(usage.upstream_cost, 0),
+  COALESCE(usage.active_hours, 0)
+FROM usage
+FULL OUTER JOIN failed ON failed.account_id = usage.account_id
+LEFT JOIN accounts a ON a.id = COALESCE(usage.account_id, failed.account_id)
+ORDER BY requests DESC, account_id ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsAccountUtilizationReportRow{}
+	for rows.Next() {
+		var item service.OpsAccountUtilizationReportRow
+		if err := rows.Scan(
+			&item.AccountID,
+			&item.AccountName,
+			&item.Platform,
+			&item.Concurrency,
+			&item.Requests,
+			&item.ErrorCount,
+			&item.TotalTokens,
+			&item.UpstreamCost,
+			&item.ActiveHours,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
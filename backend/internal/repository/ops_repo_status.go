@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// opsCustomerFacingErrorPredicate 计入客户 SLA 的错误：排除业务限流、count_tokens 与客户端自身原因
+const opsCustomerFacingErrorPredicate = `COALESCE(status_code, 0) >= 400
+  AND COALESCE(is_business_limited, false) = false
+  AND is_count_tokens = FALSE
+  AND error_owner IS DISTINCT FROM 'client'`
+
+func (r *opsRepository) GetCustomerSLAStats(ctx context.Context, filter *service.OpsCustomerSLAFilter) (*service.OpsCustomerSLAStats, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("nil filter")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, fmt.Errorf("start_time/end_time required")
+	}
+
+	usageWhere, usageArgs := buildCustomerSLAWhere(filter, "ul.")
+	usageQ := `
+SELECT
+  COUNT(*) AS success_count,
+  percentile_cont(0.50) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE duration_ms IS NOT NULL) AS duration_p50,
+  percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE duration_ms IS NOT NULL) AS duration_p95,
+  percentile_cont(0.99) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE duration_ms IS NOT NULL) AS duration_p99,
+  percentile_cont(0.50) WITHIN GROUP (ORDER BY first_token_ms) FILTER (WHERE first_token_ms IS NOT NULL) AS ttft_p50,
+  percentile_cont(0.95) WITHIN GROUP (ORDER BY first_token_ms) FILTER (WHERE first_token_ms IS NOT NULL) AS ttft_p95,
+  percentile_cont(0.99) WITHIN GROUP (ORDER BY first_token_ms) FILTER (WHERE first_token_ms IS NOT NULL) AS ttft_p99
+FROM usage_logs ul
+` + usageWhere
+
+	out := &service.OpsCustomerSLAStats{}
+	var dP50, dP95, dP99, tP50, tP95, tP99 sql.NullFloat64
+	if err := r.db.QueryRowContext(ctx, usageQ, usageArgs...).Scan(
+		&out.SuccessCount,
+		&dP50, &dP95, &dP99,
+		&tP50, &tP95, &tP99,
+	); err != nil {
+		return nil, err
+	}
+	out.DurationP50 = floatToIntPtr(dP50)
+	out.DurationP95 = floatToIntPtr(dP95)
+	out.DurationP99 = floatToIntPtr(dP99)
+	out.TTFTP50 = floatToIntPtr(tP50)
+	out.TTFTP95 = floatToIntPtr(tP95)
+	out.TTFTP99 = floatToIntPtr(tP99)
+
+	errorWhere, errorArgs := buildCustomerSLAWhere(filter, "")
+	errorQ := `
+SELECT COUNT(*)
+FROM ops_error_logs
+` + errorWhere + `
+  AND ` + opsCustomerFacingErrorPredicate
+	if err := r.db.QueryRowContext(ctx, errorQ, errorArgs...).Scan(&out.ErrorCount); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func buildCustomerSLAWhere(filter *service.OpsCustomerSLAFilter, prefix string) (string, []any) {
+	clauses := []string{
+		prefix + "created_at >= $1",
+		prefix + "created_at < $2",
+	}
+	args := []any{filter.StartTime.UTC(), filter.EndTime.UTC()}
+	if filter.UserID != nil && *filter.UserID > 0 {
+		args = append(args, *filter.UserID)
+		clauses = append(clauses, fmt.Sprintf("%suser_id = $%d", prefix, len(args)))
+	}
+	if filter.GroupID != nil && *filter.GroupID > 0 {
+		args = append(args, *filter.GroupID)
+		clauses = append(clauses, fmt.Sprintf("%sgroup_id = $%d", prefix, len(args)))
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// ListStatusModelStats 按平台/模型聚合 since 之后的成功数（usage_logs）与面向客户的错误数（ops_error_logs）
+func (r *opsRepository) ListStatusModelStats(ctx context.Context, since time.Time) ([]*service.OpsStatusModelStat, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+
+	q := `
+WITH ok AS (
+  SELECT
+    COALESCE(NULLIF(g.platform,''), a.platform, '') AS platform,
+    COALESCE(ul.model, '') AS model,
+    COUNT(*) AS cnt
+  FROM usage_logs ul
+  LEFT JOIN groups g ON g.id = ul.group_id
+  LEFT JOIN accounts a ON a.id = ul.account_id
+  WHERE ul.created_at >= $1
+  GROUP BY 1, 2
+), failed AS (
+  SELECT
+    COALESCE(platform, '') AS platform,
+    COALESCE(model, '') AS model,
+    COUNT(*) AS cnt
+  FROM ops_error_logs
+  WHERE created_at >= $1
+    AND ` + opsCustomerFacingErrorPredicate + `
+  GROUP BY 1, 2
+)
+SELECT
+  COALESCE(ok.platform, failed.platform) AS platform,
+  COALESCE(ok.model, failed.model) AS model,
+  COALESCE(ok.cnt, 0) AS success_count,
+  COALESCE(failed.cnt, 0) AS error_count
+FROM ok
+FULL OUTER JOIN failed ON failed.platform = ok.platform AND failed.model = ok.model
+ORDER BY 1, 2`
+
+	rows, err := r.db.QueryContext(ctx, q, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsStatusModelStat{}
+	for rows.Next() {
+		var item service.OpsStatusModelStat
+		if err := rows.Scan(&item.Platform, &item.Model, &item.SuccessCount, &item.ErrorCount); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(item.Platform) == "" {
+			continue
+		}
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+const opsMaintenanceWindowColumns = `
+  id,
+  title,
+  COALESCE(description, ''),
+  platforms,
+  starts_at,
+  ends_at,
+  created_at,
+  updated_at`
+
+func (r *opsRepository) ListMaintenanceWindows(ctx context.Context, filter *service.OpsMaintenanceWindowFilter) ([]*service.OpsMaintenanceWindow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		filter = &service.OpsMaintenanceWindowFilter{}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	args := []any{}
+	where := ""
+	if filter.EndsAfter != nil && !filter.EndsAfter.IsZero() {
+		args = append(args, filter.EndsAfter.UTC())
+		where = "WHERE ends_at > $1"
+	}
+	args = append(args, limit)
+
+	q := `SELECT` + opsMaintenanceWindowColumns + `
+FROM ops_maintenance_windows
+` + where + `
+ORDER BY starts_at DESC, id DESC
+LIMIT $` + itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*service.OpsMaintenanceWindow{}
+	for rows.Next() {
+		w, err := scanOpsMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) GetMaintenanceWindowByID(ctx context.Context, id int64) (*service.OpsMaintenanceWindow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+
+	row := r.db.QueryRowContext(ctx, `SELECT`+opsMaintenanceWindowColumns+`
+FROM ops_maintenance_windows
+WHERE id = $1`, id)
+	w, err := scanOpsMaintenanceWindow(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *opsRepository) CreateMaintenanceWindow(ctx context.Context, input *service.OpsMaintenanceWindow) (*service.OpsMaintenanceWindow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("nil input")
+	}
+
+	q := `
+INSERT INTO ops_maintenance_windows (
+  title,
+  description,
+  platforms,
+  starts_at,
+  ends_at,
+  created_at,
+  updated_at
+) VALUES (
+  $1,$2,$3,$4,$5,NOW(),NOW()
+)
+RETURNING` + opsMaintenanceWindowColumns
+
+	row := r.db.QueryRowContext(
+		ctx,
+		q,
+		strings.TrimSpace(input.Title),
+		input.Description,
+		pq.Array(opsMaintenancePlatformsArg(input.Platforms)),
+		input.StartsAt.UTC(),
+		input.EndsAt.UTC(),
+	)
+	return scanOpsMaintenanceWindow(row)
+}
+
+func (r *opsRepository) UpdateMaintenanceWindow(ctx context.Context, input *service.OpsMaintenanceWindow) (*service.OpsMaintenanceWindow, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("nil input")
+	}
+	if input.ID <= 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+
+	q := `
+UPDATE ops_maintenance_windows
+SET
+  title = $2,
+  description = $3,
+  platforms = $4,
+  starts_at = $5,
+  ends_at = $6,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING` + opsMaintenanceWindowColumns
+
+	row := r.db.QueryRowContext(
+		ctx,
+		q,
+		input.ID,
+		strings.TrimSpace(input.Title),
+		input.Description,
+		pq.Array(opsMaintenancePlatformsArg(input.Platforms)),
+		input.StartsAt.UTC(),
+		input.EndsAt.UTC(),
+	)
+	return scanOpsMaintenanceWindow(row)
+}
+
+func (r *opsRepository) DeleteMaintenanceWindow(ctx context.Context, id int64) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	if id <= 0 {
+		return fmt.Errorf("invalid id")
+	}
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM ops_maintenance_windows WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func opsMaintenancePlatformsArg(platforms []string) []string {
+	if platforms == nil {
+		return []string{}
+	}
+	return platforms
+}
+
+func scanOpsMaintenanceWindow(row opsAlertEventRow) (*service.OpsMaintenanceWindow, error) {
+	var w service.OpsMaintenanceWindow
+	var platforms pq.StringArray
+	if err := row.Scan(
+		&w.ID,
+		&w.Title,
+		&w.Description,
+		&platforms,
+		&w.StartsAt,
+		&w.EndsAt,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	w.Platforms = []string(platforms)
+	if w.Platforms == nil {
+		w.Platforms = []string{}
+	}
+	return &w, nil
+}
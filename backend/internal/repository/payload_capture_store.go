@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// NewPayloadCaptureStore 根据 ops.payload_capture.storage 配置返回本地磁盘或 S3 存储。
+func NewPayloadCaptureStore(cfg *config.Config, s3Factory service.BackupObjectStoreFactory) service.PayloadCaptureStore {
+	if cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Ops.PayloadCapture.Storage), "s3") {
+		return newS3FileStore(s3Factory, cfg.Ops.PayloadCapture.S3, "application/gzip")
+	}
+	localPath := ""
+	if cfg != nil {
+		localPath = cfg.Ops.PayloadCapture.LocalPath
+	}
+	return &localFileStore{root: config.ResolvePayloadCaptureStorageRoot(localPath)}
+}
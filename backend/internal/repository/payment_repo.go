@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type paymentRepository struct {
+	sql sqlExecutor
+}
+
+// NewPaymentRepository 支付订单仓储（原生 SQL，支持通过 context 参与 ent 事务）
+func NewPaymentRepository(sqlDB *sql.DB) service.PaymentRepository {
+	return &paymentRepository{sql: sqlDB}
+}
+
+const paymentOrderColumns = `id, order_no, user_id, kind, plan_id, provider, channel, amount, currency, credit_amount,
+	status, provider_trade_no, payment_url, subscription_id, granted_days, refund_reason,
+	expires_at, paid_at, refunded_at, created_at, updated_at`
+
+func (r *paymentRepository) CreateOrder(ctx context.Context, order *service.PaymentOrder) error {
+	err := scanSingleRow(ctx, sqlExecutorFromContext(ctx, r.sql), `
+		INSERT INTO payment_orders (
+			order_no, user_id, kind, plan_id, provider, channel, amount, currency, credit_amount,
+			status, expires_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, []any{
+		order.OrderNo, order.UserID, order.Kind, nullInt64(order.PlanID), order.Provider, order.Channel,
+		order.Amount, order.Currency, order.CreditAmount, order.Status, order.ExpiresAt,
+	}, &order.ID, &order.CreatedAt, &order.UpdatedAt)
+	return translatePersistenceError(err, nil, service.ErrPaymentOrderExists)
+}
+
+func (r *paymentRepository) GetOrderByID(ctx context.Context, id int64) (*service.PaymentOrder, error) {
+	return r.getOrder(ctx, `WHERE id = $1`, id)
+}
+
+func (r *paymentRepository) GetOrderByNo(ctx context.Context, orderNo string) (*service.PaymentOrder, error) {
+	return r.getOrder(ctx, `WHERE order_no = $1`, orderNo)
+}
+
+func (r *paymentRepository) GetOrderByProviderTradeNo(ctx context.Context, provider, tradeNo string) (*service.PaymentOrder, error) {
+	if tradeNo == "" {
+		return nil, service.ErrPaymentOrderNotFound
+	}
+	return r.getOrder(ctx, `WHERE provider = $1 AND provider_trade_no = $2 ORDER BY id DESC`, provider, tradeNo)
+}
+
+func (r *paymentRepository) getOrder(ctx context.Context, where string, args ...any) (*service.PaymentOrder, error) {
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `SELECT `+paymentOrderColumns+` FROM payment_orders `+where+` LIMIT 1`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, service.ErrPaymentOrderNotFound
+	}
+	order, err := scanPaymentOrder(rows)
+	if err != nil {
+		return nil, err
+	}
+	return order, rows.Err()
+}
+
+func (r *paymentRepository) UpdateCheckout(ctx context.Context, id int64, paymentURL, providerTradeNo string) error {
+	_, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE payment_orders
+		SET payment_url = $2,
+			provider_trade_no = CASE WHEN $3 = '' THEN provider_trade_no ELSE $3 END,
+			updated_at = NOW()
+		WHERE id = $1
+	`, id, paymentURL, providerTradeNo)
+	return err
+}
+
+func (r *paymentRepository) MarkPaid(ctx context.Context, id int64, providerTradeNo string, paidAt time.Time) (bool, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE payment_orders
+		SET status = $2,
+			provider_trade_no = CASE WHEN $3 = '' THEN provider_trade_no ELSE $3 END,
+			paid_at = $4,
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ($5, $6, $7)
+	`, id, service.PaymentOrderStatusPaid, providerTradeNo, paidAt,
+		service.PaymentOrderStatusPending, service.PaymentOrderStatusExpired, service.PaymentOrderStatusCanceled)
+	return rowsAffectedPositive(res, err)
+}
+
+func (r *paymentRepository) SetSubscriptionGrant(ctx context.Context, id, subscriptionID int64, grantedDays int) error {
+	_, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE payment_orders SET subscription_id = $2, granted_days = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, subscriptionID, grantedDays)
+	return err
+}
+
+func (r *paymentRepository) MarkReversed(ctx context.Context, id int64, status, reason string, at time.Time) (bool, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE payment_orders
+		SET status = $2, refund_reason = $3, refunded_at = $4, updated_at = NOW()
+		WHERE id = $1 AND status = $5
+	`, id, status, reason, at, service.PaymentOrderStatusPaid)
+	return rowsAffectedPositive(res, err)
+}
+
+func (r *paymentRepository) CancelOrder(ctx context.Context, id, userID int64) (bool, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE payment_orders SET status = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status = $4
+	`, id, userID, service.PaymentOrderStatusCanceled, service.PaymentOrderStatusPending)
+	return rowsAffectedPositive(res, err)
+}
+
+func (r *paymentRepository) ExpirePendingOrders(ctx context.Context, now time.Time) (int64, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE payment_orders SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND expires_at <= $3
+	`, service.PaymentOrderStatusExpired, service.PaymentOrderStatusPending, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *paymentRepository) ListOrders(ctx context.Context, params pagination.PaginationParams, filter service.PaymentOrderFilter) ([]service.PaymentOrder, *pagination.PaginationResult, error) {
+	conds := []string{"1=1"}
+	args := []any{}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.UserID != nil {
+		conds = append(conds, "user_id = "+addArg(*filter.UserID))
+	}
+	if filter.Status != "" {
+		conds = append(conds, "status = "+addArg(filter.Status))
+	}
+	if filter.Provider != "" {
+		conds = append(conds, "provider = "+addArg(filter.Provider))
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		p := addArg("%" + search + "%")
+		conds = append(conds, "(order_no ILIKE "+p+" OR provider_trade_no ILIKE "+p+")")
+	}
+	where := " WHERE " + strings.Join(conds, " AND ")
+
+	q := sqlExecutorFromContext(ctx, r.sql)
+	var total int64
+	if err := scanSingleRow(ctx, q, `SELECT COUNT(*) FROM payment_orders`+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+	if total == 0 {
+		return []service.PaymentOrder{}, paginationResultFromTotal(0, params), nil
+	}
+
+	limitArg := addArg(params.Limit())
+	offsetArg := addArg(params.Offset())
+	rows, err := q.QueryContext(ctx, `SELECT `+paymentOrderColumns+` FROM payment_orders`+where+
+		` ORDER BY created_at DESC, id DESC LIMIT `+limitArg+` OFFSET `+offsetArg, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	orders := make([]service.PaymentOrder, 0)
+	for rows.Next() {
+		order, err := scanPaymentOrder(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		orders = append(orders, *order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return orders, paginationResultFromTotal(total, params), nil
+}
+
+func (r *paymentRepository) ListPlanPrices(ctx context.Context) ([]service.PaymentPlanPrice, error) {
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT plan_id, price, currency, enabled, updated_at
+		FROM payment_plan_prices
+		ORDER BY plan_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	prices := make([]service.PaymentPlanPrice, 0)
+	for rows.Next() {
+		var p service.PaymentPlanPrice
+		if err := rows.Scan(&p.PlanID, &p.Price, &p.Currency, &p.Enabled, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+	return prices, rows.Err()
+}
+
+func (r *paymentRepository) GetPlanPrice(ctx context.Context, planID int64) (*service.PaymentPlanPrice, error) {
+	var p service.PaymentPlanPrice
+	err := scanSingleRow(ctx, sqlExecutorFromContext(ctx, r.sql), `
+		SELECT plan_id, price, currency, enabled, updated_at
+		FROM payment_plan_prices
+		WHERE plan_id = $1
+	`, []any{planID}, &p.PlanID, &p.Price, &p.Currency, &p.Enabled, &p.UpdatedAt)
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrPaymentPlanPriceNotFound, nil)
+	}
+	return &p, nil
+}
+
+func (r *paymentRepository) UpsertPlanPrice(ctx context.Context, price *service.PaymentPlanPrice) error {
+	_, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		INSERT INTO payment_plan_prices (plan_id, price, currency, enabled, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (plan_id) DO UPDATE
+		SET price = EXCLUDED.price, currency = EXCLUDED.currency, enabled = EXCLUDED.enabled, updated_at = NOW()
+	`, price.PlanID, price.Price, price.Currency, price.Enabled)
+	return err
+}
+
+func (r *paymentRepository) DeletePlanPrice(ctx context.Context, planID int64) error {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `DELETE FROM payment_plan_prices WHERE plan_id = $1`, planID)
+	deleted, err := rowsAffectedPositive(res, err)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return service.ErrPaymentPlanPriceNotFound
+	}
+	return nil
+}
+
+func scanPaymentOrder(rows *sql.Rows) (*service.PaymentOrder, error) {
+	var o service.PaymentOrder
+	var planID, subscriptionID sql.NullInt64
+	var paidAt, refundedAt sql.NullTime
+	if err := rows.Scan(
+		&o.ID, &o.OrderNo, &o.UserID, &o.Kind, &planID, &o.Provider, &o.Channel, &o.Amount, &o.Currency, &o.CreditAmount,
+		&o.Status, &o.ProviderTradeNo, &o.PaymentURL, &subscriptionID, &o.GrantedDays, &o.RefundReason,
+		&o.ExpiresAt, &paidAt, &refundedAt, &o.CreatedAt, &o.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if planID.Valid {
+		v := planID.Int64
+		o.PlanID = &v
+	}
+	if subscriptionID.Valid {
+		v := subscriptionID.Int64
+		o.SubscriptionID = &v
+	}
+	if paidAt.Valid {
+		v := paidAt.Time
+		o.PaidAt = &v
+	}
+	if refundedAt.Valid {
+		v := refundedAt.Time
+		o.RefundedAt = &v
+	}
+	return &o, nil
+}
+
+func rowsAffectedPositive(res sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type postpaidStatementRepository struct {
+	sql sqlExecutor
+}
+
+// NewPostpaidStatementRepository 后付费账单仓储（原生 SQL，支持通过 context 参与 ent 事务）
+func NewPostpaidStatementRepository(sqlDB *sql.DB) service.PostpaidStatementRepository {
+	return &postpaidStatementRepository{sql: sqlDB}
+}
+
+const postpaidStatementColumns = `s.id, s.statement_no, s.user_id, COALESCE(u.email, ''), s.period_start, s.period_end, s.status,
+	s.subtotal, s.tax_rate, s.tax_amount, s.total, s.request_count, s.line_items, s.due_at,
+	s.paid_at, s.paid_by, s.payment_reference, s.notes, s.created_at, s.updated_at`
+
+func (r *postpaidStatementRepository) ListPostpaidAccounts(ctx context.Context) ([]service.PostpaidAccount, error) {
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT id, billing_cycle_day
+		FROM users
+		WHERE billing_mode = $1 AND deleted_at IS NULL
+		ORDER BY id
+	`, service.BillingModePostpaid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	accounts := make([]service.PostpaidAccount, 0)
+	for rows.Next() {
+		var a service.PostpaidAccount
+		if err := rows.Scan(&a.UserID, &a.BillingCycleDay); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *postpaidStatementRepository) ExistsForPeriod(ctx context.Context, userID int64, periodStart time.Time) (bool, error) {
+	var exists bool
+	err := scanSingleRow(ctx, sqlExecutorFromContext(ctx, r.sql), `
+		SELECT EXISTS (SELECT 1 FROM postpaid_statements WHERE user_id = $1 AND period_start = $2)
+	`, []any{userID, periodStart}, &exists)
+	return exists, err
+}
+
+// AggregateUsage 汇总账期内 billing_type=postpaid 的使用记录；响应缓存命中按实际计费方式记录计费类型，
+// 仅后付费期间的命中计入账单。
+func (r *postpaidStatementRepository) AggregateUsage(ctx context.Context, userID int64, start, end time.Time) ([]service.PostpaidStatementLine, error) {
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT ul.api_key_id, COALESCE(k.name, ''), ul.group_id, COALESCE(g.name, ''), ul.model,
+			COUNT(*),
+			COALESCE(SUM(ul.input_tokens), 0),
+			COALESCE(SUM(ul.output_tokens), 0),
+			COALESCE(SUM(ul.cache_creation_tokens), 0),
+			COALESCE(SUM(ul.cache_read_tokens), 0),
+			COALESCE(SUM(ul.actual_cost), 0)
+		FROM usage_logs ul
+		LEFT JOIN api_keys k ON k.id = ul.api_key_id
+		LEFT JOIN groups g ON g.id = ul.group_id
+		WHERE ul.user_id = $1
+			AND ul.created_at >= $2 AND ul.created_at < $3
+			AND ul.billing_type = $4
+		GROUP BY ul.api_key_id, k.name, ul.group_id, g.name, ul.model
+		ORDER BY ul.api_key_id, ul.group_id NULLS FIRST, ul.model
+	`, userID, start, end, service.BillingTypePostpaid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	lines := make([]service.PostpaidStatementLine, 0)
+	for rows.Next() {
+		var (
+			line    service.PostpaidStatementLine
+			groupID sql.NullInt64
+		)
+		if err := rows.Scan(
+			&line.APIKeyID, &line.APIKeyName, &groupID, &line.GroupName, &line.Model,
+			&line.RequestCount, &line.InputTokens, &line.OutputTokens,
+			&line.CacheCreationTokens, &line.CacheReadTokens, &line.Amount,
+		); err != nil {
+			return nil, err
+		}
+		line.GroupID = nullInt64ToPtr(groupID)
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func (r *postpaidStatementRepository) Create(ctx context.Context, st *service.PostpaidStatement) error {
+	if st == nil {
+		return nil
+	}
+	lineItems, err := json.Marshal(st.LineItems)
+	if err != nil {
+		return fmt.Errorf("marshal statement line items: %w", err)
+	}
+	err = scanSingleRow(ctx, sqlExecutorFromContext(ctx, r.sql), `
+		INSERT INTO postpaid_statements (
+			statement_no, user_id, period_start, period_end, status,
+			subtotal, tax_rate, tax_amount, total, request_count, line_items, due_at, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11::jsonb, $12, $13)
+		RETURNING id, created_at, updated_at
+	`, []any{
+		st.StatementNo, st.UserID, st.PeriodStart, st.PeriodEnd, st.Status,
+		st.Subtotal, st.TaxRate, st.TaxAmount, st.Total, st.RequestCount, string(lineItems), st.DueAt, st.Notes,
+	}, &st.ID, &st.CreatedAt, &st.UpdatedAt)
+	return translatePersistenceError(err, nil, service.ErrPostpaidStatementExists)
+}
+
+func (r *postpaidStatementRepository) GetByID(ctx context.Context, id int64) (*service.PostpaidStatement, error) {
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		SELECT `+postpaidStatementColumns+`
+		FROM postpaid_statements s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, service.ErrPostpaidStatementNotFound
+	}
+	st, err := scanPostpaidStatement(rows, true)
+	if err != nil {
+		return nil, err
+	}
+	return st, rows.Err()
+}
+
+// List 列表不返回明细，详情通过 GetByID 获取
+func (r *postpaidStatementRepository) List(ctx context.Context, params pagination.PaginationParams, filter service.PostpaidStatementFilter) ([]service.PostpaidStatement, *pagination.PaginationResult, error) {
+	conds := []string{"1 = 1"}
+	args := []any{}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.UserID > 0 {
+		conds = append(conds, "s.user_id = "+addArg(filter.UserID))
+	}
+	if status := strings.TrimSpace(filter.Status); status != "" {
+		conds = append(conds, "s.status = "+addArg(status))
+	}
+	where := "WHERE " + strings.Join(conds, " AND ")
+	exec := sqlExecutorFromContext(ctx, r.sql)
+
+	var total int64
+	if err := scanSingleRow(ctx, exec, `SELECT COUNT(*) FROM postpaid_statements s `+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+
+	limitArgs := append(append([]any{}, args...), params.Limit(), params.Offset())
+	rows, err := exec.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM postpaid_statements s
+		LEFT JOIN users u ON u.id = s.user_id
+		%s
+		ORDER BY s.period_start DESC, s.id DESC
+		LIMIT $%d OFFSET $%d
+	`, postpaidStatementColumns, where, len(args)+1, len(args)+2), limitArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	statements := make([]service.PostpaidStatement, 0)
+	for rows.Next() {
+		st, err := scanPostpaidStatement(rows, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		statements = append(statements, *st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return statements, paginationResultFromTotal(total, params), nil
+}
+
+func (r *postpaidStatementRepository) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE postpaid_statements
+		SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND due_at < $3
+	`, service.PostpaidStatementStatusOverdue, service.PostpaidStatementStatusIssued, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *postpaidStatementRepository) SuspendOverdueAPIKeys(ctx context.Context) ([]int64, error) {
+	rows, err := sqlExecutorFromContext(ctx, r.sql).QueryContext(ctx, `
+		UPDATE api_keys
+		SET status = $1, updated_at = NOW()
+		WHERE status = $2
+			AND deleted_at IS NULL
+			AND user_id IN (SELECT DISTINCT user_id FROM postpaid_statements WHERE status = $3)
+		RETURNING user_id
+	`, service.StatusAPIKeyOverdue, service.StatusAPIKeyActive, service.PostpaidStatementStatusOverdue)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	seen := make(map[int64]struct{})
+	userIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+func (r *postpaidStatementRepository) MarkPaid(ctx context.Context, id int64, payment service.PostpaidPayment) (bool, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE postpaid_statements
+		SET status = $2, paid_at = $3, paid_by = $4, payment_reference = $5,
+			notes = CASE WHEN $6::text = '' THEN notes ELSE $6::text END,
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ($7, $8)
+	`, id, service.PostpaidStatementStatusPaid, payment.PaidAt, nullInt64(positiveInt64Ptr(payment.PaidBy)), payment.Reference, payment.Notes,
+		service.PostpaidStatementStatusIssued, service.PostpaidStatementStatusOverdue)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *postpaidStatementRepository) RestoreAPIKeysIfSettled(ctx context.Context, userID int64) (int64, error) {
+	res, err := sqlExecutorFromContext(ctx, r.sql).ExecContext(ctx, `
+		UPDATE api_keys
+		SET status = $2, updated_at = NOW()
+		WHERE user_id = $1
+			AND status = $3
+			AND NOT EXISTS (SELECT 1 FROM postpaid_statements WHERE user_id = $1 AND status = $4)
+	`, userID, service.StatusAPIKeyActive, service.StatusAPIKeyOverdue, service.PostpaidStatementStatusOverdue)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func positiveInt64Ptr(v int64) *int64 {
+	if v <= 0 {
+		return nil
+	}
+	return &v
+}
+
+func scanPostpaidStatement(rows *sql.Rows, withLines bool) (*service.PostpaidStatement, error) {
+	var (
+		st        service.PostpaidStatement
+		lineItems []byte
+		paidAt    sql.NullTime
+		paidBy    sql.NullInt64
+	)
+	if err := rows.Scan(
+		&st.ID, &st.StatementNo, &st.UserID, &st.UserEmail, &st.PeriodStart, &st.PeriodEnd, &st.Status,
+		&st.Subtotal, &st.TaxRate, &st.TaxAmount, &st.Total, &st.RequestCount, &lineItems, &st.DueAt,
+		&paidAt, &paidBy, &st.PaymentReference, &st.Notes, &st.CreatedAt, &st.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if paidAt.Valid {
+		t := paidAt.Time
+		st.PaidAt = &t
+	}
+	st.PaidBy = nullInt64ToPtr(paidBy)
+	if withLines && len(lineItems) > 0 {
+		if err := json.Unmarshal(lineItems, &st.LineItems); err != nil {
+			return nil, fmt.Errorf("unmarshal statement line items: %w", err)
+		}
+	}
+	return &st, nil
+}
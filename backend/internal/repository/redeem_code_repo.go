@@ -213,55 +213,19 @@ func (r *redeemCodeRepository) ListByUser(ctx context.Context, userID int64, lim
 	return redeemCodeEntitiesToService(codes), nil
 }
 
-// ListByUserPaginated returns paginated balance/concurrency history for a user.
-// Supports optional type filter (e.g. "balance", "admin_balance", "concurrency", "admin_concurrency", "subscription").
-func (r *redeemCodeRepository) ListByUserPaginated(ctx context.Context, userID int64, params pagination.PaginationParams, codeType string) ([]service.RedeemCode, *pagination.PaginationResult, error) {
-	q := r.client.RedeemCode.Query().
-		Where(redeemcode.UsedByEQ(userID))
-
-	// Optional type filter
-	if codeType != "" {
-		q = q.Where(redeemcode.TypeEQ(codeType))
-	}
-
-	total, err := q.Count(ctx)
-	if err != nil {
-		return nil, nil, err
+// ListByIDs returns the redeem codes with the given IDs, including their groups.
+func (r *redeemCodeRepository) ListByIDs(ctx context.Context, ids []int64) ([]service.RedeemCode, error) {
+	if len(ids) == 0 {
+		return []service.RedeemCode{}, nil
 	}
-
-	codes, err := q.
+	codes, err := r.client.RedeemCode.Query().
+		Where(redeemcode.IDIn(ids...)).
 		WithGroup().
-		Offset(params.Offset()).
-		Limit(params.Limit()).
-		Order(dbent.Desc(redeemcode.FieldUsedAt)).
 		All(ctx)
 	if err != nil {
-		return nil, nil, err
-	}
-
-	return redeemCodeEntitiesToService(codes), paginationResultFromTotal(int64(total), params), nil
-}
-
-// SumPositiveBalanceByUser returns total recharged amount (sum of value > 0 where type is balance/admin_balance).
-func (r *redeemCodeRepository) SumPositiveBalanceByUser(ctx context.Context, userID int64) (float64, error) {
-	var result []struct {
-		Sum float64 `json:"sum"`
-	}
-	err := r.client.RedeemCode.Query().
-		Where(
-			redeemcode.UsedByEQ(userID),
-			redeemcode.ValueGT(0),
-			redeemcode.TypeIn("balance", "admin_balance"),
-		).
-		Aggregate(dbent.As(dbent.Sum(redeemcode.FieldValue), "sum")).
-		Scan(ctx, &result)
-	if err != nil {
-		return 0, err
-	}
-	if len(result) == 0 {
-		return 0, nil
+		return nil, err
 	}
-	return result[0].Sum, nil
+	return redeemCodeEntitiesToService(codes), nil
 }
 
 func redeemCodeEntityToService(m *dbent.RedeemCode) *service.RedeemCode {
@@ -22,6 +22,8 @@ func TestRedeemServiceRedeem_GroupRequestQuotaCreatesIndependentGrantWithExpiry(
 	redeemService := service.NewRedeemService(
 		redeemRepo,
 		userRepo,
+		NewBalanceLedgerRepository(integrationDB),
+		nil,
 		nil,
 		nil,
 		nil,
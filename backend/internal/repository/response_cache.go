@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// 响应缓存键格式: response_cache:{groupID}:{fingerprint}
+// 按分组前缀组织，便于管理端按分组 SCAN 清理。
+const (
+	responseCacheKeyPrefix = "response_cache:"
+	responseCacheScanCount = 200
+)
+
+type responseCache struct {
+	rdb *redis.Client
+}
+
+// NewResponseCache 创建响应缓存
+func NewResponseCache(rdb *redis.Client) service.ResponseCache {
+	return &responseCache{rdb: rdb}
+}
+
+func responseCacheKey(groupID int64, fingerprint string) string {
+	return responseCacheKeyPrefix + strconv.FormatInt(groupID, 10) + ":" + fingerprint
+}
+
+func (c *responseCache) Get(ctx context.Context, groupID int64, fingerprint string) (*service.ResponseCacheEntry, error) {
+	data, err := c.rdb.Get(ctx, responseCacheKey(groupID, fingerprint)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entry service.ResponseCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("unmarshal response cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func (c *responseCache) Set(ctx context.Context, groupID int64, fingerprint string, entry *service.ResponseCacheEntry, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, responseCacheKey(groupID, fingerprint), data, ttl).Err()
+}
+
+func (c *responseCache) PurgeGroup(ctx context.Context, groupID int64) (int64, error) {
+	return c.deleteByPattern(ctx, responseCacheKeyPrefix+strconv.FormatInt(groupID, 10)+":*")
+}
+
+func (c *responseCache) PurgeAll(ctx context.Context) (int64, error) {
+	return c.deleteByPattern(ctx, responseCacheKeyPrefix+"*")
+}
+
+// deleteByPattern 扫描匹配 pattern 的键并删除，返回删除条数。
+func (c *responseCache) deleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	var deleted int64
+	var cursor uint64
+	for {
+		keys, nextCursor, err := c.rdb.Scan(ctx, cursor, pattern, responseCacheScanCount).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("scan %s: %w", pattern, err)
+		}
+		if len(keys) > 0 {
+			n, err := c.rdb.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, fmt.Errorf("del %s: %w", pattern, err)
+			}
+			deleted += n
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
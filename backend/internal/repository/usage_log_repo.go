@@ -28,7 +28,7 @@ import (
 	gocache "github.com/patrickmn/go-cache"
 )
 
-const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, media_type, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, created_at"
+const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, media_type, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, batch_id, batch_custom_id, batch_multiplier, audio_duration_seconds, audio_characters, trace_id, price_source, response_cache_hit, created_at"
 
 // usageLogInsertArgTypes must stay in the same order as:
 //  1. prepareUsageLogInsert().args
@@ -77,6 +77,14 @@ var usageLogInsertArgTypes = [...]string{
 	"text",        // inbound_endpoint
 	"text",        // upstream_endpoint
 	"boolean",     // cache_ttl_overridden
+	"text",        // batch_id
+	"text",        // batch_custom_id
+	"numeric",     // batch_multiplier
+	"numeric",     // audio_duration_seconds
+	"integer",     // audio_characters
+	"text",        // trace_id
+	"text",        // price_source
+	"boolean",     // response_cache_hit
 	"timestamptz", // created_at
 }
 
@@ -350,6 +358,14 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			inbound_endpoint,
 			upstream_endpoint,
 			cache_ttl_overridden,
+			batch_id,
+			batch_custom_id,
+			batch_multiplier,
+			audio_duration_seconds,
+			audio_characters,
+			trace_id,
+			price_source,
+			response_cache_hit,
 			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -357,7 +373,7 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			$10, $11, $12, $13,
 			$14, $15,
 			$16, $17, $18, $19, $20, $21,
-			$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40
+			$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 		RETURNING id, created_at
@@ -782,10 +798,18 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 			inbound_endpoint,
 			upstream_endpoint,
 			cache_ttl_overridden,
+			batch_id,
+			batch_custom_id,
+			batch_multiplier,
+			audio_duration_seconds,
+			audio_characters,
+			trace_id,
+			price_source,
+			response_cache_hit,
 			created_at
 		) AS (VALUES `)
 
-	args := make([]any, 0, len(keys)*39)
+	args := make([]any, 0, len(keys)*47)
 	argPos := 1
 	for idx, key := range keys {
 		if idx > 0 {
@@ -853,6 +877,14 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				inbound_endpoint,
 				upstream_endpoint,
 				cache_ttl_overridden,
+				batch_id,
+				batch_custom_id,
+				batch_multiplier,
+				audio_duration_seconds,
+				audio_characters,
+				trace_id,
+				price_source,
+				response_cache_hit,
 				created_at
 			)
 			SELECT
@@ -895,6 +927,14 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				inbound_endpoint,
 				upstream_endpoint,
 				cache_ttl_overridden,
+				batch_id,
+				batch_custom_id,
+				batch_multiplier,
+				audio_duration_seconds,
+				audio_characters,
+				trace_id,
+				price_source,
+				response_cache_hit,
 				created_at
 			FROM input
 			ON CONFLICT (request_id, api_key_id) DO NOTHING
@@ -977,6 +1017,14 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			inbound_endpoint,
 			upstream_endpoint,
 			cache_ttl_overridden,
+			batch_id,
+			batch_custom_id,
+			batch_multiplier,
+			audio_duration_seconds,
+			audio_characters,
+			trace_id,
+			price_source,
+			response_cache_hit,
 			created_at
 		) AS (VALUES `)
 
@@ -1045,6 +1093,14 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			inbound_endpoint,
 			upstream_endpoint,
 			cache_ttl_overridden,
+			batch_id,
+			batch_custom_id,
+			batch_multiplier,
+			audio_duration_seconds,
+			audio_characters,
+			trace_id,
+			price_source,
+			response_cache_hit,
 			created_at
 		)
 		SELECT
@@ -1087,6 +1143,14 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			inbound_endpoint,
 			upstream_endpoint,
 			cache_ttl_overridden,
+			batch_id,
+			batch_custom_id,
+			batch_multiplier,
+			audio_duration_seconds,
+			audio_characters,
+			trace_id,
+			price_source,
+			response_cache_hit,
 			created_at
 		FROM input
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
@@ -1137,6 +1201,14 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			inbound_endpoint,
 			upstream_endpoint,
 			cache_ttl_overridden,
+			batch_id,
+			batch_custom_id,
+			batch_multiplier,
+			audio_duration_seconds,
+			audio_characters,
+			trace_id,
+			price_source,
+			response_cache_hit,
 			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -1144,7 +1216,7 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			$10, $11, $12, $13,
 			$14, $15,
 			$16, $17, $18, $19, $20, $21,
-			$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40
+			$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 	`, prepared.args...)
@@ -1232,6 +1304,14 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 			inboundEndpoint,
 			upstreamEndpoint,
 			log.CacheTTLOverridden,
+			nullString(log.BatchID),
+			nullString(log.BatchCustomID),
+			log.BatchMultiplier,
+			log.AudioDurationSeconds,
+			log.AudioCharacters,
+			nullString(log.TraceID),
+			nullString(log.PriceSource),
+			log.ResponseCacheHit,
 			createdAt,
 		},
 	}
@@ -4058,6 +4138,14 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		inboundEndpoint       sql.NullString
 		upstreamEndpoint      sql.NullString
 		cacheTTLOverridden    bool
+		batchID               sql.NullString
+		batchCustomID         sql.NullString
+		batchMultiplier       sql.NullFloat64
+		audioDurationSeconds  float64
+		audioCharacters       int
+		traceID               sql.NullString
+		priceSource           sql.NullString
+		responseCacheHit      bool
 		createdAt             time.Time
 	)
 
@@ -4102,6 +4190,14 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		&inboundEndpoint,
 		&upstreamEndpoint,
 		&cacheTTLOverridden,
+		&batchID,
+		&batchCustomID,
+		&batchMultiplier,
+		&audioDurationSeconds,
+		&audioCharacters,
+		&traceID,
+		&priceSource,
+		&responseCacheHit,
 		&createdAt,
 	); err != nil {
 		return nil, err
@@ -4132,6 +4228,7 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		RequestType:           service.RequestTypeFromInt16(requestTypeRaw),
 		ImageCount:            imageCount,
 		CacheTTLOverridden:    cacheTTLOverridden,
+		ResponseCacheHit:      responseCacheHit,
 		CreatedAt:             createdAt,
 	}
 	// 先回填 legacy 字段，再基于 legacy + request_type 计算最终请求类型，保证历史数据兼容。
@@ -4186,6 +4283,21 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 	if upstreamModel.Valid {
 		log.UpstreamModel = &upstreamModel.String
 	}
+	if batchID.Valid {
+		log.BatchID = &batchID.String
+	}
+	if batchCustomID.Valid {
+		log.BatchCustomID = &batchCustomID.String
+	}
+	log.BatchMultiplier = nullFloat64Ptr(batchMultiplier)
+	log.AudioDurationSeconds = audioDurationSeconds
+	log.AudioCharacters = audioCharacters
+	if traceID.Valid {
+		log.TraceID = &traceID.String
+	}
+	if priceSource.Valid {
+		log.PriceSource = &priceSource.String
+	}
 
 	return log, nil
 }
@@ -80,6 +80,14 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			sqlmock.AnyArg(), // inbound_endpoint
 			sqlmock.AnyArg(), // upstream_endpoint
 			log.CacheTTLOverridden,
+			sqlmock.AnyArg(), // batch_id
+			sqlmock.AnyArg(), // batch_custom_id
+			sqlmock.AnyArg(), // batch_multiplier
+			sqlmock.AnyArg(), // audio_duration_seconds
+			sqlmock.AnyArg(), // audio_characters
+			sqlmock.AnyArg(), // trace_id
+			sqlmock.AnyArg(), // price_source
+			sqlmock.AnyArg(), // response_cache_hit
 			createdAt,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(99), createdAt))
@@ -153,6 +161,14 @@ func TestUsageLogRepositoryCreate_PersistsServiceTier(t *testing.T) {
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			log.CacheTTLOverridden,
+			sqlmock.AnyArg(), // batch_id
+			sqlmock.AnyArg(), // batch_custom_id
+			sqlmock.AnyArg(), // batch_multiplier
+			sqlmock.AnyArg(), // audio_duration_seconds
+			sqlmock.AnyArg(), // audio_characters
+			sqlmock.AnyArg(), // trace_id
+			sqlmock.AnyArg(), // price_source
+			sqlmock.AnyArg(), // response_cache_hit
 			createdAt,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(100), createdAt))
@@ -463,6 +479,14 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			false,
+			sql.NullString{},
+			sql.NullString{},
+			sql.NullFloat64{},
+			0.0,
+			0,
+			sql.NullString{},
+			sql.NullString{},
+			false,
 			now,
 		}})
 		require.NoError(t, err)
@@ -506,6 +530,14 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			false,
+			sql.NullString{},
+			sql.NullString{},
+			sql.NullFloat64{},
+			0.0,
+			0,
+			sql.NullString{},
+			sql.NullString{},
+			false,
 			now,
 		}})
 		require.NoError(t, err)
@@ -549,11 +581,30 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			false,
+			sql.NullString{Valid: true, String: "msgbatch_1"},
+			sql.NullString{Valid: true, String: "req-a"},
+			sql.NullFloat64{Valid: true, Float64: 0.5},
+			12.5,
+			300,
+			sql.NullString{Valid: true, String: "4bf92f3577b34da6a3ce929d0e0e4736"},
+			sql.NullString{Valid: true, String: "group"},
+			true,
 			now,
 		}})
 		require.NoError(t, err)
 		require.NotNil(t, log.ServiceTier)
 		require.Equal(t, "priority", *log.ServiceTier)
+		require.NotNil(t, log.BatchID)
+		require.Equal(t, "msgbatch_1", *log.BatchID)
+		require.Equal(t, "req-a", *log.BatchCustomID)
+		require.InDelta(t, 0.5, *log.BatchMultiplier, 1e-12)
+		require.InDelta(t, 12.5, log.AudioDurationSeconds, 1e-12)
+		require.Equal(t, 300, log.AudioCharacters)
+		require.NotNil(t, log.TraceID)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", *log.TraceID)
+		require.NotNil(t, log.PriceSource)
+		require.Equal(t, service.PriceSourceGroup, *log.PriceSource)
+		require.True(t, log.ResponseCacheHit)
 	})
 
 }
@@ -63,6 +63,9 @@ func (r *userRepository) Create(ctx context.Context, userIn *service.User) error
 		SetConcurrency(userIn.Concurrency).
 		SetStatus(userIn.Status).
 		SetSoraStorageQuotaBytes(userIn.SoraStorageQuotaBytes).
+		SetBillingMode(service.NormalizeUserBillingMode(userIn.BillingMode)).
+		SetCreditLimit(userIn.CreditLimit).
+		SetBillingCycleDay(service.NormalizeBillingCycleDay(userIn.BillingCycleDay)).
 		Save(ctx)
 	if err != nil {
 		return translatePersistenceError(err, nil, service.ErrEmailExists)
@@ -72,6 +75,16 @@ func (r *userRepository) Create(ctx context.Context, userIn *service.User) error
 		return err
 	}
 
+	if created.Balance != 0 {
+		exec := sqlExecutorFromContext(ctx, r.sql)
+		if tx != nil {
+			exec = tx
+		}
+		if err := insertOpeningBalanceEntry(ctx, exec, created.ID, created.Balance); err != nil {
+			return err
+		}
+	}
+
 	if tx != nil {
 		if err := tx.Commit(); err != nil {
 			return err
@@ -136,17 +149,21 @@ func (r *userRepository) Update(ctx context.Context, userIn *service.User) error
 		txClient = r.client
 	}
 
+	// 余额不在此处写回：余额只能通过 BalanceLedgerRepository.Apply（入账/调整）或 DeductBalance（请求扣费）原子调整，
+	// 避免读-改-写覆盖并发扣费。
 	updated, err := txClient.User.UpdateOneID(userIn.ID).
 		SetEmail(userIn.Email).
 		SetUsername(userIn.Username).
 		SetNotes(userIn.Notes).
 		SetPasswordHash(userIn.PasswordHash).
 		SetRole(userIn.Role).
-		SetBalance(userIn.Balance).
 		SetConcurrency(userIn.Concurrency).
 		SetStatus(userIn.Status).
 		SetSoraStorageQuotaBytes(userIn.SoraStorageQuotaBytes).
 		SetSoraStorageUsedBytes(userIn.SoraStorageUsedBytes).
+		SetBillingMode(service.NormalizeUserBillingMode(userIn.BillingMode)).
+		SetCreditLimit(userIn.CreditLimit).
+		SetBillingCycleDay(service.NormalizeBillingCycleDay(userIn.BillingCycleDay)).
 		Save(ctx)
 	if err != nil {
 		return translatePersistenceError(err, service.ErrUserNotFound, service.ErrEmailExists)
@@ -334,34 +351,11 @@ func (r *userRepository) filterUsersByAttributes(ctx context.Context, attrs map[
 	return result, nil
 }
 
-func (r *userRepository) UpdateBalance(ctx context.Context, id int64, amount float64) error {
-	client := clientFromContext(ctx, r.client)
-	n, err := client.User.Update().Where(dbuser.IDEQ(id)).AddBalance(amount).Save(ctx)
-	if err != nil {
-		return translatePersistenceError(err, service.ErrUserNotFound, nil)
-	}
-	if n == 0 {
-		return service.ErrUserNotFound
-	}
-	return nil
-}
-
-// DeductBalance 扣除用户余额
+// DeductBalance 扣除用户余额（请求扣费），同时累加到账本待汇总表
 // 透支策略：允许余额变为负数，确保当前请求能够完成
 // 中间件会阻止余额 <= 0 的用户发起后续请求
 func (r *userRepository) DeductBalance(ctx context.Context, id int64, amount float64) error {
-	client := clientFromContext(ctx, r.client)
-	n, err := client.User.Update().
-		Where(dbuser.IDEQ(id)).
-		AddBalance(-amount).
-		Save(ctx)
-	if err != nil {
-		return err
-	}
-	if n == 0 {
-		return service.ErrUserNotFound
-	}
-	return nil
+	return deductBalanceWithPendingUsage(ctx, sqlExecutorFromContext(ctx, r.sql), id, amount)
 }
 
 func (r *userRepository) UpdateConcurrency(ctx context.Context, id int64, amount int) error {
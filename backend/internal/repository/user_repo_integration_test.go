@@ -254,28 +254,6 @@ func (s *UserRepoSuite) TestListWithFilters_CombinedFilters() {
 
 // --- Balance operations ---
 
-func (s *UserRepoSuite) TestUpdateBalance() {
-	user := s.mustCreateUser(&service.User{Email: "bal@test.com", Balance: 10})
-
-	err := s.repo.UpdateBalance(s.ctx, user.ID, 2.5)
-	s.Require().NoError(err, "UpdateBalance")
-
-	got, err := s.repo.GetByID(s.ctx, user.ID)
-	s.Require().NoError(err)
-	s.Require().InDelta(12.5, got.Balance, 1e-6)
-}
-
-func (s *UserRepoSuite) TestUpdateBalance_Negative() {
-	user := s.mustCreateUser(&service.User{Email: "balneg@test.com", Balance: 10})
-
-	err := s.repo.UpdateBalance(s.ctx, user.ID, -3)
-	s.Require().NoError(err, "UpdateBalance with negative")
-
-	got, err := s.repo.GetByID(s.ctx, user.ID)
-	s.Require().NoError(err)
-	s.Require().InDelta(7.0, got.Balance, 1e-6)
-}
-
 func (s *UserRepoSuite) TestDeductBalance() {
 	user := s.mustCreateUser(&service.User{Email: "deduct@test.com", Balance: 10})
 
@@ -485,15 +463,10 @@ func (s *UserRepoSuite) TestCRUD_And_Filters_And_AtomicUpdates() {
 	s.Require().NoError(err, "GetByID after update")
 	s.Require().Equal("Alice2", got2.Username, "Update did not persist")
 
-	s.Require().NoError(s.repo.UpdateBalance(s.ctx, user1.ID, 2.5), "UpdateBalance")
-	got3, err := s.repo.GetByID(s.ctx, user1.ID)
-	s.Require().NoError(err, "GetByID after UpdateBalance")
-	s.Require().InDelta(12.5, got3.Balance, 1e-6)
-
 	s.Require().NoError(s.repo.DeductBalance(s.ctx, user1.ID, 5), "DeductBalance")
 	got4, err := s.repo.GetByID(s.ctx, user1.ID)
 	s.Require().NoError(err, "GetByID after DeductBalance")
-	s.Require().InDelta(7.5, got4.Balance, 1e-6)
+	s.Require().InDelta(5.0, got4.Balance, 1e-6)
 
 	// 透支策略：允许扣除超过余额的金额
 	err = s.repo.DeductBalance(s.ctx, user1.ID, 999)
@@ -515,13 +488,7 @@ func (s *UserRepoSuite) TestCRUD_And_Filters_And_AtomicUpdates() {
 	s.Require().Equal(user2.ID, users[0].ID, "ListWithFilters result mismatch")
 }
 
-// --- UpdateBalance/UpdateConcurrency 影响行数校验测试 ---
-
-func (s *UserRepoSuite) TestUpdateBalance_NotFound() {
-	err := s.repo.UpdateBalance(s.ctx, 999999, 10.0)
-	s.Require().Error(err, "expected error for non-existent user")
-	s.Require().ErrorIs(err, service.ErrUserNotFound)
-}
+// --- UpdateConcurrency 影响行数校验测试 ---
 
 func (s *UserRepoSuite) TestUpdateConcurrency_NotFound() {
 	err := s.repo.UpdateConcurrency(s.ctx, 999999, 5)
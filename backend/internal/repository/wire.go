@@ -78,12 +78,18 @@ var ProviderSet = wire.NewSet(
 	NewAccountThrottleRepository,
 	NewTLSFingerprintProfileRepository,
 	NewSubscriptionPlanRepository,
+	NewBatchRepository,
+	NewPaymentRepository,
+	NewBalanceLedgerRepository,
+	NewPostpaidStatementRepository,
 
 	// Cache implementations
 	NewGatewayCache,
 	NewBillingCache,
 	NewAPIKeyCache,
 	NewTempUnschedCache,
+	NewAccountHealthStore,
+	NewDigestSessionCache,
 	NewTimeoutCounterCache,
 	NewInternal500CounterCache,
 	ProvideConcurrencyCache,
@@ -105,6 +111,7 @@ var ProviderSet = wire.NewSet(
 	NewAccountThrottleCache,
 	NewAccountThrottleCounterCache,
 	NewTLSFingerprintProfileCache,
+	NewResponseCache,
 
 	// Encryptors
 	NewAESEncryptor,
@@ -113,6 +120,10 @@ var ProviderSet = wire.NewSet(
 	NewPgDumper,
 	NewS3BackupStoreFactory,
 
+	// Batch file storage (local disk / S3)
+	NewBatchFileStore,
+	NewPayloadCaptureStore,
+
 	// HTTP service ports (DI Strategy A: return interface directly)
 	NewTurnstileVerifier,
 	ProvidePricingRemoteClient,
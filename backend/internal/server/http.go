@@ -34,7 +34,9 @@ func ProvideRouter(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	payloadCaptureService *service.PayloadCaptureService,
 	settingService *service.SettingService,
+	batchService *service.BatchService,
 	redisClient *redis.Client,
 ) *gin.Engine {
 	if cfg.Server.Mode == "release" {
@@ -56,7 +58,10 @@ func ProvideRouter(
 		}
 	}
 
-	return SetupRouter(r, handlers, jwtAuth, adminAuth, apiKeyAuth, apiKeyService, subscriptionService, opsService, settingService, cfg, redisClient)
+	engine := SetupRouter(r, handlers, jwtAuth, adminAuth, apiKeyAuth, apiKeyService, subscriptionService, opsService, payloadCaptureService, settingService, cfg, redisClient)
+	// batch 执行器通过网关路由重放请求，复用鉴权、路由与计费链路
+	batchService.SetRequestHandler(engine)
+	return engine
 }
 
 // ProvideHTTPServer 提供 HTTP 服务器
@@ -27,6 +27,9 @@ func NewAPIKeyAuthMiddleware(apiKeyService *service.APIKeyService, subscriptionS
 // /v1/usage 端点只需鉴权，不需要计费执行（允许过期/配额耗尽的 Key 查询自身用量）。
 func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscriptionService *service.SubscriptionService, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		authSpan := startStageSpan(c, "auth.api_key")
+		defer authSpan.end(c)
+
 		// ── 1. 提取 API Key ──────────────────────────────────────────
 
 		queryKey := strings.TrimSpace(c.Query("key"))
@@ -78,10 +81,11 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 
 		// ── 3. 基础鉴权（始终执行） ─────────────────────────────────
 
-		// disabled / 未知状态 → 无条件拦截（expired 和 quota_exhausted 留给计费阶段）
+		// disabled / 未知状态 → 无条件拦截（expired、quota_exhausted 和 overdue 留给计费阶段）
 		if !apiKey.IsActive() &&
 			apiKey.Status != service.StatusAPIKeyExpired &&
-			apiKey.Status != service.StatusAPIKeyQuotaExhausted {
+			apiKey.Status != service.StatusAPIKeyQuotaExhausted &&
+			apiKey.Status != service.StatusAPIKeyOverdue {
 			AbortWithError(c, 401, "API_KEY_DISABLED", "API key is disabled")
 			return
 		}
@@ -109,6 +113,10 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			return
 		}
 
+		annotateAuthSpan(c, apiKey)
+		groupSpan := startStageSpan(c, "auth.resolve_group")
+		defer groupSpan.end(c)
+
 		// 加载用户 + 分组维度的按次配额，优先于 API Key 自身按次配额。
 		if apiKey.Group != nil && apiKey.Group.ID > 0 {
 			groupQuota, quotaErr := apiKeyService.GetUserGroupRequestQuota(c.Request.Context(), apiKey.User.ID, apiKey.Group.ID)
@@ -133,6 +141,8 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
+			groupSpan.end(c)
+			authSpan.end(c)
 			c.Next()
 			return
 		}
@@ -156,6 +166,7 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			}
 			// 订阅不存在不拦截，后续由计费逻辑决定走余额还是拒绝
 		}
+		groupSpan.end(c)
 
 		// ── 6. 计费执行（skipBilling 时整块跳过） ────────────────────
 
@@ -168,6 +179,9 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			case service.StatusAPIKeyExpired:
 				AbortWithError(c, 403, "API_KEY_EXPIRED", "API key 已过期")
 				return
+			case service.StatusAPIKeyOverdue:
+				AbortWithError(c, 403, "API_KEY_OVERDUE", "账单逾期未付，API key 已暂停")
+				return
 			}
 
 			// 运行时过期/配额检查（即使状态是 active，也要检查时间和用量）
@@ -226,6 +240,7 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		setGroupContext(c, apiKey.Group)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 
+		authSpan.end(c)
 		c.Next()
 	}
 }
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Tracing 为已注册路由创建 server span。
+//
+// 入站 W3C traceparent 会被沿用（追踪未启用时同样透传 trace id），
+// trace id 通过 X-Trace-Id 响应头返回并写入 request-scoped logger。
+// 未匹配路由（前端静态资源等）不创建 span。
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if c.Request == nil || route == "" {
+			c.Next()
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+
+		if traceID := tracing.TraceIDFromContext(ctx); traceID != "" {
+			c.Header(tracing.TraceIDHeader, traceID)
+			ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(zap.String("trace_id", traceID)))
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// stageSpan 中间件内部阶段的 span。
+// 中间件会在自身逻辑结束后调用 c.Next()，因此需在 Next 之前显式结束 span，
+// 并把请求上下文中的活动 span 还原为父 span，避免后续 handler 挂在已结束的阶段 span 下。
+type stageSpan struct {
+	parent trace.Span
+	span   trace.Span
+	ended  bool
+}
+
+func startStageSpan(c *gin.Context, name string, attrs ...attribute.KeyValue) *stageSpan {
+	parent := trace.SpanFromContext(c.Request.Context())
+	ctx, span := tracing.Start(c.Request.Context(), name, attrs...)
+	c.Request = c.Request.WithContext(ctx)
+	return &stageSpan{parent: parent, span: span}
+}
+
+// end 结束阶段 span，可重复调用；请求已被中止时记录响应状态码
+func (s *stageSpan) end(c *gin.Context) {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+	if c.IsAborted() {
+		status := c.Writer.Status()
+		s.span.SetAttributes(attribute.Int("http.response.status_code", status))
+		s.span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	s.span.End()
+	c.Request = c.Request.WithContext(trace.ContextWithSpan(c.Request.Context(), s.parent))
+}
+
+// annotateAuthSpan 将鉴权结果写入当前 span
+func annotateAuthSpan(c *gin.Context, apiKey *service.APIKey) {
+	if apiKey == nil {
+		return
+	}
+	span := trace.SpanFromContext(c.Request.Context())
+	if !span.IsRecording() {
+		return
+	}
+	attrs := []attribute.KeyValue{attribute.Int64("api_key.id", apiKey.ID)}
+	if apiKey.User != nil {
+		attrs = append(attrs, attribute.Int64("user.id", apiKey.User.ID))
+	}
+	if apiKey.Group != nil {
+		attrs = append(attrs,
+			attribute.Int64("group.id", apiKey.Group.ID),
+			attribute.String("group.platform", apiKey.Group.Platform),
+		)
+	}
+	span.SetAttributes(attrs...)
+}
@@ -93,6 +93,15 @@ func RegisterAdminRoutes(
 
 		// 定时测试计划
 		registerScheduledTestRoutes(admin, h)
+
+		// 支付订单与计划定价
+		registerPaymentRoutes(admin, h)
+
+		// 余额账本
+		admin.POST("/balance-ledger/reconcile", h.Admin.BalanceLedger.Reconcile)
+
+		// 后付费月结账单
+		registerPostpaidStatementRoutes(admin, h)
 	}
 }
 
@@ -136,6 +145,27 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.PUT("/alert-events/:id/status", h.Admin.Ops.UpdateAlertEventStatus)
 		ops.POST("/alert-silences", h.Admin.Ops.CreateAlertSilence)
 
+		// Notification channels (webhook / IM) + delivery log
+		ops.GET("/notification-channels", h.Admin.OpsNotification.ListChannels)
+		ops.POST("/notification-channels", h.Admin.OpsNotification.CreateChannel)
+		ops.PUT("/notification-channels/:id", h.Admin.OpsNotification.UpdateChannel)
+		ops.DELETE("/notification-channels/:id", h.Admin.OpsNotification.DeleteChannel)
+		ops.POST("/notification-channels/:id/test", h.Admin.OpsNotification.TestChannel)
+		ops.GET("/notification-deliveries", h.Admin.OpsNotification.ListDeliveries)
+
+		// Payload capture (sampled request/response archival)
+		ops.GET("/payload-capture/settings", h.Admin.OpsPayloadCapture.GetSettings)
+		ops.PUT("/payload-capture/settings", h.Admin.OpsPayloadCapture.UpdateSettings)
+		ops.GET("/payload-captures/:id", h.Admin.OpsPayloadCapture.GetCapture)
+		ops.GET("/payload-captures/:id/content", h.Admin.OpsPayloadCapture.DownloadContent)
+
+		// Customer SLA + status page maintenance windows
+		ops.GET("/sla/customers", h.Admin.Ops.GetCustomerSLAReport)
+		ops.GET("/maintenance-windows", h.Admin.Ops.ListMaintenanceWindows)
+		ops.POST("/maintenance-windows", h.Admin.Ops.CreateMaintenanceWindow)
+		ops.PUT("/maintenance-windows/:id", h.Admin.Ops.UpdateMaintenanceWindow)
+		ops.DELETE("/maintenance-windows/:id", h.Admin.Ops.DeleteMaintenanceWindow)
+
 		// Email notification config (DB-backed)
 		ops.GET("/email-notification/config", h.Admin.Ops.GetEmailNotificationConfig)
 		ops.PUT("/email-notification/config", h.Admin.Ops.UpdateEmailNotificationConfig)
@@ -240,6 +270,8 @@ func registerUserManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		users.GET("/:id/api-keys", h.Admin.User.GetUserAPIKeys)
 		users.GET("/:id/usage", h.Admin.User.GetUserUsage)
 		users.GET("/:id/balance-history", h.Admin.User.GetBalanceHistory)
+		users.GET("/:id/balance-ledger", h.Admin.BalanceLedger.ListUserEntries)
+		users.POST("/:id/postpaid-statements", h.Admin.PostpaidStatement.GenerateForUser)
 		users.POST("/:id/replace-group", h.Admin.User.ReplaceGroup)
 
 		// User attribute values
@@ -256,6 +288,7 @@ func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		groups.GET("/usage-summary", h.Admin.Group.GetUsageSummary)
 		groups.GET("/capacity-summary", h.Admin.Group.GetCapacitySummary)
 		groups.PUT("/sort-order", h.Admin.Group.UpdateSortOrder)
+		groups.DELETE("/response-cache", h.Admin.Group.PurgeAllResponseCache)
 		groups.GET("/:id", h.Admin.Group.GetByID)
 		groups.POST("", h.Admin.Group.Create)
 		groups.PUT("/:id", h.Admin.Group.Update)
@@ -265,6 +298,7 @@ func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		groups.PUT("/:id/rate-multipliers", h.Admin.Group.BatchSetGroupRateMultipliers)
 		groups.DELETE("/:id/rate-multipliers", h.Admin.Group.ClearGroupRateMultipliers)
 		groups.GET("/:id/api-keys", h.Admin.Group.GetGroupAPIKeys)
+		groups.DELETE("/:id/response-cache", h.Admin.Group.PurgeResponseCache)
 	}
 }
 
@@ -406,6 +440,29 @@ func registerRedeemCodeRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	}
 }
 
+func registerPaymentRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	payments := admin.Group("/payments")
+	{
+		payments.GET("/orders", h.Admin.Payment.ListOrders)
+		payments.GET("/orders/:id", h.Admin.Payment.GetOrder)
+		payments.POST("/orders/:id/refund", h.Admin.Payment.RefundOrder)
+		payments.GET("/plan-prices", h.Admin.Payment.ListPlanPrices)
+		payments.PUT("/plan-prices/:plan_id", h.Admin.Payment.UpsertPlanPrice)
+		payments.DELETE("/plan-prices/:plan_id", h.Admin.Payment.DeletePlanPrice)
+	}
+}
+
+func registerPostpaidStatementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	statements := admin.Group("/postpaid-statements")
+	{
+		statements.GET("", h.Admin.PostpaidStatement.List)
+		statements.POST("/run", h.Admin.PostpaidStatement.Run)
+		statements.GET("/:id", h.Admin.PostpaidStatement.GetByID)
+		statements.GET("/:id/document", h.Admin.PostpaidStatement.Download)
+		statements.POST("/:id/mark-paid", h.Admin.PostpaidStatement.MarkPaid)
+	}
+}
+
 func registerPromoCodeRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	promoCodes := admin.Group("/promo-codes")
 	{
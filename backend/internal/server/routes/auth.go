@@ -78,6 +78,13 @@ func RegisterAuthRoutes(
 		settings.GET("/public", h.Setting.GetPublicSettings)
 	}
 
+	// 公开状态页（无需认证，需在配置中开启）
+	v1.GET("/status", h.Status.GetPublicStatus)
+
+	// 支付渠道异步回调（无需认证，由各渠道签名校验）
+	v1.GET("/payments/callback/:provider", h.Payment.Callback)
+	v1.POST("/payments/callback/:provider", h.Payment.Callback)
+
 	// 需要认证的当前用户信息
 	authenticated := v1.Group("")
 	authenticated.Use(gin.HandlerFunc(jwtAuth))
@@ -21,6 +21,7 @@ func RegisterGatewayRoutes(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	payloadCaptureService *service.PayloadCaptureService,
 	settingService *service.SettingService,
 	cfg *config.Config,
 	redisClient *redis.Client,
@@ -33,6 +34,8 @@ func RegisterGatewayRoutes(
 	soraBodyLimit := middleware.RequestBodyLimit(soraMaxBodySize)
 	clientRequestID := middleware.ClientRequestID()
 	opsErrorLogger := handler.OpsErrorLoggerMiddleware(opsService)
+	payloadCapture := handler.PayloadCaptureMiddleware(payloadCaptureService)
+	gatewayMetrics := handler.GatewayMetricsMiddleware(cfg.Ops.Prometheus.Enabled)
 	endpointNorm := handler.InboundEndpointMiddleware()
 	errorThrottle := middleware.GatewayErrorThrottle(redisClient, middleware.GatewayErrorThrottleConfig{
 		Enabled:      cfg.Gateway.ErrorThrottle.Enabled,
@@ -51,26 +54,16 @@ func RegisterGatewayRoutes(
 	gateway.Use(bodyLimit)
 	gateway.Use(clientRequestID)
 	gateway.Use(opsErrorLogger)
+	gateway.Use(payloadCapture)
+	gateway.Use(gatewayMetrics)
 	gateway.Use(endpointNorm)
 	gateway.Use(gin.HandlerFunc(apiKeyAuth))
 	gateway.Use(requireGroupAnthropic)
 	{
 		// /v1/messages: auto-route based on group platform
 		gateway.POST("/messages", dispatchOpenAICompatibleByGroupPlatform(h.OpenAIGateway.Messages, h.Gateway.Messages))
-		// /v1/messages/count_tokens: OpenAI groups get 404
-		gateway.POST("/messages/count_tokens", func(c *gin.Context) {
-			if getGroupPlatform(c) == service.PlatformOpenAI {
-				c.JSON(http.StatusNotFound, gin.H{
-					"type": "error",
-					"error": gin.H{
-						"type":    "not_found_error",
-						"message": "Token counting is not supported for this platform",
-					},
-				})
-				return
-			}
-			h.Gateway.CountTokens(c)
-		})
+		// /v1/messages/count_tokens: OpenAI groups get an approximate local estimate (flagged by the X-Sub2API-Token-Count-Estimated header)
+		gateway.POST("/messages/count_tokens", h.Gateway.CountTokens)
 		gateway.GET("/models", h.Gateway.Models)
 		gateway.GET("/usage", h.Gateway.Usage)
 		// OpenAI Responses API: auto-route based on group platform
@@ -79,6 +72,63 @@ func RegisterGatewayRoutes(
 		gateway.GET("/responses", h.OpenAIGateway.ResponsesWebSocket)
 		// OpenAI Chat Completions API: auto-route based on group platform
 		gateway.POST("/chat/completions", dispatchOpenAICompatibleByGroupPlatform(h.OpenAIGateway.ChatCompletions, h.Gateway.ChatCompletions))
+		// OpenAI Embeddings API: only OpenAI groups, others get 404
+		gateway.POST("/embeddings", requireOpenAIGroupPlatform("Embeddings are not supported for this platform", h.OpenAIGateway.Embeddings))
+		// OpenAI Images API: OpenAI groups forward natively, Gemini/Antigravity groups use image models
+		imagesHandler := func(c *gin.Context) {
+			switch getGroupPlatform(c) {
+			case service.PlatformOpenAI:
+				h.OpenAIGateway.Images(c)
+			case service.PlatformGemini, service.PlatformAntigravity:
+				h.Gateway.Images(c)
+			default:
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"type":    "not_found_error",
+						"message": "Images are not supported for this platform",
+					},
+				})
+			}
+		}
+		gateway.POST("/images/generations", imagesHandler)
+		gateway.POST("/images/edits", imagesHandler)
+		// OpenAI Files / Batches API: batch 由网关后台执行器以调用方 Key 重放
+		gateway.POST("/files", h.Batch.UploadFile)
+		gateway.GET("/files", h.Batch.ListFiles)
+		gateway.GET("/files/:file_id", h.Batch.GetFile)
+		gateway.GET("/files/:file_id/content", h.Batch.GetFileContent)
+		gateway.DELETE("/files/:file_id", h.Batch.DeleteFile)
+		gateway.POST("/batches", h.Batch.CreateBatch)
+		gateway.GET("/batches", h.Batch.ListBatches)
+		gateway.GET("/batches/:batch_id", h.Batch.GetBatch)
+		gateway.POST("/batches/:batch_id/cancel", h.Batch.CancelBatch)
+		// Anthropic Message Batches API（复用 batch 执行器，经 /v1/messages 后台执行）
+		gateway.POST("/messages/batches", h.Batch.CreateMessageBatch)
+		gateway.GET("/messages/batches", h.Batch.ListMessageBatches)
+		gateway.GET("/messages/batches/:batch_id", h.Batch.GetMessageBatch)
+		gateway.POST("/messages/batches/:batch_id/cancel", h.Batch.CancelMessageBatch)
+		gateway.GET("/messages/batches/:batch_id/results", h.Batch.GetMessageBatchResults)
+	}
+
+	// OpenAI Audio API（仅 OpenAI 分组）：音频上传使用独立的请求体上限
+	audioMaxBodySize := cfg.Gateway.AudioMaxBodySize
+	if audioMaxBodySize <= 0 {
+		audioMaxBodySize = cfg.Gateway.MaxBodySize
+	}
+	audio := r.Group("/v1/audio")
+	audio.Use(errorThrottle)
+	audio.Use(middleware.RequestBodyLimit(audioMaxBodySize))
+	audio.Use(clientRequestID)
+	audio.Use(opsErrorLogger)
+	audio.Use(payloadCapture)
+	audio.Use(gatewayMetrics)
+	audio.Use(endpointNorm)
+	audio.Use(gin.HandlerFunc(apiKeyAuth))
+	audio.Use(requireGroupAnthropic)
+	{
+		audio.POST("/transcriptions", requireOpenAIGroupPlatform("Audio is not supported for this platform", h.OpenAIGateway.AudioTranscriptions))
+		audio.POST("/translations", requireOpenAIGroupPlatform("Audio is not supported for this platform", h.OpenAIGateway.AudioTranscriptions))
+		audio.POST("/speech", requireOpenAIGroupPlatform("Audio is not supported for this platform", h.OpenAIGateway.AudioSpeech))
 	}
 
 	// Gemini 原生 API 兼容层（Gemini SDK/CLI 直连）
@@ -87,6 +137,8 @@ func RegisterGatewayRoutes(
 	gemini.Use(bodyLimit)
 	gemini.Use(clientRequestID)
 	gemini.Use(opsErrorLogger)
+	gemini.Use(payloadCapture)
+	gemini.Use(gatewayMetrics)
 	gemini.Use(endpointNorm)
 	gemini.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
 	gemini.Use(requireGroupGoogle)
@@ -99,11 +151,11 @@ func RegisterGatewayRoutes(
 
 	// OpenAI Responses API（不带v1前缀的别名）— auto-route based on group platform
 	responsesHandler := dispatchOpenAICompatibleByGroupPlatform(h.OpenAIGateway.Responses, h.Gateway.Responses)
-	r.POST("/responses", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.POST("/responses/*subpath", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.GET("/responses", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.OpenAIGateway.ResponsesWebSocket)
+	r.POST("/responses", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, payloadCapture, gatewayMetrics, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
+	r.POST("/responses/*subpath", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, payloadCapture, gatewayMetrics, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
+	r.GET("/responses", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, payloadCapture, gatewayMetrics, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.OpenAIGateway.ResponsesWebSocket)
 	// OpenAI Chat Completions API（不带v1前缀的别名）— auto-route based on group platform
-	r.POST("/chat/completions", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, dispatchOpenAICompatibleByGroupPlatform(h.OpenAIGateway.ChatCompletions, h.Gateway.ChatCompletions))
+	r.POST("/chat/completions", errorThrottle, bodyLimit, clientRequestID, opsErrorLogger, payloadCapture, gatewayMetrics, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, dispatchOpenAICompatibleByGroupPlatform(h.OpenAIGateway.ChatCompletions, h.Gateway.ChatCompletions))
 
 	// Antigravity 模型列表
 	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.AntigravityModels)
@@ -114,6 +166,8 @@ func RegisterGatewayRoutes(
 	antigravityV1.Use(bodyLimit)
 	antigravityV1.Use(clientRequestID)
 	antigravityV1.Use(opsErrorLogger)
+	antigravityV1.Use(payloadCapture)
+	antigravityV1.Use(gatewayMetrics)
 	antigravityV1.Use(endpointNorm)
 	antigravityV1.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1.Use(gin.HandlerFunc(apiKeyAuth))
@@ -130,6 +184,8 @@ func RegisterGatewayRoutes(
 	antigravityV1Beta.Use(bodyLimit)
 	antigravityV1Beta.Use(clientRequestID)
 	antigravityV1Beta.Use(opsErrorLogger)
+	antigravityV1Beta.Use(payloadCapture)
+	antigravityV1Beta.Use(gatewayMetrics)
 	antigravityV1Beta.Use(endpointNorm)
 	antigravityV1Beta.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1Beta.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
@@ -146,6 +202,8 @@ func RegisterGatewayRoutes(
 	soraV1.Use(soraBodyLimit)
 	soraV1.Use(clientRequestID)
 	soraV1.Use(opsErrorLogger)
+	soraV1.Use(payloadCapture)
+	soraV1.Use(gatewayMetrics)
 	soraV1.Use(endpointNorm)
 	soraV1.Use(middleware.ForcePlatform(service.PlatformSora))
 	soraV1.Use(gin.HandlerFunc(apiKeyAuth))
@@ -174,6 +232,24 @@ func getGroupPlatform(c *gin.Context) string {
 	return apiKey.Group.Platform
 }
 
+// requireOpenAIGroupPlatform 仅 OpenAI 分组可用的接口，其余平台返回 404
+func requireOpenAIGroupPlatform(message string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if getGroupPlatform(c) != service.PlatformOpenAI {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"type":    "not_found_error",
+					"message": message,
+				},
+			})
+			return
+		}
+		handler(c)
+	}
+}
+
+// dispatchOpenAICompatibleByGroupPlatform 将 OpenAI 协议请求按分组平台分流：
+// OpenAI 分组直连 OpenAI 网关，其余（Anthropic / Gemini / Antigravity）由 GatewayHandler 按账号平台转换协议后转发。
 func dispatchOpenAICompatibleByGroupPlatform(openAIHandler, gatewayHandler gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if getGroupPlatform(c) == service.PlatformOpenAI {
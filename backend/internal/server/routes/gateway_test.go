@@ -32,6 +32,7 @@ func newGatewayRoutesTestRouter() *gin.Engine {
 		nil,
 		nil,
 		nil,
+		nil,
 		&config.Config{},
 		nil,
 	)
@@ -97,3 +98,42 @@ func TestDispatchOpenAICompatibleByGroupPlatform(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireOpenAIGroupPlatform(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for platform, wantStatus := range map[string]int{
+		service.PlatformOpenAI:    http.StatusNoContent,
+		service.PlatformAnthropic: http.StatusNotFound,
+		service.PlatformGemini:    http.StatusNotFound,
+	} {
+		t.Run(platform, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", nil)
+			groupID := int64(7)
+			c.Set(string(servermiddleware.ContextKeyAPIKey), &service.APIKey{
+				GroupID: &groupID,
+				Group:   &service.Group{ID: groupID, Platform: platform, Status: service.StatusActive, Hydrated: true},
+			})
+
+			requireOpenAIGroupPlatform("Audio is not supported for this platform", func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})(c)
+			require.Equal(t, wantStatus, c.Writer.Status())
+		})
+	}
+}
+
+func TestGatewayRoutesAudioPathsAreRegistered(t *testing.T) {
+	router := newGatewayRoutesTestRouter()
+
+	for _, path := range []string{"/v1/audio/transcriptions", "/v1/audio/translations", "/v1/audio/speech"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		// 无分组 Key 被 requireOpenAIGroupPlatform 拒绝（JSON 404），而不是路由不存在
+		require.Contains(t, w.Body.String(), "Audio is not supported", "path=%s", path)
+	}
+}
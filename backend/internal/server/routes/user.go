@@ -25,6 +25,12 @@ func RegisterUserRoutes(
 			user.GET("/profile", h.User.GetProfile)
 			user.PUT("/password", h.User.ChangePassword)
 			user.PUT("", h.User.UpdateProfile)
+			user.GET("/balance-statement", h.BalanceLedger.GetStatement)
+
+			// 后付费月结账单
+			user.GET("/postpaid-statements", h.PostpaidStatement.List)
+			user.GET("/postpaid-statements/:id", h.PostpaidStatement.GetByID)
+			user.GET("/postpaid-statements/:id/document", h.PostpaidStatement.Download)
 
 			// TOTP 双因素认证
 			totp := user.Group("/totp")
@@ -36,6 +42,13 @@ func RegisterUserRoutes(
 				totp.POST("/enable", h.Totp.Enable)
 				totp.POST("/disable", h.Totp.Disable)
 			}
+
+			// 月度 SLA 报告（仅当前用户）
+			sla := user.Group("/sla")
+			{
+				sla.GET("", h.Status.GetSLAReport)
+				sla.GET("/history", h.Status.GetSLAHistory)
+			}
 		}
 
 		// API Key管理
@@ -91,6 +104,16 @@ func RegisterUserRoutes(
 			subscriptions.GET("/summary", h.Subscription.GetSummary)
 		}
 
+		// 在线支付订单
+		payments := authenticated.Group("/payments")
+		{
+			payments.GET("/options", h.Payment.GetOptions)
+			payments.GET("/orders", h.Payment.ListOrders)
+			payments.POST("/orders", h.Payment.CreateOrder)
+			payments.GET("/orders/:order_no", h.Payment.GetOrder)
+			payments.POST("/orders/:order_no/cancel", h.Payment.CancelOrder)
+		}
+
 		// 可购买的订阅计划（用户端）
 		if h.Admin.SubscriptionPlan != nil {
 			authenticated.GET("/subscription-plans", h.Admin.SubscriptionPlan.List)
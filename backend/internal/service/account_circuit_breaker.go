@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 )
 
 // AccountCircuitBreaker 进程内存级账号熔断器。
@@ -12,9 +15,14 @@ import (
 //
 // 熔断条目会在 TTL 到期后自动失效（保守默认 2 分钟），
 // 此时 outbox 早已完成 snapshot 重建，DB 中的真实状态接管调度决策。
+//
+// 启用分布式模式（EnableDistributed）后，熔断/恢复会异步写入共享存储并广播给其它实例，
+// 本地 map 作为读缓存，由 pub/sub 事件与周期性全量同步维护，IsTripped 仍只读内存。
 type AccountCircuitBreaker struct {
 	mu      sync.RWMutex
 	tripped map[int64]time.Time // account_id → 过期时间
+
+	store AccountHealthStore // nil 表示单机模式
 }
 
 // NewAccountCircuitBreaker 创建账号熔断器实例。
@@ -30,6 +38,26 @@ func NewAccountCircuitBreaker() *AccountCircuitBreaker {
 // 应远大于 outbox 轮询间隔（默认 1s），但足够小以免影响已恢复的账号。
 const defaultCircuitBreakerTTL = 2 * time.Minute
 
+// accountHealthStoreTimeout 后台访问共享存储的超时时间
+const accountHealthStoreTimeout = 2 * time.Second
+
+// EnableDistributed 切换为多实例共享模式：订阅其它实例的事件，并按 syncInterval 全量同步。
+// 需在开始处理流量前调用。
+func (cb *AccountCircuitBreaker) EnableDistributed(ctx context.Context, store AccountHealthStore, syncInterval time.Duration) {
+	if cb == nil || store == nil {
+		return
+	}
+	cb.mu.Lock()
+	cb.store = store
+	cb.mu.Unlock()
+
+	store.SubscribeTripEvents(ctx, cb.applyEvent)
+	cb.syncFromStore(ctx)
+	if syncInterval > 0 {
+		go cb.syncLoop(ctx, syncInterval)
+	}
+}
+
 // Trip 熔断指定账号（立即从调度中剔除）。
 func (cb *AccountCircuitBreaker) Trip(accountID int64) {
 	cb.TripWithTTL(accountID, defaultCircuitBreakerTTL)
@@ -40,9 +68,21 @@ func (cb *AccountCircuitBreaker) TripWithTTL(accountID int64, ttl time.Duration)
 	if accountID <= 0 || ttl <= 0 {
 		return
 	}
+	until := time.Now().Add(ttl)
 	cb.mu.Lock()
-	cb.tripped[accountID] = time.Now().Add(ttl)
+	cb.tripped[accountID] = until
+	store := cb.store
 	cb.mu.Unlock()
+
+	if store != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), accountHealthStoreTimeout)
+			defer cancel()
+			if err := store.TripAccount(ctx, accountID, until); err != nil {
+				logger.LegacyPrintf("service.account_circuit_breaker", "[AccountCircuitBreaker] trip account=%d in shared store failed: %v", accountID, err)
+			}
+		}()
+	}
 }
 
 // IsTripped 检查账号是否处于熔断状态。
@@ -69,9 +109,74 @@ func (cb *AccountCircuitBreaker) IsTripped(accountID int64) bool {
 func (cb *AccountCircuitBreaker) Reset(accountID int64) {
 	cb.mu.Lock()
 	delete(cb.tripped, accountID)
+	store := cb.store
+	cb.mu.Unlock()
+
+	if store != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), accountHealthStoreTimeout)
+			defer cancel()
+			if err := store.ResetAccount(ctx, accountID); err != nil {
+				logger.LegacyPrintf("service.account_circuit_breaker", "[AccountCircuitBreaker] reset account=%d in shared store failed: %v", accountID, err)
+			}
+		}()
+	}
+}
+
+// applyEvent 应用其它实例广播的熔断/恢复事件。
+func (cb *AccountCircuitBreaker) applyEvent(event AccountTripEvent) {
+	if event.AccountID <= 0 {
+		return
+	}
+	cb.mu.Lock()
+	if event.Until.IsZero() || time.Now().After(event.Until) {
+		delete(cb.tripped, event.AccountID)
+	} else {
+		cb.tripped[event.AccountID] = event.Until
+	}
 	cb.mu.Unlock()
 }
 
+// syncFromStore 合并共享存储中的熔断状态（取较晚的到期时间）。
+// 本地独有的条目保留至 TTL 到期，避免覆盖尚未写入存储的熔断。
+func (cb *AccountCircuitBreaker) syncFromStore(ctx context.Context) {
+	cb.mu.RLock()
+	store := cb.store
+	cb.mu.RUnlock()
+	if store == nil {
+		return
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, accountHealthStoreTimeout)
+	defer cancel()
+	remote, err := store.ListTrippedAccounts(syncCtx, time.Now())
+	if err != nil {
+		logger.LegacyPrintf("service.account_circuit_breaker", "[AccountCircuitBreaker] sync from shared store failed: %v", err)
+		return
+	}
+
+	cb.mu.Lock()
+	for id, until := range remote {
+		if current, ok := cb.tripped[id]; !ok || until.After(current) {
+			cb.tripped[id] = until
+		}
+	}
+	cb.mu.Unlock()
+}
+
+func (cb *AccountCircuitBreaker) syncLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cb.syncFromStore(ctx)
+		}
+	}
+}
+
 // cleanupLoop 定期清理过期条目，防止 map 无限增长。
 func (cb *AccountCircuitBreaker) cleanupLoop() {
 	ticker := time.NewTicker(30 * time.Second)
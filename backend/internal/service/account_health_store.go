@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// AccountHealthStore 多实例共享的账号熔断状态与健康度计数存储（Redis 实现）。
+//
+// 仅在 gateway.scheduling.account_health_mode=redis 时启用；单机部署仍使用进程内实现。
+// 热路径（IsTripped / HealthScore）只读本地缓存，存储访问全部在后台完成。
+type AccountHealthStore interface {
+	// TripAccount 写入熔断状态并广播事件
+	TripAccount(ctx context.Context, accountID int64, until time.Time) error
+	// ResetAccount 清除熔断状态并广播事件
+	ResetAccount(ctx context.Context, accountID int64) error
+	// ListTrippedAccounts 返回当前仍处于熔断状态的账号及其到期时间
+	ListTrippedAccounts(ctx context.Context, now time.Time) (map[int64]time.Time, error)
+	// SubscribeTripEvents 订阅其它实例的熔断/恢复事件（ctx 取消时退出）
+	SubscribeTripEvents(ctx context.Context, handler func(AccountTripEvent))
+
+	// AddHealthCounts 将某一分钟的成功/失败增量累加到共享时间桶
+	AddHealthCounts(ctx context.Context, minute int64, deltas map[int64]AccountHealthCounts) error
+	// GetHealthCounts 汇总 [fromMinute, toMinute] 内各账号的成功/失败计数
+	GetHealthCounts(ctx context.Context, accountIDs []int64, fromMinute, toMinute int64) (map[int64]AccountHealthCounts, error)
+}
+
+// AccountTripEvent 熔断状态变更事件；Until 为零值表示恢复
+type AccountTripEvent struct {
+	AccountID int64
+	Until     time.Time
+}
+
+// AccountHealthCounts 成功/失败计数
+type AccountHealthCounts struct {
+	Success  int64
+	Failures int64
+}
@@ -0,0 +1,176 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAccountHealthStore 模拟 Redis：共享熔断集合、分钟桶计数与事件广播
+type memoryAccountHealthStore struct {
+	mu          sync.Mutex
+	tripped     map[int64]time.Time
+	counts      map[int64]map[int64]AccountHealthCounts
+	subscribers []func(AccountTripEvent)
+}
+
+func newMemoryAccountHealthStore() *memoryAccountHealthStore {
+	return &memoryAccountHealthStore{
+		tripped: make(map[int64]time.Time),
+		counts:  make(map[int64]map[int64]AccountHealthCounts),
+	}
+}
+
+func (m *memoryAccountHealthStore) publish(event AccountTripEvent) {
+	m.mu.Lock()
+	subs := append([]func(AccountTripEvent){}, m.subscribers...)
+	m.mu.Unlock()
+	for _, fn := range subs {
+		fn(event)
+	}
+}
+
+func (m *memoryAccountHealthStore) TripAccount(ctx context.Context, accountID int64, until time.Time) error {
+	m.mu.Lock()
+	m.tripped[accountID] = until
+	m.mu.Unlock()
+	m.publish(AccountTripEvent{AccountID: accountID, Until: until})
+	return nil
+}
+
+func (m *memoryAccountHealthStore) ResetAccount(ctx context.Context, accountID int64) error {
+	m.mu.Lock()
+	delete(m.tripped, accountID)
+	m.mu.Unlock()
+	m.publish(AccountTripEvent{AccountID: accountID})
+	return nil
+}
+
+func (m *memoryAccountHealthStore) ListTrippedAccounts(ctx context.Context, now time.Time) (map[int64]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[int64]time.Time)
+	for id, until := range m.tripped {
+		if until.After(now) {
+			out[id] = until
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryAccountHealthStore) SubscribeTripEvents(ctx context.Context, handler func(AccountTripEvent)) {
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, handler)
+	m.mu.Unlock()
+}
+
+func (m *memoryAccountHealthStore) AddHealthCounts(ctx context.Context, minute int64, deltas map[int64]AccountHealthCounts) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.counts[minute]
+	if bucket == nil {
+		bucket = make(map[int64]AccountHealthCounts)
+		m.counts[minute] = bucket
+	}
+	for id, d := range deltas {
+		c := bucket[id]
+		c.Success += d.Success
+		c.Failures += d.Failures
+		bucket[id] = c
+	}
+	return nil
+}
+
+func (m *memoryAccountHealthStore) GetHealthCounts(ctx context.Context, accountIDs []int64, fromMinute, toMinute int64) (map[int64]AccountHealthCounts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[int64]AccountHealthCounts)
+	for minute := fromMinute; minute <= toMinute; minute++ {
+		for _, id := range accountIDs {
+			c := out[id]
+			c.Success += m.counts[minute][id].Success
+			c.Failures += m.counts[minute][id].Failures
+			out[id] = c
+		}
+	}
+	return out, nil
+}
+
+func TestAccountCircuitBreaker_DistributedTripAndReset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryAccountHealthStore()
+
+	a := NewAccountCircuitBreaker()
+	b := NewAccountCircuitBreaker()
+	a.EnableDistributed(ctx, store, 0)
+	b.EnableDistributed(ctx, store, 0)
+
+	a.Trip(1)
+	require.True(t, a.IsTripped(1))
+	require.Eventually(t, func() bool { return b.IsTripped(1) }, 2*time.Second, 10*time.Millisecond)
+
+	b.Reset(1)
+	require.False(t, b.IsTripped(1))
+	require.Eventually(t, func() bool { return !a.IsTripped(1) }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestAccountCircuitBreaker_DistributedInitialSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryAccountHealthStore()
+	store.tripped[5] = time.Now().Add(time.Minute)
+	store.tripped[6] = time.Now().Add(-time.Minute)
+
+	cb := NewAccountCircuitBreaker()
+	cb.EnableDistributed(ctx, store, 0)
+	require.True(t, cb.IsTripped(5))
+	require.False(t, cb.IsTripped(6))
+}
+
+func TestAccountCircuitBreaker_ExpiredEventIgnored(t *testing.T) {
+	cb := NewAccountCircuitBreaker()
+	cb.applyEvent(AccountTripEvent{AccountID: 3, Until: time.Now().Add(-time.Second)})
+	require.False(t, cb.IsTripped(3))
+	cb.applyEvent(AccountTripEvent{AccountID: 3, Until: time.Now().Add(time.Minute)})
+	require.True(t, cb.IsTripped(3))
+	cb.applyEvent(AccountTripEvent{AccountID: 3})
+	require.False(t, cb.IsTripped(3))
+}
+
+func TestAccountHealthTracker_DistributedSharesCounts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryAccountHealthStore()
+
+	a := NewAccountHealthTracker()
+	b := NewAccountHealthTracker()
+	a.EnableDistributed(ctx, store)
+	b.EnableDistributed(ctx, store)
+
+	for i := 0; i < 10; i++ {
+		a.RecordFailure(1)
+	}
+	require.Equal(t, 0, a.HealthScore(1))
+	// 尚无集群计数缓存时回退到本地计数
+	require.Equal(t, 100, b.HealthScore(1))
+
+	require.Eventually(t, func() bool { return b.HealthScore(1) == 0 }, 5*time.Second, 50*time.Millisecond)
+
+	// 集群计数 + 本地未写入增量
+	for i := 0; i < 10; i++ {
+		b.RecordSuccess(1)
+	}
+	require.Equal(t, 50, b.HealthScore(1))
+}
+
+func TestHealthScoreFromCounts(t *testing.T) {
+	require.Equal(t, 100, healthScoreFromCounts(0, 0, 0))
+	require.Equal(t, 100, healthScoreFromCounts(0, 2, 3))
+	require.Equal(t, 75, healthScoreFromCounts(3, 1, 3))
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 )
 
 // AccountHealthTracker 基于滑动时间窗口的账号健康度追踪器。
@@ -16,9 +19,38 @@ import (
 //   - 低开销：只用原子操作 + 分钟级时间桶，无锁竞争
 //   - 自愈性：窗口自动滑动，历史错误自然过期
 //   - 区分度：仅在有统计意义时（>=3 次请求）才降权
+//
+// 启用分布式模式（EnableDistributed）后，计数增量按 accountHealthFlushInterval 批量写入共享存储，
+// HealthScore 优先使用短期缓存的集群汇总计数（叠加尚未写入的本地增量），缓存缺失时回退到本地计数。
 type AccountHealthTracker struct {
 	mu      sync.RWMutex
 	buckets map[int64]*accountHealthBuckets
+
+	dist atomic.Pointer[distributedHealthState]
+}
+
+const (
+	// accountHealthFlushInterval 分布式模式下本地增量批量写入共享存储的周期
+	accountHealthFlushInterval = time.Second
+	// accountHealthRemoteCacheTTL 集群汇总计数的本地缓存时长
+	accountHealthRemoteCacheTTL = 2 * time.Second
+)
+
+// distributedHealthState 分布式模式下的待写入增量与集群计数缓存
+type distributedHealthState struct {
+	store AccountHealthStore
+
+	pendingMu sync.Mutex
+	pending   map[int64]map[int64]AccountHealthCounts // minute → account_id → 增量
+
+	remoteMu sync.RWMutex
+	remote   map[int64]remoteHealthEntry
+	wanted   map[int64]struct{} // 需要刷新集群计数的账号
+}
+
+type remoteHealthEntry struct {
+	counts    AccountHealthCounts
+	fetchedAt time.Time
 }
 
 const (
@@ -47,11 +79,31 @@ func NewAccountHealthTracker() *AccountHealthTracker {
 	}
 }
 
+// EnableDistributed 切换为多实例共享模式，后台周期性写入增量并刷新集群计数。
+func (t *AccountHealthTracker) EnableDistributed(ctx context.Context, store AccountHealthStore) {
+	if t == nil || store == nil {
+		return
+	}
+	d := &distributedHealthState{
+		store:   store,
+		pending: make(map[int64]map[int64]AccountHealthCounts),
+		remote:  make(map[int64]remoteHealthEntry),
+		wanted:  make(map[int64]struct{}),
+	}
+	if !t.dist.CompareAndSwap(nil, d) {
+		return
+	}
+	go d.loop(ctx)
+}
+
 // RecordSuccess 记录一次成功请求。
 func (t *AccountHealthTracker) RecordSuccess(accountID int64) {
 	b := t.getBuckets(accountID)
 	bucket := b.currentBucket()
 	bucket.success.Add(1)
+	if d := t.dist.Load(); d != nil {
+		d.addPending(accountID, AccountHealthCounts{Success: 1})
+	}
 }
 
 // RecordFailure 记录一次失败请求。
@@ -59,12 +111,25 @@ func (t *AccountHealthTracker) RecordFailure(accountID int64) {
 	b := t.getBuckets(accountID)
 	bucket := b.currentBucket()
 	bucket.failures.Add(1)
+	if d := t.dist.Load(); d != nil {
+		d.addPending(accountID, AccountHealthCounts{Failures: 1})
+	}
 }
 
 // HealthScore 返回账号健康分数（0-100）。
 // 100 = 完全健康或数据不足（不惩罚），0 = 窗口内全部失败。
 // minSamples 为 0 时使用默认值 healthMinSamples。
 func (t *AccountHealthTracker) HealthScore(accountID int64, minSamples ...int) int {
+	effectiveMinSamples := int32(healthMinSamples)
+	if len(minSamples) > 0 && minSamples[0] > 0 {
+		effectiveMinSamples = int32(minSamples[0])
+	}
+	if d := t.dist.Load(); d != nil {
+		if counts, ok := d.clusterCounts(accountID); ok {
+			return healthScoreFromCounts(counts.Success, counts.Failures, int64(effectiveMinSamples))
+		}
+	}
+
 	t.mu.RLock()
 	b, ok := t.buckets[accountID]
 	t.mu.RUnlock()
@@ -72,11 +137,6 @@ func (t *AccountHealthTracker) HealthScore(accountID int64, minSamples ...int) i
 		return 100
 	}
 
-	effectiveMinSamples := int32(healthMinSamples)
-	if len(minSamples) > 0 && minSamples[0] > 0 {
-		effectiveMinSamples = int32(minSamples[0])
-	}
-
 	now := currentMinute()
 	var totalSuccess, totalFailures int32
 	for i := range b.minutes {
@@ -89,13 +149,16 @@ func (t *AccountHealthTracker) HealthScore(accountID int64, minSamples ...int) i
 		totalFailures += m.failures.Load()
 	}
 
-	total := totalSuccess + totalFailures
-	if total < effectiveMinSamples {
+	return healthScoreFromCounts(int64(totalSuccess), int64(totalFailures), int64(effectiveMinSamples))
+}
+
+func healthScoreFromCounts(success, failures, minSamples int64) int {
+	total := success + failures
+	if total <= 0 || total < minSamples {
 		return 100 // 样本不足，视为健康
 	}
-
 	// 成功率 * 100
-	return int(totalSuccess * 100 / total)
+	return int(success * 100 / total)
 }
 
 // getBuckets 获取或创建账号的时间桶
@@ -135,3 +198,129 @@ func (b *accountHealthBuckets) currentBucket() *minuteBucket {
 func currentMinute() int64 {
 	return time.Now().Unix() / 60
 }
+
+func (d *distributedHealthState) addPending(accountID int64, delta AccountHealthCounts) {
+	if accountID <= 0 {
+		return
+	}
+	minute := currentMinute()
+	d.pendingMu.Lock()
+	byAccount := d.pending[minute]
+	if byAccount == nil {
+		byAccount = make(map[int64]AccountHealthCounts)
+		d.pending[minute] = byAccount
+	}
+	c := byAccount[accountID]
+	c.Success += delta.Success
+	c.Failures += delta.Failures
+	byAccount[accountID] = c
+	d.pendingMu.Unlock()
+}
+
+// clusterCounts 返回窗口内的集群计数（缓存的远端汇总 + 尚未写入的本地增量）。
+// 缓存缺失时登记刷新并返回 false，由调用方回退到本地计数；缓存过期时继续使用旧值并登记刷新。
+func (d *distributedHealthState) clusterCounts(accountID int64) (AccountHealthCounts, bool) {
+	d.remoteMu.RLock()
+	entry, ok := d.remote[accountID]
+	_, queued := d.wanted[accountID]
+	d.remoteMu.RUnlock()
+
+	if (!ok || time.Since(entry.fetchedAt) > accountHealthRemoteCacheTTL) && !queued {
+		d.remoteMu.Lock()
+		d.wanted[accountID] = struct{}{}
+		d.remoteMu.Unlock()
+	}
+	if !ok {
+		return AccountHealthCounts{}, false
+	}
+
+	counts := entry.counts
+	from := currentMinute() - healthWindowMinutes + 1
+	d.pendingMu.Lock()
+	for minute, byAccount := range d.pending {
+		if minute < from {
+			continue
+		}
+		c := byAccount[accountID]
+		counts.Success += c.Success
+		counts.Failures += c.Failures
+	}
+	d.pendingMu.Unlock()
+	return counts, true
+}
+
+func (d *distributedHealthState) loop(ctx context.Context) {
+	flushTicker := time.NewTicker(accountHealthFlushInterval)
+	defer flushTicker.Stop()
+	refreshTicker := time.NewTicker(accountHealthRemoteCacheTTL / 2)
+	defer refreshTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			d.flush(context.Background())
+			return
+		case <-flushTicker.C:
+			d.flush(ctx)
+		case <-refreshTicker.C:
+			d.refresh(ctx)
+		}
+	}
+}
+
+// flush 将待写入增量写入共享存储；失败的增量丢弃（本地计数仍然有效）。
+func (d *distributedHealthState) flush(ctx context.Context) {
+	d.pendingMu.Lock()
+	if len(d.pending) == 0 {
+		d.pendingMu.Unlock()
+		return
+	}
+	pending := d.pending
+	d.pending = make(map[int64]map[int64]AccountHealthCounts)
+	d.pendingMu.Unlock()
+
+	flushCtx, cancel := context.WithTimeout(ctx, accountHealthStoreTimeout)
+	defer cancel()
+	for minute, deltas := range pending {
+		if err := d.store.AddHealthCounts(flushCtx, minute, deltas); err != nil {
+			logger.LegacyPrintf("service.account_health_tracker", "[AccountHealthTracker] flush health counts failed: %v", err)
+			return
+		}
+	}
+}
+
+// refresh 拉取被查询过的账号的集群计数。
+func (d *distributedHealthState) refresh(ctx context.Context) {
+	d.remoteMu.Lock()
+	if len(d.wanted) == 0 {
+		d.remoteMu.Unlock()
+		return
+	}
+	ids := make([]int64, 0, len(d.wanted))
+	for id := range d.wanted {
+		ids = append(ids, id)
+	}
+	d.wanted = make(map[int64]struct{})
+	d.remoteMu.Unlock()
+
+	now := currentMinute()
+	refreshCtx, cancel := context.WithTimeout(ctx, accountHealthStoreTimeout)
+	defer cancel()
+	counts, err := d.store.GetHealthCounts(refreshCtx, ids, now-healthWindowMinutes+1, now)
+	if err != nil {
+		logger.LegacyPrintf("service.account_health_tracker", "[AccountHealthTracker] refresh health counts failed: %v", err)
+		return
+	}
+
+	fetchedAt := time.Now()
+	d.remoteMu.Lock()
+	for _, id := range ids {
+		d.remote[id] = remoteHealthEntry{counts: counts[id], fetchedAt: fetchedAt}
+	}
+	// 清理长期未被查询的缓存，防止无限增长
+	for id, entry := range d.remote {
+		if fetchedAt.Sub(entry.fetchedAt) > healthWindowMinutes*time.Minute {
+			delete(d.remote, id)
+		}
+	}
+	d.remoteMu.Unlock()
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"math"
+	mathrand "math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 账号调度策略（分组级配置，空字符串表示使用网关默认策略）
+const (
+	// AccountScheduleStrategyPriorityLRU 优先级 → 负载率 → 最久未用（Anthropic/Gemini 网关默认策略）
+	AccountScheduleStrategyPriorityLRU = "priority_lru"
+	// AccountScheduleStrategyLeastLoad 按负载率（已按账号并发容量加权）→ 排队数 → 最久未用，不区分优先级
+	AccountScheduleStrategyLeastLoad = "least_load"
+	// AccountScheduleStrategyEWMALatency 按首 Token 耗时 EWMA（按错误率惩罚）择优
+	AccountScheduleStrategyEWMALatency = "ewma_latency"
+	// AccountScheduleStrategyCostAware 优先选择账号计费倍率（RateMultiplier）最低的账号
+	AccountScheduleStrategyCostAware = "cost_aware"
+)
+
+const (
+	accountScheduleLayerModelRouting  = "model_routing"
+	accountScheduleLayerSessionSticky = "session_hash"
+	accountScheduleLayerLoadBalance   = "load_balance"
+	accountScheduleLayerFallbackWait  = "fallback_wait"
+	accountScheduleLayerLegacy        = "legacy"
+)
+
+// IsValidAccountScheduleStrategy 判断调度策略是否合法（空字符串表示默认策略，视为合法）
+func IsValidAccountScheduleStrategy(strategy string) bool {
+	switch strategy {
+	case "", AccountScheduleStrategyPriorityLRU, AccountScheduleStrategyLeastLoad,
+		AccountScheduleStrategyEWMALatency, AccountScheduleStrategyCostAware:
+		return true
+	default:
+		return false
+	}
+}
+
+// ErrInvalidAccountScheduleStrategy 分组调度策略取值非法
+var ErrInvalidAccountScheduleStrategy = infraerrors.BadRequest("INVALID_SCHEDULE_STRATEGY", "schedule_strategy must be one of priority_lru, least_load, ewma_latency, cost_aware")
+
+// normalizeAccountScheduleStrategy 去除首尾空白并统一小写
+func normalizeAccountScheduleStrategy(strategy string) string {
+	return strings.ToLower(strings.TrimSpace(strategy))
+}
+
+// resolveAccountScheduleStrategy 返回分组生效的调度策略，未配置或取值非法时使用网关默认策略
+func resolveAccountScheduleStrategy(group *Group, defaultStrategy string) string {
+	if group != nil {
+		strategy := normalizeAccountScheduleStrategy(group.ScheduleStrategy)
+		if strategy != "" && IsValidAccountScheduleStrategy(strategy) {
+			return strategy
+		}
+	}
+	return defaultStrategy
+}
+
+// AccountScheduleRequest 账号调度请求
+type AccountScheduleRequest struct {
+	GroupID         *int64
+	SessionHash     string
+	StickyAccountID int64
+	RequestedModel  string
+	ExcludedIDs     map[int64]struct{}
+
+	// 以下字段仅 OpenAI 网关使用
+	PreviousResponseID string
+	RequiredTransport  OpenAIUpstreamTransport
+}
+
+// AccountScheduleDecision 单次调度决策信息
+type AccountScheduleDecision struct {
+	Layer               string
+	Strategy            string
+	StickyPreviousHit   bool
+	StickySessionHit    bool
+	CandidateCount      int
+	TopK                int
+	LatencyMs           int64
+	LoadSkew            float64
+	SelectedAccountID   int64
+	SelectedAccountType string
+}
+
+// startAccountSelectSpan 为一次账号选择尝试创建 span（failover 时每次重选各自一个 span）
+func startAccountSelectSpan(ctx context.Context, name string, groupID *int64, requestedModel string, excludedCount int) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("model.requested", requestedModel),
+		attribute.Int("scheduler.excluded_count", excludedCount),
+	}
+	if groupID != nil {
+		attrs = append(attrs, attribute.Int64("group.id", *groupID))
+	}
+	return tracing.Start(ctx, name, attrs...)
+}
+
+// endAccountSelectSpan 记录调度决策并结束 span
+func endAccountSelectSpan(span trace.Span, decision AccountScheduleDecision, err error) {
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("scheduler.layer", decision.Layer),
+			attribute.String("scheduler.strategy", decision.Strategy),
+			attribute.Bool("scheduler.sticky_hit", decision.StickySessionHit || decision.StickyPreviousHit),
+			attribute.Int("scheduler.candidate_count", decision.CandidateCount),
+			attribute.Int64("account.id", decision.SelectedAccountID),
+			attribute.String("account.type", decision.SelectedAccountType),
+		)
+	}
+	tracing.EndSpan(span, err)
+}
+
+// endSelectedAccountSpan 记录直接选择（非调度器路径）的账号并结束 span
+func endSelectedAccountSpan(span trace.Span, account *Account, err error) {
+	if account != nil && span.IsRecording() {
+		span.SetAttributes(
+			attribute.Int64("account.id", account.ID),
+			attribute.String("account.platform", account.Platform),
+			attribute.String("account.type", account.Type),
+		)
+	}
+	tracing.EndSpan(span, err)
+}
+
+// AccountSchedulerMetricsSnapshot 调度器决策指标快照
+type AccountSchedulerMetricsSnapshot struct {
+	SelectTotal              int64
+	StickyPreviousHitTotal   int64
+	StickySessionHitTotal    int64
+	LoadBalanceSelectTotal   int64
+	AccountSwitchTotal       int64
+	SchedulerLatencyMsTotal  int64
+	SchedulerLatencyMsAvg    float64
+	StickyHitRatio           float64
+	AccountSwitchRate        float64
+	LoadSkewAvg              float64
+	RuntimeStatsAccountCount int
+	// StrategySelectTotals 负载均衡层按策略统计的选择次数
+	StrategySelectTotals map[string]int64
+}
+
+// AccountScheduler 账号调度器，OpenAI 与 Anthropic/Gemini 网关共用。
+type AccountScheduler interface {
+	Select(ctx context.Context, req AccountScheduleRequest) (*AccountSelectionResult, AccountScheduleDecision, error)
+	ReportResult(accountID int64, success bool, firstTokenMs *int)
+	ReportSwitch()
+	SnapshotMetrics() AccountSchedulerMetricsSnapshot
+}
+
+type accountSchedulerMetrics struct {
+	selectTotal            atomic.Int64
+	stickyPreviousHitTotal atomic.Int64
+	stickySessionHitTotal  atomic.Int64
+	loadBalanceSelectTotal atomic.Int64
+	accountSwitchTotal     atomic.Int64
+	latencyMsTotal         atomic.Int64
+	loadSkewMilliTotal     atomic.Int64
+	strategySelectTotals   sync.Map // key: strategy, value: *atomic.Int64
+}
+
+func (m *accountSchedulerMetrics) recordSelect(decision AccountScheduleDecision) {
+	if m == nil {
+		return
+	}
+	m.selectTotal.Add(1)
+	m.latencyMsTotal.Add(decision.LatencyMs)
+	m.loadSkewMilliTotal.Add(int64(math.Round(decision.LoadSkew * 1000)))
+	if decision.StickyPreviousHit {
+		m.stickyPreviousHitTotal.Add(1)
+	}
+	if decision.StickySessionHit {
+		m.stickySessionHitTotal.Add(1)
+	}
+	if decision.Layer == accountScheduleLayerLoadBalance {
+		m.loadBalanceSelectTotal.Add(1)
+		if decision.Strategy != "" {
+			counter, _ := m.strategySelectTotals.LoadOrStore(decision.Strategy, &atomic.Int64{})
+			counter.(*atomic.Int64).Add(1)
+		}
+	}
+}
+
+func (m *accountSchedulerMetrics) recordSwitch() {
+	if m == nil {
+		return
+	}
+	m.accountSwitchTotal.Add(1)
+}
+
+func (m *accountSchedulerMetrics) snapshot(stats *accountRuntimeStats) AccountSchedulerMetricsSnapshot {
+	if m == nil {
+		return AccountSchedulerMetricsSnapshot{}
+	}
+
+	selectTotal := m.selectTotal.Load()
+	prevHit := m.stickyPreviousHitTotal.Load()
+	sessionHit := m.stickySessionHitTotal.Load()
+	switchTotal := m.accountSwitchTotal.Load()
+	latencyTotal := m.latencyMsTotal.Load()
+	loadSkewTotal := m.loadSkewMilliTotal.Load()
+
+	snapshot := AccountSchedulerMetricsSnapshot{
+		SelectTotal:              selectTotal,
+		StickyPreviousHitTotal:   prevHit,
+		StickySessionHitTotal:    sessionHit,
+		LoadBalanceSelectTotal:   m.loadBalanceSelectTotal.Load(),
+		AccountSwitchTotal:       switchTotal,
+		SchedulerLatencyMsTotal:  latencyTotal,
+		RuntimeStatsAccountCount: stats.size(),
+	}
+	m.strategySelectTotals.Range(func(key, value any) bool {
+		if snapshot.StrategySelectTotals == nil {
+			snapshot.StrategySelectTotals = make(map[string]int64)
+		}
+		snapshot.StrategySelectTotals[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	if selectTotal > 0 {
+		snapshot.SchedulerLatencyMsAvg = float64(latencyTotal) / float64(selectTotal)
+		snapshot.StickyHitRatio = float64(prevHit+sessionHit) / float64(selectTotal)
+		snapshot.AccountSwitchRate = float64(switchTotal) / float64(selectTotal)
+		snapshot.LoadSkewAvg = float64(loadSkewTotal) / 1000 / float64(selectTotal)
+	}
+	return snapshot
+}
+
+// accountRuntimeStats 账号运行时统计（错误率与首 Token 耗时的 EWMA），仅保存在本实例内存中。
+type accountRuntimeStats struct {
+	accounts     sync.Map
+	accountCount atomic.Int64
+}
+
+type accountRuntimeStat struct {
+	errorRateEWMABits atomic.Uint64
+	ttftEWMABits      atomic.Uint64
+}
+
+func newAccountRuntimeStats() *accountRuntimeStats {
+	return &accountRuntimeStats{}
+}
+
+func (s *accountRuntimeStats) loadOrCreate(accountID int64) *accountRuntimeStat {
+	if value, ok := s.accounts.Load(accountID); ok {
+		stat, _ := value.(*accountRuntimeStat)
+		if stat != nil {
+			return stat
+		}
+	}
+
+	stat := &accountRuntimeStat{}
+	stat.ttftEWMABits.Store(math.Float64bits(math.NaN()))
+	actual, loaded := s.accounts.LoadOrStore(accountID, stat)
+	if !loaded {
+		s.accountCount.Add(1)
+		return stat
+	}
+	existing, _ := actual.(*accountRuntimeStat)
+	if existing != nil {
+		return existing
+	}
+	return stat
+}
+
+func updateEWMAAtomic(target *atomic.Uint64, sample float64, alpha float64) {
+	for {
+		oldBits := target.Load()
+		oldValue := math.Float64frombits(oldBits)
+		newValue := alpha*sample + (1-alpha)*oldValue
+		if target.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
+			return
+		}
+	}
+}
+
+func (s *accountRuntimeStats) report(accountID int64, success bool, firstTokenMs *int) {
+	if s == nil || accountID <= 0 {
+		return
+	}
+	const alpha = 0.2
+	stat := s.loadOrCreate(accountID)
+
+	errorSample := 1.0
+	if success {
+		errorSample = 0.0
+	}
+	updateEWMAAtomic(&stat.errorRateEWMABits, errorSample, alpha)
+
+	if firstTokenMs != nil && *firstTokenMs > 0 {
+		ttft := float64(*firstTokenMs)
+		ttftBits := math.Float64bits(ttft)
+		for {
+			oldBits := stat.ttftEWMABits.Load()
+			oldValue := math.Float64frombits(oldBits)
+			if math.IsNaN(oldValue) {
+				if stat.ttftEWMABits.CompareAndSwap(oldBits, ttftBits) {
+					break
+				}
+				continue
+			}
+			newValue := alpha*ttft + (1-alpha)*oldValue
+			if stat.ttftEWMABits.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
+				break
+			}
+		}
+	}
+}
+
+func (s *accountRuntimeStats) snapshot(accountID int64) (errorRate float64, ttft float64, hasTTFT bool) {
+	if s == nil || accountID <= 0 {
+		return 0, 0, false
+	}
+	value, ok := s.accounts.Load(accountID)
+	if !ok {
+		return 0, 0, false
+	}
+	stat, _ := value.(*accountRuntimeStat)
+	if stat == nil {
+		return 0, 0, false
+	}
+	errorRate = clamp01(math.Float64frombits(stat.errorRateEWMABits.Load()))
+	ttftValue := math.Float64frombits(stat.ttftEWMABits.Load())
+	if math.IsNaN(ttftValue) {
+		return errorRate, 0, false
+	}
+	return errorRate, ttftValue, true
+}
+
+func (s *accountRuntimeStats) size() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.accountCount.Load())
+}
+
+// accountScheduleCandidate 策略排序使用的候选账号
+type accountScheduleCandidate struct {
+	account   *Account
+	loadInfo  *AccountLoadInfo
+	errorRate float64
+	ttft      float64
+	hasTTFT   bool
+}
+
+// orderAccountScheduleCandidates 按调度策略对候选账号原地排序，排序键相同的账号组内随机打散以避免热点。
+func orderAccountScheduleCandidates(strategy string, candidates []accountScheduleCandidate) {
+	if len(candidates) <= 1 {
+		return
+	}
+
+	// ewma_latency：无样本的账号按已有样本均值估算，避免新账号长期饥饿或独占流量
+	latency := make(map[int64]float64, len(candidates))
+	if strategy == AccountScheduleStrategyEWMALatency {
+		sum, count := 0.0, 0
+		for _, c := range candidates {
+			if c.hasTTFT {
+				sum += c.ttft
+				count++
+			}
+		}
+		mean := 0.0
+		if count > 0 {
+			mean = sum / float64(count)
+		}
+		for _, c := range candidates {
+			ttft := mean
+			if c.hasTTFT {
+				ttft = c.ttft
+			}
+			latency[c.account.ID] = ttft / math.Max(1-clamp01(c.errorRate), 0.05)
+		}
+	}
+
+	compare := func(a, b accountScheduleCandidate) int {
+		switch strategy {
+		case AccountScheduleStrategyLeastLoad:
+			if d := a.loadInfo.LoadRate - b.loadInfo.LoadRate; d != 0 {
+				return d
+			}
+			if d := a.loadInfo.WaitingCount - b.loadInfo.WaitingCount; d != 0 {
+				return d
+			}
+		case AccountScheduleStrategyEWMALatency:
+			if d := compareFloat64(latency[a.account.ID], latency[b.account.ID]); d != 0 {
+				return d
+			}
+			if d := a.loadInfo.LoadRate - b.loadInfo.LoadRate; d != 0 {
+				return d
+			}
+		case AccountScheduleStrategyCostAware:
+			if d := compareFloat64(a.account.BillingRateMultiplier(), b.account.BillingRateMultiplier()); d != 0 {
+				return d
+			}
+			if d := a.account.Priority - b.account.Priority; d != 0 {
+				return d
+			}
+			if d := a.loadInfo.LoadRate - b.loadInfo.LoadRate; d != 0 {
+				return d
+			}
+		default:
+			if d := a.account.Priority - b.account.Priority; d != 0 {
+				return d
+			}
+			if d := a.loadInfo.LoadRate - b.loadInfo.LoadRate; d != 0 {
+				return d
+			}
+		}
+		return compareLastUsedAt(a.account.LastUsedAt, b.account.LastUsedAt)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return compare(candidates[i], candidates[j]) < 0
+	})
+	i := 0
+	for i < len(candidates) {
+		j := i + 1
+		for j < len(candidates) && compare(candidates[i], candidates[j]) == 0 {
+			j++
+		}
+		if j-i > 1 {
+			mathrand.Shuffle(j-i, func(a, b int) {
+				candidates[i+a], candidates[i+b] = candidates[i+b], candidates[i+a]
+			})
+		}
+		i = j
+	}
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareLastUsedAt 最久未用优先（nil 视为最早），精度与 sameLastUsedAt 一致为秒
+func compareLastUsedAt(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return compareFloat64(float64(a.Unix()), float64(b.Unix()))
+	}
+}
@@ -0,0 +1,136 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduleCandidateIDs(candidates []accountScheduleCandidate) []int64 {
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.account.ID)
+	}
+	return ids
+}
+
+func TestOrderAccountScheduleCandidates(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+	cheap, pricey := 0.5, 2.0
+	build := func() []accountScheduleCandidate {
+		return []accountScheduleCandidate{
+			{account: &Account{ID: 1, Priority: 1, LastUsedAt: &newer, RateMultiplier: &pricey}, loadInfo: &AccountLoadInfo{LoadRate: 60}, ttft: 900, hasTTFT: true},
+			{account: &Account{ID: 2, Priority: 5, LastUsedAt: &older, RateMultiplier: &cheap}, loadInfo: &AccountLoadInfo{LoadRate: 10}, ttft: 300, hasTTFT: true, errorRate: 0.8},
+			{account: &Account{ID: 3, Priority: 3, LastUsedAt: &older}, loadInfo: &AccountLoadInfo{LoadRate: 30, WaitingCount: 2}, ttft: 400, hasTTFT: true},
+		}
+	}
+
+	cases := []struct {
+		strategy string
+		want     []int64
+	}{
+		{AccountScheduleStrategyPriorityLRU, []int64{1, 3, 2}},
+		{AccountScheduleStrategyLeastLoad, []int64{2, 3, 1}},
+		// 账号 2 的 TTFT 最低，但 80% 错误率惩罚后 300/0.2=1500ms
+		{AccountScheduleStrategyEWMALatency, []int64{3, 1, 2}},
+		{AccountScheduleStrategyCostAware, []int64{2, 3, 1}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			candidates := build()
+			orderAccountScheduleCandidates(tc.strategy, candidates)
+			require.Equal(t, tc.want, scheduleCandidateIDs(candidates))
+		})
+	}
+}
+
+func TestOrderAccountScheduleCandidates_EWMALatencyWithoutSamples(t *testing.T) {
+	candidates := []accountScheduleCandidate{
+		{account: &Account{ID: 1}, loadInfo: &AccountLoadInfo{LoadRate: 50}, ttft: 800, hasTTFT: true},
+		{account: &Account{ID: 2}, loadInfo: &AccountLoadInfo{LoadRate: 50}},
+		{account: &Account{ID: 3}, loadInfo: &AccountLoadInfo{LoadRate: 50}, ttft: 200, hasTTFT: true},
+	}
+	orderAccountScheduleCandidates(AccountScheduleStrategyEWMALatency, candidates)
+	// 无样本账号按均值 500ms 估算，排在两者之间
+	require.Equal(t, []int64{3, 2, 1}, scheduleCandidateIDs(candidates))
+}
+
+func TestResolveAccountScheduleStrategy(t *testing.T) {
+	require.Equal(t, AccountScheduleStrategyPriorityLRU, resolveAccountScheduleStrategy(nil, AccountScheduleStrategyPriorityLRU))
+	require.Equal(t, AccountScheduleStrategyPriorityLRU, resolveAccountScheduleStrategy(&Group{}, AccountScheduleStrategyPriorityLRU))
+	require.Equal(t, AccountScheduleStrategyCostAware, resolveAccountScheduleStrategy(&Group{ScheduleStrategy: " Cost_Aware "}, AccountScheduleStrategyPriorityLRU))
+	require.Equal(t, openAIAccountScheduleStrategyWeightedScore, resolveAccountScheduleStrategy(&Group{ScheduleStrategy: "unknown"}, openAIAccountScheduleStrategyWeightedScore))
+
+	require.True(t, IsValidAccountScheduleStrategy(""))
+	require.True(t, IsValidAccountScheduleStrategy(AccountScheduleStrategyEWMALatency))
+	require.False(t, IsValidAccountScheduleStrategy("round_robin"))
+}
+
+func TestGatewayAccountScheduler_GroupStrategyAndMetrics(t *testing.T) {
+	cheap, pricey := 0.3, 1.5
+	accounts := []Account{
+		{ID: 1, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 5, Priority: 1, RateMultiplier: &pricey},
+		{ID: 2, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 5, Priority: 9, RateMultiplier: &cheap},
+	}
+	cfg := &config.Config{
+		RunMode: config.RunModeStandard,
+		Gateway: config.GatewayConfig{
+			Scheduling: config.GatewaySchedulingConfig{
+				LoadBatchEnabled:    true,
+				FallbackWaitTimeout: time.Second,
+				FallbackMaxWaiting:  10,
+			},
+		},
+	}
+	newSvc := func() *GatewayService {
+		return &GatewayService{
+			accountRepo:        stubOpenAIAccountRepo{accounts: accounts},
+			cfg:                cfg,
+			concurrencyService: NewConcurrencyService(stubConcurrencyCache{}),
+			userGroupRateCache: gocache.New(time.Minute, time.Minute),
+			modelsListCache:    gocache.New(time.Minute, time.Minute),
+			modelsListCacheTTL: time.Minute,
+		}
+	}
+	groupID := int64(7)
+	selectWithStrategy := func(svc *GatewayService, strategy string) *AccountSelectionResult {
+		group := &Group{ID: groupID, Hydrated: true, Platform: PlatformAnthropic, Status: StatusActive, ScheduleStrategy: strategy}
+		ctx := context.WithValue(context.Background(), ctxkey.ForcePlatform, PlatformAnthropic)
+		ctx = context.WithValue(ctx, ctxkey.Group, group)
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, &groupID, "", "", nil, "")
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.Acquired)
+		return result
+	}
+
+	svc := newSvc()
+	require.Equal(t, int64(1), selectWithStrategy(svc, "").Account.ID, "default priority_lru prefers higher priority")
+	require.Equal(t, int64(2), selectWithStrategy(svc, AccountScheduleStrategyCostAware).Account.ID, "cost_aware prefers cheaper rate multiplier")
+
+	svc.ReportAccountScheduleResult(2, true, intPtrForTest(120))
+	svc.RecordAccountSwitch()
+
+	snapshot := svc.SnapshotAccountSchedulerMetrics()
+	require.Equal(t, int64(2), snapshot.SelectTotal)
+	require.Equal(t, int64(2), snapshot.LoadBalanceSelectTotal)
+	require.Equal(t, int64(1), snapshot.AccountSwitchTotal)
+	require.Equal(t, 1, snapshot.RuntimeStatsAccountCount)
+	require.Equal(t, map[string]int64{
+		AccountScheduleStrategyPriorityLRU: 1,
+		AccountScheduleStrategyCostAware:   1,
+	}, snapshot.StrategySelectTotals)
+
+	var nilSvc *GatewayService
+	require.Zero(t, nilSvc.SnapshotAccountSchedulerMetrics().SelectTotal)
+	nilSvc.ReportAccountScheduleResult(1, true, nil)
+	nilSvc.RecordAccountSwitch()
+}
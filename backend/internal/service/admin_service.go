@@ -26,14 +26,15 @@ type AdminService interface {
 	CreateUser(ctx context.Context, input *CreateUserInput) (*User, error)
 	UpdateUser(ctx context.Context, id int64, input *UpdateUserInput) (*User, error)
 	DeleteUser(ctx context.Context, id int64) error
-	UpdateUserBalance(ctx context.Context, userID int64, balance float64, operation string, notes string) (*User, error)
+	UpdateUserBalance(ctx context.Context, userID int64, balance float64, operation string, notes string, operatorID int64) (*User, error)
 	BatchUpdateUsers(ctx context.Context, ids []int64, input *UpdateUserInput) (updated int, errors []string, err error)
 	GetUserAPIKeys(ctx context.Context, userID int64, page, pageSize int) ([]APIKey, int64, error)
 	GetUserUsageStats(ctx context.Context, userID int64, period string) (any, error)
 	// GetUserBalanceHistory returns paginated balance/concurrency change records for a user.
+	// Balance changes come from the balance ledger; concurrency and subscription changes from redeem codes.
 	// codeType is optional - pass empty string to return all types.
 	// Also returns totalRecharged (sum of all positive balance top-ups).
-	GetUserBalanceHistory(ctx context.Context, userID int64, page, pageSize int, codeType string) ([]RedeemCode, int64, float64, error)
+	GetUserBalanceHistory(ctx context.Context, userID int64, page, pageSize int, codeType string) ([]BalanceHistoryRecord, int64, float64, error)
 
 	// Group management
 	ListGroups(ctx context.Context, page, pageSize int, platform, status, search string, isExclusive *bool) ([]Group, int64, error)
@@ -115,6 +116,10 @@ type CreateUserInput struct {
 	Concurrency           int
 	AllowedGroups         []int64
 	SoraStorageQuotaBytes int64
+	// 后付费配置：BillingMode 为空时为预付费，BillingCycleDay 为 0 时默认每月 1 日
+	BillingMode     string
+	CreditLimit     float64
+	BillingCycleDay int
 }
 
 type UpdateUserInput struct {
@@ -133,6 +138,9 @@ type UpdateUserInput struct {
 	// map[groupID]*requestQuota，nil 表示删除该分组的按次配额
 	GroupRequestQuotas    map[int64]*int64
 	SoraStorageQuotaBytes *int64
+	BillingMode           *string
+	CreditLimit           *float64
+	BillingCycleDay       *int
 }
 
 type CreateGroupInput struct {
@@ -167,6 +175,8 @@ type CreateGroupInput struct {
 	ModelAliases  map[string]string
 	FallbackModel string // 模型别名兜底模型
 	MCPXMLInject  *bool
+	// 分组自定义模型价格（USD per MTok），优先于全局价格表
+	ModelPrices map[string]GroupModelPrice
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string
 	// Sora 存储配额
@@ -176,6 +186,16 @@ type CreateGroupInput struct {
 	DefaultMappedModel    string
 	RequireOAuthOnly      bool
 	RequirePrivacySet     bool
+	// 精确匹配响应缓存配置（nil 表示使用默认值）
+	ResponseCacheEnabled       bool
+	ResponseCacheTTLSeconds    *int
+	ResponseCacheHitMultiplier *float64
+	// 对冲请求预算（百分比，0 表示关闭）
+	HedgeBudgetPercent float64
+	// 账号调度策略（空表示网关默认策略）
+	ScheduleStrategy string
+	// 计费预留模式（空表示 optimistic）
+	BillingReservationMode string
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -213,6 +233,8 @@ type UpdateGroupInput struct {
 	ModelAliases  map[string]string
 	FallbackModel *string // 模型别名兜底模型
 	MCPXMLInject  *bool
+	// 分组自定义模型价格：nil 表示不修改，空 map 表示清除
+	ModelPrices map[string]GroupModelPrice
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes *[]string
 	// Sora 存储配额
@@ -222,6 +244,16 @@ type UpdateGroupInput struct {
 	DefaultMappedModel    *string
 	RequireOAuthOnly      *bool
 	RequirePrivacySet     *bool
+	// 精确匹配响应缓存配置
+	ResponseCacheEnabled       *bool
+	ResponseCacheTTLSeconds    *int
+	ResponseCacheHitMultiplier *float64
+	// 对冲请求预算（百分比，0 表示关闭）
+	HedgeBudgetPercent *float64
+	// 账号调度策略（空字符串表示恢复网关默认策略）
+	ScheduleStrategy *string
+	// 计费预留模式：optimistic / strict
+	BillingReservationMode *string
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -471,6 +503,7 @@ type adminServiceImpl struct {
 	proxyRepo            ProxyRepository
 	apiKeyRepo           APIKeyRepository
 	redeemCodeRepo       RedeemCodeRepository
+	balanceLedgerRepo    BalanceLedgerRepository
 	userGroupRateRepo    UserGroupRateRepository
 	billingCacheService  *BillingCacheService
 	proxyProber          ProxyExitInfoProber
@@ -497,6 +530,7 @@ func NewAdminService(
 	proxyRepo ProxyRepository,
 	apiKeyRepo APIKeyRepository,
 	redeemCodeRepo RedeemCodeRepository,
+	balanceLedgerRepo BalanceLedgerRepository,
 	userGroupRateRepo UserGroupRateRepository,
 	billingCacheService *BillingCacheService,
 	proxyProber ProxyExitInfoProber,
@@ -516,6 +550,7 @@ func NewAdminService(
 		proxyRepo:            proxyRepo,
 		apiKeyRepo:           apiKeyRepo,
 		redeemCodeRepo:       redeemCodeRepo,
+		balanceLedgerRepo:    balanceLedgerRepo,
 		userGroupRateRepo:    userGroupRateRepo,
 		billingCacheService:  billingCacheService,
 		proxyProber:          proxyProber,
@@ -630,6 +665,15 @@ func (s *adminServiceImpl) CreateUser(ctx context.Context, input *CreateUserInpu
 		Status:                StatusActive,
 		AllowedGroups:         input.AllowedGroups,
 		SoraStorageQuotaBytes: input.SoraStorageQuotaBytes,
+		BillingMode:           NormalizeUserBillingMode(input.BillingMode),
+		CreditLimit:           input.CreditLimit,
+		BillingCycleDay:       input.BillingCycleDay,
+	}
+	if user.BillingCycleDay == 0 {
+		user.BillingCycleDay = MinBillingCycleDay
+	}
+	if err := validateUserBillingSettings(user.BillingMode, user.CreditLimit, user.BillingCycleDay); err != nil {
+		return nil, err
 	}
 	if err := user.SetPassword(input.Password); err != nil {
 		return nil, err
@@ -672,6 +716,8 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 	oldConcurrency := user.Concurrency
 	oldStatus := user.Status
 	oldRole := user.Role
+	oldBillingMode := user.BillingMode
+	oldCreditLimit := user.CreditLimit
 
 	if input.Email != "" {
 		user.Email = input.Email
@@ -705,6 +751,21 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 		user.SoraStorageQuotaBytes = *input.SoraStorageQuotaBytes
 	}
 
+	if input.BillingMode != nil {
+		user.BillingMode = NormalizeUserBillingMode(*input.BillingMode)
+	}
+	if input.CreditLimit != nil {
+		user.CreditLimit = *input.CreditLimit
+	}
+	if input.BillingCycleDay != nil {
+		user.BillingCycleDay = *input.BillingCycleDay
+	}
+	if input.BillingMode != nil || input.CreditLimit != nil || input.BillingCycleDay != nil {
+		if err := validateUserBillingSettings(NormalizeUserBillingMode(user.BillingMode), user.CreditLimit, user.BillingCycleDay); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
@@ -724,7 +785,8 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 	}
 
 	if s.authCacheInvalidator != nil {
-		if user.Concurrency != oldConcurrency || user.Status != oldStatus || user.Role != oldRole || input.GroupRequestQuotas != nil {
+		billingChanged := user.BillingMode != oldBillingMode || user.CreditLimit != oldCreditLimit
+		if user.Concurrency != oldConcurrency || user.Status != oldStatus || user.Role != oldRole || input.GroupRequestQuotas != nil || billingChanged {
 			s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, user.ID)
 		}
 	}
@@ -790,32 +852,53 @@ func (s *adminServiceImpl) DeleteUser(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *adminServiceImpl) UpdateUserBalance(ctx context.Context, userID int64, balance float64, operation string, notes string) (*User, error) {
+// UpdateUserBalance 调整用户余额并写入 admin_adjust 账本流水。
+// "set" 按读取时的余额换算为差额入账，避免覆盖期间并发产生的请求扣费。
+func (s *adminServiceImpl) UpdateUserBalance(ctx context.Context, userID int64, balance float64, operation string, notes string, operatorID int64) (*User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	oldBalance := user.Balance
+	newBalance := oldBalance
 
 	switch operation {
 	case "set":
-		user.Balance = balance
+		newBalance = balance
 	case "add":
-		user.Balance += balance
+		newBalance += balance
 	case "subtract":
-		user.Balance -= balance
+		newBalance -= balance
 	}
 
-	if user.Balance < 0 {
-		return nil, fmt.Errorf("balance cannot be negative, current balance: %.2f, requested operation would result in: %.2f", oldBalance, user.Balance)
+	if newBalance < 0 {
+		return nil, fmt.Errorf("balance cannot be negative, current balance: %.2f, requested operation would result in: %.2f", oldBalance, newBalance)
 	}
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	balanceDiff := newBalance - oldBalance
+	if balanceDiff == 0 {
+		return user, nil
+	}
+
+	entry := &BalanceLedgerEntry{
+		UserID:        userID,
+		EntryType:     BalanceLedgerEntryAdminAdjust,
+		Amount:        balanceDiff,
+		ReferenceType: BalanceLedgerRefAdminAdjustment,
+		ReferenceID:   "adj_" + randomHex(12),
+		ActorType:     BalanceLedgerActorAdmin,
+		Notes:         notes,
+	}
+	if operatorID > 0 {
+		entry.ActorID = &operatorID
+	}
+	if err := s.balanceLedgerRepo.Apply(ctx, entry); err != nil {
 		return nil, err
 	}
-	balanceDiff := user.Balance - oldBalance
-	if s.authCacheInvalidator != nil && balanceDiff != 0 {
+	user.Balance = newBalance
+
+	if s.authCacheInvalidator != nil {
 		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
 	}
 
@@ -829,29 +912,6 @@ func (s *adminServiceImpl) UpdateUserBalance(ctx context.Context, userID int64,
 		}()
 	}
 
-	if balanceDiff != 0 {
-		code, err := GenerateRedeemCode()
-		if err != nil {
-			logger.LegacyPrintf("service.admin", "failed to generate adjustment redeem code: %v", err)
-			return user, nil
-		}
-
-		adjustmentRecord := &RedeemCode{
-			Code:   code,
-			Type:   AdjustmentTypeAdminBalance,
-			Value:  balanceDiff,
-			Status: StatusUsed,
-			UsedBy: &user.ID,
-			Notes:  notes,
-		}
-		now := time.Now()
-		adjustmentRecord.UsedAt = &now
-
-		if err := s.redeemCodeRepo.Create(ctx, adjustmentRecord); err != nil {
-			logger.LegacyPrintf("service.admin", "failed to create balance adjustment redeem code: %v", err)
-		}
-	}
-
 	return user, nil
 }
 
@@ -876,18 +936,58 @@ func (s *adminServiceImpl) GetUserUsageStats(ctx context.Context, userID int64,
 }
 
 // GetUserBalanceHistory returns paginated balance/concurrency change records for a user.
-func (s *adminServiceImpl) GetUserBalanceHistory(ctx context.Context, userID int64, page, pageSize int, codeType string) ([]RedeemCode, int64, float64, error) {
+func (s *adminServiceImpl) GetUserBalanceHistory(ctx context.Context, userID int64, page, pageSize int, codeType string) ([]BalanceHistoryRecord, int64, float64, error) {
 	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
-	codes, result, err := s.redeemCodeRepo.ListByUserPaginated(ctx, userID, params, codeType)
+	refs, result, err := s.balanceLedgerRepo.ListHistoryByUser(ctx, userID, params, codeType)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	var ledgerIDs, codeIDs []int64
+	for _, ref := range refs {
+		if ref.Source == BalanceHistorySourceLedger {
+			ledgerIDs = append(ledgerIDs, ref.ID)
+		} else {
+			codeIDs = append(codeIDs, ref.ID)
+		}
+	}
+	entries, err := s.balanceLedgerRepo.ListByIDs(ctx, ledgerIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	codes, err := s.redeemCodeRepo.ListByIDs(ctx, codeIDs)
 	if err != nil {
 		return nil, 0, 0, err
 	}
+	entryByID := make(map[int64]*BalanceLedgerEntry, len(entries))
+	for i := range entries {
+		entryByID[entries[i].ID] = &entries[i]
+	}
+	codeByID := make(map[int64]*RedeemCode, len(codes))
+	for i := range codes {
+		codeByID[codes[i].ID] = &codes[i]
+	}
+
+	// Keep the merged order returned by ListHistoryByUser
+	records := make([]BalanceHistoryRecord, 0, len(refs))
+	for _, ref := range refs {
+		var record BalanceHistoryRecord
+		if ref.Source == BalanceHistorySourceLedger {
+			record.LedgerEntry = entryByID[ref.ID]
+		} else {
+			record.RedeemCode = codeByID[ref.ID]
+		}
+		if record.LedgerEntry != nil || record.RedeemCode != nil {
+			records = append(records, record)
+		}
+	}
+
 	// Aggregate total recharged amount (only once, regardless of type filter)
-	totalRecharged, err := s.redeemCodeRepo.SumPositiveBalanceByUser(ctx, userID)
+	totalRecharged, err := s.balanceLedgerRepo.SumCreditsByUser(ctx, userID)
 	if err != nil {
 		return nil, 0, 0, err
 	}
-	return codes, result.Total, totalRecharged, nil
+	return records, result.Total, totalRecharged, nil
 }
 
 // Group management implementations
@@ -992,6 +1092,17 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		}
 	}
 
+	if !IsValidAccountScheduleStrategy(normalizeAccountScheduleStrategy(input.ScheduleStrategy)) {
+		return nil, ErrInvalidAccountScheduleStrategy
+	}
+	if !IsValidBillingReservationMode(NormalizeBillingReservationMode(input.BillingReservationMode)) {
+		return nil, ErrInvalidBillingReservationMode
+	}
+	modelPrices, err := NormalizeGroupModelPrices(input.ModelPrices)
+	if err != nil {
+		return nil, err
+	}
+
 	group := &Group{
 		Name:                            input.Name,
 		Description:                     input.Description,
@@ -1017,6 +1128,7 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		FallbackGroupIDOnInvalidRequest: fallbackOnInvalidRequest,
 		ModelRouting:                    input.ModelRouting,
 		ModelAliases:                    input.ModelAliases,
+		ModelPrices:                     modelPrices,
 		FallbackModel:                   input.FallbackModel,
 		MCPXMLInject:                    mcpXMLInject,
 		SupportedModelScopes:            input.SupportedModelScopes,
@@ -1025,6 +1137,12 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		RequireOAuthOnly:                input.RequireOAuthOnly,
 		RequirePrivacySet:               input.RequirePrivacySet,
 		DefaultMappedModel:              input.DefaultMappedModel,
+		ResponseCacheEnabled:            input.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         normalizeResponseCacheTTLSeconds(input.ResponseCacheTTLSeconds),
+		ResponseCacheHitMultiplier:      normalizeResponseCacheHitMultiplier(input.ResponseCacheHitMultiplier),
+		HedgeBudgetPercent:              normalizeHedgeBudgetPercent(input.HedgeBudgetPercent),
+		ScheduleStrategy:                normalizeAccountScheduleStrategy(input.ScheduleStrategy),
+		BillingReservationMode:          NormalizeBillingReservationMode(input.BillingReservationMode),
 	}
 	if err := s.groupRepo.Create(ctx, group); err != nil {
 		return nil, err
@@ -1255,6 +1373,13 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.ModelAliases != nil {
 		group.ModelAliases = input.ModelAliases
 	}
+	if input.ModelPrices != nil {
+		modelPrices, err := NormalizeGroupModelPrices(input.ModelPrices)
+		if err != nil {
+			return nil, err
+		}
+		group.ModelPrices = modelPrices
+	}
 	if input.FallbackModel != nil {
 		group.FallbackModel = *input.FallbackModel
 	}
@@ -1281,6 +1406,34 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 		group.DefaultMappedModel = *input.DefaultMappedModel
 	}
 
+	// 精确匹配响应缓存配置
+	if input.ResponseCacheEnabled != nil {
+		group.ResponseCacheEnabled = *input.ResponseCacheEnabled
+	}
+	if input.ResponseCacheTTLSeconds != nil {
+		group.ResponseCacheTTLSeconds = normalizeResponseCacheTTLSeconds(input.ResponseCacheTTLSeconds)
+	}
+	if input.ResponseCacheHitMultiplier != nil {
+		group.ResponseCacheHitMultiplier = normalizeResponseCacheHitMultiplier(input.ResponseCacheHitMultiplier)
+	}
+	if input.HedgeBudgetPercent != nil {
+		group.HedgeBudgetPercent = normalizeHedgeBudgetPercent(*input.HedgeBudgetPercent)
+	}
+	if input.ScheduleStrategy != nil {
+		strategy := normalizeAccountScheduleStrategy(*input.ScheduleStrategy)
+		if !IsValidAccountScheduleStrategy(strategy) {
+			return nil, ErrInvalidAccountScheduleStrategy
+		}
+		group.ScheduleStrategy = strategy
+	}
+	if input.BillingReservationMode != nil {
+		mode := NormalizeBillingReservationMode(*input.BillingReservationMode)
+		if !IsValidBillingReservationMode(mode) {
+			return nil, ErrInvalidBillingReservationMode
+		}
+		group.BillingReservationMode = mode
+	}
+
 	if err := s.groupRepo.Update(ctx, group); err != nil {
 		return nil, err
 	}
@@ -70,10 +70,6 @@ func (s *userRepoStub) ListWithFilters(ctx context.Context, params pagination.Pa
 	panic("unexpected ListWithFilters call")
 }
 
-func (s *userRepoStub) UpdateBalance(ctx context.Context, id int64, amount float64) error {
-	panic("unexpected UpdateBalance call")
-}
-
 func (s *userRepoStub) DeductBalance(ctx context.Context, id int64, amount float64) error {
 	panic("unexpected DeductBalance call")
 }
@@ -298,12 +294,8 @@ func (s *redeemRepoStub) ListByUser(ctx context.Context, userID int64, limit int
 	panic("unexpected ListByUser call")
 }
 
-func (s *redeemRepoStub) ListByUserPaginated(ctx context.Context, userID int64, params pagination.PaginationParams, codeType string) ([]RedeemCode, *pagination.PaginationResult, error) {
-	panic("unexpected ListByUserPaginated call")
-}
-
-func (s *redeemRepoStub) SumPositiveBalanceByUser(ctx context.Context, userID int64) (float64, error) {
-	panic("unexpected SumPositiveBalanceByUser call")
+func (s *redeemRepoStub) ListByIDs(ctx context.Context, ids []int64) ([]RedeemCode, error) {
+	panic("unexpected ListByIDs call")
 }
 
 type subscriptionInvalidateCall struct {
@@ -365,6 +357,18 @@ func (s *billingCacheStub) InvalidateAPIKeyRateLimit(ctx context.Context, keyID
 	panic("unexpected InvalidateAPIKeyRateLimit call")
 }
 
+func (s *billingCacheStub) ReserveBillingHolds(ctx context.Context, holdID string, holds []BillingHold, ttl time.Duration) (int, error) {
+	panic("unexpected ReserveBillingHolds call")
+}
+
+func (s *billingCacheStub) SettleBillingHolds(ctx context.Context, holdID string, holds []BillingHold, balanceCost float64) error {
+	panic("unexpected SettleBillingHolds call")
+}
+
+func (s *billingCacheStub) ReleaseBillingHolds(ctx context.Context, holdID string, holds []BillingHold) error {
+	panic("unexpected ReleaseBillingHolds call")
+}
+
 func waitForInvalidations(t *testing.T, ch <-chan subscriptionInvalidateCall, expected int) []subscriptionInvalidateCall {
 	t.Helper()
 	calls := make([]subscriptionInvalidateCall, 0, expected)
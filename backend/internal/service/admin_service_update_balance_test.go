@@ -4,8 +4,10 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,6 +46,20 @@ func (s *balanceRedeemRepoStub) Create(ctx context.Context, code *RedeemCode) er
 	return nil
 }
 
+type balanceLedgerRepoStub struct {
+	BalanceLedgerRepository
+	balance float64
+	applied []*BalanceLedgerEntry
+}
+
+func (s *balanceLedgerRepoStub) Apply(ctx context.Context, entry *BalanceLedgerEntry) error {
+	s.balance += entry.Amount
+	entry.BalanceAfter = s.balance
+	clone := *entry
+	s.applied = append(s.applied, &clone)
+	return nil
+}
+
 type authCacheInvalidatorStub struct {
 	userIDs  []int64
 	groupIDs []int64
@@ -66,32 +82,105 @@ func TestAdminService_UpdateUserBalance_InvalidatesAuthCache(t *testing.T) {
 	baseRepo := &userRepoStub{user: &User{ID: 7, Balance: 10}}
 	repo := &balanceUserRepoStub{userRepoStub: baseRepo}
 	redeemRepo := &balanceRedeemRepoStub{redeemRepoStub: &redeemRepoStub{}}
+	ledgerRepo := &balanceLedgerRepoStub{balance: 10}
 	invalidator := &authCacheInvalidatorStub{}
 	svc := &adminServiceImpl{
 		userRepo:             repo,
 		redeemCodeRepo:       redeemRepo,
+		balanceLedgerRepo:    ledgerRepo,
 		authCacheInvalidator: invalidator,
 	}
 
-	_, err := svc.UpdateUserBalance(context.Background(), 7, 5, "add", "")
+	user, err := svc.UpdateUserBalance(context.Background(), 7, 5, "add", "", 1)
 	require.NoError(t, err)
+	require.Equal(t, 15.0, user.Balance)
 	require.Equal(t, []int64{7}, invalidator.userIDs)
-	require.Len(t, redeemRepo.created, 1)
+	require.Empty(t, redeemRepo.created)
+	require.Len(t, ledgerRepo.applied, 1)
+	entry := ledgerRepo.applied[0]
+	require.Equal(t, BalanceLedgerEntryAdminAdjust, entry.EntryType)
+	require.Equal(t, 5.0, entry.Amount)
+	require.Equal(t, BalanceLedgerActorAdmin, entry.ActorType)
+	require.Equal(t, int64(1), *entry.ActorID)
+	require.True(t, strings.HasPrefix(entry.ReferenceID, "adj_"))
 }
 
 func TestAdminService_UpdateUserBalance_NoChangeNoInvalidate(t *testing.T) {
 	baseRepo := &userRepoStub{user: &User{ID: 7, Balance: 10}}
 	repo := &balanceUserRepoStub{userRepoStub: baseRepo}
 	redeemRepo := &balanceRedeemRepoStub{redeemRepoStub: &redeemRepoStub{}}
+	ledgerRepo := &balanceLedgerRepoStub{balance: 10}
 	invalidator := &authCacheInvalidatorStub{}
 	svc := &adminServiceImpl{
 		userRepo:             repo,
 		redeemCodeRepo:       redeemRepo,
+		balanceLedgerRepo:    ledgerRepo,
 		authCacheInvalidator: invalidator,
 	}
 
-	_, err := svc.UpdateUserBalance(context.Background(), 7, 10, "set", "")
+	_, err := svc.UpdateUserBalance(context.Background(), 7, 10, "set", "", 1)
 	require.NoError(t, err)
 	require.Empty(t, invalidator.userIDs)
 	require.Empty(t, redeemRepo.created)
+	require.Empty(t, ledgerRepo.applied)
+}
+
+type balanceHistoryLedgerRepoStub struct {
+	balanceLedgerRepoStub
+	refs    []BalanceHistoryRef
+	entries []BalanceLedgerEntry
+	credits float64
+}
+
+func (s *balanceHistoryLedgerRepoStub) ListHistoryByUser(ctx context.Context, userID int64, params pagination.PaginationParams, historyType string) ([]BalanceHistoryRef, *pagination.PaginationResult, error) {
+	return s.refs, &pagination.PaginationResult{Total: int64(len(s.refs)), Page: params.Page, PageSize: params.PageSize}, nil
+}
+
+func (s *balanceHistoryLedgerRepoStub) ListByIDs(ctx context.Context, ids []int64) ([]BalanceLedgerEntry, error) {
+	return s.entries, nil
+}
+
+func (s *balanceHistoryLedgerRepoStub) SumCreditsByUser(ctx context.Context, userID int64) (float64, error) {
+	return s.credits, nil
+}
+
+type balanceHistoryRedeemRepoStub struct {
+	*redeemRepoStub
+	codes []RedeemCode
+}
+
+func (s *balanceHistoryRedeemRepoStub) ListByIDs(ctx context.Context, ids []int64) ([]RedeemCode, error) {
+	return s.codes, nil
+}
+
+func TestAdminService_GetUserBalanceHistory_MergesLedgerAndRedeemCodes(t *testing.T) {
+	ledgerRepo := &balanceHistoryLedgerRepoStub{
+		refs: []BalanceHistoryRef{
+			{Source: BalanceHistorySourceLedger, ID: 5},
+			{Source: BalanceHistorySourceRedeemCode, ID: 5},
+			{Source: BalanceHistorySourceLedger, ID: 3},
+		},
+		entries: []BalanceLedgerEntry{
+			{ID: 3, UserID: 7, EntryType: BalanceLedgerEntryRedeem, Amount: 20},
+			{ID: 5, UserID: 7, EntryType: BalanceLedgerEntryAdminAdjust, Amount: -5},
+		},
+		credits: 20,
+	}
+	redeemRepo := &balanceHistoryRedeemRepoStub{
+		redeemRepoStub: &redeemRepoStub{},
+		codes:          []RedeemCode{{ID: 5, Type: RedeemTypeConcurrency, Value: 2}},
+	}
+	svc := &adminServiceImpl{redeemCodeRepo: redeemRepo, balanceLedgerRepo: ledgerRepo}
+
+	records, total, totalRecharged, err := svc.GetUserBalanceHistory(context.Background(), 7, 1, 20, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Equal(t, 20.0, totalRecharged)
+	require.Len(t, records, 3)
+	require.NotNil(t, records[0].LedgerEntry)
+	require.Equal(t, int64(5), records[0].LedgerEntry.ID)
+	require.NotNil(t, records[1].RedeemCode)
+	require.Equal(t, RedeemTypeConcurrency, records[1].RedeemCode.Type)
+	require.NotNil(t, records[2].LedgerEntry)
+	require.Equal(t, int64(3), records[2].LedgerEntry.ID)
 }
@@ -53,10 +53,6 @@ func (s *userRepoStubForGroupRequestQuota) ListWithFilters(context.Context, pagi
 	panic("unexpected ListWithFilters call")
 }
 
-func (s *userRepoStubForGroupRequestQuota) UpdateBalance(context.Context, int64, float64) error {
-	panic("unexpected UpdateBalance call")
-}
-
 func (s *userRepoStubForGroupRequestQuota) DeductBalance(context.Context, int64, float64) error {
 	panic("unexpected DeductBalance call")
 }
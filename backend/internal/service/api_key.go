@@ -12,6 +12,7 @@ const (
 	StatusAPIKeyDisabled       = "disabled"
 	StatusAPIKeyQuotaExhausted = "quota_exhausted"
 	StatusAPIKeyExpired        = "expired"
+	StatusAPIKeyOverdue        = "overdue" // 后付费账单逾期暂停，付款后由系统恢复
 )
 
 // Rate limit window durations
@@ -37,6 +37,8 @@ type APIKeyAuthUserSnapshot struct {
 	Role        string  `json:"role"`
 	Balance     float64 `json:"balance"`
 	Concurrency int     `json:"concurrency"`
+	BillingMode string  `json:"billing_mode,omitempty"`
+	CreditLimit float64 `json:"credit_limit,omitempty"`
 }
 
 // APIKeyAuthGroupSnapshot 分组快照
@@ -69,6 +71,8 @@ type APIKeyAuthGroupSnapshot struct {
 	ModelAliases  map[string]string `json:"model_aliases,omitempty"`
 	FallbackModel string            `json:"fallback_model,omitempty"`
 	MCPXMLInject  bool              `json:"mcp_xml_inject"`
+	// 分组自定义模型价格，计费时优先于全局价格表
+	ModelPrices map[string]GroupModelPrice `json:"model_prices,omitempty"`
 
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string `json:"supported_model_scopes,omitempty"`
@@ -76,6 +80,16 @@ type APIKeyAuthGroupSnapshot struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    string `json:"default_mapped_model,omitempty"`
+
+	// 精确匹配响应缓存配置
+	ResponseCacheEnabled       bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds    int     `json:"response_cache_ttl_seconds,omitempty"`
+	ResponseCacheHitMultiplier float64 `json:"response_cache_hit_multiplier"`
+
+	HedgeBudgetPercent float64 `json:"hedge_budget_percent,omitempty"`
+	ScheduleStrategy   string  `json:"schedule_strategy,omitempty"`
+
+	BillingReservationMode string `json:"billing_reservation_mode,omitempty"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
@@ -225,6 +225,8 @@ func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
 			Role:        apiKey.User.Role,
 			Balance:     apiKey.User.Balance,
 			Concurrency: apiKey.User.Concurrency,
+			BillingMode: apiKey.User.BillingMode,
+			CreditLimit: apiKey.User.CreditLimit,
 		},
 	}
 	if apiKey.Group != nil {
@@ -251,11 +253,18 @@ func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
 			ModelRouting:                    apiKey.Group.ModelRouting,
 			ModelRoutingEnabled:             apiKey.Group.ModelRoutingEnabled,
 			ModelAliases:                    apiKey.Group.ModelAliases,
+			ModelPrices:                     apiKey.Group.ModelPrices,
 			FallbackModel:                   apiKey.Group.FallbackModel,
 			MCPXMLInject:                    apiKey.Group.MCPXMLInject,
 			SupportedModelScopes:            apiKey.Group.SupportedModelScopes,
 			AllowMessagesDispatch:           apiKey.Group.AllowMessagesDispatch,
 			DefaultMappedModel:              apiKey.Group.DefaultMappedModel,
+			ResponseCacheEnabled:            apiKey.Group.ResponseCacheEnabled,
+			ResponseCacheTTLSeconds:         apiKey.Group.ResponseCacheTTLSeconds,
+			ResponseCacheHitMultiplier:      apiKey.Group.ResponseCacheHitMultiplier,
+			HedgeBudgetPercent:              apiKey.Group.HedgeBudgetPercent,
+			ScheduleStrategy:                apiKey.Group.ScheduleStrategy,
+			BillingReservationMode:          apiKey.Group.BillingReservationMode,
 		}
 	}
 	return snapshot
@@ -289,6 +298,8 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			Role:        snapshot.User.Role,
 			Balance:     snapshot.User.Balance,
 			Concurrency: snapshot.User.Concurrency,
+			BillingMode: snapshot.User.BillingMode,
+			CreditLimit: snapshot.User.CreditLimit,
 		},
 	}
 	if snapshot.Group != nil {
@@ -316,11 +327,18 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			ModelRouting:                    snapshot.Group.ModelRouting,
 			ModelRoutingEnabled:             snapshot.Group.ModelRoutingEnabled,
 			ModelAliases:                    snapshot.Group.ModelAliases,
+			ModelPrices:                     snapshot.Group.ModelPrices,
 			FallbackModel:                   snapshot.Group.FallbackModel,
 			MCPXMLInject:                    snapshot.Group.MCPXMLInject,
 			SupportedModelScopes:            snapshot.Group.SupportedModelScopes,
 			AllowMessagesDispatch:           snapshot.Group.AllowMessagesDispatch,
 			DefaultMappedModel:              snapshot.Group.DefaultMappedModel,
+			ResponseCacheEnabled:            snapshot.Group.ResponseCacheEnabled,
+			ResponseCacheTTLSeconds:         snapshot.Group.ResponseCacheTTLSeconds,
+			ResponseCacheHitMultiplier:      snapshot.Group.ResponseCacheHitMultiplier,
+			HedgeBudgetPercent:              snapshot.Group.HedgeBudgetPercent,
+			ScheduleStrategy:                snapshot.Group.ScheduleStrategy,
+			BillingReservationMode:          snapshot.Group.BillingReservationMode,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
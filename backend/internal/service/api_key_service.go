@@ -584,7 +584,8 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		apiKey.GroupID = req.GroupID
 	}
 
-	if req.Status != nil {
+	// 逾期暂停的 Key 只能通过结清账单恢复，编辑时提交的 active 状态不生效
+	if req.Status != nil && !(apiKey.Status == StatusAPIKeyOverdue && *req.Status == StatusAPIKeyActive) {
 		apiKey.Status = *req.Status
 		// 如果状态改变，清除Redis缓存
 		if s.cache != nil {
@@ -956,6 +956,37 @@ func (s *BackupService) GetBackupDownloadURL(ctx context.Context, backupID strin
 	return url, nil
 }
 
+// UploadArtifact 将非备份产物（如定时运维报表）上传到已配置的 S3 存储，返回对象 key。
+// key 格式：<prefix>/<category>/<yyyy/mm/dd>/<fileName>，与备份文件共用同一前缀。
+func (s *BackupService) UploadArtifact(ctx context.Context, category, fileName string, body io.Reader, contentType string) (string, error) {
+	s3Cfg, err := s.loadS3Config(ctx)
+	if err != nil {
+		return "", err
+	}
+	if s3Cfg == nil || !s3Cfg.IsConfigured() {
+		return "", ErrBackupS3NotConfigured
+	}
+	objectStore, err := s.getOrCreateStore(ctx, s3Cfg)
+	if err != nil {
+		return "", fmt.Errorf("init object store: %w", err)
+	}
+
+	parts := make([]string, 0, 4)
+	if prefix := strings.Trim(strings.TrimSpace(s3Cfg.Prefix), "/"); prefix != "" {
+		parts = append(parts, prefix)
+	}
+	if category = strings.Trim(strings.TrimSpace(category), "/"); category != "" {
+		parts = append(parts, category)
+	}
+	parts = append(parts, time.Now().Format("2006/01/02"), fileName)
+	key := strings.Join(parts, "/")
+
+	if _, err := objectStore.Upload(ctx, key, body, contentType); err != nil {
+		return "", fmt.Errorf("S3 upload failed: %w", err)
+	}
+	return key, nil
+}
+
 // ─── 内部方法 ───
 
 func (s *BackupService) loadS3Config(ctx context.Context) (*BackupS3Config, error) {
@@ -476,6 +476,29 @@ func TestBackupService_GetDownloadURL(t *testing.T) {
 	require.Contains(t, url, "https://presigned.example.com/")
 }
 
+func TestBackupService_UploadArtifact(t *testing.T) {
+	repo := newMockSettingRepo()
+	seedS3Config(t, repo)
+	store := newMockObjectStore()
+	svc := newTestBackupService(repo, &mockDumper{}, store)
+
+	key, err := svc.UploadArtifact(context.Background(), "ops-reports", "user_spend.csv", strings.NewReader("a,b\n1,2\n"), "text/csv")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, "backups/ops-reports/"))
+	require.True(t, strings.HasSuffix(key, "/user_spend.csv"))
+
+	store.mu.Lock()
+	require.Equal(t, "a,b\n1,2\n", string(store.objects[key]))
+	store.mu.Unlock()
+}
+
+func TestBackupService_UploadArtifact_NoS3Config(t *testing.T) {
+	svc := newTestBackupService(newMockSettingRepo(), &mockDumper{}, newMockObjectStore())
+
+	_, err := svc.UploadArtifact(context.Background(), "ops-reports", "x.csv", strings.NewReader("x"), "text/csv")
+	require.ErrorIs(t, err, ErrBackupS3NotConfigured)
+}
+
 func TestBackupService_ListBackups_Sorted(t *testing.T) {
 	repo := newMockSettingRepo()
 	svc := newTestBackupService(repo, &mockDumper{}, newMockObjectStore())
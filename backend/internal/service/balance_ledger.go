@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 账本流水类型
+const (
+	BalanceLedgerEntryOpening         = "opening"
+	BalanceLedgerEntryTopup           = "topup"
+	BalanceLedgerEntryRedeem          = "redeem"
+	BalanceLedgerEntryPromo           = "promo"
+	BalanceLedgerEntryAdminAdjust     = "admin_adjust"
+	BalanceLedgerEntryUsage           = "usage"
+	BalanceLedgerEntryRefund          = "refund"
+	BalanceLedgerEntryChargeback      = "chargeback"
+	BalanceLedgerEntryPostpaidPayment = "postpaid_payment"
+)
+
+// 账本操作方
+const (
+	BalanceLedgerActorSystem   = "system"
+	BalanceLedgerActorUser     = "user"
+	BalanceLedgerActorAdmin    = "admin"
+	BalanceLedgerActorProvider = "provider"
+)
+
+// 账本关联对象类型
+const (
+	BalanceLedgerRefRedeemCode        = "redeem_code"
+	BalanceLedgerRefPromoCode         = "promo_code"
+	BalanceLedgerRefPaymentOrder      = "payment_order"
+	BalanceLedgerRefAdminAdjustment   = "admin_adjustment"
+	BalanceLedgerRefUser              = "user"
+	BalanceLedgerRefPostpaidStatement = "postpaid_statement"
+)
+
+var (
+	ErrBalanceLedgerDuplicate = infraerrors.Conflict("BALANCE_LEDGER_DUPLICATE", "balance change already recorded for this reference")
+	ErrBalanceLedgerAmount    = infraerrors.BadRequest("BALANCE_LEDGER_AMOUNT_INVALID", "balance change amount must be non-zero")
+)
+
+// BalanceLedgerEntry 一条余额流水
+type BalanceLedgerEntry struct {
+	ID        int64
+	UserID    int64
+	EntryType string
+	// Amount 变动金额，正数入账、负数扣减
+	Amount float64
+	// BalanceAfter 账本余额：上一条流水的 BalanceAfter + Amount，不含尚未汇总的请求扣费；
+	// users.balance = 最新一条流水的 BalanceAfter - 待汇总扣费
+	BalanceAfter float64
+
+	ReferenceType string
+	ReferenceID   string
+	ActorType     string
+	ActorID       *int64
+
+	// RequestCount / PeriodStart / PeriodEnd 仅 usage 汇总条目有效
+	RequestCount int64
+	PeriodStart  *time.Time
+	PeriodEnd    *time.Time
+
+	Notes     string
+	CreatedAt time.Time
+}
+
+// BalanceLedgerFilter 流水查询条件
+type BalanceLedgerFilter struct {
+	EntryType string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// 余额历史记录来源
+const (
+	BalanceHistorySourceLedger     = "ledger"
+	BalanceHistorySourceRedeemCode = "redeem_code"
+)
+
+// BalanceHistoryRef 余额/并发变动历史中的一条记录引用（来源 + 记录 ID）
+type BalanceHistoryRef struct {
+	Source string
+	ID     int64
+}
+
+// BalanceHistoryRecord 余额/并发变动历史中的一条记录：余额变动取自账本流水，
+// 并发、订阅等非余额变动取自已使用的兑换码；LedgerEntry 与 RedeemCode 二选一。
+type BalanceHistoryRecord struct {
+	LedgerEntry *BalanceLedgerEntry
+	RedeemCode  *RedeemCode
+}
+
+// BalanceLedgerTypeTotal 按类型汇总的流水金额
+type BalanceLedgerTypeTotal struct {
+	EntryType string  `json:"entry_type"`
+	Amount    float64 `json:"amount"`
+	Count     int64   `json:"count"`
+}
+
+// BalancePendingUsage 尚未汇总入账的请求扣费
+type BalancePendingUsage struct {
+	Amount       float64    `json:"amount"`
+	RequestCount int64      `json:"request_count"`
+	FirstAt      *time.Time `json:"first_at,omitempty"`
+	LastAt       *time.Time `json:"last_at,omitempty"`
+}
+
+// BalanceDrift 余额与账本不一致的用户
+type BalanceDrift struct {
+	UserID  int64   `json:"user_id"`
+	Email   string  `json:"email"`
+	Balance float64 `json:"balance"`
+	// LedgerTotal 账本流水合计
+	LedgerTotal float64 `json:"ledger_total"`
+	// PendingUsage 尚未汇总入账的请求扣费
+	PendingUsage float64 `json:"pending_usage"`
+	// Drift = Balance - (LedgerTotal - PendingUsage)
+	Drift float64 `json:"drift"`
+}
+
+// BalanceLedgerRepository 余额账本存储
+type BalanceLedgerRepository interface {
+	// Apply 调整 users.balance 并追加流水（同一用户串行写入），回填 ID、BalanceAfter 与 CreatedAt。
+	// 同一 (user, entry_type, reference) 重复写入返回 ErrBalanceLedgerDuplicate。
+	Apply(ctx context.Context, entry *BalanceLedgerEntry) error
+	ListByUser(ctx context.Context, userID int64, params pagination.PaginationParams, filter BalanceLedgerFilter) ([]BalanceLedgerEntry, *pagination.PaginationResult, error)
+	SummarizeByUser(ctx context.Context, userID int64, filter BalanceLedgerFilter) ([]BalanceLedgerTypeTotal, error)
+	GetPendingUsage(ctx context.Context, userID int64) (*BalancePendingUsage, error)
+	ListByIDs(ctx context.Context, ids []int64) ([]BalanceLedgerEntry, error)
+	// SumCreditsByUser 返回用户累计充值金额（充值、兑换、优惠码与管理员调增的入账合计）
+	SumCreditsByUser(ctx context.Context, userID int64) (float64, error)
+
+	// ListHistoryByUser 按时间倒序分页返回余额/并发变动历史的记录引用：账本流水与非余额类兑换码。
+	// historyType 沿用兑换码类型：balance（管理员调整以外的流水）、admin_balance（管理员调整），
+	// 其他类型只查兑换码，空字符串表示全部。
+	ListHistoryByUser(ctx context.Context, userID int64, params pagination.PaginationParams, historyType string) ([]BalanceHistoryRef, *pagination.PaginationResult, error)
+
+	// ListPendingUsageUsers 返回有待汇总请求扣费的用户（最早累计的优先）
+	ListPendingUsageUsers(ctx context.Context, limit int) ([]int64, error)
+	// FlushPendingUsage 将用户累计的请求扣费汇总为一条 usage 流水；无待汇总数据时返回 nil
+	FlushPendingUsage(ctx context.Context, userID int64) (*BalanceLedgerEntry, error)
+
+	// FindDrift 返回 users.balance 与账本不一致（偏差超过 tolerance）的用户
+	FindDrift(ctx context.Context, tolerance float64, limit int) ([]BalanceDrift, error)
+}
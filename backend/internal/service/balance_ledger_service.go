@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	balanceLedgerReconcileLockKey   = "balance_ledger:reconcile:leader"
+	balanceLedgerDriftReportLimit   = 100
+	balanceLedgerDriftAlertMaxUsers = 10
+	balanceLedgerFlushTimeout       = 30 * time.Second
+	balanceLedgerReconcileTimeout   = 2 * time.Minute
+)
+
+// BalanceStatement 用户账单：当前余额、待汇总扣费、区间内按类型汇总与流水明细
+type BalanceStatement struct {
+	Balance      float64                  `json:"balance"`
+	PendingUsage *BalancePendingUsage     `json:"pending_usage"`
+	Totals       []BalanceLedgerTypeTotal `json:"totals"`
+	Entries      []BalanceLedgerEntry     `json:"-"`
+	Total        int64                    `json:"-"`
+}
+
+// BalanceReconcileResult 一次对账的结果
+type BalanceReconcileResult struct {
+	CheckedAt time.Time      `json:"checked_at"`
+	Tolerance float64        `json:"tolerance"`
+	Drifts    []BalanceDrift `json:"drifts"`
+}
+
+// BalanceLedgerService 余额账本：周期性汇总请求扣费、核对 users.balance 与账本并在偏差时告警
+type BalanceLedgerService struct {
+	repo                BalanceLedgerRepository
+	userRepo            UserRepository
+	notificationService *OpsNotificationService
+	redisClient         *redis.Client
+
+	flushInterval     time.Duration
+	flushBatch        int
+	reconcileInterval time.Duration
+	tolerance         float64
+	alertChannelIDs   []int64
+	instanceID        string
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewBalanceLedgerService 创建余额账本服务
+func NewBalanceLedgerService(
+	repo BalanceLedgerRepository,
+	userRepo UserRepository,
+	notificationService *OpsNotificationService,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) *BalanceLedgerService {
+	s := &BalanceLedgerService{
+		repo:                repo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		redisClient:         redisClient,
+		flushInterval:       time.Minute,
+		flushBatch:          500,
+		reconcileInterval:   time.Hour,
+		tolerance:           0.000001,
+		instanceID:          uuid.NewString(),
+		stopCh:              make(chan struct{}),
+	}
+	if cfg != nil {
+		lc := cfg.BalanceLedger
+		if lc.UsageFlushIntervalSeconds > 0 {
+			s.flushInterval = time.Duration(lc.UsageFlushIntervalSeconds) * time.Second
+		}
+		if lc.UsageFlushBatchSize > 0 {
+			s.flushBatch = lc.UsageFlushBatchSize
+		}
+		s.reconcileInterval = time.Duration(lc.ReconcileIntervalMinutes) * time.Minute
+		if lc.DriftTolerance >= 0 {
+			s.tolerance = lc.DriftTolerance
+		}
+		s.alertChannelIDs = lc.AlertChannelIDs
+	}
+	return s
+}
+
+func (s *BalanceLedgerService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] started flush_interval=%s reconcile_interval=%s", s.flushInterval, s.reconcileInterval)
+		s.wg.Add(1)
+		go s.runLoop()
+	})
+}
+
+func (s *BalanceLedgerService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+		// 停机前汇总一轮，减少重启后的积压
+		if s.repo != nil {
+			s.flushOnce()
+		}
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] stopped")
+	})
+}
+
+func (s *BalanceLedgerService) runLoop() {
+	defer s.wg.Done()
+	flushTicker := time.NewTicker(s.flushInterval)
+	defer flushTicker.Stop()
+
+	var reconcileC <-chan time.Time
+	if s.reconcileInterval > 0 {
+		reconcileTicker := time.NewTicker(s.reconcileInterval)
+		defer reconcileTicker.Stop()
+		reconcileC = reconcileTicker.C
+	}
+
+	for {
+		select {
+		case <-flushTicker.C:
+			s.flushOnce()
+		case <-reconcileC:
+			s.reconcileScheduled()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *BalanceLedgerService) flushOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), balanceLedgerFlushTimeout)
+	defer cancel()
+
+	flushed, err := s.FlushPendingUsage(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] flush pending usage failed: %v", err)
+		return
+	}
+	if flushed > 0 {
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] flushed pending usage users=%d", flushed)
+	}
+}
+
+// FlushPendingUsage 将累计的请求扣费汇总写入账本，返回处理的用户数
+func (s *BalanceLedgerService) FlushPendingUsage(ctx context.Context) (int, error) {
+	userIDs, err := s.repo.ListPendingUsageUsers(ctx, s.flushBatch)
+	if err != nil {
+		return 0, err
+	}
+	flushed := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return flushed, ctx.Err()
+		}
+		entry, err := s.repo.FlushPendingUsage(ctx, userID)
+		if err != nil {
+			logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] flush usage failed: user=%d err=%v", userID, err)
+			continue
+		}
+		if entry != nil {
+			flushed++
+		}
+	}
+	return flushed, nil
+}
+
+func (s *BalanceLedgerService) reconcileScheduled() {
+	ctx, cancel := context.WithTimeout(context.Background(), balanceLedgerReconcileTimeout)
+	defer cancel()
+
+	// 多实例部署时每个周期只由一个实例对账，避免重复告警
+	if !s.tryAcquireReconcileLock(ctx) {
+		return
+	}
+	if _, err := s.Reconcile(ctx); err != nil {
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] reconcile failed: %v", err)
+	}
+}
+
+func (s *BalanceLedgerService) tryAcquireReconcileLock(ctx context.Context) bool {
+	if s.redisClient == nil {
+		return true
+	}
+	ttl := s.reconcileInterval - 5*time.Second
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	ok, err := s.redisClient.SetNX(ctx, balanceLedgerReconcileLockKey, s.instanceID, ttl).Result()
+	if err != nil {
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] reconcile lock failed; skipping this cycle: %v", err)
+		return false
+	}
+	return ok
+}
+
+// Reconcile 核对 users.balance 与账本；发现偏差时记录错误日志并通知配置的运维渠道
+func (s *BalanceLedgerService) Reconcile(ctx context.Context) (*BalanceReconcileResult, error) {
+	drifts, err := s.repo.FindDrift(ctx, s.tolerance, balanceLedgerDriftReportLimit)
+	if err != nil {
+		return nil, err
+	}
+	result := &BalanceReconcileResult{CheckedAt: time.Now(), Tolerance: s.tolerance, Drifts: drifts}
+	if result.Drifts == nil {
+		result.Drifts = []BalanceDrift{}
+	}
+	if len(drifts) == 0 {
+		return result, nil
+	}
+
+	for _, d := range drifts {
+		logger.LegacyPrintf("service.balance_ledger", "[BalanceLedger] balance drift: user=%d balance=%.8f ledger=%.8f pending=%.8f drift=%.8f", d.UserID, d.Balance, d.LedgerTotal, d.PendingUsage, d.Drift)
+	}
+	if s.notificationService != nil && len(s.alertChannelIDs) > 0 {
+		s.notificationService.DispatchAsync(s.alertChannelIDs, buildBalanceDriftNotification(result))
+	}
+	return result, nil
+}
+
+func buildBalanceDriftNotification(result *BalanceReconcileResult) *OpsNotificationMessage {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d user(s) have a balance that does not match the ledger (tolerance %.8f).\n", len(result.Drifts), result.Tolerance)
+	for i, d := range result.Drifts {
+		if i >= balanceLedgerDriftAlertMaxUsers {
+			fmt.Fprintf(&b, "... and %d more\n", len(result.Drifts)-i)
+			break
+		}
+		fmt.Fprintf(&b, "user #%d %s: balance=%.8f expected=%.8f drift=%.8f\n", d.UserID, d.Email, d.Balance, d.LedgerTotal-d.PendingUsage, d.Drift)
+	}
+	firedAt := result.CheckedAt
+	return &OpsNotificationMessage{
+		Source:   OpsNotificationSourceBalanceDrift,
+		Title:    "Balance ledger drift detected",
+		Text:     b.String(),
+		Severity: "P1",
+		Status:   "firing",
+		FiredAt:  &firedAt,
+		Data:     result,
+	}
+}
+
+// GetStatement 返回用户账单；filter 同时作用于流水明细与按类型汇总
+func (s *BalanceLedgerService) GetStatement(ctx context.Context, userID int64, params pagination.PaginationParams, filter BalanceLedgerFilter) (*BalanceStatement, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, result, err := s.repo.ListByUser(ctx, userID, params, filter)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.SummarizeByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.GetPendingUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	statement := &BalanceStatement{
+		Balance:      user.Balance,
+		PendingUsage: pending,
+		Totals:       totals,
+		Entries:      entries,
+	}
+	if result != nil {
+		statement.Total = result.Total
+	}
+	return statement, nil
+}
+
+// ListEntries 分页返回用户的余额流水（管理员查询）
+func (s *BalanceLedgerService) ListEntries(ctx context.Context, userID int64, params pagination.PaginationParams, filter BalanceLedgerFilter) ([]BalanceLedgerEntry, *pagination.PaginationResult, error) {
+	return s.repo.ListByUser(ctx, userID, params, filter)
+}
@@ -0,0 +1,87 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type balanceLedgerFlushRepoStub struct {
+	BalanceLedgerRepository
+	pending  []int64
+	failFor  map[int64]bool
+	flushed  []int64
+	drifts   []BalanceDrift
+	gotLimit int
+	gotTol   float64
+}
+
+func (s *balanceLedgerFlushRepoStub) ListPendingUsageUsers(_ context.Context, limit int) ([]int64, error) {
+	s.gotLimit = limit
+	return s.pending, nil
+}
+
+func (s *balanceLedgerFlushRepoStub) FlushPendingUsage(_ context.Context, userID int64) (*BalanceLedgerEntry, error) {
+	if s.failFor[userID] {
+		return nil, errors.New("boom")
+	}
+	s.flushed = append(s.flushed, userID)
+	if userID == 3 {
+		// 其它实例已汇总
+		return nil, nil
+	}
+	return &BalanceLedgerEntry{UserID: userID, EntryType: BalanceLedgerEntryUsage}, nil
+}
+
+func (s *balanceLedgerFlushRepoStub) FindDrift(_ context.Context, tolerance float64, _ int) ([]BalanceDrift, error) {
+	s.gotTol = tolerance
+	return s.drifts, nil
+}
+
+func TestBalanceLedgerService_FlushPendingUsageSkipsFailures(t *testing.T) {
+	repo := &balanceLedgerFlushRepoStub{pending: []int64{1, 2, 3}, failFor: map[int64]bool{2: true}}
+	svc := NewBalanceLedgerService(repo, nil, nil, nil, &config.Config{BalanceLedger: config.BalanceLedgerConfig{UsageFlushBatchSize: 50}})
+
+	flushed, err := svc.FlushPendingUsage(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, flushed)
+	require.Equal(t, []int64{1, 3}, repo.flushed)
+	require.Equal(t, 50, repo.gotLimit)
+}
+
+func TestBalanceLedgerService_ReconcileNoDrift(t *testing.T) {
+	repo := &balanceLedgerFlushRepoStub{}
+	svc := NewBalanceLedgerService(repo, nil, nil, nil, &config.Config{BalanceLedger: config.BalanceLedgerConfig{DriftTolerance: 0.01}})
+
+	result, err := svc.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, result.Drifts)
+	require.Empty(t, result.Drifts)
+	require.Equal(t, 0.01, repo.gotTol)
+}
+
+func TestBuildBalanceDriftNotification(t *testing.T) {
+	result := &BalanceReconcileResult{
+		CheckedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tolerance: 0.000001,
+	}
+	for i := int64(1); i <= balanceLedgerDriftAlertMaxUsers+2; i++ {
+		result.Drifts = append(result.Drifts, BalanceDrift{UserID: i, Email: "u@example.com", Balance: 10, LedgerTotal: 12, PendingUsage: 1, Drift: -1})
+	}
+
+	msg := buildBalanceDriftNotification(result)
+	require.Equal(t, OpsNotificationSourceBalanceDrift, msg.Source)
+	require.Equal(t, "P1", msg.Severity)
+	require.Equal(t, "firing", msg.Status)
+	require.Equal(t, result.CheckedAt, *msg.FiredAt)
+	require.Contains(t, msg.Text, "12 user(s)")
+	require.Contains(t, msg.Text, "user #1 u@example.com: balance=10.00000000 expected=11.00000000 drift=-1.00000000")
+	require.Contains(t, msg.Text, "... and 2 more")
+	require.NotContains(t, msg.Text, "user #11 ")
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Batch 任务状态（与 OpenAI Batch API 保持一致）
+const (
+	BatchStatusValidating = "validating"
+	BatchStatusFailed     = "failed"
+	BatchStatusInProgress = "in_progress"
+	BatchStatusFinalizing = "finalizing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusExpired    = "expired"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+)
+
+// Batch 文件用途
+const (
+	BatchFilePurposeBatch       = "batch"
+	BatchFilePurposeBatchOutput = "batch_output"
+	// BatchFilePurposeMessageBatch Anthropic Message Batches 的内部输入/结果文件，不出现在 /v1/files 列表中
+	BatchFilePurposeMessageBatch = "message_batch"
+)
+
+// Batch 任务的 API 格式：决定 ID 前缀、结果文件格式与对外暴露的接口
+const (
+	BatchAPIFormatOpenAI    = "openai"
+	BatchAPIFormatAnthropic = "anthropic"
+)
+
+// BatchCompletionWindow 目前仅支持 24h（与 OpenAI 一致）
+const BatchCompletionWindow = "24h"
+
+// BatchSupportedEndpoints 允许在 batch 中执行的网关端点
+var BatchSupportedEndpoints = []string{
+	"/v1/responses",
+	"/v1/chat/completions",
+	"/v1/messages",
+	"/v1/embeddings",
+}
+
+// IsBatchSupportedEndpoint 判断 endpoint 是否允许在 batch 中执行。
+func IsBatchSupportedEndpoint(endpoint string) bool {
+	for _, supported := range BatchSupportedEndpoints {
+		if endpoint == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchFile 表示通过 /v1/files 上传或由 batch 生成的文件
+type BatchFile struct {
+	ID         string
+	UserID     int64
+	APIKeyID   int64
+	Purpose    string
+	Filename   string
+	Bytes      int64
+	StorageKey string
+	CreatedAt  time.Time
+}
+
+// BatchError 表示 batch 校验阶段的错误（对应 OpenAI batch.errors.data）
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    *int   `json:"line,omitempty"`
+}
+
+// BatchJob 表示一个批处理任务
+type BatchJob struct {
+	ID               string
+	APIFormat        string
+	UserID           int64
+	APIKeyID         int64
+	GroupID          *int64
+	Endpoint         string
+	InputFileID      string
+	OutputFileID     *string
+	ErrorFileID      *string
+	CompletionWindow string
+	Status           string
+	TotalCount       int
+	CompletedCount   int
+	FailedCount      int
+	Metadata         map[string]string
+	Errors           []BatchError
+	ClientIP         string
+	CreatedAt        time.Time
+	InProgressAt     *time.Time
+	FinalizingAt     *time.Time
+	CompletedAt      *time.Time
+	FailedAt         *time.Time
+	CancellingAt     *time.Time
+	CancelledAt      *time.Time
+	ExpiredAt        *time.Time
+	ExpiresAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// BatchJobResult 表示任务结束时需要落库的结果
+type BatchJobResult struct {
+	Status         string
+	OutputFileID   *string
+	ErrorFileID    *string
+	TotalCount     int
+	CompletedCount int
+	FailedCount    int
+	Errors         []BatchError
+}
+
+// BatchRepository 定义 batch 文件与任务的持久层接口
+type BatchRepository interface {
+	CreateFile(ctx context.Context, file *BatchFile) error
+	// GetFile 查询用户文件；不存在或已删除返回 sql.ErrNoRows
+	GetFile(ctx context.Context, userID int64, fileID string) (*BatchFile, error)
+	ListFiles(ctx context.Context, userID int64, purpose string, after string, limit int) ([]BatchFile, error)
+	DeleteFile(ctx context.Context, userID int64, fileID string) (bool, error)
+
+	CreateJob(ctx context.Context, job *BatchJob) error
+	// GetJob 查询用户任务；不存在返回 sql.ErrNoRows
+	GetJob(ctx context.Context, userID int64, batchID string) (*BatchJob, error)
+	ListJobs(ctx context.Context, userID int64, apiFormat string, after string, limit int) ([]BatchJob, error)
+	// ClaimNextJob 抢占下一条 validating 任务并置为 in_progress；无任务返回 nil
+	ClaimNextJob(ctx context.Context) (*BatchJob, error)
+	// GetJobStatus 查询任务状态；不存在返回 sql.ErrNoRows
+	GetJobStatus(ctx context.Context, batchID string) (string, error)
+	// UpdateJobProgress 更新任务进度，同时刷新 updated_at 作为心跳
+	UpdateJobProgress(ctx context.Context, batchID string, total, completed, failed int) error
+	// RequestCancel validating 直接置为 cancelled，in_progress 置为 cancelling；返回更新后的状态
+	RequestCancel(ctx context.Context, userID int64, batchID string) (string, bool, error)
+	// FinishJob 写入终态（completed/failed/cancelled/expired）
+	FinishJob(ctx context.Context, batchID string, result *BatchJobResult) error
+	// FailStaleJobs 将长时间无心跳的 in_progress/cancelling 任务标记为 failed（进程退出/崩溃）
+	FailStaleJobs(ctx context.Context, staleAfterSeconds int64, message string) (int64, error)
+}
+
+// BatchFileStore 定义 batch 文件内容的存储接口（本地磁盘或 S3）
+type BatchFileStore interface {
+	Put(ctx context.Context, key string, body io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// BatchBillingInfo 标记一次请求来自 batch 执行器，计费时按 Multiplier 打折
+type BatchBillingInfo struct {
+	BatchID    string
+	CustomID   string
+	Multiplier float64
+}
+
+type batchBillingContextKey struct{}
+
+// WithBatchBilling 在 context 中标记 batch 计费信息。
+func WithBatchBilling(ctx context.Context, info *BatchBillingInfo) context.Context {
+	if ctx == nil || info == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, batchBillingContextKey{}, info)
+}
+
+// BatchBillingFromContext 读取 batch 计费信息；非 batch 请求返回 nil。
+func BatchBillingFromContext(ctx context.Context) *BatchBillingInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(batchBillingContextKey{}).(*BatchBillingInfo)
+	return info
+}
+
+// applyBatchBillingDiscount 对 batch 请求的费用应用折扣倍率。
+// 同时缩放 usage log 中的费用字段，保证扣费与使用记录一致。
+func applyBatchBillingDiscount(ctx context.Context, usageLog *UsageLog, p *postUsageBillingParams) {
+	info := BatchBillingFromContext(ctx)
+	if info == nil || info.Multiplier < 0 || info.Multiplier == 1 {
+		return
+	}
+	scaleUsageBillingCost(usageLog, p, info.Multiplier)
+}
+
+// scaleUsageBillingCost 按倍率缩放扣费金额，并同步 usage log 中的费用字段。
+func scaleUsageBillingCost(usageLog *UsageLog, p *postUsageBillingParams, m float64) {
+	if p == nil || p.Cost == nil {
+		return
+	}
+	discounted := *p.Cost
+	discounted.InputCost *= m
+	discounted.OutputCost *= m
+	discounted.CacheCreationCost *= m
+	discounted.CacheReadCost *= m
+	discounted.TotalCost *= m
+	discounted.ActualCost *= m
+	p.Cost = &discounted
+
+	if usageLog != nil {
+		usageLog.InputCost = discounted.InputCost
+		usageLog.OutputCost = discounted.OutputCost
+		usageLog.CacheCreationCost = discounted.CacheCreationCost
+		usageLog.CacheReadCost = discounted.CacheReadCost
+		usageLog.TotalCost = discounted.TotalCost
+		usageLog.ActualCost = discounted.ActualCost
+	}
+}
+
+// tagUsageLogWithBatch 在使用记录上标记 batch ID、custom_id 与折扣倍率。
+func tagUsageLogWithBatch(ctx context.Context, usageLog *UsageLog) {
+	info := BatchBillingFromContext(ctx)
+	if info == nil || usageLog == nil {
+		return
+	}
+	batchID := info.BatchID
+	customID := info.CustomID
+	multiplier := info.Multiplier
+	usageLog.BatchID = &batchID
+	usageLog.BatchCustomID = &customID
+	usageLog.BatchMultiplier = &multiplier
+}
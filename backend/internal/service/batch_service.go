@@ -0,0 +1,1115 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	batchWorkerName = "openai_batch_worker"
+
+	batchMaxReportedErrors   = 100
+	batchInterruptedMessage  = "batch execution was interrupted"
+	batchDefaultListLimit    = 20
+	batchMaxListLimit        = 100
+	batchResultBodyReadLimit = 32 << 20
+	batchMaxRequestAttempts  = 3
+)
+
+// batchRetryBackoff 可重试响应（429/503/529）的基础退避时间，按尝试次数线性递增
+var batchRetryBackoff = 2 * time.Second
+
+// batchMonitorInterval 执行中任务的心跳与取消/过期检测间隔
+var batchMonitorInterval = 5 * time.Second
+
+var (
+	ErrBatchDisabled      = infraerrors.ServiceUnavailable("BATCH_DISABLED", "batch API is disabled")
+	ErrBatchFileNotFound  = infraerrors.NotFound("BATCH_FILE_NOT_FOUND", "file not found")
+	ErrBatchJobNotFound   = infraerrors.NotFound("BATCH_NOT_FOUND", "batch not found")
+	ErrBatchFileTooLarge  = infraerrors.New(http.StatusRequestEntityTooLarge, "BATCH_FILE_TOO_LARGE", "file exceeds the maximum allowed size")
+	ErrBatchCancelInvalid = infraerrors.Conflict("BATCH_CANCEL_CONFLICT", "batch cannot be cancelled in current status")
+)
+
+// BatchFileUploadInput /v1/files 上传参数
+type BatchFileUploadInput struct {
+	UserID   int64
+	APIKeyID int64
+	Filename string
+	Purpose  string
+	Size     int64
+	Body     io.Reader
+}
+
+// BatchCreateInput /v1/batches 创建参数
+type BatchCreateInput struct {
+	UserID           int64
+	APIKeyID         int64
+	GroupID          *int64
+	GroupPlatform    string
+	ClientIP         string
+	InputFileID      string
+	Endpoint         string
+	CompletionWindow string
+	Metadata         map[string]string
+}
+
+// batchRequestLine 输入 JSONL 中的一行
+type batchRequestLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// batchResultLine 输出/错误 JSONL 中的一行
+type batchResultLine struct {
+	ID       string               `json:"id"`
+	CustomID string               `json:"custom_id"`
+	Response *batchResultResponse `json:"response"`
+	Error    *BatchError          `json:"error"`
+}
+
+type batchResultResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// BatchService 负责 Files/Batches API 与后台批处理执行。
+// 每条请求通过网关路由（SetRequestHandler 注入）以调用方 API Key 重放，
+// 因此与交互请求共享鉴权、分组路由、账号调度与计费逻辑。
+type BatchService struct {
+	repo        BatchRepository
+	store       BatchFileStore
+	apiKeyRepo  APIKeyRepository
+	timingWheel *TimingWheelService
+	cfg         *config.Config
+
+	handlerMu sync.RWMutex
+	handler   http.Handler
+
+	running    int32
+	activeJobs int32
+	jobsWG     sync.WaitGroup
+
+	groupSemMu sync.Mutex
+	groupSems  map[int64]chan struct{}
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	workerCtx    context.Context
+	workerCancel context.CancelFunc
+}
+
+func NewBatchService(repo BatchRepository, store BatchFileStore, apiKeyRepo APIKeyRepository, timingWheel *TimingWheelService, cfg *config.Config) *BatchService {
+	workerCtx, workerCancel := context.WithCancel(context.Background())
+	return &BatchService{
+		repo:         repo,
+		store:        store,
+		apiKeyRepo:   apiKeyRepo,
+		timingWheel:  timingWheel,
+		cfg:          cfg,
+		groupSems:    make(map[int64]chan struct{}),
+		workerCtx:    workerCtx,
+		workerCancel: workerCancel,
+	}
+}
+
+// SetRequestHandler 注入用于重放 batch 请求的 HTTP handler（网关路由）。
+func (s *BatchService) SetRequestHandler(handler http.Handler) {
+	if s == nil {
+		return
+	}
+	s.handlerMu.Lock()
+	s.handler = handler
+	s.handlerMu.Unlock()
+}
+
+func (s *BatchService) requestHandler() http.Handler {
+	s.handlerMu.RLock()
+	defer s.handlerMu.RUnlock()
+	return s.handler
+}
+
+func (s *BatchService) Start() {
+	if s == nil {
+		return
+	}
+	if !s.enabled() {
+		logger.LegacyPrintf("service.batch", "[Batch] not started (disabled)")
+		return
+	}
+	if s.repo == nil || s.store == nil || s.timingWheel == nil {
+		logger.LegacyPrintf("service.batch", "[Batch] not started (missing deps)")
+		return
+	}
+
+	interval := s.workerInterval()
+	s.startOnce.Do(func() {
+		s.timingWheel.ScheduleRecurring(batchWorkerName, interval, s.runOnce)
+		logger.LegacyPrintf("service.batch", "[Batch] started (interval=%s max_concurrent_jobs=%d group_concurrency=%d discount=%.4f)", interval, s.maxConcurrentJobs(), s.groupConcurrency(), s.discountMultiplier())
+	})
+}
+
+func (s *BatchService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		if s.timingWheel != nil {
+			s.timingWheel.Cancel(batchWorkerName)
+		}
+		if s.workerCancel != nil {
+			s.workerCancel()
+		}
+		s.jobsWG.Wait()
+		logger.LegacyPrintf("service.batch", "[Batch] stopped")
+	})
+}
+
+// MaxFileSizeBytes 返回单个上传文件的大小上限。
+func (s *BatchService) MaxFileSizeBytes() int64 {
+	if s == nil || s.cfg == nil || s.cfg.Batch.MaxFileSizeMB <= 0 {
+		return 200 << 20
+	}
+	return int64(s.cfg.Batch.MaxFileSizeMB) << 20
+}
+
+// ─── Files ───
+
+func (s *BatchService) UploadFile(ctx context.Context, input *BatchFileUploadInput) (*BatchFile, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	if input == nil || input.Body == nil {
+		return nil, infraerrors.BadRequest("BATCH_FILE_REQUIRED", "file is required")
+	}
+	if input.Purpose != BatchFilePurposeBatch {
+		return nil, infraerrors.BadRequest("BATCH_FILE_INVALID_PURPOSE", "purpose must be 'batch'")
+	}
+	maxSize := s.MaxFileSizeBytes()
+	if input.Size > maxSize {
+		return nil, ErrBatchFileTooLarge
+	}
+
+	fileID := "file-" + randomHex(12)
+	key := batchFileStorageKey(input.UserID, fileID)
+	size, err := s.store.Put(ctx, key, io.LimitReader(input.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("store batch file: %w", err)
+	}
+	if size > maxSize {
+		_ = s.store.Delete(ctx, key)
+		return nil, ErrBatchFileTooLarge
+	}
+
+	file := &BatchFile{
+		ID:         fileID,
+		UserID:     input.UserID,
+		APIKeyID:   input.APIKeyID,
+		Purpose:    BatchFilePurposeBatch,
+		Filename:   sanitizeBatchFilename(input.Filename),
+		Bytes:      size,
+		StorageKey: key,
+	}
+	if err := s.repo.CreateFile(ctx, file); err != nil {
+		_ = s.store.Delete(ctx, key)
+		return nil, fmt.Errorf("create batch file: %w", err)
+	}
+	return file, nil
+}
+
+func (s *BatchService) GetFile(ctx context.Context, userID int64, fileID string) (*BatchFile, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	file, err := s.repo.GetFile(ctx, userID, strings.TrimSpace(fileID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBatchFileNotFound
+		}
+		return nil, err
+	}
+	// Message Batches 的内部文件只能通过 /v1/messages/batches 访问
+	if file.Purpose == BatchFilePurposeMessageBatch {
+		return nil, ErrBatchFileNotFound
+	}
+	return file, nil
+}
+
+// ListFiles 按创建时间倒序列出文件；第二个返回值表示是否还有更多数据。
+func (s *BatchService) ListFiles(ctx context.Context, userID int64, purpose string, after string, limit int) ([]BatchFile, bool, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, false, err
+	}
+	limit = normalizeBatchListLimit(limit)
+	files, err := s.repo.ListFiles(ctx, userID, strings.TrimSpace(purpose), strings.TrimSpace(after), limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(files) > limit {
+		return files[:limit], true, nil
+	}
+	return files, false, nil
+}
+
+// OpenFileContent 打开文件内容，调用方负责关闭。
+func (s *BatchService) OpenFileContent(ctx context.Context, userID int64, fileID string) (*BatchFile, io.ReadCloser, error) {
+	file, err := s.GetFile(ctx, userID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := s.store.Open(ctx, file.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open batch file: %w", err)
+	}
+	return file, rc, nil
+}
+
+func (s *BatchService) DeleteFile(ctx context.Context, userID int64, fileID string) error {
+	file, err := s.GetFile(ctx, userID, fileID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.DeleteFile(ctx, userID, file.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBatchFileNotFound
+	}
+	if err := s.store.Delete(ctx, file.StorageKey); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] delete file content failed: file=%s err=%v", file.ID, err)
+	}
+	return nil
+}
+
+// ─── Batches ───
+
+func (s *BatchService) CreateBatch(ctx context.Context, input *BatchCreateInput) (*BatchJob, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return nil, infraerrors.BadRequest("BATCH_INVALID_REQUEST", "invalid request")
+	}
+	endpoint := strings.TrimSpace(input.Endpoint)
+	if !IsBatchSupportedEndpoint(endpoint) {
+		return nil, infraerrors.BadRequest("BATCH_UNSUPPORTED_ENDPOINT", "endpoint must be one of: "+strings.Join(BatchSupportedEndpoints, ", "))
+	}
+	if endpoint == "/v1/embeddings" && input.GroupPlatform != PlatformOpenAI {
+		return nil, infraerrors.BadRequest("BATCH_UNSUPPORTED_ENDPOINT", "embeddings are not supported for this platform")
+	}
+	window := strings.TrimSpace(input.CompletionWindow)
+	if window == "" {
+		window = BatchCompletionWindow
+	}
+	if window != BatchCompletionWindow {
+		return nil, infraerrors.BadRequest("BATCH_INVALID_COMPLETION_WINDOW", "completion_window must be '24h'")
+	}
+	if len(input.Metadata) > 16 {
+		return nil, infraerrors.BadRequest("BATCH_INVALID_METADATA", "metadata supports at most 16 keys")
+	}
+
+	file, err := s.GetFile(ctx, input.UserID, input.InputFileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.Purpose != BatchFilePurposeBatch {
+		return nil, infraerrors.BadRequest("BATCH_INVALID_INPUT_FILE", "input file must have purpose 'batch'")
+	}
+
+	now := time.Now()
+	job := &BatchJob{
+		ID:               "batch_" + randomHex(12),
+		APIFormat:        BatchAPIFormatOpenAI,
+		UserID:           input.UserID,
+		APIKeyID:         input.APIKeyID,
+		GroupID:          input.GroupID,
+		Endpoint:         endpoint,
+		InputFileID:      file.ID,
+		CompletionWindow: window,
+		Status:           BatchStatusValidating,
+		Metadata:         input.Metadata,
+		ClientIP:         strings.TrimSpace(input.ClientIP),
+		ExpiresAt:        now.Add(24 * time.Hour),
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("create batch job: %w", err)
+	}
+	logger.LegacyPrintf("service.batch", "[Batch] created: batch=%s user=%d api_key=%d endpoint=%s input_file=%s", job.ID, job.UserID, job.APIKeyID, job.Endpoint, job.InputFileID)
+	go s.runOnce()
+	return job, nil
+}
+
+func (s *BatchService) GetBatch(ctx context.Context, userID int64, batchID string) (*BatchJob, error) {
+	return s.getJob(ctx, userID, batchID, BatchAPIFormatOpenAI)
+}
+
+// ListBatches 按创建时间倒序列出任务；第二个返回值表示是否还有更多数据。
+func (s *BatchService) ListBatches(ctx context.Context, userID int64, after string, limit int) ([]BatchJob, bool, error) {
+	return s.listJobs(ctx, userID, BatchAPIFormatOpenAI, after, limit)
+}
+
+// CancelBatch 请求取消任务：未开始的任务直接取消，执行中的任务进入 cancelling，
+// 由执行器停止派发新请求并在已派发请求完成后写入 cancelled。
+func (s *BatchService) CancelBatch(ctx context.Context, userID int64, batchID string) (*BatchJob, error) {
+	return s.cancelJob(ctx, userID, batchID, BatchAPIFormatOpenAI)
+}
+
+// getJob 查询指定 API 格式的任务；格式不匹配视为不存在。
+func (s *BatchService) getJob(ctx context.Context, userID int64, batchID string, apiFormat string) (*BatchJob, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	job, err := s.repo.GetJob(ctx, userID, strings.TrimSpace(batchID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBatchJobNotFound
+		}
+		return nil, err
+	}
+	if job.APIFormat != apiFormat {
+		return nil, ErrBatchJobNotFound
+	}
+	return job, nil
+}
+
+func (s *BatchService) listJobs(ctx context.Context, userID int64, apiFormat string, after string, limit int) ([]BatchJob, bool, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, false, err
+	}
+	limit = normalizeBatchListLimit(limit)
+	jobs, err := s.repo.ListJobs(ctx, userID, apiFormat, strings.TrimSpace(after), limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(jobs) > limit {
+		return jobs[:limit], true, nil
+	}
+	return jobs, false, nil
+}
+
+func (s *BatchService) cancelJob(ctx context.Context, userID int64, batchID string, apiFormat string) (*BatchJob, error) {
+	job, err := s.getJob(ctx, userID, batchID, apiFormat)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case BatchStatusCancelling, BatchStatusCancelled:
+		return job, nil
+	case BatchStatusValidating, BatchStatusInProgress:
+	default:
+		return nil, ErrBatchCancelInvalid
+	}
+	if _, ok, err := s.repo.RequestCancel(ctx, userID, job.ID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrBatchCancelInvalid
+	}
+	logger.LegacyPrintf("service.batch", "[Batch] cancel requested: batch=%s user=%d status=%s", job.ID, userID, job.Status)
+	return s.getJob(ctx, userID, job.ID, apiFormat)
+}
+
+// ─── Worker ───
+
+func (s *BatchService) runOnce() {
+	if s == nil || !s.enabled() || s.repo == nil || s.store == nil {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.running, 0)
+
+	if s.requestHandler() == nil {
+		slog.Debug("[Batch] run_once skipped: request handler not ready")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.workerCtx, 30*time.Second)
+	defer cancel()
+
+	if n, err := s.repo.FailStaleJobs(ctx, int64(s.staleJobTimeout().Seconds()), batchInterruptedMessage); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] fail stale jobs failed: %v", err)
+	} else if n > 0 {
+		logger.LegacyPrintf("service.batch", "[Batch] stale jobs marked failed: count=%d", n)
+	}
+
+	for int(atomic.LoadInt32(&s.activeJobs)) < s.maxConcurrentJobs() {
+		if s.workerCtx.Err() != nil {
+			return
+		}
+		job, err := s.repo.ClaimNextJob(ctx)
+		if err != nil {
+			logger.LegacyPrintf("service.batch", "[Batch] claim job failed: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		atomic.AddInt32(&s.activeJobs, 1)
+		s.jobsWG.Add(1)
+		go func() {
+			defer s.jobsWG.Done()
+			defer atomic.AddInt32(&s.activeJobs, -1)
+			s.executeJob(job)
+		}()
+	}
+}
+
+func (s *BatchService) executeJob(job *BatchJob) {
+	ctx := s.workerCtx
+	start := time.Now()
+	logger.LegacyPrintf("service.batch", "[Batch] job started: batch=%s user=%d endpoint=%s", job.ID, job.UserID, job.Endpoint)
+
+	total, validationErrs, err := s.validateInput(ctx, job)
+	if err != nil {
+		s.finishJob(job, &BatchJobResult{Status: BatchStatusFailed, Errors: []BatchError{{Code: "input_file_unavailable", Message: err.Error()}}})
+		return
+	}
+	if len(validationErrs) > 0 {
+		s.finishJob(job, &BatchJobResult{Status: BatchStatusFailed, TotalCount: total, Errors: validationErrs})
+		return
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, job.APIKeyID)
+	if err != nil || apiKey == nil || apiKey.Key == "" {
+		s.finishJob(job, &BatchJobResult{Status: BatchStatusFailed, TotalCount: total, Errors: []BatchError{{Code: "api_key_unavailable", Message: "the API key that created this batch is no longer available"}}})
+		return
+	}
+
+	out, err := newBatchResultWriter(job)
+	if err != nil {
+		s.finishJob(job, &BatchJobResult{Status: BatchStatusFailed, TotalCount: total, Errors: []BatchError{{Code: "internal_error", Message: "failed to prepare output file"}}})
+		return
+	}
+	defer out.cleanup()
+
+	_ = s.repo.UpdateJobProgress(ctx, job.ID, total, 0, 0)
+
+	// 监控：定期心跳、检测取消与过期；runCtx 同时作为每条请求的上下文，取消或过期时中止执行中的请求
+	runCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+	var stopReason atomic.Value
+	monitorDone := make(chan struct{})
+	go func() {
+		defer close(monitorDone)
+		ticker := time.NewTicker(batchMonitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			completed, failed := out.counts()
+			if err := s.repo.UpdateJobProgress(ctx, job.ID, total, completed, failed); err != nil {
+				logger.LegacyPrintf("service.batch", "[Batch] update progress failed: batch=%s err=%v", job.ID, err)
+			}
+			if time.Now().After(job.ExpiresAt) {
+				stopReason.Store(BatchStatusExpired)
+				stopDispatch()
+				return
+			}
+			if status, err := s.repo.GetJobStatus(ctx, job.ID); err == nil && status == BatchStatusCancelling {
+				stopReason.Store(BatchStatusCancelled)
+				stopDispatch()
+				return
+			}
+		}
+	}()
+
+	sem := s.groupSemaphore(job.GroupID)
+	var inflight sync.WaitGroup
+	dispatchErr := s.scanInput(runCtx, job, func(_ int, line *batchRequestLine) error {
+		select {
+		case sem <- struct{}{}:
+		case <-runCtx.Done():
+			return runCtx.Err()
+		}
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+			defer func() { <-sem }()
+			if result := s.executeRequest(runCtx, job, apiKey.Key, line); result != nil {
+				out.write(result)
+			}
+		}()
+		return nil
+	})
+	inflight.Wait()
+	stopDispatch()
+	<-monitorDone
+
+	status := BatchStatusCompleted
+	var jobErrs []BatchError
+	switch {
+	case ctx.Err() != nil:
+		status = BatchStatusFailed
+		jobErrs = []BatchError{{Code: "interrupted", Message: batchInterruptedMessage}}
+	case stopReason.Load() != nil:
+		status = stopReason.Load().(string)
+	case dispatchErr != nil:
+		status = BatchStatusFailed
+		jobErrs = []BatchError{{Code: "input_file_unavailable", Message: dispatchErr.Error()}}
+	}
+
+	if job.APIFormat == BatchAPIFormatAnthropic {
+		s.fillUnprocessedResults(job, out, status)
+	}
+
+	result := &BatchJobResult{Status: status, TotalCount: total, Errors: jobErrs}
+	result.CompletedCount, result.FailedCount = out.counts()
+	result.OutputFileID, result.ErrorFileID = s.uploadResults(job, out)
+	s.finishJob(job, result)
+	logger.LegacyPrintf("service.batch", "[Batch] job finished: batch=%s status=%s total=%d completed=%d failed=%d duration=%s", job.ID, status, total, result.CompletedCount, result.FailedCount, time.Since(start))
+}
+
+// executeRequest 通过网关路由重放一条 batch 请求。
+// 账号调度与并发槽位由网关处理；无可用账号或槽位（429/503/529）时退避重试。
+// 任务被取消、过期或中断导致请求未成功完成时返回 nil，该请求视为未处理。
+func (s *BatchService) executeRequest(ctx context.Context, job *BatchJob, rawKey string, line *batchRequestLine) *batchResultLine {
+	result := &batchResultLine{ID: "batch_req_" + randomHex(12), CustomID: line.CustomID}
+
+	body := []byte(line.Body)
+	if gjson.GetBytes(body, "stream").Bool() {
+		if updated, err := sjson.SetBytes(body, "stream", false); err == nil {
+			body = updated
+		}
+	}
+
+	var rec *httptest.ResponseRecorder
+	for attempt := 1; ; attempt++ {
+		var err error
+		rec, err = s.replayRequest(ctx, job, rawKey, line.CustomID, body)
+		if err != nil {
+			result.Error = &BatchError{Code: "internal_error", Message: "failed to build request"}
+			return result
+		}
+		if !isBatchRetryableStatus(rec.Code) || attempt >= batchMaxRequestAttempts {
+			break
+		}
+		timer := time.NewTimer(time.Duration(attempt) * batchRetryBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// 上下文已结束时，仅保留完整成功的响应；被中止的请求（非 2xx 或未写出响应体）留待未处理结果补齐
+	if ctx.Err() != nil && (rec.Code < 200 || rec.Code >= 300 || rec.Body.Len() == 0) {
+		return nil
+	}
+
+	respBody := rec.Body.Bytes()
+	if len(respBody) > batchResultBodyReadLimit {
+		respBody = respBody[:batchResultBodyReadLimit]
+	}
+	result.Response = &batchResultResponse{
+		StatusCode: rec.Code,
+		RequestID:  rec.Header().Get("x-request-id"),
+		Body:       normalizeBatchResponseBody(respBody),
+	}
+	if result.Response.RequestID == "" {
+		result.Response.RequestID = gjson.GetBytes(respBody, "id").String()
+	}
+	return result
+}
+
+// replayRequest 以调用方 API Key 执行一次网关请求。
+func (s *BatchService) replayRequest(ctx context.Context, job *BatchJob, rawKey, customID string, body []byte) (*httptest.ResponseRecorder, error) {
+	reqCtx, cancel := context.WithTimeout(WithBatchBilling(ctx, &BatchBillingInfo{
+		BatchID:    job.ID,
+		CustomID:   customID,
+		Multiplier: s.jobDiscountMultiplier(job),
+	}), s.requestTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, job.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	clientIP := job.ClientIP
+	if clientIP == "" {
+		clientIP = "127.0.0.1"
+	}
+	req.RemoteAddr = net.JoinHostPort(clientIP, "0")
+	req.Header.Set("Authorization", "Bearer "+rawKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", clientIP)
+	req.Header.Set("User-Agent", "sub2api-batch")
+	if job.Endpoint == "/v1/messages" {
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+
+	rec := httptest.NewRecorder()
+	s.requestHandler().ServeHTTP(rec, req)
+	return rec, nil
+}
+
+func isBatchRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable || status == 529
+}
+
+func (s *BatchService) finishJob(job *BatchJob, result *BatchJobResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.repo.FinishJob(ctx, job.ID, result); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] finish job failed: batch=%s status=%s err=%v", job.ID, result.Status, err)
+	}
+}
+
+// uploadResults 上传输出/错误文件；即使任务被取消或中断，已完成的结果也会保留。
+func (s *BatchService) uploadResults(job *BatchJob, out *batchResultWriter) (*string, *string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	purpose := BatchFilePurposeBatchOutput
+	if job.APIFormat == BatchAPIFormatAnthropic {
+		purpose = BatchFilePurposeMessageBatch
+	}
+	upload := func(f *os.File, n int, suffix string) *string {
+		if f == nil || n == 0 {
+			return nil
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil
+		}
+		fileID := "file-" + randomHex(12)
+		key := batchFileStorageKey(job.UserID, fileID)
+		size, err := s.store.Put(ctx, key, f)
+		if err != nil {
+			logger.LegacyPrintf("service.batch", "[Batch] upload result failed: batch=%s err=%v", job.ID, err)
+			return nil
+		}
+		file := &BatchFile{
+			ID:         fileID,
+			UserID:     job.UserID,
+			APIKeyID:   job.APIKeyID,
+			Purpose:    purpose,
+			Filename:   job.ID + "_" + suffix + ".jsonl",
+			Bytes:      size,
+			StorageKey: key,
+		}
+		if err := s.repo.CreateFile(ctx, file); err != nil {
+			logger.LegacyPrintf("service.batch", "[Batch] create result file failed: batch=%s err=%v", job.ID, err)
+			_ = s.store.Delete(ctx, key)
+			return nil
+		}
+		return &fileID
+	}
+	completed, failed := out.counts()
+	if err := out.flush(); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] flush results failed: batch=%s err=%v", job.ID, err)
+	}
+	// Anthropic 格式所有结果写入同一个 results 文件
+	if job.APIFormat == BatchAPIFormatAnthropic {
+		return upload(out.outFile, out.written(), "results"), nil
+	}
+	return upload(out.outFile, completed, "output"), upload(out.errFile, failed, "error")
+}
+
+// validateInput 校验输入文件并返回请求总数；格式错误通过 []BatchError 返回。
+func (s *BatchService) validateInput(ctx context.Context, job *BatchJob) (int, []BatchError, error) {
+	var errs []BatchError
+	seen := make(map[string]struct{})
+	total := 0
+	maxRequests := s.maxRequestsPerBatch()
+	addErr := func(lineNo int, code, msg string) {
+		if len(errs) < batchMaxReportedErrors {
+			ln := lineNo
+			errs = append(errs, BatchError{Code: code, Message: msg, Line: &ln})
+		}
+	}
+	err := s.readInputLines(ctx, job, func(lineNo int, raw []byte) error {
+		total++
+		if total > maxRequests {
+			addErr(lineNo, "too_many_requests", fmt.Sprintf("batch input exceeds %d requests", maxRequests))
+			return errBatchStopScan
+		}
+		line, code, msg := parseBatchRequestLine(raw, job.Endpoint)
+		if line == nil {
+			addErr(lineNo, code, msg)
+			return nil
+		}
+		if _, dup := seen[line.CustomID]; dup {
+			addErr(lineNo, "duplicate_custom_id", "custom_id must be unique within a batch")
+			return nil
+		}
+		seen[line.CustomID] = struct{}{}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchStopScan) {
+		return 0, nil, err
+	}
+	if total == 0 {
+		errs = append(errs, BatchError{Code: "empty_file", Message: "input file contains no requests"})
+	}
+	return total, errs, nil
+}
+
+// scanInput 逐行解析输入文件（已通过校验）。
+func (s *BatchService) scanInput(ctx context.Context, job *BatchJob, fn func(lineNo int, line *batchRequestLine) error) error {
+	err := s.readInputLines(ctx, job, func(lineNo int, raw []byte) error {
+		line, _, _ := parseBatchRequestLine(raw, job.Endpoint)
+		if line == nil {
+			return nil
+		}
+		return fn(lineNo, line)
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
+
+var errBatchStopScan = errors.New("batch: stop scan")
+
+func (s *BatchService) readInputLines(ctx context.Context, job *BatchJob, fn func(lineNo int, raw []byte) error) error {
+	file, err := s.repo.GetFile(ctx, job.UserID, job.InputFileID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("input file %s not found", job.InputFileID)
+		}
+		return err
+	}
+	rc, err := s.store.Open(ctx, file.StorageKey)
+	if err != nil {
+		return fmt.Errorf("open input file: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	reader := bufio.NewReaderSize(rc, 64*1024)
+	lineNo := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		raw, readErr := reader.ReadBytes('\n')
+		if len(raw) > 0 {
+			lineNo++
+			raw = bytes.TrimSpace(raw)
+			if len(raw) > 0 {
+				if err := fn(lineNo, raw); err != nil {
+					return err
+				}
+			}
+		}
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read input file: %w", readErr)
+		}
+	}
+}
+
+// parseBatchRequestLine 解析并校验输入行；失败时返回错误码与描述。
+func parseBatchRequestLine(raw []byte, endpoint string) (*batchRequestLine, string, string) {
+	var line batchRequestLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return nil, "invalid_json_line", "line is not valid JSON"
+	}
+	line.CustomID = strings.TrimSpace(line.CustomID)
+	if line.CustomID == "" {
+		return nil, "missing_required_parameter", "custom_id is required"
+	}
+	if !strings.EqualFold(strings.TrimSpace(line.Method), http.MethodPost) {
+		return nil, "invalid_method", "method must be POST"
+	}
+	if strings.TrimSpace(line.URL) != endpoint {
+		return nil, "mismatched_endpoint", fmt.Sprintf("url must match the batch endpoint %s", endpoint)
+	}
+	if !gjson.ValidBytes(line.Body) || !gjson.ParseBytes(line.Body).IsObject() {
+		return nil, "invalid_body", "body must be a JSON object"
+	}
+	if gjson.GetBytes(line.Body, "model").String() == "" {
+		return nil, "missing_required_parameter", "body.model is required"
+	}
+	return &line, "", ""
+}
+
+func (s *BatchService) groupSemaphore(groupID *int64) chan struct{} {
+	var key int64
+	if groupID != nil {
+		key = *groupID
+	}
+	s.groupSemMu.Lock()
+	defer s.groupSemMu.Unlock()
+	sem, ok := s.groupSems[key]
+	if !ok {
+		sem = make(chan struct{}, s.groupConcurrency())
+		s.groupSems[key] = sem
+	}
+	return sem
+}
+
+func (s *BatchService) ensureReady() error {
+	if s == nil || s.repo == nil || s.store == nil {
+		return fmt.Errorf("batch service not ready")
+	}
+	if !s.enabled() {
+		return ErrBatchDisabled
+	}
+	return nil
+}
+
+func (s *BatchService) enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Batch.Enabled
+}
+
+func (s *BatchService) maxConcurrentJobs() int {
+	if s == nil || s.cfg == nil || s.cfg.Batch.MaxConcurrentJobs <= 0 {
+		return 2
+	}
+	return s.cfg.Batch.MaxConcurrentJobs
+}
+
+func (s *BatchService) groupConcurrency() int {
+	if s == nil || s.cfg == nil || s.cfg.Batch.GroupConcurrency <= 0 {
+		return 4
+	}
+	return s.cfg.Batch.GroupConcurrency
+}
+
+func (s *BatchService) maxRequestsPerBatch() int {
+	if s == nil || s.cfg == nil || s.cfg.Batch.MaxRequestsPerBatch <= 0 {
+		return 50000
+	}
+	return s.cfg.Batch.MaxRequestsPerBatch
+}
+
+func (s *BatchService) discountMultiplier() float64 {
+	if s == nil || s.cfg == nil || s.cfg.Batch.DiscountMultiplier < 0 {
+		return 1
+	}
+	return s.cfg.Batch.DiscountMultiplier
+}
+
+func (s *BatchService) messageBatchDiscountMultiplier() float64 {
+	if s == nil || s.cfg == nil || s.cfg.Batch.MessageBatchDiscountMultiplier < 0 {
+		return 1
+	}
+	return s.cfg.Batch.MessageBatchDiscountMultiplier
+}
+
+// jobDiscountMultiplier 按任务 API 格式选择计费折扣倍率。
+func (s *BatchService) jobDiscountMultiplier(job *BatchJob) float64 {
+	if job != nil && job.APIFormat == BatchAPIFormatAnthropic {
+		return s.messageBatchDiscountMultiplier()
+	}
+	return s.discountMultiplier()
+}
+
+func (s *BatchService) workerInterval() time.Duration {
+	if s == nil || s.cfg == nil || s.cfg.Batch.WorkerIntervalSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(s.cfg.Batch.WorkerIntervalSeconds) * time.Second
+}
+
+func (s *BatchService) requestTimeout() time.Duration {
+	if s == nil || s.cfg == nil || s.cfg.Batch.RequestTimeoutSeconds <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(s.cfg.Batch.RequestTimeoutSeconds) * time.Second
+}
+
+func (s *BatchService) staleJobTimeout() time.Duration {
+	timeout := 10 * time.Minute
+	if s != nil && s.cfg != nil && s.cfg.Batch.StaleJobSeconds > 0 {
+		timeout = time.Duration(s.cfg.Batch.StaleJobSeconds) * time.Second
+	}
+	// 心跳间隔为 batchMonitorInterval，阈值过小会误判执行中的任务
+	if timeout < 6*batchMonitorInterval {
+		timeout = 6 * batchMonitorInterval
+	}
+	return timeout
+}
+
+func normalizeBatchListLimit(limit int) int {
+	if limit <= 0 {
+		return batchDefaultListLimit
+	}
+	if limit > batchMaxListLimit {
+		return batchMaxListLimit
+	}
+	return limit
+}
+
+func batchFileStorageKey(userID int64, fileID string) string {
+	return fmt.Sprintf("%d/%s.jsonl", userID, fileID)
+}
+
+func sanitizeBatchFilename(name string) string {
+	name = strings.TrimSpace(name)
+	if idx := strings.LastIndexAny(name, `/\`); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if name == "" {
+		name = "batch.jsonl"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+// normalizeBatchResponseBody 非 JSON 响应体以 JSON 字符串形式保存。
+func normalizeBatchResponseBody(body []byte) json.RawMessage {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && gjson.ValidBytes(trimmed) {
+		return json.RawMessage(trimmed)
+	}
+	encoded, _ := json.Marshal(string(trimmed))
+	return json.RawMessage(encoded)
+}
+
+// batchResultWriter 将结果行写入临时文件，任务结束时统一上传。
+// OpenAI 格式按成功/失败分别写入输出与错误文件；Anthropic 格式全部写入输出文件，
+// 并记录已处理的 custom_id，用于结束时补齐 canceled/expired 结果。
+type batchResultWriter struct {
+	mu        sync.Mutex
+	apiFormat string
+	outFile   *os.File
+	errFile   *os.File
+	outWriter *bufio.Writer
+	errWriter *bufio.Writer
+	completed int
+	failed    int
+	filled    int
+	processed map[string]struct{}
+}
+
+func newBatchResultWriter(job *BatchJob) (*batchResultWriter, error) {
+	batchID := job.ID
+	outFile, err := os.CreateTemp("", batchID+"-output-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	errFile, err := os.CreateTemp("", batchID+"-error-*.jsonl")
+	if err != nil {
+		_ = outFile.Close()
+		_ = os.Remove(outFile.Name())
+		return nil, err
+	}
+	w := &batchResultWriter{
+		apiFormat: job.APIFormat,
+		outFile:   outFile,
+		errFile:   errFile,
+		outWriter: bufio.NewWriter(outFile),
+		errWriter: bufio.NewWriter(errFile),
+	}
+	if job.APIFormat == BatchAPIFormatAnthropic {
+		w.processed = make(map[string]struct{})
+	}
+	return w, nil
+}
+
+// write 2xx 响应写入输出文件，其余写入错误文件（Anthropic 格式统一写入输出文件）。
+func (w *batchResultWriter) write(line *batchResultLine) {
+	succeeded := line.Error == nil && line.Response != nil && line.Response.StatusCode >= 200 && line.Response.StatusCode < 300
+	var (
+		encoded []byte
+		err     error
+	)
+	if w.apiFormat == BatchAPIFormatAnthropic {
+		encoded, err = json.Marshal(newMessageBatchResult(line, succeeded))
+	} else {
+		encoded, err = json.Marshal(line)
+	}
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	target := w.errWriter
+	if succeeded || w.apiFormat == BatchAPIFormatAnthropic {
+		target = w.outWriter
+	}
+	if succeeded {
+		w.completed++
+	} else {
+		w.failed++
+	}
+	if w.processed != nil {
+		w.processed[line.CustomID] = struct{}{}
+	}
+	_, _ = target.Write(encoded)
+	_ = target.WriteByte('\n')
+}
+
+// writeUnprocessed 为未执行的请求写入结果（仅 Anthropic 格式）；已处理的 custom_id 会被跳过。
+func (w *batchResultWriter) writeUnprocessed(result *messageBatchResultLine) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, done := w.processed[result.CustomID]; done {
+		return
+	}
+	w.processed[result.CustomID] = struct{}{}
+	w.filled++
+	_, _ = w.outWriter.Write(encoded)
+	_ = w.outWriter.WriteByte('\n')
+}
+
+// written 返回输出文件中的总行数（Anthropic 格式）。
+func (w *batchResultWriter) written() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.completed + w.failed + w.filled
+}
+
+func (w *batchResultWriter) counts() (int, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.completed, w.failed
+}
+
+func (w *batchResultWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.outWriter.Flush(); err != nil {
+		return err
+	}
+	return w.errWriter.Flush()
+}
+
+func (w *batchResultWriter) cleanup() {
+	for _, f := range []*os.File{w.outFile, w.errFile} {
+		if f == nil {
+			continue
+		}
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,363 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// batchRepoStub 内存实现的 BatchRepository，仅覆盖执行器用到的行为
+type batchRepoStub struct {
+	mu       sync.Mutex
+	files    map[string]*BatchFile
+	jobs     map[string]*BatchJob
+	finished map[string]*BatchJobResult
+}
+
+func newBatchRepoStub() *batchRepoStub {
+	return &batchRepoStub{
+		files:    make(map[string]*BatchFile),
+		jobs:     make(map[string]*BatchJob),
+		finished: make(map[string]*BatchJobResult),
+	}
+}
+
+func (r *batchRepoStub) CreateFile(_ context.Context, file *BatchFile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clone := *file
+	r.files[file.ID] = &clone
+	return nil
+}
+
+func (r *batchRepoStub) GetFile(_ context.Context, userID int64, fileID string) (*BatchFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[fileID]
+	if !ok || f.UserID != userID {
+		return nil, sql.ErrNoRows
+	}
+	clone := *f
+	return &clone, nil
+}
+
+func (r *batchRepoStub) ListFiles(context.Context, int64, string, string, int) ([]BatchFile, error) {
+	return nil, nil
+}
+
+func (r *batchRepoStub) DeleteFile(context.Context, int64, string) (bool, error) { return false, nil }
+
+func (r *batchRepoStub) CreateJob(_ context.Context, job *BatchJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clone := *job
+	r.jobs[job.ID] = &clone
+	return nil
+}
+
+func (r *batchRepoStub) GetJob(_ context.Context, userID int64, batchID string) (*BatchJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[batchID]
+	if !ok || j.UserID != userID {
+		return nil, sql.ErrNoRows
+	}
+	clone := *j
+	return &clone, nil
+}
+
+func (r *batchRepoStub) ListJobs(context.Context, int64, string, string, int) ([]BatchJob, error) {
+	return nil, nil
+}
+
+func (r *batchRepoStub) ClaimNextJob(context.Context) (*BatchJob, error) { return nil, nil }
+
+func (r *batchRepoStub) GetJobStatus(_ context.Context, batchID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[batchID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return j.Status, nil
+}
+
+func (r *batchRepoStub) UpdateJobProgress(context.Context, string, int, int, int) error { return nil }
+
+func (r *batchRepoStub) RequestCancel(context.Context, int64, string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (r *batchRepoStub) FinishJob(_ context.Context, batchID string, result *BatchJobResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished[batchID] = result
+	if j, ok := r.jobs[batchID]; ok {
+		j.Status = result.Status
+		j.OutputFileID = result.OutputFileID
+		j.ErrorFileID = result.ErrorFileID
+	}
+	return nil
+}
+
+func (r *batchRepoStub) FailStaleJobs(context.Context, int64, string) (int64, error) { return 0, nil }
+
+type memoryBatchFileStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *memoryBatchFileStore) Put(_ context.Context, key string, body io.Reader) (int64, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = data
+	return int64(len(data)), nil
+}
+
+func (s *memoryBatchFileStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryBatchFileStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+func newBatchServiceForTest(t *testing.T) (*BatchService, *batchRepoStub, *memoryBatchFileStore) {
+	t.Helper()
+	cfg := &config.Config{Batch: config.BatchConfig{
+		Enabled:             true,
+		MaxFileSizeMB:       1,
+		MaxRequestsPerBatch: 10,
+		MaxConcurrentJobs:   1,
+		GroupConcurrency:    2,
+		DiscountMultiplier:  0.5,
+	}}
+	repo := newBatchRepoStub()
+	store := &memoryBatchFileStore{blobs: make(map[string][]byte)}
+	apiKeyRepo := &apiKeyRepoStub{apiKey: &APIKey{ID: 7, UserID: 1, Key: "sk-batch"}}
+	svc := NewBatchService(repo, store, apiKeyRepo, nil, cfg)
+	t.Cleanup(svc.Stop)
+	return svc, repo, store
+}
+
+func createBatchForTest(t *testing.T, svc *BatchService, endpoint, content string) *BatchJob {
+	t.Helper()
+	file, err := svc.UploadFile(context.Background(), &BatchFileUploadInput{
+		UserID:   1,
+		APIKeyID: 7,
+		Filename: "input.jsonl",
+		Purpose:  BatchFilePurposeBatch,
+		Size:     int64(len(content)),
+		Body:     strings.NewReader(content),
+	})
+	require.NoError(t, err)
+	groupID := int64(3)
+	job, err := svc.CreateBatch(context.Background(), &BatchCreateInput{
+		UserID:        1,
+		APIKeyID:      7,
+		GroupID:       &groupID,
+		GroupPlatform: PlatformOpenAI,
+		InputFileID:   file.ID,
+		Endpoint:      endpoint,
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func readBatchFileLines(t *testing.T, svc *BatchService, fileID *string) []string {
+	t.Helper()
+	require.NotNil(t, fileID)
+	_, rc, err := svc.OpenFileContent(context.Background(), 1, *fileID)
+	require.NoError(t, err)
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestBatchService_ExecuteJob_ReplaysThroughHandler(t *testing.T) {
+	svc, repo, _ := newBatchServiceForTest(t)
+
+	var mu sync.Mutex
+	var discounts []float64
+	svc.SetRequestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.Equal(t, "Bearer sk-batch", r.Header.Get("Authorization"))
+		require.False(t, gjson.GetBytes(body, "stream").Bool())
+		info := BatchBillingFromContext(r.Context())
+		require.NotNil(t, info)
+		mu.Lock()
+		discounts = append(discounts, info.Multiplier)
+		mu.Unlock()
+
+		w.Header().Set("x-request-id", "req-"+info.CustomID)
+		if info.CustomID == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad request"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion"}`))
+	}))
+
+	input := strings.Join([]string{
+		`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o","stream":true,"messages":[]}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o","messages":[]}}`,
+		`{"custom_id":"bad","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o","messages":[]}}`,
+	}, "\n")
+	job := createBatchForTest(t, svc, "/v1/chat/completions", input)
+	job.ExpiresAt = time.Now().Add(time.Hour)
+
+	svc.executeJob(job)
+
+	result := repo.finished[job.ID]
+	require.NotNil(t, result)
+	require.Equal(t, BatchStatusCompleted, result.Status)
+	require.Equal(t, 3, result.TotalCount)
+	require.Equal(t, 2, result.CompletedCount)
+	require.Equal(t, 1, result.FailedCount)
+	require.Equal(t, []float64{0.5, 0.5, 0.5}, discounts)
+
+	outLines := readBatchFileLines(t, svc, result.OutputFileID)
+	require.Len(t, outLines, 2)
+	var line batchResultLine
+	require.NoError(t, json.Unmarshal([]byte(outLines[0]), &line))
+	require.True(t, strings.HasPrefix(line.ID, "batch_req_"))
+	require.Equal(t, http.StatusOK, line.Response.StatusCode)
+	require.Equal(t, "chat.completion", gjson.GetBytes(line.Response.Body, "object").String())
+	require.Nil(t, line.Error)
+
+	errLines := readBatchFileLines(t, svc, result.ErrorFileID)
+	require.Len(t, errLines, 1)
+	require.Equal(t, "bad", gjson.Get(errLines[0], "custom_id").String())
+	require.Equal(t, int64(http.StatusBadRequest), gjson.Get(errLines[0], "response.status_code").Int())
+	require.Equal(t, "req-bad", gjson.Get(errLines[0], "response.request_id").String())
+}
+
+func TestBatchService_ExecuteJob_ExpiryAbortsInflightRequests(t *testing.T) {
+	prevInterval := batchMonitorInterval
+	batchMonitorInterval = 10 * time.Millisecond
+	t.Cleanup(func() { batchMonitorInterval = prevInterval })
+
+	svc, repo, _ := newBatchServiceForTest(t)
+	aborted := make(chan struct{}, 2)
+	svc.SetRequestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			aborted <- struct{}{}
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte(`{"id":"chatcmpl-1"}`))
+		}
+	}))
+
+	input := strings.Join([]string{
+		`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o","messages":[]}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o","messages":[]}}`,
+	}, "\n")
+	job := createBatchForTest(t, svc, "/v1/chat/completions", input)
+	job.ExpiresAt = time.Now().Add(-time.Second)
+
+	start := time.Now()
+	svc.executeJob(job)
+	require.Less(t, time.Since(start), 2*time.Second)
+	require.Len(t, aborted, 2)
+
+	result := repo.finished[job.ID]
+	require.NotNil(t, result)
+	require.Equal(t, BatchStatusExpired, result.Status)
+	require.Zero(t, result.CompletedCount)
+	require.Zero(t, result.FailedCount)
+	require.Nil(t, result.OutputFileID)
+	require.Nil(t, result.ErrorFileID)
+}
+
+func TestBatchService_ExecuteJob_FailsOnInvalidInput(t *testing.T) {
+	svc, repo, _ := newBatchServiceForTest(t)
+	svc.SetRequestHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("invalid batch must not be executed")
+	}))
+
+	input := strings.Join([]string{
+		`{"custom_id":"a","method":"POST","url":"/v1/responses","body":{"model":"gpt-4o"}}`,
+		`{"custom_id":"a","method":"POST","url":"/v1/responses","body":{"model":"gpt-4o"}}`,
+		`{"custom_id":"c","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o"}}`,
+		`not json`,
+	}, "\n")
+	job := createBatchForTest(t, svc, "/v1/responses", input)
+
+	svc.executeJob(job)
+
+	result := repo.finished[job.ID]
+	require.NotNil(t, result)
+	require.Equal(t, BatchStatusFailed, result.Status)
+	require.Len(t, result.Errors, 3)
+	require.Equal(t, "duplicate_custom_id", result.Errors[0].Code)
+	require.Equal(t, 2, *result.Errors[0].Line)
+	require.Equal(t, "mismatched_endpoint", result.Errors[1].Code)
+	require.Equal(t, "invalid_json_line", result.Errors[2].Code)
+	require.Nil(t, result.OutputFileID)
+}
+
+func TestBatchService_CreateBatch_Validation(t *testing.T) {
+	svc, _, _ := newBatchServiceForTest(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateBatch(ctx, &BatchCreateInput{UserID: 1, InputFileID: "file-x", Endpoint: "/v1/completions"})
+	require.Error(t, err)
+
+	_, err = svc.CreateBatch(ctx, &BatchCreateInput{UserID: 1, InputFileID: "file-x", Endpoint: "/v1/embeddings", GroupPlatform: PlatformAnthropic})
+	require.Error(t, err)
+
+	_, err = svc.CreateBatch(ctx, &BatchCreateInput{UserID: 1, InputFileID: "file-missing", Endpoint: "/v1/responses"})
+	require.ErrorIs(t, err, ErrBatchFileNotFound)
+
+	_, err = svc.UploadFile(ctx, &BatchFileUploadInput{UserID: 1, Purpose: "fine-tune", Body: strings.NewReader("x")})
+	require.Error(t, err)
+
+	_, err = svc.UploadFile(ctx, &BatchFileUploadInput{UserID: 1, Purpose: BatchFilePurposeBatch, Size: 2 << 20, Body: strings.NewReader("x")})
+	require.ErrorIs(t, err, ErrBatchFileTooLarge)
+}
+
+func TestApplyBatchBillingDiscount(t *testing.T) {
+	cost := &CostBreakdown{InputCost: 1, OutputCost: 2, CacheCreationCost: 0.5, CacheReadCost: 0.25, TotalCost: 3.75, ActualCost: 7.5}
+	usageLog := &UsageLog{InputCost: 1, OutputCost: 2, CacheCreationCost: 0.5, CacheReadCost: 0.25, TotalCost: 3.75, ActualCost: 7.5}
+	p := &postUsageBillingParams{Cost: cost}
+
+	applyBatchBillingDiscount(context.Background(), usageLog, p)
+	require.Same(t, cost, p.Cost, "non-batch requests must not be discounted")
+
+	ctx := WithBatchBilling(context.Background(), &BatchBillingInfo{BatchID: "batch_1", Multiplier: 0.5})
+	applyBatchBillingDiscount(ctx, usageLog, p)
+	require.InDelta(t, 1.875, p.Cost.TotalCost, 1e-12)
+	require.InDelta(t, 3.75, p.Cost.ActualCost, 1e-12)
+	require.InDelta(t, 3.75, cost.ActualCost*0.5, 1e-12)
+	require.InDelta(t, 7.5, cost.ActualCost, 1e-12, "original breakdown must stay untouched")
+	require.InDelta(t, 1.875, usageLog.TotalCost, 1e-12)
+	require.InDelta(t, 3.75, usageLog.ActualCost, 1e-12)
+	require.InDelta(t, 0.5, usageLog.InputCost, 1e-12)
+}
@@ -81,6 +81,11 @@ type apiKeyRateLimitLoader interface {
 	GetRateLimitData(ctx context.Context, keyID int64) (*APIKeyRateLimitData, error)
 }
 
+// apiKeyQuotaLoader 从数据库读取 API Key 实时额度用量（严格预留模式使用，认证缓存中的 quota_used 可能已过期）
+type apiKeyQuotaLoader interface {
+	GetQuotaUsage(ctx context.Context, keyID int64) (quota, quotaUsed float64, err error)
+}
+
 // BillingCacheService 计费缓存服务
 // 负责余额和订阅数据的缓存管理，提供高性能的计费资格检查
 type BillingCacheService struct {
@@ -88,6 +93,7 @@ type BillingCacheService struct {
 	userRepo              UserRepository
 	subRepo               UserSubscriptionRepository
 	apiKeyRateLimitLoader apiKeyRateLimitLoader
+	apiKeyQuotaLoader     apiKeyQuotaLoader
 	cfg                   *config.Config
 	circuitBreaker        *billingCircuitBreaker
 
@@ -113,6 +119,9 @@ func NewBillingCacheService(cache BillingCache, userRepo UserRepository, subRepo
 		apiKeyRateLimitLoader: apiKeyRepo,
 		cfg:                   cfg,
 	}
+	if loader, ok := apiKeyRepo.(apiKeyQuotaLoader); ok {
+		svc.apiKeyQuotaLoader = loader
+	}
 	svc.circuitBreaker = newBillingCircuitBreaker(cfg.Billing.CircuitBreaker)
 	svc.startCacheWriteWorkers()
 	return svc
@@ -660,7 +669,7 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 				return err
 			}
 		} else {
-			if err := s.checkBalanceEligibility(ctx, user.ID); err != nil {
+			if err := s.checkBalanceEligibility(ctx, user); err != nil {
 				return err
 			}
 		}
@@ -677,7 +686,8 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 }
 
 // checkBalanceEligibility 检查余额模式资格
-func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, userID int64) error {
+func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, user *User) error {
+	userID := user.ID
 	balance, err := s.GetUserBalance(ctx, userID)
 	if err != nil {
 		if s.circuitBreaker != nil {
@@ -690,7 +700,11 @@ func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, userI
 		s.circuitBreaker.OnSuccess()
 	}
 
-	if balance <= 0 {
+	// 后付费用户允许透支至信用额度
+	if balance <= -user.CreditHeadroom() {
+		if user.IsPostpaid() {
+			return ErrCreditLimitExceeded
+		}
 		return ErrInsufficientBalance
 	}
 
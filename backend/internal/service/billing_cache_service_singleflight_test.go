@@ -67,6 +67,18 @@ func (s *billingCacheMissStub) InvalidateAPIKeyRateLimit(ctx context.Context, ke
 	return nil
 }
 
+func (s *billingCacheMissStub) ReserveBillingHolds(ctx context.Context, holdID string, holds []BillingHold, ttl time.Duration) (int, error) {
+	return 0, nil
+}
+
+func (s *billingCacheMissStub) SettleBillingHolds(ctx context.Context, holdID string, holds []BillingHold, balanceCost float64) error {
+	return nil
+}
+
+func (s *billingCacheMissStub) ReleaseBillingHolds(ctx context.Context, holdID string, holds []BillingHold) error {
+	return nil
+}
+
 type balanceLoadUserRepoStub struct {
 	mockUserRepo
 	calls   atomic.Int64
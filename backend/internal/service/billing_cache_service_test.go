@@ -70,6 +70,18 @@ func (b *billingCacheWorkerStub) InvalidateAPIKeyRateLimit(ctx context.Context,
 	return nil
 }
 
+func (b *billingCacheWorkerStub) ReserveBillingHolds(ctx context.Context, holdID string, holds []BillingHold, ttl time.Duration) (int, error) {
+	return 0, nil
+}
+
+func (b *billingCacheWorkerStub) SettleBillingHolds(ctx context.Context, holdID string, holds []BillingHold, balanceCost float64) error {
+	return nil
+}
+
+func (b *billingCacheWorkerStub) ReleaseBillingHolds(ctx context.Context, holdID string, holds []BillingHold) error {
+	return nil
+}
+
 func TestBillingCacheServiceQueueHighLoad(t *testing.T) {
 	cache := &billingCacheWorkerStub{}
 	svc := NewBillingCacheService(cache, nil, nil, nil, &config.Config{})
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tokenestimate"
+	"github.com/tidwall/gjson"
+)
+
+// 分组计费预留模式
+const (
+	// BillingReservationModeOptimistic 请求完成后按实际费用扣费（默认）
+	BillingReservationModeOptimistic = "optimistic"
+	// BillingReservationModeStrict 请求前按预估最大费用预留余额/额度，完成后按实际费用结算
+	BillingReservationModeStrict = "strict"
+)
+
+// 预留额度池
+const (
+	BillingHoldPoolBalance     = "balance"
+	BillingHoldPoolAPIKeyQuota = "quota"
+	BillingHoldPoolAPIKeyRate  = "rate"
+)
+
+const (
+	defaultBillingReservationHoldTTL      = 15 * time.Minute
+	defaultBillingReservationOutputTokens = 8192
+	defaultBillingReservationAudioKbps    = 32
+	billingReservationReleaseTimeout      = 2 * time.Second
+
+	// 按 token 计费的转写模型（gpt-4o 系列）每秒音频的输入 token 与转写输出 token 上限
+	transcriptionInputTokensPerSecond  = 10
+	transcriptionOutputTokensPerSecond = 10
+)
+
+var (
+	ErrInvalidBillingReservationMode = infraerrors.BadRequest("INVALID_BILLING_RESERVATION_MODE", "billing_reservation_mode must be one of optimistic, strict")
+
+	ErrReservationInsufficientBalance = infraerrors.Forbidden("INSUFFICIENT_BALANCE_FOR_RESERVATION", "insufficient balance to cover the estimated maximum cost of this request")
+	ErrReservationAPIKeyQuota         = infraerrors.TooManyRequests("API_KEY_QUOTA_RESERVATION_FAILED", "api key remaining quota cannot cover the estimated maximum cost of this request")
+	ErrReservationAPIKeyRateLimit     = infraerrors.TooManyRequests("API_KEY_RATE_LIMIT_RESERVATION_FAILED", "api key rate limit window cannot cover the estimated maximum cost of this request")
+	ErrReservationUnsupported         = infraerrors.Forbidden("BILLING_RESERVATION_UNSUPPORTED", "this endpoint cannot reserve its cost before forwarding; it is unavailable in groups with strict billing reservation")
+)
+
+// NormalizeBillingReservationMode 去除首尾空白并统一小写，空值视为 optimistic
+func NormalizeBillingReservationMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		return BillingReservationModeOptimistic
+	}
+	return mode
+}
+
+// IsValidBillingReservationMode 检查计费预留模式取值是否合法
+func IsValidBillingReservationMode(mode string) bool {
+	switch mode {
+	case BillingReservationModeOptimistic, BillingReservationModeStrict:
+		return true
+	default:
+		return false
+	}
+}
+
+// UsesStrictBillingReservation 分组是否启用严格预留模式
+func (g *Group) UsesStrictBillingReservation() bool {
+	return g != nil && NormalizeBillingReservationMode(g.BillingReservationMode) == BillingReservationModeStrict
+}
+
+// BillingHold 单个额度池上的预留
+type BillingHold struct {
+	Pool    string  // balance / quota / rate
+	OwnerID int64   // balance 为用户 ID，quota / rate 为 API Key ID
+	Amount  float64 // 预留金额（USD，已乘倍率）
+	// Available 预留时的可用额度（含 Credit）；balance 池在 Redis 存在余额缓存时以缓存 + Credit 为准
+	Available float64
+	// Credit 后付费用户的信用额度，允许 balance 池透支至 -Credit
+	Credit float64
+}
+
+// BillingReservation 一次请求在各额度池上的预留，结算或释放后失效（重复调用无副作用）
+type BillingReservation struct {
+	ID     string
+	Amount float64
+	Holds  []BillingHold
+
+	svc      *BillingCacheService
+	done     atomic.Bool
+	recorded atomic.Bool
+}
+
+type billingReservationContextKey struct{}
+
+// WithBillingReservation 将预留信息写入 context，供使用量记录任务结算。
+func WithBillingReservation(ctx context.Context, r *BillingReservation) context.Context {
+	if ctx == nil || r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, billingReservationContextKey{}, r)
+}
+
+// BillingReservationFromContext 读取预留信息；未预留的请求返回 nil。
+func BillingReservationFromContext(ctx context.Context) *BillingReservation {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(billingReservationContextKey{}).(*BillingReservation)
+	return r
+}
+
+// Covers 预留是否包含指定额度池
+func (r *BillingReservation) Covers(pool string) bool {
+	return r != nil && r.hold(pool) != nil
+}
+
+func (r *BillingReservation) hold(pool string) *BillingHold {
+	for i := range r.Holds {
+		if r.Holds[i].Pool == pool {
+			return &r.Holds[i]
+		}
+	}
+	return nil
+}
+
+// MarkRecorded 标记使用量记录任务已接管预留（由记录任务负责结算与释放）
+func (r *BillingReservation) MarkRecorded() {
+	if r != nil {
+		r.recorded.Store(true)
+	}
+}
+
+// ReleaseIfUnrecorded 请求结束时调用：未提交使用量记录（上游失败、客户端断开等）时释放预留
+func (r *BillingReservation) ReleaseIfUnrecorded(ctx context.Context) {
+	if r == nil || r.recorded.Load() {
+		return
+	}
+	r.Release(ctx)
+}
+
+// Release 释放预留（上游失败、超时或请求未产生计费时调用）
+func (r *BillingReservation) Release(ctx context.Context) {
+	if r == nil || r.svc == nil || !r.done.CompareAndSwap(false, true) {
+		return
+	}
+	releaseCtx, cancel := detachedReservationContext(ctx)
+	defer cancel()
+	if err := r.svc.cache.ReleaseBillingHolds(releaseCtx, r.ID, r.Holds); err != nil {
+		// 释放失败时预留会在 TTL 到期后自动失效
+		logger.LegacyPrintf("service.billing_reservation", "Warning: release billing reservation %s failed: %v", r.ID, err)
+	}
+}
+
+// Settle 按实际费用结算预留：同步扣减余额缓存、更新限速用量缓存并释放预留。
+// 仅结算被预留的额度池；返回 false 表示预留已结算或释放，调用方需回退到异步缓存更新。
+func (r *BillingReservation) Settle(ctx context.Context, balanceCost, rateLimitCost float64) bool {
+	if r == nil || r.svc == nil || !r.done.CompareAndSwap(false, true) {
+		return false
+	}
+	settleCtx, cancel := detachedReservationContext(ctx)
+	defer cancel()
+
+	if h := r.hold(BillingHoldPoolAPIKeyRate); h != nil && rateLimitCost > 0 {
+		// 先计入限速用量再释放预留，避免两者之间出现可透支窗口
+		if err := r.svc.cache.UpdateAPIKeyRateLimitUsage(settleCtx, h.OwnerID, rateLimitCost); err != nil {
+			logger.LegacyPrintf("service.billing_reservation", "Warning: settle rate limit usage for api key %d failed: %v", h.OwnerID, err)
+			r.svc.QueueUpdateAPIKeyRateLimitUsage(h.OwnerID, rateLimitCost)
+		}
+	}
+	balanceHold := r.hold(BillingHoldPoolBalance)
+	settleBalance := 0.0
+	if balanceHold != nil {
+		settleBalance = balanceCost
+	}
+	if err := r.svc.cache.SettleBillingHolds(settleCtx, r.ID, r.Holds, settleBalance); err != nil {
+		logger.LegacyPrintf("service.billing_reservation", "Warning: settle billing reservation %s failed: %v", r.ID, err)
+		if settleBalance > 0 {
+			r.svc.QueueDeductBalance(balanceHold.OwnerID, settleBalance)
+		}
+	}
+	return true
+}
+
+func detachedReservationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	base := context.Background()
+	if ctx != nil {
+		base = context.WithoutCancel(ctx)
+	}
+	return context.WithTimeout(base, billingReservationReleaseTimeout)
+}
+
+func (s *BillingCacheService) reservationConfig() config.BillingReservationConfig {
+	if s == nil || s.cfg == nil {
+		return config.BillingReservationConfig{}
+	}
+	return s.cfg.Billing.Reservation
+}
+
+func (s *BillingCacheService) reservationHoldTTL() time.Duration {
+	if ttl := s.reservationConfig().HoldTTLSeconds; ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+	return defaultBillingReservationHoldTTL
+}
+
+// EstimateReservationCost 按本地估算的输入 token 与请求声明的最大输出 token 估算最大费用（已乘倍率）。
+// 未声明 max_tokens 时按 billing.reservation.default_max_output_tokens 估算；分组自定义模型价格优先于全局价格表。
+func (s *BillingCacheService) EstimateReservationCost(billing *BillingService, group *Group, model string, body []byte, rateMultiplier float64) float64 {
+	if billing == nil || model == "" {
+		return 0
+	}
+	outputTokens := tokenestimate.MaxOutputTokens(body)
+	if outputTokens <= 0 {
+		outputTokens = defaultBillingReservationOutputTokens
+		if s != nil && s.cfg != nil {
+			outputTokens = s.cfg.Billing.Reservation.DefaultMaxOutputTokens
+		}
+	}
+	if rateMultiplier < 0 {
+		rateMultiplier = 1.0
+	}
+	breakdown, err := billing.CalculateCostWithServiceTier(model, UsageTokens{
+		InputTokens:  tokenestimate.CountRequest(model, body),
+		OutputTokens: outputTokens,
+	}, rateMultiplier, "", group)
+	if err != nil || breakdown == nil {
+		// 无价格数据的模型无法估算，跳过预留
+		return 0
+	}
+	return breakdown.ActualCost
+}
+
+// ReserveCost 严格预留模式下，在请求转发前按预估最大费用原子预留余额、API Key 额度与限速窗口。
+// 任一额度池不足时整体拒绝，不产生部分预留；非严格模式或无需预留时返回 nil。
+func (s *BillingCacheService) ReserveCost(ctx context.Context, user *User, apiKey *APIKey, group *Group, subscription *UserSubscription, amount float64) (*BillingReservation, error) {
+	if s == nil || s.cache == nil || s.cfg == nil || s.cfg.RunMode == config.RunModeSimple {
+		return nil, nil
+	}
+	if !group.UsesStrictBillingReservation() || user == nil || apiKey == nil || amount <= 0 {
+		return nil, nil
+	}
+	// 按次计费不涉及 USD 额度
+	if apiKey.HasRemainingEffectiveRequestQuota() || (subscription != nil && subscription.HasRequestQuota()) {
+		return nil, nil
+	}
+
+	holds := make([]BillingHold, 0, 3)
+	if subscription == nil {
+		balance, err := s.GetUserBalance(ctx, user.ID)
+		if err != nil {
+			logger.LegacyPrintf("service.billing_reservation", "ALERT: load balance for reservation failed for user %d: %v", user.ID, err)
+			return nil, ErrBillingServiceUnavailable.WithCause(err)
+		}
+		credit := user.CreditHeadroom()
+		holds = append(holds, BillingHold{Pool: BillingHoldPoolBalance, OwnerID: user.ID, Amount: amount, Available: balance + credit, Credit: credit})
+	}
+	if apiKey.Quota > 0 {
+		quota, quotaUsed, err := s.apiKeyQuotaUsage(ctx, apiKey)
+		if err != nil {
+			logger.LegacyPrintf("service.billing_reservation", "ALERT: load quota usage for reservation failed for api key %d: %v", apiKey.ID, err)
+			return nil, ErrBillingServiceUnavailable.WithCause(err)
+		}
+		holds = append(holds, BillingHold{Pool: BillingHoldPoolAPIKeyQuota, OwnerID: apiKey.ID, Amount: amount, Available: quota - quotaUsed})
+	}
+	if apiKey.HasRateLimits() {
+		if headroom, ok := s.rateLimitHeadroom(ctx, apiKey); ok {
+			holds = append(holds, BillingHold{Pool: BillingHoldPoolAPIKeyRate, OwnerID: apiKey.ID, Amount: amount, Available: headroom})
+		}
+	}
+	if len(holds) == 0 {
+		return nil, nil
+	}
+
+	r := &BillingReservation{ID: generateRequestID(), Amount: amount, Holds: holds, svc: s}
+	rejected, err := s.cache.ReserveBillingHolds(ctx, r.ID, holds, s.reservationHoldTTL())
+	if err != nil {
+		logger.LegacyPrintf("service.billing_reservation", "ALERT: reserve billing holds failed for user %d api key %d: %v", user.ID, apiKey.ID, err)
+		return nil, ErrBillingServiceUnavailable.WithCause(err)
+	}
+	if rejected > 0 && rejected <= len(holds) {
+		switch holds[rejected-1].Pool {
+		case BillingHoldPoolBalance:
+			if user.IsPostpaid() {
+				return nil, ErrCreditLimitExceeded
+			}
+			return nil, ErrReservationInsufficientBalance
+		case BillingHoldPoolAPIKeyQuota:
+			return nil, ErrReservationAPIKeyQuota
+		default:
+			return nil, ErrReservationAPIKeyRateLimit
+		}
+	}
+	return r, nil
+}
+
+// apiKeyQuotaUsage 从数据库读取 API Key 当前额度与已用额度：已结算请求的用量在释放预留前已写入 quota_used，
+// 与进行中的预留合计即为真实占用；认证缓存中的 quota_used 可能滞后，不能作为预留依据。
+func (s *BillingCacheService) apiKeyQuotaUsage(ctx context.Context, apiKey *APIKey) (float64, float64, error) {
+	if s.apiKeyQuotaLoader == nil {
+		return apiKey.Quota, apiKey.QuotaUsed, nil
+	}
+	return s.apiKeyQuotaLoader.GetQuotaUsage(ctx, apiKey.ID)
+}
+
+// rateLimitHeadroom 返回各限速窗口剩余额度的最小值；限速缓存不可用时返回 false（不预留限速池）。
+func (s *BillingCacheService) rateLimitHeadroom(ctx context.Context, apiKey *APIKey) (float64, bool) {
+	data, err := s.cache.GetAPIKeyRateLimit(ctx, apiKey.ID)
+	if err != nil || data == nil {
+		return 0, false
+	}
+	headroom := math.Inf(1)
+	windows := []struct {
+		limit  float64
+		usage  float64
+		start  int64
+		window time.Duration
+	}{
+		{apiKey.RateLimit5h, data.Usage5h, data.Window5h, RateLimitWindow5h},
+		{apiKey.RateLimit1d, data.Usage1d, data.Window1d, RateLimitWindow1d},
+		{apiKey.RateLimit7d, data.Usage7d, data.Window7d, RateLimitWindow7d},
+	}
+	for _, w := range windows {
+		if w.limit <= 0 {
+			continue
+		}
+		usage := w.usage
+		if w.start > 0 {
+			start := time.Unix(w.start, 0)
+			if IsWindowExpired(&start, w.window) {
+				usage = 0
+			}
+		} else {
+			usage = 0
+		}
+		headroom = math.Min(headroom, w.limit-usage)
+	}
+	if math.IsInf(headroom, 1) {
+		return 0, false
+	}
+	return headroom, true
+}
+
+// EstimateImageReservationCost 按请求的图片数量与尺寸档位估算图片生成费用（已乘倍率），与实际计费同样优先使用分组图片单价。
+func (s *BillingCacheService) EstimateImageReservationCost(billing *BillingService, group *Group, model, imageSize string, imageCount int, rateMultiplier float64) float64 {
+	if billing == nil {
+		return 0
+	}
+	if imageCount <= 0 {
+		imageCount = 1
+	}
+	var groupConfig *ImagePriceConfig
+	if group != nil {
+		groupConfig = &ImagePriceConfig{
+			Price1K: group.ImagePrice1K,
+			Price2K: group.ImagePrice2K,
+			Price4K: group.ImagePrice4K,
+		}
+	}
+	return billing.CalculateImageCost(model, imageSize, imageCount, groupConfig, rateMultiplier).ActualCost
+}
+
+// EstimateSpeechReservationCost 语音合成按输入字符数估算费用（已乘倍率）；
+// 无按字符价格的模型（如 gpt-4o-mini-tts 按 token 计费）回退到 token 估算。
+func (s *BillingCacheService) EstimateSpeechReservationCost(billing *BillingService, group *Group, model string, body []byte, rateMultiplier float64) float64 {
+	if billing == nil || model == "" {
+		return 0
+	}
+	characters := utf8.RuneCountInString(gjson.GetBytes(body, "input").String())
+	if cost, err := billing.CalculateAudioCost(model, 0, characters, rateMultiplier); err == nil && cost.ActualCost > 0 {
+		return cost.ActualCost
+	}
+	return s.EstimateReservationCost(billing, group, model, body, rateMultiplier)
+}
+
+// EstimateTranscriptionReservationCost 按上传文件大小估算转写/翻译的最大费用（已乘倍率）。
+// 音频时长上限 = 文件大小 / billing.reservation.audio_min_bitrate_kbps；按时长计费的模型（whisper-1）按时长上限计费，
+// 按 token 计费的模型（gpt-4o 系列）按每秒音频输入 token 与转写输出 token 上限估算，分组自定义模型价格优先。
+func (s *BillingCacheService) EstimateTranscriptionReservationCost(billing *BillingService, group *Group, model string, fileSize int64, rateMultiplier float64) float64 {
+	if billing == nil || model == "" || fileSize <= 0 {
+		return 0
+	}
+	kbps := defaultBillingReservationAudioKbps
+	if s != nil && s.cfg != nil && s.cfg.Billing.Reservation.AudioMinBitrateKbps > 0 {
+		kbps = s.cfg.Billing.Reservation.AudioMinBitrateKbps
+	}
+	seconds := math.Ceil(float64(fileSize) * 8 / (float64(kbps) * 1000))
+	if cost, err := billing.CalculateAudioCost(model, seconds, 0, rateMultiplier); err == nil && cost.ActualCost > 0 {
+		return cost.ActualCost
+	}
+	if rateMultiplier < 0 {
+		rateMultiplier = 1.0
+	}
+	breakdown, err := billing.CalculateCostWithServiceTier(model, UsageTokens{
+		InputTokens:  int(seconds) * transcriptionInputTokensPerSecond,
+		OutputTokens: int(seconds) * transcriptionOutputTokensPerSecond,
+	}, rateMultiplier, "", group)
+	if err != nil || breakdown == nil {
+		return 0
+	}
+	return breakdown.ActualCost
+}
+
+// ReserveRequestCost 严格预留模式下按预估最大费用为请求预留额度（费率倍数与实际扣费口径一致）。
+// 非严格模式或无需预留时返回 nil。
+func (s *GatewayService) ReserveRequestCost(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model string, body []byte) (*BillingReservation, error) {
+	if s == nil || apiKey == nil || apiKey.User == nil || !apiKey.Group.UsesStrictBillingReservation() {
+		return nil, nil
+	}
+	amount := s.billingCacheService.EstimateReservationCost(s.billingService, apiKey.Group, model, body, s.reservationRateMultiplier(ctx, apiKey))
+	return s.billingCacheService.ReserveCost(ctx, apiKey.User, apiKey, apiKey.Group, subscription, amount)
+}
+
+// ReserveImageCost 严格预留模式下按请求的图片数量与尺寸为 Images 请求预留额度。
+func (s *GatewayService) ReserveImageCost(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model, imageSize string, imageCount int) (*BillingReservation, error) {
+	if s == nil || apiKey == nil || apiKey.User == nil || !apiKey.Group.UsesStrictBillingReservation() {
+		return nil, nil
+	}
+	amount := s.billingCacheService.EstimateImageReservationCost(s.billingService, apiKey.Group, model, imageSize, imageCount, s.reservationRateMultiplier(ctx, apiKey))
+	return s.billingCacheService.ReserveCost(ctx, apiKey.User, apiKey, apiKey.Group, subscription, amount)
+}
+
+func (s *GatewayService) reservationRateMultiplier(ctx context.Context, apiKey *APIKey) float64 {
+	multiplier := 1.0
+	if s.cfg != nil {
+		multiplier = s.cfg.Default.RateMultiplier
+	}
+	if apiKey.GroupID != nil {
+		multiplier = s.getUserGroupRateMultiplier(ctx, apiKey.User.ID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+	}
+	return multiplier
+}
+
+// ReserveRequestCost 严格预留模式下按预估最大费用为请求预留额度（费率倍数与实际扣费口径一致）。
+// 非严格模式或无需预留时返回 nil。
+func (s *OpenAIGatewayService) ReserveRequestCost(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model string, body []byte) (*BillingReservation, error) {
+	if s == nil || apiKey == nil || apiKey.User == nil || !apiKey.Group.UsesStrictBillingReservation() {
+		return nil, nil
+	}
+	amount := s.billingCacheService.EstimateReservationCost(s.billingService, apiKey.Group, model, body, s.reservationRateMultiplier(ctx, apiKey))
+	return s.billingCacheService.ReserveCost(ctx, apiKey.User, apiKey, apiKey.Group, subscription, amount)
+}
+
+// ReserveImageCost 严格预留模式下按请求的图片数量与尺寸为 Images 请求预留额度。
+func (s *OpenAIGatewayService) ReserveImageCost(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model, imageSize string, imageCount int) (*BillingReservation, error) {
+	if s == nil || apiKey == nil || apiKey.User == nil || !apiKey.Group.UsesStrictBillingReservation() {
+		return nil, nil
+	}
+	amount := s.billingCacheService.EstimateImageReservationCost(s.billingService, apiKey.Group, model, imageSize, imageCount, s.reservationRateMultiplier(ctx, apiKey))
+	return s.billingCacheService.ReserveCost(ctx, apiKey.User, apiKey, apiKey.Group, subscription, amount)
+}
+
+// ReserveSpeechCost 严格预留模式下按输入字符数为语音合成请求预留额度。
+func (s *OpenAIGatewayService) ReserveSpeechCost(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model string, body []byte) (*BillingReservation, error) {
+	if s == nil || apiKey == nil || apiKey.User == nil || !apiKey.Group.UsesStrictBillingReservation() {
+		return nil, nil
+	}
+	amount := s.billingCacheService.EstimateSpeechReservationCost(s.billingService, apiKey.Group, model, body, s.reservationRateMultiplier(ctx, apiKey))
+	return s.billingCacheService.ReserveCost(ctx, apiKey.User, apiKey, apiKey.Group, subscription, amount)
+}
+
+// ReserveTranscriptionCost 严格预留模式下按上传音频文件大小为转写/翻译请求预留额度。
+func (s *OpenAIGatewayService) ReserveTranscriptionCost(ctx context.Context, apiKey *APIKey, subscription *UserSubscription, model string, fileSize int64) (*BillingReservation, error) {
+	if s == nil || apiKey == nil || apiKey.User == nil || !apiKey.Group.UsesStrictBillingReservation() {
+		return nil, nil
+	}
+	amount := s.billingCacheService.EstimateTranscriptionReservationCost(s.billingService, apiKey.Group, model, fileSize, s.reservationRateMultiplier(ctx, apiKey))
+	return s.billingCacheService.ReserveCost(ctx, apiKey.User, apiKey, apiKey.Group, subscription, amount)
+}
+
+func (s *OpenAIGatewayService) reservationRateMultiplier(ctx context.Context, apiKey *APIKey) float64 {
+	multiplier := 1.0
+	if s.cfg != nil {
+		multiplier = s.cfg.Default.RateMultiplier
+	}
+	if apiKey.GroupID != nil {
+		resolver := s.userGroupRateResolver
+		if resolver == nil {
+			resolver = newUserGroupRateResolver(nil, nil, resolveUserGroupRateCacheTTL(s.cfg), nil, "service.openai_gateway")
+		}
+		multiplier = resolver.Resolve(ctx, apiKey.User.ID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+	}
+	return multiplier
+}
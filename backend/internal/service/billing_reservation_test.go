@@ -0,0 +1,261 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type reservationCacheStub struct {
+	BillingCache
+
+	mu           sync.Mutex
+	balance      float64
+	rate         *APIKeyRateLimitCacheData
+	rejected     int
+	reserved     []BillingHold
+	reservedTTL  time.Duration
+	settledCost  float64
+	settleCalls  int
+	releaseCalls int
+	rateUpdates  []float64
+	deducted     []float64
+}
+
+func (s *reservationCacheStub) GetUserBalance(context.Context, int64) (float64, error) {
+	return s.balance, nil
+}
+
+func (s *reservationCacheStub) GetAPIKeyRateLimit(context.Context, int64) (*APIKeyRateLimitCacheData, error) {
+	if s.rate == nil {
+		return nil, context.DeadlineExceeded
+	}
+	return s.rate, nil
+}
+
+func (s *reservationCacheStub) UpdateAPIKeyRateLimitUsage(_ context.Context, _ int64, cost float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateUpdates = append(s.rateUpdates, cost)
+	return nil
+}
+
+func (s *reservationCacheStub) DeductUserBalance(_ context.Context, _ int64, amount float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deducted = append(s.deducted, amount)
+	return nil
+}
+
+func (s *reservationCacheStub) ReserveBillingHolds(_ context.Context, _ string, holds []BillingHold, ttl time.Duration) (int, error) {
+	s.reserved = append([]BillingHold(nil), holds...)
+	s.reservedTTL = ttl
+	return s.rejected, nil
+}
+
+func (s *reservationCacheStub) SettleBillingHolds(_ context.Context, _ string, _ []BillingHold, balanceCost float64) error {
+	s.settleCalls++
+	s.settledCost = balanceCost
+	return nil
+}
+
+func (s *reservationCacheStub) ReleaseBillingHolds(context.Context, string, []BillingHold) error {
+	s.releaseCalls++
+	return nil
+}
+
+func newReservationTestService(t *testing.T, cache BillingCache) *BillingCacheService {
+	t.Helper()
+	cfg := &config.Config{Billing: config.BillingConfig{Reservation: config.BillingReservationConfig{HoldTTLSeconds: 60}}}
+	svc := NewBillingCacheService(cache, nil, nil, nil, cfg)
+	t.Cleanup(svc.Stop)
+	return svc
+}
+
+func TestNormalizeBillingReservationMode(t *testing.T) {
+	require.Equal(t, BillingReservationModeOptimistic, NormalizeBillingReservationMode(""))
+	require.Equal(t, BillingReservationModeStrict, NormalizeBillingReservationMode(" Strict "))
+	require.True(t, IsValidBillingReservationMode(NormalizeBillingReservationMode("optimistic")))
+	require.False(t, IsValidBillingReservationMode(NormalizeBillingReservationMode("pessimistic")))
+}
+
+func TestReserveCost_SkipsOptimisticGroup(t *testing.T) {
+	cache := &reservationCacheStub{balance: 10}
+	svc := newReservationTestService(t, cache)
+
+	r, err := svc.ReserveCost(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, &Group{}, nil, 1)
+	require.NoError(t, err)
+	require.Nil(t, r)
+	require.Nil(t, cache.reserved)
+}
+
+func TestReserveCost_HoldsBalanceQuotaAndRateHeadroom(t *testing.T) {
+	now := time.Now().Unix()
+	cache := &reservationCacheStub{
+		balance: 10,
+		rate:    &APIKeyRateLimitCacheData{Usage5h: 1, Window5h: now, Usage1d: 9.5, Window1d: now},
+	}
+	svc := newReservationTestService(t, cache)
+	apiKey := &APIKey{ID: 2, Quota: 5, QuotaUsed: 1, RateLimit5h: 3, RateLimit1d: 10}
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+
+	r, err := svc.ReserveCost(context.Background(), &User{ID: 1}, apiKey, group, nil, 0.25)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.Equal(t, time.Minute, cache.reservedTTL)
+	require.Equal(t, []BillingHold{
+		{Pool: BillingHoldPoolBalance, OwnerID: 1, Amount: 0.25, Available: 10},
+		{Pool: BillingHoldPoolAPIKeyQuota, OwnerID: 2, Amount: 0.25, Available: 4},
+		{Pool: BillingHoldPoolAPIKeyRate, OwnerID: 2, Amount: 0.25, Available: 0.5},
+	}, cache.reserved)
+}
+
+type reservationQuotaLoaderStub struct {
+	quota, quotaUsed float64
+}
+
+func (s reservationQuotaLoaderStub) GetQuotaUsage(context.Context, int64) (float64, float64, error) {
+	return s.quota, s.quotaUsed, nil
+}
+
+func TestReserveCost_QuotaPoolUsesFreshUsage(t *testing.T) {
+	cache := &reservationCacheStub{balance: 10}
+	svc := newReservationTestService(t, cache)
+	svc.apiKeyQuotaLoader = reservationQuotaLoaderStub{quota: 5, quotaUsed: 4.5}
+	// 认证缓存中的 quota_used 已过期
+	apiKey := &APIKey{ID: 2, Quota: 5, QuotaUsed: 1}
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+
+	_, err := svc.ReserveCost(context.Background(), &User{ID: 1}, apiKey, group, nil, 0.25)
+	require.NoError(t, err)
+	require.Equal(t, BillingHold{Pool: BillingHoldPoolAPIKeyQuota, OwnerID: 2, Amount: 0.25, Available: 0.5}, cache.reserved[1])
+}
+
+func TestReserveCost_SubscriptionSkipsBalancePool(t *testing.T) {
+	cache := &reservationCacheStub{}
+	svc := newReservationTestService(t, cache)
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+
+	r, err := svc.ReserveCost(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, group, &UserSubscription{ID: 3}, 1)
+	require.NoError(t, err)
+	require.Nil(t, r, "no pool to hold without balance billing, quota or rate limits")
+	require.Nil(t, cache.reserved)
+}
+
+func TestReserveCost_MapsRejectedPool(t *testing.T) {
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+	apiKey := &APIKey{ID: 2, Quota: 5}
+
+	cache := &reservationCacheStub{balance: 1, rejected: 1}
+	_, err := newReservationTestService(t, cache).ReserveCost(context.Background(), &User{ID: 1}, apiKey, group, nil, 2)
+	require.ErrorIs(t, err, ErrReservationInsufficientBalance)
+
+	cache = &reservationCacheStub{balance: 10, rejected: 2}
+	_, err = newReservationTestService(t, cache).ReserveCost(context.Background(), &User{ID: 1}, apiKey, group, nil, 2)
+	require.ErrorIs(t, err, ErrReservationAPIKeyQuota)
+}
+
+func TestEstimateImageReservationCost_UsesGroupImagePrice(t *testing.T) {
+	billing := newTestBillingService()
+	price2K := 0.2
+	group := &Group{ImagePrice2K: &price2K}
+
+	require.InDelta(t, 0.9, (*BillingCacheService)(nil).EstimateImageReservationCost(billing, group, "gemini-3-pro-image", "2K", 3, 1.5), 1e-12)
+	// 未声明 n 时按 1 张估算
+	require.InDelta(t, 0.2, (*BillingCacheService)(nil).EstimateImageReservationCost(billing, group, "gemini-3-pro-image", "2K", 0, 1), 1e-12)
+}
+
+func TestEstimateSpeechReservationCost_ByCharacters(t *testing.T) {
+	billing := NewBillingService(&config.Config{}, &PricingService{
+		pricingData: map[string]*LiteLLMModelPricing{
+			"tts-1": {InputCostPerCharacter: 15e-6},
+		},
+	})
+	body := []byte(`{"model":"tts-1","input":"héllo"}`)
+
+	require.InDelta(t, 5*15e-6*2, (*BillingCacheService)(nil).EstimateSpeechReservationCost(billing, nil, "tts-1", body, 2), 1e-12)
+}
+
+func TestEstimateTranscriptionReservationCost_ByFileSize(t *testing.T) {
+	billing := NewBillingService(&config.Config{}, &PricingService{
+		pricingData: map[string]*LiteLLMModelPricing{
+			"whisper-1":         {InputCostPerSecond: 1e-4},
+			"gpt-4o-transcribe": {InputCostPerToken: 2.5e-6, OutputCostPerToken: 10e-6},
+		},
+	})
+	svc := &BillingCacheService{cfg: &config.Config{Billing: config.BillingConfig{Reservation: config.BillingReservationConfig{AudioMinBitrateKbps: 16}}}}
+
+	// 1MB / 16kbps = 500 秒
+	require.InDelta(t, 500*1e-4*2, svc.EstimateTranscriptionReservationCost(billing, nil, "whisper-1", 1_000_000, 2), 1e-12)
+	// 按 token 计费：每秒 10 个输入 token 与 10 个输出 token
+	require.InDelta(t, 500*10*(2.5e-6+10e-6), svc.EstimateTranscriptionReservationCost(billing, nil, "gpt-4o-transcribe", 1_000_000, 1), 1e-12)
+	// 未配置时按默认 32kbps
+	require.InDelta(t, 250*1e-4, (*BillingCacheService)(nil).EstimateTranscriptionReservationCost(billing, nil, "whisper-1", 1_000_000, 1), 1e-12)
+	require.Zero(t, svc.EstimateTranscriptionReservationCost(billing, nil, "whisper-1", 0, 1))
+}
+
+func TestBillingReservation_SettleIsOnce(t *testing.T) {
+	cache := &reservationCacheStub{balance: 10, rate: &APIKeyRateLimitCacheData{}}
+	svc := newReservationTestService(t, cache)
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+
+	r, err := svc.ReserveCost(context.Background(), &User{ID: 1}, &APIKey{ID: 2, RateLimit5h: 5}, group, nil, 1)
+	require.NoError(t, err)
+
+	require.True(t, r.Settle(context.Background(), 0.4, 0.4))
+	require.False(t, r.Settle(context.Background(), 0.4, 0.4))
+	r.Release(context.Background())
+
+	require.Equal(t, 1, cache.settleCalls)
+	require.Equal(t, 0.4, cache.settledCost)
+	require.Equal(t, []float64{0.4}, cache.rateUpdates)
+	require.Zero(t, cache.releaseCalls)
+}
+
+func TestBillingReservation_ReleaseIfUnrecorded(t *testing.T) {
+	cache := &reservationCacheStub{balance: 10}
+	svc := newReservationTestService(t, cache)
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+
+	recorded, err := svc.ReserveCost(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, group, nil, 1)
+	require.NoError(t, err)
+	recorded.MarkRecorded()
+	recorded.ReleaseIfUnrecorded(context.Background())
+	require.Zero(t, cache.releaseCalls)
+
+	failed, err := svc.ReserveCost(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, group, nil, 1)
+	require.NoError(t, err)
+	failed.ReleaseIfUnrecorded(context.Background())
+	failed.ReleaseIfUnrecorded(context.Background())
+	require.Equal(t, 1, cache.releaseCalls)
+}
+
+func TestFinalizePostUsageBilling_SettlesReservationInsteadOfQueuedDeduct(t *testing.T) {
+	cache := &reservationCacheStub{balance: 10}
+	svc := newReservationTestService(t, cache)
+	group := &Group{BillingReservationMode: BillingReservationModeStrict}
+	user := &User{ID: 1}
+	apiKey := &APIKey{ID: 2}
+
+	r, err := svc.ReserveCost(context.Background(), user, apiKey, group, nil, 1)
+	require.NoError(t, err)
+
+	finalizePostUsageBilling(&postUsageBillingParams{
+		Cost:        &CostBreakdown{TotalCost: 0.3, ActualCost: 0.3},
+		User:        user,
+		APIKey:      apiKey,
+		Account:     &Account{ID: 9},
+		Reservation: r,
+	}, &billingDeps{billingCacheService: svc, deferredService: &DeferredService{}})
+	svc.Stop()
+
+	require.Equal(t, 1, cache.settleCalls)
+	require.Equal(t, 0.3, cache.settledCost)
+	require.Empty(t, cache.deducted, "balance cache must not be deducted twice")
+}
@@ -6,8 +6,10 @@ import (
 
 	"log"
 	"strings"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tokenestimate"
 )
 
 // APIKeyRateLimitCacheData holds rate limit usage data cached in Redis.
@@ -39,6 +41,13 @@ type BillingCache interface {
 	SetAPIKeyRateLimit(ctx context.Context, keyID int64, data *APIKeyRateLimitCacheData) error
 	UpdateAPIKeyRateLimitUsage(ctx context.Context, keyID int64, cost float64) error
 	InvalidateAPIKeyRateLimit(ctx context.Context, keyID int64) error
+
+	// Cost reservation operations (strict billing reservation mode)
+	// ReserveBillingHolds 原子预留全部额度池；返回被拒绝的额度池序号（从 1 开始），0 表示预留成功
+	ReserveBillingHolds(ctx context.Context, holdID string, holds []BillingHold, ttl time.Duration) (int, error)
+	// SettleBillingHolds 原子扣减余额缓存（balanceCost > 0 时）并释放预留
+	SettleBillingHolds(ctx context.Context, holdID string, holds []BillingHold, balanceCost float64) error
+	ReleaseBillingHolds(ctx context.Context, holdID string, holds []BillingHold) error
 }
 
 // ModelPricing 模型价格配置（per-token价格，与LiteLLM格式一致）
@@ -104,6 +113,7 @@ type CostBreakdown struct {
 	CacheReadCost     float64
 	TotalCost         float64
 	ActualCost        float64 // 应用倍率后的实际费用
+	PriceSource       string  // 价格来源：group / litellm / fallback
 }
 
 // BillingService 计费服务
@@ -287,6 +297,20 @@ func (s *BillingService) initFallbackPricing() {
 		SupportsCacheBreakdown:         false,
 	}
 	s.fallbackPrices["gpt-5.3-codex"] = s.fallbackPrices["gpt-5.1-codex"]
+
+	// Embeddings 模型（仅输入计费）
+	s.fallbackPrices["text-embedding-3-small"] = &ModelPricing{
+		InputPricePerToken: 0.02e-6, // $0.02 per MTok
+	}
+	s.fallbackPrices["text-embedding-3-large"] = &ModelPricing{
+		InputPricePerToken: 0.13e-6, // $0.13 per MTok
+	}
+	s.fallbackPrices["text-embedding-ada-002"] = &ModelPricing{
+		InputPricePerToken: 0.1e-6, // $0.10 per MTok
+	}
+	s.fallbackPrices["gemini-embedding"] = &ModelPricing{
+		InputPricePerToken: 0.15e-6, // $0.15 per MTok
+	}
 }
 
 // getFallbackPricing 根据模型系列获取回退价格
@@ -319,6 +343,18 @@ func (s *BillingService) getFallbackPricing(model string) *ModelPricing {
 	if strings.Contains(modelLower, "claude") {
 		return s.fallbackPrices["claude-sonnet-4"]
 	}
+	if strings.Contains(modelLower, "embedding") {
+		switch {
+		case strings.Contains(modelLower, "text-embedding-3-small"):
+			return s.fallbackPrices["text-embedding-3-small"]
+		case strings.Contains(modelLower, "text-embedding-3-large"):
+			return s.fallbackPrices["text-embedding-3-large"]
+		case strings.Contains(modelLower, "text-embedding-ada-002"):
+			return s.fallbackPrices["text-embedding-ada-002"]
+		case strings.Contains(modelLower, "gemini-embedding"), strings.Contains(modelLower, "text-embedding-004"):
+			return s.fallbackPrices["gemini-embedding"]
+		}
+	}
 	if strings.Contains(modelLower, "gemini-3.1-pro") || strings.Contains(modelLower, "gemini-3-1-pro") {
 		return s.fallbackPrices["gemini-3.1-pro"]
 	}
@@ -379,6 +415,12 @@ func (s *BillingService) getFallbackPricing(model string) *ModelPricing {
 
 // GetModelPricing 获取模型价格配置
 func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
+	pricing, _, err := s.resolveModelPricing(model)
+	return pricing, err
+}
+
+// resolveModelPricing 从全局价格表获取模型价格，并返回价格来源
+func (s *BillingService) resolveModelPricing(model string) (*ModelPricing, string, error) {
 	// 标准化模型名称（转小写）
 	model = strings.ToLower(model)
 
@@ -406,7 +448,7 @@ func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
 				LongContextInputThreshold:      litellmPricing.LongContextInputTokenThreshold,
 				LongContextInputMultiplier:     litellmPricing.LongContextInputCostMultiplier,
 				LongContextOutputMultiplier:    litellmPricing.LongContextOutputCostMultiplier,
-			}), nil
+			}), PriceSourceLiteLLM, nil
 		}
 	}
 
@@ -414,24 +456,31 @@ func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
 	fallback := s.getFallbackPricing(model)
 	if fallback != nil {
 		log.Printf("[Billing] Using fallback pricing for model: %s", model)
-		return s.applyModelSpecificPricingPolicy(model, fallback), nil
+		return s.applyModelSpecificPricingPolicy(model, fallback), PriceSourceFallback, nil
 	}
 
-	return nil, fmt.Errorf("pricing not found for model: %s", model)
+	return nil, "", fmt.Errorf("pricing not found for model: %s", model)
 }
 
 // CalculateCost 计算使用费用
 func (s *BillingService) CalculateCost(model string, tokens UsageTokens, rateMultiplier float64) (*CostBreakdown, error) {
-	return s.CalculateCostWithServiceTier(model, tokens, rateMultiplier, "")
+	return s.CalculateCostWithServiceTier(model, tokens, rateMultiplier, "", nil)
 }
 
-func (s *BillingService) CalculateCostWithServiceTier(model string, tokens UsageTokens, rateMultiplier float64, serviceTier string) (*CostBreakdown, error) {
-	pricing, err := s.GetModelPricing(model)
+// CalculateCostWithServiceTier 按 service tier 计算费用。
+// group 配置了命中该模型的自定义价格时优先于全局价格表（未设置的价格项沿用全局价格）。
+func (s *BillingService) CalculateCostWithServiceTier(model string, tokens UsageTokens, rateMultiplier float64, serviceTier string, group *Group) (*CostBreakdown, error) {
+	pricing, priceSource, err := s.resolveModelPricing(model)
+	if groupPrice, ok := group.ResolveModelPrice(model); ok {
+		pricing = applyGroupModelPrice(pricing, groupPrice)
+		priceSource = PriceSourceGroup
+		err = nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	breakdown := &CostBreakdown{}
+	breakdown := &CostBreakdown{PriceSource: priceSource}
 	inputPricePerToken := pricing.InputPricePerToken
 	outputPricePerToken := pricing.OutputPricePerToken
 	cacheReadPricePerToken := pricing.CacheReadPricePerToken
@@ -548,20 +597,21 @@ func (s *BillingService) CalculateCostWithConfig(model string, tokens UsageToken
 // CalculateCostWithLongContext 计算费用，支持长上下文双倍计费
 // threshold: 阈值（如 200000），超过此值的部分按 extraMultiplier 倍计费
 // extraMultiplier: 超出部分的倍率（如 2.0 表示双倍）
+// group: 分组自定义模型价格来源（可为 nil）
 //
 // 示例：缓存 210k + 输入 10k = 220k，阈值 200k，倍率 2.0
 // 拆分为：范围内 (200k, 0) + 范围外 (10k, 10k)
 // 范围内正常计费，范围外 × 2 计费
-func (s *BillingService) CalculateCostWithLongContext(model string, tokens UsageTokens, rateMultiplier float64, threshold int, extraMultiplier float64) (*CostBreakdown, error) {
+func (s *BillingService) CalculateCostWithLongContext(model string, tokens UsageTokens, rateMultiplier float64, threshold int, extraMultiplier float64, group *Group) (*CostBreakdown, error) {
 	// 未启用长上下文计费，直接走正常计费
 	if threshold <= 0 || extraMultiplier <= 1 {
-		return s.CalculateCost(model, tokens, rateMultiplier)
+		return s.CalculateCostWithServiceTier(model, tokens, rateMultiplier, "", group)
 	}
 
 	// 计算总输入 token（缓存读取 + 新输入）
 	total := tokens.CacheReadTokens + tokens.InputTokens
 	if total <= threshold {
-		return s.CalculateCost(model, tokens, rateMultiplier)
+		return s.CalculateCostWithServiceTier(model, tokens, rateMultiplier, "", group)
 	}
 
 	// 拆分成范围内和范围外
@@ -591,7 +641,7 @@ func (s *BillingService) CalculateCostWithLongContext(model string, tokens Usage
 		CacheCreation5mTokens: tokens.CacheCreation5mTokens,
 		CacheCreation1hTokens: tokens.CacheCreation1hTokens,
 	}
-	inRangeCost, err := s.CalculateCost(model, inRangeTokens, rateMultiplier)
+	inRangeCost, err := s.CalculateCostWithServiceTier(model, inRangeTokens, rateMultiplier, "", group)
 	if err != nil {
 		return nil, err
 	}
@@ -601,7 +651,7 @@ func (s *BillingService) CalculateCostWithLongContext(model string, tokens Usage
 		InputTokens:     outRangeInputTokens,
 		CacheReadTokens: outRangeCacheTokens,
 	}
-	outRangeCost, err := s.CalculateCost(model, outRangeTokens, rateMultiplier*extraMultiplier)
+	outRangeCost, err := s.CalculateCostWithServiceTier(model, outRangeTokens, rateMultiplier*extraMultiplier, "", group)
 	if err != nil {
 		return inRangeCost, fmt.Errorf("out-range cost: %w", err)
 	}
@@ -614,6 +664,7 @@ func (s *BillingService) CalculateCostWithLongContext(model string, tokens Usage
 		CacheReadCost:     inRangeCost.CacheReadCost + outRangeCost.CacheReadCost,
 		TotalCost:         inRangeCost.TotalCost + outRangeCost.TotalCost,
 		ActualCost:        inRangeCost.ActualCost + outRangeCost.ActualCost,
+		PriceSource:       inRangeCost.PriceSource,
 	}, nil
 }
 
@@ -637,14 +688,23 @@ func (s *BillingService) IsModelSupported(model string) bool {
 		strings.Contains(modelLower, "haiku")
 }
 
-// GetEstimatedCost 估算费用（用于前端展示）
-func (s *BillingService) GetEstimatedCost(model string, estimatedInputTokens, estimatedOutputTokens int) (float64, error) {
+// GetEstimatedCost 按请求体估算费用（用于前端展示与请求前预估）
+// 输入 token 由本地 token 估算器近似估算；estimatedOutputTokens <= 0 时取请求声明的最大输出 token 数。
+// group: 分组自定义模型价格来源（可为 nil），命中时优先于全局价格表，与实际计费一致。
+func (s *BillingService) GetEstimatedCost(group *Group, model string, body []byte, estimatedOutputTokens int) (float64, error) {
+	if estimatedOutputTokens <= 0 {
+		estimatedOutputTokens = tokenestimate.MaxOutputTokens(body)
+	}
 	tokens := UsageTokens{
-		InputTokens:  estimatedInputTokens,
+		InputTokens:  tokenestimate.CountRequest(model, body),
 		OutputTokens: estimatedOutputTokens,
 	}
 
-	breakdown, err := s.CalculateCostWithConfig(model, tokens)
+	multiplier := s.cfg.Default.RateMultiplier
+	if multiplier <= 0 {
+		multiplier = 1.0
+	}
+	breakdown, err := s.CalculateCostWithServiceTier(model, tokens, multiplier, "", group)
 	if err != nil {
 		return 0, err
 	}
@@ -716,6 +776,36 @@ func (s *BillingService) CalculateImageCost(model string, imageSize string, imag
 	}
 }
 
+// CalculateAudioCost 计算音频按量费用
+// durationSeconds: 转写/翻译的音频时长（秒），按 input_cost_per_second 计费
+// characters: 语音合成的输入字符数，按 input_cost_per_character 计费
+func (s *BillingService) CalculateAudioCost(model string, durationSeconds float64, characters int, rateMultiplier float64) (*CostBreakdown, error) {
+	var pricing *LiteLLMModelPricing
+	if s.pricingService != nil {
+		pricing = s.pricingService.GetModelPricing(model)
+	}
+	if pricing == nil || (pricing.InputCostPerSecond <= 0 && pricing.InputCostPerCharacter <= 0) {
+		return nil, fmt.Errorf("audio pricing not found for model: %s", model)
+	}
+
+	inputCost := 0.0
+	if durationSeconds > 0 {
+		inputCost += durationSeconds * pricing.InputCostPerSecond
+	}
+	if characters > 0 {
+		inputCost += float64(characters) * pricing.InputCostPerCharacter
+	}
+
+	if rateMultiplier <= 0 {
+		rateMultiplier = 1.0
+	}
+	return &CostBreakdown{
+		InputCost:  inputCost,
+		TotalCost:  inputCost,
+		ActualCost: inputCost * rateMultiplier,
+	}, nil
+}
+
 // CalculateSoraImageCost 计算 Sora 图片按次费用
 func (s *BillingService) CalculateSoraImageCost(imageSize string, imageCount int, groupConfig *SoraPriceConfig, rateMultiplier float64) *CostBreakdown {
 	if imageCount <= 0 {
@@ -264,7 +264,7 @@ func TestCalculateCostWithLongContext_BelowThreshold(t *testing.T) {
 		CacheReadTokens: 100000,
 	}
 	// 总输入 150k < 200k 阈值，应走正常计费
-	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 2.0)
+	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 2.0, nil)
 	require.NoError(t, err)
 
 	normalCost, err := svc.CalculateCost("claude-sonnet-4", tokens, 1.0)
@@ -283,7 +283,7 @@ func TestCalculateCostWithLongContext_AboveThreshold_CacheExceedsThreshold(t *te
 		OutputTokens:    1000,
 		CacheReadTokens: 210000,
 	}
-	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 2.0)
+	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 2.0, nil)
 	require.NoError(t, err)
 
 	// 范围内：200k cache + 0 input + 1k output
@@ -312,7 +312,7 @@ func TestCalculateCostWithLongContext_AboveThreshold_CacheBelowThreshold(t *test
 		OutputTokens:    1000,
 		CacheReadTokens: 100000,
 	}
-	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 2.0)
+	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 2.0, nil)
 	require.NoError(t, err)
 
 	require.True(t, cost.ActualCost > 0, "费用应大于 0")
@@ -328,7 +328,7 @@ func TestCalculateCostWithLongContext_DisabledThreshold(t *testing.T) {
 	tokens := UsageTokens{InputTokens: 300000, CacheReadTokens: 0}
 
 	// threshold <= 0 应禁用长上下文计费
-	cost1, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 0, 2.0)
+	cost1, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 0, 2.0, nil)
 	require.NoError(t, err)
 
 	cost2, err := svc.CalculateCost("claude-sonnet-4", tokens, 1.0)
@@ -343,7 +343,7 @@ func TestCalculateCostWithLongContext_ExtraMultiplierLessEqualOne(t *testing.T)
 	tokens := UsageTokens{InputTokens: 300000}
 
 	// extraMultiplier <= 1 应禁用长上下文计费
-	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 1.0)
+	cost, err := svc.CalculateCostWithLongContext("claude-sonnet-4", tokens, 1.0, 200000, 1.0, nil)
 	require.NoError(t, err)
 
 	normalCost, err := svc.CalculateCost("claude-sonnet-4", tokens, 1.0)
@@ -435,9 +435,28 @@ func TestCalculateCostWithConfig_ZeroMultiplier(t *testing.T) {
 func TestGetEstimatedCost(t *testing.T) {
 	svc := newTestBillingService()
 
-	est, err := svc.GetEstimatedCost("claude-sonnet-4", 1000, 500)
+	body := []byte(`{"model":"claude-sonnet-4","max_tokens":500,"messages":[{"role":"user","content":"Summarize the quarterly report in three bullet points."}]}`)
+	est, err := svc.GetEstimatedCost(nil, "claude-sonnet-4", body, 0)
 	require.NoError(t, err)
 	require.True(t, est > 0)
+
+	// 显式传入的输出 token 数优先于 max_tokens
+	larger, err := svc.GetEstimatedCost(nil, "claude-sonnet-4", body, 5000)
+	require.NoError(t, err)
+	require.Greater(t, larger, est)
+
+	// 分组自定义价格优先于全局价格表
+	zero := 0.0
+	free := &Group{ModelPrices: map[string]GroupModelPrice{"claude-sonnet-*": {InputPrice: &zero, OutputPrice: &zero}}}
+	groupEst, err := svc.GetEstimatedCost(free, "claude-sonnet-4", body, 0)
+	require.NoError(t, err)
+	require.Zero(t, groupEst)
+
+	double := 6.0
+	pricier := &Group{ModelPrices: map[string]GroupModelPrice{"claude-sonnet-4": {InputPrice: &double}}}
+	groupEst, err = svc.GetEstimatedCost(pricier, "claude-sonnet-4", body, 0)
+	require.NoError(t, err)
+	require.Greater(t, groupEst, est)
 }
 
 func TestListSupportedModels(t *testing.T) {
@@ -499,7 +518,7 @@ func TestCalculateCostWithLongContext_PropagatesError(t *testing.T) {
 	}
 
 	tokens := UsageTokens{InputTokens: 300000, CacheReadTokens: 0}
-	_, err := svc.CalculateCostWithLongContext("unknown-model", tokens, 1.0, 200000, 2.0)
+	_, err := svc.CalculateCostWithLongContext("unknown-model", tokens, 1.0, 200000, 2.0, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "pricing not found")
 }
@@ -564,7 +583,7 @@ func TestCalculateCostWithServiceTier_OpenAIPriorityUsesPriorityPricing(t *testi
 	baseCost, err := svc.CalculateCost("gpt-5.1-codex", tokens, 1.0)
 	require.NoError(t, err)
 
-	priorityCost, err := svc.CalculateCostWithServiceTier("gpt-5.1-codex", tokens, 1.0, "priority")
+	priorityCost, err := svc.CalculateCostWithServiceTier("gpt-5.1-codex", tokens, 1.0, "priority", nil)
 	require.NoError(t, err)
 
 	require.InDelta(t, baseCost.InputCost*2, priorityCost.InputCost, 1e-10)
@@ -580,7 +599,7 @@ func TestCalculateCostWithServiceTier_FlexAppliesHalfMultiplier(t *testing.T) {
 	baseCost, err := svc.CalculateCost("gpt-5.4", tokens, 1.0)
 	require.NoError(t, err)
 
-	flexCost, err := svc.CalculateCostWithServiceTier("gpt-5.4", tokens, 1.0, "flex")
+	flexCost, err := svc.CalculateCostWithServiceTier("gpt-5.4", tokens, 1.0, "flex", nil)
 	require.NoError(t, err)
 
 	require.InDelta(t, baseCost.InputCost*0.5, flexCost.InputCost, 1e-10)
@@ -597,7 +616,7 @@ func TestCalculateCostWithServiceTier_Gpt54MiniPriorityFallsBackToTierMultiplier
 	baseCost, err := svc.CalculateCost("gpt-5.4-mini", tokens, 1.0)
 	require.NoError(t, err)
 
-	priorityCost, err := svc.CalculateCostWithServiceTier("gpt-5.4-mini", tokens, 1.0, "priority")
+	priorityCost, err := svc.CalculateCostWithServiceTier("gpt-5.4-mini", tokens, 1.0, "priority", nil)
 	require.NoError(t, err)
 
 	require.InDelta(t, baseCost.InputCost*2, priorityCost.InputCost, 1e-10)
@@ -614,7 +633,7 @@ func TestCalculateCostWithServiceTier_Gpt54NanoFlexAppliesHalfMultiplier(t *test
 	baseCost, err := svc.CalculateCost("gpt-5.4-nano", tokens, 1.0)
 	require.NoError(t, err)
 
-	flexCost, err := svc.CalculateCostWithServiceTier("gpt-5.4-nano", tokens, 1.0, "flex")
+	flexCost, err := svc.CalculateCostWithServiceTier("gpt-5.4-nano", tokens, 1.0, "flex", nil)
 	require.NoError(t, err)
 
 	require.InDelta(t, baseCost.InputCost*0.5, flexCost.InputCost, 1e-10)
@@ -631,7 +650,7 @@ func TestCalculateCostWithServiceTier_PriorityFallsBackToTierMultiplierWithoutEx
 	baseCost, err := svc.CalculateCost("claude-sonnet-4", tokens, 1.0)
 	require.NoError(t, err)
 
-	priorityCost, err := svc.CalculateCostWithServiceTier("claude-sonnet-4", tokens, 1.0, "priority")
+	priorityCost, err := svc.CalculateCostWithServiceTier("claude-sonnet-4", tokens, 1.0, "priority", nil)
 	require.NoError(t, err)
 
 	require.InDelta(t, baseCost.InputCost*2, priorityCost.InputCost, 1e-10)
@@ -706,7 +725,7 @@ func TestCalculateCostWithServiceTier_PriorityFallsBackToTierMultiplierWhenExpli
 	baseCost, err := svc.CalculateCost("custom-no-priority", tokens, 1.0)
 	require.NoError(t, err)
 
-	priorityCost, err := svc.CalculateCostWithServiceTier("custom-no-priority", tokens, 1.0, "priority")
+	priorityCost, err := svc.CalculateCostWithServiceTier("custom-no-priority", tokens, 1.0, "priority", nil)
 	require.NoError(t, err)
 
 	require.InDelta(t, baseCost.InputCost*2, priorityCost.InputCost, 1e-10)
@@ -734,6 +753,23 @@ func TestGetModelPricing_OpenAIGpt52FallbacksExposePriorityPrices(t *testing.T)
 	require.InDelta(t, 28e-6, gpt52Codex.OutputPricePerTokenPriority, 1e-12)
 }
 
+func TestGetModelPricing_EmbeddingFallbacks(t *testing.T) {
+	svc := newTestBillingService()
+
+	small, err := svc.GetModelPricing("text-embedding-3-small")
+	require.NoError(t, err)
+	require.InDelta(t, 0.02e-6, small.InputPricePerToken, 1e-15)
+	require.Zero(t, small.OutputPricePerToken)
+
+	gemini, err := svc.GetModelPricing("gemini-embedding-001")
+	require.NoError(t, err)
+	require.InDelta(t, 0.15e-6, gemini.InputPricePerToken, 1e-15)
+
+	cost, err := svc.CalculateCost("text-embedding-3-large", UsageTokens{InputTokens: 1_000_000}, 1.0)
+	require.NoError(t, err)
+	require.InDelta(t, 0.13, cost.ActualCost, 1e-9)
+}
+
 func TestGetModelPricing_MapsDynamicPriorityFieldsIntoBillingPricing(t *testing.T) {
 	svc := NewBillingService(&config.Config{}, &PricingService{
 		pricingData: map[string]*LiteLLMModelPricing{
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -149,6 +150,7 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 
 	// Redis unavailable — fail-open: allow request through without concurrency control
 	if errors.Is(err, ErrRedisUnavailable) {
+		accountSlotAcquireTotal.Inc("degraded")
 		return &AcquireResult{
 			Acquired:    true,
 			Degraded:    true,
@@ -156,13 +158,18 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 		}, nil
 	}
 	if err != nil {
+		accountSlotAcquireTotal.Inc("error")
 		return nil, err
 	}
 
 	if acquired {
+		accountSlotAcquireTotal.Inc("acquired")
+		accountSlotsInUse.Add(1)
+		var releaseOnce sync.Once
 		return &AcquireResult{
 			Acquired: true,
 			ReleaseFunc: func() {
+				releaseOnce.Do(func() { accountSlotsInUse.Add(-1) })
 				bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 				if err := s.cache.ReleaseAccountSlot(bgCtx, accountID, requestID); err != nil {
@@ -172,6 +179,7 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 		}, nil
 	}
 
+	accountSlotAcquireTotal.Inc("rejected")
 	return &AcquireResult{
 		Acquired:    false,
 		ReleaseFunc: nil,
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	gocache "github.com/patrickmn/go-cache"
 )
 
@@ -17,10 +20,23 @@ type sessionEntry struct {
 	accountID int64
 }
 
-// DigestSessionStore 内存摘要会话存储（flat cache 实现）
+// DigestSessionCache 多实例共享的摘要会话存储（Redis 实现）。
+//
+// 仅在 gateway.digest_session.backend=redis 时启用；访问失败时调用方回退到本地内存存储。
+type DigestSessionCache interface {
+	// FindDigestSession 按顺序（最长优先）探测候选 chain，返回第一个命中项
+	FindDigestSession(ctx context.Context, groupID int64, prefixHash string, chains []string) (uuid string, accountID int64, matchedChain string, found bool, err error)
+	// SaveDigestSession 写入会话并删除旧 chain 对应的 key
+	SaveDigestSession(ctx context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string, ttl time.Duration) error
+}
+
+// DigestSessionStore 摘要会话存储（flat cache 实现）
 // key: "{groupID}:{prefixHash}|{digestChain}" → *sessionEntry
+//
+// 配置了 remote 时优先读写共享存储，本地缓存始终同步写入，共享存储异常时回退到本地结果。
 type DigestSessionStore struct {
-	cache *gocache.Cache
+	cache  *gocache.Cache
+	remote DigestSessionCache
 }
 
 // NewDigestSessionStore 创建内存摘要会话存储
@@ -30,8 +46,29 @@ func NewDigestSessionStore() *DigestSessionStore {
 	}
 }
 
+// NewSharedDigestSessionStore 创建以共享存储为主、内存为回退的摘要会话存储
+func NewSharedDigestSessionStore(remote DigestSessionCache) *DigestSessionStore {
+	s := NewDigestSessionStore()
+	s.remote = remote
+	return s
+}
+
+// ProvideDigestSessionStore 按 gateway.digest_session.backend 选择摘要会话存储后端
+func ProvideDigestSessionStore(cfg *config.Config, remote DigestSessionCache) *DigestSessionStore {
+	if cfg != nil && remote != nil &&
+		strings.EqualFold(strings.TrimSpace(cfg.Gateway.DigestSession.Backend), config.DigestSessionBackendRedis) {
+		return NewSharedDigestSessionStore(remote)
+	}
+	return NewDigestSessionStore()
+}
+
 // Save 保存摘要会话。oldDigestChain 为 Find 返回的 matchedChain，用于删旧 key。
 func (s *DigestSessionStore) Save(groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string) {
+	s.SaveContext(context.Background(), groupID, prefixHash, digestChain, uuid, accountID, oldDigestChain)
+}
+
+// SaveContext 同 Save，共享存储写入失败时仅记录日志（本地缓存已写入）。
+func (s *DigestSessionStore) SaveContext(ctx context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string) {
 	if digestChain == "" {
 		return
 	}
@@ -40,13 +77,32 @@ func (s *DigestSessionStore) Save(groupID int64, prefixHash, digestChain, uuid s
 	if oldDigestChain != "" && oldDigestChain != digestChain {
 		s.cache.Delete(ns + oldDigestChain)
 	}
+	if s.remote != nil {
+		if err := s.remote.SaveDigestSession(ctx, groupID, prefixHash, digestChain, uuid, accountID, oldDigestChain, digestSessionTTL); err != nil {
+			logger.LegacyPrintf("service.digest_session", "[DigestSession] save to shared store failed: group=%d err=%v", groupID, err)
+		}
+	}
 }
 
 // Find 查找摘要会话，从完整 chain 逐段截断，返回最长匹配及对应 matchedChain。
 func (s *DigestSessionStore) Find(groupID int64, prefixHash, digestChain string) (uuid string, accountID int64, matchedChain string, found bool) {
+	return s.FindContext(context.Background(), groupID, prefixHash, digestChain)
+}
+
+// FindContext 同 Find。配置了共享存储时一次性批量探测所有截断前缀，
+// 共享存储出错时回退到本地缓存。
+func (s *DigestSessionStore) FindContext(ctx context.Context, groupID int64, prefixHash, digestChain string) (uuid string, accountID int64, matchedChain string, found bool) {
 	if digestChain == "" {
 		return "", 0, "", false
 	}
+	if s.remote != nil {
+		uuid, accountID, matchedChain, found, err := s.remote.FindDigestSession(ctx, groupID, prefixHash, digestChainCandidates(digestChain))
+		if err == nil {
+			return uuid, accountID, matchedChain, found
+		}
+		logger.LegacyPrintf("service.digest_session", "[DigestSession] find in shared store failed, fallback to local: group=%d err=%v", groupID, err)
+	}
+
 	ns := buildNS(groupID, prefixHash)
 	chain := digestChain
 	for {
@@ -63,6 +119,20 @@ func (s *DigestSessionStore) Find(groupID int64, prefixHash, digestChain string)
 	}
 }
 
+// digestChainCandidates 返回 chain 的全部截断前缀（含自身），按长度降序排列
+func digestChainCandidates(digestChain string) []string {
+	candidates := []string{digestChain}
+	chain := digestChain
+	for {
+		i := strings.LastIndex(chain, "-")
+		if i < 0 {
+			return candidates
+		}
+		chain = chain[:i]
+		candidates = append(candidates, chain)
+	}
+}
+
 // buildNS 构建 namespace 前缀
 func buildNS(groupID int64, prefixHash string) string {
 	return strconv.FormatInt(groupID, 10) + ":" + prefixHash + "|"
@@ -3,11 +3,14 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	gocache "github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -310,3 +313,100 @@ func TestDigestSessionStore_SaveSameChainNoDelete(t *testing.T) {
 	assert.Equal(t, "uuid-1", uuid)
 	assert.Equal(t, int64(100), accountID)
 }
+
+// fakeDigestSessionCache 模拟共享存储：map 记录会话并统计批量探测次数
+type fakeDigestSessionCache struct {
+	mu       sync.Mutex
+	entries  map[string]sessionEntry
+	findErr  error
+	saveErr  error
+	findCall int
+	lastKeys []string
+}
+
+func newFakeDigestSessionCache() *fakeDigestSessionCache {
+	return &fakeDigestSessionCache{entries: make(map[string]sessionEntry)}
+}
+
+func (f *fakeDigestSessionCache) FindDigestSession(_ context.Context, groupID int64, prefixHash string, chains []string) (string, int64, string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.findCall++
+	f.lastKeys = chains
+	if f.findErr != nil {
+		return "", 0, "", false, f.findErr
+	}
+	ns := buildNS(groupID, prefixHash)
+	for _, chain := range chains {
+		if e, ok := f.entries[ns+chain]; ok {
+			return e.uuid, e.accountID, chain, true, nil
+		}
+	}
+	return "", 0, "", false, nil
+}
+
+func (f *fakeDigestSessionCache) SaveDigestSession(_ context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	ns := buildNS(groupID, prefixHash)
+	f.entries[ns+digestChain] = sessionEntry{uuid: uuid, accountID: accountID}
+	if oldDigestChain != "" && oldDigestChain != digestChain {
+		delete(f.entries, ns+oldDigestChain)
+	}
+	return nil
+}
+
+func TestDigestChainCandidates(t *testing.T) {
+	require.Equal(t, []string{"s:a-u:b-m:c", "s:a-u:b", "s:a"}, digestChainCandidates("s:a-u:b-m:c"))
+	require.Equal(t, []string{"u:a"}, digestChainCandidates("u:a"))
+}
+
+func TestDigestSessionStore_SharedAcrossInstances(t *testing.T) {
+	remote := newFakeDigestSessionCache()
+	a := NewSharedDigestSessionStore(remote)
+	b := NewSharedDigestSessionStore(remote)
+
+	a.Save(1, "prefix", "s:a1-u:b2", "uuid-1", 100, "")
+
+	// 另一实例通过共享存储命中最长前缀，且只做一次批量探测
+	uuid, accountID, matched, found := b.FindContext(context.Background(), 1, "prefix", "s:a1-u:b2-m:c3-u:d4")
+	require.True(t, found)
+	assert.Equal(t, "uuid-1", uuid)
+	assert.Equal(t, int64(100), accountID)
+	assert.Equal(t, "s:a1-u:b2", matched)
+	assert.Equal(t, 1, remote.findCall)
+	assert.Len(t, remote.lastKeys, 4)
+
+	// 续写后旧 key 被删除
+	b.Save(1, "prefix", "s:a1-u:b2-m:c3-u:d4", "uuid-1", 100, matched)
+	_, _, _, found = a.Find(1, "prefix", "s:a1-u:b2-x:zz")
+	require.False(t, found)
+}
+
+func TestDigestSessionStore_RemoteErrorFallsBackToLocal(t *testing.T) {
+	remote := newFakeDigestSessionCache()
+	remote.saveErr = errors.New("redis down")
+	remote.findErr = errors.New("redis down")
+	store := NewSharedDigestSessionStore(remote)
+
+	store.Save(1, "prefix", "u:a-m:b", "uuid-1", 100, "")
+
+	uuid, accountID, matched, found := store.Find(1, "prefix", "u:a-m:b-u:c")
+	require.True(t, found)
+	assert.Equal(t, "uuid-1", uuid)
+	assert.Equal(t, int64(100), accountID)
+	assert.Equal(t, "u:a-m:b", matched)
+}
+
+func TestProvideDigestSessionStore(t *testing.T) {
+	remote := newFakeDigestSessionCache()
+
+	cfg := &config.Config{}
+	require.Nil(t, ProvideDigestSessionStore(cfg, remote).remote)
+
+	cfg.Gateway.DigestSession.Backend = " Redis "
+	require.NotNil(t, ProvideDigestSessionStore(cfg, remote).remote)
+}
@@ -63,6 +63,12 @@ const (
 	SubscriptionTypeSubscription = domain.SubscriptionTypeSubscription // 订阅模式（按限额控制）
 )
 
+// User billing mode constants
+const (
+	BillingModePrepaid  = domain.BillingModePrepaid  // 预付费
+	BillingModePostpaid = domain.BillingModePostpaid // 后付费（月结）
+)
+
 // Subscription status constants
 const (
 	SubscriptionStatusActive    = domain.SubscriptionStatusActive
@@ -179,6 +185,9 @@ const (
 	// SettingKeyOpsRuntimeLogConfig stores JSON config for runtime log settings.
 	SettingKeyOpsRuntimeLogConfig = "ops_runtime_log_config"
 
+	// SettingKeyOpsPayloadCaptureSettings stores JSON config for sampled payload capture rules.
+	SettingKeyOpsPayloadCaptureSettings = "ops_payload_capture_settings"
+
 	// =========================
 	// Overload Cooldown (529)
 	// =========================
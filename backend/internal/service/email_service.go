@@ -1,15 +1,21 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/subtle"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"net/url"
 	"strconv"
 	"strings"
@@ -81,6 +87,13 @@ type SMTPConfig struct {
 	UseTLS   bool
 }
 
+// EmailAttachment 邮件附件
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
 // EmailService 邮件服务
 type EmailService struct {
 	settingRepo SettingRepository
@@ -148,22 +161,103 @@ func (s *EmailService) SendEmail(ctx context.Context, to, subject, body string)
 
 // SendEmailWithConfig 使用指定配置发送邮件
 func (s *EmailService) SendEmailWithConfig(config *SMTPConfig, to, subject, body string) error {
-	from := config.From
-	if config.FromName != "" {
-		from = fmt.Sprintf("%s <%s>", config.FromName, config.From)
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s",
+		formatEmailFrom(config), to, subject, body)
+	return s.deliver(config, to, []byte(msg))
+}
+
+// SendEmailWithAttachments 发送带附件的 HTML 邮件（使用数据库中保存的配置）
+func (s *EmailService) SendEmailWithAttachments(ctx context.Context, to, subject, body string, attachments []EmailAttachment) error {
+	config, err := s.GetSMTPConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return s.SendEmailWithConfig(config, to, subject, body)
 	}
+	msg, err := buildMultipartEmailMessage(formatEmailFrom(config), to, subject, body, attachments)
+	if err != nil {
+		return err
+	}
+	return s.deliver(config, to, msg)
+}
 
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s",
-		from, to, subject, body)
+func formatEmailFrom(config *SMTPConfig) string {
+	if config.FromName != "" {
+		return fmt.Sprintf("%s <%s>", config.FromName, config.From)
+	}
+	return config.From
+}
 
+// deliver 按配置选择 TLS 直连或 STARTTLS/明文发送已组装好的报文
+func (s *EmailService) deliver(config *SMTPConfig, to string, msg []byte) error {
 	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
 	auth := smtp.PlainAuth("", config.Username, config.Password, config.Host)
 
 	if config.UseTLS {
-		return s.sendMailTLS(addr, auth, config.From, to, []byte(msg), config.Host)
+		return s.sendMailTLS(addr, auth, config.From, to, msg, config.Host)
+	}
+
+	return smtp.SendMail(addr, auth, config.From, []string{to}, msg)
+}
+
+// buildMultipartEmailMessage 组装 multipart/mixed 报文：HTML 正文 + base64 编码的附件
+func buildMultipartEmailMessage(from, to, subject, body string, attachments []EmailAttachment) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%q\r\n\r\n",
+		from, to, subject, w.Boundary())
+
+	bodyHeader := textproto.MIMEHeader{}
+	bodyHeader.Set("Content-Type", "text/html; charset=UTF-8")
+	bodyHeader.Set("Content-Transfer-Encoding", "base64")
+	part, err := w.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64Lines(part, []byte(body)); err != nil {
+		return nil, err
 	}
 
-	return smtp.SendMail(addr, auth, config.From, []string{to}, []byte(msg))
+	for _, att := range attachments {
+		contentType := strings.TrimSpace(att.ContentType)
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		name := mime.QEncoding.Encode("UTF-8", att.FileName)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("%s; name=%q", contentType, name))
+		header.Set("Content-Transfer-Encoding", "base64")
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, att.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64Lines 按 RFC 2045 每行 76 字符写出 base64 内容
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := 76
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+		if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
 }
 
 // sendMailTLS 使用TLS发送邮件
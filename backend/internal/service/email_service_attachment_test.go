@@ -0,0 +1,48 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMultipartEmailMessage(t *testing.T) {
+	raw, err := buildMultipartEmailMessage("Ops <ops@example.com>", "fin@example.com", "[Ops Report] 用户消费明细", "<p>hi</p>", []EmailAttachment{
+		{FileName: "user_spend_20260901-20260930.csv", ContentType: "text/csv; charset=UTF-8", Data: []byte("a,b\n1,2\n")},
+	})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	body, err := mr.NextPart()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(body.Header.Get("Content-Type"), "text/html"))
+	decoded, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, body))
+	require.NoError(t, err)
+	require.Equal(t, "<p>hi</p>", string(decoded))
+
+	att, err := mr.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "user_spend_20260901-20260930.csv", att.FileName())
+	decoded, err = io.ReadAll(base64.NewDecoder(base64.StdEncoding, att))
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n1,2\n", string(decoded))
+
+	_, err = mr.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// defaultGatewayAccountScheduler Anthropic/Gemini 网关账号调度器。
+// 分层逻辑（模型路由 → 粘性会话 → 负载均衡 → 兜底排队）由 GatewayService 实现，
+// 负载均衡层按分组配置的调度策略排序候选账号。
+type defaultGatewayAccountScheduler struct {
+	service *GatewayService
+	metrics accountSchedulerMetrics
+	stats   *accountRuntimeStats
+}
+
+func newDefaultGatewayAccountScheduler(service *GatewayService, stats *accountRuntimeStats) AccountScheduler {
+	if stats == nil {
+		stats = newAccountRuntimeStats()
+	}
+	return &defaultGatewayAccountScheduler{
+		service: service,
+		stats:   stats,
+	}
+}
+
+func (s *defaultGatewayAccountScheduler) Select(ctx context.Context, req AccountScheduleRequest) (selection *AccountSelectionResult, decision AccountScheduleDecision, err error) {
+	ctx, span := startAccountSelectSpan(ctx, "scheduler.select", req.GroupID, req.RequestedModel, len(req.ExcludedIDs))
+	start := time.Now()
+	defer func() {
+		decision.LatencyMs = time.Since(start).Milliseconds()
+		s.metrics.recordSelect(decision)
+		endAccountSelectSpan(span, decision, err)
+	}()
+
+	selection, err = s.service.selectAccountWithLoadAwareness(ctx, req.GroupID, req.SessionHash, req.RequestedModel, req.ExcludedIDs, &decision)
+	if err != nil {
+		return nil, decision, err
+	}
+	if selection != nil && selection.Account != nil {
+		decision.SelectedAccountID = selection.Account.ID
+		decision.SelectedAccountType = selection.Account.Type
+	}
+	return selection, decision, nil
+}
+
+func (s *defaultGatewayAccountScheduler) ReportResult(accountID int64, success bool, firstTokenMs *int) {
+	if s == nil || s.stats == nil {
+		return
+	}
+	s.stats.report(accountID, success, firstTokenMs)
+}
+
+func (s *defaultGatewayAccountScheduler) ReportSwitch() {
+	if s == nil {
+		return
+	}
+	s.metrics.recordSwitch()
+}
+
+func (s *defaultGatewayAccountScheduler) SnapshotMetrics() AccountSchedulerMetricsSnapshot {
+	if s == nil {
+		return AccountSchedulerMetricsSnapshot{}
+	}
+	return s.metrics.snapshot(s.stats)
+}
+
+func (s *GatewayService) getAccountScheduler() AccountScheduler {
+	if s == nil {
+		return nil
+	}
+	s.accountSchedulerOnce.Do(func() {
+		if s.accountStats == nil {
+			s.accountStats = newAccountRuntimeStats()
+		}
+		if s.accountScheduler == nil {
+			s.accountScheduler = newDefaultGatewayAccountScheduler(s, s.accountStats)
+		}
+	})
+	return s.accountScheduler
+}
+
+// ReportAccountScheduleResult 上报账号请求结果，用于更新错误率与首 Token 耗时 EWMA
+func (s *GatewayService) ReportAccountScheduleResult(accountID int64, success bool, firstTokenMs *int) {
+	scheduler := s.getAccountScheduler()
+	if scheduler == nil {
+		return
+	}
+	scheduler.ReportResult(accountID, success, firstTokenMs)
+}
+
+// RecordAccountSwitch 记录一次故障转移导致的账号切换
+func (s *GatewayService) RecordAccountSwitch() {
+	scheduler := s.getAccountScheduler()
+	if scheduler == nil {
+		return
+	}
+	scheduler.ReportSwitch()
+}
+
+// SnapshotAccountSchedulerMetrics 返回调度器决策指标快照
+func (s *GatewayService) SnapshotAccountSchedulerMetrics() AccountSchedulerMetricsSnapshot {
+	scheduler := s.getAccountScheduler()
+	if scheduler == nil {
+		return AccountSchedulerMetricsSnapshot{}
+	}
+	return scheduler.SnapshotMetrics()
+}
@@ -533,7 +533,7 @@ func TestGatewayService_AnthropicAPIKeyPassthrough_EmptyModelSkipsMapping(t *tes
 	require.Equal(t, body, upstream.lastBody, "空模型名时请求体不应被修改")
 }
 
-func TestGatewayService_AnthropicAPIKeyPassthrough_CountTokens404PassthroughNotError(t *testing.T) {
+func TestGatewayService_AnthropicAPIKeyPassthrough_CountTokens404LocalEstimateNotError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
@@ -543,7 +543,7 @@ func TestGatewayService_AnthropicAPIKeyPassthrough_CountTokens404PassthroughNotE
 		wantPassthrough bool
 	}{
 		{
-			name:            "404 endpoint not found passes through as 404",
+			name:            "404 endpoint not found falls back to local estimate",
 			statusCode:      http.StatusNotFound,
 			respBody:        `{"error":{"message":"Not found: /v1/messages/count_tokens","type":"not_found_error"}}`,
 			wantPassthrough: true,
@@ -617,15 +617,16 @@ func TestGatewayService_AnthropicAPIKeyPassthrough_CountTokens404PassthroughNotE
 			err := svc.ForwardCountTokens(context.Background(), c, account, parsed)
 
 			if tt.wantPassthrough {
-				// 返回 nil（不记录为错误），HTTP 状态码 404 + Anthropic 错误体
+				// 返回 nil（不记录为错误），由本地估算器返回 200 + input_tokens
 				require.NoError(t, err)
-				require.Equal(t, http.StatusNotFound, rec.Code)
-				var errResp map[string]any
-				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
-				require.Equal(t, "error", errResp["type"])
-				errObj, ok := errResp["error"].(map[string]any)
+				require.Equal(t, http.StatusOK, rec.Code)
+				var countResp map[string]any
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &countResp))
+				inputTokens, ok := countResp["input_tokens"].(float64)
 				require.True(t, ok)
-				require.Equal(t, "not_found_error", errObj["type"])
+				require.Greater(t, inputTokens, float64(0))
+				require.NotContains(t, countResp, "estimated")
+				require.Equal(t, "true", rec.Header().Get(CountTokensEstimatedHeader))
 			} else {
 				require.Error(t, err)
 				require.Equal(t, tt.statusCode, rec.Code)
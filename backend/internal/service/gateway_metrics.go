@@ -0,0 +1,162 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+)
+
+// Prometheus 指标定义。
+// 标签仅使用有限取值（平台、分组 ID、模型、状态码分类），且每个指标受 metrics.DefaultMaxSeries 约束。
+var (
+	gatewayRequestsTotal = metrics.Default.NewCounterVec(
+		"sub2api_gateway_requests_total",
+		"Total gateway requests by platform, group, model and status class.",
+		"platform", "group", "model", "status",
+	)
+	gatewayRequestDuration = metrics.Default.NewHistogramVec(
+		"sub2api_gateway_request_duration_seconds",
+		"Gateway request latency in seconds.",
+		metrics.DefaultDurationBuckets,
+		"platform", "group", "model", "status",
+	)
+	gatewayFailoverTotal = metrics.Default.NewCounterVec(
+		"sub2api_gateway_failover_total",
+		"Account failovers triggered by upstream errors, by platform and upstream status.",
+		"platform", "status",
+	)
+	tokenRefreshTotal = metrics.Default.NewCounterVec(
+		"sub2api_token_refresh_total",
+		"OAuth token refresh outcomes.",
+		"platform", "result",
+	)
+	accountSlotAcquireTotal = metrics.Default.NewCounterVec(
+		"sub2api_account_slot_acquire_total",
+		"Account concurrency slot acquire attempts by result.",
+		"result",
+	)
+	accountSlotsInUse = metrics.Default.NewGaugeVec(
+		"sub2api_account_slots_in_use",
+		"Account concurrency slots currently held by this instance.",
+	)
+	schedulerOutboxLagSeconds = metrics.Default.NewGaugeVec(
+		"sub2api_scheduler_outbox_lag_seconds",
+		"Age of the oldest unprocessed scheduler outbox event in seconds.",
+	)
+)
+
+const (
+	tokenRefreshResultRefreshed = "refreshed"
+	tokenRefreshResultFailed    = "failed"
+	tokenRefreshResultSkipped   = "skipped"
+)
+
+// RecordGatewayRequestMetrics 记录一次网关请求的计数与耗时
+func RecordGatewayRequestMetrics(platform string, groupID *int64, model string, status int, duration time.Duration) {
+	labels := []string{metricsLabelOrUnknown(platform), metricsGroupLabel(groupID), metricsLabelOrUnknown(model), metricsStatusClass(status)}
+	gatewayRequestsTotal.Inc(labels...)
+	gatewayRequestDuration.Observe(duration.Seconds(), labels...)
+}
+
+// RecordGatewayFailoverMetrics 记录一次账号故障转移
+func RecordGatewayFailoverMetrics(platform string, upstreamStatus int) {
+	gatewayFailoverTotal.Inc(metricsLabelOrUnknown(platform), metricsStatusClass(upstreamStatus))
+}
+
+func recordTokenRefreshMetrics(platform, result string) {
+	tokenRefreshTotal.Inc(metricsLabelOrUnknown(platform), result)
+}
+
+func metricsLabelOrUnknown(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
+
+func metricsGroupLabel(groupID *int64) string {
+	if groupID == nil || *groupID <= 0 {
+		return "none"
+	}
+	return strconv.FormatInt(*groupID, 10)
+}
+
+// metricsStatusClass 将状态码归类为 2xx/4xx/5xx，429 与 529 单独保留以便观察限流与过载
+func metricsStatusClass(status int) string {
+	switch {
+	case status == 429 || status == 529:
+		return strconv.Itoa(status)
+	case status >= 200 && status < 600:
+		return strconv.Itoa(status/100) + "xx"
+	default:
+		return "unknown"
+	}
+}
+
+type gatewaySchedulerSnapshot struct {
+	platform string
+	snapshot AccountSchedulerMetricsSnapshot
+}
+
+// RegisterRuntimeMetricsCollectors 注册抓取时读取的运行时指标（工作池队列、调度器统计）。
+// 重复调用会以最新实例替换之前注册的回调。
+func RegisterRuntimeMetricsCollectors(pool *UsageRecordWorkerPool, gatewayService *GatewayService, openaiGatewayService *OpenAIGatewayService) {
+	reg := metrics.Default
+	workerPoolLabels := []string{"pool"}
+	reg.RegisterGaugeFunc("sub2api_worker_pool_queue_depth", "Tasks waiting in the worker pool queue.", workerPoolLabels, func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{"usage_record"}, Value: float64(pool.Stats().WaitingTasks)}}
+	})
+	reg.RegisterGaugeFunc("sub2api_worker_pool_running_workers", "Workers currently running tasks.", workerPoolLabels, func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{"usage_record"}, Value: float64(pool.Stats().RunningWorkers)}}
+	})
+	reg.RegisterGaugeFunc("sub2api_worker_pool_max_concurrency", "Configured worker pool concurrency.", workerPoolLabels, func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{"usage_record"}, Value: float64(pool.Stats().MaxConcurrency)}}
+	})
+	reg.RegisterCounterFunc("sub2api_worker_pool_dropped_tasks_total", "Tasks dropped by the worker pool.", []string{"pool", "reason"}, func() []metrics.Sample {
+		stats := pool.Stats()
+		return []metrics.Sample{
+			{LabelValues: []string{"usage_record", "queue_full"}, Value: float64(stats.DroppedQueueFull)},
+			{LabelValues: []string{"usage_record", "pool_stopped"}, Value: float64(stats.DroppedPoolStopped)},
+		}
+	})
+
+	schedulerSnapshots := func() []gatewaySchedulerSnapshot {
+		return []gatewaySchedulerSnapshot{
+			{platform: PlatformAnthropic, snapshot: gatewayService.SnapshotAccountSchedulerMetrics()},
+			{platform: PlatformOpenAI, snapshot: openaiGatewayService.SnapshotOpenAIAccountSchedulerMetrics()},
+		}
+	}
+	reg.RegisterCounterFunc("sub2api_scheduler_select_total", "Account scheduler selections by gateway and layer.", []string{"gateway", "layer"}, func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, item := range schedulerSnapshots() {
+			samples = append(samples,
+				metrics.Sample{LabelValues: []string{item.platform, "sticky_previous"}, Value: float64(item.snapshot.StickyPreviousHitTotal)},
+				metrics.Sample{LabelValues: []string{item.platform, "sticky_session"}, Value: float64(item.snapshot.StickySessionHitTotal)},
+				metrics.Sample{LabelValues: []string{item.platform, "load_balance"}, Value: float64(item.snapshot.LoadBalanceSelectTotal)},
+			)
+		}
+		return samples
+	})
+	reg.RegisterCounterFunc("sub2api_scheduler_account_switch_total", "Account switches reported to the scheduler.", []string{"gateway"}, func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, item := range schedulerSnapshots() {
+			samples = append(samples, metrics.Sample{LabelValues: []string{item.platform}, Value: float64(item.snapshot.AccountSwitchTotal)})
+		}
+		return samples
+	})
+	reg.RegisterGaugeFunc("sub2api_scheduler_latency_ms_avg", "Average account scheduler decision latency in milliseconds.", []string{"gateway"}, func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, item := range schedulerSnapshots() {
+			samples = append(samples, metrics.Sample{LabelValues: []string{item.platform}, Value: item.snapshot.SchedulerLatencyMsAvg})
+		}
+		return samples
+	})
+	reg.RegisterGaugeFunc("sub2api_scheduler_load_skew_avg", "Average load skew among scheduler candidates.", []string{"gateway"}, func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, item := range schedulerSnapshots() {
+			samples = append(samples, metrics.Sample{LabelValues: []string{item.platform}, Value: item.snapshot.LoadSkewAvg})
+		}
+		return samples
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -26,6 +27,8 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tokenestimate"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
@@ -638,6 +641,10 @@ type GatewayService struct {
 	miniMaxAPI            *MiniMaxAPIClient
 	failoverPolicy        *FailoverPolicy
 	providerRegistry      *ProviderRegistry
+
+	accountSchedulerOnce sync.Once
+	accountScheduler     AccountScheduler
+	accountStats         *accountRuntimeStats
 }
 
 // NewGatewayService creates a new GatewayService
@@ -803,36 +810,36 @@ func (s *GatewayService) GetCachedSessionAccountID(ctx context.Context, groupID
 
 // FindGeminiSession 查找 Gemini 会话（基于内容摘要链的 Fallback 匹配）
 // 返回最长匹配的会话信息（uuid, accountID）
-func (s *GatewayService) FindGeminiSession(_ context.Context, groupID int64, prefixHash, digestChain string) (uuid string, accountID int64, matchedChain string, found bool) {
+func (s *GatewayService) FindGeminiSession(ctx context.Context, groupID int64, prefixHash, digestChain string) (uuid string, accountID int64, matchedChain string, found bool) {
 	if digestChain == "" || s.digestStore == nil {
 		return "", 0, "", false
 	}
-	return s.digestStore.Find(groupID, prefixHash, digestChain)
+	return s.digestStore.FindContext(ctx, groupID, prefixHash, digestChain)
 }
 
 // SaveGeminiSession 保存 Gemini 会话。oldDigestChain 为 Find 返回的 matchedChain，用于删旧 key。
-func (s *GatewayService) SaveGeminiSession(_ context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string) error {
+func (s *GatewayService) SaveGeminiSession(ctx context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string) error {
 	if digestChain == "" || s.digestStore == nil {
 		return nil
 	}
-	s.digestStore.Save(groupID, prefixHash, digestChain, uuid, accountID, oldDigestChain)
+	s.digestStore.SaveContext(ctx, groupID, prefixHash, digestChain, uuid, accountID, oldDigestChain)
 	return nil
 }
 
 // FindAnthropicSession 查找 Anthropic 会话（基于内容摘要链的 Fallback 匹配）
-func (s *GatewayService) FindAnthropicSession(_ context.Context, groupID int64, prefixHash, digestChain string) (uuid string, accountID int64, matchedChain string, found bool) {
+func (s *GatewayService) FindAnthropicSession(ctx context.Context, groupID int64, prefixHash, digestChain string) (uuid string, accountID int64, matchedChain string, found bool) {
 	if digestChain == "" || s.digestStore == nil {
 		return "", 0, "", false
 	}
-	return s.digestStore.Find(groupID, prefixHash, digestChain)
+	return s.digestStore.FindContext(ctx, groupID, prefixHash, digestChain)
 }
 
 // SaveAnthropicSession 保存 Anthropic 会话
-func (s *GatewayService) SaveAnthropicSession(_ context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string) error {
+func (s *GatewayService) SaveAnthropicSession(ctx context.Context, groupID int64, prefixHash, digestChain, uuid string, accountID int64, oldDigestChain string) error {
 	if digestChain == "" || s.digestStore == nil {
 		return nil
 	}
-	s.digestStore.Save(groupID, prefixHash, digestChain, uuid, accountID, oldDigestChain)
+	s.digestStore.SaveContext(ctx, groupID, prefixHash, digestChain, uuid, accountID, oldDigestChain)
 	return nil
 }
 
@@ -1240,7 +1247,10 @@ func (s *GatewayService) SelectAccountForModel(ctx context.Context, groupID *int
 }
 
 // SelectAccountForModelWithExclusions selects an account supporting the requested model while excluding specified accounts.
-func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (*Account, error) {
+func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (account *Account, err error) {
+	ctx, span := startAccountSelectSpan(ctx, "scheduler.select_for_model", groupID, requestedModel, len(excludedIDs))
+	defer func() { endSelectedAccountSpan(span, account, err) }()
+
 	// 优先检查 context 中的强制平台（/antigravity 路由）
 	var platform string
 	forcePlatform, hasForcePlatform := ctx.Value(ctxkey.ForcePlatform).(string)
@@ -1273,6 +1283,21 @@ func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context
 // SelectAccountWithLoadAwareness selects account with load-awareness and wait plan.
 // metadataUserID: 已废弃参数，会话限制现在统一使用 sessionHash
 func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, metadataUserID string) (*AccountSelectionResult, error) {
+	scheduler := s.getAccountScheduler()
+	if scheduler == nil {
+		return s.selectAccountWithLoadAwareness(ctx, groupID, sessionHash, requestedModel, excludedIDs, &AccountScheduleDecision{})
+	}
+	selection, _, err := scheduler.Select(ctx, AccountScheduleRequest{
+		GroupID:        groupID,
+		SessionHash:    sessionHash,
+		RequestedModel: requestedModel,
+		ExcludedIDs:    excludedIDs,
+	})
+	return selection, err
+}
+
+// selectAccountWithLoadAwareness 分层选择账号，并将命中的层级等信息写入 decision
+func (s *GatewayService) selectAccountWithLoadAwareness(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, decision *AccountScheduleDecision) (*AccountSelectionResult, error) {
 	// 调试日志：记录调度入口参数
 	excludedIDsList := make([]int64, 0, len(excludedIDs))
 	for id := range excludedIDs {
@@ -1312,6 +1337,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	}
 
 	if s.concurrencyService == nil || !cfg.LoadBatchEnabled {
+		decision.Layer = accountScheduleLayerLegacy
 		// 复制排除列表，用于会话限制拒绝时的重试
 		localExcluded := make(map[int64]struct{})
 		for k, v := range excludedIDs {
@@ -1428,6 +1454,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 
 	// ============ Layer 1: 模型路由优先选择（优先级高于粘性会话） ============
 	if len(routingAccountIDs) > 0 && s.concurrencyService != nil {
+		decision.Layer = accountScheduleLayerModelRouting
 		// 1. 过滤出路由列表中可调度的账号
 		var routingCandidates []*Account
 		var filteredExcluded, filteredMissing, filteredUnsched, filteredPlatform, filteredModelScope, filteredModelMapping, filteredWindowCost int
@@ -1514,6 +1541,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 									if s.debugModelRoutingEnabled() {
 										logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed sticky hit: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), stickyAccountID)
 									}
+									decision.StickySessionHit = true
 									return &AccountSelectionResult{
 										Account:     stickyAccount,
 										Acquired:    true,
@@ -1530,6 +1558,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 										stickyCacheMissReason = "session_limit"
 										// 会话限制已满，继续到负载感知选择
 									} else {
+										decision.StickySessionHit = true
 										return &AccountSelectionResult{
 											Account: stickyAccount,
 											WaitPlan: &AccountWaitPlan{
@@ -1665,6 +1694,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 
 	// ============ Layer 1.5: 粘性会话（仅在无模型路由配置时生效） ============
 	if len(routingAccountIDs) == 0 && sessionHash != "" && stickyAccountID > 0 && !isExcluded(stickyAccountID) {
+		decision.Layer = accountScheduleLayerSessionSticky
 		accountID := stickyAccountID
 		if accountID > 0 && !isExcluded(accountID) {
 			account, ok := accountByID[accountID]
@@ -1690,6 +1720,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 						if !s.checkAndRegisterSession(ctx, account, sessionHash) {
 							result.ReleaseFunc() // 释放槽位，继续到 Layer 2
 						} else {
+							decision.StickySessionHit = true
 							return &AccountSelectionResult{
 								Account:     account,
 								Acquired:    true,
@@ -1706,6 +1737,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							// 会话限制已满，继续到 Layer 2
 							// Session limit full, continue to Layer 2
 						} else {
+							decision.StickySessionHit = true
 							return &AccountSelectionResult{
 								Account: account,
 								WaitPlan: &AccountWaitPlan{
@@ -1723,6 +1755,13 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	}
 
 	// ============ Layer 2: 负载感知选择 ============
+	decision.Layer = accountScheduleLayerLoadBalance
+	scheduleGroup := group
+	if scheduleGroup == nil && groupID != nil {
+		// 强制平台模式下不解析分组，仅从请求上下文读取分组的调度策略
+		scheduleGroup = s.groupFromContext(ctx, *groupID)
+	}
+	decision.Strategy = resolveAccountScheduleStrategy(scheduleGroup, AccountScheduleStrategyPriorityLRU)
 	candidates := make([]*Account, 0, len(accounts))
 	for i := range accounts {
 		acc := &accounts[i]
@@ -1762,6 +1801,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	if len(candidates) == 0 {
 		return nil, ErrNoAvailableAccounts
 	}
+	decision.CandidateCount = len(candidates)
 
 	accountLoads := make([]AccountWithConcurrency, 0, len(candidates))
 	for _, acc := range candidates {
@@ -1778,11 +1818,15 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		}
 	} else {
 		var available []accountWithLoad
+		loadRateSum, loadRateSumSquares := 0.0, 0.0
 		for _, acc := range candidates {
 			loadInfo := loadMap[acc.ID]
 			if loadInfo == nil {
 				loadInfo = &AccountLoadInfo{AccountID: acc.ID}
 			}
+			loadRate := float64(loadInfo.LoadRate)
+			loadRateSum += loadRate
+			loadRateSumSquares += loadRate * loadRate
 			if loadInfo.LoadRate < 100 {
 				available = append(available, accountWithLoad{
 					account:  acc,
@@ -1790,15 +1834,28 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 				})
 			}
 		}
+		decision.LoadSkew = calcLoadSkewByMoments(loadRateSum, loadRateSumSquares, len(candidates))
+
+		// 非默认策略：预先按策略排好尝试顺序
+		var ordered []accountWithLoad
+		if decision.Strategy != AccountScheduleStrategyPriorityLRU {
+			ordered = s.orderAccountsByScheduleStrategy(decision.Strategy, available)
+		}
 
 		// 分层过滤选择：优先级 → 负载率 → LRU
 		for len(available) > 0 {
-			// 1. 取优先级最小的集合
-			candidates := filterByMinPriority(available)
-			// 2. 取负载率最低的集合
-			candidates = filterByMinLoadRate(candidates)
-			// 3. LRU 选择最久未用的账号
-			selected := selectByLRU(candidates, preferOAuth)
+			var selected *accountWithLoad
+			if ordered != nil {
+				selected = &ordered[0]
+				ordered = ordered[1:]
+			} else {
+				// 1. 取优先级最小的集合
+				candidates := filterByMinPriority(available)
+				// 2. 取负载率最低的集合
+				candidates = filterByMinLoadRate(candidates)
+				// 3. LRU 选择最久未用的账号
+				selected = selectByLRU(candidates, preferOAuth)
+			}
 			if selected == nil {
 				break
 			}
@@ -1833,6 +1890,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	}
 
 	// ============ Layer 3: 兜底排队 ============
+	decision.Layer = accountScheduleLayerFallbackWait
 	s.sortCandidatesForFallback(candidates, preferOAuth, cfg.FallbackSelectionMode)
 	for _, acc := range candidates {
 		// 会话数量限制检查（等待计划也需要占用会话配额）
@@ -2612,6 +2670,27 @@ func (s *GatewayService) getSchedulableAccount(ctx context.Context, accountID in
 	return s.accountRepo.GetByID(ctx, accountID)
 }
 
+// orderAccountsByScheduleStrategy 按调度策略返回候选账号的尝试顺序
+func (s *GatewayService) orderAccountsByScheduleStrategy(strategy string, available []accountWithLoad) []accountWithLoad {
+	candidates := make([]accountScheduleCandidate, 0, len(available))
+	for _, item := range available {
+		errorRate, ttft, hasTTFT := s.accountStats.snapshot(item.account.ID)
+		candidates = append(candidates, accountScheduleCandidate{
+			account:   item.account,
+			loadInfo:  item.loadInfo,
+			errorRate: errorRate,
+			ttft:      ttft,
+			hasTTFT:   hasTTFT,
+		})
+	}
+	orderAccountScheduleCandidates(strategy, candidates)
+	ordered := make([]accountWithLoad, 0, len(candidates))
+	for _, item := range candidates {
+		ordered = append(ordered, accountWithLoad{account: item.account, loadInfo: item.loadInfo})
+	}
+	return ordered
+}
+
 // filterByMinPriority 过滤出优先级最小的账号集合
 func filterByMinPriority(accounts []accountWithLoad) []accountWithLoad {
 	if len(accounts) == 0 {
@@ -8053,6 +8132,8 @@ type postUsageBillingParams struct {
 	IsSubscriptionBill    bool
 	AccountRateMultiplier float64
 	APIKeyService         APIKeyQuotaUpdater
+	// Reservation 严格预留模式下请求前创建的预留，扣费后按实际费用结算
+	Reservation *BillingReservation
 }
 
 func (p *postUsageBillingParams) usesRequestQuotaBilling() bool {
@@ -8250,6 +8331,11 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 	if p == nil || deps == nil {
 		return false, nil
 	}
+	applyBatchBillingDiscount(ctx, usageLog, p)
+	applyResponseCacheHitBilling(ctx, usageLog, p)
+	if p.Reservation == nil {
+		p.Reservation = BillingReservationFromContext(ctx)
+	}
 
 	cmd := buildUsageBillingCommand(requestID, usageLog, p)
 	if cmd == nil || cmd.RequestID == "" || repo == nil {
@@ -8286,20 +8372,32 @@ func finalizePostUsageBilling(p *postUsageBillingParams, deps *billingDeps) {
 	}
 
 	if p.usesRequestQuotaBilling() {
+		p.Reservation.Release(context.Background())
 		deps.deferredService.ScheduleLastUsedUpdate(p.Account.ID)
 		return
 	}
 
+	balanceCost := 0.0
+	if !p.IsSubscriptionBill && p.Cost.ActualCost > 0 && p.User != nil {
+		balanceCost = p.Cost.ActualCost
+	}
+	rateLimitCost := 0.0
+	if p.Cost.ActualCost > 0 && p.APIKey != nil && p.APIKey.HasRateLimits() {
+		rateLimitCost = p.Cost.ActualCost
+	}
+	// 严格预留模式：同步结算被预留的额度池，其余仍走异步缓存更新
+	settled := p.Reservation.Settle(context.Background(), balanceCost, rateLimitCost)
+
 	if p.IsSubscriptionBill {
 		if p.Cost.TotalCost > 0 && p.User != nil && p.APIKey != nil && p.APIKey.GroupID != nil {
 			deps.billingCacheService.QueueUpdateSubscriptionUsage(p.User.ID, *p.APIKey.GroupID, p.Cost.TotalCost)
 		}
-	} else if p.Cost.ActualCost > 0 && p.User != nil {
-		deps.billingCacheService.QueueDeductBalance(p.User.ID, p.Cost.ActualCost)
+	} else if balanceCost > 0 && !(settled && p.Reservation.Covers(BillingHoldPoolBalance)) {
+		deps.billingCacheService.QueueDeductBalance(p.User.ID, balanceCost)
 	}
 
-	if p.Cost.ActualCost > 0 && p.APIKey != nil && p.APIKey.HasRateLimits() {
-		deps.billingCacheService.QueueUpdateAPIKeyRateLimitUsage(p.APIKey.ID, p.Cost.ActualCost)
+	if rateLimitCost > 0 && !(settled && p.Reservation.Covers(BillingHoldPoolAPIKeyRate)) {
+		deps.billingCacheService.QueueUpdateAPIKeyRateLimitUsage(p.APIKey.ID, rateLimitCost)
 	}
 
 	deps.deferredService.ScheduleLastUsedUpdate(p.Account.ID)
@@ -8346,6 +8444,9 @@ func writeUsageLogBestEffort(ctx context.Context, repo UsageLogRepository, usage
 	if repo == nil || usageLog == nil {
 		return
 	}
+	tagUsageLogWithBatch(ctx, usageLog)
+	tagUsageLogWithResponseCacheHit(ctx, usageLog)
+	tagUsageLogWithTraceID(ctx, usageLog)
 	usageCtx, cancel := detachedBillingContext(ctx)
 	defer cancel()
 
@@ -8367,6 +8468,16 @@ func writeUsageLogBestEffort(ctx context.Context, repo UsageLogRepository, usage
 	}
 }
 
+// tagUsageLogWithTraceID 写入 ctx 中的 trace id（异步计费任务已继承请求的 span 上下文）
+func tagUsageLogWithTraceID(ctx context.Context, usageLog *UsageLog) {
+	if usageLog == nil || usageLog.TraceID != nil {
+		return
+	}
+	if traceID := tracing.TraceIDFromContext(ctx); traceID != "" {
+		usageLog.TraceID = &traceID
+	}
+}
+
 // RecordUsage 记录使用量并扣费（或更新订阅用量）
 func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInput) error {
 	result := input.Result
@@ -8444,7 +8555,7 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 			CacheCreation1hTokens: result.Usage.CacheCreation1hTokens,
 		}
 		var err error
-		cost, err = s.billingService.CalculateCost(billingModel, tokens, multiplier)
+		cost, err = s.billingService.CalculateCostWithServiceTier(billingModel, tokens, multiplier, "", apiKey.Group)
 		if err != nil {
 			logger.LegacyPrintf("service.gateway", "Calculate cost failed: %v", err)
 			cost = &CostBreakdown{ActualCost: 0}
@@ -8453,10 +8564,7 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 
 	// 判断计费方式：订阅模式 vs 余额模式
 	isSubscriptionBilling := subscription != nil
-	billingType := BillingTypeBalance
-	if isSubscriptionBilling {
-		billingType = BillingTypeSubscription
-	}
+	billingType := usageBillingType(user, apiKey, subscription)
 
 	// 创建使用日志
 	durationMs := int(result.Duration.Milliseconds())
@@ -8493,6 +8601,7 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 		CacheReadCost:         cost.CacheReadCost,
 		TotalCost:             cost.TotalCost,
 		ActualCost:            cost.ActualCost,
+		PriceSource:           optionalTrimmedStringPtr(cost.PriceSource),
 		RateMultiplier:        multiplier,
 		AccountRateMultiplier: &accountRateMultiplier,
 		BillingType:           billingType,
@@ -8632,7 +8741,7 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 			CacheCreation1hTokens: result.Usage.CacheCreation1hTokens,
 		}
 		var err error
-		cost, err = s.billingService.CalculateCostWithLongContext(billingModel, tokens, multiplier, input.LongContextThreshold, input.LongContextMultiplier)
+		cost, err = s.billingService.CalculateCostWithLongContext(billingModel, tokens, multiplier, input.LongContextThreshold, input.LongContextMultiplier, apiKey.Group)
 		if err != nil {
 			logger.LegacyPrintf("service.gateway", "Calculate cost failed: %v", err)
 			cost = &CostBreakdown{ActualCost: 0}
@@ -8641,10 +8750,7 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 
 	// 判断计费方式：订阅模式 vs 余额模式
 	isSubscriptionBilling := subscription != nil
-	billingType := BillingTypeBalance
-	if isSubscriptionBilling {
-		billingType = BillingTypeSubscription
-	}
+	billingType := usageBillingType(user, apiKey, subscription)
 
 	// 创建使用日志
 	durationMs := int(result.Duration.Milliseconds())
@@ -8677,6 +8783,7 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 		CacheReadCost:         cost.CacheReadCost,
 		TotalCost:             cost.TotalCost,
 		ActualCost:            cost.ActualCost,
+		PriceSource:           optionalTrimmedStringPtr(cost.PriceSource),
 		RateMultiplier:        multiplier,
 		AccountRateMultiplier: &accountRateMultiplier,
 		BillingType:           billingType,
@@ -8753,12 +8860,12 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 				logger.LegacyPrintf("service.gateway", "CountTokens passthrough model mapping: %s -> %s (account: %s)", reqModel, mappedModel, account.Name)
 			}
 		}
-		return s.forwardCountTokensAnthropicAPIKeyPassthrough(ctx, c, account, passthroughBody)
+		return s.forwardCountTokensAnthropicAPIKeyPassthrough(ctx, c, account, parsed, passthroughBody)
 	}
 
-	// Bedrock 不支持 count_tokens 端点
+	// Bedrock 不支持 count_tokens 端点，使用本地估算
 	if account != nil && account.IsBedrock() {
-		s.countTokensError(c, http.StatusNotFound, "not_found_error", "count_tokens endpoint is not supported for Bedrock")
+		s.RespondCountTokensEstimate(c, parsed)
 		return nil
 	}
 
@@ -8777,10 +8884,10 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 		body, reqModel = normalizeClaudeOAuthRequestBody(body, reqModel, normalizeOpts)
 	}
 
-	// Antigravity 账户不支持 count_tokens，返回 404 让客户端 fallback 到本地估算。
+	// 非 Anthropic 上游（Antigravity/Gemini/OpenAI 等）不支持 count_tokens，使用本地估算。
 	// 返回 nil 避免 handler 层记录为错误，也不设置 ops 上游错误上下文。
-	if account.Platform == PlatformAntigravity {
-		s.countTokensError(c, http.StatusNotFound, "not_found_error", "count_tokens endpoint is not supported for this platform")
+	if account.Platform != PlatformAnthropic {
+		s.RespondCountTokensEstimate(c, parsed)
 		return nil
 	}
 
@@ -8879,6 +8986,15 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 		}
 	}
 
+	// 中转站不支持 count_tokens 端点（404）时使用本地估算
+	if isCountTokensUnsupported404(resp.StatusCode, respBody) {
+		logger.LegacyPrintf("service.gateway",
+			"[count_tokens] Upstream does not support count_tokens (404), using local estimate: account=%d name=%s",
+			account.ID, account.Name)
+		s.RespondCountTokensEstimate(c, parsed)
+		return nil
+	}
+
 	// 处理错误响应
 	if resp.StatusCode >= 400 {
 		// 标记账号状态（429/529等）
@@ -8928,7 +9044,7 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 	return nil
 }
 
-func (s *GatewayService) forwardCountTokensAnthropicAPIKeyPassthrough(ctx context.Context, c *gin.Context, account *Account, body []byte) error {
+func (s *GatewayService) forwardCountTokensAnthropicAPIKeyPassthrough(ctx context.Context, c *gin.Context, account *Account, parsed *ParsedRequest, body []byte) error {
 	token, tokenType, err := s.GetAccessToken(ctx, account)
 	if err != nil {
 		s.countTokensError(c, http.StatusBadGateway, "upstream_error", "Failed to get access token")
@@ -8988,14 +9104,14 @@ func (s *GatewayService) forwardCountTokensAnthropicAPIKeyPassthrough(ctx contex
 		upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 		upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 
-		// 中转站不支持 count_tokens 端点时（404），返回 404 让客户端 fallback 到本地估算。
+		// 中转站不支持 count_tokens 端点时（404），使用本地估算。
 		// 仅在错误消息明确指向 count_tokens endpoint 不存在时生效，避免误吞其他 404（如错误 base_url）。
 		// 返回 nil 避免 handler 层记录为错误，也不设置 ops 上游错误上下文。
 		if isCountTokensUnsupported404(resp.StatusCode, respBody) {
 			logger.LegacyPrintf("service.gateway",
-				"[count_tokens] Upstream does not support count_tokens (404), returning 404: account=%d name=%s msg=%s",
+				"[count_tokens] Upstream does not support count_tokens (404), using local estimate: account=%d name=%s msg=%s",
 				account.ID, account.Name, truncateString(upstreamMsg, 512))
-			s.countTokensError(c, http.StatusNotFound, "not_found_error", "count_tokens endpoint is not supported by upstream")
+			s.RespondCountTokensEstimate(c, parsed)
 			return nil
 		}
 
@@ -9242,6 +9358,24 @@ func (s *GatewayService) buildCountTokensRequest(ctx context.Context, c *gin.Con
 	return req, nil
 }
 
+// CountTokensEstimatedHeader 标记 count_tokens 响应来自本地估算而非上游精确计数
+const CountTokensEstimatedHeader = "X-Sub2API-Token-Count-Estimated"
+
+// RespondCountTokensEstimate 使用本地 token 估算器响应 count_tokens 请求。
+// 响应体保持与 Anthropic 一致（仅 input_tokens），估算标记通过响应头返回，避免严格校验响应体的 SDK 报错。
+func (s *GatewayService) RespondCountTokensEstimate(c *gin.Context, parsed *ParsedRequest) {
+	var body []byte
+	model := ""
+	if parsed != nil {
+		body = parsed.Body
+		model = parsed.Model
+	}
+	c.Header(CountTokensEstimatedHeader, "true")
+	c.JSON(http.StatusOK, gin.H{
+		"input_tokens": tokenestimate.CountRequest(model, body),
+	})
+}
+
 // countTokensError 返回 count_tokens 错误响应
 func (s *GatewayService) countTokensError(c *gin.Context, status int, errType, message string) {
 	message = NormalizeClientFacingUpstreamErrorMessage(message)
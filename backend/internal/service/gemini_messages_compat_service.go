@@ -23,6 +23,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tokenestimate"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 
@@ -93,7 +94,10 @@ func (s *GeminiMessagesCompatService) SelectAccountForModel(ctx context.Context,
 	return s.SelectAccountForModelWithExclusions(ctx, groupID, sessionHash, requestedModel, nil)
 }
 
-func (s *GeminiMessagesCompatService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (*Account, error) {
+func (s *GeminiMessagesCompatService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (account *Account, err error) {
+	ctx, span := startAccountSelectSpan(ctx, "scheduler.select_for_model", groupID, requestedModel, len(excludedIDs))
+	defer func() { endSelectedAccountSpan(span, account, err) }()
+
 	// 1. 确定目标平台和调度模式
 	// Determine target platform and scheduling mode
 	platform, useMixedScheduling, hasForcePlatform, err := s.resolvePlatformAndSchedulingMode(ctx, groupID)
@@ -1149,7 +1153,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 	}
 
 	switch action {
-	case "generateContent", "streamGenerateContent", "countTokens":
+	case "generateContent", "streamGenerateContent", "countTokens", "embedContent", "batchEmbedContents":
 		// ok
 	default:
 		return nil, s.writeGoogleError(c, http.StatusNotFound, "Unsupported action: "+action)
@@ -1184,7 +1188,8 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		useUpstreamStream = true
 		upstreamAction = "streamGenerateContent"
 	}
-	forceAIStudio := action == "countTokens"
+	// Code Assist 不提供 countTokens / embedContent，统一走 AI Studio。
+	forceAIStudio := action == "countTokens" || IsGeminiEmbedAction(action)
 
 	var requestIDHeader string
 	var buildReq func(ctx context.Context) (*http.Request, string, error)
@@ -1662,6 +1667,10 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 	if usage == nil {
 		usage = &ClaudeUsage{}
 	}
+	// embedContent 响应不带 usageMetadata，按请求文本估算输入 token 以便计费。
+	if IsGeminiEmbedAction(action) && usage.InputTokens == 0 {
+		usage.InputTokens = estimateGeminiEmbedTokens(body)
+	}
 
 	// 图片生成计费
 	imageCount := 0
@@ -2511,28 +2520,35 @@ func estimateGeminiCountTokens(reqBody []byte) int {
 	return total
 }
 
-func estimateTokensForText(s string) int {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return 0
-	}
-	runes := []rune(s)
-	if len(runes) == 0 {
-		return 0
-	}
-	ascii := 0
-	for _, r := range runes {
-		if r <= 0x7f {
-			ascii++
-		}
-	}
-	asciiRatio := float64(ascii) / float64(len(runes))
-	if asciiRatio >= 0.8 {
-		// Roughly 4 chars per token for English-like text.
-		return (len(runes) + 3) / 4
+// IsGeminiEmbedAction 判断是否为 Gemini embeddings 动作（embedContent / batchEmbedContents）。
+func IsGeminiEmbedAction(action string) bool {
+	return action == "embedContent" || action == "batchEmbedContents"
+}
+
+// estimateGeminiEmbedTokens 估算 embedContent / batchEmbedContents 请求的输入 token。
+func estimateGeminiEmbedTokens(reqBody []byte) int {
+	total := 0
+	countParts := func(content gjson.Result) {
+		content.Get("parts").ForEach(func(_, part gjson.Result) bool {
+			if t := strings.TrimSpace(part.Get("text").String()); t != "" {
+				total += estimateTokensForText(t)
+			}
+			return true
+		})
 	}
-	// For CJK-heavy text, approximate 1 rune per token.
-	return len(runes)
+
+	// embedContent: content.parts[].text
+	countParts(gjson.GetBytes(reqBody, "content"))
+	// batchEmbedContents: requests[].content.parts[].text
+	gjson.GetBytes(reqBody, "requests").ForEach(func(_, req gjson.Result) bool {
+		countParts(req.Get("content"))
+		return true
+	})
+	return total
+}
+
+func estimateTokensForText(s string) int {
+	return tokenestimate.CountText(tokenestimate.EncodingClaude, strings.TrimSpace(s))
 }
 
 type UpstreamHTTPResult struct {
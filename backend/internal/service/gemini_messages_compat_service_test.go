@@ -627,3 +627,18 @@ func TestParseGeminiRateLimitResetTime(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimateGeminiEmbedTokens(t *testing.T) {
+	single := estimateGeminiEmbedTokens([]byte(`{"content":{"parts":[{"text":"What is the meaning of life?"}]}}`))
+	require.Equal(t, estimateTokensForText("What is the meaning of life?"), single)
+
+	batch := estimateGeminiEmbedTokens([]byte(`{"requests":[
+		{"model":"models/gemini-embedding-001","content":{"parts":[{"text":"What is the meaning of life?"}]}},
+		{"model":"models/gemini-embedding-001","content":{"parts":[{"text":"How much wood would a woodchuck chuck?"}]}}
+	]}`))
+	require.Equal(t, single+estimateTokensForText("How much wood would a woodchuck chuck?"), batch)
+
+	require.Zero(t, estimateGeminiEmbedTokens([]byte(`{"content":{"parts":[]}}`)))
+	require.True(t, IsGeminiEmbedAction("batchEmbedContents"))
+	require.False(t, IsGeminiEmbedAction("generateContent"))
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/gin-gonic/gin"
+)
+
+// geminiOpenAIFormat 标识 Gemini 原生响应需要转换成的 OpenAI 协议格式。
+type geminiOpenAIFormat int
+
+const (
+	geminiOpenAIChatCompletions geminiOpenAIFormat = iota
+	geminiOpenAIResponses
+	// geminiOpenAIImages 仅捕获非流式 Gemini 响应，由 ImageGenerationService 组装 Images API 响应。
+	geminiOpenAIImages
+)
+
+// ForwardAsChatCompletions 接收 OpenAI Chat Completions 请求，转换为 Gemini generateContent
+// 格式后复用 ForwardNative 转发（模型映射、重试、限流处理保持一致），并把响应实时转换回
+// Chat Completions 格式。使 Gemini 平台分组可以服务 OpenAI 协议客户端。
+func (s *GeminiMessagesCompatService) ForwardAsChatCompletions(ctx context.Context, c *gin.Context, account *Account, body []byte) (*ForwardResult, error) {
+	return forwardGeminiAsOpenAI(c, geminiOpenAIChatCompletions, body, func(model, action string, stream bool, geminiBody []byte) (*ForwardResult, error) {
+		return s.ForwardNative(ctx, c, account, model, action, stream, geminiBody)
+	})
+}
+
+// ForwardAsResponses 接收 OpenAI Responses 请求，经 Gemini generateContent 转发后
+// 把响应转换回 Responses 格式。
+func (s *GeminiMessagesCompatService) ForwardAsResponses(ctx context.Context, c *gin.Context, account *Account, body []byte) (*ForwardResult, error) {
+	return forwardGeminiAsOpenAI(c, geminiOpenAIResponses, body, func(model, action string, stream bool, geminiBody []byte) (*ForwardResult, error) {
+		return s.ForwardNative(ctx, c, account, model, action, stream, geminiBody)
+	})
+}
+
+// ForwardAsChatCompletions 接收 OpenAI Chat Completions 请求，转换为 Gemini 格式后
+// 复用 ForwardGemini 转发到 Antigravity 上游，并把响应转换回 Chat Completions 格式。
+func (s *AntigravityGatewayService) ForwardAsChatCompletions(ctx context.Context, c *gin.Context, account *Account, body []byte, isStickySession bool) (*ForwardResult, error) {
+	return forwardGeminiAsOpenAI(c, geminiOpenAIChatCompletions, body, func(model, action string, stream bool, geminiBody []byte) (*ForwardResult, error) {
+		return s.ForwardGemini(ctx, c, account, model, action, stream, geminiBody, isStickySession)
+	})
+}
+
+// ForwardAsResponses 接收 OpenAI Responses 请求，经 Antigravity 的 Gemini 通道转发后
+// 把响应转换回 Responses 格式。
+func (s *AntigravityGatewayService) ForwardAsResponses(ctx context.Context, c *gin.Context, account *Account, body []byte, isStickySession bool) (*ForwardResult, error) {
+	return forwardGeminiAsOpenAI(c, geminiOpenAIResponses, body, func(model, action string, stream bool, geminiBody []byte) (*ForwardResult, error) {
+		return s.ForwardGemini(ctx, c, account, model, action, stream, geminiBody, isStickySession)
+	})
+}
+
+// forwardGeminiAsOpenAI 完成 OpenAI 请求 → Gemini 请求的转换，并在调用原生转发函数期间
+// 用 geminiOpenAIResponseWriter 替换 c.Writer，使原生转发写出的 Gemini 响应被转换为 OpenAI 格式。
+func forwardGeminiAsOpenAI(
+	c *gin.Context,
+	format geminiOpenAIFormat,
+	body []byte,
+	forward func(model, action string, stream bool, geminiBody []byte) (*ForwardResult, error),
+) (*ForwardResult, error) {
+	model, stream, includeUsage, geminiReq, err := convertOpenAIRequestToGemini(format, body)
+	if err != nil {
+		writeGeminiOpenAIError(c, format, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return nil, err
+	}
+	geminiBody, err := json.Marshal(geminiReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal gemini request: %w", err)
+	}
+
+	action := "generateContent"
+	if stream {
+		action = "streamGenerateContent"
+	}
+
+	original := c.Writer
+	w := newGeminiOpenAIResponseWriter(original, format, model, stream, includeUsage)
+	c.Writer = w
+	result, err := forward(model, action, stream, geminiBody)
+	c.Writer = original
+
+	if finishErr := w.finish(c, err); finishErr != nil && err == nil {
+		return nil, finishErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && format == geminiOpenAIResponses && result.ReasoningEffort == nil {
+		result.ReasoningEffort = ExtractResponsesReasoningEffortFromBody(body)
+	}
+	if result != nil && format == geminiOpenAIChatCompletions && result.ReasoningEffort == nil {
+		result.ReasoningEffort = extractCCReasoningEffortFromBody(body)
+	}
+	return result, nil
+}
+
+// convertOpenAIRequestToGemini 解析 OpenAI 请求体并转换为 Gemini 请求。
+func convertOpenAIRequestToGemini(format geminiOpenAIFormat, body []byte) (model string, stream, includeUsage bool, out *apicompat.GeminiRequest, err error) {
+	if format == geminiOpenAIResponses {
+		var req apicompat.ResponsesRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return "", false, false, nil, fmt.Errorf("parse responses request: %w", err)
+		}
+		out, err = apicompat.ResponsesToGeminiRequest(&req)
+		if err != nil {
+			return "", false, false, nil, fmt.Errorf("convert responses to gemini: %w", err)
+		}
+		return req.Model, req.Stream, false, out, nil
+	}
+
+	var req apicompat.ChatCompletionsRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return "", false, false, nil, fmt.Errorf("parse chat completions request: %w", err)
+	}
+	out, err = apicompat.ChatCompletionsToGeminiRequest(&req)
+	if err != nil {
+		return "", false, false, nil, fmt.Errorf("convert chat completions to gemini: %w", err)
+	}
+	includeUsage = req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+	return req.Model, req.Stream, includeUsage, out, nil
+}
+
+// writeGeminiOpenAIError 按目标协议写出错误响应。
+func writeGeminiOpenAIError(c *gin.Context, format geminiOpenAIFormat, status int, errType, message string) {
+	if format == geminiOpenAIResponses {
+		writeResponsesError(c, status, errType, message)
+		return
+	}
+	writeGatewayCCError(c, status, errType, message)
+}
+
+// geminiOpenAIResponseWriter 包装 gin.ResponseWriter：
+//   - 流式成功响应：逐行解析 Gemini SSE，转换为 OpenAI SSE 后立即写给客户端；
+//   - 非流式或错误响应：缓存响应体，由 finish 统一转换后写出。
+//
+// 原生转发函数通过 c.Status / c.Header / c.Data / io.WriteString 写响应，
+// 因此需要同时覆盖 WriteHeader、Write、WriteString 和 Flush。
+type geminiOpenAIResponseWriter struct {
+	gin.ResponseWriter
+
+	format geminiOpenAIFormat
+	model  string
+	stream bool
+
+	status     int
+	written    bool
+	headerSent bool
+	buf        bytes.Buffer
+	line       []byte
+
+	chatState      *apicompat.GeminiEventToChatState
+	responsesState *apicompat.GeminiEventToResponsesState
+	// geminiResp 为 geminiOpenAIImages 格式下捕获的上游响应
+	geminiResp *apicompat.GeminiResponse
+}
+
+func newGeminiOpenAIResponseWriter(w gin.ResponseWriter, format geminiOpenAIFormat, model string, stream, includeUsage bool) *geminiOpenAIResponseWriter {
+	cw := &geminiOpenAIResponseWriter{
+		ResponseWriter: w,
+		format:         format,
+		model:          model,
+		stream:         stream,
+	}
+	switch format {
+	case geminiOpenAIResponses:
+		cw.responsesState = apicompat.NewGeminiEventToResponsesState()
+		cw.responsesState.Model = model
+	case geminiOpenAIChatCompletions:
+		cw.chatState = apicompat.NewGeminiEventToChatState(model, includeUsage)
+	}
+	return cw
+}
+
+func (w *geminiOpenAIResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *geminiOpenAIResponseWriter) WriteHeaderNow() {}
+
+func (w *geminiOpenAIResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *geminiOpenAIResponseWriter) Written() bool {
+	return w.written
+}
+
+func (w *geminiOpenAIResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	if !w.stream || w.Status() >= http.StatusBadRequest {
+		return w.buf.Write(data)
+	}
+
+	w.line = append(w.line, data...)
+	for {
+		idx := bytes.IndexByte(w.line, '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(w.line[:idx])
+		w.line = w.line[idx+1:]
+		if err := w.handleStreamLine(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *geminiOpenAIResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *geminiOpenAIResponseWriter) Flush() {
+	if w.headerSent {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// handleStreamLine 转换一行 Gemini SSE。keepalive 注释行原样透传，其余非 data 行丢弃。
+func (w *geminiOpenAIResponseWriter) handleStreamLine(line string) error {
+	line = strings.TrimRight(line, "\r")
+	if strings.HasPrefix(line, ":") {
+		w.sendStreamHeader()
+		_, err := w.ResponseWriter.WriteString(line + "\n\n")
+		return err
+	}
+	if !strings.HasPrefix(line, "data:") {
+		return nil
+	}
+	payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+	if payload == "" || payload == "[DONE]" {
+		return nil
+	}
+	raw, _ := unwrapGeminiResponse([]byte(payload))
+	var chunk apicompat.GeminiResponse
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return nil
+	}
+	return w.writeStreamEvents(w.convertChunk(&chunk))
+}
+
+func (w *geminiOpenAIResponseWriter) convertChunk(chunk *apicompat.GeminiResponse) []string {
+	if w.format == geminiOpenAIResponses {
+		return responsesEventsToSSE(apicompat.GeminiChunkToResponsesEvents(chunk, w.responsesState))
+	}
+	return chatChunksToSSE(apicompat.GeminiChunkToChatChunks(chunk, w.chatState))
+}
+
+func (w *geminiOpenAIResponseWriter) sendStreamHeader() {
+	if w.headerSent {
+		return
+	}
+	w.headerSent = true
+	h := w.ResponseWriter.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
+func (w *geminiOpenAIResponseWriter) writeStreamEvents(events []string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	w.sendStreamHeader()
+	for _, sse := range events {
+		if _, err := w.ResponseWriter.WriteString(sse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish 在原生转发返回后（c.Writer 已恢复）写出转换后的错误、非流式响应或流式收尾事件。
+// 原生转发未写任何内容（如返回 UpstreamFailoverError）时不做处理，保留 failover 能力。
+func (w *geminiOpenAIResponseWriter) finish(c *gin.Context, forwardErr error) error {
+	if !w.written && !w.headerSent {
+		if forwardErr != nil || !w.stream {
+			return nil
+		}
+	}
+
+	if w.Status() >= http.StatusBadRequest {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Length")
+		message := extractUpstreamErrorMessage(w.buf.Bytes())
+		if strings.TrimSpace(message) == "" {
+			message = http.StatusText(w.Status())
+		}
+		writeGeminiOpenAIError(c, w.format, mapUpstreamStatusCode(w.Status()), "upstream_error", message)
+		return nil
+	}
+
+	if !w.stream {
+		if forwardErr != nil {
+			return nil
+		}
+		raw, _ := unwrapGeminiResponse(w.buf.Bytes())
+		var resp apicompat.GeminiResponse
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			writeGeminiOpenAIError(c, w.format, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
+			return fmt.Errorf("parse gemini response: %w", err)
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Length")
+		switch w.format {
+		case geminiOpenAIResponses:
+			c.JSON(http.StatusOK, apicompat.GeminiToResponsesResponse(&resp, w.model))
+		case geminiOpenAIImages:
+			w.geminiResp = &resp
+		default:
+			c.JSON(http.StatusOK, apicompat.GeminiToChatCompletions(&resp, w.model))
+		}
+		return nil
+	}
+
+	if len(w.line) > 0 {
+		_ = w.handleStreamLine(string(w.line))
+		w.line = nil
+	}
+	var final []string
+	if w.format == geminiOpenAIResponses {
+		final = responsesEventsToSSE(apicompat.FinalizeGeminiResponsesStream(w.responsesState))
+	} else {
+		final = append(chatChunksToSSE(apicompat.FinalizeGeminiChatStream(w.chatState)), "data: [DONE]\n\n")
+	}
+	_ = w.writeStreamEvents(final)
+	w.Flush()
+	return nil
+}
+
+func responsesEventsToSSE(events []apicompat.ResponsesStreamEvent) []string {
+	out := make([]string, 0, len(events))
+	for _, evt := range events {
+		if sse, err := apicompat.ResponsesEventToSSE(evt); err == nil {
+			out = append(out, sse)
+		}
+	}
+	return out
+}
+
+func chatChunksToSSE(chunks []apicompat.ChatCompletionsChunk) []string {
+	out := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		if sse, err := apicompat.ChatChunkToSSE(chunk); err == nil {
+			out = append(out, sse)
+		}
+	}
+	return out
+}
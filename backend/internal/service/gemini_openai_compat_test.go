@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newGeminiOpenAICompatTestService(status int, contentType, body string) (*GeminiMessagesCompatService, *geminiCompatHTTPUpstreamStub) {
+	httpStub := &geminiCompatHTTPUpstreamStub{
+		response: &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		},
+	}
+	return &GeminiMessagesCompatService{httpUpstream: httpStub, cfg: &config.Config{}}, httpStub
+}
+
+func TestGeminiForwardAsChatCompletions_NonStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	svc, httpStub := newGeminiOpenAICompatTestService(http.StatusOK, "application/json",
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello!"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5}}`)
+	account := &Account{ID: 1, Type: AccountTypeAPIKey, Platform: PlatformGemini, Credentials: map[string]any{"api_key": "test-key"}}
+	body := []byte(`{"model":"gemini-2.5-flash","messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"Hi"}]}`)
+
+	result, err := svc.ForwardAsChatCompletions(context.Background(), c, account, body)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 10, result.Usage.InputTokens)
+	require.Equal(t, 5, result.Usage.OutputTokens)
+
+	require.NotNil(t, httpStub.lastReq)
+	require.Contains(t, httpStub.lastReq.URL.String(), "/models/gemini-2.5-flash:generateContent")
+	upstreamBody, _ := io.ReadAll(httpStub.lastReq.Body)
+	require.Equal(t, "Be brief.", gjson.GetBytes(upstreamBody, "systemInstruction.parts.0.text").String())
+	require.Equal(t, "Hi", gjson.GetBytes(upstreamBody, "contents.0.parts.0.text").String())
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "chat.completion", gjson.Get(w.Body.String(), "object").String())
+	require.Equal(t, "Hello!", gjson.Get(w.Body.String(), "choices.0.message.content").String())
+	require.Equal(t, "stop", gjson.Get(w.Body.String(), "choices.0.finish_reason").String())
+	require.Equal(t, int64(15), gjson.Get(w.Body.String(), "usage.total_tokens").Int())
+}
+
+func TestGeminiForwardAsResponses_Streaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+
+	upstream := "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"Hel\"}]}}]}\r\n\r\n" +
+		"data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":7,\"candidatesTokenCount\":2}}\r\n\r\n"
+	svc, httpStub := newGeminiOpenAICompatTestService(http.StatusOK, "text/event-stream", upstream)
+	account := &Account{ID: 1, Type: AccountTypeAPIKey, Platform: PlatformGemini, Credentials: map[string]any{"api_key": "test-key"}}
+	body := []byte(`{"model":"gemini-2.5-pro","stream":true,"input":"Hi","reasoning":{"effort":"high"}}`)
+
+	result, err := svc.ForwardAsResponses(context.Background(), c, account, body)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.True(t, result.Stream)
+	require.Equal(t, 7, result.Usage.InputTokens)
+	require.NotNil(t, result.ReasoningEffort)
+	require.Equal(t, "high", *result.ReasoningEffort)
+	require.Contains(t, httpStub.lastReq.URL.String(), ":streamGenerateContent")
+
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	out := w.Body.String()
+	require.Contains(t, out, "event: response.created\n")
+	require.Contains(t, out, "event: response.output_text.delta\n")
+	require.Contains(t, out, "event: response.completed\n")
+	require.NotContains(t, out, "candidates")
+	require.NotContains(t, out, "[DONE]")
+}
+
+func TestGeminiOpenAIResponseWriter_StreamingChatCompletions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	original := c.Writer
+	w := newGeminiOpenAIResponseWriter(original, geminiOpenAIChatCompletions, "gemini-2.5-flash", true, true)
+	c.Writer = w
+	c.Status(http.StatusOK)
+	_, _ = io.WriteString(c.Writer, ": keepalive\n\n")
+	// 分片写入同一行，验证按行缓冲
+	_, _ = io.WriteString(c.Writer, "data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"te")
+	_, _ = io.WriteString(c.Writer, "xt\":\"Hi\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":3,\"candidatesTokenCount\":1}}}\n\n")
+	c.Writer = original
+	require.NoError(t, w.finish(c, nil))
+
+	out := rec.Body.String()
+	require.True(t, strings.HasPrefix(out, ": keepalive\n\n"))
+	require.Contains(t, out, `"content":"Hi"`)
+	require.Contains(t, out, `"finish_reason":"stop"`)
+	require.Contains(t, out, `"prompt_tokens":3`)
+	require.True(t, strings.HasSuffix(out, "data: [DONE]\n\n"))
+}
+
+func TestGeminiOpenAIResponseWriter_ConvertsGoogleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	original := c.Writer
+	w := newGeminiOpenAIResponseWriter(original, geminiOpenAIResponses, "gemini-2.5-pro", true, false)
+	c.Writer = w
+	c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": 400, "message": "Invalid argument", "status": "INVALID_ARGUMENT"}})
+	c.Writer = original
+	require.NoError(t, w.finish(c, nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "upstream_error", gjson.Get(rec.Body.String(), "error.code").String())
+	require.Equal(t, "Invalid argument", gjson.Get(rec.Body.String(), "error.message").String())
+}
+
+func TestGeminiOpenAIResponseWriter_NothingWrittenKeepsFailover(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	w := newGeminiOpenAIResponseWriter(c.Writer, geminiOpenAIChatCompletions, "gemini-2.5-flash", true, false)
+	require.NoError(t, w.finish(c, &UpstreamFailoverError{StatusCode: http.StatusTooManyRequests}))
+	require.False(t, c.Writer.Written())
+	require.Empty(t, rec.Body.String())
+}
@@ -56,6 +56,10 @@ type Group struct {
 	// 模型别名兜底模型：未匹配到任何别名规则时使用
 	FallbackModel string
 
+	// 分组自定义模型价格（支持 * 通配符），优先于全局价格表
+	// key: 模型匹配模式（如 "claude-opus-*"），value: 每百万 token 价格
+	ModelPrices map[string]GroupModelPrice
+
 	// MCP XML 协议注入开关（仅 antigravity 平台使用）
 	MCPXMLInject bool
 
@@ -72,6 +76,20 @@ type Group struct {
 	RequirePrivacySet     bool // 调度时仅允许 privacy 已成功设置的账号（OpenAI/Antigravity/Anthropic/Gemini）
 	DefaultMappedModel    string
 
+	// 精确匹配响应缓存配置
+	ResponseCacheEnabled       bool
+	ResponseCacheTTLSeconds    int     // 缓存有效期（秒）
+	ResponseCacheHitMultiplier float64 // 缓存命中计费倍率，作用于原始请求费用
+
+	// HedgeBudgetPercent 对冲请求占比上限（百分比），0 表示关闭对冲
+	HedgeBudgetPercent float64
+
+	// ScheduleStrategy 账号调度策略，空表示网关默认策略
+	ScheduleStrategy string
+
+	// BillingReservationMode 计费预留模式：optimistic 请求后扣费，strict 请求前预留预估最大费用
+	BillingReservationMode string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"math"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// GroupModelPrice 分组自定义模型价格（USD per MTok），nil 项沿用全局价格表
+type GroupModelPrice = domain.GroupModelPrice
+
+// 价格来源（记录在使用记录中）
+const (
+	PriceSourceGroup    = domain.PriceSourceGroup
+	PriceSourceLiteLLM  = domain.PriceSourceLiteLLM
+	PriceSourceFallback = domain.PriceSourceFallback
+)
+
+// maxGroupModelPricePerMTok 单项价格上限（USD per MTok），拦截误填的异常值
+const maxGroupModelPricePerMTok = 10000
+
+var ErrInvalidGroupModelPrices = infraerrors.BadRequest("INVALID_GROUP_MODEL_PRICES", "model_prices: each pattern needs at least one price between 0 and 10000 USD per MTok, and * is only allowed at the end")
+
+// ResolveModelPrice 返回计费模型命中的分组自定义价格。
+// 匹配规则与模型别名一致：精确匹配优先，通配符匹配次之（最长前缀优先）；模型名不区分大小写。
+func (g *Group) ResolveModelPrice(model string) (*GroupModelPrice, bool) {
+	if g == nil || len(g.ModelPrices) == 0 || model == "" {
+		return nil, false
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+
+	if price, ok := g.ModelPrices[model]; ok {
+		return &price, true
+	}
+
+	bestPattern := ""
+	var best *GroupModelPrice
+	for pattern, price := range g.ModelPrices {
+		if matchModelPattern(pattern, model) && len(pattern) > len(bestPattern) {
+			bestPattern = pattern
+			p := price
+			best = &p
+		}
+	}
+	return best, best != nil
+}
+
+// NormalizeGroupModelPrices 规范化并校验分组模型价格：模式去空白并转小写，
+// 每条规则至少设置一项价格，价格必须在 [0, 10000] 范围内。nil 或空 map 表示清除自定义价格。
+func NormalizeGroupModelPrices(prices map[string]GroupModelPrice) (map[string]GroupModelPrice, error) {
+	if len(prices) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]GroupModelPrice, len(prices))
+	for pattern, price := range prices {
+		key := strings.ToLower(strings.TrimSpace(pattern))
+		invalid := ErrInvalidGroupModelPrices.WithMetadata(map[string]string{"pattern": pattern})
+		if key == "" || key == "*" || strings.Contains(strings.TrimSuffix(key, "*"), "*") {
+			return nil, invalid
+		}
+		if _, dup := out[key]; dup {
+			return nil, invalid
+		}
+		set := 0
+		for _, v := range []*float64{price.InputPrice, price.OutputPrice, price.CacheReadPrice, price.CacheWritePrice} {
+			if v == nil {
+				continue
+			}
+			if math.IsNaN(*v) || *v < 0 || *v > maxGroupModelPricePerMTok {
+				return nil, invalid
+			}
+			set++
+		}
+		if set == 0 {
+			return nil, invalid
+		}
+		out[key] = price
+	}
+	return out, nil
+}
+
+// applyGroupModelPrice 以分组价格覆盖全局价格；base 为 nil（全局价格表无该模型）时未设置的项按 0 计费。
+// 覆盖后不再使用 priority 专属单价，service tier 统一按倍率计费；
+// 设置了缓存写入价格时 5m/1h 缓存创建统一按该价格计费。
+func applyGroupModelPrice(base *ModelPricing, price *GroupModelPrice) *ModelPricing {
+	pricing := &ModelPricing{}
+	if base != nil {
+		*pricing = *base
+	}
+	pricing.InputPricePerTokenPriority = 0
+	pricing.OutputPricePerTokenPriority = 0
+	pricing.CacheReadPricePerTokenPriority = 0
+
+	if price.InputPrice != nil {
+		pricing.InputPricePerToken = *price.InputPrice / 1e6
+	}
+	if price.OutputPrice != nil {
+		pricing.OutputPricePerToken = *price.OutputPrice / 1e6
+	}
+	if price.CacheReadPrice != nil {
+		pricing.CacheReadPricePerToken = *price.CacheReadPrice / 1e6
+	}
+	if price.CacheWritePrice != nil {
+		perToken := *price.CacheWritePrice / 1e6
+		pricing.CacheCreationPricePerToken = perToken
+		pricing.CacheCreation5mPrice = perToken
+		pricing.CacheCreation1hPrice = perToken
+		pricing.SupportsCacheBreakdown = false
+	}
+	return pricing
+}
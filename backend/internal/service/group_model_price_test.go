@@ -0,0 +1,151 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testModelPrice(v float64) *float64 { return &v }
+
+func TestGroupResolveModelPrice(t *testing.T) {
+	group := &Group{ModelPrices: map[string]GroupModelPrice{
+		"claude-opus-*":   {InputPrice: testModelPrice(10)},
+		"claude-opus-4-*": {InputPrice: testModelPrice(12)},
+		"claude-opus-4-6": {InputPrice: testModelPrice(20)},
+		"claude-*":        {InputPrice: testModelPrice(1)},
+	}}
+
+	tests := []struct {
+		model string
+		want  float64
+		found bool
+	}{
+		{model: "claude-opus-4-6", want: 20, found: true},
+		{model: "Claude-Opus-4-5-20251101", want: 12, found: true},
+		{model: "claude-opus-3", want: 10, found: true},
+		{model: "claude-haiku-4-5", want: 1, found: true},
+		{model: "gpt-5", found: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			price, ok := group.ResolveModelPrice(tt.model)
+			require.Equal(t, tt.found, ok)
+			if tt.found {
+				require.Equal(t, tt.want, *price.InputPrice)
+			}
+		})
+	}
+
+	var nilGroup *Group
+	_, ok := nilGroup.ResolveModelPrice("claude-opus-4-6")
+	require.False(t, ok)
+}
+
+func TestNormalizeGroupModelPrices(t *testing.T) {
+	out, err := NormalizeGroupModelPrices(map[string]GroupModelPrice{
+		" Claude-Opus-* ": {InputPrice: testModelPrice(15), OutputPrice: testModelPrice(75)},
+	})
+	require.NoError(t, err)
+	require.Contains(t, out, "claude-opus-*")
+
+	out, err = NormalizeGroupModelPrices(map[string]GroupModelPrice{})
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	invalid := []map[string]GroupModelPrice{
+		{"": {InputPrice: testModelPrice(1)}},
+		{"*": {InputPrice: testModelPrice(1)}},
+		{"claude-*-opus": {InputPrice: testModelPrice(1)}},
+		{"claude-opus-*": {}},
+		{"claude-opus-*": {OutputPrice: testModelPrice(-1)}},
+		{"claude-opus-*": {CacheReadPrice: testModelPrice(maxGroupModelPricePerMTok + 1)}},
+		{"Claude-Opus-*": {InputPrice: testModelPrice(1)}, "claude-opus-*": {InputPrice: testModelPrice(2)}},
+	}
+	for _, prices := range invalid {
+		_, err := NormalizeGroupModelPrices(prices)
+		require.ErrorIs(t, err, ErrInvalidGroupModelPrices)
+	}
+}
+
+func TestCalculateCostWithServiceTier_GroupPriceOverridesGlobal(t *testing.T) {
+	svc := newTestBillingService()
+	tokens := UsageTokens{InputTokens: 1000, OutputTokens: 500, CacheReadTokens: 2000}
+	group := &Group{ModelPrices: map[string]GroupModelPrice{
+		"claude-sonnet-*": {InputPrice: testModelPrice(4), OutputPrice: testModelPrice(20)},
+	}}
+
+	global, err := svc.CalculateCost("claude-sonnet-4", tokens, 1.0)
+	require.NoError(t, err)
+	require.Equal(t, PriceSourceFallback, global.PriceSource)
+
+	cost, err := svc.CalculateCostWithServiceTier("claude-sonnet-4", tokens, 2.0, "", group)
+	require.NoError(t, err)
+	require.Equal(t, PriceSourceGroup, cost.PriceSource)
+	require.InDelta(t, 1000*4e-6, cost.InputCost, 1e-12)
+	require.InDelta(t, 500*20e-6, cost.OutputCost, 1e-12)
+	// 未设置的缓存读取价格沿用全局价格表
+	require.InDelta(t, global.CacheReadCost, cost.CacheReadCost, 1e-12)
+	require.InDelta(t, cost.TotalCost*2, cost.ActualCost, 1e-12)
+
+	// 未命中的模型仍使用全局价格
+	other, err := svc.CalculateCostWithServiceTier("claude-haiku-4-5", tokens, 1.0, "", group)
+	require.NoError(t, err)
+	require.Equal(t, PriceSourceFallback, other.PriceSource)
+}
+
+func TestCalculateCostWithServiceTier_GroupPriceForUnknownModel(t *testing.T) {
+	svc := newTestBillingService()
+	tokens := UsageTokens{InputTokens: 1000, OutputTokens: 1000}
+
+	_, err := svc.CalculateCostWithServiceTier("acme-model-1", tokens, 1.0, "", nil)
+	require.Error(t, err)
+
+	group := &Group{ModelPrices: map[string]GroupModelPrice{
+		"acme-*": {InputPrice: testModelPrice(1), OutputPrice: testModelPrice(2)},
+	}}
+	cost, err := svc.CalculateCostWithServiceTier("acme-model-1", tokens, 1.0, "", group)
+	require.NoError(t, err)
+	require.Equal(t, PriceSourceGroup, cost.PriceSource)
+	require.InDelta(t, 1000*1e-6+1000*2e-6, cost.TotalCost, 1e-12)
+}
+
+func TestCalculateCostWithServiceTier_GroupCacheWritePriceReplacesBreakdown(t *testing.T) {
+	svc := newTestBillingService()
+	tokens := UsageTokens{CacheCreationTokens: 3000, CacheCreation5mTokens: 1000, CacheCreation1hTokens: 2000}
+	group := &Group{ModelPrices: map[string]GroupModelPrice{
+		"claude-opus-*": {CacheWritePrice: testModelPrice(10)},
+	}}
+
+	cost, err := svc.CalculateCostWithServiceTier("claude-opus-4-6", tokens, 1.0, "", group)
+	require.NoError(t, err)
+	require.InDelta(t, 3000*10e-6, cost.CacheCreationCost, 1e-12)
+}
+
+func TestCalculateCostWithServiceTier_GroupPriceUsesTierMultiplier(t *testing.T) {
+	svc := NewBillingService(&config.Config{}, &PricingService{
+		pricingData: map[string]*LiteLLMModelPricing{
+			"gpt-5.4": {
+				InputCostPerToken:          2.5e-6,
+				InputCostPerTokenPriority:  5e-6,
+				OutputCostPerToken:         15e-6,
+				OutputCostPerTokenPriority: 30e-6,
+			},
+		},
+	})
+	tokens := UsageTokens{InputTokens: 100, OutputTokens: 100}
+
+	global, err := svc.CalculateCostWithServiceTier("gpt-5.4", tokens, 1.0, "priority", nil)
+	require.NoError(t, err)
+	require.Equal(t, PriceSourceLiteLLM, global.PriceSource)
+
+	group := &Group{ModelPrices: map[string]GroupModelPrice{"gpt-5.4": {InputPrice: testModelPrice(3), OutputPrice: testModelPrice(18)}}}
+	cost, err := svc.CalculateCostWithServiceTier("gpt-5.4", tokens, 1.0, "priority", group)
+	require.NoError(t, err)
+	require.Equal(t, PriceSourceGroup, cost.PriceSource)
+	require.InDelta(t, 100*3e-6*2, cost.InputCost, 1e-12)
+	require.InDelta(t, 100*18e-6*2, cost.OutputCost, 1e-12)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Images API 端点
+const (
+	ImagesEndpointGenerations = "generations"
+	ImagesEndpointEdits       = "edits"
+)
+
+// Images API response_format
+const (
+	ImagesResponseFormatB64JSON = "b64_json"
+	ImagesResponseFormatURL     = "url"
+)
+
+// MaxImagesPerRequest Images API 单次请求最多生成的图片数量（与 OpenAI 限制一致）
+const MaxImagesPerRequest = 10
+
+var errImagesNoOutput = errors.New("upstream returned no image")
+
+// ImageGenerationInput 一次 OpenAI Images API 请求
+type ImageGenerationInput struct {
+	Endpoint    string // ImagesEndpointGenerations / ImagesEndpointEdits
+	Body        []byte // 原始请求体（OpenAI 原生转发使用）
+	ContentType string
+	Request     *apicompat.ImagesRequest
+	// BodyModel 原始请求体中的模型名；分组模型别名解析后与 Request.Model 不同，转发时需重写
+	BodyModel string
+	// BaseURL 网关对外地址（scheme://host），response_format=url 时用于拼接图片地址
+	BaseURL string
+}
+
+// ImageGenerationService 处理 OpenAI Images API（/v1/images/generations、/v1/images/edits）：
+// OpenAI API Key 账号原生转发；Gemini / Antigravity 账号转换为图片模型的 generateContent 请求。
+// 按 response_format 返回 b64_json，或落地到媒体目录后返回 /sora/media(-signed) 地址。
+type ImageGenerationService struct {
+	openaiGateway *OpenAIGatewayService
+	geminiCompat  *GeminiMessagesCompatService
+	antigravity   *AntigravityGatewayService
+	mediaStorage  *SoraMediaStorage
+	cfg           *config.Config
+}
+
+// NewImageGenerationService 创建 ImageGenerationService
+func NewImageGenerationService(
+	openaiGateway *OpenAIGatewayService,
+	geminiCompat *GeminiMessagesCompatService,
+	antigravity *AntigravityGatewayService,
+	mediaStorage *SoraMediaStorage,
+	cfg *config.Config,
+) *ImageGenerationService {
+	return &ImageGenerationService{
+		openaiGateway: openaiGateway,
+		geminiCompat:  geminiCompat,
+		antigravity:   antigravity,
+		mediaStorage:  mediaStorage,
+		cfg:           cfg,
+	}
+}
+
+// SupportsPlatform 判断平台是否支持 Images API
+func (s *ImageGenerationService) SupportsPlatform(platform string) bool {
+	switch platform {
+	case PlatformOpenAI, PlatformGemini, PlatformAntigravity:
+		return true
+	default:
+		return false
+	}
+}
+
+// Forward 按账号平台转发 Images 请求并写出 OpenAI 格式响应。
+// 返回的 ForwardResult 带 ImageCount / ImageSize，由 GatewayService.RecordUsage 按分组图片单价计费。
+func (s *ImageGenerationService) Forward(ctx context.Context, c *gin.Context, account *Account, input *ImageGenerationInput) (*ForwardResult, error) {
+	startTime := time.Now()
+	req := input.Request
+	result := &ForwardResult{
+		Model:     req.Model,
+		ImageSize: apicompat.ImageSizeTier(req.Size),
+	}
+
+	var resp *apicompat.ImagesResponse
+	switch account.Platform {
+	case PlatformOpenAI:
+		upstream, err := s.openaiGateway.forwardImages(ctx, c, account, input)
+		if err != nil {
+			return nil, err
+		}
+		resp = upstream.Response
+		result.UpstreamModel = upstream.UpstreamModel
+		result.RequestID = upstream.RequestID
+	case PlatformGemini:
+		var err error
+		resp, err = s.forwardGeminiImages(c, req, result, func(model string, body []byte) (*ForwardResult, error) {
+			return s.geminiCompat.ForwardNative(ctx, c, account, model, "generateContent", false, body)
+		})
+		if err != nil {
+			return nil, err
+		}
+	case PlatformAntigravity:
+		var err error
+		resp, err = s.forwardGeminiImages(c, req, result, func(model string, body []byte) (*ForwardResult, error) {
+			return s.antigravity.ForwardGemini(ctx, c, account, model, "generateContent", false, body, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		writeChatCompletionsError(c, http.StatusBadRequest, "invalid_request_error", "Images API is not supported for this platform")
+		return nil, fmt.Errorf("images api unsupported for platform %s", account.Platform)
+	}
+
+	if resp == nil || len(resp.Data) == 0 {
+		writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Upstream returned no image")
+		return nil, errImagesNoOutput
+	}
+	if req.ResponseFormat == ImagesResponseFormatURL {
+		if err := s.storeImagesAsURLs(resp, input.BaseURL); err != nil {
+			logger.LegacyPrintf("service.image_generation", "[Images] store generated images failed: %v", err)
+			writeChatCompletionsError(c, http.StatusInternalServerError, "api_error", "Failed to store generated images, please use response_format=b64_json")
+			return nil, err
+		}
+	}
+	if resp.Usage != nil && result.Usage.InputTokens == 0 && result.Usage.OutputTokens == 0 {
+		result.Usage.InputTokens = resp.Usage.InputTokens
+		result.Usage.OutputTokens = resp.Usage.OutputTokens
+	}
+	if resp.Created == 0 {
+		resp.Created = time.Now().Unix()
+	}
+
+	body, err := jsonMarshalRaw(resp)
+	if err != nil {
+		return nil, fmt.Errorf("marshal images response: %w", err)
+	}
+	c.Data(http.StatusOK, "application/json", body)
+
+	result.ImageCount = len(resp.Data)
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// forwardGeminiImages 将 Images 请求转换为 Gemini 图片模型请求。Gemini 每次调用只返回一张图片，
+// n > 1 时顺序发起 n 次调用；任一调用失败即返回错误（此时尚未写出成功响应，可安全 failover）。
+func (s *ImageGenerationService) forwardGeminiImages(
+	c *gin.Context,
+	req *apicompat.ImagesRequest,
+	result *ForwardResult,
+	forward func(model string, body []byte) (*ForwardResult, error),
+) (*apicompat.ImagesResponse, error) {
+	geminiBody, err := json.Marshal(apicompat.ImagesToGeminiRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("marshal gemini request: %w", err)
+	}
+
+	n := req.N
+	if n <= 0 {
+		n = 1
+	}
+	out := &apicompat.ImagesResponse{Created: time.Now().Unix()}
+	for i := 0; i < n; i++ {
+		original := c.Writer
+		w := newGeminiOpenAIResponseWriter(original, geminiOpenAIImages, req.Model, false, false)
+		c.Writer = w
+		callResult, err := forward(req.Model, geminiBody)
+		c.Writer = original
+		if finishErr := w.finish(c, err); finishErr != nil && err == nil {
+			return nil, finishErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if w.geminiResp == nil {
+			writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Upstream returned no image")
+			return nil, errImagesNoOutput
+		}
+
+		if callResult != nil {
+			if result.RequestID == "" {
+				result.RequestID = callResult.RequestID
+			}
+			result.UpstreamModel = callResult.UpstreamModel
+			result.Usage.InputTokens += callResult.Usage.InputTokens
+			result.Usage.OutputTokens += callResult.Usage.OutputTokens
+		}
+		converted := apicompat.GeminiToImagesResponse(w.geminiResp)
+		if len(converted.Data) == 0 && isGeminiImageSafetyBlocked(w.geminiResp) {
+			writeChatCompletionsError(c, http.StatusBadRequest, "content_policy_violation", "Your request was rejected by the upstream safety system")
+			return nil, errImagesNoOutput
+		}
+		out.Data = append(out.Data, converted.Data...)
+	}
+	return out, nil
+}
+
+// isGeminiImageSafetyBlocked 判断 Gemini 是否因安全策略拒绝生成图片
+func isGeminiImageSafetyBlocked(resp *apicompat.GeminiResponse) bool {
+	for _, cand := range resp.Candidates {
+		switch strings.ToUpper(cand.FinishReason) {
+		case "SAFETY", "IMAGE_SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST", "SPII", "RECITATION":
+			return true
+		}
+	}
+	return false
+}
+
+// storeImagesAsURLs 将 b64_json 图片落地到媒体目录，并替换为对外访问地址（复用 Sora 媒体签名）。
+// 上游已返回 url 的条目保持不变。
+func (s *ImageGenerationService) storeImagesAsURLs(resp *apicompat.ImagesResponse, baseURL string) error {
+	if s.mediaStorage == nil || !s.mediaStorage.Enabled() {
+		return errors.New("media storage is not enabled")
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	for i := range resp.Data {
+		item := &resp.Data[i]
+		if item.B64JSON == "" {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(item.B64JSON)
+		if err != nil {
+			return fmt.Errorf("decode image: %w", err)
+		}
+		relative, err := s.mediaStorage.StoreBytes("image", imageFileExt(data), data)
+		if err != nil {
+			return err
+		}
+		item.URL = baseURL + buildSoraMediaURL(s.cfg, relative, "")
+		item.B64JSON = ""
+	}
+	return nil
+}
+
+// imageFileExt 根据图片内容推断扩展名
+func imageFileExt(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
+}
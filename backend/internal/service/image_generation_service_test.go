@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestImageGenerationForward_GeminiB64JSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/images/generations", nil)
+
+	geminiSvc, httpStub := newGeminiOpenAICompatTestService(http.StatusOK, "application/json",
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"A red cat"},{"inlineData":{"mimeType":"image/png","data":"aW1n"}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":1290}}`)
+	svc := NewImageGenerationService(nil, geminiSvc, nil, nil, &config.Config{})
+	account := &Account{ID: 1, Type: AccountTypeAPIKey, Platform: PlatformGemini, Credentials: map[string]any{"api_key": "test-key"}}
+	input := &ImageGenerationInput{
+		Endpoint: ImagesEndpointGenerations,
+		Request:  &apicompat.ImagesRequest{Model: "gemini-2.5-flash-image", Prompt: "a cat", Size: "1536x1024"},
+	}
+
+	result, err := svc.Forward(context.Background(), c, account, input)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.ImageCount)
+	require.Equal(t, "2K", result.ImageSize)
+	require.Equal(t, 12, result.Usage.InputTokens)
+
+	upstreamBody, _ := io.ReadAll(httpStub.lastReq.Body)
+	require.Contains(t, httpStub.lastReq.URL.String(), "/models/gemini-2.5-flash-image:generateContent")
+	require.Equal(t, "a cat", gjson.GetBytes(upstreamBody, "contents.0.parts.0.text").String())
+	require.Equal(t, "3:2", gjson.GetBytes(upstreamBody, "generationConfig.imageConfig.aspectRatio").String())
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "aW1n", gjson.Get(w.Body.String(), "data.0.b64_json").String())
+	require.Equal(t, "A red cat", gjson.Get(w.Body.String(), "data.0.revised_prompt").String())
+}
+
+func TestImageGenerationForward_GeminiSafetyBlocked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/images/generations", nil)
+
+	geminiSvc, _ := newGeminiOpenAICompatTestService(http.StatusOK, "application/json",
+		`{"candidates":[{"finishReason":"IMAGE_SAFETY"}]}`)
+	svc := NewImageGenerationService(nil, geminiSvc, nil, nil, &config.Config{})
+	account := &Account{ID: 1, Type: AccountTypeAPIKey, Platform: PlatformGemini, Credentials: map[string]any{"api_key": "test-key"}}
+	input := &ImageGenerationInput{Request: &apicompat.ImagesRequest{Model: "gemini-2.5-flash-image", Prompt: "x"}}
+
+	_, err := svc.Forward(context.Background(), c, account, input)
+	require.ErrorIs(t, err, errImagesNoOutput)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "content_policy_violation", gjson.Get(w.Body.String(), "error.type").String())
+}
+
+func TestImageGenerationForward_URLRequiresMediaStorage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/images/generations", nil)
+
+	geminiSvc, _ := newGeminiOpenAICompatTestService(http.StatusOK, "application/json",
+		`{"candidates":[{"content":{"role":"model","parts":[{"inlineData":{"mimeType":"image/png","data":"aW1n"}}]},"finishReason":"STOP"}]}`)
+	svc := NewImageGenerationService(nil, geminiSvc, nil, nil, &config.Config{})
+	account := &Account{ID: 1, Type: AccountTypeAPIKey, Platform: PlatformGemini, Credentials: map[string]any{"api_key": "test-key"}}
+	input := &ImageGenerationInput{Request: &apicompat.ImagesRequest{Model: "gemini-2.5-flash-image", Prompt: "x", ResponseFormat: ImagesResponseFormatURL}}
+
+	_, err := svc.Forward(context.Background(), c, account, input)
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestBuildOpenAIImagesUpstreamBody_JSON(t *testing.T) {
+	input := &ImageGenerationInput{
+		Body:        []byte(`{"model":"alias","prompt":"p","response_format":"url"}`),
+		ContentType: "application/json",
+		Request:     &apicompat.ImagesRequest{Model: "alias", Prompt: "p", ResponseFormat: "url"},
+	}
+
+	body, err := buildOpenAIImagesUpstreamBody(input, "gpt-image-1")
+	require.NoError(t, err)
+	require.Equal(t, "gpt-image-1", gjson.GetBytes(body, "model").String())
+	require.False(t, gjson.GetBytes(body, "response_format").Exists())
+
+	input.Request.ResponseFormat = ""
+	unchanged, err := buildOpenAIImagesUpstreamBody(input, "alias")
+	require.NoError(t, err)
+	require.Equal(t, input.Body, unchanged)
+}
+
+func TestBuildOpenAIImagesUpstreamBody_Multipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("model", "dall-e-2"))
+	require.NoError(t, mw.WriteField("prompt", "add a hat"))
+	fw, err := mw.CreateFormFile("image", "cat.png")
+	require.NoError(t, err)
+	_, _ = fw.Write([]byte("\x89PNG\r\n\x1a\nimage-bytes"))
+	require.NoError(t, mw.Close())
+
+	input := &ImageGenerationInput{
+		Body:        buf.Bytes(),
+		ContentType: mw.FormDataContentType(),
+		Request:     &apicompat.ImagesRequest{Model: "dall-e-2", Prompt: "add a hat"},
+	}
+	body, err := buildOpenAIImagesUpstreamBody(input, "dall-e-2-mapped")
+	require.NoError(t, err)
+
+	parsed, err := apicompat.ParseImagesRequest(input.ContentType, body)
+	require.NoError(t, err)
+	require.Equal(t, "dall-e-2-mapped", parsed.Model)
+	require.Equal(t, "add a hat", parsed.Prompt)
+	require.Len(t, parsed.Images, 1)
+	require.Equal(t, "image/png", parsed.Images[0].MimeType)
+}
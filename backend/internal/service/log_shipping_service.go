@@ -0,0 +1,442 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
+)
+
+const (
+	logShippingDefaultQueueSize     = 5000
+	logShippingDefaultBatchSize     = 200
+	logShippingDefaultFlushInterval = time.Second
+	logShippingDefaultTimeout       = 5 * time.Second
+	logShippingMaxBatchSize         = 5000
+)
+
+// LogShippingSinkHealth 外部日志投递 sink 的健康状态
+type LogShippingSinkHealth struct {
+	Name          string     `json:"name"`
+	Type          string     `json:"type"`
+	Healthy       bool       `json:"healthy"`
+	QueueDepth    int64      `json:"queue_depth"`
+	QueueCapacity int64      `json:"queue_capacity"`
+	DroppedCount  uint64     `json:"dropped_count"`
+	SentCount     uint64     `json:"sent_count"`
+	FailedCount   uint64     `json:"failed_count"`
+	LastError     string     `json:"last_error"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+// logShippingRecord 已脱敏、可直接序列化的日志记录
+type logShippingRecord struct {
+	Time      time.Time
+	Level     string
+	Component string
+	Message   string
+	Fields    map[string]any
+}
+
+// logShippingTransport 具体投递协议；Send 返回成功接收的条数。
+type logShippingTransport interface {
+	Send(ctx context.Context, records []*logShippingRecord) (int, error)
+	Close() error
+}
+
+// LogShippingService 将结构化日志异步投递到外部日志系统（Loki / Elasticsearch / HTTP / syslog）。
+// 与 OpsSystemLogSink 并列挂载在 logger sink 上；每个 sink 有独立的有界队列，队列满时丢弃并计数，不阻塞业务日志。
+type LogShippingService struct {
+	sinks []*logShippingSink
+}
+
+func NewLogShippingService(cfg *config.Config) *LogShippingService {
+	s := &LogShippingService{}
+	if cfg == nil {
+		return s
+	}
+	for i := range cfg.Log.Shipping.Sinks {
+		sinkCfg := cfg.Log.Shipping.Sinks[i]
+		if !sinkCfg.Enabled {
+			continue
+		}
+		transport, err := newLogShippingTransport(&sinkCfg, cfg.Log.ServiceName)
+		if err != nil {
+			// 此时 logger 可能尚未挂载 sink，直接写 stderr，避免日志回流到自身。
+			_, _ = fmt.Fprintf(os.Stderr, "time=%s level=WARN msg=\"log shipping sink disabled\" sink=%s err=%v\n",
+				time.Now().Format(time.RFC3339Nano), sinkCfg.Name, err,
+			)
+			continue
+		}
+		s.sinks = append(s.sinks, newLogShippingSink(&sinkCfg, transport))
+	}
+	return s
+}
+
+func (s *LogShippingService) Start() {
+	if s == nil {
+		return
+	}
+	for _, sink := range s.sinks {
+		sink.start()
+	}
+}
+
+func (s *LogShippingService) Stop() {
+	if s == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, sink := range s.sinks {
+		wg.Add(1)
+		go func(sink *logShippingSink) {
+			defer wg.Done()
+			sink.stop()
+		}(sink)
+	}
+	wg.Wait()
+}
+
+// Enabled 是否配置了至少一个启用的 sink
+func (s *LogShippingService) Enabled() bool {
+	return s != nil && len(s.sinks) > 0
+}
+
+func (s *LogShippingService) WriteLogEvent(event *logger.LogEvent) {
+	if s == nil || event == nil {
+		return
+	}
+	for _, sink := range s.sinks {
+		sink.write(event)
+	}
+}
+
+func (s *LogShippingService) Health() []LogShippingSinkHealth {
+	if s == nil {
+		return nil
+	}
+	out := make([]LogShippingSinkHealth, 0, len(s.sinks))
+	for _, sink := range s.sinks {
+		out = append(out, sink.health())
+	}
+	return out
+}
+
+type logShippingSink struct {
+	name      string
+	kind      string
+	transport logShippingTransport
+
+	minLevel       int
+	modules        []string
+	excludeModules []string
+
+	queue         chan *logger.LogEvent
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+
+	droppedCount  uint64
+	sentCount     uint64
+	failedCount   uint64
+	lastErrorAt   atomic.Int64
+	lastSuccessAt atomic.Int64
+	lastError     atomic.Value
+}
+
+func newLogShippingSink(cfg *config.LogShippingSinkConfig, transport logShippingTransport) *logShippingSink {
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = logShippingDefaultQueueSize
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = logShippingDefaultBatchSize
+	}
+	if batchSize > logShippingMaxBatchSize {
+		batchSize = logShippingMaxBatchSize
+	}
+	flushInterval := logShippingDefaultFlushInterval
+	if cfg.FlushIntervalMs > 0 {
+		flushInterval = time.Duration(cfg.FlushIntervalMs) * time.Millisecond
+	}
+	timeout := logShippingDefaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	minLevel := logShippingLevelRank(cfg.MinLevel)
+	if strings.TrimSpace(cfg.MinLevel) == "" {
+		minLevel = logShippingLevelRank("info")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &logShippingSink{
+		name:           cfg.Name,
+		kind:           cfg.Type,
+		transport:      transport,
+		minLevel:       minLevel,
+		modules:        normalizeLogShippingModules(cfg.Modules),
+		excludeModules: normalizeLogShippingModules(cfg.ExcludeModules),
+		queue:          make(chan *logger.LogEvent, queueSize),
+		batchSize:      batchSize,
+		flushInterval:  flushInterval,
+		timeout:        timeout,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	s.lastError.Store("")
+	return s
+}
+
+func (s *logShippingSink) start() {
+	if !s.started.CompareAndSwap(false, true) {
+		return
+	}
+	s.wg.Add(1)
+	go s.run()
+}
+
+func (s *logShippingSink) stop() {
+	s.cancel()
+	s.wg.Wait()
+	_ = s.transport.Close()
+}
+
+func (s *logShippingSink) write(event *logger.LogEvent) {
+	if !s.accepts(event) {
+		return
+	}
+	select {
+	case <-s.ctx.Done():
+		return
+	default:
+	}
+	select {
+	case s.queue <- event:
+	default:
+		atomic.AddUint64(&s.droppedCount, 1)
+	}
+}
+
+// accepts 按级别与组件前缀过滤（组件名优先取字段 component，与 OpsSystemLogSink 保持一致）
+func (s *logShippingSink) accepts(event *logger.LogEvent) bool {
+	if logShippingLevelRank(event.Level) < s.minLevel {
+		return false
+	}
+	if len(s.modules) == 0 && len(s.excludeModules) == 0 {
+		return true
+	}
+	component := strings.ToLower(logShippingComponent(event))
+	for _, prefix := range s.excludeModules {
+		if strings.HasPrefix(component, prefix) {
+			return false
+		}
+	}
+	if len(s.modules) == 0 {
+		return true
+	}
+	for _, prefix := range s.modules {
+		if strings.HasPrefix(component, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *logShippingSink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*logger.LogEvent, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.flush(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			for {
+				select {
+				case item := <-s.queue:
+					batch = append(batch, item)
+					if len(batch) >= s.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case item := <-s.queue:
+			batch = append(batch, item)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *logShippingSink) flush(batch []*logger.LogEvent) {
+	records := make([]*logShippingRecord, 0, len(batch))
+	for _, event := range batch {
+		if event != nil {
+			records = append(records, newLogShippingRecord(event))
+		}
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	// 停止阶段 ctx 已取消，使用独立的超时上下文把剩余日志发出去。
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	sent, err := s.transport.Send(ctx, records)
+	if sent > 0 {
+		atomic.AddUint64(&s.sentCount, uint64(sent))
+	}
+	if err != nil {
+		failed := len(records) - sent
+		if failed < 0 {
+			failed = 0
+		}
+		atomic.AddUint64(&s.failedCount, uint64(failed))
+		s.lastError.Store(err.Error())
+		s.lastErrorAt.Store(time.Now().UnixNano())
+		_, _ = fmt.Fprintf(os.Stderr, "time=%s level=WARN msg=\"log shipping flush failed\" sink=%s type=%s err=%v batch=%d\n",
+			time.Now().Format(time.RFC3339Nano), s.name, s.kind, err, len(records),
+		)
+		return
+	}
+	s.lastError.Store("")
+	s.lastSuccessAt.Store(time.Now().UnixNano())
+}
+
+func (s *logShippingSink) health() LogShippingSinkHealth {
+	lastErr, _ := s.lastError.Load().(string)
+	return LogShippingSinkHealth{
+		Name:          s.name,
+		Type:          s.kind,
+		Healthy:       strings.TrimSpace(lastErr) == "",
+		QueueDepth:    int64(len(s.queue)),
+		QueueCapacity: int64(cap(s.queue)),
+		DroppedCount:  atomic.LoadUint64(&s.droppedCount),
+		SentCount:     atomic.LoadUint64(&s.sentCount),
+		FailedCount:   atomic.LoadUint64(&s.failedCount),
+		LastError:     strings.TrimSpace(lastErr),
+		LastErrorAt:   unixNanoToTimePtr(s.lastErrorAt.Load()),
+		LastSuccessAt: unixNanoToTimePtr(s.lastSuccessAt.Load()),
+	}
+}
+
+func newLogShippingRecord(event *logger.LogEvent) *logShippingRecord {
+	ts := event.Time.UTC()
+	if event.Time.IsZero() {
+		ts = time.Now().UTC()
+	}
+	level := strings.ToLower(strings.TrimSpace(event.Level))
+	if level == "" {
+		level = "info"
+	}
+	return &logShippingRecord{
+		Time:      ts,
+		Level:     level,
+		Component: logShippingComponent(event),
+		Message:   logredact.RedactText(strings.TrimSpace(event.Message)),
+		Fields:    logredact.RedactMap(copyMap(event.Fields)),
+	}
+}
+
+// document 扁平化为单层 JSON 文档；保留字段（@timestamp/level/component/message）覆盖同名业务字段。
+func (r *logShippingRecord) document() map[string]any {
+	doc := make(map[string]any, len(r.Fields)+4)
+	for k, v := range r.Fields {
+		doc[k] = v
+	}
+	doc["@timestamp"] = r.Time.Format(time.RFC3339Nano)
+	doc["level"] = r.Level
+	doc["component"] = r.Component
+	doc["message"] = r.Message
+	return doc
+}
+
+// marshal 序列化文档；字段中含无法序列化的值时退化为仅保留基础字段。
+func (r *logShippingRecord) marshal() []byte {
+	if raw, err := json.Marshal(r.document()); err == nil {
+		return raw
+	}
+	raw, _ := json.Marshal(map[string]any{
+		"@timestamp": r.Time.Format(time.RFC3339Nano),
+		"level":      r.Level,
+		"component":  r.Component,
+		"message":    r.Message,
+	})
+	return raw
+}
+
+func logShippingComponent(event *logger.LogEvent) string {
+	component := strings.TrimSpace(event.Component)
+	if event.Fields != nil {
+		if fc := asString(event.Fields["component"]); fc != "" {
+			component = fc
+		}
+	}
+	if component == "" {
+		component = "app"
+	}
+	return component
+}
+
+func logShippingLevelRank(level string) int {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return 0
+	case "warn", "warning":
+		return 2
+	case "error":
+		return 3
+	case "dpanic", "panic", "fatal":
+		return 4
+	default:
+		return 1
+	}
+}
+
+func normalizeLogShippingModules(modules []string) []string {
+	out := make([]string, 0, len(modules))
+	for _, m := range modules {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func unixNanoToTimePtr(v int64) *time.Time {
+	if v <= 0 {
+		return nil
+	}
+	t := time.Unix(0, v).UTC()
+	return &t
+}
@@ -0,0 +1,313 @@
+//go:build unit
+
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogShippingTransport struct {
+	mu      sync.Mutex
+	records []*logShippingRecord
+	err     error
+}
+
+func (t *recordingLogShippingTransport) Send(ctx context.Context, records []*logShippingRecord) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return 0, t.err
+	}
+	t.records = append(t.records, records...)
+	return len(records), nil
+}
+
+func (t *recordingLogShippingTransport) Close() error { return nil }
+
+func (t *recordingLogShippingTransport) snapshot() []*logShippingRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*logShippingRecord(nil), t.records...)
+}
+
+func TestLogShippingSink_FiltersByLevelAndModule(t *testing.T) {
+	sink := newLogShippingSink(&config.LogShippingSinkConfig{
+		Name:           "test",
+		Type:           config.LogShippingSinkHTTP,
+		MinLevel:       "warn",
+		Modules:        []string{"service.", "Handler.Admin"},
+		ExcludeModules: []string{"service.noisy"},
+	}, &recordingLogShippingTransport{})
+
+	require.False(t, sink.accepts(&logger.LogEvent{Level: "info", Component: "service.ops"}))
+	require.True(t, sink.accepts(&logger.LogEvent{Level: "warn", Component: "service.ops"}))
+	require.True(t, sink.accepts(&logger.LogEvent{Level: "error", Fields: map[string]any{"component": "handler.admin.ops"}}))
+	require.False(t, sink.accepts(&logger.LogEvent{Level: "error", Component: "service.noisy.worker"}))
+	require.False(t, sink.accepts(&logger.LogEvent{Level: "error", Component: "repository"}))
+
+	all := newLogShippingSink(&config.LogShippingSinkConfig{Name: "all", Type: config.LogShippingSinkHTTP}, &recordingLogShippingTransport{})
+	require.False(t, all.accepts(&logger.LogEvent{Level: "debug"}))
+	require.True(t, all.accepts(&logger.LogEvent{Level: "info"}))
+}
+
+func TestLogShippingSink_DropsWhenQueueFullAndReportsHealth(t *testing.T) {
+	transport := &recordingLogShippingTransport{}
+	sink := newLogShippingSink(&config.LogShippingSinkConfig{Name: "small", Type: config.LogShippingSinkHTTP, QueueSize: 2}, transport)
+
+	// 未启动时队列不会被消费，第 3 条开始丢弃
+	for i := 0; i < 5; i++ {
+		sink.write(&logger.LogEvent{Level: "info", Message: "m" + strconv.Itoa(i)})
+	}
+	health := sink.health()
+	require.Equal(t, int64(2), health.QueueDepth)
+	require.Equal(t, uint64(3), health.DroppedCount)
+
+	sink.start()
+	sink.stop()
+	require.Len(t, transport.snapshot(), 2)
+	health = sink.health()
+	require.True(t, health.Healthy)
+	require.Equal(t, uint64(2), health.SentCount)
+	require.NotNil(t, health.LastSuccessAt)
+}
+
+func TestLogShippingSink_RecordsFailures(t *testing.T) {
+	transport := &recordingLogShippingTransport{err: errors.New("connection refused")}
+	sink := newLogShippingSink(&config.LogShippingSinkConfig{Name: "down", Type: config.LogShippingSinkHTTP}, transport)
+	sink.start()
+	sink.write(&logger.LogEvent{Level: "error", Message: "boom"})
+	sink.stop()
+
+	health := sink.health()
+	require.False(t, health.Healthy)
+	require.Equal(t, uint64(1), health.FailedCount)
+	require.Equal(t, "connection refused", health.LastError)
+	require.NotNil(t, health.LastErrorAt)
+}
+
+func TestNewLogShippingRecord_RedactsAndFlattens(t *testing.T) {
+	rec := newLogShippingRecord(&logger.LogEvent{
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:     "WARN",
+		Component: "zap",
+		Message:   "upstream failed",
+		Fields: map[string]any{
+			"component":    "service.gateway",
+			"access_token": "secret-token",
+			"message":      "shadowed",
+			"account_id":   7,
+		},
+	})
+	require.Equal(t, "warn", rec.Level)
+	require.Equal(t, "service.gateway", rec.Component)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rec.marshal(), &doc))
+	require.Equal(t, "2026-01-02T03:04:05Z", doc["@timestamp"])
+	require.Equal(t, "upstream failed", doc["message"])
+	require.Equal(t, float64(7), doc["account_id"])
+	require.NotEqual(t, "secret-token", doc["access_token"])
+}
+
+func TestLokiLogTransport_PushesStreamsByLevel(t *testing.T) {
+	var body map[string]any
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	transport, err := newLogShippingTransport(&config.LogShippingSinkConfig{
+		Type:        config.LogShippingSinkLoki,
+		URL:         srv.URL,
+		BearerToken: "tkn",
+		Labels:      map[string]string{"env": "test"},
+	}, "sub2api")
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	sent, err := transport.Send(context.Background(), []*logShippingRecord{
+		{Time: now, Level: "info", Component: "a", Message: "one"},
+		{Time: now, Level: "error", Component: "b", Message: "two"},
+		{Time: now, Level: "info", Component: "c", Message: "three"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, sent)
+	require.Equal(t, "Bearer tkn", auth)
+
+	streams := body["streams"].([]any)
+	require.Len(t, streams, 2)
+	first := streams[0].(map[string]any)
+	labels := first["stream"].(map[string]any)
+	require.Equal(t, "error", labels["level"])
+	require.Equal(t, "test", labels["env"])
+	require.Equal(t, "sub2api", labels["service"])
+	values := streams[1].(map[string]any)["values"].([]any)
+	require.Len(t, values, 2)
+	require.Equal(t, strconv.FormatInt(now.UnixNano(), 10), values[0].([]any)[0])
+}
+
+func TestElasticsearchLogTransport_BulkAndPartialFailure(t *testing.T) {
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/_bulk", r.URL.Path)
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "elastic", user)
+		require.Equal(t, "pw", pass)
+		raw, _ := io.ReadAll(r.Body)
+		lines = strings.Split(strings.TrimSpace(string(raw)), "\n")
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}]}`))
+	}))
+	defer srv.Close()
+
+	transport, err := newLogShippingTransport(&config.LogShippingSinkConfig{
+		Type:     config.LogShippingSinkElasticsearch,
+		URL:      srv.URL + "/",
+		Username: "elastic",
+		Password: "pw",
+		Index:    "logs-{date}",
+	}, "sub2api")
+	require.NoError(t, err)
+
+	ts := time.Date(2026, 2, 3, 23, 0, 0, 0, time.UTC)
+	sent, err := transport.Send(context.Background(), []*logShippingRecord{
+		{Time: ts, Level: "info", Message: "a"},
+		{Time: ts, Level: "info", Message: "b"},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mapper_parsing_exception")
+	require.Equal(t, 1, sent)
+	require.Len(t, lines, 4)
+	require.JSONEq(t, `{"index":{"_index":"logs-2026.02.03"}}`, lines[0])
+}
+
+func TestHTTPJSONLogTransport_PostsArray(t *testing.T) {
+	var docs []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "v", r.Header.Get("X-Custom"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&docs))
+	}))
+	defer srv.Close()
+
+	transport, err := newLogShippingTransport(&config.LogShippingSinkConfig{
+		Type:    config.LogShippingSinkHTTP,
+		URL:     srv.URL,
+		Headers: map[string]string{"X-Custom": "v"},
+	}, "sub2api")
+	require.NoError(t, err)
+
+	sent, err := transport.Send(context.Background(), []*logShippingRecord{{Time: time.Now(), Level: "info", Component: "x", Message: "hi"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.Len(t, docs, 1)
+	require.Equal(t, "hi", docs[0]["message"])
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	})
+	_, err = transport.Send(context.Background(), []*logShippingRecord{{Time: time.Now(), Level: "info"}})
+	require.ErrorContains(t, err, "http 503")
+}
+
+func TestSyslogLogTransport_UDPAndTCP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = pc.Close() }()
+
+	udp := newSyslogLogTransport(&config.LogShippingSinkConfig{Network: "udp", Address: pc.LocalAddr().String(), AppName: "my app"}, "sub2api", time.Second)
+	defer func() { _ = udp.Close() }()
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	sent, err := udp.Send(context.Background(), []*logShippingRecord{{Time: ts, Level: "error", Component: "service.gateway", Message: "upstream down"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+	// local0(16)*8 + err(3) = 131
+	require.True(t, strings.HasPrefix(msg, "<131>1 2026-01-02T03:04:05.123456Z "), msg)
+	require.Contains(t, msg, " myapp "+udp.procID+" service.gateway - {")
+	require.Contains(t, msg, `"message":"upstream down"`)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	frames := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+			frame := make([]byte, size)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				return
+			}
+			frames <- string(frame)
+		}
+	}()
+
+	tcp := newSyslogLogTransport(&config.LogShippingSinkConfig{Network: "tcp", Address: ln.Addr().String(), Facility: 1}, "sub2api", time.Second)
+	defer func() { _ = tcp.Close() }()
+	sent, err = tcp.Send(context.Background(), []*logShippingRecord{
+		{Time: ts, Level: "info", Message: "a"},
+		{Time: ts, Level: "debug", Message: "b"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, sent)
+	for _, prefix := range []string{"<14>1 ", "<15>1 "} {
+		select {
+		case frame := <-frames:
+			require.True(t, strings.HasPrefix(frame, prefix), frame)
+			require.Contains(t, frame, " sub2api ")
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for syslog frame")
+		}
+	}
+}
+
+func TestLogShippingService_SkipsDisabledSinks(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Log.ServiceName = "sub2api"
+	cfg.Log.Shipping.Sinks = []config.LogShippingSinkConfig{
+		{Name: "off", Type: config.LogShippingSinkHTTP, URL: "http://127.0.0.1:1"},
+		{Name: "on", Type: config.LogShippingSinkSyslog, Enabled: true, Network: "udp", Address: "127.0.0.1:1"},
+	}
+	svc := NewLogShippingService(cfg)
+	require.True(t, svc.Enabled())
+	health := svc.Health()
+	require.Len(t, health, 1)
+	require.Equal(t, "on", health[0].Name)
+	require.Equal(t, config.LogShippingSinkSyslog, health[0].Type)
+
+	require.False(t, NewLogShippingService(&config.Config{}).Enabled())
+}
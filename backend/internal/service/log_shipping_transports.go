@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+)
+
+const (
+	logShippingMaxResponseBytes   = 64 << 10
+	logShippingDefaultESIndex     = "sub2api-logs-{date}"
+	logShippingDefaultSyslogFac   = 16 // local0
+	logShippingSyslogMaxUDPBytes  = 8 << 10
+	logShippingSyslogMaxFieldLen  = 48
+	logShippingSyslogMaxMsgIDLen  = 32
+	logShippingSyslogTimestampFmt = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+func newLogShippingTransport(cfg *config.LogShippingSinkConfig, serviceName string) (logShippingTransport, error) {
+	timeout := logShippingDefaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	switch cfg.Type {
+	case config.LogShippingSinkLoki, config.LogShippingSinkElasticsearch, config.LogShippingSinkHTTP:
+		client, err := httpclient.GetClient(httpclient.Options{Timeout: timeout})
+		if err != nil {
+			return nil, err
+		}
+		base := logShippingHTTP{client: client, cfg: cfg}
+		switch cfg.Type {
+		case config.LogShippingSinkLoki:
+			labels := map[string]string{}
+			for k, v := range cfg.Labels {
+				labels[k] = v
+			}
+			if _, ok := labels["service"]; !ok && strings.TrimSpace(serviceName) != "" {
+				labels["service"] = serviceName
+			}
+			return &lokiLogTransport{logShippingHTTP: base, labels: labels}, nil
+		case config.LogShippingSinkElasticsearch:
+			index := strings.TrimSpace(cfg.Index)
+			if index == "" {
+				index = logShippingDefaultESIndex
+			}
+			return &elasticsearchLogTransport{logShippingHTTP: base, endpoint: elasticsearchBulkURL(cfg.URL), index: index}, nil
+		default:
+			return &httpJSONLogTransport{logShippingHTTP: base}, nil
+		}
+	case config.LogShippingSinkSyslog:
+		return newSyslogLogTransport(cfg, serviceName, timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported log shipping sink type: %s", cfg.Type)
+	}
+}
+
+// =========================
+// HTTP based transports
+// =========================
+
+type logShippingHTTP struct {
+	client *http.Client
+	cfg    *config.LogShippingSinkConfig
+}
+
+func (h *logShippingHTTP) post(ctx context.Context, endpoint, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if token := strings.TrimSpace(h.cfg.BearerToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if h.cfg.Username != "" {
+		req.SetBasicAuth(h.cfg.Username, h.cfg.Password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, logShippingMaxResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, truncateString(strings.TrimSpace(string(respBody)), 256))
+	}
+	return respBody, nil
+}
+
+func (h *logShippingHTTP) Close() error { return nil }
+
+// lokiLogTransport 通过 Loki push API（JSON）投递；按级别拆分 stream，避免高基数标签。
+type lokiLogTransport struct {
+	logShippingHTTP
+	labels map[string]string
+}
+
+func (t *lokiLogTransport) Send(ctx context.Context, records []*logShippingRecord) (int, error) {
+	body, err := buildLokiPushBody(records, t.labels)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := t.post(ctx, t.cfg.URL, "application/json", body); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+func buildLokiPushBody(records []*logShippingRecord, labels map[string]string) ([]byte, error) {
+	type lokiStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	byLevel := make(map[string]*lokiStream)
+	levels := make([]string, 0, 4)
+	for _, r := range records {
+		stream := byLevel[r.Level]
+		if stream == nil {
+			streamLabels := make(map[string]string, len(labels)+1)
+			for k, v := range labels {
+				streamLabels[k] = v
+			}
+			streamLabels["level"] = r.Level
+			stream = &lokiStream{Stream: streamLabels}
+			byLevel[r.Level] = stream
+			levels = append(levels, r.Level)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(r.Time.UnixNano(), 10), string(r.marshal())})
+	}
+	sort.Strings(levels)
+	streams := make([]*lokiStream, 0, len(levels))
+	for _, level := range levels {
+		streams = append(streams, byLevel[level])
+	}
+	return json.Marshal(map[string]any{"streams": streams})
+}
+
+// elasticsearchLogTransport 通过 _bulk API 投递（兼容 OpenSearch）。
+type elasticsearchLogTransport struct {
+	logShippingHTTP
+	endpoint string
+	index    string
+}
+
+func (t *elasticsearchLogTransport) Send(ctx context.Context, records []*logShippingRecord) (int, error) {
+	var buf bytes.Buffer
+	for _, r := range records {
+		action, _ := json.Marshal(map[string]any{"index": map[string]string{"_index": elasticsearchIndexName(t.index, r.Time)}})
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(r.marshal())
+		buf.WriteByte('\n')
+	}
+	respBody, err := t.post(ctx, t.endpoint, "application/x-ndjson", buf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	failed, reason := parseElasticsearchBulkFailures(respBody)
+	if failed > 0 {
+		sent := len(records) - failed
+		if sent < 0 {
+			sent = 0
+		}
+		return sent, fmt.Errorf("bulk rejected %d/%d documents: %s", failed, len(records), reason)
+	}
+	return len(records), nil
+}
+
+func elasticsearchBulkURL(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if strings.HasSuffix(raw, "/_bulk") {
+		return raw
+	}
+	return raw + "/_bulk"
+}
+
+func elasticsearchIndexName(pattern string, ts time.Time) string {
+	return strings.ReplaceAll(pattern, "{date}", ts.UTC().Format("2006.01.02"))
+}
+
+// parseElasticsearchBulkFailures 返回 bulk 响应中失败的条数与首个失败原因。
+func parseElasticsearchBulkFailures(body []byte) (int, string) {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || !resp.Errors {
+		return 0, ""
+	}
+	failed := 0
+	reason := ""
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error == nil && result.Status < 300 {
+				continue
+			}
+			failed++
+			if reason == "" && result.Error != nil {
+				reason = truncateString(strings.TrimSpace(result.Error.Type+": "+result.Error.Reason), 256)
+			}
+		}
+	}
+	return failed, reason
+}
+
+// httpJSONLogTransport 以 JSON 数组批量 POST 到任意接收端。
+type httpJSONLogTransport struct {
+	logShippingHTTP
+}
+
+func (t *httpJSONLogTransport) Send(ctx context.Context, records []*logShippingRecord) (int, error) {
+	docs := make([]json.RawMessage, 0, len(records))
+	for _, r := range records {
+		docs = append(docs, r.marshal())
+	}
+	body, err := json.Marshal(docs)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := t.post(ctx, t.cfg.URL, "application/json", body); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+// =========================
+// Syslog (RFC5424)
+// =========================
+
+// syslogLogTransport RFC5424 over TCP（octet-counting 分帧，RFC6587）或 UDP（每条一个数据报）。
+// 连接按需建立，写失败后关闭，下一批次重连。
+type syslogLogTransport struct {
+	network  string
+	address  string
+	facility int
+	appName  string
+	hostname string
+	procID   string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogLogTransport(cfg *config.LogShippingSinkConfig, serviceName string, timeout time.Duration) *syslogLogTransport {
+	network := cfg.Network
+	if network == "" {
+		network = "udp"
+	}
+	facility := cfg.Facility
+	if facility == 0 {
+		facility = logShippingDefaultSyslogFac
+	}
+	appName := strings.TrimSpace(cfg.AppName)
+	if appName == "" {
+		appName = serviceName
+	}
+	hostname, _ := os.Hostname()
+	return &syslogLogTransport{
+		network:  network,
+		address:  cfg.Address,
+		facility: facility,
+		appName:  syslogHeaderField(appName, logShippingSyslogMaxFieldLen),
+		hostname: syslogHeaderField(hostname, 255),
+		procID:   strconv.Itoa(os.Getpid()),
+		timeout:  timeout,
+	}
+}
+
+func (t *syslogLogTransport) Send(ctx context.Context, records []*logShippingRecord) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		dialer := net.Dialer{Timeout: t.timeout}
+		conn, err := dialer.DialContext(ctx, t.network, t.address)
+		if err != nil {
+			return 0, err
+		}
+		t.conn = conn
+	}
+	deadline := time.Now().Add(t.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = t.conn.SetWriteDeadline(deadline)
+
+	for i, r := range records {
+		msg := t.format(r)
+		var payload []byte
+		if t.network == "tcp" {
+			payload = []byte(strconv.Itoa(len(msg)) + " " + msg)
+		} else {
+			if len(msg) > logShippingSyslogMaxUDPBytes {
+				msg = msg[:logShippingSyslogMaxUDPBytes]
+			}
+			payload = []byte(msg)
+		}
+		if _, err := t.conn.Write(payload); err != nil {
+			_ = t.conn.Close()
+			t.conn = nil
+			return i, err
+		}
+	}
+	return len(records), nil
+}
+
+func (t *syslogLogTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// format 生成 RFC5424 报文：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID - MSG（MSG 为 JSON 文档）
+func (t *syslogLogTransport) format(r *logShippingRecord) string {
+	pri := t.facility*8 + syslogSeverity(r.Level)
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s - %s",
+		pri,
+		r.Time.UTC().Format(logShippingSyslogTimestampFmt),
+		t.hostname,
+		t.appName,
+		t.procID,
+		syslogHeaderField(r.Component, logShippingSyslogMaxMsgIDLen),
+		r.marshal(),
+	)
+}
+
+func syslogSeverity(level string) int {
+	switch logShippingLevelRank(level) {
+	case 0:
+		return 7 // debug
+	case 2:
+		return 4 // warning
+	case 3:
+		return 3 // err
+	case 4:
+		return 2 // crit
+	default:
+		return 6 // informational
+	}
+}
+
+// syslogHeaderField 头部字段仅允许 PRINTUSASCII（33-126），为空时返回 NILVALUE "-"
+func syslogHeaderField(v string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range v {
+		if r < 33 || r > 126 {
+			continue
+		}
+		b.WriteRune(r)
+		if b.Len() >= maxLen {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
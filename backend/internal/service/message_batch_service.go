@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+)
+
+// Anthropic Message Batches 处理状态
+const (
+	MessageBatchStatusInProgress = "in_progress"
+	MessageBatchStatusCanceling  = "canceling"
+	MessageBatchStatusEnded      = "ended"
+)
+
+// Anthropic Message Batches 单条结果类型
+const (
+	MessageBatchResultSucceeded = "succeeded"
+	MessageBatchResultErrored   = "errored"
+	MessageBatchResultCanceled  = "canceled"
+	MessageBatchResultExpired   = "expired"
+)
+
+const messageBatchEndpoint = "/v1/messages"
+
+var (
+	ErrMessageBatchNotEnded       = infraerrors.BadRequest("MESSAGE_BATCH_NOT_ENDED", "message batch has not ended yet")
+	ErrMessageBatchResultsMissing = infraerrors.NotFound("MESSAGE_BATCH_RESULTS_NOT_FOUND", "results are not available for this message batch")
+
+	messageBatchCustomIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+// MessageBatchRequest /v1/messages/batches 中的一条请求
+type MessageBatchRequest struct {
+	CustomID string          `json:"custom_id"`
+	Params   json.RawMessage `json:"params"`
+}
+
+// MessageBatchCreateInput /v1/messages/batches 创建参数
+type MessageBatchCreateInput struct {
+	UserID   int64
+	APIKeyID int64
+	GroupID  *int64
+	ClientIP string
+	Requests []MessageBatchRequest
+}
+
+// MessageBatchRequestCounts 对应 Anthropic message_batch.request_counts
+type MessageBatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// messageBatchResultLine Anthropic results JSONL 中的一行
+type messageBatchResultLine struct {
+	CustomID string             `json:"custom_id"`
+	Result   messageBatchResult `json:"result"`
+}
+
+type messageBatchResult struct {
+	Type    string          `json:"type"`
+	Message json.RawMessage `json:"message,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// CreateMessageBatch 将 requests 转换为内部 JSONL 输入文件并创建任务，
+// 由 batch 执行器通过 /v1/messages 在后台逐条执行。
+func (s *BatchService) CreateMessageBatch(ctx context.Context, input *MessageBatchCreateInput) (*BatchJob, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	if input == nil || len(input.Requests) == 0 {
+		return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", "requests: must contain at least one request")
+	}
+	if maxRequests := s.maxRequestsPerBatch(); len(input.Requests) > maxRequests {
+		return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests: must contain at most %d requests", maxRequests))
+	}
+
+	var buf bytes.Buffer
+	seen := make(map[string]struct{}, len(input.Requests))
+	for i := range input.Requests {
+		req := &input.Requests[i]
+		if err := validateMessageBatchRequest(i, req); err != nil {
+			return nil, err
+		}
+		if _, dup := seen[req.CustomID]; dup {
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.custom_id: duplicate custom_id %q", i, req.CustomID))
+		}
+		seen[req.CustomID] = struct{}{}
+
+		encoded, err := json.Marshal(&batchRequestLine{
+			CustomID: req.CustomID,
+			Method:   "POST",
+			URL:      messageBatchEndpoint,
+			Body:     req.Params,
+		})
+		if err != nil {
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.params: invalid JSON", i))
+		}
+		buf.Write(encoded)
+		buf.WriteByte('\n')
+	}
+	if int64(buf.Len()) > s.MaxFileSizeBytes() {
+		return nil, ErrBatchFileTooLarge
+	}
+
+	jobID := "msgbatch_" + randomHex(12)
+	fileID := "file-" + randomHex(12)
+	key := batchFileStorageKey(input.UserID, fileID)
+	size, err := s.store.Put(ctx, key, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("store message batch input: %w", err)
+	}
+	file := &BatchFile{
+		ID:         fileID,
+		UserID:     input.UserID,
+		APIKeyID:   input.APIKeyID,
+		Purpose:    BatchFilePurposeMessageBatch,
+		Filename:   jobID + "_input.jsonl",
+		Bytes:      size,
+		StorageKey: key,
+	}
+	if err := s.repo.CreateFile(ctx, file); err != nil {
+		_ = s.store.Delete(ctx, key)
+		return nil, fmt.Errorf("create message batch input file: %w", err)
+	}
+
+	job := &BatchJob{
+		ID:               jobID,
+		APIFormat:        BatchAPIFormatAnthropic,
+		UserID:           input.UserID,
+		APIKeyID:         input.APIKeyID,
+		GroupID:          input.GroupID,
+		Endpoint:         messageBatchEndpoint,
+		InputFileID:      file.ID,
+		CompletionWindow: BatchCompletionWindow,
+		Status:           BatchStatusValidating,
+		TotalCount:       len(input.Requests),
+		ClientIP:         strings.TrimSpace(input.ClientIP),
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("create message batch: %w", err)
+	}
+	logger.LegacyPrintf("service.batch", "[Batch] message batch created: batch=%s user=%d api_key=%d requests=%d", job.ID, job.UserID, job.APIKeyID, len(input.Requests))
+	go s.runOnce()
+	return job, nil
+}
+
+func (s *BatchService) GetMessageBatch(ctx context.Context, userID int64, batchID string) (*BatchJob, error) {
+	return s.getJob(ctx, userID, batchID, BatchAPIFormatAnthropic)
+}
+
+// ListMessageBatches 按创建时间倒序列出 Message Batches；第二个返回值表示是否还有更多数据。
+func (s *BatchService) ListMessageBatches(ctx context.Context, userID int64, after string, limit int) ([]BatchJob, bool, error) {
+	return s.listJobs(ctx, userID, BatchAPIFormatAnthropic, after, limit)
+}
+
+func (s *BatchService) CancelMessageBatch(ctx context.Context, userID int64, batchID string) (*BatchJob, error) {
+	return s.cancelJob(ctx, userID, batchID, BatchAPIFormatAnthropic)
+}
+
+// OpenMessageBatchResults 打开已结束任务的 results JSONL，调用方负责关闭。
+func (s *BatchService) OpenMessageBatchResults(ctx context.Context, userID int64, batchID string) (*BatchFile, io.ReadCloser, error) {
+	job, err := s.GetMessageBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if MessageBatchProcessingStatus(job) != MessageBatchStatusEnded {
+		return nil, nil, ErrMessageBatchNotEnded
+	}
+	if job.OutputFileID == nil {
+		return nil, nil, ErrMessageBatchResultsMissing
+	}
+	file, err := s.repo.GetFile(ctx, userID, *job.OutputFileID)
+	if err != nil {
+		return nil, nil, ErrMessageBatchResultsMissing
+	}
+	rc, err := s.store.Open(ctx, file.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open message batch results: %w", err)
+	}
+	return file, rc, nil
+}
+
+// MessageBatchProcessingStatus 将内部任务状态映射为 Anthropic processing_status。
+func MessageBatchProcessingStatus(job *BatchJob) string {
+	switch job.Status {
+	case BatchStatusValidating, BatchStatusInProgress, BatchStatusFinalizing:
+		return MessageBatchStatusInProgress
+	case BatchStatusCancelling:
+		return MessageBatchStatusCanceling
+	default:
+		return MessageBatchStatusEnded
+	}
+}
+
+// MessageBatchEndedAt 返回任务结束时间；未结束返回 nil。
+func MessageBatchEndedAt(job *BatchJob) *time.Time {
+	switch job.Status {
+	case BatchStatusCompleted:
+		return job.CompletedAt
+	case BatchStatusFailed:
+		return job.FailedAt
+	case BatchStatusCancelled:
+		return job.CancelledAt
+	case BatchStatusExpired:
+		return job.ExpiredAt
+	}
+	return nil
+}
+
+// MessageBatchCounts 计算 request_counts：未执行的请求按终态计入 canceled/expired/errored。
+func MessageBatchCounts(job *BatchJob) MessageBatchRequestCounts {
+	counts := MessageBatchRequestCounts{Succeeded: job.CompletedCount, Errored: job.FailedCount}
+	remaining := job.TotalCount - job.CompletedCount - job.FailedCount
+	if remaining < 0 {
+		remaining = 0
+	}
+	switch job.Status {
+	case BatchStatusCancelled:
+		counts.Canceled = remaining
+	case BatchStatusExpired:
+		counts.Expired = remaining
+	case BatchStatusFailed:
+		counts.Errored += remaining
+	case BatchStatusCompleted:
+	default:
+		counts.Processing = remaining
+	}
+	return counts
+}
+
+// fillUnprocessedResults 为未执行的请求补齐 canceled/expired/errored 结果行。
+func (s *BatchService) fillUnprocessedResults(job *BatchJob, out *batchResultWriter, status string) {
+	result := messageBatchResult{Type: MessageBatchResultErrored, Error: messageBatchErrorBody("api_error", batchInterruptedMessage)}
+	switch status {
+	case BatchStatusCancelled:
+		result = messageBatchResult{Type: MessageBatchResultCanceled}
+	case BatchStatusExpired:
+		result = messageBatchResult{Type: MessageBatchResultExpired}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	err := s.scanInput(ctx, job, func(_ int, line *batchRequestLine) error {
+		out.writeUnprocessed(&messageBatchResultLine{CustomID: line.CustomID, Result: result})
+		return nil
+	})
+	if err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] fill unprocessed results failed: batch=%s err=%v", job.ID, err)
+	}
+}
+
+// newMessageBatchResult 将网关响应转换为 Anthropic results 行。
+func newMessageBatchResult(line *batchResultLine, succeeded bool) *messageBatchResultLine {
+	out := &messageBatchResultLine{CustomID: line.CustomID}
+	switch {
+	case succeeded:
+		out.Result = messageBatchResult{Type: MessageBatchResultSucceeded, Message: line.Response.Body}
+	case line.Error != nil:
+		out.Result = messageBatchResult{Type: MessageBatchResultErrored, Error: messageBatchErrorBody("api_error", line.Error.Message)}
+	default:
+		body := []byte(line.Response.Body)
+		if gjson.GetBytes(body, "type").String() == "error" && gjson.GetBytes(body, "error").IsObject() {
+			out.Result = messageBatchResult{Type: MessageBatchResultErrored, Error: line.Response.Body}
+			break
+		}
+		message := gjson.GetBytes(body, "error.message").String()
+		if message == "" {
+			message = gjson.ParseBytes(body).String()
+		}
+		if message == "" {
+			message = fmt.Sprintf("upstream request failed with status %d", line.Response.StatusCode)
+		}
+		out.Result = messageBatchResult{Type: MessageBatchResultErrored, Error: messageBatchErrorBody("api_error", message)}
+	}
+	return out
+}
+
+func messageBatchErrorBody(errType, message string) json.RawMessage {
+	encoded, _ := json.Marshal(map[string]any{
+		"type": "error",
+		"error": map[string]string{
+			"type":    errType,
+			"message": message,
+		},
+	})
+	return encoded
+}
+
+func validateMessageBatchRequest(idx int, req *MessageBatchRequest) error {
+	if !messageBatchCustomIDPattern.MatchString(req.CustomID) {
+		return infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.custom_id: must be 1-64 characters of letters, digits, '_' or '-'", idx))
+	}
+	params := []byte(req.Params)
+	if !gjson.ValidBytes(params) || !gjson.ParseBytes(params).IsObject() {
+		return infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.params: must be an object", idx))
+	}
+	if gjson.GetBytes(params, "model").String() == "" {
+		return infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.params.model: field required", idx))
+	}
+	if !gjson.GetBytes(params, "max_tokens").Exists() {
+		return infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.params.max_tokens: field required", idx))
+	}
+	if !gjson.GetBytes(params, "messages").IsArray() {
+		return infraerrors.BadRequest("MESSAGE_BATCH_INVALID_REQUEST", fmt.Sprintf("requests.%d.params.messages: field required", idx))
+	}
+	return nil
+}
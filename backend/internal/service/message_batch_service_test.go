@@ -0,0 +1,197 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func createMessageBatchForTest(t *testing.T, svc *BatchService, customIDs ...string) *BatchJob {
+	t.Helper()
+	requests := make([]MessageBatchRequest, 0, len(customIDs))
+	for _, id := range customIDs {
+		requests = append(requests, MessageBatchRequest{
+			CustomID: id,
+			Params:   json.RawMessage(`{"model":"claude-sonnet-4-5","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`),
+		})
+	}
+	groupID := int64(3)
+	job, err := svc.CreateMessageBatch(context.Background(), &MessageBatchCreateInput{
+		UserID:   1,
+		APIKeyID: 7,
+		GroupID:  &groupID,
+		Requests: requests,
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func readMessageBatchResults(t *testing.T, svc *BatchService, batchID string) map[string]gjson.Result {
+	t.Helper()
+	_, rc, err := svc.OpenMessageBatchResults(context.Background(), 1, batchID)
+	require.NoError(t, err)
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	results := make(map[string]gjson.Result)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		results[gjson.Get(line, "custom_id").String()] = gjson.Get(line, "result")
+	}
+	return results
+}
+
+func TestBatchService_MessageBatch_ExecutesAndWritesAnthropicResults(t *testing.T) {
+	svc, repo, _ := newBatchServiceForTest(t)
+	svc.cfg.Batch.MessageBatchDiscountMultiplier = 0.4
+	prevBackoff := batchRetryBackoff
+	batchRetryBackoff = time.Millisecond
+	t.Cleanup(func() { batchRetryBackoff = prevBackoff })
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	svc.SetRequestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "2023-06-01", r.Header.Get("anthropic-version"))
+		info := BatchBillingFromContext(r.Context())
+		require.NotNil(t, info)
+		require.InDelta(t, 0.4, info.Multiplier, 1e-12)
+
+		mu.Lock()
+		attempts[info.CustomID]++
+		n := attempts[info.CustomID]
+		mu.Unlock()
+
+		switch {
+		case info.CustomID == "busy" && n == 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"busy"}}`))
+		case info.CustomID == "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"bad prompt"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[]}`))
+		}
+	}))
+
+	job := createMessageBatchForTest(t, svc, "ok", "busy", "bad")
+	require.True(t, strings.HasPrefix(job.ID, "msgbatch_"))
+	require.Equal(t, BatchAPIFormatAnthropic, job.APIFormat)
+	job.ExpiresAt = time.Now().Add(time.Hour)
+
+	svc.executeJob(job)
+
+	result := repo.finished[job.ID]
+	require.NotNil(t, result)
+	require.Equal(t, BatchStatusCompleted, result.Status)
+	require.Equal(t, 2, result.CompletedCount)
+	require.Equal(t, 1, result.FailedCount)
+	require.Nil(t, result.ErrorFileID)
+	require.Equal(t, 2, attempts["busy"], "429 responses must be retried")
+	require.Equal(t, 1, attempts["bad"])
+
+	results := readMessageBatchResults(t, svc, job.ID)
+	require.Len(t, results, 3)
+	require.Equal(t, MessageBatchResultSucceeded, results["ok"].Get("type").String())
+	require.Equal(t, "msg_1", results["ok"].Get("message.id").String())
+	require.Equal(t, MessageBatchResultSucceeded, results["busy"].Get("type").String())
+	require.Equal(t, MessageBatchResultErrored, results["bad"].Get("type").String())
+	require.Equal(t, "invalid_request_error", results["bad"].Get("error.error.type").String())
+
+	// Message Batch 的内部文件不能通过 /v1/files 访问
+	_, err := svc.GetFile(context.Background(), 1, *result.OutputFileID)
+	require.ErrorIs(t, err, ErrBatchFileNotFound)
+	// 两套接口互不可见
+	_, err = svc.GetBatch(context.Background(), 1, job.ID)
+	require.ErrorIs(t, err, ErrBatchJobNotFound)
+}
+
+func TestBatchService_MessageBatch_FillsCanceledResults(t *testing.T) {
+	svc, _, _ := newBatchServiceForTest(t)
+	job := createMessageBatchForTest(t, svc, "a", "b", "c")
+
+	out, err := newBatchResultWriter(job)
+	require.NoError(t, err)
+	defer out.cleanup()
+	out.write(&batchResultLine{CustomID: "a", Response: &batchResultResponse{StatusCode: http.StatusOK, Body: json.RawMessage(`{"id":"msg_a"}`)}})
+
+	svc.fillUnprocessedResults(job, out, BatchStatusCancelled)
+	require.NoError(t, out.flush())
+	require.Equal(t, 3, out.written())
+
+	data, err := io.ReadAll(mustSeekStart(t, out))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, MessageBatchResultSucceeded, gjson.Get(lines[0], "result.type").String())
+	require.Equal(t, "b", gjson.Get(lines[1], "custom_id").String())
+	require.Equal(t, MessageBatchResultCanceled, gjson.Get(lines[1], "result.type").String())
+	require.Equal(t, MessageBatchResultCanceled, gjson.Get(lines[2], "result.type").String())
+}
+
+func mustSeekStart(t *testing.T, out *batchResultWriter) io.Reader {
+	t.Helper()
+	_, err := out.outFile.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	return out.outFile
+}
+
+func TestBatchService_CreateMessageBatch_Validation(t *testing.T) {
+	svc, _, _ := newBatchServiceForTest(t)
+	ctx := context.Background()
+	params := json.RawMessage(`{"model":"claude-sonnet-4-5","max_tokens":16,"messages":[]}`)
+
+	cases := []struct {
+		name     string
+		requests []MessageBatchRequest
+	}{
+		{name: "empty", requests: nil},
+		{name: "invalid_custom_id", requests: []MessageBatchRequest{{CustomID: "has space", Params: params}}},
+		{name: "duplicate_custom_id", requests: []MessageBatchRequest{{CustomID: "a", Params: params}, {CustomID: "a", Params: params}}},
+		{name: "missing_max_tokens", requests: []MessageBatchRequest{{CustomID: "a", Params: json.RawMessage(`{"model":"m","messages":[]}`)}}},
+		{name: "params_not_object", requests: []MessageBatchRequest{{CustomID: "a", Params: json.RawMessage(`[]`)}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.CreateMessageBatch(ctx, &MessageBatchCreateInput{UserID: 1, APIKeyID: 7, Requests: tc.requests})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMessageBatchCounts(t *testing.T) {
+	job := &BatchJob{Status: BatchStatusInProgress, TotalCount: 10, CompletedCount: 3, FailedCount: 1}
+	require.Equal(t, MessageBatchRequestCounts{Processing: 6, Succeeded: 3, Errored: 1}, MessageBatchCounts(job))
+	require.Equal(t, MessageBatchStatusInProgress, MessageBatchProcessingStatus(job))
+
+	job.Status = BatchStatusCancelled
+	require.Equal(t, MessageBatchRequestCounts{Succeeded: 3, Errored: 1, Canceled: 6}, MessageBatchCounts(job))
+	require.Equal(t, MessageBatchStatusEnded, MessageBatchProcessingStatus(job))
+
+	job.Status = BatchStatusExpired
+	require.Equal(t, MessageBatchRequestCounts{Succeeded: 3, Errored: 1, Expired: 6}, MessageBatchCounts(job))
+
+	job.Status = BatchStatusCancelling
+	require.Equal(t, MessageBatchStatusCanceling, MessageBatchProcessingStatus(job))
+}
+
+func TestTagUsageLogWithBatch(t *testing.T) {
+	usageLog := &UsageLog{}
+	tagUsageLogWithBatch(context.Background(), usageLog)
+	require.Nil(t, usageLog.BatchID)
+
+	ctx := WithBatchBilling(context.Background(), &BatchBillingInfo{BatchID: "msgbatch_1", CustomID: "req-1", Multiplier: 1})
+	tagUsageLogWithBatch(ctx, usageLog)
+	require.Equal(t, "msgbatch_1", *usageLog.BatchID)
+	require.Equal(t, "req-1", *usageLog.BatchCustomID)
+	require.InDelta(t, 1.0, *usageLog.BatchMultiplier, 1e-12)
+}
@@ -10,211 +10,37 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 )
 
 const (
 	openAIAccountScheduleLayerPreviousResponse = "previous_response_id"
-	openAIAccountScheduleLayerSessionSticky    = "session_hash"
-	openAIAccountScheduleLayerLoadBalance      = "load_balance"
-)
-
-type OpenAIAccountScheduleRequest struct {
-	GroupID            *int64
-	SessionHash        string
-	StickyAccountID    int64
-	PreviousResponseID string
-	RequestedModel     string
-	RequiredTransport  OpenAIUpstreamTransport
-	ExcludedIDs        map[int64]struct{}
-}
-
-type OpenAIAccountScheduleDecision struct {
-	Layer               string
-	StickyPreviousHit   bool
-	StickySessionHit    bool
-	CandidateCount      int
-	TopK                int
-	LatencyMs           int64
-	LoadSkew            float64
-	SelectedAccountID   int64
-	SelectedAccountType string
-}
-
-type OpenAIAccountSchedulerMetricsSnapshot struct {
-	SelectTotal              int64
-	StickyPreviousHitTotal   int64
-	StickySessionHitTotal    int64
-	LoadBalanceSelectTotal   int64
-	AccountSwitchTotal       int64
-	SchedulerLatencyMsTotal  int64
-	SchedulerLatencyMsAvg    float64
-	StickyHitRatio           float64
-	AccountSwitchRate        float64
-	LoadSkewAvg              float64
-	RuntimeStatsAccountCount int
-}
-
-type OpenAIAccountScheduler interface {
-	Select(ctx context.Context, req OpenAIAccountScheduleRequest) (*AccountSelectionResult, OpenAIAccountScheduleDecision, error)
-	ReportResult(accountID int64, success bool, firstTokenMs *int)
-	ReportSwitch()
-	SnapshotMetrics() OpenAIAccountSchedulerMetricsSnapshot
-}
-
-type openAIAccountSchedulerMetrics struct {
-	selectTotal            atomic.Int64
-	stickyPreviousHitTotal atomic.Int64
-	stickySessionHitTotal  atomic.Int64
-	loadBalanceSelectTotal atomic.Int64
-	accountSwitchTotal     atomic.Int64
-	latencyMsTotal         atomic.Int64
-	loadSkewMilliTotal     atomic.Int64
-}
-
-func (m *openAIAccountSchedulerMetrics) recordSelect(decision OpenAIAccountScheduleDecision) {
-	if m == nil {
-		return
-	}
-	m.selectTotal.Add(1)
-	m.latencyMsTotal.Add(decision.LatencyMs)
-	m.loadSkewMilliTotal.Add(int64(math.Round(decision.LoadSkew * 1000)))
-	if decision.StickyPreviousHit {
-		m.stickyPreviousHitTotal.Add(1)
-	}
-	if decision.StickySessionHit {
-		m.stickySessionHitTotal.Add(1)
-	}
-	if decision.Layer == openAIAccountScheduleLayerLoadBalance {
-		m.loadBalanceSelectTotal.Add(1)
-	}
-}
-
-func (m *openAIAccountSchedulerMetrics) recordSwitch() {
-	if m == nil {
-		return
-	}
-	m.accountSwitchTotal.Add(1)
-}
-
-type openAIAccountRuntimeStats struct {
-	accounts     sync.Map
-	accountCount atomic.Int64
-}
-
-type openAIAccountRuntimeStat struct {
-	errorRateEWMABits atomic.Uint64
-	ttftEWMABits      atomic.Uint64
-}
-
-func newOpenAIAccountRuntimeStats() *openAIAccountRuntimeStats {
-	return &openAIAccountRuntimeStats{}
-}
+	openAIAccountScheduleLayerSessionSticky    = accountScheduleLayerSessionSticky
+	openAIAccountScheduleLayerLoadBalance      = accountScheduleLayerLoadBalance
 
-func (s *openAIAccountRuntimeStats) loadOrCreate(accountID int64) *openAIAccountRuntimeStat {
-	if value, ok := s.accounts.Load(accountID); ok {
-		stat, _ := value.(*openAIAccountRuntimeStat)
-		if stat != nil {
-			return stat
-		}
-	}
-
-	stat := &openAIAccountRuntimeStat{}
-	stat.ttftEWMABits.Store(math.Float64bits(math.NaN()))
-	actual, loaded := s.accounts.LoadOrStore(accountID, stat)
-	if !loaded {
-		s.accountCount.Add(1)
-		return stat
-	}
-	existing, _ := actual.(*openAIAccountRuntimeStat)
-	if existing != nil {
-		return existing
-	}
-	return stat
-}
-
-func updateEWMAAtomic(target *atomic.Uint64, sample float64, alpha float64) {
-	for {
-		oldBits := target.Load()
-		oldValue := math.Float64frombits(oldBits)
-		newValue := alpha*sample + (1-alpha)*oldValue
-		if target.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
-			return
-		}
-	}
-}
-
-func (s *openAIAccountRuntimeStats) report(accountID int64, success bool, firstTokenMs *int) {
-	if s == nil || accountID <= 0 {
-		return
-	}
-	const alpha = 0.2
-	stat := s.loadOrCreate(accountID)
-
-	errorSample := 1.0
-	if success {
-		errorSample = 0.0
-	}
-	updateEWMAAtomic(&stat.errorRateEWMABits, errorSample, alpha)
-
-	if firstTokenMs != nil && *firstTokenMs > 0 {
-		ttft := float64(*firstTokenMs)
-		ttftBits := math.Float64bits(ttft)
-		for {
-			oldBits := stat.ttftEWMABits.Load()
-			oldValue := math.Float64frombits(oldBits)
-			if math.IsNaN(oldValue) {
-				if stat.ttftEWMABits.CompareAndSwap(oldBits, ttftBits) {
-					break
-				}
-				continue
-			}
-			newValue := alpha*ttft + (1-alpha)*oldValue
-			if stat.ttftEWMABits.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
-				break
-			}
-		}
-	}
-}
-
-func (s *openAIAccountRuntimeStats) snapshot(accountID int64) (errorRate float64, ttft float64, hasTTFT bool) {
-	if s == nil || accountID <= 0 {
-		return 0, 0, false
-	}
-	value, ok := s.accounts.Load(accountID)
-	if !ok {
-		return 0, 0, false
-	}
-	stat, _ := value.(*openAIAccountRuntimeStat)
-	if stat == nil {
-		return 0, 0, false
-	}
-	errorRate = clamp01(math.Float64frombits(stat.errorRateEWMABits.Load()))
-	ttftValue := math.Float64frombits(stat.ttftEWMABits.Load())
-	if math.IsNaN(ttftValue) {
-		return errorRate, 0, false
-	}
-	return errorRate, ttftValue, true
-}
+	// openAIAccountScheduleStrategyWeightedScore OpenAI 网关默认策略：按优先级/负载/排队/错误率/TTFT 综合评分后 Top-K 加权随机
+	openAIAccountScheduleStrategyWeightedScore = "weighted_score"
+)
 
-func (s *openAIAccountRuntimeStats) size() int {
-	if s == nil {
-		return 0
-	}
-	return int(s.accountCount.Load())
-}
+// OpenAI 网关沿用通用调度器类型，保留原有名称以兼容既有调用方。
+type (
+	OpenAIAccountScheduleRequest          = AccountScheduleRequest
+	OpenAIAccountScheduleDecision         = AccountScheduleDecision
+	OpenAIAccountSchedulerMetricsSnapshot = AccountSchedulerMetricsSnapshot
+	OpenAIAccountScheduler                = AccountScheduler
+)
 
 type defaultOpenAIAccountScheduler struct {
 	service *OpenAIGatewayService
-	metrics openAIAccountSchedulerMetrics
-	stats   *openAIAccountRuntimeStats
+	metrics accountSchedulerMetrics
+	stats   *accountRuntimeStats
 }
 
-func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *openAIAccountRuntimeStats) OpenAIAccountScheduler {
+func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *accountRuntimeStats) OpenAIAccountScheduler {
 	if stats == nil {
-		stats = newOpenAIAccountRuntimeStats()
+		stats = newAccountRuntimeStats()
 	}
 	return &defaultOpenAIAccountScheduler{
 		service: service,
@@ -225,12 +51,13 @@ func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *open
 func (s *defaultOpenAIAccountScheduler) Select(
 	ctx context.Context,
 	req OpenAIAccountScheduleRequest,
-) (*AccountSelectionResult, OpenAIAccountScheduleDecision, error) {
-	decision := OpenAIAccountScheduleDecision{}
+) (selection *AccountSelectionResult, decision OpenAIAccountScheduleDecision, err error) {
+	ctx, span := startAccountSelectSpan(ctx, "scheduler.select", req.GroupID, req.RequestedModel, len(req.ExcludedIDs))
 	start := time.Now()
 	defer func() {
 		decision.LatencyMs = time.Since(start).Milliseconds()
 		s.metrics.recordSelect(decision)
+		endAccountSelectSpan(span, decision, err)
 	}()
 
 	previousResponseID := strings.TrimSpace(req.PreviousResponseID)
@@ -262,7 +89,7 @@ func (s *defaultOpenAIAccountScheduler) Select(
 		}
 	}
 
-	selection, err := s.selectBySessionHash(ctx, req)
+	selection, err = s.selectBySessionHash(ctx, req)
 	if err != nil {
 		return nil, decision, err
 	}
@@ -274,7 +101,7 @@ func (s *defaultOpenAIAccountScheduler) Select(
 		return selection, decision, nil
 	}
 
-	selection, candidateCount, topK, loadSkew, err := s.selectByLoadBalance(ctx, req)
+	selection, candidateCount, topK, loadSkew, err := s.selectByLoadBalance(ctx, req, &decision)
 	decision.Layer = openAIAccountScheduleLayerLoadBalance
 	decision.CandidateCount = candidateCount
 	decision.TopK = topK
@@ -567,6 +394,7 @@ func buildOpenAIWeightedSelectionOrder(
 func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 	ctx context.Context,
 	req OpenAIAccountScheduleRequest,
+	decision *OpenAIAccountScheduleDecision,
 ) (*AccountSelectionResult, int, int, float64, error) {
 	accounts, err := s.service.listSchedulableAccounts(ctx, req.GroupID)
 	if err != nil {
@@ -693,15 +521,39 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 			weights.TTFT*ttftFactor
 	}
 
-	topK := s.service.openAIWSLBTopK()
-	if topK > len(candidates) {
-		topK = len(candidates)
-	}
-	if topK <= 0 {
-		topK = 1
+	strategy := resolveAccountScheduleStrategy(s.scheduleGroup(ctx, req.GroupID, schedGroup), openAIAccountScheduleStrategyWeightedScore)
+	decision.Strategy = strategy
+
+	var selectionOrder []openAIAccountCandidateScore
+	topK := len(candidates)
+	if strategy == openAIAccountScheduleStrategyWeightedScore {
+		topK = s.service.openAIWSLBTopK()
+		if topK > len(candidates) {
+			topK = len(candidates)
+		}
+		if topK <= 0 {
+			topK = 1
+		}
+		rankedCandidates := selectTopKOpenAICandidates(candidates, topK)
+		selectionOrder = buildOpenAIWeightedSelectionOrder(rankedCandidates, req)
+	} else {
+		// 分组显式配置了调度策略：按策略对全部候选排序，不再使用综合评分
+		ordered := make([]accountScheduleCandidate, 0, len(candidates))
+		for _, item := range candidates {
+			ordered = append(ordered, accountScheduleCandidate{
+				account:   item.account,
+				loadInfo:  item.loadInfo,
+				errorRate: item.errorRate,
+				ttft:      item.ttft,
+				hasTTFT:   item.hasTTFT,
+			})
+		}
+		orderAccountScheduleCandidates(strategy, ordered)
+		selectionOrder = make([]openAIAccountCandidateScore, 0, len(ordered))
+		for _, item := range ordered {
+			selectionOrder = append(selectionOrder, openAIAccountCandidateScore{account: item.account, loadInfo: item.loadInfo})
+		}
 	}
-	rankedCandidates := selectTopKOpenAICandidates(candidates, topK)
-	selectionOrder := buildOpenAIWeightedSelectionOrder(rankedCandidates, req)
 
 	for i := 0; i < len(selectionOrder); i++ {
 		candidate := selectionOrder[i]
@@ -750,6 +602,17 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 	return nil, len(candidates), topK, loadSkew, ErrNoAvailableAccounts
 }
 
+// scheduleGroup 返回调度所属分组（优先使用调度快照，其次使用请求上下文中的分组）
+func (s *defaultOpenAIAccountScheduler) scheduleGroup(ctx context.Context, groupID *int64, schedGroup *Group) *Group {
+	if schedGroup != nil || groupID == nil {
+		return schedGroup
+	}
+	if group, ok := ctx.Value(ctxkey.Group).(*Group); ok && IsGroupContextValid(group) && group.ID == *groupID {
+		return group
+	}
+	return nil
+}
+
 func (s *defaultOpenAIAccountScheduler) isAccountTransportCompatible(account *Account, requiredTransport OpenAIUpstreamTransport) bool {
 	// HTTP 入站可回退到 HTTP 线路，不需要在账号选择阶段做传输协议强过滤。
 	if requiredTransport == OpenAIUpstreamTransportAny || requiredTransport == OpenAIUpstreamTransportHTTPSSE {
@@ -779,30 +642,7 @@ func (s *defaultOpenAIAccountScheduler) SnapshotMetrics() OpenAIAccountScheduler
 	if s == nil {
 		return OpenAIAccountSchedulerMetricsSnapshot{}
 	}
-
-	selectTotal := s.metrics.selectTotal.Load()
-	prevHit := s.metrics.stickyPreviousHitTotal.Load()
-	sessionHit := s.metrics.stickySessionHitTotal.Load()
-	switchTotal := s.metrics.accountSwitchTotal.Load()
-	latencyTotal := s.metrics.latencyMsTotal.Load()
-	loadSkewTotal := s.metrics.loadSkewMilliTotal.Load()
-
-	snapshot := OpenAIAccountSchedulerMetricsSnapshot{
-		SelectTotal:              selectTotal,
-		StickyPreviousHitTotal:   prevHit,
-		StickySessionHitTotal:    sessionHit,
-		LoadBalanceSelectTotal:   s.metrics.loadBalanceSelectTotal.Load(),
-		AccountSwitchTotal:       switchTotal,
-		SchedulerLatencyMsTotal:  latencyTotal,
-		RuntimeStatsAccountCount: s.stats.size(),
-	}
-	if selectTotal > 0 {
-		snapshot.SchedulerLatencyMsAvg = float64(latencyTotal) / float64(selectTotal)
-		snapshot.StickyHitRatio = float64(prevHit+sessionHit) / float64(selectTotal)
-		snapshot.AccountSwitchRate = float64(switchTotal) / float64(selectTotal)
-		snapshot.LoadSkewAvg = float64(loadSkewTotal) / 1000 / float64(selectTotal)
-	}
-	return snapshot
+	return s.metrics.snapshot(s.stats)
 }
 
 func (s *OpenAIGatewayService) getOpenAIAccountScheduler() OpenAIAccountScheduler {
@@ -811,7 +651,7 @@ func (s *OpenAIGatewayService) getOpenAIAccountScheduler() OpenAIAccountSchedule
 	}
 	s.openaiSchedulerOnce.Do(func() {
 		if s.openaiAccountStats == nil {
-			s.openaiAccountStats = newOpenAIAccountRuntimeStats()
+			s.openaiAccountStats = newAccountRuntimeStats()
 		}
 		if s.openaiScheduler == nil {
 			s.openaiScheduler = newDefaultOpenAIAccountScheduler(s, s.openaiAccountStats)
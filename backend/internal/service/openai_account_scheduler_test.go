@@ -591,7 +591,7 @@ func intPtrForTest(v int) *int {
 }
 
 func TestOpenAIAccountRuntimeStats_ReportAndSnapshot(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := newAccountRuntimeStats()
 	stats.report(1001, true, nil)
 	firstTTFT := 100
 	stats.report(1001, false, &firstTTFT)
@@ -606,7 +606,7 @@ func TestOpenAIAccountRuntimeStats_ReportAndSnapshot(t *testing.T) {
 }
 
 func TestOpenAIAccountRuntimeStats_ReportConcurrent(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := newAccountRuntimeStats()
 
 	const (
 		accountCount = 4
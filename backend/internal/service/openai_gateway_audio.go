@@ -0,0 +1,545 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// Audio API 端点
+const (
+	AudioEndpointTranscriptions = "transcriptions"
+	AudioEndpointTranslations   = "translations"
+	AudioEndpointSpeech         = "speech"
+)
+
+// openaiPlatformAudioURL OpenAI Platform audio 端点前缀（API Key 账号未配置 base_url 时使用）
+const openaiPlatformAudioURL = "https://api.openai.com/v1/audio/"
+
+// maxAudioFormFieldBytes 音频 multipart 中单个文本字段的最大字节数（prompt 等）
+const maxAudioFormFieldBytes = 64 << 10
+
+// audioSubtitleTimestampPattern 匹配 srt / vtt 字幕时间轴的结束时间
+var audioSubtitleTimestampPattern = regexp.MustCompile(`-->\s*(?:(\d+):)?(\d{1,2}):(\d{2})[.,](\d{1,3})`)
+
+// AudioFormField 音频 multipart 请求中的文本字段
+type AudioFormField struct {
+	Name  string
+	Value string
+}
+
+// AudioFilePart 已落盘的音频文件字段
+type AudioFilePart struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Path        string
+	Size        int64
+}
+
+// AudioUpload 已落盘的转写/翻译请求：文本字段保留在内存，音频文件流式写入临时文件，
+// failover 时可重复构造上游请求体，且不会把整个音频读入内存。
+type AudioUpload struct {
+	Fields []AudioFormField
+	File   *AudioFilePart
+}
+
+// SpoolAudioUpload 流式解析 multipart 请求，音频文件写入临时文件。
+// 调用方负责在请求结束后调用 Close 删除临时文件。
+func SpoolAudioUpload(body io.Reader, contentType string) (*AudioUpload, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, errors.New("content type must be multipart/form-data")
+	}
+
+	upload := &AudioUpload{}
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			upload.Close()
+			return nil, fmt.Errorf("read multipart: %w", err)
+		}
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxAudioFormFieldBytes+1))
+			if err != nil {
+				upload.Close()
+				return nil, fmt.Errorf("read field %s: %w", part.FormName(), err)
+			}
+			if len(value) > maxAudioFormFieldBytes {
+				upload.Close()
+				return nil, fmt.Errorf("field %s is too large", part.FormName())
+			}
+			upload.Fields = append(upload.Fields, AudioFormField{Name: part.FormName(), Value: string(value)})
+			continue
+		}
+		if upload.File != nil {
+			upload.Close()
+			return nil, errors.New("only one file is allowed")
+		}
+		file, err := spoolAudioFilePart(part)
+		if err != nil {
+			upload.Close()
+			return nil, err
+		}
+		upload.File = file
+	}
+	return upload, nil
+}
+
+func spoolAudioFilePart(part *multipart.Part) (*AudioFilePart, error) {
+	tmp, err := os.CreateTemp("", "audio-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	size, copyErr := io.Copy(tmp, part)
+	closeErr := tmp.Close()
+	if copyErr != nil || closeErr != nil {
+		_ = os.Remove(tmp.Name())
+		if copyErr != nil {
+			return nil, fmt.Errorf("read file: %w", copyErr)
+		}
+		return nil, fmt.Errorf("write temp file: %w", closeErr)
+	}
+	return &AudioFilePart{
+		FieldName:   part.FormName(),
+		FileName:    part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+		Path:        tmp.Name(),
+		Size:        size,
+	}, nil
+}
+
+// Field 返回第一个同名文本字段的值
+func (u *AudioUpload) Field(name string) string {
+	for _, f := range u.Fields {
+		if f.Name == name {
+			return strings.TrimSpace(f.Value)
+		}
+	}
+	return ""
+}
+
+// Close 删除临时文件
+func (u *AudioUpload) Close() {
+	if u != nil && u.File != nil {
+		_ = os.Remove(u.File.Path)
+	}
+}
+
+// upstreamBody 以 io.Pipe 流式构造上游 multipart 请求体；overrides 中的字段替换原值（空值表示删除）。
+func (u *AudioUpload) upstreamBody(overrides map[string]string) (io.ReadCloser, string) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(u.writeMultipart(writer, overrides))
+	}()
+	return pr, writer.FormDataContentType()
+}
+
+func (u *AudioUpload) writeMultipart(writer *multipart.Writer, overrides map[string]string) error {
+	written := make(map[string]bool, len(overrides))
+	for _, f := range u.Fields {
+		value := f.Value
+		if override, ok := overrides[f.Name]; ok {
+			if written[f.Name] || override == "" {
+				continue
+			}
+			written[f.Name] = true
+			value = override
+		}
+		if err := writer.WriteField(f.Name, value); err != nil {
+			return err
+		}
+	}
+	for name, value := range overrides {
+		if !written[name] && value != "" {
+			if err := writer.WriteField(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	if u.File != nil {
+		header := make(map[string][]string)
+		header["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name=%q; filename=%q`, u.File.FieldName, u.File.FileName)}
+		contentType := u.File.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header["Content-Type"] = []string{contentType}
+		pw, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(u.File.Path)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(pw, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// ForwardAudioTranscription 转发 /v1/audio/transcriptions 与 /v1/audio/translations。
+// 计费优先使用上游 usage（gpt-4o 系列返回 token，whisper-1 返回时长）；
+// response_format=text 时向上游请求 json 以取得 usage，再转换为纯文本返回。
+func (s *OpenAIGatewayService) ForwardAudioTranscription(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	endpoint string,
+	upload *AudioUpload,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	originalModel := upload.Field("model")
+	upstreamModel := resolveAudioUpstreamModel(account, originalModel)
+	responseFormat := strings.ToLower(upload.Field("response_format"))
+	stream := strings.EqualFold(upload.Field("stream"), "true")
+
+	overrides := map[string]string{}
+	if upstreamModel != originalModel {
+		overrides["model"] = upstreamModel
+	}
+	if responseFormat == "text" && !stream {
+		overrides["response_format"] = "json"
+	}
+
+	resp, err := s.doAudioUpstreamRequest(ctx, c, account, endpoint, func() (io.ReadCloser, string) {
+		return upload.upstreamBody(overrides)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	result := &OpenAIForwardResult{
+		RequestID:     resp.Header.Get("x-request-id"),
+		Model:         originalModel,
+		UpstreamModel: upstreamModel,
+		Stream:        stream,
+	}
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+
+	if stream {
+		usage, firstTokenMs, err := s.streamAudioResponse(c, resp, startTime)
+		result.Usage = usage
+		result.FirstTokenMs = firstTokenMs
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+	if err != nil {
+		if errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+			setOpsUpstreamError(c, http.StatusBadGateway, "upstream response too large", "")
+			writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Upstream response too large")
+		}
+		return nil, err
+	}
+
+	switch responseFormat {
+	case "srt", "vtt":
+		result.AudioDurationSeconds = extractSubtitleDurationSeconds(respBody)
+		c.Data(http.StatusOK, resp.Header.Get("Content-Type"), respBody)
+	case "text":
+		result.Usage, result.AudioDurationSeconds = extractAudioTranscriptionUsage(respBody)
+		if gjson.ValidBytes(respBody) {
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(gjson.GetBytes(respBody, "text").String()+"\n"))
+		} else {
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", respBody)
+		}
+	default:
+		result.Usage, result.AudioDurationSeconds = extractAudioTranscriptionUsage(respBody)
+		c.Data(http.StatusOK, "application/json", respBody)
+	}
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// ForwardAudioSpeech 转发 /v1/audio/speech（TTS），音频响应边读边写给客户端。
+// 按输入字符数计费；stream_format=sse 时上游返回的 token usage 优先。
+func (s *OpenAIGatewayService) ForwardAudioSpeech(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	originalModel := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+	upstreamModel := resolveAudioUpstreamModel(account, originalModel)
+	if upstreamModel != originalModel {
+		if updated, err := sjson.SetBytes(body, "model", upstreamModel); err == nil {
+			body = updated
+		}
+	}
+
+	resp, err := s.doAudioUpstreamRequest(ctx, c, account, AudioEndpointSpeech, func() (io.ReadCloser, string) {
+		return io.NopCloser(bytes.NewReader(body)), "application/json"
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	usage, firstTokenMs, err := s.streamAudioResponse(c, resp, startTime)
+	return &OpenAIForwardResult{
+		RequestID:       resp.Header.Get("x-request-id"),
+		Usage:           usage,
+		Model:           originalModel,
+		UpstreamModel:   upstreamModel,
+		Stream:          true,
+		Duration:        time.Since(startTime),
+		FirstTokenMs:    firstTokenMs,
+		AudioCharacters: utf8.RuneCountInString(gjson.GetBytes(body, "input").String()),
+	}, err
+}
+
+func resolveAudioUpstreamModel(account *Account, model string) string {
+	if mapped, matched := account.ResolveMappedModel(model); matched && mapped != "" {
+		return mapped
+	}
+	return model
+}
+
+// doAudioUpstreamRequest 发送 audio 上游请求；返回状态码 < 400 的响应，错误响应按 failover 规则处理。
+// newBody 每次调用构造新的请求体（multipart 请求体为一次性 pipe）。
+func (s *OpenAIGatewayService) doAudioUpstreamRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	endpoint string,
+	newBody func() (io.ReadCloser, string),
+) (*http.Response, error) {
+	// ChatGPT OAuth 账号只暴露 Codex Responses 接口，只有 API Key 账号可以调用 audio。
+	if !account.IsOpenAIApiKey() {
+		return nil, &UpstreamFailoverError{
+			StatusCode:   http.StatusBadGateway,
+			ResponseBody: []byte(`{"error":{"type":"upstream_error","message":"account does not support audio"}}`),
+		}
+	}
+
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	targetURL := openaiPlatformAudioURL + endpoint
+	if baseURL := account.GetOpenAIBaseURL(); baseURL != "" {
+		validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		targetURL = buildOpenAIAudioURL(validatedURL, endpoint)
+	}
+
+	body, contentType := newBody()
+	upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, body)
+	if err != nil {
+		_ = body.Close()
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	if c != nil && c.Request != nil {
+		for key, values := range c.Request.Header {
+			if !openaiAllowedHeaders[strings.ToLower(key)] {
+				continue
+			}
+			for _, v := range values {
+				upstreamReq.Header.Add(key, v)
+			}
+		}
+	}
+	upstreamReq.Header.Set("authorization", "Bearer "+token)
+	upstreamReq.Header.Set("content-type", contentType)
+	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
+		upstreamReq.Header.Set("user-agent", customUA)
+	}
+
+	logger.L().Debug("openai audio: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("endpoint", endpoint),
+	)
+
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		return nil, &UpstreamFailoverError{
+			StatusCode:   http.StatusBadGateway,
+			ResponseBody: []byte(fmt.Sprintf(`{"error":{"type":"upstream_error","message":"%s"}}`, safeErr)),
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+		if s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody) {
+			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+				Platform:           account.Platform,
+				AccountID:          account.ID,
+				AccountName:        account.Name,
+				UpstreamStatusCode: resp.StatusCode,
+				UpstreamRequestID:  resp.Header.Get("x-request-id"),
+				Kind:               "failover",
+				Message:            upstreamMsg,
+			})
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+			}
+			return nil, &UpstreamFailoverError{
+				StatusCode:             resp.StatusCode,
+				ResponseBody:           respBody,
+				RetryableOnSameAccount: account.IsPoolMode() && isPoolModeRetryableStatus(resp.StatusCode),
+			}
+		}
+		_, err := s.handleCompatErrorResponse(resp, c, account, writeChatCompletionsError)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// streamAudioResponse 将上游响应（音频二进制或 SSE）边读边写给客户端，
+// SSE 事件中的 usage（transcript.text.done / speech.audio.done）用于计费。
+func (s *OpenAIGatewayService) streamAudioResponse(c *gin.Context, resp *http.Response, startTime time.Time) (OpenAIUsage, *int, error) {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Writer.Header().Set("Content-Type", contentType)
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+	}
+	c.Status(resp.StatusCode)
+	flusher, _ := c.Writer.(http.Flusher)
+
+	var usage OpenAIUsage
+	var firstTokenMs *int
+	isSSE := strings.HasPrefix(contentType, "text/event-stream")
+	reader := bufio.NewReaderSize(resp.Body, 32*1024)
+	buf := make([]byte, 32*1024)
+	for {
+		var chunk []byte
+		var readErr error
+		if isSSE {
+			chunk, readErr = reader.ReadBytes('\n')
+			if data, ok := extractOpenAISSEDataLine(strings.TrimRight(string(chunk), "\r\n")); ok {
+				if u := gjson.Get(data, "usage"); u.Exists() && u.Get("input_tokens").Exists() {
+					usage.InputTokens = int(u.Get("input_tokens").Int())
+					usage.OutputTokens = int(u.Get("output_tokens").Int())
+				}
+			}
+		} else {
+			var n int
+			n, readErr = reader.Read(buf)
+			chunk = buf[:n]
+		}
+		if len(chunk) > 0 {
+			if firstTokenMs == nil {
+				ms := int(time.Since(startTime).Milliseconds())
+				firstTokenMs = &ms
+			}
+			if _, err := c.Writer.Write(chunk); err != nil {
+				// 客户端断开：停止转发，已生成的内容仍按量计费
+				return usage, firstTokenMs, nil
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return usage, firstTokenMs, nil
+		}
+		if readErr != nil {
+			return usage, firstTokenMs, fmt.Errorf("read upstream audio stream: %w", readErr)
+		}
+	}
+}
+
+// buildOpenAIAudioURL 根据账号 base_url 拼接 audio 端点。
+func buildOpenAIAudioURL(base, endpoint string) string {
+	normalized := strings.TrimRight(strings.TrimSpace(base), "/")
+	if strings.HasSuffix(normalized, "/v1") {
+		return normalized + "/audio/" + endpoint
+	}
+	return normalized + "/v1/audio/" + endpoint
+}
+
+// extractAudioTranscriptionUsage 解析转写 JSON 响应的用量：
+// usage.type=tokens（gpt-4o 系列）返回 token，usage.type=duration 或 verbose_json 的 duration 返回秒数。
+func extractAudioTranscriptionUsage(body []byte) (OpenAIUsage, float64) {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return OpenAIUsage{}, 0
+	}
+	usage := gjson.GetBytes(body, "usage")
+	switch usage.Get("type").String() {
+	case "tokens":
+		return OpenAIUsage{
+			InputTokens:  int(usage.Get("input_tokens").Int()),
+			OutputTokens: int(usage.Get("output_tokens").Int()),
+		}, 0
+	case "duration":
+		return OpenAIUsage{}, usage.Get("seconds").Float()
+	}
+	return OpenAIUsage{}, gjson.GetBytes(body, "duration").Float()
+}
+
+// extractSubtitleDurationSeconds 取 srt / vtt 字幕最后一条时间轴的结束时间作为音频时长。
+func extractSubtitleDurationSeconds(body []byte) float64 {
+	matches := audioSubtitleTimestampPattern.FindAllSubmatch(body, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	m := matches[len(matches)-1]
+	hours, _ := strconv.Atoi(string(m[1]))
+	minutes, _ := strconv.Atoi(string(m[2]))
+	seconds, _ := strconv.Atoi(string(m[3]))
+	millis, _ := strconv.Atoi((string(m[4]) + "00")[:3])
+	return float64(hours*3600+minutes*60+seconds) + float64(millis)/1000
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newAudioTestUpload(t *testing.T, fields map[string]string) *AudioUpload {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		require.NoError(t, mw.WriteField(name, value))
+	}
+	fw, err := mw.CreateFormFile("file", "speech.mp3")
+	require.NoError(t, err)
+	_, _ = fw.Write([]byte("ID3-audio-bytes"))
+	require.NoError(t, mw.Close())
+
+	upload, err := SpoolAudioUpload(&buf, mw.FormDataContentType())
+	require.NoError(t, err)
+	t.Cleanup(upload.Close)
+	return upload
+}
+
+func TestSpoolAudioUpload_WritesFileToDisk(t *testing.T) {
+	upload := newAudioTestUpload(t, map[string]string{"model": "whisper-1"})
+	require.Equal(t, "whisper-1", upload.Field("model"))
+	require.NotNil(t, upload.File)
+	require.Equal(t, "speech.mp3", upload.File.FileName)
+	require.EqualValues(t, len("ID3-audio-bytes"), upload.File.Size)
+
+	data, err := os.ReadFile(upload.File.Path)
+	require.NoError(t, err)
+	require.Equal(t, "ID3-audio-bytes", string(data))
+
+	upload.Close()
+	_, err = os.Stat(upload.File.Path)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = SpoolAudioUpload(strings.NewReader("{}"), "application/json")
+	require.Error(t, err)
+}
+
+func TestOpenAIGatewayService_ForwardAudioTranscription_TextUsesJSONUpstream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", nil)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"text":"hello there","usage":{"type":"duration","seconds":9}}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	upload := newAudioTestUpload(t, map[string]string{"model": "whisper-1", "response_format": "text"})
+
+	result, err := svc.ForwardAudioTranscription(context.Background(), c, newEmbeddingsTestAccount("https://api.openai.com"), AudioEndpointTranscriptions, upload)
+	require.NoError(t, err)
+	require.InDelta(t, 9.0, result.AudioDurationSeconds, 1e-9)
+	require.Equal(t, "https://api.openai.com/v1/audio/transcriptions", upstream.lastReq.URL.String())
+
+	parsed, err := SpoolAudioUpload(bytes.NewReader(upstream.lastBody), upstream.lastReq.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	defer parsed.Close()
+	require.Equal(t, "json", parsed.Field("response_format"))
+	require.NotNil(t, parsed.File)
+
+	require.Equal(t, "hello there\n", rec.Body.String())
+	require.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestOpenAIGatewayService_ForwardAudioSpeech_StreamsAudio(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", nil)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"audio/mpeg"}, "X-Request-Id": []string{"rid-tts"}},
+		Body:       io.NopCloser(strings.NewReader("mp3-frames")),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	body := []byte(`{"model":"tts-1","input":"你好 world","voice":"alloy"}`)
+
+	result, err := svc.ForwardAudioSpeech(context.Background(), c, newEmbeddingsTestAccount("https://relay.example.com/v1"), body)
+	require.NoError(t, err)
+	require.Equal(t, 8, result.AudioCharacters)
+	require.Equal(t, "rid-tts", result.RequestID)
+	require.NotNil(t, result.FirstTokenMs)
+	require.Equal(t, "https://relay.example.com/v1/audio/speech", upstream.lastReq.URL.String())
+	require.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+	require.Equal(t, "mp3-frames", rec.Body.String())
+}
+
+func TestExtractAudioUsage(t *testing.T) {
+	usage, seconds := extractAudioTranscriptionUsage([]byte(`{"text":"x","usage":{"type":"tokens","input_tokens":14,"output_tokens":45}}`))
+	require.Equal(t, 14, usage.InputTokens)
+	require.Equal(t, 45, usage.OutputTokens)
+	require.Zero(t, seconds)
+
+	_, seconds = extractAudioTranscriptionUsage([]byte(`{"task":"transcribe","duration":8.47,"text":"x"}`))
+	require.InDelta(t, 8.47, seconds, 1e-9)
+
+	srt := "1\n00:00:00,000 --> 00:00:03,200\nhello\n\n2\n00:00:03,200 --> 00:01:05,5\nworld\n"
+	require.InDelta(t, 65.5, extractSubtitleDurationSeconds([]byte(srt)), 1e-9)
+	vtt := "WEBVTT\n\n00:00.000 --> 00:02.750\nhi\n"
+	require.InDelta(t, 2.75, extractSubtitleDurationSeconds([]byte(vtt)), 1e-9)
+}
+
+func TestBillingService_CalculateAudioCost(t *testing.T) {
+	svc := &BillingService{pricingService: &PricingService{pricingData: map[string]*LiteLLMModelPricing{}}}
+
+	cost, err := svc.CalculateAudioCost("whisper-1", 60, 0, 2)
+	require.NoError(t, err)
+	require.InDelta(t, 0.006, cost.TotalCost, 1e-12)
+	require.InDelta(t, 0.012, cost.ActualCost, 1e-12)
+
+	cost, err = svc.CalculateAudioCost("tts-1-hd", 0, 1000, 1)
+	require.NoError(t, err)
+	require.InDelta(t, 0.03, cost.TotalCost, 1e-12)
+
+	_, err = svc.CalculateAudioCost("gpt-4o-mini-tts", 0, 1000, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// openaiPlatformEmbeddingsURL OpenAI Platform embeddings 端点（API Key 账号未配置 base_url 时使用）
+const openaiPlatformEmbeddingsURL = "https://api.openai.com/v1/embeddings"
+
+// ForwardEmbeddings forwards an OpenAI /v1/embeddings request to an API key
+// account. Embeddings are always non-streaming and only consume input tokens;
+// when the upstream omits usage, input tokens are estimated locally so the
+// call is still billed.
+func (s *OpenAIGatewayService) ForwardEmbeddings(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	// ChatGPT OAuth 账号只暴露 Codex Responses 接口，只有 API Key 账号可以调用 embeddings。
+	if !account.IsOpenAIApiKey() {
+		return nil, &UpstreamFailoverError{
+			StatusCode:   http.StatusBadGateway,
+			ResponseBody: []byte(`{"error":{"type":"upstream_error","message":"account does not support embeddings"}}`),
+		}
+	}
+
+	originalModel := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+	upstreamModel := originalModel
+	if mapped, matched := account.ResolveMappedModel(originalModel); matched && mapped != "" {
+		upstreamModel = mapped
+	}
+	if upstreamModel != originalModel {
+		if updated, err := sjson.SetBytes(body, "model", upstreamModel); err == nil {
+			body = updated
+		}
+	}
+
+	logger.L().Debug("openai embeddings: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("upstream_model", upstreamModel),
+	)
+
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	targetURL := openaiPlatformEmbeddingsURL
+	if baseURL := account.GetOpenAIBaseURL(); baseURL != "" {
+		validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		targetURL = buildOpenAIEmbeddingsURL(validatedURL)
+	}
+
+	upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	if c != nil && c.Request != nil {
+		for key, values := range c.Request.Header {
+			if !openaiAllowedHeaders[strings.ToLower(key)] {
+				continue
+			}
+			for _, v := range values {
+				upstreamReq.Header.Add(key, v)
+			}
+		}
+	}
+	upstreamReq.Header.Set("authorization", "Bearer "+token)
+	upstreamReq.Header.Set("content-type", "application/json")
+	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
+		upstreamReq.Header.Set("user-agent", customUA)
+	}
+
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		return nil, &UpstreamFailoverError{
+			StatusCode:   http.StatusBadGateway,
+			ResponseBody: []byte(fmt.Sprintf(`{"error":{"type":"upstream_error","message":"%s"}}`, safeErr)),
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+		if s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody) {
+			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+				Platform:           account.Platform,
+				AccountID:          account.ID,
+				AccountName:        account.Name,
+				UpstreamStatusCode: resp.StatusCode,
+				UpstreamRequestID:  resp.Header.Get("x-request-id"),
+				Kind:               "failover",
+				Message:            upstreamMsg,
+			})
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+			}
+			return nil, &UpstreamFailoverError{
+				StatusCode:             resp.StatusCode,
+				ResponseBody:           respBody,
+				RetryableOnSameAccount: account.IsPoolMode() && isPoolModeRetryableStatus(resp.StatusCode),
+			}
+		}
+		return s.handleCompatErrorResponse(resp, c, account, writeChatCompletionsError)
+	}
+
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+	if err != nil {
+		if errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+			setOpsUpstreamError(c, http.StatusBadGateway, "upstream response too large", "")
+			writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Upstream response too large")
+		}
+		return nil, err
+	}
+
+	usage := extractOpenAIEmbeddingsUsage(respBody)
+	if usage.InputTokens == 0 {
+		usage.InputTokens = estimateOpenAIEmbeddingsInputTokens(body)
+	}
+	if upstreamModel != originalModel {
+		respBody = s.replaceModelInResponseBody(respBody, upstreamModel, originalModel)
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.Data(http.StatusOK, "application/json", respBody)
+
+	return &OpenAIForwardResult{
+		RequestID:     resp.Header.Get("x-request-id"),
+		Usage:         usage,
+		Model:         originalModel,
+		UpstreamModel: upstreamModel,
+		Stream:        false,
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+// buildOpenAIEmbeddingsURL 根据账号 base_url 拼接 embeddings 端点。
+func buildOpenAIEmbeddingsURL(base string) string {
+	normalized := strings.TrimRight(strings.TrimSpace(base), "/")
+	if strings.HasSuffix(normalized, "/embeddings") {
+		return normalized
+	}
+	if strings.HasSuffix(normalized, "/v1") {
+		return normalized + "/embeddings"
+	}
+	return normalized + "/v1/embeddings"
+}
+
+// extractOpenAIEmbeddingsUsage 解析 embeddings 响应中的 usage.prompt_tokens。
+func extractOpenAIEmbeddingsUsage(body []byte) OpenAIUsage {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return OpenAIUsage{}
+	}
+	prompt := gjson.GetBytes(body, "usage.prompt_tokens").Int()
+	if prompt <= 0 {
+		// 部分兼容上游只返回 total_tokens
+		prompt = gjson.GetBytes(body, "usage.total_tokens").Int()
+	}
+	return OpenAIUsage{InputTokens: int(prompt)}
+}
+
+// estimateOpenAIEmbeddingsInputTokens 上游未返回 usage 时按 input 文本本地估算 token。
+// input 为 token 数组时直接按元素个数计数。
+func estimateOpenAIEmbeddingsInputTokens(body []byte) int {
+	input := gjson.GetBytes(body, "input")
+	switch {
+	case input.Type == gjson.String:
+		return estimateTokensForText(input.String())
+	case input.IsArray():
+		total := 0
+		input.ForEach(func(_, item gjson.Result) bool {
+			switch {
+			case item.Type == gjson.String:
+				total += estimateTokensForText(item.String())
+			case item.Type == gjson.Number:
+				total++
+			case item.IsArray():
+				total += len(item.Array())
+			}
+			return true
+		})
+		return total
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newEmbeddingsTestAccount(baseURL string) *Account {
+	return &Account{
+		ID:             789,
+		Name:           "embeddings-acc",
+		Platform:       PlatformOpenAI,
+		Type:           AccountTypeAPIKey,
+		Concurrency:    1,
+		Credentials:    map[string]any{"api_key": "sk-embed", "base_url": baseURL},
+		Status:         StatusActive,
+		Schedulable:    true,
+		RateMultiplier: f64p(1),
+	}
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_UsesUpstreamUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", bytes.NewReader(nil))
+
+	body := []byte(`{"model":"text-embedding-3-small","input":"hello world"}`)
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "X-Request-Id": []string{"rid-embed"}},
+		Body:       io.NopCloser(strings.NewReader(`{"object":"list","data":[{"embedding":[0.1]}],"model":"text-embedding-3-small","usage":{"prompt_tokens":7,"total_tokens":7}}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardEmbeddings(context.Background(), c, newEmbeddingsTestAccount("https://api.openai.com"), body)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 7, result.Usage.InputTokens)
+	require.Zero(t, result.Usage.OutputTokens)
+	require.Equal(t, "rid-embed", result.RequestID)
+	require.Equal(t, "text-embedding-3-small", result.Model)
+	require.Equal(t, "https://api.openai.com/v1/embeddings", upstream.lastReq.URL.String())
+	require.Equal(t, "Bearer sk-embed", upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, body, upstream.lastBody)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_EstimatesWhenUsageMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", bytes.NewReader(nil))
+
+	body := []byte(`{"model":"text-embedding-3-small","input":["first sentence","second sentence"]}`)
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"object":"list","data":[]}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardEmbeddings(context.Background(), c, newEmbeddingsTestAccount("https://relay.example.com/v1"), body)
+	require.NoError(t, err)
+	require.Equal(t, estimateOpenAIEmbeddingsInputTokens(body), result.Usage.InputTokens)
+	require.Positive(t, result.Usage.InputTokens)
+	require.Equal(t, "https://relay.example.com/v1/embeddings", upstream.lastReq.URL.String())
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_RestoresMappedModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", bytes.NewReader(nil))
+
+	account := newEmbeddingsTestAccount("https://api.openai.com")
+	account.Credentials["model_mapping"] = map[string]any{"embed-small": "text-embedding-3-small"}
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"object":"list","data":[],"model":"text-embedding-3-small","usage":{"prompt_tokens":3}}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardEmbeddings(context.Background(), c, account, []byte(`{"model":"embed-small","input":"x"}`))
+	require.NoError(t, err)
+	require.Equal(t, "embed-small", result.Model)
+	require.Equal(t, "text-embedding-3-small", result.UpstreamModel)
+	require.Equal(t, "text-embedding-3-small", gjson.GetBytes(upstream.lastBody, "model").String())
+	require.Equal(t, "embed-small", gjson.Get(rec.Body.String(), "model").String())
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_RejectsOAuthAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", bytes.NewReader(nil))
+
+	upstream := &httpUpstreamRecorder{}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	account := &Account{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeOAuth}
+
+	_, err := svc.ForwardEmbeddings(context.Background(), c, account, []byte(`{"model":"text-embedding-3-small","input":"x"}`))
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr))
+	require.Nil(t, upstream.lastReq)
+}
+
+func TestBuildOpenAIEmbeddingsURL(t *testing.T) {
+	require.Equal(t, "https://api.openai.com/v1/embeddings", buildOpenAIEmbeddingsURL("https://api.openai.com"))
+	require.Equal(t, "https://relay.example.com/v1/embeddings", buildOpenAIEmbeddingsURL("https://relay.example.com/v1/"))
+	require.Equal(t, "https://relay.example.com/v1/embeddings", buildOpenAIEmbeddingsURL("https://relay.example.com/v1/embeddings"))
+}
+
+func TestEstimateOpenAIEmbeddingsInputTokens(t *testing.T) {
+	require.Equal(t, estimateTokensForText("hello world"), estimateOpenAIEmbeddingsInputTokens([]byte(`{"input":"hello world"}`)))
+	require.Equal(t, 3, estimateOpenAIEmbeddingsInputTokens([]byte(`{"input":[1,2,3]}`)))
+	require.Equal(t, 5, estimateOpenAIEmbeddingsInputTokens([]byte(`{"input":[[1,2],[3,4,5]]}`)))
+	require.Zero(t, estimateOpenAIEmbeddingsInputTokens([]byte(`{"model":"x"}`)))
+}
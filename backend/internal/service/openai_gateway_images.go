@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// openaiPlatformImagesURL OpenAI Platform images 端点前缀（API Key 账号未配置 base_url 时使用）
+const openaiPlatformImagesURL = "https://api.openai.com/v1/images/"
+
+// openAIImagesUpstreamResult OpenAI 原生 Images 转发结果
+type openAIImagesUpstreamResult struct {
+	Response      *apicompat.ImagesResponse
+	UpstreamModel string
+	RequestID     string
+}
+
+// forwardImages 将 OpenAI Images 请求转发到 API Key 账号上游，成功时返回解析后的响应但不写客户端，
+// 以便调用方统一处理 response_format 与计费。非 failover 错误由本函数写出。
+func (s *OpenAIGatewayService) forwardImages(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	input *ImageGenerationInput,
+) (*openAIImagesUpstreamResult, error) {
+	if !account.IsOpenAIApiKey() {
+		return nil, &UpstreamFailoverError{
+			StatusCode:   http.StatusBadGateway,
+			ResponseBody: []byte(`{"error":{"type":"upstream_error","message":"account does not support images"}}`),
+		}
+	}
+
+	originalModel := input.Request.Model
+	upstreamModel := originalModel
+	if mapped, matched := account.ResolveMappedModel(originalModel); matched && mapped != "" {
+		upstreamModel = mapped
+	}
+	body, err := buildOpenAIImagesUpstreamBody(input, upstreamModel)
+	if err != nil {
+		writeChatCompletionsError(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return nil, err
+	}
+
+	logger.L().Debug("openai images: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("endpoint", input.Endpoint),
+		zap.String("original_model", originalModel),
+		zap.String("upstream_model", upstreamModel),
+	)
+
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	targetURL := openaiPlatformImagesURL + input.Endpoint
+	if baseURL := account.GetOpenAIBaseURL(); baseURL != "" {
+		validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		targetURL = buildOpenAIImagesURL(validatedURL, input.Endpoint)
+	}
+
+	upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	if c != nil && c.Request != nil {
+		for key, values := range c.Request.Header {
+			if !openaiAllowedHeaders[strings.ToLower(key)] {
+				continue
+			}
+			for _, v := range values {
+				upstreamReq.Header.Add(key, v)
+			}
+		}
+	}
+	upstreamReq.Header.Set("authorization", "Bearer "+token)
+	upstreamReq.Header.Set("content-type", input.ContentType)
+	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
+		upstreamReq.Header.Set("user-agent", customUA)
+	}
+
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		return nil, &UpstreamFailoverError{
+			StatusCode:   http.StatusBadGateway,
+			ResponseBody: []byte(fmt.Sprintf(`{"error":{"type":"upstream_error","message":"%s"}}`, safeErr)),
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+		if s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody) {
+			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+				Platform:           account.Platform,
+				AccountID:          account.ID,
+				AccountName:        account.Name,
+				UpstreamStatusCode: resp.StatusCode,
+				UpstreamRequestID:  resp.Header.Get("x-request-id"),
+				Kind:               "failover",
+				Message:            upstreamMsg,
+			})
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+			}
+			return nil, &UpstreamFailoverError{
+				StatusCode:             resp.StatusCode,
+				ResponseBody:           respBody,
+				RetryableOnSameAccount: account.IsPoolMode() && isPoolModeRetryableStatus(resp.StatusCode),
+			}
+		}
+		_, err := s.handleCompatErrorResponse(resp, c, account, writeChatCompletionsError)
+		return nil, err
+	}
+
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+	if err != nil {
+		if errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+			setOpsUpstreamError(c, http.StatusBadGateway, "upstream response too large", "")
+			writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Upstream response too large")
+		}
+		return nil, err
+	}
+	var imagesResp apicompat.ImagesResponse
+	if err := json.Unmarshal(respBody, &imagesResp); err != nil {
+		writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
+		return nil, fmt.Errorf("parse images response: %w", err)
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	return &openAIImagesUpstreamResult{
+		Response:      &imagesResp,
+		UpstreamModel: upstreamModel,
+		RequestID:     resp.Header.Get("x-request-id"),
+	}, nil
+}
+
+// buildOpenAIImagesUpstreamBody 应用模型映射；gpt-image 系列不接受 response_format（固定返回 b64_json），
+// url 格式由网关本地落地后生成，因此转发前移除该字段。
+func buildOpenAIImagesUpstreamBody(input *ImageGenerationInput, upstreamModel string) ([]byte, error) {
+	bodyModel := input.BodyModel
+	if bodyModel == "" {
+		bodyModel = input.Request.Model
+	}
+	setModel := upstreamModel != bodyModel
+	dropFormat := input.Request.ResponseFormat != "" && isGPTImageModel(upstreamModel)
+	if !setModel && !dropFormat {
+		return input.Body, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(input.ContentType)
+	if mediaType != "multipart/form-data" {
+		body := input.Body
+		var err error
+		if setModel {
+			if body, err = sjson.SetBytes(body, "model", upstreamModel); err != nil {
+				return nil, err
+			}
+		}
+		if dropFormat {
+			if body, err = sjson.DeleteBytes(body, "response_format"); err != nil {
+				return nil, err
+			}
+		}
+		return body, nil
+	}
+
+	// multipart：沿用原 boundary 重写字段，Content-Type 无需改动
+	boundary := params["boundary"]
+	reader := multipart.NewReader(bytes.NewReader(input.Body), boundary)
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := part.FormName()
+		switch {
+		case dropFormat && name == "response_format":
+			continue
+		case setModel && name == "model" && part.FileName() == "":
+			fw, err := writer.CreateFormField("model")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := io.WriteString(fw, upstreamModel); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pw, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(pw, part); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// buildOpenAIImagesURL 根据账号 base_url 拼接 images 端点。
+func buildOpenAIImagesURL(base, endpoint string) string {
+	normalized := strings.TrimRight(strings.TrimSpace(base), "/")
+	if strings.HasSuffix(normalized, "/v1") {
+		return normalized + "/images/" + endpoint
+	}
+	return normalized + "/v1/images/" + endpoint
+}
+
+func isGPTImageModel(model string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(model)), "gpt-image")
+}
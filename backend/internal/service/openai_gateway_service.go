@@ -231,6 +231,12 @@ type OpenAIForwardResult struct {
 	ResponseHeaders http.Header
 	Duration        time.Duration
 	FirstTokenMs    *int
+	// ImageCount / ImageSize 由 Images API 设置，大于 0 时按分组图片单价计费
+	ImageCount int
+	ImageSize  string
+	// AudioDurationSeconds / AudioCharacters 由 Audio API 设置；上游未返回 token 用量时按时长/字符计费
+	AudioDurationSeconds float64
+	AudioCharacters      int
 }
 
 type OpenAIWSRetryMetricsSnapshot struct {
@@ -332,7 +338,7 @@ type OpenAIGatewayService struct {
 	openaiWSStateStore            OpenAIWSStateStore
 	openaiScheduler               OpenAIAccountScheduler
 	openaiWSPassthroughDialer     openAIWSClientDialer
-	openaiAccountStats            *openAIAccountRuntimeStats
+	openaiAccountStats            *accountRuntimeStats
 
 	openaiWSFallbackUntil sync.Map // key: int64(accountID), value: time.Time
 	openaiWSRetryMetrics  openAIWSRetryMetrics
@@ -1131,7 +1137,9 @@ func (s *OpenAIGatewayService) SelectAccountForModel(ctx context.Context, groupI
 
 // SelectAccountForModelWithExclusions selects an account supporting the requested model while excluding specified accounts.
 // SelectAccountForModelWithExclusions 选择支持指定模型的账号，同时排除指定的账号。
-func (s *OpenAIGatewayService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (*Account, error) {
+func (s *OpenAIGatewayService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (account *Account, err error) {
+	ctx, span := startAccountSelectSpan(ctx, "scheduler.select_for_model", groupID, requestedModel, len(excludedIDs))
+	defer func() { endSelectedAccountSpan(span, account, err) }()
 	return s.selectAccountForModelWithExclusions(ctx, groupID, sessionHash, requestedModel, excludedIDs, 0)
 }
 
@@ -4494,8 +4502,9 @@ type OpenAIRecordUsageInput struct {
 func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRecordUsageInput) error {
 	result := input.Result
 
-	// 跳过所有 token 均为零的用量记录——上游未返回 usage 时不应写入数据库
-	if result.Usage.InputTokens == 0 && result.Usage.OutputTokens == 0 &&
+	// 跳过所有 token 均为零的用量记录——上游未返回 usage 时不应写入数据库（图片、音频按量计费，不受此限制）
+	hasAudioUsage := result.AudioDurationSeconds > 0 || result.AudioCharacters > 0
+	if result.ImageCount == 0 && !hasAudioUsage && result.Usage.InputTokens == 0 && result.Usage.OutputTokens == 0 &&
 		result.Usage.CacheCreationInputTokens == 0 && result.Usage.CacheReadInputTokens == 0 {
 		return nil
 	}
@@ -4535,20 +4544,43 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	if result.ServiceTier != nil {
 		serviceTier = strings.TrimSpace(*result.ServiceTier)
 	}
-	cost, err := s.billingService.CalculateCostWithServiceTier(billingModel, tokens, multiplier, serviceTier)
-	if err != nil {
-		cost = &CostBreakdown{ActualCost: 0}
+	var cost *CostBreakdown
+	if result.ImageCount > 0 {
+		// 图片生成计费
+		var groupConfig *ImagePriceConfig
+		if apiKey.Group != nil {
+			groupConfig = &ImagePriceConfig{
+				Price1K: apiKey.Group.ImagePrice1K,
+				Price2K: apiKey.Group.ImagePrice2K,
+				Price4K: apiKey.Group.ImagePrice4K,
+			}
+		}
+		cost = s.billingService.CalculateImageCost(billingModel, result.ImageSize, result.ImageCount, groupConfig, multiplier)
+	} else if hasAudioUsage && result.Usage.InputTokens == 0 && result.Usage.OutputTokens == 0 {
+		// 音频按时长/字符计费（whisper-1、tts-1 等）；gpt-4o 系列返回 token 用量时走 token 计费
+		var err error
+		cost, err = s.billingService.CalculateAudioCost(billingModel, result.AudioDurationSeconds, result.AudioCharacters, multiplier)
+		if err != nil {
+			cost = &CostBreakdown{ActualCost: 0}
+		}
+	} else {
+		var err error
+		cost, err = s.billingService.CalculateCostWithServiceTier(billingModel, tokens, multiplier, serviceTier, apiKey.Group)
+		if err != nil {
+			cost = &CostBreakdown{ActualCost: 0}
+		}
 	}
 
 	// Determine billing type
 	isSubscriptionBilling := subscription != nil
-	billingType := BillingTypeBalance
-	if isSubscriptionBilling {
-		billingType = BillingTypeSubscription
-	}
+	billingType := usageBillingType(user, apiKey, subscription)
 
 	// Create usage log
 	durationMs := int(result.Duration.Milliseconds())
+	var imageSize *string
+	if result.ImageSize != "" {
+		imageSize = &result.ImageSize
+	}
 	accountRateMultiplier := account.BillingRateMultiplier()
 	requestID := resolveUsageBillingRequestID(ctx, result.RequestID)
 	usageLog := &UsageLog{
@@ -4573,6 +4605,7 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		CacheReadCost:         cost.CacheReadCost,
 		TotalCost:             cost.TotalCost,
 		ActualCost:            cost.ActualCost,
+		PriceSource:           optionalTrimmedStringPtr(cost.PriceSource),
 		RateMultiplier:        multiplier,
 		AccountRateMultiplier: &accountRateMultiplier,
 		BillingType:           billingType,
@@ -4580,6 +4613,10 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		OpenAIWSMode:          result.OpenAIWSMode,
 		DurationMs:            &durationMs,
 		FirstTokenMs:          result.FirstTokenMs,
+		ImageCount:            result.ImageCount,
+		ImageSize:             imageSize,
+		AudioDurationSeconds:  result.AudioDurationSeconds,
+		AudioCharacters:       result.AudioCharacters,
 		CreatedAt:             time.Now(),
 	}
 	// 添加 UserAgent
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 基于历史基线的告警指标。
+//
+// 静态阈值无法应对昼夜流量差异，以下指标将当前值与预聚合表中同一时段的历史值比较：
+//   - error_rate_baseline_zscore：当前错误率相对过去 4 周同一周内小时（ops_metrics_hourly）的 z-score（σ 倍数）
+//   - token_throughput_drop_percent：当前每小时 token 吞吐相对过去 7 天同一小时中位数的下降百分比
+//   - cost_per_user_spike_percent：最近一个完整小时的人均费用相对过去 7 天同一小时中位数的上涨百分比
+//     （usage_dashboard_hourly 仅有全局维度，因此该指标不支持 platform / group 过滤）
+//
+// 历史样本不足时返回 ok=false，规则不会触发也不会恢复。
+
+const (
+	opsBaselineErrorRateWeeks    = 4
+	opsBaselineTrailingDays      = 7
+	opsBaselineMinErrorSamples   = 2
+	opsBaselineMinDailySamples   = 3
+	opsBaselineMinSampleRequests = 20
+	// opsBaselineMinStdDevPct 错误率标准差下限（百分点），避免历史非常平稳时微小波动放大成巨大 z-score
+	opsBaselineMinStdDevPct = 0.5
+)
+
+// OpsHourlyBaselineSample 单个基线时段在 ops_metrics_hourly 中的汇总
+type OpsHourlyBaselineSample struct {
+	WindowStart   time.Time
+	SuccessCount  int64
+	ErrorCountSLA int64
+	TokenConsumed int64
+}
+
+// OpsUsageCostBaselineSample 单个基线时段在 usage_dashboard_hourly 中的汇总
+type OpsUsageCostBaselineSample struct {
+	WindowStart time.Time
+	ActualCost  float64
+	ActiveUsers int64
+}
+
+func (s *OpsAlertEvaluatorService) computeBaselineMetric(
+	ctx context.Context,
+	metricType string,
+	start time.Time,
+	end time.Time,
+	platform string,
+	groupID *int64,
+) (float64, bool) {
+	if s == nil || s.opsRepo == nil || !end.After(start) {
+		return 0, false
+	}
+
+	switch strings.TrimSpace(metricType) {
+	case "cost_per_user_spike_percent":
+		if strings.TrimSpace(platform) != "" || (groupID != nil && *groupID > 0) {
+			return 0, false
+		}
+		current := end.UTC().Truncate(time.Hour).Add(-time.Hour)
+		windows := append([]time.Time{current}, opsBaselineWindowStarts(current, 24*time.Hour, opsBaselineTrailingDays)...)
+		samples, err := s.opsRepo.ListUsageCostBaselineSamples(ctx, windows)
+		if err != nil {
+			return 0, false
+		}
+		return computeOpsCostPerUserSpike(current, samples)
+	}
+
+	overview, err := s.opsRepo.GetDashboardOverview(ctx, &OpsDashboardFilter{
+		StartTime: start,
+		EndTime:   end,
+		Platform:  platform,
+		GroupID:   groupID,
+		QueryMode: OpsQueryModeRaw,
+	})
+	if err != nil || overview == nil {
+		return 0, false
+	}
+	// 以窗口中点所在小时为锚点回溯历史同一时段
+	anchor := start.Add(end.Sub(start) / 2).UTC()
+
+	switch strings.TrimSpace(metricType) {
+	case "error_rate_baseline_zscore":
+		if overview.RequestCountSLA < opsBaselineMinSampleRequests {
+			return 0, false
+		}
+		windows := opsBaselineWindowStarts(anchor, 7*24*time.Hour, opsBaselineErrorRateWeeks)
+		samples, err := s.opsRepo.ListHourlyBaselineSamples(ctx, windows, platform, groupID)
+		if err != nil {
+			return 0, false
+		}
+		return computeOpsErrorRateZScore(overview.ErrorRate*100, samples)
+	case "token_throughput_drop_percent":
+		windows := opsBaselineWindowStarts(anchor, 24*time.Hour, opsBaselineTrailingDays)
+		samples, err := s.opsRepo.ListHourlyBaselineSamples(ctx, windows, platform, groupID)
+		if err != nil {
+			return 0, false
+		}
+		perHour := float64(overview.TokenConsumed) * float64(time.Hour) / float64(end.Sub(start))
+		return computeOpsTokenThroughputDrop(perHour, samples)
+	default:
+		return 0, false
+	}
+}
+
+// opsBaselineWindowStarts 返回 anchor 往前 1..count 个周期所在小时的起点
+func opsBaselineWindowStarts(anchor time.Time, period time.Duration, count int) []time.Time {
+	out := make([]time.Time, 0, count)
+	for k := 1; k <= count; k++ {
+		out = append(out, anchor.Add(-time.Duration(k)*period).Truncate(time.Hour))
+	}
+	return out
+}
+
+func computeOpsErrorRateZScore(currentPct float64, samples []*OpsHourlyBaselineSample) (float64, bool) {
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+		total := sample.SuccessCount + sample.ErrorCountSLA
+		if total < opsBaselineMinSampleRequests {
+			continue
+		}
+		values = append(values, float64(sample.ErrorCountSLA)/float64(total)*100)
+	}
+	if len(values) < opsBaselineMinErrorSamples {
+		return 0, false
+	}
+	mean, std := opsMeanStdDev(values)
+	if std < opsBaselineMinStdDevPct {
+		std = opsBaselineMinStdDevPct
+	}
+	return (currentPct - mean) / std, true
+}
+
+func computeOpsTokenThroughputDrop(currentPerHour float64, samples []*OpsHourlyBaselineSample) (float64, bool) {
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if sample != nil {
+			values = append(values, float64(sample.TokenConsumed))
+		}
+	}
+	if len(values) < opsBaselineMinDailySamples {
+		return 0, false
+	}
+	median := opsMedian(values)
+	if median <= 0 {
+		return 0, false
+	}
+	return (median - currentPerHour) / median * 100, true
+}
+
+func computeOpsCostPerUserSpike(current time.Time, samples []*OpsUsageCostBaselineSample) (float64, bool) {
+	var currentValue *float64
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if sample == nil || sample.ActiveUsers <= 0 {
+			continue
+		}
+		perUser := sample.ActualCost / float64(sample.ActiveUsers)
+		if sample.WindowStart.Equal(current) {
+			currentValue = &perUser
+			continue
+		}
+		values = append(values, perUser)
+	}
+	if currentValue == nil || len(values) < opsBaselineMinDailySamples {
+		return 0, false
+	}
+	median := opsMedian(values)
+	if median <= 0 {
+		return 0, false
+	}
+	return (*currentValue - median) / median * 100, true
+}
+
+func opsMeanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+func opsMedian(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,148 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type baselineStubOpsRepo struct {
+	stubOpsRepo
+	hourly      []*OpsHourlyBaselineSample
+	cost        []*OpsUsageCostBaselineSample
+	gotWindows  []time.Time
+	gotPlatform string
+	gotGroupID  *int64
+}
+
+func (s *baselineStubOpsRepo) ListHourlyBaselineSamples(ctx context.Context, windowStarts []time.Time, platform string, groupID *int64) ([]*OpsHourlyBaselineSample, error) {
+	s.gotWindows = windowStarts
+	s.gotPlatform = platform
+	s.gotGroupID = groupID
+	return s.hourly, nil
+}
+
+func (s *baselineStubOpsRepo) ListUsageCostBaselineSamples(ctx context.Context, windowStarts []time.Time) ([]*OpsUsageCostBaselineSample, error) {
+	s.gotWindows = windowStarts
+	return s.cost, nil
+}
+
+func TestOpsBaselineWindowStarts(t *testing.T) {
+	anchor := time.Date(2026, 3, 10, 14, 35, 0, 0, time.UTC)
+	got := opsBaselineWindowStarts(anchor, 7*24*time.Hour, 2)
+	require.Equal(t, []time.Time{
+		time.Date(2026, 3, 3, 14, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 24, 14, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestComputeRuleMetric_ErrorRateBaselineZScore(t *testing.T) {
+	repo := &baselineStubOpsRepo{
+		stubOpsRepo: stubOpsRepo{overview: &OpsDashboardOverview{RequestCountSLA: 1000, ErrorRate: 0.08}},
+		hourly: []*OpsHourlyBaselineSample{
+			{SuccessCount: 980, ErrorCountSLA: 20}, // 2%
+			{SuccessCount: 960, ErrorCountSLA: 40}, // 4%
+			{SuccessCount: 970, ErrorCountSLA: 30}, // 3%
+			{SuccessCount: 5, ErrorCountSLA: 5},    // 样本量不足，忽略
+		},
+	}
+	svc := &OpsAlertEvaluatorService{opsRepo: repo}
+	end := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	start := end.Add(-time.Hour)
+	groupID := int64(3)
+
+	got, ok := svc.computeRuleMetric(context.Background(), &OpsAlertRule{MetricType: "error_rate_baseline_zscore"}, nil, start, end, "openai", &groupID)
+	require.True(t, ok)
+	// mean=3, std=sqrt(2/3)≈0.8165 → (8-3)/0.8165
+	require.InDelta(t, 6.1237, got, 0.001)
+	require.Len(t, repo.gotWindows, opsBaselineErrorRateWeeks)
+	require.Equal(t, time.Date(2026, 3, 3, 14, 0, 0, 0, time.UTC), repo.gotWindows[0])
+	require.Equal(t, "openai", repo.gotPlatform)
+	require.Equal(t, &groupID, repo.gotGroupID)
+}
+
+func TestComputeRuleMetric_ErrorRateBaselineZScore_InsufficientData(t *testing.T) {
+	end := time.Now().UTC()
+	start := end.Add(-time.Hour)
+	rule := &OpsAlertRule{MetricType: "error_rate_baseline_zscore"}
+
+	lowTraffic := &baselineStubOpsRepo{stubOpsRepo: stubOpsRepo{overview: &OpsDashboardOverview{RequestCountSLA: 5, ErrorRate: 0.5}}}
+	_, ok := (&OpsAlertEvaluatorService{opsRepo: lowTraffic}).computeRuleMetric(context.Background(), rule, nil, start, end, "", nil)
+	require.False(t, ok)
+
+	oneSample := &baselineStubOpsRepo{
+		stubOpsRepo: stubOpsRepo{overview: &OpsDashboardOverview{RequestCountSLA: 1000, ErrorRate: 0.1}},
+		hourly:      []*OpsHourlyBaselineSample{{SuccessCount: 990, ErrorCountSLA: 10}},
+	}
+	_, ok = (&OpsAlertEvaluatorService{opsRepo: oneSample}).computeRuleMetric(context.Background(), rule, nil, start, end, "", nil)
+	require.False(t, ok)
+}
+
+func TestComputeOpsErrorRateZScore_StdDevFloor(t *testing.T) {
+	samples := []*OpsHourlyBaselineSample{
+		{SuccessCount: 990, ErrorCountSLA: 10},
+		{SuccessCount: 990, ErrorCountSLA: 10},
+	}
+	got, ok := computeOpsErrorRateZScore(2, samples)
+	require.True(t, ok)
+	require.InDelta(t, (2.0-1.0)/opsBaselineMinStdDevPct, got, 0.0001)
+}
+
+func TestComputeRuleMetric_TokenThroughputDrop(t *testing.T) {
+	repo := &baselineStubOpsRepo{
+		// 30 分钟窗口内 20k token → 每小时 40k
+		stubOpsRepo: stubOpsRepo{overview: &OpsDashboardOverview{TokenConsumed: 20000}},
+		hourly: []*OpsHourlyBaselineSample{
+			{TokenConsumed: 90000},
+			{TokenConsumed: 100000},
+			{TokenConsumed: 110000},
+			{TokenConsumed: 500000},
+		},
+	}
+	svc := &OpsAlertEvaluatorService{opsRepo: repo}
+	end := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	start := end.Add(-30 * time.Minute)
+
+	got, ok := svc.computeRuleMetric(context.Background(), &OpsAlertRule{MetricType: "token_throughput_drop_percent"}, nil, start, end, "", nil)
+	require.True(t, ok)
+	// median=105000 → (105000-40000)/105000
+	require.InDelta(t, 61.9048, got, 0.001)
+	require.Len(t, repo.gotWindows, opsBaselineTrailingDays)
+	require.Equal(t, time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC), repo.gotWindows[0])
+}
+
+func TestComputeRuleMetric_CostPerUserSpike(t *testing.T) {
+	end := time.Date(2026, 3, 10, 15, 20, 0, 0, time.UTC)
+	current := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	repo := &baselineStubOpsRepo{
+		cost: []*OpsUsageCostBaselineSample{
+			{WindowStart: current, ActualCost: 30, ActiveUsers: 10},
+			{WindowStart: current.Add(-24 * time.Hour), ActualCost: 10, ActiveUsers: 10},
+			{WindowStart: current.Add(-48 * time.Hour), ActualCost: 12, ActiveUsers: 10},
+			{WindowStart: current.Add(-72 * time.Hour), ActualCost: 8, ActiveUsers: 10},
+			{WindowStart: current.Add(-96 * time.Hour), ActualCost: 5, ActiveUsers: 0},
+		},
+	}
+	svc := &OpsAlertEvaluatorService{opsRepo: repo}
+	rule := &OpsAlertRule{MetricType: "cost_per_user_spike_percent"}
+
+	got, ok := svc.computeRuleMetric(context.Background(), rule, nil, end.Add(-time.Hour), end, "", nil)
+	require.True(t, ok)
+	require.InDelta(t, 200.0, got, 0.0001)
+	require.Equal(t, current, repo.gotWindows[0])
+	require.Len(t, repo.gotWindows, opsBaselineTrailingDays+1)
+
+	groupID := int64(1)
+	_, ok = svc.computeRuleMetric(context.Background(), rule, nil, end.Add(-time.Hour), end, "", &groupID)
+	require.False(t, ok)
+}
+
+func TestOpsMedian(t *testing.T) {
+	require.Equal(t, 0.0, opsMedian(nil))
+	require.Equal(t, 2.0, opsMedian([]float64{3, 1, 2}))
+	require.Equal(t, 2.5, opsMedian([]float64{4, 1, 3, 2}))
+}
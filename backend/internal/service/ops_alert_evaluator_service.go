@@ -32,9 +32,10 @@ return 0
 `)
 
 type OpsAlertEvaluatorService struct {
-	opsService   *OpsService
-	opsRepo      OpsRepository
-	emailService *EmailService
+	opsService          *OpsService
+	opsRepo             OpsRepository
+	emailService        *EmailService
+	notificationService *OpsNotificationService
 
 	redisClient *redis.Client
 	cfg         *config.Config
@@ -65,18 +66,20 @@ func NewOpsAlertEvaluatorService(
 	opsService *OpsService,
 	opsRepo OpsRepository,
 	emailService *EmailService,
+	notificationService *OpsNotificationService,
 	redisClient *redis.Client,
 	cfg *config.Config,
 ) *OpsAlertEvaluatorService {
 	return &OpsAlertEvaluatorService{
-		opsService:   opsService,
-		opsRepo:      opsRepo,
-		emailService: emailService,
-		redisClient:  redisClient,
-		cfg:          cfg,
-		instanceID:   uuid.NewString(),
-		ruleStates:   map[int64]*opsAlertRuleState{},
-		emailLimiter: newSlidingWindowLimiter(0, time.Hour),
+		opsService:          opsService,
+		opsRepo:             opsRepo,
+		emailService:        emailService,
+		notificationService: notificationService,
+		redisClient:         redisClient,
+		cfg:                 cfg,
+		instanceID:          uuid.NewString(),
+		ruleStates:          map[int64]*opsAlertRuleState{},
+		emailLimiter:        newSlidingWindowLimiter(0, time.Hour),
 	}
 }
 
@@ -196,6 +199,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 	eventsCreated := 0
 	eventsResolved := 0
 	emailsSent := 0
+	channelNotifications := 0
 
 	now := time.Now().UTC()
 	safeEnd := now.Truncate(time.Minute)
@@ -292,6 +296,9 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				if s.maybeSendAlertEmail(ctx, runtimeCfg, rule, created) {
 					emailsSent++
 				}
+				if s.maybeNotifyChannels(runtimeCfg, rule, created, OpsNotificationSourceAlertFiring) {
+					channelNotifications++
+				}
 			}
 			continue
 		}
@@ -303,11 +310,17 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] resolve event failed (event=%d): %v", activeEvent.ID, err)
 			} else {
 				eventsResolved++
+				resolvedEvent := *activeEvent
+				resolvedEvent.Status = OpsAlertStatusResolved
+				resolvedEvent.ResolvedAt = &resolvedAt
+				if s.maybeNotifyChannels(runtimeCfg, rule, &resolvedEvent, OpsNotificationSourceAlertResolved) {
+					channelNotifications++
+				}
 			}
 		}
 	}
 
-	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent), 2048)
+	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d channel_notifications=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent, channelNotifications), 2048)
 	s.recordHeartbeatSuccess(runAt, time.Since(startedAt), result)
 }
 
@@ -548,6 +561,8 @@ func (s *OpsAlertEvaluatorService) computeRuleMetric(
 		return float64(countAccountsByCondition(availability.Accounts, func(acc *AccountAvailability) bool {
 			return acc.IsOverloaded
 		})), true
+	case "error_rate_baseline_zscore", "token_throughput_drop_percent", "cost_per_user_spike_percent":
+		return s.computeBaselineMetric(ctx, rule.MetricType, start, end, platform, groupID)
 	}
 
 	overview, err := s.opsRepo.GetDashboardOverview(ctx, &OpsDashboardFilter{
@@ -699,6 +714,71 @@ func (s *OpsAlertEvaluatorService) maybeSendAlertEmail(ctx context.Context, runt
 	return anySent
 }
 
+// maybeNotifyChannels 将告警事件异步投递到规则配置的通知渠道，返回是否已提交投递
+func (s *OpsAlertEvaluatorService) maybeNotifyChannels(runtimeCfg *OpsAlertRuntimeSettings, rule *OpsAlertRule, event *OpsAlertEvent, source string) bool {
+	if s == nil || s.notificationService == nil || rule == nil || event == nil || event.ID <= 0 {
+		return false
+	}
+	if len(rule.NotifyChannelIDs) == 0 {
+		return false
+	}
+	if runtimeCfg != nil && runtimeCfg.Silencing.Enabled {
+		if isOpsAlertSilenced(time.Now().UTC(), rule, event, runtimeCfg.Silencing) {
+			return false
+		}
+	}
+	s.notificationService.DispatchAsync(rule.NotifyChannelIDs, buildOpsAlertNotificationMessage(rule, event, source))
+	return true
+}
+
+func buildOpsAlertNotificationMessage(rule *OpsAlertRule, event *OpsAlertEvent, source string) *OpsNotificationMessage {
+	eventID := event.ID
+	firedAt := event.FiredAt
+	threshold := event.ThresholdValue
+	if threshold == nil {
+		threshold = float64Ptr(rule.Threshold)
+	}
+
+	title := fmt.Sprintf("[Ops Alert][%s] %s", strings.TrimSpace(rule.Severity), strings.TrimSpace(rule.Name))
+	if source == OpsNotificationSourceAlertResolved {
+		title = fmt.Sprintf("[Ops Resolved][%s] %s", strings.TrimSpace(rule.Severity), strings.TrimSpace(rule.Name))
+	}
+
+	value := "-"
+	if event.MetricValue != nil {
+		value = fmt.Sprintf("%.2f", *event.MetricValue)
+	}
+	lines := []string{
+		"Rule: " + strings.TrimSpace(rule.Name),
+		"Severity: " + strings.TrimSpace(rule.Severity),
+		"Status: " + strings.TrimSpace(event.Status),
+		fmt.Sprintf("Metric: %s %s %.2f (current %s)", strings.TrimSpace(rule.MetricType), strings.TrimSpace(rule.Operator), *threshold, value),
+		"Fired at: " + firedAt.UTC().Format(time.RFC3339),
+	}
+	if event.ResolvedAt != nil {
+		lines = append(lines, "Resolved at: "+event.ResolvedAt.UTC().Format(time.RFC3339))
+	}
+	if desc := strings.TrimSpace(event.Description); desc != "" {
+		lines = append(lines, "Description: "+desc)
+	}
+
+	return &OpsNotificationMessage{
+		Source:      source,
+		Title:       title,
+		Text:        strings.Join(lines, "\n"),
+		Severity:    strings.TrimSpace(rule.Severity),
+		Status:      strings.TrimSpace(event.Status),
+		RuleName:    strings.TrimSpace(rule.Name),
+		MetricType:  strings.TrimSpace(rule.MetricType),
+		Operator:    strings.TrimSpace(rule.Operator),
+		MetricValue: event.MetricValue,
+		Threshold:   threshold,
+		EventID:     &eventID,
+		FiredAt:     &firedAt,
+		ResolvedAt:  event.ResolvedAt,
+	}
+}
+
 func buildOpsAlertEmailBody(rule *OpsAlertRule, event *OpsAlertEvent) string {
 	if rule == nil || event == nil {
 		return ""
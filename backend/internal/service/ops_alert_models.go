@@ -30,6 +30,8 @@ type OpsAlertRule struct {
 	CooldownMinutes  int `json:"cooldown_minutes"`
 
 	NotifyEmail bool `json:"notify_email"`
+	// NotifyChannelIDs 告警触发/恢复时投递的通知渠道
+	NotifyChannelIDs []int64 `json:"notify_channel_ids"`
 
 	Filters map[string]any `json:"filters,omitempty"`
 
@@ -57,6 +59,9 @@ type OpsAlertEvent struct {
 
 	EmailSent bool      `json:"email_sent"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// Deliveries 通知渠道投递日志（仅详情接口返回）
+	Deliveries []*OpsNotificationDelivery `json:"deliveries,omitempty"`
 }
 
 type OpsAlertSilence struct {
@@ -30,6 +30,9 @@ func (s *OpsService) CreateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	if rule == nil {
 		return nil, infraerrors.BadRequest("INVALID_RULE", "invalid rule")
 	}
+	if err := s.validateAlertRuleChannels(ctx, rule); err != nil {
+		return nil, err
+	}
 
 	created, err := s.opsRepo.CreateAlertRule(ctx, rule)
 	if err != nil {
@@ -48,6 +51,9 @@ func (s *OpsService) UpdateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	if rule == nil || rule.ID <= 0 {
 		return nil, infraerrors.BadRequest("INVALID_RULE", "invalid rule")
 	}
+	if err := s.validateAlertRuleChannels(ctx, rule); err != nil {
+		return nil, err
+	}
 
 	updated, err := s.opsRepo.UpdateAlertRule(ctx, rule)
 	if err != nil {
@@ -108,6 +114,11 @@ func (s *OpsService) GetAlertEventByID(ctx context.Context, eventID int64) (*Ops
 	if ev == nil {
 		return nil, infraerrors.NotFound("OPS_ALERT_EVENT_NOT_FOUND", "alert event not found")
 	}
+	deliveries, err := s.opsRepo.ListNotificationDeliveries(ctx, &OpsNotificationDeliveryFilter{AlertEventID: &eventID, Limit: 100})
+	if err != nil {
+		return nil, err
+	}
+	ev.Deliveries = deliveries
 	return ev, nil
 }
 
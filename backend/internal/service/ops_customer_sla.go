@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsCustomerSLADefaultTarget = 99.9
+	opsCustomerSLAMaxHistory    = 12
+	opsCustomerSLAMonthLayout   = "2006-01"
+)
+
+// GetCustomerSLAReport 计算指定用户/分组在某个自然月（UTC）的 SLA 报告。
+// 用户侧接口由 handler 强制带上当前用户 ID；管理员可按任意用户/分组查询。
+func (s *OpsService) GetCustomerSLAReport(ctx context.Context, query *OpsCustomerSLAQuery) (*OpsCustomerSLAReport, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if query == nil {
+		query = &OpsCustomerSLAQuery{}
+	}
+	now := time.Now().UTC()
+	start, err := parseOpsSLAMonth(query.Month, now)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildCustomerSLAReport(ctx, query.UserID, query.GroupID, start, now)
+}
+
+// GetCustomerSLAHistory 返回最近 months 个自然月（含当月，倒序）的 SLA 报告。
+func (s *OpsService) GetCustomerSLAHistory(ctx context.Context, userID, groupID *int64, months int) ([]*OpsCustomerSLAReport, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if months <= 0 {
+		months = 3
+	}
+	if months > opsCustomerSLAMaxHistory {
+		return nil, infraerrors.BadRequest("INVALID_SLA_MONTHS", "months must be between 1 and 12")
+	}
+
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	out := make([]*OpsCustomerSLAReport, 0, months)
+	for i := 0; i < months; i++ {
+		report, err := s.buildCustomerSLAReport(ctx, userID, groupID, current.AddDate(0, -i, 0), now)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, report)
+	}
+	return out, nil
+}
+
+func (s *OpsService) buildCustomerSLAReport(ctx context.Context, userID, groupID *int64, monthStart, now time.Time) (*OpsCustomerSLAReport, error) {
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	windowEnd := monthEnd
+	if now.Before(windowEnd) {
+		windowEnd = now
+	}
+
+	stats, err := s.opsRepo.GetCustomerSLAStats(ctx, &OpsCustomerSLAFilter{
+		UserID:    userID,
+		GroupID:   groupID,
+		StartTime: monthStart,
+		EndTime:   windowEnd,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = &OpsCustomerSLAStats{}
+	}
+
+	report := &OpsCustomerSLAReport{
+		Month:              monthStart.Format(opsCustomerSLAMonthLayout),
+		UserID:             userID,
+		GroupID:            groupID,
+		WindowStart:        monthStart,
+		WindowEnd:          windowEnd,
+		WindowElapsedPct:   roundTo2DP(float64(windowEnd.Sub(monthStart)) / float64(monthEnd.Sub(monthStart)) * 100),
+		SuccessfulRequests: stats.SuccessCount,
+		FailedRequests:     stats.ErrorCount,
+		TotalRequests:      stats.SuccessCount + stats.ErrorCount,
+		TargetAvailability: s.customerSLATarget(),
+		Latency:            OpsSLAPercentiles{P50: stats.DurationP50, P95: stats.DurationP95, P99: stats.DurationP99},
+		TTFT:               OpsSLAPercentiles{P50: stats.TTFTP50, P95: stats.TTFTP95, P99: stats.TTFTP99},
+		PartialData:        s.opsErrorLogsPrunedBefore(monthStart, now),
+	}
+	report.Availability, report.ErrorBudget = computeOpsSLAErrorBudget(report.SuccessfulRequests, report.FailedRequests, report.TargetAvailability)
+	report.TargetMet = report.Availability >= report.TargetAvailability
+	return report, nil
+}
+
+// computeOpsSLAErrorBudget 根据成功/失败数与目标可用性（百分比）计算可用性与错误预算。
+func computeOpsSLAErrorBudget(success, failed int64, target float64) (float64, OpsSLAErrorBudget) {
+	total := success + failed
+	budget := OpsSLAErrorBudget{RemainingPct: 100}
+	if total <= 0 {
+		return 100, budget
+	}
+	availability := roundTo4DP(float64(success) / float64(total) * 100)
+	allowedRate := 1 - target/100
+	budget.AllowedFailures = roundTo2DP(float64(total) * allowedRate)
+	if allowedRate > 0 {
+		errorRate := float64(failed) / float64(total)
+		budget.BurnRate = roundTo2DP(errorRate / allowedRate)
+		budget.ConsumedPercent = roundTo2DP(errorRate / allowedRate * 100)
+	} else if failed > 0 {
+		budget.ConsumedPercent = 100
+	}
+	budget.RemainingPct = roundTo2DP(math.Max(0, 100-budget.ConsumedPercent))
+	return availability, budget
+}
+
+func (s *OpsService) customerSLATarget() float64 {
+	if s.cfg != nil && s.cfg.Ops.CustomerSLA.TargetAvailability > 0 && s.cfg.Ops.CustomerSLA.TargetAvailability < 100 {
+		return s.cfg.Ops.CustomerSLA.TargetAvailability
+	}
+	return opsCustomerSLADefaultTarget
+}
+
+// opsErrorLogsPrunedBefore 错误日志保留期早于窗口起点时，窗口内的失败数不完整（可用性会偏高）。
+func (s *OpsService) opsErrorLogsPrunedBefore(windowStart, now time.Time) bool {
+	if s.cfg == nil || !s.cfg.Ops.Cleanup.Enabled || s.cfg.Ops.Cleanup.ErrorLogRetentionDays <= 0 {
+		return false
+	}
+	return windowStart.Before(now.AddDate(0, 0, -s.cfg.Ops.Cleanup.ErrorLogRetentionDays))
+}
+
+func parseOpsSLAMonth(raw string, now time.Time) (time.Time, error) {
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return current, nil
+	}
+	month, err := time.ParseInLocation(opsCustomerSLAMonthLayout, raw, time.UTC)
+	if err != nil {
+		return time.Time{}, infraerrors.BadRequest("INVALID_SLA_MONTH", "month must be in YYYY-MM format")
+	}
+	if month.After(current) {
+		return time.Time{}, infraerrors.BadRequest("INVALID_SLA_MONTH", "month must not be in the future")
+	}
+	return month, nil
+}
+
+func roundTo2DP(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func roundTo4DP(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,105 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeOpsSLAErrorBudget(t *testing.T) {
+	availability, budget := computeOpsSLAErrorBudget(0, 0, 99.9)
+	require.Equal(t, 100.0, availability)
+	require.Equal(t, 100.0, budget.RemainingPct)
+
+	// 10000 次请求、目标 99.9% → 允许 10 次失败；实际 5 次 → 消耗 50%
+	availability, budget = computeOpsSLAErrorBudget(9995, 5, 99.9)
+	require.Equal(t, 99.95, availability)
+	require.InDelta(t, 10.0, budget.AllowedFailures, 0.001)
+	require.InDelta(t, 50.0, budget.ConsumedPercent, 0.001)
+	require.InDelta(t, 50.0, budget.RemainingPct, 0.001)
+	require.InDelta(t, 0.5, budget.BurnRate, 0.001)
+
+	_, budget = computeOpsSLAErrorBudget(998, 2, 99.9)
+	require.InDelta(t, 200.0, budget.ConsumedPercent, 0.001)
+	require.Equal(t, 0.0, budget.RemainingPct)
+	require.InDelta(t, 2.0, budget.BurnRate, 0.001)
+}
+
+func TestParseOpsSLAMonth(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseOpsSLAMonth("", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = parseOpsSLAMonth("2025-12", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), got)
+
+	_, err = parseOpsSLAMonth("2026-04", now)
+	require.Equal(t, "INVALID_SLA_MONTH", infraerrors.Reason(err))
+	_, err = parseOpsSLAMonth("2026/01", now)
+	require.Equal(t, "INVALID_SLA_MONTH", infraerrors.Reason(err))
+}
+
+func TestGetCustomerSLAReport_UsesFilterAndTarget(t *testing.T) {
+	userID := int64(42)
+	var got *OpsCustomerSLAFilter
+	p95 := 1200
+	repo := &opsRepoMock{
+		GetCustomerSLAStatsFn: func(ctx context.Context, filter *OpsCustomerSLAFilter) (*OpsCustomerSLAStats, error) {
+			got = filter
+			return &OpsCustomerSLAStats{SuccessCount: 990, ErrorCount: 10, DurationP95: &p95}, nil
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	cfg.Ops.CustomerSLA.TargetAvailability = 99.5
+	svc := &OpsService{opsRepo: repo, cfg: cfg}
+
+	report, err := svc.GetCustomerSLAReport(context.Background(), &OpsCustomerSLAQuery{UserID: &userID, Month: "2025-02"})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, &userID, got.UserID)
+	require.Nil(t, got.GroupID)
+	require.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), got.StartTime)
+	require.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), got.EndTime)
+
+	require.Equal(t, "2025-02", report.Month)
+	require.Equal(t, int64(1000), report.TotalRequests)
+	require.Equal(t, 99.0, report.Availability)
+	require.Equal(t, 99.5, report.TargetAvailability)
+	require.False(t, report.TargetMet)
+	require.Equal(t, 100.0, report.WindowElapsedPct)
+	require.Equal(t, &p95, report.Latency.P95)
+}
+
+func TestGetCustomerSLAHistory(t *testing.T) {
+	var starts []time.Time
+	repo := &opsRepoMock{
+		GetCustomerSLAStatsFn: func(ctx context.Context, filter *OpsCustomerSLAFilter) (*OpsCustomerSLAStats, error) {
+			starts = append(starts, filter.StartTime)
+			return nil, nil
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	svc := &OpsService{opsRepo: repo, cfg: cfg}
+
+	reports, err := svc.GetCustomerSLAHistory(context.Background(), nil, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+	require.True(t, starts[0].After(starts[1]))
+	require.True(t, starts[1].After(starts[2]))
+	require.Equal(t, 100.0, reports[2].Availability)
+	require.Equal(t, opsCustomerSLADefaultTarget, reports[0].TargetAvailability)
+
+	_, err = svc.GetCustomerSLAHistory(context.Background(), nil, nil, 13)
+	require.Equal(t, "INVALID_SLA_MONTHS", infraerrors.Reason(err))
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"text/template"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"github.com/lib/pq"
+)
+
+const (
+	opsNotificationChannelNameMaxLen = 128
+	opsNotificationTemplateMaxLen    = 4096
+)
+
+// ListNotificationChannels 返回全部通知渠道（凭据已脱敏）
+func (s *OpsService) ListNotificationChannels(ctx context.Context) ([]*OpsNotificationChannel, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return []*OpsNotificationChannel{}, nil
+	}
+	channels, err := s.opsRepo.ListNotificationChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*OpsNotificationChannel, 0, len(channels))
+	for _, ch := range channels {
+		if ch != nil {
+			out = append(out, sanitizeOpsNotificationChannel(ch))
+		}
+	}
+	return out, nil
+}
+
+// GetNotificationChannel 返回单个通知渠道（凭据已脱敏）
+func (s *OpsService) GetNotificationChannel(ctx context.Context, id int64) (*OpsNotificationChannel, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	ch, err := s.getNotificationChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return sanitizeOpsNotificationChannel(ch), nil
+}
+
+func (s *OpsService) CreateNotificationChannel(ctx context.Context, input *OpsNotificationChannel) (*OpsNotificationChannel, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if input == nil {
+		return nil, infraerrors.BadRequest("INVALID_CHANNEL", "invalid notification channel")
+	}
+	if err := s.validateNotificationChannel(input); err != nil {
+		return nil, err
+	}
+
+	created, err := s.opsRepo.CreateNotificationChannel(ctx, input)
+	if err != nil {
+		if isOpsUniqueViolation(err) {
+			return nil, infraerrors.Conflict("OPS_NOTIFICATION_CHANNEL_EXISTS", "notification channel name already exists")
+		}
+		return nil, err
+	}
+	return sanitizeOpsNotificationChannel(created), nil
+}
+
+// UpdateNotificationChannel 更新通知渠道；secret / bot_token 留空表示保留原值
+func (s *OpsService) UpdateNotificationChannel(ctx context.Context, input *OpsNotificationChannel) (*OpsNotificationChannel, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if input == nil || input.ID <= 0 {
+		return nil, infraerrors.BadRequest("INVALID_CHANNEL", "invalid notification channel")
+	}
+	existing, err := s.getNotificationChannel(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Secret == "" {
+		input.Secret = existing.Secret
+	}
+	if input.BotToken == "" {
+		input.BotToken = existing.BotToken
+	}
+	if err := s.validateNotificationChannel(input); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.opsRepo.UpdateNotificationChannel(ctx, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, infraerrors.NotFound("OPS_NOTIFICATION_CHANNEL_NOT_FOUND", "notification channel not found")
+		}
+		if isOpsUniqueViolation(err) {
+			return nil, infraerrors.Conflict("OPS_NOTIFICATION_CHANNEL_EXISTS", "notification channel name already exists")
+		}
+		return nil, err
+	}
+	return sanitizeOpsNotificationChannel(updated), nil
+}
+
+func (s *OpsService) DeleteNotificationChannel(ctx context.Context, id int64) error {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return err
+	}
+	if s.opsRepo == nil {
+		return infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if id <= 0 {
+		return infraerrors.BadRequest("INVALID_CHANNEL_ID", "invalid notification channel id")
+	}
+	if err := s.opsRepo.DeleteNotificationChannel(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return infraerrors.NotFound("OPS_NOTIFICATION_CHANNEL_NOT_FOUND", "notification channel not found")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *OpsService) ListNotificationDeliveries(ctx context.Context, filter *OpsNotificationDeliveryFilter) ([]*OpsNotificationDelivery, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return []*OpsNotificationDelivery{}, nil
+	}
+	return s.opsRepo.ListNotificationDeliveries(ctx, filter)
+}
+
+// getNotificationChannel 返回包含凭据的原始渠道，仅供服务内部使用
+func (s *OpsService) getNotificationChannel(ctx context.Context, id int64) (*OpsNotificationChannel, error) {
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if id <= 0 {
+		return nil, infraerrors.BadRequest("INVALID_CHANNEL_ID", "invalid notification channel id")
+	}
+	ch, err := s.opsRepo.GetNotificationChannelByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return nil, infraerrors.NotFound("OPS_NOTIFICATION_CHANNEL_NOT_FOUND", "notification channel not found")
+	}
+	return ch, nil
+}
+
+func (s *OpsService) validateNotificationChannel(ch *OpsNotificationChannel) error {
+	ch.Name = strings.TrimSpace(ch.Name)
+	ch.Type = strings.ToLower(strings.TrimSpace(ch.Type))
+	ch.URL = strings.TrimSpace(ch.URL)
+	ch.ChatID = strings.TrimSpace(ch.ChatID)
+	ch.MinSeverity = strings.ToUpper(strings.TrimSpace(ch.MinSeverity))
+
+	if ch.Name == "" || len(ch.Name) > opsNotificationChannelNameMaxLen {
+		return infraerrors.BadRequest("INVALID_CHANNEL_NAME", "name is required and must be at most 128 characters")
+	}
+	if !isValidOpsNotificationChannelType(ch.Type) {
+		return infraerrors.BadRequest("INVALID_CHANNEL_TYPE", "type must be one of: "+strings.Join(OpsNotificationChannelTypes, ", "))
+	}
+	switch ch.MinSeverity {
+	case "", "P0", "P1", "P2", "P3":
+	default:
+		return infraerrors.BadRequest("INVALID_MIN_SEVERITY", "min_severity must be one of: P0, P1, P2, P3, or empty")
+	}
+
+	if ch.Type == OpsNotificationChannelTelegram {
+		if ch.BotToken == "" || ch.ChatID == "" {
+			return infraerrors.BadRequest("INVALID_CHANNEL_CONFIG", "bot_token and chat_id are required for telegram channels")
+		}
+	}
+	// Telegram 的 url 为可选的 Bot API 根地址，其余渠道必填
+	if ch.URL != "" || ch.Type != OpsNotificationChannelTelegram {
+		normalized, err := s.normalizeNotificationChannelURL(ch.URL)
+		if err != nil {
+			return infraerrors.BadRequest("INVALID_CHANNEL_URL", err.Error())
+		}
+		ch.URL = normalized
+	}
+	if ch.Type != OpsNotificationChannelWebhook {
+		ch.Headers = nil
+	}
+	for k := range ch.Headers {
+		if strings.TrimSpace(k) == "" {
+			return infraerrors.BadRequest("INVALID_CHANNEL_HEADERS", "header name must not be empty")
+		}
+	}
+
+	if len(ch.Template) > opsNotificationTemplateMaxLen {
+		return infraerrors.BadRequest("INVALID_CHANNEL_TEMPLATE", "template must be at most 4096 characters")
+	}
+	if strings.TrimSpace(ch.Template) != "" {
+		if _, err := template.New("channel").Option("missingkey=zero").Parse(ch.Template); err != nil {
+			return infraerrors.BadRequest("INVALID_CHANNEL_TEMPLATE", "invalid template: "+err.Error())
+		}
+	}
+	return nil
+}
+
+// normalizeNotificationChannelURL 启用 URL 白名单时要求 https 且按配置拒绝私网地址，否则仅做格式校验
+func (s *OpsService) normalizeNotificationChannelURL(raw string) (string, error) {
+	if s.cfg != nil && s.cfg.Security.URLAllowlist.Enabled {
+		return urlvalidator.ValidateHTTPSURL(raw, urlvalidator.ValidationOptions{
+			AllowPrivate: s.cfg.Security.URLAllowlist.AllowPrivateHosts,
+		})
+	}
+	allowInsecureHTTP := s.cfg != nil && s.cfg.Security.URLAllowlist.AllowInsecureHTTP
+	return urlvalidator.ValidateURLFormat(raw, allowInsecureHTTP)
+}
+
+// validateAlertRuleChannels 规范化规则的渠道 ID 并校验其存在
+func (s *OpsService) validateAlertRuleChannels(ctx context.Context, rule *OpsAlertRule) error {
+	rule.NotifyChannelIDs = normalizeOpsNotificationChannelIDs(rule.NotifyChannelIDs)
+	if len(rule.NotifyChannelIDs) == 0 {
+		return nil
+	}
+	channels, err := s.opsRepo.ListNotificationChannels(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[int64]struct{}, len(channels))
+	for _, ch := range channels {
+		if ch != nil {
+			known[ch.ID] = struct{}{}
+		}
+	}
+	for _, id := range rule.NotifyChannelIDs {
+		if _, ok := known[id]; !ok {
+			return infraerrors.BadRequest("INVALID_NOTIFY_CHANNEL", "notify_channel_ids contains unknown channel")
+		}
+	}
+	return nil
+}
+
+func isValidOpsNotificationChannelType(t string) bool {
+	for _, v := range OpsNotificationChannelTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeOpsNotificationChannelIDs 去重、去除非法 ID 并排序，nil 输入返回空切片
+func normalizeOpsNotificationChannelIDs(ids []int64) []int64 {
+	out := make([]int64, 0, len(ids))
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func sanitizeOpsNotificationChannel(ch *OpsNotificationChannel) *OpsNotificationChannel {
+	if ch == nil {
+		return nil
+	}
+	out := *ch
+	out.SecretConfigured = ch.Secret != ""
+	out.BotTokenConfigured = ch.BotToken != ""
+	out.Secret = ""
+	out.BotToken = ""
+	return &out
+}
+
+func isOpsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == "23505"
+}
@@ -0,0 +1,115 @@
+package service
+
+import "time"
+
+// Ops notification channel models.
+//
+// 渠道凭据（secret / bot_token）仅在写入时接收，读取接口只返回 *_configured 标记。
+
+const (
+	OpsNotificationChannelWebhook  = "webhook"
+	OpsNotificationChannelSlack    = "slack"
+	OpsNotificationChannelFeishu   = "feishu"
+	OpsNotificationChannelDingTalk = "dingtalk"
+	OpsNotificationChannelWeCom    = "wecom"
+	OpsNotificationChannelTelegram = "telegram"
+)
+
+// OpsNotificationChannelTypes 支持的渠道类型
+var OpsNotificationChannelTypes = []string{
+	OpsNotificationChannelWebhook,
+	OpsNotificationChannelSlack,
+	OpsNotificationChannelFeishu,
+	OpsNotificationChannelDingTalk,
+	OpsNotificationChannelWeCom,
+	OpsNotificationChannelTelegram,
+}
+
+const (
+	OpsNotificationSourceAlertFiring   = "alert_firing"
+	OpsNotificationSourceAlertResolved = "alert_resolved"
+	OpsNotificationSourceReport        = "report"
+	OpsNotificationSourceTest          = "test"
+	// OpsNotificationSourceBalanceDrift 余额对账发现偏差
+	OpsNotificationSourceBalanceDrift = "balance_drift"
+)
+
+const (
+	OpsNotificationDeliverySuccess = "success"
+	OpsNotificationDeliveryFailed  = "failed"
+)
+
+type OpsNotificationChannel struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+
+	// URL webhook 地址；Telegram 渠道为可选的 Bot API 根地址（默认 https://api.telegram.org）
+	URL string `json:"url"`
+	// Secret 签名密钥：webhook 为 HMAC-SHA256，飞书/钉钉为机器人加签密钥
+	Secret           string `json:"secret,omitempty"`
+	SecretConfigured bool   `json:"secret_configured"`
+	// BotToken / ChatID 仅 Telegram 使用
+	BotToken           string `json:"bot_token,omitempty"`
+	BotTokenConfigured bool   `json:"bot_token_configured"`
+	ChatID             string `json:"chat_id,omitempty"`
+	// Headers 通用 webhook 附加请求头
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Template text/template 消息模板，空表示使用内置模板
+	Template string `json:"template"`
+	// MinSeverity 最低投递级别（P0 最高），空表示不过滤；报表与测试消息不受限制
+	MinSeverity string `json:"min_severity"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OpsNotificationDelivery struct {
+	ID          int64  `json:"id"`
+	ChannelID   int64  `json:"channel_id"`
+	ChannelName string `json:"channel_name"`
+	ChannelType string `json:"channel_type"`
+
+	AlertEventID *int64 `json:"alert_event_id,omitempty"`
+	Source       string `json:"source"`
+
+	Status       string `json:"status"`
+	Attempts     int    `json:"attempts"`
+	HTTPStatus   *int   `json:"http_status,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OpsNotificationDeliveryFilter struct {
+	Limit int
+
+	AlertEventID *int64
+	ChannelID    *int64
+}
+
+// OpsNotificationMessage 渠道无关的通知内容
+type OpsNotificationMessage struct {
+	Source string
+	Title  string
+	// Text 内置模板生成的正文
+	Text string
+
+	Severity string
+	Status   string
+
+	RuleName    string
+	MetricType  string
+	Operator    string
+	MetricValue *float64
+	Threshold   *float64
+
+	EventID    *int64
+	FiredAt    *time.Time
+	ResolvedAt *time.Time
+
+	// Data 结构化附加数据（如财务报表明细），仅通用 webhook 渠道在 payload 的 data 字段中携带
+	Data any
+}
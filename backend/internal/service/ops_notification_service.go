@@ -0,0 +1,532 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	opsNotificationRequestTimeout   = 10 * time.Second
+	opsNotificationDispatchTimeout  = 2 * time.Minute
+	opsNotificationMaxResponseBytes = 64 << 10
+	opsNotificationErrorMaxLen      = 1024
+	opsNotificationTextMaxLen       = 3800
+
+	opsNotificationTelegramAPIBase = "https://api.telegram.org"
+
+	// 通用 webhook 签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+	OpsNotificationSignatureHeader = "X-Sub2API-Signature"
+	OpsNotificationTimestampHeader = "X-Sub2API-Timestamp"
+	OpsNotificationSourceHeader    = "X-Sub2API-Event"
+)
+
+// opsNotificationRetryBackoff 失败后的重试间隔，最多尝试 len+1 次
+var opsNotificationRetryBackoff = []time.Duration{1 * time.Second, 3 * time.Second}
+
+const opsNotificationDefaultTemplate = `{{.Text}}`
+
+// OpsNotificationService 负责将告警/报表投递到通知渠道，并记录投递日志。
+type OpsNotificationService struct {
+	opsRepo OpsRepository
+	cfg     *config.Config
+
+	httpClient   *http.Client
+	retryBackoff []time.Duration
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func NewOpsNotificationService(opsRepo OpsRepository, cfg *config.Config) *OpsNotificationService {
+	return &OpsNotificationService{
+		opsRepo:      opsRepo,
+		cfg:          cfg,
+		retryBackoff: opsNotificationRetryBackoff,
+	}
+}
+
+// Stop 停止接收异步投递并等待进行中的投递完成
+func (s *OpsNotificationService) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// DispatchAsync 在后台投递，避免重试退避阻塞告警评估循环
+func (s *OpsNotificationService) DispatchAsync(channelIDs []int64, msg *OpsNotificationMessage) {
+	if s == nil || len(channelIDs) == 0 || msg == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), opsNotificationDispatchTimeout)
+		defer cancel()
+		s.Dispatch(ctx, channelIDs, msg)
+	}()
+}
+
+// Dispatch 同步投递到指定渠道并返回投递记录。
+// 已禁用的渠道跳过；告警消息额外按渠道的 min_severity 过滤。
+func (s *OpsNotificationService) Dispatch(ctx context.Context, channelIDs []int64, msg *OpsNotificationMessage) []*OpsNotificationDelivery {
+	if s == nil || s.opsRepo == nil || msg == nil {
+		return nil
+	}
+	ids := normalizeOpsNotificationChannelIDs(channelIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	channels, err := s.opsRepo.ListNotificationChannels(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.ops_notification", "[OpsNotification] list channels failed: %v", err)
+		return nil
+	}
+	byID := make(map[int64]*OpsNotificationChannel, len(channels))
+	for _, ch := range channels {
+		if ch != nil {
+			byID[ch.ID] = ch
+		}
+	}
+
+	out := make([]*OpsNotificationDelivery, 0, len(ids))
+	for _, id := range ids {
+		ch := byID[id]
+		if ch == nil || !ch.Enabled {
+			continue
+		}
+		if isOpsAlertNotificationSource(msg.Source) && !opsNotificationSeverityAllowed(ch.MinSeverity, msg.Severity) {
+			continue
+		}
+		out = append(out, s.Deliver(ctx, ch, msg))
+	}
+	return out
+}
+
+// SendTest 向指定渠道发送测试消息（忽略启用状态与级别过滤）
+func (s *OpsNotificationService) SendTest(ctx context.Context, channelID int64) (*OpsNotificationDelivery, error) {
+	if s == nil || s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_NOTIFICATION_UNAVAILABLE", "Notification service not available")
+	}
+	ch, err := s.opsRepo.GetNotificationChannelByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return nil, infraerrors.NotFound("OPS_NOTIFICATION_CHANNEL_NOT_FOUND", "notification channel not found")
+	}
+	now := time.Now().UTC()
+	msg := &OpsNotificationMessage{
+		Source:   OpsNotificationSourceTest,
+		Title:    "[Ops Test] " + ch.Name,
+		Text:     fmt.Sprintf("This is a test notification from sub2api (%s).", now.Format(time.RFC3339)),
+		Severity: "P3",
+		Status:   OpsNotificationSourceTest,
+		FiredAt:  &now,
+	}
+	return s.Deliver(ctx, ch, msg), nil
+}
+
+// Deliver 投递到单个渠道（含重试）并写入投递日志
+func (s *OpsNotificationService) Deliver(ctx context.Context, ch *OpsNotificationChannel, msg *OpsNotificationMessage) *OpsNotificationDelivery {
+	attempts, httpStatus, err := s.send(ctx, ch, msg)
+
+	delivery := &OpsNotificationDelivery{
+		ChannelID:    ch.ID,
+		ChannelName:  truncateString(ch.Name, opsNotificationChannelNameMaxLen),
+		ChannelType:  ch.Type,
+		AlertEventID: msg.EventID,
+		Source:       msg.Source,
+		Status:       OpsNotificationDeliverySuccess,
+		Attempts:     attempts,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if httpStatus > 0 {
+		delivery.HTTPStatus = &httpStatus
+	}
+	if err != nil {
+		delivery.Status = OpsNotificationDeliveryFailed
+		delivery.ErrorMessage = truncateString(err.Error(), opsNotificationErrorMaxLen)
+		logger.LegacyPrintf("service.ops_notification", "[OpsNotification] deliver failed (channel=%d type=%s source=%s attempts=%d): %v", ch.ID, ch.Type, msg.Source, attempts, err)
+	}
+
+	if s.opsRepo != nil {
+		recordCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if id, err := s.opsRepo.InsertNotificationDelivery(recordCtx, delivery); err == nil {
+			delivery.ID = id
+		}
+	}
+	return delivery
+}
+
+// send 渲染并发送消息。网络错误、429 与 5xx 会按退避重试，其余失败立即返回。
+func (s *OpsNotificationService) send(ctx context.Context, ch *OpsNotificationChannel, msg *OpsNotificationMessage) (attempts int, httpStatus int, err error) {
+	text, err := renderOpsNotificationText(ch.Template, msg)
+	if err != nil {
+		return 0, 0, err
+	}
+	client, err := s.client()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for {
+		attempts++
+		var retryable bool
+		httpStatus, retryable, err = s.sendOnce(ctx, client, ch, msg, text)
+		if err == nil || !retryable || attempts > len(s.retryBackoff) {
+			return attempts, httpStatus, err
+		}
+		timer := time.NewTimer(s.retryBackoff[attempts-1])
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, httpStatus, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *OpsNotificationService) sendOnce(ctx context.Context, client *http.Client, ch *OpsNotificationChannel, msg *OpsNotificationMessage, text string) (status int, retryable bool, err error) {
+	req, err := buildOpsNotificationRequest(ctx, ch, msg, text, time.Now())
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Telegram 的 bot token 位于 URL 路径中，*url.Error 会原样带出，写日志前需要脱敏
+		if ch.BotToken != "" {
+			err = errors.New(strings.ReplaceAll(err.Error(), ch.BotToken, "***"))
+		}
+		return 0, ctx.Err() == nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, opsNotificationMaxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return resp.StatusCode, retryable, fmt.Errorf("http %d: %s", resp.StatusCode, truncateString(strings.TrimSpace(string(body)), 256))
+	}
+	if err := checkOpsNotificationResponse(ch.Type, body); err != nil {
+		return resp.StatusCode, false, err
+	}
+	return resp.StatusCode, false, nil
+}
+
+func (s *OpsNotificationService) client() (*http.Client, error) {
+	if s.httpClient != nil {
+		return s.httpClient, nil
+	}
+	opts := httpclient.Options{Timeout: opsNotificationRequestTimeout}
+	if s.cfg != nil {
+		opts.ValidateResolvedIP = s.cfg.Security.URLAllowlist.Enabled
+		opts.AllowPrivateHosts = s.cfg.Security.URLAllowlist.AllowPrivateHosts
+	}
+	return httpclient.GetClient(opts)
+}
+
+// opsNotificationTemplateData 消息模板可用字段：
+// .Source .Title .Text .Severity .Status .RuleName .MetricType .Operator
+// .MetricValue .Threshold .EventID .FiredAt .ResolvedAt
+type opsNotificationTemplateData struct {
+	Source      string
+	Title       string
+	Text        string
+	Severity    string
+	Status      string
+	RuleName    string
+	MetricType  string
+	Operator    string
+	MetricValue string
+	Threshold   string
+	EventID     int64
+	FiredAt     string
+	ResolvedAt  string
+}
+
+func newOpsNotificationTemplateData(msg *OpsNotificationMessage) opsNotificationTemplateData {
+	data := opsNotificationTemplateData{
+		Source:      msg.Source,
+		Title:       msg.Title,
+		Text:        msg.Text,
+		Severity:    msg.Severity,
+		Status:      msg.Status,
+		RuleName:    msg.RuleName,
+		MetricType:  msg.MetricType,
+		Operator:    msg.Operator,
+		MetricValue: formatOpsNotificationFloat(msg.MetricValue),
+		Threshold:   formatOpsNotificationFloat(msg.Threshold),
+	}
+	if msg.EventID != nil {
+		data.EventID = *msg.EventID
+	}
+	if msg.FiredAt != nil {
+		data.FiredAt = msg.FiredAt.UTC().Format(time.RFC3339)
+	}
+	if msg.ResolvedAt != nil {
+		data.ResolvedAt = msg.ResolvedAt.UTC().Format(time.RFC3339)
+	}
+	return data
+}
+
+func renderOpsNotificationText(tmpl string, msg *OpsNotificationMessage) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = opsNotificationDefaultTemplate
+	}
+	t, err := template.New("notification").Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, newOpsNotificationTemplateData(msg)); err != nil {
+		return "", fmt.Errorf("render template: %w", err)
+	}
+	return truncateString(strings.TrimSpace(buf.String()), opsNotificationTextMaxLen), nil
+}
+
+func buildOpsNotificationRequest(ctx context.Context, ch *OpsNotificationChannel, msg *OpsNotificationMessage, text string, now time.Time) (*http.Request, error) {
+	title := strings.TrimSpace(msg.Title)
+	endpoint := strings.TrimSpace(ch.URL)
+	var payload any
+
+	switch ch.Type {
+	case OpsNotificationChannelWebhook:
+		body := map[string]any{
+			"source":       msg.Source,
+			"title":        title,
+			"text":         text,
+			"severity":     msg.Severity,
+			"status":       msg.Status,
+			"rule_name":    msg.RuleName,
+			"metric_type":  msg.MetricType,
+			"operator":     msg.Operator,
+			"metric_value": msg.MetricValue,
+			"threshold":    msg.Threshold,
+			"event_id":     msg.EventID,
+			"fired_at":     msg.FiredAt,
+			"resolved_at":  msg.ResolvedAt,
+			"sent_at":      now.UTC(),
+		}
+		if msg.Data != nil {
+			body["data"] = msg.Data
+		}
+		payload = body
+	case OpsNotificationChannelSlack:
+		payload = map[string]any{"text": "*" + title + "*\n" + text}
+	case OpsNotificationChannelFeishu:
+		body := map[string]any{
+			"msg_type": "text",
+			"content":  map[string]string{"text": title + "\n" + text},
+		}
+		if ch.Secret != "" {
+			ts := strconv.FormatInt(now.Unix(), 10)
+			body["timestamp"] = ts
+			body["sign"] = opsFeishuSign(ts, ch.Secret)
+		}
+		payload = body
+	case OpsNotificationChannelDingTalk:
+		payload = map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": title,
+				"text":  "### " + title + "\n\n" + strings.ReplaceAll(text, "\n", "\n\n"),
+			},
+		}
+		if ch.Secret != "" {
+			signed, err := opsDingTalkSignedURL(endpoint, ch.Secret, now)
+			if err != nil {
+				return nil, err
+			}
+			endpoint = signed
+		}
+	case OpsNotificationChannelWeCom:
+		payload = map[string]any{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": "**" + title + "**\n" + text},
+		}
+	case OpsNotificationChannelTelegram:
+		base := strings.TrimRight(endpoint, "/")
+		if base == "" {
+			base = opsNotificationTelegramAPIBase
+		}
+		endpoint = base + "/bot" + ch.BotToken + "/sendMessage"
+		payload = map[string]any{
+			"chat_id":                  ch.ChatID,
+			"text":                     title + "\n" + text,
+			"disable_web_page_preview": true,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported channel type: %s", ch.Type)
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if ch.Type == OpsNotificationChannelWebhook {
+		for k, v := range ch.Headers {
+			if k = strings.TrimSpace(k); k != "" {
+				req.Header.Set(k, v)
+			}
+		}
+		req.Header.Set(OpsNotificationSourceHeader, msg.Source)
+		if ch.Secret != "" {
+			ts := strconv.FormatInt(now.Unix(), 10)
+			req.Header.Set(OpsNotificationTimestampHeader, ts)
+			req.Header.Set(OpsNotificationSignatureHeader, "sha256="+SignOpsNotificationWebhook(ch.Secret, ts, raw))
+		}
+	}
+	return req, nil
+}
+
+// SignOpsNotificationWebhook 计算通用 webhook 签名，接收方可用同一算法校验
+func SignOpsNotificationWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// opsFeishuSign 飞书机器人加签：以 timestamp + "\n" + secret 为 key 对空串做 HMAC-SHA256
+func opsFeishuSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// opsDingTalkSignedURL 钉钉机器人加签：对 timestamp(ms) + "\n" + secret 做 HMAC-SHA256，结果附加到 query
+func opsDingTalkSignedURL(endpoint, secret string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(ts + "\n" + secret))
+	q := u.Query()
+	q.Set("timestamp", ts)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// checkOpsNotificationResponse 部分 IM 平台在 HTTP 200 中返回业务错误码
+func checkOpsNotificationResponse(channelType string, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	switch channelType {
+	case OpsNotificationChannelFeishu:
+		var r struct {
+			Code          *int   `json:"code"`
+			Msg           string `json:"msg"`
+			StatusCode    *int   `json:"StatusCode"`
+			StatusMessage string `json:"StatusMessage"`
+		}
+		if json.Unmarshal(body, &r) != nil {
+			return nil
+		}
+		if r.Code != nil && *r.Code != 0 {
+			return fmt.Errorf("feishu error %d: %s", *r.Code, r.Msg)
+		}
+		if r.StatusCode != nil && *r.StatusCode != 0 {
+			return fmt.Errorf("feishu error %d: %s", *r.StatusCode, r.StatusMessage)
+		}
+	case OpsNotificationChannelDingTalk, OpsNotificationChannelWeCom:
+		var r struct {
+			ErrCode *int   `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if json.Unmarshal(body, &r) != nil {
+			return nil
+		}
+		if r.ErrCode != nil && *r.ErrCode != 0 {
+			return fmt.Errorf("%s error %d: %s", channelType, *r.ErrCode, r.ErrMsg)
+		}
+	case OpsNotificationChannelTelegram:
+		var r struct {
+			OK          *bool  `json:"ok"`
+			Description string `json:"description"`
+		}
+		if json.Unmarshal(body, &r) != nil {
+			return nil
+		}
+		if r.OK != nil && !*r.OK {
+			return fmt.Errorf("telegram error: %s", r.Description)
+		}
+	}
+	return nil
+}
+
+func isOpsAlertNotificationSource(source string) bool {
+	return source == OpsNotificationSourceAlertFiring || source == OpsNotificationSourceAlertResolved || source == OpsNotificationSourceBalanceDrift
+}
+
+// opsNotificationSeverityAllowed P0 为最高级别；未知级别按最低处理
+func opsNotificationSeverityAllowed(minSeverity, severity string) bool {
+	minSeverity = strings.ToUpper(strings.TrimSpace(minSeverity))
+	if minSeverity == "" {
+		return true
+	}
+	rank := func(v string) int {
+		switch strings.ToUpper(strings.TrimSpace(v)) {
+		case "P0":
+			return 0
+		case "P1":
+			return 1
+		case "P2":
+			return 2
+		case "P3":
+			return 3
+		default:
+			return 4
+		}
+	}
+	return rank(severity) <= rank(minSeverity)
+}
+
+func formatOpsNotificationFloat(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
@@ -0,0 +1,266 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOpsNotificationService(repo OpsRepository) *OpsNotificationService {
+	svc := NewOpsNotificationService(repo, nil)
+	svc.httpClient = &http.Client{Timeout: 5 * time.Second}
+	svc.retryBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	return svc
+}
+
+func TestOpsNotificationService_WebhookSignatureAndHeaders(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var recorded *OpsNotificationDelivery
+	repo := &opsRepoMock{
+		InsertNotificationDeliveryFn: func(ctx context.Context, input *OpsNotificationDelivery) (int64, error) {
+			recorded = input
+			return 7, nil
+		},
+	}
+	svc := newTestOpsNotificationService(repo)
+
+	eventID := int64(42)
+	ch := &OpsNotificationChannel{
+		ID:      1,
+		Name:    "hook",
+		Type:    OpsNotificationChannelWebhook,
+		URL:     srv.URL,
+		Secret:  "s3cret",
+		Headers: map[string]string{"X-Custom": "yes"},
+	}
+	msg := &OpsNotificationMessage{
+		Source:   OpsNotificationSourceAlertFiring,
+		Title:    "CPU high",
+		Text:     "cpu 95%",
+		Severity: "P1",
+		Status:   "firing",
+		EventID:  &eventID,
+	}
+
+	d := svc.Deliver(context.Background(), ch, msg)
+	require.Equal(t, OpsNotificationDeliverySuccess, d.Status)
+	require.Equal(t, int64(7), d.ID)
+	require.Equal(t, 1, d.Attempts)
+	require.NotNil(t, d.HTTPStatus)
+	require.Equal(t, http.StatusNoContent, *d.HTTPStatus)
+	require.Same(t, d, recorded)
+	require.Equal(t, &eventID, d.AlertEventID)
+
+	require.Equal(t, "yes", gotHeader.Get("X-Custom"))
+	require.Equal(t, OpsNotificationSourceAlertFiring, gotHeader.Get(OpsNotificationSourceHeader))
+	ts := gotHeader.Get(OpsNotificationTimestampHeader)
+	require.NotEmpty(t, ts)
+	require.Equal(t, "sha256="+SignOpsNotificationWebhook("s3cret", ts, gotBody), gotHeader.Get(OpsNotificationSignatureHeader))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(gotBody, &payload))
+	require.Equal(t, "CPU high", payload["title"])
+	require.Equal(t, "cpu 95%", payload["text"])
+	require.Equal(t, float64(42), payload["event_id"])
+}
+
+func TestOpsNotificationService_RetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc := newTestOpsNotificationService(&opsRepoMock{})
+	ch := &OpsNotificationChannel{ID: 1, Name: "slack", Type: OpsNotificationChannelSlack, URL: srv.URL}
+
+	d := svc.Deliver(context.Background(), ch, &OpsNotificationMessage{Source: OpsNotificationSourceReport, Title: "t", Text: "x"})
+	require.Equal(t, OpsNotificationDeliverySuccess, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestOpsNotificationService_NoRetryOnClientError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	svc := newTestOpsNotificationService(&opsRepoMock{})
+	ch := &OpsNotificationChannel{ID: 1, Name: "wecom", Type: OpsNotificationChannelWeCom, URL: srv.URL}
+
+	d := svc.Deliver(context.Background(), ch, &OpsNotificationMessage{Source: OpsNotificationSourceReport, Title: "t", Text: "x"})
+	require.Equal(t, OpsNotificationDeliveryFailed, d.Status)
+	require.Equal(t, 1, d.Attempts)
+	require.Contains(t, d.ErrorMessage, "bad payload")
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOpsNotificationService_BusinessErrorCodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		typ    string
+		body   string
+		errMsg string
+	}{
+		{name: "feishu error", typ: OpsNotificationChannelFeishu, body: `{"code":19021,"msg":"sign match fail"}`, errMsg: "sign match fail"},
+		{name: "feishu ok", typ: OpsNotificationChannelFeishu, body: `{"code":0,"msg":"success"}`},
+		{name: "dingtalk error", typ: OpsNotificationChannelDingTalk, body: `{"errcode":310000,"errmsg":"keywords not in content"}`, errMsg: "keywords not in content"},
+		{name: "wecom ok", typ: OpsNotificationChannelWeCom, body: `{"errcode":0,"errmsg":"ok"}`},
+		{name: "telegram error", typ: OpsNotificationChannelTelegram, body: `{"ok":false,"description":"chat not found"}`, errMsg: "chat not found"},
+		{name: "non json", typ: OpsNotificationChannelSlack, body: `ok`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkOpsNotificationResponse(tc.typ, []byte(tc.body))
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestBuildOpsNotificationRequest_SignedIMFormats(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	msg := &OpsNotificationMessage{Source: OpsNotificationSourceAlertFiring, Title: "T"}
+
+	feishu := &OpsNotificationChannel{Type: OpsNotificationChannelFeishu, URL: "https://open.feishu.cn/hook/x", Secret: "k"}
+	req, err := buildOpsNotificationRequest(context.Background(), feishu, msg, "body", now)
+	require.NoError(t, err)
+	var payload map[string]any
+	require.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+	require.Equal(t, "1700000000", payload["timestamp"])
+	require.Equal(t, opsFeishuSign("1700000000", "k"), payload["sign"])
+
+	ding := &OpsNotificationChannel{Type: OpsNotificationChannelDingTalk, URL: "https://oapi.dingtalk.com/robot/send?access_token=abc", Secret: "k"}
+	req, err = buildOpsNotificationRequest(context.Background(), ding, msg, "body", now)
+	require.NoError(t, err)
+	q := req.URL.Query()
+	require.Equal(t, "abc", q.Get("access_token"))
+	require.Equal(t, "1700000000000", q.Get("timestamp"))
+	require.NotEmpty(t, q.Get("sign"))
+}
+
+func TestOpsNotificationService_TelegramRedactsToken(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	svc := newTestOpsNotificationService(&opsRepoMock{})
+	ch := &OpsNotificationChannel{ID: 1, Name: "tg", Type: OpsNotificationChannelTelegram, URL: srv.URL, BotToken: "123:ABC", ChatID: "-100"}
+	d := svc.Deliver(context.Background(), ch, &OpsNotificationMessage{Source: OpsNotificationSourceTest, Title: "t", Text: "x"})
+	require.Equal(t, OpsNotificationDeliverySuccess, d.Status)
+	require.Equal(t, "/bot123:ABC/sendMessage", gotPath)
+
+	// 关闭服务器后触发传输错误，错误信息中不应出现 bot token
+	srv.Close()
+	d = svc.Deliver(context.Background(), ch, &OpsNotificationMessage{Source: OpsNotificationSourceTest, Title: "t", Text: "x"})
+	require.Equal(t, OpsNotificationDeliveryFailed, d.Status)
+	require.NotContains(t, d.ErrorMessage, "123:ABC")
+	require.Equal(t, 3, d.Attempts)
+}
+
+func TestOpsNotificationService_DispatchFiltersChannels(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repo := &opsRepoMock{
+		ListNotificationChannelsFn: func(ctx context.Context) ([]*OpsNotificationChannel, error) {
+			return []*OpsNotificationChannel{
+				{ID: 1, Name: "all", Type: OpsNotificationChannelSlack, URL: srv.URL, Enabled: true},
+				{ID: 2, Name: "critical", Type: OpsNotificationChannelSlack, URL: srv.URL, Enabled: true, MinSeverity: "P0"},
+				{ID: 3, Name: "disabled", Type: OpsNotificationChannelSlack, URL: srv.URL, Enabled: false},
+			}, nil
+		},
+	}
+	svc := newTestOpsNotificationService(repo)
+
+	alert := &OpsNotificationMessage{Source: OpsNotificationSourceAlertFiring, Severity: "P2", Title: "t", Text: "x"}
+	deliveries := svc.Dispatch(context.Background(), []int64{1, 2, 3, 4}, alert)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, int64(1), deliveries[0].ChannelID)
+
+	// 报表不受 min_severity 限制
+	report := &OpsNotificationMessage{Source: OpsNotificationSourceReport, Title: "t", Text: "x"}
+	deliveries = svc.Dispatch(context.Background(), []int64{1, 2, 3}, report)
+	require.Len(t, deliveries, 2)
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRenderOpsNotificationText(t *testing.T) {
+	v := 95.5
+	th := 90.0
+	eventID := int64(9)
+	msg := &OpsNotificationMessage{
+		Text:        "default body",
+		Severity:    "P1",
+		RuleName:    "cpu",
+		MetricValue: &v,
+		Threshold:   &th,
+		EventID:     &eventID,
+	}
+
+	out, err := renderOpsNotificationText("", msg)
+	require.NoError(t, err)
+	require.Equal(t, "default body", out)
+
+	out, err = renderOpsNotificationText("[{{.Severity}}] {{.RuleName}} {{.MetricValue}}>{{.Threshold}} #{{.EventID}}", msg)
+	require.NoError(t, err)
+	require.Equal(t, "[P1] cpu 95.50>90.00 #9", out)
+
+	_, err = renderOpsNotificationText("{{.Missing", msg)
+	require.Error(t, err)
+}
+
+func TestOpsNotificationSeverityAllowed(t *testing.T) {
+	require.True(t, opsNotificationSeverityAllowed("", "P3"))
+	require.True(t, opsNotificationSeverityAllowed("P1", "P0"))
+	require.True(t, opsNotificationSeverityAllowed("P1", "P1"))
+	require.False(t, opsNotificationSeverityAllowed("P1", "P2"))
+	require.False(t, opsNotificationSeverityAllowed("p0", "unknown"))
+}
+
+func TestOpsHTMLToPlainText(t *testing.T) {
+	in := "<h2>Daily &amp; Report</h2><table><tr><td>QPS</td><td>12</td></tr></table><p>done</p>"
+	out := opsHTMLToPlainText(in)
+	require.Equal(t, "Daily & Report\nQPS | 12\ndone", strings.TrimSpace(out))
+}
+
+func TestNormalizeOpsNotificationChannelIDs(t *testing.T) {
+	require.Equal(t, []int64{}, normalizeOpsNotificationChannelIDs(nil))
+	require.Equal(t, []int64{1, 3, 5}, normalizeOpsNotificationChannelIDs([]int64{5, 3, 0, -1, 3, 1}))
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// 错误类别：client_error=4xx，server_error=5xx，upstream_error=发生过上游错误（含被重试覆盖的），any_error=以上任一
+	OpsPayloadCaptureErrorClassClient   = "client_error"
+	OpsPayloadCaptureErrorClassServer   = "server_error"
+	OpsPayloadCaptureErrorClassUpstream = "upstream_error"
+	OpsPayloadCaptureErrorClassAny      = "any_error"
+
+	// OpsPayloadCaptureTraceEvent 抓取成功后写入请求追踪的事件类型，Data 中带 capture_id
+	OpsPayloadCaptureTraceEvent = "payload_captured"
+)
+
+// PayloadCaptureStore 载荷抓取对象存储（本地磁盘或 S3）
+type PayloadCaptureStore interface {
+	Put(ctx context.Context, key string, body io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// OpsPayloadCaptureSettings 载荷抓取运行时配置（存储在 settings 表）
+type OpsPayloadCaptureSettings struct {
+	Enabled bool                     `json:"enabled"`
+	Rules   []*OpsPayloadCaptureRule `json:"rules"`
+}
+
+// OpsPayloadCaptureRule 抓取规则：已设置的条件全部满足后再按 SampleRate 抽样。
+// 未设置的条件视为不限；多条规则按顺序匹配，命中第一条即抓取。
+type OpsPayloadCaptureRule struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// SampleRate 抽样比例（0-1]，默认 1
+	SampleRate float64 `json:"sample_rate"`
+
+	UserIDs      []int64  `json:"user_ids,omitempty"`
+	APIKeyIDs    []int64  `json:"api_key_ids,omitempty"`
+	GroupIDs     []int64  `json:"group_ids,omitempty"`
+	Models       []string `json:"models,omitempty"`
+	StatusCodes  []int    `json:"status_codes,omitempty"`
+	ErrorClasses []string `json:"error_classes,omitempty"`
+}
+
+// OpsPayloadCaptureMatchInput 规则匹配所需的请求属性
+type OpsPayloadCaptureMatchInput struct {
+	UserID        int64
+	APIKeyID      int64
+	GroupID       int64
+	Model         string
+	StatusCode    int
+	UpstreamError bool
+}
+
+// OpsPayloadCaptureInput 网关中间件提交的原始载荷（未脱敏）
+type OpsPayloadCaptureInput struct {
+	ClientRequestID string
+	RuleName        string
+	Platform        string
+	Model           string
+	Method          string
+	RequestPath     string
+	StatusCode      int
+	Stream          bool
+
+	UserID   *int64
+	APIKeyID *int64
+	GroupID  *int64
+
+	RequestHeaders      http.Header
+	RequestBody         []byte
+	UpstreamRequestBody []byte
+	ResponseHeaders     http.Header
+	ResponseBody        []byte
+	// ResponseTruncated 响应在写出阶段已超过上限被截断
+	ResponseTruncated bool
+
+	CapturedAt time.Time
+}
+
+// OpsPayloadCapture 抓取元数据
+type OpsPayloadCapture struct {
+	ID              string    `json:"id"`
+	ClientRequestID string    `json:"client_request_id"`
+	RuleName        string    `json:"rule_name"`
+	StorageKey      string    `json:"-"`
+	Platform        string    `json:"platform,omitempty"`
+	Model           string    `json:"model,omitempty"`
+	RequestPath     string    `json:"request_path,omitempty"`
+	StatusCode      int       `json:"status_code"`
+	UserID          *int64    `json:"user_id,omitempty"`
+	APIKeyID        *int64    `json:"api_key_id,omitempty"`
+	GroupID         *int64    `json:"group_id,omitempty"`
+	SizeBytes       int64     `json:"size_bytes"`
+	Truncated       bool      `json:"truncated"`
+	CreatedAt       time.Time `json:"created_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// opsPayloadCaptureDocument 存储对象的 JSON 结构（gzip 压缩）
+type opsPayloadCaptureDocument struct {
+	ID              string                 `json:"id"`
+	ClientRequestID string                 `json:"client_request_id"`
+	Rule            string                 `json:"rule"`
+	CapturedAt      time.Time              `json:"captured_at"`
+	Request         *opsPayloadCapturePart `json:"request"`
+	UpstreamRequest *opsPayloadCapturePart `json:"upstream_request,omitempty"`
+	Response        *opsPayloadCapturePart `json:"response"`
+}
+
+type opsPayloadCapturePart struct {
+	Method     string            `json:"method,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	StatusCode int               `json:"status_code,omitempty"`
+	Stream     bool              `json:"stream,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	// Body JSON 载荷保留结构（已脱敏），其它内容（含 SSE 文本）为脱敏后的字符串
+	Body      any  `json:"body,omitempty"`
+	BodyBytes int  `json:"body_bytes"`
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -0,0 +1,647 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	opsPayloadCaptureMaxRules          = 20
+	opsPayloadCaptureDefaultRetention  = 72 * time.Hour
+	opsPayloadCaptureDefaultMaxBody    = 1 << 20
+	opsPayloadCaptureDefaultQueueSize  = 256
+	opsPayloadCaptureSettingsRefresh   = 30 * time.Second
+	opsPayloadCaptureCleanupInterval   = time.Hour
+	opsPayloadCaptureCleanupBatchSize  = 500
+	opsPayloadCaptureStoreTimeout      = 30 * time.Second
+	opsPayloadCaptureSettingsLoadLimit = 5 * time.Second
+)
+
+var ErrPayloadCaptureNotFound = infraerrors.NotFound("OPS_PAYLOAD_CAPTURE_NOT_FOUND", "payload capture not found")
+
+func invalidPayloadCaptureSettings(message string) error {
+	return infraerrors.BadRequest("OPS_PAYLOAD_CAPTURE_INVALID", message)
+}
+
+type opsPayloadCaptureJob struct {
+	id    string
+	key   string
+	input *OpsPayloadCaptureInput
+}
+
+// PayloadCaptureService 按规则抽样抓取网关请求/响应载荷。
+//
+// 匹配在网关热路径上只读内存中的规则快照；脱敏、压缩与上传由后台 worker 完成，
+// 队列满时直接丢弃，不影响请求本身。抓取元数据写入 ops_payload_captures，并按保留时长定期清理。
+type PayloadCaptureService struct {
+	opsRepo     OpsRepository
+	settingRepo SettingRepository
+	store       PayloadCaptureStore
+	cfg         *config.Config
+
+	settings atomic.Pointer[OpsPayloadCaptureSettings]
+	queue    chan *opsPayloadCaptureJob
+	dropped  atomic.Int64
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+func NewPayloadCaptureService(opsRepo OpsRepository, settingRepo SettingRepository, store PayloadCaptureStore, cfg *config.Config) *PayloadCaptureService {
+	queueSize := opsPayloadCaptureDefaultQueueSize
+	if cfg != nil && cfg.Ops.PayloadCapture.QueueSize > 0 {
+		queueSize = cfg.Ops.PayloadCapture.QueueSize
+	}
+	return &PayloadCaptureService{
+		opsRepo:     opsRepo,
+		settingRepo: settingRepo,
+		store:       store,
+		cfg:         cfg,
+		queue:       make(chan *opsPayloadCaptureJob, queueSize),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start 加载规则并启动上传 worker、规则刷新与过期清理。
+func (s *PayloadCaptureService) Start() {
+	if s == nil {
+		return
+	}
+	if s.cfg != nil && !s.cfg.Ops.Enabled {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.refreshSettings()
+		s.wg.Add(3)
+		go s.uploadLoop()
+		go s.refreshLoop()
+		go s.cleanupLoop()
+	})
+}
+
+// Stop 停止后台任务；队列中尚未上传的抓取会被丢弃。
+func (s *PayloadCaptureService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		if s.stopCh != nil {
+			close(s.stopCh)
+		}
+	})
+	s.wg.Wait()
+}
+
+// Active 是否存在启用的抓取规则（网关中间件据此决定是否包装响应）。
+func (s *PayloadCaptureService) Active() bool {
+	if s == nil {
+		return false
+	}
+	settings := s.settings.Load()
+	if settings == nil || !settings.Enabled {
+		return false
+	}
+	for _, rule := range settings.Rules {
+		if rule != nil && rule.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxBodyBytes 每段载荷最多保留的字节数。
+func (s *PayloadCaptureService) MaxBodyBytes() int {
+	if s != nil && s.cfg != nil && s.cfg.Ops.PayloadCapture.MaxBodyBytes > 0 {
+		return s.cfg.Ops.PayloadCapture.MaxBodyBytes
+	}
+	return opsPayloadCaptureDefaultMaxBody
+}
+
+// Match 返回命中的第一条规则（含抽样），未命中返回 nil。
+func (s *PayloadCaptureService) Match(input *OpsPayloadCaptureMatchInput) *OpsPayloadCaptureRule {
+	if s == nil || input == nil {
+		return nil
+	}
+	settings := s.settings.Load()
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+	for _, rule := range settings.Rules {
+		if rule == nil || !rule.Enabled || !rule.matches(input) {
+			continue
+		}
+		if rule.SampleRate < 1 && rand.Float64() >= rule.SampleRate {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// Submit 将抓取放入上传队列，返回分配的 capture id；队列满或服务未启动时返回 false。
+func (s *PayloadCaptureService) Submit(input *OpsPayloadCaptureInput) (string, bool) {
+	if s == nil || input == nil || s.store == nil {
+		return "", false
+	}
+	if input.CapturedAt.IsZero() {
+		input.CapturedAt = time.Now().UTC()
+	}
+	id := uuid.NewString()
+	job := &opsPayloadCaptureJob{
+		id:    id,
+		key:   opsPayloadCaptureStorageKey(input.CapturedAt, id),
+		input: input,
+	}
+	select {
+	case s.queue <- job:
+		return id, true
+	default:
+		if n := s.dropped.Add(1); n == 1 || n%100 == 0 {
+			logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] queue full, dropped=%d", n)
+		}
+		return "", false
+	}
+}
+
+// GetCapture 返回抓取元数据。
+func (s *PayloadCaptureService) GetCapture(ctx context.Context, id string) (*OpsPayloadCapture, error) {
+	if s == nil || s.opsRepo == nil {
+		return nil, ErrPayloadCaptureNotFound
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, ErrPayloadCaptureNotFound
+	}
+	capture, err := s.opsRepo.GetPayloadCaptureByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if capture == nil {
+		return nil, ErrPayloadCaptureNotFound
+	}
+	return capture, nil
+}
+
+// OpenCaptureContent 返回解压后的抓取内容（JSON）。
+func (s *PayloadCaptureService) OpenCaptureContent(ctx context.Context, id string) (io.ReadCloser, error) {
+	capture, err := s.GetCapture(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.store == nil {
+		return nil, ErrPayloadCaptureNotFound
+	}
+	raw, err := s.store.Open(ctx, capture.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("open payload capture: %w", err)
+	}
+	zr, err := gzip.NewReader(raw)
+	if err != nil {
+		_ = raw.Close()
+		return nil, fmt.Errorf("decompress payload capture: %w", err)
+	}
+	return &opsPayloadCaptureReader{Reader: zr, closers: []io.Closer{zr, raw}}, nil
+}
+
+type opsPayloadCaptureReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *opsPayloadCaptureReader) Close() error {
+	var firstErr error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// =========================
+// Settings
+// =========================
+
+func defaultOpsPayloadCaptureSettings() *OpsPayloadCaptureSettings {
+	return &OpsPayloadCaptureSettings{Enabled: false, Rules: []*OpsPayloadCaptureRule{}}
+}
+
+func normalizeOpsPayloadCaptureSettings(cfg *OpsPayloadCaptureSettings) {
+	if cfg == nil {
+		return
+	}
+	rules := make([]*OpsPayloadCaptureRule, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		if rule == nil {
+			continue
+		}
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.SampleRate <= 0 {
+			rule.SampleRate = 1
+		}
+		models := make([]string, 0, len(rule.Models))
+		for _, m := range rule.Models {
+			if m = strings.TrimSpace(m); m != "" {
+				models = append(models, m)
+			}
+		}
+		rule.Models = models
+		classes := make([]string, 0, len(rule.ErrorClasses))
+		for _, c := range rule.ErrorClasses {
+			if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+				classes = append(classes, c)
+			}
+		}
+		rule.ErrorClasses = classes
+		rules = append(rules, rule)
+	}
+	cfg.Rules = rules
+}
+
+func validateOpsPayloadCaptureSettings(cfg *OpsPayloadCaptureSettings) error {
+	if cfg == nil {
+		return invalidPayloadCaptureSettings("invalid payload capture settings")
+	}
+	if len(cfg.Rules) > opsPayloadCaptureMaxRules {
+		return invalidPayloadCaptureSettings(fmt.Sprintf("at most %d rules are allowed", opsPayloadCaptureMaxRules))
+	}
+	seen := make(map[string]struct{}, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		if rule.Name == "" {
+			return invalidPayloadCaptureSettings("rule name is required")
+		}
+		if _, ok := seen[rule.Name]; ok {
+			return invalidPayloadCaptureSettings("duplicate rule name: " + rule.Name)
+		}
+		seen[rule.Name] = struct{}{}
+		if rule.SampleRate > 1 {
+			return invalidPayloadCaptureSettings("sample_rate must be between 0 and 1")
+		}
+		for _, code := range rule.StatusCodes {
+			if code < 100 || code > 599 {
+				return invalidPayloadCaptureSettings(fmt.Sprintf("invalid status code: %d", code))
+			}
+		}
+		for _, class := range rule.ErrorClasses {
+			switch class {
+			case OpsPayloadCaptureErrorClassClient, OpsPayloadCaptureErrorClassServer,
+				OpsPayloadCaptureErrorClassUpstream, OpsPayloadCaptureErrorClassAny:
+			default:
+				return invalidPayloadCaptureSettings("invalid error class: " + class)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *PayloadCaptureService) GetSettings(ctx context.Context) (*OpsPayloadCaptureSettings, error) {
+	defaultCfg := defaultOpsPayloadCaptureSettings()
+	if s == nil || s.settingRepo == nil {
+		return defaultCfg, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyOpsPayloadCaptureSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return defaultCfg, nil
+		}
+		return nil, err
+	}
+
+	cfg := defaultOpsPayloadCaptureSettings()
+	if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+		return defaultCfg, nil
+	}
+	normalizeOpsPayloadCaptureSettings(cfg)
+	return cfg, nil
+}
+
+func (s *PayloadCaptureService) UpdateSettings(ctx context.Context, cfg *OpsPayloadCaptureSettings) (*OpsPayloadCaptureSettings, error) {
+	if s == nil || s.settingRepo == nil {
+		return nil, errors.New("setting repository not initialized")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cfg == nil {
+		return nil, invalidPayloadCaptureSettings("invalid payload capture settings")
+	}
+
+	normalizeOpsPayloadCaptureSettings(cfg)
+	if err := validateOpsPayloadCaptureSettings(cfg); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyOpsPayloadCaptureSettings, string(raw)); err != nil {
+		return nil, err
+	}
+
+	// 本实例立即生效，其它实例在下一次刷新周期内生效
+	snapshot := &OpsPayloadCaptureSettings{}
+	_ = json.Unmarshal(raw, snapshot)
+	s.settings.Store(snapshot)
+
+	updated := &OpsPayloadCaptureSettings{}
+	_ = json.Unmarshal(raw, updated)
+	return updated, nil
+}
+
+func (s *PayloadCaptureService) refreshSettings() {
+	ctx, cancel := context.WithTimeout(context.Background(), opsPayloadCaptureSettingsLoadLimit)
+	defer cancel()
+	cfg, err := s.GetSettings(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] load settings failed: %v", err)
+		return
+	}
+	s.settings.Store(cfg)
+}
+
+func (s *PayloadCaptureService) refreshLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(opsPayloadCaptureSettingsRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.refreshSettings()
+		}
+	}
+}
+
+func (r *OpsPayloadCaptureRule) matches(in *OpsPayloadCaptureMatchInput) bool {
+	if len(r.UserIDs) > 0 && !containsInt64(r.UserIDs, in.UserID) {
+		return false
+	}
+	if len(r.APIKeyIDs) > 0 && !containsInt64(r.APIKeyIDs, in.APIKeyID) {
+		return false
+	}
+	if len(r.GroupIDs) > 0 && !containsInt64(r.GroupIDs, in.GroupID) {
+		return false
+	}
+	if len(r.Models) > 0 {
+		matched := false
+		for _, m := range r.Models {
+			if strings.EqualFold(m, strings.TrimSpace(in.Model)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.StatusCodes) > 0 {
+		matched := false
+		for _, code := range r.StatusCodes {
+			if code == in.StatusCode {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.ErrorClasses) > 0 {
+		matched := false
+		for _, class := range r.ErrorClasses {
+			if opsPayloadCaptureErrorClassMatches(class, in) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func opsPayloadCaptureErrorClassMatches(class string, in *OpsPayloadCaptureMatchInput) bool {
+	switch class {
+	case OpsPayloadCaptureErrorClassClient:
+		return in.StatusCode >= 400 && in.StatusCode < 500
+	case OpsPayloadCaptureErrorClassServer:
+		return in.StatusCode >= 500
+	case OpsPayloadCaptureErrorClassUpstream:
+		return in.UpstreamError
+	case OpsPayloadCaptureErrorClassAny:
+		return in.StatusCode >= 400 || in.UpstreamError
+	default:
+		return false
+	}
+}
+
+// =========================
+// Upload & cleanup
+// =========================
+
+func (s *PayloadCaptureService) uploadLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case job := <-s.queue:
+			s.persist(job)
+		}
+	}
+}
+
+func (s *PayloadCaptureService) persist(job *opsPayloadCaptureJob) {
+	if job == nil || job.input == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsPayloadCaptureStoreTimeout)
+	defer cancel()
+
+	payload, truncated, err := buildOpsPayloadCaptureObject(job.id, job.input, s.MaxBodyBytes())
+	if err != nil {
+		logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] encode capture=%s failed: %v", job.id, err)
+		return
+	}
+	size, err := s.store.Put(ctx, job.key, bytes.NewReader(payload))
+	if err != nil {
+		logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] store capture=%s failed: %v", job.id, err)
+		return
+	}
+
+	in := job.input
+	record := &OpsPayloadCapture{
+		ID:              job.id,
+		ClientRequestID: in.ClientRequestID,
+		RuleName:        in.RuleName,
+		StorageKey:      job.key,
+		Platform:        in.Platform,
+		Model:           in.Model,
+		RequestPath:     in.RequestPath,
+		StatusCode:      in.StatusCode,
+		UserID:          in.UserID,
+		APIKeyID:        in.APIKeyID,
+		GroupID:         in.GroupID,
+		SizeBytes:       size,
+		Truncated:       truncated,
+		CreatedAt:       in.CapturedAt,
+		ExpiresAt:       in.CapturedAt.Add(s.retention()),
+	}
+	if s.opsRepo == nil {
+		return
+	}
+	if err := s.opsRepo.InsertPayloadCapture(ctx, record); err != nil {
+		logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] insert capture=%s failed: %v", job.id, err)
+		_ = s.store.Delete(ctx, job.key)
+	}
+}
+
+func (s *PayloadCaptureService) retention() time.Duration {
+	if s.cfg != nil && s.cfg.Ops.PayloadCapture.RetentionHours > 0 {
+		return time.Duration(s.cfg.Ops.PayloadCapture.RetentionHours) * time.Hour
+	}
+	return opsPayloadCaptureDefaultRetention
+}
+
+func (s *PayloadCaptureService) cleanupLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(opsPayloadCaptureCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.cleanupExpired(time.Now().UTC())
+		}
+	}
+}
+
+// cleanupExpired 删除过期抓取（先删对象再删元数据，对象删除失败的条目留待下次重试）
+func (s *PayloadCaptureService) cleanupExpired(now time.Time) int {
+	if s.opsRepo == nil || s.store == nil {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	total := 0
+	for {
+		expired, err := s.opsRepo.ListExpiredPayloadCaptures(ctx, now, opsPayloadCaptureCleanupBatchSize)
+		if err != nil {
+			logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] list expired failed: %v", err)
+			return total
+		}
+		if len(expired) == 0 {
+			return total
+		}
+		ids := make([]string, 0, len(expired))
+		for _, capture := range expired {
+			if err := s.store.Delete(ctx, capture.StorageKey); err != nil {
+				logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] delete object capture=%s failed: %v", capture.ID, err)
+				continue
+			}
+			ids = append(ids, capture.ID)
+		}
+		if len(ids) == 0 {
+			return total
+		}
+		if err := s.opsRepo.DeletePayloadCaptures(ctx, ids); err != nil {
+			logger.LegacyPrintf("service.ops_payload_capture", "[PayloadCapture] delete metadata failed: %v", err)
+			return total
+		}
+		total += len(ids)
+		if len(expired) < opsPayloadCaptureCleanupBatchSize {
+			return total
+		}
+	}
+}
+
+// opsPayloadCaptureStorageKey 按日期分目录，便于按前缀配置对象存储生命周期规则
+func opsPayloadCaptureStorageKey(at time.Time, id string) string {
+	return at.UTC().Format("2006/01/02") + "/" + id + ".json.gz"
+}
+
+// buildOpsPayloadCaptureObject 脱敏、截断并 gzip 压缩抓取内容
+func buildOpsPayloadCaptureObject(id string, in *OpsPayloadCaptureInput, maxBodyBytes int) ([]byte, bool, error) {
+	requestBody, requestTruncated := truncateOpsPayloadCaptureBody(in.RequestBody, maxBodyBytes)
+	responseBody, responseTruncated := truncateOpsPayloadCaptureBody(in.ResponseBody, maxBodyBytes)
+	responseTruncated = responseTruncated || in.ResponseTruncated
+
+	doc := &opsPayloadCaptureDocument{
+		ID:              id,
+		ClientRequestID: in.ClientRequestID,
+		Rule:            in.RuleName,
+		CapturedAt:      in.CapturedAt.UTC(),
+		Request: &opsPayloadCapturePart{
+			Method:    in.Method,
+			Path:      in.RequestPath,
+			Headers:   redactPayloadCaptureHeaders(in.RequestHeaders),
+			Body:      redactPayloadCaptureBody(requestBody),
+			BodyBytes: len(in.RequestBody),
+			Truncated: requestTruncated,
+		},
+		Response: &opsPayloadCapturePart{
+			StatusCode: in.StatusCode,
+			Stream:     in.Stream,
+			Headers:    redactPayloadCaptureHeaders(in.ResponseHeaders),
+			Body:       redactPayloadCaptureBody(responseBody),
+			BodyBytes:  len(in.ResponseBody),
+			Truncated:  responseTruncated,
+		},
+	}
+	truncated := requestTruncated || responseTruncated
+	if len(in.UpstreamRequestBody) > 0 {
+		upstreamBody, upstreamTruncated := truncateOpsPayloadCaptureBody(in.UpstreamRequestBody, maxBodyBytes)
+		doc.UpstreamRequest = &opsPayloadCapturePart{
+			Body:      redactPayloadCaptureBody(upstreamBody),
+			BodyBytes: len(in.UpstreamRequestBody),
+			Truncated: upstreamTruncated,
+		}
+		truncated = truncated || upstreamTruncated
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, false, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), truncated, nil
+}
+
+func truncateOpsPayloadCaptureBody(body []byte, maxBytes int) ([]byte, bool) {
+	if maxBytes <= 0 || len(body) <= maxBytes {
+		return body, false
+	}
+	return body[:maxBytes], true
+}
@@ -0,0 +1,296 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryPayloadCaptureStore struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	deleteErr error
+}
+
+func newMemoryPayloadCaptureStore() *memoryPayloadCaptureStore {
+	return &memoryPayloadCaptureStore{objects: make(map[string][]byte)}
+}
+
+func (s *memoryPayloadCaptureStore) Put(ctx context.Context, key string, body io.Reader) (int64, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return int64(len(data)), nil
+}
+
+func (s *memoryPayloadCaptureStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryPayloadCaptureStore) Delete(ctx context.Context, key string) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func newTestPayloadCaptureService(repo OpsRepository, store PayloadCaptureStore, rules ...*OpsPayloadCaptureRule) *PayloadCaptureService {
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	cfg.Ops.PayloadCapture.MaxBodyBytes = 64
+	cfg.Ops.PayloadCapture.RetentionHours = 2
+	svc := NewPayloadCaptureService(repo, nil, store, cfg)
+	settings := &OpsPayloadCaptureSettings{Enabled: true, Rules: rules}
+	normalizeOpsPayloadCaptureSettings(settings)
+	svc.settings.Store(settings)
+	return svc
+}
+
+func TestPayloadCaptureService_MatchRules(t *testing.T) {
+	svc := newTestPayloadCaptureService(nil, newMemoryPayloadCaptureStore(),
+		&OpsPayloadCaptureRule{Name: "disabled", Enabled: false},
+		&OpsPayloadCaptureRule{Name: "user-5xx", Enabled: true, UserIDs: []int64{7}, ErrorClasses: []string{OpsPayloadCaptureErrorClassServer}},
+		&OpsPayloadCaptureRule{Name: "model-429", Enabled: true, Models: []string{"claude-sonnet-4"}, StatusCodes: []int{429}},
+		&OpsPayloadCaptureRule{Name: "upstream", Enabled: true, GroupIDs: []int64{3}, ErrorClasses: []string{OpsPayloadCaptureErrorClassUpstream}},
+	)
+	require.True(t, svc.Active())
+
+	rule := svc.Match(&OpsPayloadCaptureMatchInput{UserID: 7, StatusCode: 502})
+	require.NotNil(t, rule)
+	require.Equal(t, "user-5xx", rule.Name)
+
+	require.Nil(t, svc.Match(&OpsPayloadCaptureMatchInput{UserID: 8, StatusCode: 502}))
+
+	rule = svc.Match(&OpsPayloadCaptureMatchInput{Model: "Claude-Sonnet-4", StatusCode: 429})
+	require.NotNil(t, rule)
+	require.Equal(t, "model-429", rule.Name)
+
+	rule = svc.Match(&OpsPayloadCaptureMatchInput{GroupID: 3, StatusCode: 200, UpstreamError: true})
+	require.NotNil(t, rule)
+	require.Equal(t, "upstream", rule.Name)
+
+	require.Nil(t, svc.Match(&OpsPayloadCaptureMatchInput{GroupID: 3, StatusCode: 200}))
+}
+
+func TestPayloadCaptureService_SampleRate(t *testing.T) {
+	tiny := &OpsPayloadCaptureRule{Name: "tiny", Enabled: true, SampleRate: 1e-12}
+	svc := newTestPayloadCaptureService(nil, newMemoryPayloadCaptureStore(), tiny)
+	for i := 0; i < 100; i++ {
+		require.Nil(t, svc.Match(&OpsPayloadCaptureMatchInput{StatusCode: 200}))
+	}
+
+	// 未设置抽样比例时默认全量
+	all := &OpsPayloadCaptureRule{Name: "all", Enabled: true}
+	svc = newTestPayloadCaptureService(nil, newMemoryPayloadCaptureStore(), all)
+	require.Equal(t, 1.0, all.SampleRate)
+	require.NotNil(t, svc.Match(&OpsPayloadCaptureMatchInput{StatusCode: 200}))
+}
+
+func TestPayloadCaptureService_InactiveWhenDisabled(t *testing.T) {
+	svc := newTestPayloadCaptureService(nil, newMemoryPayloadCaptureStore(), &OpsPayloadCaptureRule{Name: "a", Enabled: true})
+	svc.settings.Store(&OpsPayloadCaptureSettings{Enabled: false, Rules: []*OpsPayloadCaptureRule{{Name: "a", Enabled: true, SampleRate: 1}}})
+	require.False(t, svc.Active())
+	require.Nil(t, svc.Match(&OpsPayloadCaptureMatchInput{StatusCode: 500}))
+
+	var nilSvc *PayloadCaptureService
+	require.False(t, nilSvc.Active())
+	nilSvc.Stop()
+}
+
+func TestValidateOpsPayloadCaptureSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings *OpsPayloadCaptureSettings
+		wantErr  bool
+	}{
+		{name: "ok", settings: &OpsPayloadCaptureSettings{Enabled: true, Rules: []*OpsPayloadCaptureRule{{Name: "a", SampleRate: 0.5, StatusCodes: []int{500}}}}},
+		{name: "missing name", settings: &OpsPayloadCaptureSettings{Rules: []*OpsPayloadCaptureRule{{SampleRate: 1}}}, wantErr: true},
+		{name: "duplicate name", settings: &OpsPayloadCaptureSettings{Rules: []*OpsPayloadCaptureRule{{Name: "a"}, {Name: "a"}}}, wantErr: true},
+		{name: "sample rate", settings: &OpsPayloadCaptureSettings{Rules: []*OpsPayloadCaptureRule{{Name: "a", SampleRate: 1.5}}}, wantErr: true},
+		{name: "status code", settings: &OpsPayloadCaptureSettings{Rules: []*OpsPayloadCaptureRule{{Name: "a", StatusCodes: []int{700}}}}, wantErr: true},
+		{name: "error class", settings: &OpsPayloadCaptureSettings{Rules: []*OpsPayloadCaptureRule{{Name: "a", ErrorClasses: []string{"timeout"}}}}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			normalizeOpsPayloadCaptureSettings(tc.settings)
+			err := validateOpsPayloadCaptureSettings(tc.settings)
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Equal(t, http.StatusBadRequest, infraerrors.Code(err))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildOpsPayloadCaptureObject_RedactsAndTruncates(t *testing.T) {
+	in := &OpsPayloadCaptureInput{
+		ClientRequestID: "req-1",
+		RuleName:        "debug",
+		Method:          http.MethodPost,
+		RequestPath:     "/v1/messages",
+		StatusCode:      502,
+		Stream:          true,
+		RequestHeaders: http.Header{
+			"Authorization": []string{"Bearer sk-ant-REDACTED"},
+			"Cookie":        []string{"session=secret"},
+			"Content-Type":  []string{"application/json"},
+		},
+		RequestBody:         []byte(`{"model":"claude","api_key":"sk-live-123456","messages":[{"content":"mail me at alice@example.com"}]}`),
+		UpstreamRequestBody: []byte(`{"model":"claude-upstream"}`),
+		ResponseHeaders:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		ResponseBody:        []byte("event: error\ndata: {\"error\":\"token sk-abcdefghijklmnopqrstuv rejected\"}\n\n" + strings.Repeat("x", 64)),
+		CapturedAt:          time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	payload, truncated, err := buildOpsPayloadCaptureObject("cap-1", in, 1024)
+	require.NoError(t, err)
+	require.False(t, truncated)
+
+	doc := decodeTestPayloadCapture(t, payload)
+	require.Equal(t, "cap-1", doc["id"])
+	require.Equal(t, "req-1", doc["client_request_id"])
+
+	request := doc["request"].(map[string]any)
+	headers := request["headers"].(map[string]any)
+	require.NotContains(t, headers["Authorization"], "abcdefghijklmnop")
+	require.NotContains(t, headers["Cookie"], "secret")
+	require.Equal(t, "application/json", headers["Content-Type"])
+
+	body := request["body"].(map[string]any)
+	require.Equal(t, "claude", body["model"])
+	require.NotEqual(t, "sk-live-123456", body["api_key"])
+	encoded, _ := json.Marshal(body)
+	require.NotContains(t, string(encoded), "alice@example.com")
+
+	upstream := doc["upstream_request"].(map[string]any)
+	require.Equal(t, "claude-upstream", upstream["body"].(map[string]any)["model"])
+
+	response := doc["response"].(map[string]any)
+	require.Equal(t, true, response["stream"])
+	require.NotContains(t, response["body"], "sk-abcdefghijklmnopqrstuv")
+
+	_, truncated, err = buildOpsPayloadCaptureObject("cap-2", in, 16)
+	require.NoError(t, err)
+	require.True(t, truncated)
+}
+
+func TestPayloadCaptureService_PersistOpenAndCleanup(t *testing.T) {
+	var inserted *OpsPayloadCapture
+	var deleted []string
+	repo := &opsRepoMock{
+		InsertPayloadCaptureFn: func(ctx context.Context, input *OpsPayloadCapture) error {
+			inserted = input
+			return nil
+		},
+		GetPayloadCaptureByIDFn: func(ctx context.Context, id string) (*OpsPayloadCapture, error) {
+			if inserted == nil || inserted.ID != id {
+				return nil, nil
+			}
+			return inserted, nil
+		},
+		ListExpiredPayloadCapturesFn: func(ctx context.Context, before time.Time, limit int) ([]*OpsPayloadCapture, error) {
+			if inserted == nil || len(deleted) > 0 || inserted.ExpiresAt.After(before) {
+				return nil, nil
+			}
+			return []*OpsPayloadCapture{inserted}, nil
+		},
+		DeletePayloadCapturesFn: func(ctx context.Context, ids []string) error {
+			deleted = append(deleted, ids...)
+			return nil
+		},
+	}
+	store := newMemoryPayloadCaptureStore()
+	svc := newTestPayloadCaptureService(repo, store, &OpsPayloadCaptureRule{Name: "all", Enabled: true})
+
+	capturedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	id, ok := svc.Submit(&OpsPayloadCaptureInput{
+		ClientRequestID: "req-9",
+		RuleName:        "all",
+		StatusCode:      200,
+		RequestBody:     []byte(`{"model":"gpt"}`),
+		ResponseBody:    []byte(`{"ok":true}`),
+		CapturedAt:      capturedAt,
+	})
+	require.True(t, ok)
+	svc.persist(<-svc.queue)
+
+	require.NotNil(t, inserted)
+	require.Equal(t, id, inserted.ID)
+	require.Equal(t, "2026/03/04/"+id+".json.gz", inserted.StorageKey)
+	require.Equal(t, capturedAt.Add(2*time.Hour), inserted.ExpiresAt)
+	require.Positive(t, inserted.SizeBytes)
+
+	rc, err := svc.OpenCaptureContent(context.Background(), id)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Contains(t, string(raw), `"client_request_id":"req-9"`)
+
+	_, err = svc.GetCapture(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrPayloadCaptureNotFound)
+
+	require.Equal(t, 0, svc.cleanupExpired(capturedAt.Add(time.Hour)))
+	require.Equal(t, 1, svc.cleanupExpired(capturedAt.Add(3*time.Hour)))
+	require.Equal(t, []string{id}, deleted)
+	require.Empty(t, store.objects)
+}
+
+func TestPayloadCaptureService_CleanupKeepsMetadataWhenObjectDeleteFails(t *testing.T) {
+	deleteCalled := false
+	repo := &opsRepoMock{
+		ListExpiredPayloadCapturesFn: func(ctx context.Context, before time.Time, limit int) ([]*OpsPayloadCapture, error) {
+			return []*OpsPayloadCapture{{ID: "a", StorageKey: "k"}}, nil
+		},
+		DeletePayloadCapturesFn: func(ctx context.Context, ids []string) error {
+			deleteCalled = true
+			return nil
+		},
+	}
+	store := newMemoryPayloadCaptureStore()
+	store.deleteErr = errors.New("s3 down")
+	svc := newTestPayloadCaptureService(repo, store)
+
+	require.Equal(t, 0, svc.cleanupExpired(time.Now()))
+	require.False(t, deleteCalled)
+}
+
+func decodeTestPayloadCapture(t *testing.T, payload []byte) map[string]any {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	return doc
+}
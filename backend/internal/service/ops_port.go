@@ -59,6 +59,15 @@ type OpsRepository interface {
 	UpdateAlertEventStatus(ctx context.Context, eventID int64, status string, resolvedAt *time.Time) error
 	UpdateAlertEventEmailSent(ctx context.Context, eventID int64, emailSent bool) error
 
+	// Notification channels + delivery log
+	ListNotificationChannels(ctx context.Context) ([]*OpsNotificationChannel, error)
+	GetNotificationChannelByID(ctx context.Context, id int64) (*OpsNotificationChannel, error)
+	CreateNotificationChannel(ctx context.Context, input *OpsNotificationChannel) (*OpsNotificationChannel, error)
+	UpdateNotificationChannel(ctx context.Context, input *OpsNotificationChannel) (*OpsNotificationChannel, error)
+	DeleteNotificationChannel(ctx context.Context, id int64) error
+	InsertNotificationDelivery(ctx context.Context, input *OpsNotificationDelivery) (int64, error)
+	ListNotificationDeliveries(ctx context.Context, filter *OpsNotificationDeliveryFilter) ([]*OpsNotificationDelivery, error)
+
 	// Alert silences
 	CreateAlertSilence(ctx context.Context, input *OpsAlertSilence) (*OpsAlertSilence, error)
 	IsAlertSilenced(ctx context.Context, ruleID int64, platform string, groupID *int64, region *string, now time.Time) (bool, error)
@@ -68,6 +77,30 @@ type OpsRepository interface {
 	UpsertDailyMetrics(ctx context.Context, startTime, endTime time.Time) error
 	GetLatestHourlyBucketStart(ctx context.Context) (time.Time, bool, error)
 	GetLatestDailyBucketDate(ctx context.Context) (time.Time, bool, error)
+
+	// Baseline samples for anomaly alert metrics (each window covers [start, start+1h)).
+	ListHourlyBaselineSamples(ctx context.Context, windowStarts []time.Time, platform string, groupID *int64) ([]*OpsHourlyBaselineSample, error)
+	ListUsageCostBaselineSamples(ctx context.Context, windowStarts []time.Time) ([]*OpsUsageCostBaselineSample, error)
+
+	// Payload capture metadata (payload objects live in PayloadCaptureStore).
+	InsertPayloadCapture(ctx context.Context, input *OpsPayloadCapture) error
+	GetPayloadCaptureByID(ctx context.Context, id string) (*OpsPayloadCapture, error)
+	ListExpiredPayloadCaptures(ctx context.Context, before time.Time, limit int) ([]*OpsPayloadCapture, error)
+	DeletePayloadCaptures(ctx context.Context, ids []string) error
+
+	// Customer SLA & public status page.
+	GetCustomerSLAStats(ctx context.Context, filter *OpsCustomerSLAFilter) (*OpsCustomerSLAStats, error)
+	ListStatusModelStats(ctx context.Context, since time.Time) ([]*OpsStatusModelStat, error)
+	ListMaintenanceWindows(ctx context.Context, filter *OpsMaintenanceWindowFilter) ([]*OpsMaintenanceWindow, error)
+	GetMaintenanceWindowByID(ctx context.Context, id int64) (*OpsMaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error)
+	DeleteMaintenanceWindow(ctx context.Context, id int64) error
+
+	// Finance reports (scheduled CSV/XLSX exports), range is [start, end).
+	ListUserSpendReport(ctx context.Context, start, end time.Time) ([]*OpsUserSpendReportRow, error)
+	ListGroupRevenueReport(ctx context.Context, start, end time.Time) ([]*OpsGroupRevenueReportRow, error)
+	ListAccountUtilizationReport(ctx context.Context, start, end time.Time) ([]*OpsAccountUtilizationReportRow, error)
 }
 
 type OpsInsertErrorLogInput struct {
@@ -7,14 +7,33 @@ import (
 
 // opsRepoMock is a test-only OpsRepository implementation with optional function hooks.
 type opsRepoMock struct {
-	InsertErrorLogFn              func(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error)
-	BatchInsertErrorLogsFn        func(ctx context.Context, inputs []*OpsInsertErrorLogInput) (int64, error)
-	ListRequestTracesByKeyFn      func(ctx context.Context, key string, keyType string, limit int) ([]*OpsRequestTrace, error)
-	UpsertRequestTraceFn          func(ctx context.Context, trace *OpsRequestTrace) error
-	BatchInsertSystemLogsFn       func(ctx context.Context, inputs []*OpsInsertSystemLogInput) (int64, error)
-	ListSystemLogsFn              func(ctx context.Context, filter *OpsSystemLogFilter) (*OpsSystemLogList, error)
-	DeleteSystemLogsFn            func(ctx context.Context, filter *OpsSystemLogCleanupFilter) (int64, error)
-	InsertSystemLogCleanupAuditFn func(ctx context.Context, input *OpsSystemLogCleanupAudit) error
+	InsertErrorLogFn               func(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error)
+	BatchInsertErrorLogsFn         func(ctx context.Context, inputs []*OpsInsertErrorLogInput) (int64, error)
+	ListRequestTracesByKeyFn       func(ctx context.Context, key string, keyType string, limit int) ([]*OpsRequestTrace, error)
+	UpsertRequestTraceFn           func(ctx context.Context, trace *OpsRequestTrace) error
+	BatchInsertSystemLogsFn        func(ctx context.Context, inputs []*OpsInsertSystemLogInput) (int64, error)
+	ListSystemLogsFn               func(ctx context.Context, filter *OpsSystemLogFilter) (*OpsSystemLogList, error)
+	DeleteSystemLogsFn             func(ctx context.Context, filter *OpsSystemLogCleanupFilter) (int64, error)
+	InsertSystemLogCleanupAuditFn  func(ctx context.Context, input *OpsSystemLogCleanupAudit) error
+	ListNotificationChannelsFn     func(ctx context.Context) ([]*OpsNotificationChannel, error)
+	InsertNotificationDeliveryFn   func(ctx context.Context, input *OpsNotificationDelivery) (int64, error)
+	ListHourlyBaselineSamplesFn    func(ctx context.Context, windowStarts []time.Time, platform string, groupID *int64) ([]*OpsHourlyBaselineSample, error)
+	ListUsageCostBaselineSamplesFn func(ctx context.Context, windowStarts []time.Time) ([]*OpsUsageCostBaselineSample, error)
+	InsertPayloadCaptureFn         func(ctx context.Context, input *OpsPayloadCapture) error
+	GetPayloadCaptureByIDFn        func(ctx context.Context, id string) (*OpsPayloadCapture, error)
+	ListExpiredPayloadCapturesFn   func(ctx context.Context, before time.Time, limit int) ([]*OpsPayloadCapture, error)
+	DeletePayloadCapturesFn        func(ctx context.Context, ids []string) error
+	ListAlertEventsFn              func(ctx context.Context, filter *OpsAlertEventFilter) ([]*OpsAlertEvent, error)
+	GetCustomerSLAStatsFn          func(ctx context.Context, filter *OpsCustomerSLAFilter) (*OpsCustomerSLAStats, error)
+	ListStatusModelStatsFn         func(ctx context.Context, since time.Time) ([]*OpsStatusModelStat, error)
+	ListMaintenanceWindowsFn       func(ctx context.Context, filter *OpsMaintenanceWindowFilter) ([]*OpsMaintenanceWindow, error)
+	GetMaintenanceWindowByIDFn     func(ctx context.Context, id int64) (*OpsMaintenanceWindow, error)
+	CreateMaintenanceWindowFn      func(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error)
+	UpdateMaintenanceWindowFn      func(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error)
+	DeleteMaintenanceWindowFn      func(ctx context.Context, id int64) error
+	ListUserSpendReportFn          func(ctx context.Context, start, end time.Time) ([]*OpsUserSpendReportRow, error)
+	ListGroupRevenueReportFn       func(ctx context.Context, start, end time.Time) ([]*OpsGroupRevenueReportRow, error)
+	ListAccountUtilizationReportFn func(ctx context.Context, start, end time.Time) ([]*OpsAccountUtilizationReportRow, error)
 }
 
 func (m *opsRepoMock) InsertErrorLog(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error) {
@@ -182,6 +201,9 @@ func (m *opsRepoMock) DeleteAlertRule(ctx context.Context, id int64) error {
 }
 
 func (m *opsRepoMock) ListAlertEvents(ctx context.Context, filter *OpsAlertEventFilter) ([]*OpsAlertEvent, error) {
+	if m.ListAlertEventsFn != nil {
+		return m.ListAlertEventsFn(ctx, filter)
+	}
 	return []*OpsAlertEvent{}, nil
 }
 
@@ -209,6 +231,40 @@ func (m *opsRepoMock) UpdateAlertEventEmailSent(ctx context.Context, eventID int
 	return nil
 }
 
+func (m *opsRepoMock) ListNotificationChannels(ctx context.Context) ([]*OpsNotificationChannel, error) {
+	if m.ListNotificationChannelsFn != nil {
+		return m.ListNotificationChannelsFn(ctx)
+	}
+	return []*OpsNotificationChannel{}, nil
+}
+
+func (m *opsRepoMock) GetNotificationChannelByID(ctx context.Context, id int64) (*OpsNotificationChannel, error) {
+	return nil, nil
+}
+
+func (m *opsRepoMock) CreateNotificationChannel(ctx context.Context, input *OpsNotificationChannel) (*OpsNotificationChannel, error) {
+	return input, nil
+}
+
+func (m *opsRepoMock) UpdateNotificationChannel(ctx context.Context, input *OpsNotificationChannel) (*OpsNotificationChannel, error) {
+	return input, nil
+}
+
+func (m *opsRepoMock) DeleteNotificationChannel(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *opsRepoMock) InsertNotificationDelivery(ctx context.Context, input *OpsNotificationDelivery) (int64, error) {
+	if m.InsertNotificationDeliveryFn != nil {
+		return m.InsertNotificationDeliveryFn(ctx, input)
+	}
+	return 0, nil
+}
+
+func (m *opsRepoMock) ListNotificationDeliveries(ctx context.Context, filter *OpsNotificationDeliveryFilter) ([]*OpsNotificationDelivery, error) {
+	return []*OpsNotificationDelivery{}, nil
+}
+
 func (m *opsRepoMock) CreateAlertSilence(ctx context.Context, input *OpsAlertSilence) (*OpsAlertSilence, error) {
 	return input, nil
 }
@@ -234,3 +290,115 @@ func (m *opsRepoMock) GetLatestDailyBucketDate(ctx context.Context) (time.Time,
 }
 
 var _ OpsRepository = (*opsRepoMock)(nil)
+
+func (m *opsRepoMock) ListHourlyBaselineSamples(ctx context.Context, windowStarts []time.Time, platform string, groupID *int64) ([]*OpsHourlyBaselineSample, error) {
+	if m.ListHourlyBaselineSamplesFn != nil {
+		return m.ListHourlyBaselineSamplesFn(ctx, windowStarts, platform, groupID)
+	}
+	return []*OpsHourlyBaselineSample{}, nil
+}
+
+func (m *opsRepoMock) ListUsageCostBaselineSamples(ctx context.Context, windowStarts []time.Time) ([]*OpsUsageCostBaselineSample, error) {
+	if m.ListUsageCostBaselineSamplesFn != nil {
+		return m.ListUsageCostBaselineSamplesFn(ctx, windowStarts)
+	}
+	return []*OpsUsageCostBaselineSample{}, nil
+}
+
+func (m *opsRepoMock) InsertPayloadCapture(ctx context.Context, input *OpsPayloadCapture) error {
+	if m.InsertPayloadCaptureFn != nil {
+		return m.InsertPayloadCaptureFn(ctx, input)
+	}
+	return nil
+}
+
+func (m *opsRepoMock) GetPayloadCaptureByID(ctx context.Context, id string) (*OpsPayloadCapture, error) {
+	if m.GetPayloadCaptureByIDFn != nil {
+		return m.GetPayloadCaptureByIDFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *opsRepoMock) ListExpiredPayloadCaptures(ctx context.Context, before time.Time, limit int) ([]*OpsPayloadCapture, error) {
+	if m.ListExpiredPayloadCapturesFn != nil {
+		return m.ListExpiredPayloadCapturesFn(ctx, before, limit)
+	}
+	return []*OpsPayloadCapture{}, nil
+}
+
+func (m *opsRepoMock) DeletePayloadCaptures(ctx context.Context, ids []string) error {
+	if m.DeletePayloadCapturesFn != nil {
+		return m.DeletePayloadCapturesFn(ctx, ids)
+	}
+	return nil
+}
+
+func (m *opsRepoMock) GetCustomerSLAStats(ctx context.Context, filter *OpsCustomerSLAFilter) (*OpsCustomerSLAStats, error) {
+	if m.GetCustomerSLAStatsFn != nil {
+		return m.GetCustomerSLAStatsFn(ctx, filter)
+	}
+	return &OpsCustomerSLAStats{}, nil
+}
+
+func (m *opsRepoMock) ListStatusModelStats(ctx context.Context, since time.Time) ([]*OpsStatusModelStat, error) {
+	if m.ListStatusModelStatsFn != nil {
+		return m.ListStatusModelStatsFn(ctx, since)
+	}
+	return []*OpsStatusModelStat{}, nil
+}
+
+func (m *opsRepoMock) ListMaintenanceWindows(ctx context.Context, filter *OpsMaintenanceWindowFilter) ([]*OpsMaintenanceWindow, error) {
+	if m.ListMaintenanceWindowsFn != nil {
+		return m.ListMaintenanceWindowsFn(ctx, filter)
+	}
+	return []*OpsMaintenanceWindow{}, nil
+}
+
+func (m *opsRepoMock) GetMaintenanceWindowByID(ctx context.Context, id int64) (*OpsMaintenanceWindow, error) {
+	if m.GetMaintenanceWindowByIDFn != nil {
+		return m.GetMaintenanceWindowByIDFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *opsRepoMock) CreateMaintenanceWindow(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error) {
+	if m.CreateMaintenanceWindowFn != nil {
+		return m.CreateMaintenanceWindowFn(ctx, input)
+	}
+	return input, nil
+}
+
+func (m *opsRepoMock) UpdateMaintenanceWindow(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error) {
+	if m.UpdateMaintenanceWindowFn != nil {
+		return m.UpdateMaintenanceWindowFn(ctx, input)
+	}
+	return input, nil
+}
+
+func (m *opsRepoMock) DeleteMaintenanceWindow(ctx context.Context, id int64) error {
+	if m.DeleteMaintenanceWindowFn != nil {
+		return m.DeleteMaintenanceWindowFn(ctx, id)
+	}
+	return nil
+}
+
+func (m *opsRepoMock) ListUserSpendReport(ctx context.Context, start, end time.Time) ([]*OpsUserSpendReportRow, error) {
+	if m.ListUserSpendReportFn != nil {
+		return m.ListUserSpendReportFn(ctx, start, end)
+	}
+	return []*OpsUserSpendReportRow{}, nil
+}
+
+func (m *opsRepoMock) ListGroupRevenueReport(ctx context.Context, start, end time.Time) ([]*OpsGroupRevenueReportRow, error) {
+	if m.ListGroupRevenueReportFn != nil {
+		return m.ListGroupRevenueReportFn(ctx, start, end)
+	}
+	return []*OpsGroupRevenueReportRow{}, nil
+}
+
+func (m *opsRepoMock) ListAccountUtilizationReport(ctx context.Context, start, end time.Time) ([]*OpsAccountUtilizationReportRow, error) {
+	if m.ListAccountUtilizationReportFn != nil {
+		return m.ListAccountUtilizationReportFn(ctx, start, end)
+	}
+	return []*OpsAccountUtilizationReportRow{}, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	opsReportCSVContentType  = "text/csv; charset=UTF-8"
+	opsReportXLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	opsReportXLSXSheetNameMaxLen = 31
+)
+
+// buildOpsReportAttachment 将报表明细渲染为 CSV 或 XLSX 附件
+func buildOpsReportAttachment(data *OpsReportData, format string) (*EmailAttachment, error) {
+	if data == nil {
+		return nil, fmt.Errorf("empty report data")
+	}
+	base := opsReportFileBaseName(data)
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", OpsReportAttachmentCSV:
+		raw, err := renderOpsReportCSV(data)
+		if err != nil {
+			return nil, err
+		}
+		return &EmailAttachment{FileName: base + ".csv", ContentType: opsReportCSVContentType, Data: raw}, nil
+	case OpsReportAttachmentXLSX:
+		raw, err := renderOpsReportXLSX(data)
+		if err != nil {
+			return nil, err
+		}
+		return &EmailAttachment{FileName: base + ".xlsx", ContentType: opsReportXLSXContentType, Data: raw}, nil
+	default:
+		return nil, fmt.Errorf("unsupported attachment format: %s", format)
+	}
+}
+
+// opsReportFileBaseName 形如 user_spend_20260901-20260930（结束日期为闭区间）
+func opsReportFileBaseName(data *OpsReportData) string {
+	lastDay := data.PeriodEnd.Add(-time.Second)
+	if lastDay.Before(data.PeriodStart) {
+		lastDay = data.PeriodStart
+	}
+	return fmt.Sprintf("%s_%s-%s", data.ReportType, data.PeriodStart.Format("20060102"), lastDay.Format("20060102"))
+}
+
+// renderOpsReportCSV 输出带 UTF-8 BOM 的 CSV，便于 Excel 直接打开中文内容
+func renderOpsReportCSV(data *OpsReportData) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	if err := w.Write(data.Columns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(data.Columns))
+	for _, row := range data.Rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = formatOpsReportCell(row[i])
+				if _, ok := row[i].(string); ok {
+					record[i] = escapeOpsReportCSVFormula(record[i])
+				}
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeOpsReportCSVFormula 防止用户名等文本以 = + - @ 开头时被表格软件当作公式执行
+func escapeOpsReportCSVFormula(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func formatOpsReportCell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case int:
+		return strconv.Itoa(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// renderOpsReportXLSX 生成单工作表的最小 SpreadsheetML 文档（字符串使用 inlineStr，无共享字符串表）
+func renderOpsReportXLSX(data *OpsReportData) ([]byte, error) {
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	writeRow := func(rowIdx int, cells []any) error {
+		fmt.Fprintf(&sheet, `<row r="%d">`, rowIdx)
+		for colIdx, v := range cells {
+			ref := opsXLSXColumnName(colIdx) + strconv.Itoa(rowIdx)
+			switch t := v.(type) {
+			case nil:
+				continue
+			case int, int64, float64:
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, formatOpsReportCell(t))
+			default:
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				if err := xml.EscapeText(&sheet, []byte(formatOpsReportCell(t))); err != nil {
+					return err
+				}
+				sheet.WriteString(`</t></is></c>`)
+			}
+		}
+		sheet.WriteString(`</row>`)
+		return nil
+	}
+
+	header := make([]any, len(data.Columns))
+	for i, c := range data.Columns {
+		header[i] = c
+	}
+	if err := writeRow(1, header); err != nil {
+		return nil, err
+	}
+	for i, row := range data.Rows {
+		if err := writeRow(i+2, row); err != nil {
+			return nil, err
+		}
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var sheetName bytes.Buffer
+	if err := xml.EscapeText(&sheetName, []byte(opsXLSXSheetName(data.ReportType))); err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + sheetName.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(f.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// opsXLSXColumnName 0 -> A, 25 -> Z, 26 -> AA
+func opsXLSXColumnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+// opsXLSXSheetName 工作表名不得超过 31 字符且不能包含 []:*?/\
+func opsXLSXSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '[', ']', ':', '*', '?', '/', '\\':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "report"
+	}
+	if len(name) > opsReportXLSXSheetNameMaxLen {
+		name = name[:opsReportXLSXSheetNameMaxLen]
+	}
+	return name
+}
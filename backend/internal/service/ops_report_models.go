@@ -0,0 +1,99 @@
+package service
+
+import "time"
+
+// 定时报表类型（HTML 摘要类）
+const (
+	OpsReportTypeDailySummary  = "daily_summary"
+	OpsReportTypeWeeklySummary = "weekly_summary"
+	OpsReportTypeErrorDigest   = "error_digest"
+	OpsReportTypeAccountHealth = "account_health"
+)
+
+// 定时报表类型（财务明细类，附带 CSV/XLSX 附件）
+const (
+	OpsReportTypeUserSpend          = "user_spend"
+	OpsReportTypeGroupRevenue       = "group_revenue"
+	OpsReportTypeAccountUtilization = "account_utilization"
+)
+
+// 财务报表附件格式
+const (
+	OpsReportAttachmentCSV  = "csv"
+	OpsReportAttachmentXLSX = "xlsx"
+)
+
+// 财务报表统计周期：按配置时区取上一个完整的自然日/周/月
+const (
+	OpsReportPeriodDay   = "day"
+	OpsReportPeriodWeek  = "week"
+	OpsReportPeriodMonth = "month"
+)
+
+// OpsUserSpendReportRow 用户消费报表行
+type OpsUserSpendReportRow struct {
+	UserID   int64
+	Email    string
+	Username string
+
+	Requests            int64
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+
+	// StandardCost 标准价（未乘倍率），ActualCost 实际扣费
+	StandardCost float64
+	ActualCost   float64
+}
+
+// OpsGroupRevenueReportRow 分组收入与上游成本报表行
+type OpsGroupRevenueReportRow struct {
+	GroupID   int64
+	GroupName string
+	Platform  string
+
+	Requests    int64
+	TotalTokens int64
+
+	// Revenue 向用户实际收取的费用；UpstreamCost 按账号倍率折算的上游成本
+	Revenue      float64
+	StandardCost float64
+	UpstreamCost float64
+}
+
+// OpsAccountUtilizationReportRow 账号利用率报表行
+type OpsAccountUtilizationReportRow struct {
+	AccountID   int64
+	AccountName string
+	Platform    string
+	Concurrency int
+
+	Requests     int64
+	ErrorCount   int64
+	TotalTokens  int64
+	UpstreamCost float64
+
+	// ActiveHours 周期内至少有一次成功请求的小时数
+	ActiveHours int64
+}
+
+// OpsReportData 财务报表的表格明细，CSV/XLSX 附件与 webhook 渠道的 data 字段共用
+type OpsReportData struct {
+	ReportType  string    `json:"report_type"`
+	Name        string    `json:"name"`
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Timezone    string    `json:"timezone"`
+
+	Columns []string `json:"columns"`
+	// Rows 每行与 Columns 一一对应，单元格为 string/int64/float64
+	Rows [][]any `json:"rows"`
+	// Summary 周期汇总（如总收入、总请求数）
+	Summary map[string]float64 `json:"summary"`
+
+	FileName string `json:"file_name,omitempty"`
+	// S3Key 开启 upload_to_s3 且上传成功时为附件的对象 key
+	S3Key string `json:"s3_key,omitempty"`
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// opsFinanceReportScheduleDefault 每月 1 日 08:00 发送上一周期明细
+	opsFinanceReportScheduleDefault = "0 8 1 * *"
+
+	opsFinanceReportS3Category       = "ops-reports"
+	opsFinanceReportEmailPreviewRows = 20
+)
+
+// opsReportArtifactUploader 报表附件上传到对象存储（由 BackupService 复用备份的 S3 配置实现）
+type opsReportArtifactUploader interface {
+	UploadArtifact(ctx context.Context, category, fileName string, body io.Reader, contentType string) (string, error)
+}
+
+func isOpsFinanceReportType(kind string) bool {
+	switch strings.TrimSpace(kind) {
+	case OpsReportTypeUserSpend, OpsReportTypeGroupRevenue, OpsReportTypeAccountUtilization:
+		return true
+	default:
+		return false
+	}
+}
+
+// opsFinanceReportWindow 返回 now 之前最近一个完整周期 [start, end)，按 now 所在时区切分；周以周一为起点
+func opsFinanceReportWindow(period string, now time.Time) (time.Time, time.Time) {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	switch strings.TrimSpace(period) {
+	case OpsReportPeriodDay:
+		return today.AddDate(0, 0, -1), today
+	case OpsReportPeriodWeek:
+		offset := (int(today.Weekday()) + 6) % 7
+		end := today.AddDate(0, 0, -offset)
+		return end.AddDate(0, 0, -7), end
+	default:
+		end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return end.AddDate(0, -1, 0), end
+	}
+}
+
+func (s *OpsScheduledReportService) generateFinanceReport(ctx context.Context, report *opsScheduledReport, now time.Time) (*OpsReportData, error) {
+	if s == nil || s.opsService == nil || s.opsService.opsRepo == nil || report == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	repo := s.opsService.opsRepo
+
+	start, end := opsFinanceReportWindow(report.Period, now)
+	data := &OpsReportData{
+		ReportType:  report.ReportType,
+		Name:        report.Name,
+		Period:      report.Period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Timezone:    now.Location().String(),
+	}
+
+	switch strings.TrimSpace(report.ReportType) {
+	case OpsReportTypeUserSpend:
+		rows, err := repo.ListUserSpendReport(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		fillOpsUserSpendReportData(data, rows)
+	case OpsReportTypeGroupRevenue:
+		rows, err := repo.ListGroupRevenueReport(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		fillOpsGroupRevenueReportData(data, rows)
+	case OpsReportTypeAccountUtilization:
+		rows, err := repo.ListAccountUtilizationReport(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		fillOpsAccountUtilizationReportData(data, rows)
+	default:
+		return nil, fmt.Errorf("unknown report type: %s", report.ReportType)
+	}
+	return data, nil
+}
+
+func fillOpsUserSpendReportData(data *OpsReportData, rows []*OpsUserSpendReportRow) {
+	data.Columns = []string{
+		"user_id", "email", "username", "requests",
+		"input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+		"standard_cost", "actual_cost",
+	}
+	data.Rows = make([][]any, 0, len(rows))
+	var requests int64
+	var standard, actual float64
+	for _, r := range rows {
+		if r == nil {
+			continue
+		}
+		requests += r.Requests
+		standard += r.StandardCost
+		actual += r.ActualCost
+		data.Rows = append(data.Rows, []any{
+			r.UserID, r.Email, r.Username, r.Requests,
+			r.InputTokens, r.OutputTokens, r.CacheCreationTokens, r.CacheReadTokens,
+			roundTo6DP(r.StandardCost), roundTo6DP(r.ActualCost),
+		})
+	}
+	data.Summary = map[string]float64{
+		"users":         float64(len(data.Rows)),
+		"requests":      float64(requests),
+		"standard_cost": roundTo6DP(standard),
+		"actual_cost":   roundTo6DP(actual),
+	}
+}
+
+func fillOpsGroupRevenueReportData(data *OpsReportData, rows []*OpsGroupRevenueReportRow) {
+	data.Columns = []string{
+		"group_id", "group_name", "platform", "requests", "total_tokens",
+		"revenue", "standard_cost", "upstream_cost", "gross_profit", "gross_margin_pct",
+	}
+	data.Rows = make([][]any, 0, len(rows))
+	var revenue, upstream float64
+	for _, r := range rows {
+		if r == nil {
+			continue
+		}
+		revenue += r.Revenue
+		upstream += r.UpstreamCost
+		profit := r.Revenue - r.UpstreamCost
+		data.Rows = append(data.Rows, []any{
+			r.GroupID, r.GroupName, r.Platform, r.Requests, r.TotalTokens,
+			roundTo6DP(r.Revenue), roundTo6DP(r.StandardCost), roundTo6DP(r.UpstreamCost),
+			roundTo6DP(profit), opsGrossMarginPct(r.Revenue, profit),
+		})
+	}
+	data.Summary = map[string]float64{
+		"revenue":          roundTo6DP(revenue),
+		"upstream_cost":    roundTo6DP(upstream),
+		"gross_profit":     roundTo6DP(revenue - upstream),
+		"gross_margin_pct": opsGrossMarginPct(revenue, revenue-upstream),
+	}
+}
+
+func fillOpsAccountUtilizationReportData(data *OpsReportData, rows []*OpsAccountUtilizationReportRow) {
+	data.Columns = []string{
+		"account_id", "account_name", "platform", "concurrency",
+		"requests", "error_count", "success_rate_pct", "total_tokens", "upstream_cost",
+		"active_hours", "utilization_pct",
+	}
+	periodHours := data.PeriodEnd.Sub(data.PeriodStart).Hours()
+	data.Rows = make([][]any, 0, len(rows))
+	var requests, errorCount int64
+	var upstream float64
+	for _, r := range rows {
+		if r == nil {
+			continue
+		}
+		requests += r.Requests
+		errorCount += r.ErrorCount
+		upstream += r.UpstreamCost
+
+		successRate := 0.0
+		if total := r.Requests + r.ErrorCount; total > 0 {
+			successRate = roundTo2DP(float64(r.Requests) / float64(total) * 100)
+		}
+		utilization := 0.0
+		if periodHours > 0 {
+			utilization = roundTo2DP(math.Min(float64(r.ActiveHours)/periodHours, 1) * 100)
+		}
+		data.Rows = append(data.Rows, []any{
+			r.AccountID, r.AccountName, r.Platform, r.Concurrency,
+			r.Requests, r.ErrorCount, successRate, r.TotalTokens, roundTo6DP(r.UpstreamCost),
+			r.ActiveHours, utilization,
+		})
+	}
+	data.Summary = map[string]float64{
+		"accounts":      float64(len(data.Rows)),
+		"requests":      float64(requests),
+		"error_count":   float64(errorCount),
+		"upstream_cost": roundTo6DP(upstream),
+	}
+}
+
+// opsGrossMarginPct 毛利率（百分比），收入为 0 时返回 0
+func opsGrossMarginPct(revenue, profit float64) float64 {
+	if revenue <= 0 {
+		return 0
+	}
+	return roundTo2DP(profit / revenue * 100)
+}
+
+func roundTo6DP(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// uploadReportAttachment 上传失败仅记录日志，不影响邮件与渠道投递
+func (s *OpsScheduledReportService) uploadReportAttachment(ctx context.Context, att *EmailAttachment) string {
+	if s == nil || s.artifactUploader == nil || att == nil {
+		return ""
+	}
+	key, err := s.artifactUploader.UploadArtifact(ctx, opsFinanceReportS3Category, att.FileName, bytes.NewReader(att.Data), att.ContentType)
+	if err != nil {
+		logger.LegacyPrintf("service.ops_scheduled_report", "[OpsScheduledReport] upload %s to S3 failed: %v", att.FileName, err)
+		return ""
+	}
+	return key
+}
+
+func buildOpsFinanceReportEmailHTML(data *OpsReportData) string {
+	if data == nil {
+		return ""
+	}
+
+	summaryKeys := make([]string, 0, len(data.Summary))
+	for k := range data.Summary {
+		summaryKeys = append(summaryKeys, k)
+	}
+	sort.Strings(summaryKeys)
+	summary := ""
+	for _, k := range summaryKeys {
+		summary += fmt.Sprintf("<li><b>%s</b>: %s</li>", htmlEscape(k), htmlEscape(formatOpsReportCell(data.Summary[k])))
+	}
+
+	var head strings.Builder
+	for _, c := range data.Columns {
+		head.WriteString("<th>" + htmlEscape(c) + "</th>")
+	}
+	preview := data.Rows
+	if len(preview) > opsFinanceReportEmailPreviewRows {
+		preview = preview[:opsFinanceReportEmailPreviewRows]
+	}
+	var body strings.Builder
+	for _, row := range preview {
+		body.WriteString("<tr>")
+		for _, cell := range row {
+			body.WriteString("<td>" + htmlEscape(formatOpsReportCell(cell)) + "</td>")
+		}
+		body.WriteString("</tr>")
+	}
+	if len(preview) == 0 {
+		fmt.Fprintf(&body, "<tr><td colspan=\"%d\">No data.</td></tr>", len(data.Columns))
+	}
+
+	attachment := ""
+	if data.FileName != "" {
+		attachment = fmt.Sprintf("<p><b>Attachment</b>: %s", htmlEscape(data.FileName))
+		if data.S3Key != "" {
+			attachment += fmt.Sprintf(" (S3: %s)", htmlEscape(data.S3Key))
+		}
+		attachment += "</p>"
+	}
+
+	return fmt.Sprintf(`
+<h2>%s</h2>
+<p><b>Period</b>: %s ~ %s (%s)</p>
+<p><b>Rows</b>: %d (showing first %d)</p>
+<ul>%s</ul>
+%s
+<table border="1" cellpadding="6" cellspacing="0" style="border-collapse:collapse;">
+  <thead><tr>%s</tr></thead>
+  <tbody>%s</tbody>
+</table>
+`,
+		htmlEscape(strings.TrimSpace(data.Name)),
+		htmlEscape(data.PeriodStart.Format(time.RFC3339)),
+		htmlEscape(data.PeriodEnd.Format(time.RFC3339)),
+		htmlEscape(data.Timezone),
+		len(data.Rows),
+		len(preview),
+		summary,
+		attachment,
+		head.String(),
+		body.String(),
+	)
+}
@@ -0,0 +1,248 @@
+//go:build unit
+
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type opsReportUploaderStub struct {
+	category string
+	fileName string
+	body     []byte
+	err      error
+}
+
+func (u *opsReportUploaderStub) UploadArtifact(_ context.Context, category, fileName string, body io.Reader, _ string) (string, error) {
+	if u.err != nil {
+		return "", u.err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	u.category, u.fileName, u.body = category, fileName, data
+	return category + "/" + fileName, nil
+}
+
+func TestOpsFinanceReportWindow(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	// 2026-10-15 是周四
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, loc)
+
+	start, end := opsFinanceReportWindow(OpsReportPeriodDay, now)
+	require.Equal(t, time.Date(2026, 10, 14, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, loc), end)
+
+	start, end = opsFinanceReportWindow(OpsReportPeriodWeek, now)
+	require.Equal(t, time.Date(2026, 10, 5, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, loc), end)
+
+	start, end = opsFinanceReportWindow(OpsReportPeriodMonth, now)
+	require.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, loc), end)
+
+	// 跨年
+	start, end = opsFinanceReportWindow(OpsReportPeriodMonth, time.Date(2027, 1, 1, 8, 0, 0, 0, loc))
+	require.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, loc), end)
+}
+
+func TestFillOpsGroupRevenueReportData(t *testing.T) {
+	data := &OpsReportData{}
+	fillOpsGroupRevenueReportData(data, []*OpsGroupRevenueReportRow{
+		{GroupID: 1, GroupName: "pro", Platform: "anthropic", Requests: 10, Revenue: 20, StandardCost: 10, UpstreamCost: 5},
+		{GroupID: 2, GroupName: "free", Platform: "openai", Requests: 3, Revenue: 0, UpstreamCost: 1},
+	})
+
+	require.Len(t, data.Rows, 2)
+	require.Len(t, data.Rows[0], len(data.Columns))
+	require.Equal(t, 15.0, data.Rows[0][8])
+	require.Equal(t, 75.0, data.Rows[0][9])
+	require.Equal(t, 0.0, data.Rows[1][9])
+	require.Equal(t, 20.0, data.Summary["revenue"])
+	require.Equal(t, 14.0, data.Summary["gross_profit"])
+	require.Equal(t, 70.0, data.Summary["gross_margin_pct"])
+}
+
+func TestFillOpsAccountUtilizationReportData(t *testing.T) {
+	data := &OpsReportData{
+		PeriodStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+	}
+	fillOpsAccountUtilizationReportData(data, []*OpsAccountUtilizationReportRow{
+		{AccountID: 7, AccountName: "a", Requests: 90, ErrorCount: 10, ActiveHours: 6},
+	})
+
+	require.Len(t, data.Rows, 1)
+	require.Equal(t, 90.0, data.Rows[0][6])
+	require.Equal(t, 25.0, data.Rows[0][10])
+}
+
+func TestRenderOpsReportCSV(t *testing.T) {
+	data := &OpsReportData{
+		Columns: []string{"user_id", "username", "actual_cost"},
+		Rows: [][]any{
+			{int64(1), "alice, inc", 1.5},
+			{int64(2), "=HYPERLINK(\"x\")", 0.000001},
+		},
+	}
+	raw, err := renderOpsReportCSV(data)
+	require.NoError(t, err)
+
+	text := string(raw)
+	require.True(t, strings.HasPrefix(text, "\ufeff"))
+	require.Contains(t, text, "user_id,username,actual_cost\n")
+	require.Contains(t, text, "1,\"alice, inc\",1.5\n")
+	require.Contains(t, text, "2,\"'=HYPERLINK(\"\"x\"\")\",0.000001\n")
+}
+
+func TestRenderOpsReportXLSX(t *testing.T) {
+	data := &OpsReportData{
+		ReportType: OpsReportTypeUserSpend,
+		Columns:    []string{"user_id", "email", "actual_cost"},
+		Rows:       [][]any{{int64(1), "a<b>@example.com", 2.25}},
+	}
+	raw, err := renderOpsReportXLSX(data)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files["xl/workbook.xml"], `name="user_spend"`)
+	sheet := files["xl/worksheets/sheet1.xml"]
+	require.Contains(t, sheet, `<c r="A2"><v>1</v></c>`)
+	require.Contains(t, sheet, `a&lt;b&gt;@example.com`)
+	require.Contains(t, sheet, `<c r="C2"><v>2.25</v></c>`)
+}
+
+func TestOpsXLSXColumnName(t *testing.T) {
+	require.Equal(t, "A", opsXLSXColumnName(0))
+	require.Equal(t, "Z", opsXLSXColumnName(25))
+	require.Equal(t, "AA", opsXLSXColumnName(26))
+	require.Equal(t, "AZ", opsXLSXColumnName(51))
+	require.Equal(t, "BA", opsXLSXColumnName(52))
+}
+
+func TestOpsScheduledReport_FinanceReportUploadsAndPostsWebhook(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var gotStart, gotEnd time.Time
+	repo := &opsRepoMock{
+		ListUserSpendReportFn: func(ctx context.Context, start, end time.Time) ([]*OpsUserSpendReportRow, error) {
+			gotStart, gotEnd = start, end
+			return []*OpsUserSpendReportRow{
+				{UserID: 1, Email: "a@example.com", Requests: 3, ActualCost: 1.25, StandardCost: 2.5},
+			}, nil
+		},
+		ListNotificationChannelsFn: func(ctx context.Context) ([]*OpsNotificationChannel, error) {
+			return []*OpsNotificationChannel{
+				{ID: 1, Name: "finance", Type: OpsNotificationChannelWebhook, URL: srv.URL, Enabled: true},
+			}, nil
+		},
+	}
+	uploader := &opsReportUploaderStub{}
+	svc := NewOpsScheduledReportService(&OpsService{opsRepo: repo}, nil, nil, newTestOpsNotificationService(repo), nil, nil, nil)
+	svc.artifactUploader = uploader
+
+	now := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	attempts, err := svc.runReport(context.Background(), &opsScheduledReport{
+		Name:             "用户消费明细",
+		ReportType:       OpsReportTypeUserSpend,
+		Enabled:          true,
+		ChannelIDs:       []int64{1},
+		Period:           OpsReportPeriodMonth,
+		AttachmentFormat: OpsReportAttachmentCSV,
+		UploadToS3:       true,
+	}, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+
+	require.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), gotStart)
+	require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), gotEnd)
+
+	require.Equal(t, opsFinanceReportS3Category, uploader.category)
+	require.Equal(t, "user_spend_20260901-20260930.csv", uploader.fileName)
+	require.Contains(t, string(uploader.body), "1,a@example.com,,3,0,0,0,0,2.5,1.25")
+
+	require.NotNil(t, received)
+	data, ok := received["data"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, OpsReportTypeUserSpend, data["report_type"])
+	require.Equal(t, "ops-reports/user_spend_20260901-20260930.csv", data["s3_key"])
+	require.Len(t, data["rows"], 1)
+}
+
+func TestOpsScheduledReport_HTMLReportWebhookHasNoData(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repo := &opsRepoMock{
+		ListNotificationChannelsFn: func(ctx context.Context) ([]*OpsNotificationChannel, error) {
+			return []*OpsNotificationChannel{
+				{ID: 1, Name: "ops", Type: OpsNotificationChannelWebhook, URL: srv.URL, Enabled: true},
+			}, nil
+		},
+	}
+	svc := NewOpsScheduledReportService(&OpsService{opsRepo: repo}, nil, nil, newTestOpsNotificationService(repo), nil, nil, nil)
+
+	_, err := svc.runReport(context.Background(), &opsScheduledReport{
+		Name:       "账号健康",
+		ReportType: "account_health",
+		Enabled:    true,
+		TimeRange:  24 * time.Hour,
+		ChannelIDs: []int64{1},
+	}, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, received)
+	_, hasData := received["data"]
+	require.False(t, hasData)
+}
+
+func TestValidateOpsEmailNotificationConfig_FinanceReport(t *testing.T) {
+	cfg := defaultOpsEmailNotificationConfig()
+	require.NoError(t, validateOpsEmailNotificationConfig(cfg))
+
+	bad := defaultOpsEmailNotificationConfig()
+	bad.Report.UserSpendSchedule = "every month"
+	require.ErrorContains(t, validateOpsEmailNotificationConfig(bad), "report.user_spend_schedule")
+
+	bad = defaultOpsEmailNotificationConfig()
+	bad.Report.AttachmentFormat = "pdf"
+	require.ErrorContains(t, validateOpsEmailNotificationConfig(bad), "attachment_format")
+
+	bad = defaultOpsEmailNotificationConfig()
+	bad.Report.FinancePeriod = "quarter"
+	require.ErrorContains(t, validateOpsEmailNotificationConfig(bad), "finance_period")
+}
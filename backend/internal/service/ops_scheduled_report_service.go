@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"html"
 	"log"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,11 +38,13 @@ return 0
 `)
 
 type OpsScheduledReportService struct {
-	opsService   *OpsService
-	userService  *UserService
-	emailService *EmailService
-	redisClient  *redis.Client
-	cfg          *config.Config
+	opsService          *OpsService
+	userService         *UserService
+	emailService        *EmailService
+	notificationService *OpsNotificationService
+	artifactUploader    opsReportArtifactUploader
+	redisClient         *redis.Client
+	cfg                 *config.Config
 
 	instanceID string
 	loc        *time.Location
@@ -59,6 +63,8 @@ func NewOpsScheduledReportService(
 	opsService *OpsService,
 	userService *UserService,
 	emailService *EmailService,
+	notificationService *OpsNotificationService,
+	backupService *BackupService,
 	redisClient *redis.Client,
 	cfg *config.Config,
 ) *OpsScheduledReportService {
@@ -70,12 +76,13 @@ func NewOpsScheduledReportService(
 			loc = parsed
 		}
 	}
-	return &OpsScheduledReportService{
-		opsService:   opsService,
-		userService:  userService,
-		emailService: emailService,
-		redisClient:  redisClient,
-		cfg:          cfg,
+	svc := &OpsScheduledReportService{
+		opsService:          opsService,
+		userService:         userService,
+		emailService:        emailService,
+		notificationService: notificationService,
+		redisClient:         redisClient,
+		cfg:                 cfg,
 
 		instanceID:        uuid.NewString(),
 		loc:               loc,
@@ -87,6 +94,10 @@ func NewOpsScheduledReportService(
 		stop:              nil,
 		wg:                sync.WaitGroup{},
 	}
+	if backupService != nil {
+		svc.artifactUploader = backupService
+	}
+	return svc
 }
 
 func (s *OpsScheduledReportService) Start() {
@@ -103,7 +114,7 @@ func (s *OpsScheduledReportService) StartWithContext(ctx context.Context) {
 	if s.cfg != nil && !s.cfg.Ops.Enabled {
 		return
 	}
-	if s.opsService == nil || s.emailService == nil {
+	if s.opsService == nil || (s.emailService == nil && s.notificationService == nil) {
 		return
 	}
 
@@ -144,7 +155,7 @@ func (s *OpsScheduledReportService) run() {
 }
 
 func (s *OpsScheduledReportService) runOnce() {
-	if s == nil || s.opsService == nil || s.emailService == nil {
+	if s == nil || s.opsService == nil || (s.emailService == nil && s.notificationService == nil) {
 		return
 	}
 
@@ -211,10 +222,16 @@ type opsScheduledReport struct {
 	TimeRange time.Duration
 
 	Recipients []string
+	ChannelIDs []int64
 
 	ErrorDigestMinCount             int
 	AccountHealthErrorRateThreshold float64
 
+	// 财务明细报表
+	Period           string
+	AttachmentFormat string
+	UploadToS3       bool
+
 	LastRunAt *time.Time
 	NextRunAt time.Time
 }
@@ -250,6 +267,9 @@ func (s *OpsScheduledReportService) listScheduledReports(ctx context.Context, no
 		{enabled: emailCfg.Report.WeeklySummaryEnabled, name: "周报", kind: "weekly_summary", timeRange: 7 * 24 * time.Hour, schedule: emailCfg.Report.WeeklySummarySchedule},
 		{enabled: emailCfg.Report.ErrorDigestEnabled, name: "错误摘要", kind: "error_digest", timeRange: 24 * time.Hour, schedule: emailCfg.Report.ErrorDigestSchedule},
 		{enabled: emailCfg.Report.AccountHealthEnabled, name: "账号健康", kind: "account_health", timeRange: 24 * time.Hour, schedule: emailCfg.Report.AccountHealthSchedule},
+		{enabled: emailCfg.Report.UserSpendEnabled, name: "用户消费明细", kind: OpsReportTypeUserSpend, schedule: emailCfg.Report.UserSpendSchedule},
+		{enabled: emailCfg.Report.GroupRevenueEnabled, name: "分组收入与成本", kind: OpsReportTypeGroupRevenue, schedule: emailCfg.Report.GroupRevenueSchedule},
+		{enabled: emailCfg.Report.AccountUtilizationEnabled, name: "账号利用率", kind: OpsReportTypeAccountUtilization, schedule: emailCfg.Report.AccountUtilizationSchedule},
 	}
 
 	out := make([]*opsScheduledReport, 0, len(defs))
@@ -293,10 +313,15 @@ func (s *OpsScheduledReportService) listScheduledReports(ctx context.Context, no
 			TimeRange: d.timeRange,
 
 			Recipients: recipients,
+			ChannelIDs: emailCfg.Report.ChannelIDs,
 
 			ErrorDigestMinCount:             emailCfg.Report.ErrorDigestMinCount,
 			AccountHealthErrorRateThreshold: emailCfg.Report.AccountHealthErrorRateThreshold,
 
+			Period:           emailCfg.Report.FinancePeriod,
+			AttachmentFormat: emailCfg.Report.AttachmentFormat,
+			UploadToS3:       emailCfg.Report.UploadToS3,
+
 			LastRunAt: lastRunPtr,
 			NextRunAt: next,
 		})
@@ -306,7 +331,7 @@ func (s *OpsScheduledReportService) listScheduledReports(ctx context.Context, no
 }
 
 func (s *OpsScheduledReportService) runReport(ctx context.Context, report *opsScheduledReport, now time.Time) (int, error) {
-	if s == nil || s.opsService == nil || s.emailService == nil || report == nil {
+	if s == nil || s.opsService == nil || report == nil {
 		return 0, nil
 	}
 	if ctx == nil {
@@ -316,15 +341,56 @@ func (s *OpsScheduledReportService) runReport(ctx context.Context, report *opsSc
 	// Mark as "run" up-front so a broken SMTP config doesn't spam retries every minute.
 	s.setLastRunAt(ctx, report.ReportType, now)
 
-	content, err := s.generateReportHTML(ctx, report, now)
-	if err != nil {
-		return 0, err
+	var (
+		content    string
+		data       *OpsReportData
+		attachment *EmailAttachment
+		err        error
+	)
+	if isOpsFinanceReportType(report.ReportType) {
+		data, err = s.generateFinanceReport(ctx, report, now)
+		if err != nil {
+			return 0, err
+		}
+		attachment, err = buildOpsReportAttachment(data, report.AttachmentFormat)
+		if err != nil {
+			return 0, err
+		}
+		data.FileName = attachment.FileName
+		if report.UploadToS3 {
+			data.S3Key = s.uploadReportAttachment(ctx, attachment)
+		}
+		content = buildOpsFinanceReportEmailHTML(data)
+	} else {
+		content, err = s.generateReportHTML(ctx, report, now)
+		if err != nil {
+			return 0, err
+		}
 	}
 	if strings.TrimSpace(content) == "" {
 		// Skip sending when the report decides not to emit content (e.g., digest below min count).
 		return 0, nil
 	}
 
+	subject := fmt.Sprintf("[Ops Report] %s", strings.TrimSpace(report.Name))
+
+	attempts := 0
+	if s.notificationService != nil && len(report.ChannelIDs) > 0 {
+		msg := &OpsNotificationMessage{
+			Source: OpsNotificationSourceReport,
+			Title:  subject,
+			Text:   opsHTMLToPlainText(content),
+		}
+		if data != nil {
+			msg.Data = data
+		}
+		deliveries := s.notificationService.Dispatch(ctx, report.ChannelIDs, msg)
+		attempts += len(deliveries)
+	}
+	if s.emailService == nil {
+		return attempts, nil
+	}
+
 	recipients := report.Recipients
 	if len(recipients) == 0 && s.userService != nil {
 		admin, err := s.userService.GetFirstAdmin(ctx)
@@ -333,19 +399,21 @@ func (s *OpsScheduledReportService) runReport(ctx context.Context, report *opsSc
 		}
 	}
 	if len(recipients) == 0 {
-		return 0, nil
+		return attempts, nil
 	}
 
-	subject := fmt.Sprintf("[Ops Report] %s", strings.TrimSpace(report.Name))
-
-	attempts := 0
 	for _, to := range recipients {
 		addr := strings.TrimSpace(to)
 		if addr == "" {
 			continue
 		}
 		attempts++
-		if err := s.emailService.SendEmail(ctx, addr, subject, content); err != nil {
+		if attachment != nil {
+			err = s.emailService.SendEmailWithAttachments(ctx, addr, subject, content, []EmailAttachment{*attachment})
+		} else {
+			err = s.emailService.SendEmail(ctx, addr, subject, content)
+		}
+		if err != nil {
 			// Ignore per-recipient failures; continue best-effort.
 			continue
 		}
@@ -719,3 +787,27 @@ func normalizeEmails(in []string) []string {
 	}
 	return out
 }
+
+var (
+	opsHTMLBlockTagPattern = regexp.MustCompile(`(?i)<\s*(br\s*/?|/p|/h[1-6]|/li|/tr|/ul|/table)\s*>`)
+	opsHTMLCellTagPattern  = regexp.MustCompile(`(?i)<\s*/t[dh]\s*>`)
+	opsHTMLTagPattern      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// opsHTMLToPlainText 将报表 HTML 转为 IM 渠道可读的纯文本
+func opsHTMLToPlainText(in string) string {
+	out := opsHTMLBlockTagPattern.ReplaceAllString(in, "\n")
+	out = opsHTMLCellTagPattern.ReplaceAllString(out, " | ")
+	out = opsHTMLTagPattern.ReplaceAllString(out, "")
+	out = html.UnescapeString(out)
+
+	lines := strings.Split(out, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "|"))
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
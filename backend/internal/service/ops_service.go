@@ -59,6 +59,13 @@ type OpsService struct {
 	requestTraceWriter     *OpsRequestTraceWriter
 	requestTraceWriterOnce sync.Once
 	finalizedRequestTraces sync.Map
+
+	// getPlatformAvailability is a unit-test hook for overriding platform availability lookup (status page).
+	getPlatformAvailability func(ctx context.Context) (map[string]*PlatformAvailability, error)
+
+	statusPageMu       sync.Mutex
+	statusPageCache    *OpsPublicStatus
+	statusPageCachedAt time.Time
 }
 
 func NewOpsService(
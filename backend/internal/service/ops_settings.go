@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -89,13 +90,25 @@ func (s *OpsService) UpdateEmailNotificationConfig(ctx context.Context, req *Ops
 		cfg.Report.AccountHealthEnabled = req.Report.AccountHealthEnabled
 		cfg.Report.AccountHealthSchedule = strings.TrimSpace(req.Report.AccountHealthSchedule)
 		cfg.Report.AccountHealthErrorRateThreshold = req.Report.AccountHealthErrorRateThreshold
+		cfg.Report.UserSpendEnabled = req.Report.UserSpendEnabled
+		cfg.Report.UserSpendSchedule = strings.TrimSpace(req.Report.UserSpendSchedule)
+		cfg.Report.GroupRevenueEnabled = req.Report.GroupRevenueEnabled
+		cfg.Report.GroupRevenueSchedule = strings.TrimSpace(req.Report.GroupRevenueSchedule)
+		cfg.Report.AccountUtilizationEnabled = req.Report.AccountUtilizationEnabled
+		cfg.Report.AccountUtilizationSchedule = strings.TrimSpace(req.Report.AccountUtilizationSchedule)
+		cfg.Report.FinancePeriod = strings.TrimSpace(req.Report.FinancePeriod)
+		cfg.Report.AttachmentFormat = strings.TrimSpace(req.Report.AttachmentFormat)
+		cfg.Report.UploadToS3 = req.Report.UploadToS3
+		if req.Report.ChannelIDs != nil {
+			cfg.Report.ChannelIDs = req.Report.ChannelIDs
+		}
 	}
 
+	normalizeOpsEmailNotificationConfig(cfg)
 	if err := validateOpsEmailNotificationConfig(cfg); err != nil {
 		return nil, err
 	}
 
-	normalizeOpsEmailNotificationConfig(cfg)
 	raw, err := json.Marshal(cfg)
 	if err != nil {
 		return nil, err
@@ -129,6 +142,16 @@ func defaultOpsEmailNotificationConfig() *OpsEmailNotificationConfig {
 			AccountHealthEnabled:            false,
 			AccountHealthSchedule:           "0 9 * * *",
 			AccountHealthErrorRateThreshold: 10.0,
+			UserSpendEnabled:                false,
+			UserSpendSchedule:               opsFinanceReportScheduleDefault,
+			GroupRevenueEnabled:             false,
+			GroupRevenueSchedule:            opsFinanceReportScheduleDefault,
+			AccountUtilizationEnabled:       false,
+			AccountUtilizationSchedule:      opsFinanceReportScheduleDefault,
+			FinancePeriod:                   OpsReportPeriodMonth,
+			AttachmentFormat:                OpsReportAttachmentCSV,
+			UploadToS3:                      false,
+			ChannelIDs:                      []int64{},
 		},
 	}
 }
@@ -143,12 +166,18 @@ func normalizeOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) {
 	if cfg.Report.Recipients == nil {
 		cfg.Report.Recipients = []string{}
 	}
+	cfg.Report.ChannelIDs = normalizeOpsNotificationChannelIDs(cfg.Report.ChannelIDs)
 
 	cfg.Alert.MinSeverity = strings.TrimSpace(cfg.Alert.MinSeverity)
 	cfg.Report.DailySummarySchedule = strings.TrimSpace(cfg.Report.DailySummarySchedule)
 	cfg.Report.WeeklySummarySchedule = strings.TrimSpace(cfg.Report.WeeklySummarySchedule)
 	cfg.Report.ErrorDigestSchedule = strings.TrimSpace(cfg.Report.ErrorDigestSchedule)
 	cfg.Report.AccountHealthSchedule = strings.TrimSpace(cfg.Report.AccountHealthSchedule)
+	cfg.Report.UserSpendSchedule = strings.TrimSpace(cfg.Report.UserSpendSchedule)
+	cfg.Report.GroupRevenueSchedule = strings.TrimSpace(cfg.Report.GroupRevenueSchedule)
+	cfg.Report.AccountUtilizationSchedule = strings.TrimSpace(cfg.Report.AccountUtilizationSchedule)
+	cfg.Report.FinancePeriod = strings.ToLower(strings.TrimSpace(cfg.Report.FinancePeriod))
+	cfg.Report.AttachmentFormat = strings.ToLower(strings.TrimSpace(cfg.Report.AttachmentFormat))
 
 	// Fill missing schedules with defaults to avoid breaking cron logic if clients send empty strings.
 	if cfg.Report.DailySummarySchedule == "" {
@@ -163,6 +192,21 @@ func normalizeOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) {
 	if cfg.Report.AccountHealthSchedule == "" {
 		cfg.Report.AccountHealthSchedule = "0 9 * * *"
 	}
+	if cfg.Report.UserSpendSchedule == "" {
+		cfg.Report.UserSpendSchedule = opsFinanceReportScheduleDefault
+	}
+	if cfg.Report.GroupRevenueSchedule == "" {
+		cfg.Report.GroupRevenueSchedule = opsFinanceReportScheduleDefault
+	}
+	if cfg.Report.AccountUtilizationSchedule == "" {
+		cfg.Report.AccountUtilizationSchedule = opsFinanceReportScheduleDefault
+	}
+	if cfg.Report.FinancePeriod == "" {
+		cfg.Report.FinancePeriod = OpsReportPeriodMonth
+	}
+	if cfg.Report.AttachmentFormat == "" {
+		cfg.Report.AttachmentFormat = OpsReportAttachmentCSV
+	}
 }
 
 func validateOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) error {
@@ -188,6 +232,37 @@ func validateOpsEmailNotificationConfig(cfg *OpsEmailNotificationConfig) error {
 	if cfg.Report.AccountHealthErrorRateThreshold < 0 || cfg.Report.AccountHealthErrorRateThreshold > 100 {
 		return errors.New("report.account_health_error_rate_threshold must be between 0 and 100")
 	}
+	switch strings.TrimSpace(cfg.Report.FinancePeriod) {
+	case "", OpsReportPeriodDay, OpsReportPeriodWeek, OpsReportPeriodMonth:
+	default:
+		return errors.New("report.finance_period must be one of: day, week, month")
+	}
+	switch strings.TrimSpace(cfg.Report.AttachmentFormat) {
+	case "", OpsReportAttachmentCSV, OpsReportAttachmentXLSX:
+	default:
+		return errors.New("report.attachment_format must be one of: csv, xlsx")
+	}
+	schedules := []struct {
+		field string
+		spec  string
+	}{
+		{"report.daily_summary_schedule", cfg.Report.DailySummarySchedule},
+		{"report.weekly_summary_schedule", cfg.Report.WeeklySummarySchedule},
+		{"report.error_digest_schedule", cfg.Report.ErrorDigestSchedule},
+		{"report.account_health_schedule", cfg.Report.AccountHealthSchedule},
+		{"report.user_spend_schedule", cfg.Report.UserSpendSchedule},
+		{"report.group_revenue_schedule", cfg.Report.GroupRevenueSchedule},
+		{"report.account_utilization_schedule", cfg.Report.AccountUtilizationSchedule},
+	}
+	for _, sc := range schedules {
+		spec := strings.TrimSpace(sc.spec)
+		if spec == "" {
+			continue
+		}
+		if _, err := opsScheduledReportCronParser.Parse(spec); err != nil {
+			return fmt.Errorf("%s is not a valid cron expression: %v", sc.field, err)
+		}
+	}
 	return nil
 }
 
@@ -29,6 +29,22 @@ type OpsEmailReportConfig struct {
 	AccountHealthEnabled            bool     `json:"account_health_enabled"`
 	AccountHealthSchedule           string   `json:"account_health_schedule"`
 	AccountHealthErrorRateThreshold float64  `json:"account_health_error_rate_threshold"`
+
+	// 财务明细报表：统计上一个完整的 FinancePeriod 周期，以 CSV/XLSX 附件发送
+	UserSpendEnabled           bool   `json:"user_spend_enabled"`
+	UserSpendSchedule          string `json:"user_spend_schedule"`
+	GroupRevenueEnabled        bool   `json:"group_revenue_enabled"`
+	GroupRevenueSchedule       string `json:"group_revenue_schedule"`
+	AccountUtilizationEnabled  bool   `json:"account_utilization_enabled"`
+	AccountUtilizationSchedule string `json:"account_utilization_schedule"`
+	FinancePeriod              string `json:"finance_period"`
+	AttachmentFormat           string `json:"attachment_format"`
+	// UploadToS3 附件同时上传到备份所用的 S3 存储
+	UploadToS3 bool `json:"upload_to_s3"`
+
+	// ChannelIDs 报表同时投递的通知渠道（与邮件收件人并行）；
+	// 通用 webhook 渠道会在 payload 的 data 字段收到财务报表的结构化明细
+	ChannelIDs []int64 `json:"channel_ids"`
 }
 
 // OpsEmailNotificationConfigUpdateRequest allows partial updates, while the
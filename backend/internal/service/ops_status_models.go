@@ -0,0 +1,176 @@
+package service
+
+import "time"
+
+// 公开状态页组件状态（按严重程度从低到高）
+const (
+	OpsComponentStatusOperational   = "operational"
+	OpsComponentStatusMaintenance   = "under_maintenance"
+	OpsComponentStatusDegraded      = "degraded_performance"
+	OpsComponentStatusPartialOutage = "partial_outage"
+	OpsComponentStatusMajorOutage   = "major_outage"
+)
+
+// 公开事件状态
+const (
+	OpsIncidentStatusOngoing  = "ongoing"
+	OpsIncidentStatusResolved = "resolved"
+)
+
+// OpsMaintenanceWindow 计划维护窗口
+type OpsMaintenanceWindow struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Platforms 受影响的平台，为空表示全部平台
+	Platforms []string  `json:"platforms"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Covers 维护窗口是否覆盖指定平台
+func (w *OpsMaintenanceWindow) Covers(platform string) bool {
+	if w == nil {
+		return false
+	}
+	if len(w.Platforms) == 0 {
+		return true
+	}
+	for _, p := range w.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveAt 维护窗口在指定时刻是否生效
+func (w *OpsMaintenanceWindow) ActiveAt(t time.Time) bool {
+	return w != nil && !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+type OpsMaintenanceWindowFilter struct {
+	// EndsAfter 仅返回结束时间晚于该时刻的窗口（进行中 + 未开始）
+	EndsAfter *time.Time
+	Limit     int
+}
+
+// OpsStatusModelStat 近期按平台/模型聚合的成功与错误数（用于推导组件状态）
+type OpsStatusModelStat struct {
+	Platform     string
+	Model        string
+	SuccessCount int64
+	ErrorCount   int64
+}
+
+// OpsStatusComponent 状态页组件（平台，或平台下的模型）
+type OpsStatusComponent struct {
+	Name       string                `json:"name"`
+	Platform   string                `json:"platform"`
+	Model      string                `json:"model,omitempty"`
+	Status     string                `json:"status"`
+	Components []*OpsStatusComponent `json:"components,omitempty"`
+}
+
+// OpsStatusIncident 公开事件（来自告警事件，仅保留可公开字段）
+type OpsStatusIncident struct {
+	ID         int64      `json:"id"`
+	Title      string     `json:"title"`
+	Severity   string     `json:"severity"`
+	Status     string     `json:"status"`
+	Platform   string     `json:"platform,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// OpsStatusMaintenance 公开维护窗口
+type OpsStatusMaintenance struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Platforms   []string  `json:"platforms"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+}
+
+// OpsPublicStatus 公开状态页数据
+type OpsPublicStatus struct {
+	Status              string                  `json:"status"`
+	UpdatedAt           time.Time               `json:"updated_at"`
+	Components          []*OpsStatusComponent   `json:"components"`
+	ActiveMaintenance   []*OpsStatusMaintenance `json:"active_maintenance"`
+	UpcomingMaintenance []*OpsStatusMaintenance `json:"upcoming_maintenance"`
+	Incidents           []*OpsStatusIncident    `json:"incidents"`
+}
+
+// OpsCustomerSLAFilter 客户 SLA 统计范围（UserID/GroupID 为空表示不限）
+type OpsCustomerSLAFilter struct {
+	UserID    *int64
+	GroupID   *int64
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// OpsCustomerSLAStats 客户 SLA 原始统计
+type OpsCustomerSLAStats struct {
+	SuccessCount int64
+	// ErrorCount 计入 SLA 的错误：HTTP >= 400，排除业务限流与客户端自身原因导致的错误
+	ErrorCount int64
+
+	DurationP50 *int
+	DurationP95 *int
+	DurationP99 *int
+	TTFTP50     *int
+	TTFTP95     *int
+	TTFTP99     *int
+}
+
+// OpsCustomerSLAQuery 客户 SLA 报告查询参数
+type OpsCustomerSLAQuery struct {
+	UserID  *int64
+	GroupID *int64
+	// Month 格式 YYYY-MM（UTC），为空表示当月
+	Month string
+}
+
+type OpsSLAPercentiles struct {
+	P50 *int `json:"p50_ms"`
+	P95 *int `json:"p95_ms"`
+	P99 *int `json:"p99_ms"`
+}
+
+// OpsSLAErrorBudget 错误预算：Allowed = 总请求数 × (1 - 目标可用性)
+type OpsSLAErrorBudget struct {
+	AllowedFailures float64 `json:"allowed_failures"`
+	ConsumedPercent float64 `json:"consumed_percent"`
+	RemainingPct    float64 `json:"remaining_percent"`
+	// BurnRate 实际错误率 / 允许错误率，>1 表示按当前速度将在月底前耗尽预算
+	BurnRate float64 `json:"burn_rate"`
+}
+
+// OpsCustomerSLAReport 客户月度 SLA 报告
+type OpsCustomerSLAReport struct {
+	Month       string    `json:"month"`
+	UserID      *int64    `json:"user_id,omitempty"`
+	GroupID     *int64    `json:"group_id,omitempty"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	// WindowElapsedPct 当月已过去的比例（历史月份为 100）
+	WindowElapsedPct float64 `json:"window_elapsed_percent"`
+
+	TotalRequests      int64   `json:"total_requests"`
+	SuccessfulRequests int64   `json:"successful_requests"`
+	FailedRequests     int64   `json:"failed_requests"`
+	Availability       float64 `json:"availability"`
+	TargetAvailability float64 `json:"target_availability"`
+	TargetMet          bool    `json:"target_met"`
+
+	Latency     OpsSLAPercentiles `json:"latency"`
+	TTFT        OpsSLAPercentiles `json:"ttft"`
+	ErrorBudget OpsSLAErrorBudget `json:"error_budget"`
+
+	// PartialData 窗口早于错误日志保留期，失败数可能不完整
+	PartialData bool `json:"partial_data"`
+}
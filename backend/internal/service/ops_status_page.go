@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	opsStatusDefaultCacheTTL     = 30 * time.Second
+	opsStatusDefaultIncidentDays = 14
+	opsStatusTrafficWindow       = 15 * time.Minute
+	// 近期流量低于该值时不根据错误率判定状态，避免少量请求导致状态抖动
+	opsStatusMinSample              = 20
+	opsStatusDegradedErrorRate      = 0.05
+	opsStatusPartialOutageErrorRate = 0.25
+	opsStatusMaxIncidents           = 50
+	opsStatusMaxMaintenanceWindows  = 20
+	opsMaintenanceTitleMaxLen       = 200
+)
+
+var ErrStatusPageDisabled = infraerrors.NotFound("STATUS_PAGE_DISABLED", "Status page is disabled")
+
+// opsComponentStatusRank 状态严重程度，用于取最差状态
+var opsComponentStatusRank = map[string]int{
+	OpsComponentStatusOperational:   0,
+	OpsComponentStatusMaintenance:   1,
+	OpsComponentStatusDegraded:      2,
+	OpsComponentStatusPartialOutage: 3,
+	OpsComponentStatusMajorOutage:   4,
+}
+
+func worseOpsComponentStatus(a, b string) string {
+	if opsComponentStatusRank[b] > opsComponentStatusRank[a] {
+		return b
+	}
+	return a
+}
+
+// GetPublicStatus 返回公开状态页数据（无需鉴权，结果按 cache_ttl_seconds 缓存）。
+func (s *OpsService) GetPublicStatus(ctx context.Context) (*OpsPublicStatus, error) {
+	if s == nil || s.cfg == nil || !s.cfg.Ops.StatusPage.Enabled {
+		return nil, ErrStatusPageDisabled
+	}
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, ErrStatusPageDisabled
+	}
+
+	ttl := opsStatusDefaultCacheTTL
+	if s.cfg.Ops.StatusPage.CacheTTLSeconds > 0 {
+		ttl = time.Duration(s.cfg.Ops.StatusPage.CacheTTLSeconds) * time.Second
+	}
+
+	// 持锁计算，保证缓存失效时只有一个请求访问数据库
+	s.statusPageMu.Lock()
+	defer s.statusPageMu.Unlock()
+	if s.statusPageCache != nil && time.Since(s.statusPageCachedAt) < ttl {
+		return s.statusPageCache, nil
+	}
+
+	status, err := s.buildPublicStatus(ctx, time.Now().UTC())
+	if err != nil {
+		if s.statusPageCache != nil {
+			// 数据源暂时不可用时返回上一次结果
+			return s.statusPageCache, nil
+		}
+		return nil, err
+	}
+	s.statusPageCache = status
+	s.statusPageCachedAt = time.Now()
+	return status, nil
+}
+
+func (s *OpsService) buildPublicStatus(ctx context.Context, now time.Time) (*OpsPublicStatus, error) {
+	platforms, err := s.statusPlatformAvailability(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelStats []*OpsStatusModelStat
+	if s.opsRepo != nil {
+		modelStats, err = s.opsRepo.ListStatusModelStats(ctx, now.Add(-opsStatusTrafficWindow))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	windows := []*OpsMaintenanceWindow{}
+	if s.opsRepo != nil {
+		windows, err = s.opsRepo.ListMaintenanceWindows(ctx, &OpsMaintenanceWindowFilter{EndsAfter: &now, Limit: opsStatusMaxMaintenanceWindows})
+		if err != nil {
+			return nil, err
+		}
+	}
+	active := make([]*OpsMaintenanceWindow, 0, len(windows))
+	out := &OpsPublicStatus{
+		Status:              OpsComponentStatusOperational,
+		UpdatedAt:           now,
+		Components:          []*OpsStatusComponent{},
+		ActiveMaintenance:   []*OpsStatusMaintenance{},
+		UpcomingMaintenance: []*OpsStatusMaintenance{},
+		Incidents:           []*OpsStatusIncident{},
+	}
+	for _, w := range windows {
+		if w == nil {
+			continue
+		}
+		if w.ActiveAt(now) {
+			active = append(active, w)
+			out.ActiveMaintenance = append(out.ActiveMaintenance, toOpsStatusMaintenance(w))
+		} else if w.StartsAt.After(now) {
+			out.UpcomingMaintenance = append(out.UpcomingMaintenance, toOpsStatusMaintenance(w))
+		}
+	}
+
+	out.Components = buildOpsStatusComponents(platforms, modelStats, active, s.cfg.Ops.StatusPage.ShowModels)
+	for _, c := range out.Components {
+		out.Status = worseOpsComponentStatus(out.Status, c.Status)
+	}
+
+	incidents, err := s.listStatusIncidents(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	out.Incidents = incidents
+	return out, nil
+}
+
+func (s *OpsService) statusPlatformAvailability(ctx context.Context) (map[string]*PlatformAvailability, error) {
+	if s.getPlatformAvailability != nil {
+		return s.getPlatformAvailability(ctx)
+	}
+	platforms, _, _, _, err := s.GetAccountAvailabilityStats(ctx, "", nil)
+	return platforms, err
+}
+
+// buildOpsStatusComponents 由账号可用性与近期错误率推导平台组件状态；维护窗口覆盖的平台显示为维护中（故障除外）。
+func buildOpsStatusComponents(platforms map[string]*PlatformAvailability, modelStats []*OpsStatusModelStat, active []*OpsMaintenanceWindow, showModels bool) []*OpsStatusComponent {
+	type trafficAgg struct {
+		success int64
+		errors  int64
+		models  []*OpsStatusModelStat
+	}
+	traffic := make(map[string]*trafficAgg)
+	for _, st := range modelStats {
+		if st == nil || st.Platform == "" {
+			continue
+		}
+		agg := traffic[st.Platform]
+		if agg == nil {
+			agg = &trafficAgg{}
+			traffic[st.Platform] = agg
+		}
+		agg.success += st.SuccessCount
+		agg.errors += st.ErrorCount
+		if st.Model != "" {
+			agg.models = append(agg.models, st)
+		}
+	}
+
+	names := make([]string, 0, len(platforms))
+	for name, p := range platforms {
+		if p != nil && p.TotalAccounts > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]*OpsStatusComponent, 0, len(names))
+	for _, name := range names {
+		p := platforms[name]
+		status := OpsComponentStatusOperational
+		switch {
+		case p.AvailableCount <= 0:
+			status = OpsComponentStatusMajorOutage
+		case p.AvailableCount*2 < p.TotalAccounts:
+			status = OpsComponentStatusPartialOutage
+		}
+		agg := traffic[name]
+		if agg != nil {
+			status = worseOpsComponentStatus(status, opsStatusFromErrorRate(agg.success, agg.errors))
+		}
+		maintenance := false
+		for _, w := range active {
+			if w.Covers(name) {
+				maintenance = true
+				break
+			}
+		}
+		if maintenance {
+			// 维护期间的降级属预期内，仅保留完全不可用的状态
+			if status != OpsComponentStatusMajorOutage {
+				status = OpsComponentStatusMaintenance
+			}
+		}
+
+		component := &OpsStatusComponent{Name: name, Platform: name, Status: status}
+		if showModels && agg != nil {
+			sort.Slice(agg.models, func(i, j int) bool { return agg.models[i].Model < agg.models[j].Model })
+			for _, st := range agg.models {
+				modelStatus := opsStatusFromErrorRate(st.SuccessCount, st.ErrorCount)
+				switch {
+				case status == OpsComponentStatusMajorOutage:
+					modelStatus = status
+				case maintenance:
+					modelStatus = OpsComponentStatusMaintenance
+				}
+				component.Components = append(component.Components, &OpsStatusComponent{
+					Name:     st.Model,
+					Platform: name,
+					Model:    st.Model,
+					Status:   modelStatus,
+				})
+			}
+		}
+		out = append(out, component)
+	}
+	return out
+}
+
+func opsStatusFromErrorRate(success, errorCount int64) string {
+	total := success + errorCount
+	if total < opsStatusMinSample {
+		return OpsComponentStatusOperational
+	}
+	rate := float64(errorCount) / float64(total)
+	switch {
+	case success == 0:
+		return OpsComponentStatusMajorOutage
+	case rate >= opsStatusPartialOutageErrorRate:
+		return OpsComponentStatusPartialOutage
+	case rate >= opsStatusDegradedErrorRate:
+		return OpsComponentStatusDegraded
+	default:
+		return OpsComponentStatusOperational
+	}
+}
+
+// listStatusIncidents 将告警事件转换为公开事件，仅保留标题、级别、平台与时间，不暴露描述与指标。
+func (s *OpsService) listStatusIncidents(ctx context.Context, now time.Time) ([]*OpsStatusIncident, error) {
+	out := []*OpsStatusIncident{}
+	if s.opsRepo == nil {
+		return out, nil
+	}
+	days := s.cfg.Ops.StatusPage.IncidentDays
+	if days <= 0 {
+		days = opsStatusDefaultIncidentDays
+	}
+	severities := make(map[string]struct{})
+	for _, sev := range s.cfg.Ops.StatusPage.IncidentSeverities {
+		if sev = strings.ToUpper(strings.TrimSpace(sev)); sev != "" {
+			severities[sev] = struct{}{}
+		}
+	}
+
+	start := now.AddDate(0, 0, -days)
+	events, err := s.opsRepo.ListAlertEvents(ctx, &OpsAlertEventFilter{Limit: opsStatusMaxIncidents, StartTime: &start})
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		if len(severities) > 0 {
+			if _, ok := severities[strings.ToUpper(strings.TrimSpace(ev.Severity))]; !ok {
+				continue
+			}
+		}
+		incident := &OpsStatusIncident{
+			ID:         ev.ID,
+			Title:      ev.Title,
+			Severity:   ev.Severity,
+			Status:     OpsIncidentStatusResolved,
+			StartedAt:  ev.FiredAt,
+			ResolvedAt: ev.ResolvedAt,
+		}
+		if ev.Status == OpsAlertStatusFiring {
+			incident.Status = OpsIncidentStatusOngoing
+		}
+		if platform, ok := ev.Dimensions["platform"].(string); ok {
+			incident.Platform = platform
+		}
+		out = append(out, incident)
+	}
+	return out, nil
+}
+
+func toOpsStatusMaintenance(w *OpsMaintenanceWindow) *OpsStatusMaintenance {
+	platforms := w.Platforms
+	if platforms == nil {
+		platforms = []string{}
+	}
+	return &OpsStatusMaintenance{
+		ID:          w.ID,
+		Title:       w.Title,
+		Description: w.Description,
+		Platforms:   platforms,
+		StartsAt:    w.StartsAt,
+		EndsAt:      w.EndsAt,
+	}
+}
+
+func (s *OpsService) invalidateStatusPageCache() {
+	s.statusPageMu.Lock()
+	s.statusPageCache = nil
+	s.statusPageMu.Unlock()
+}
+
+// =========================
+// Maintenance windows (admin)
+// =========================
+
+// ListMaintenanceWindows 列出维护窗口；includePast=false 时只返回进行中与未开始的窗口。
+func (s *OpsService) ListMaintenanceWindows(ctx context.Context, includePast bool) ([]*OpsMaintenanceWindow, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return []*OpsMaintenanceWindow{}, nil
+	}
+	filter := &OpsMaintenanceWindowFilter{Limit: 200}
+	if !includePast {
+		now := time.Now().UTC()
+		filter.EndsAfter = &now
+	}
+	return s.opsRepo.ListMaintenanceWindows(ctx, filter)
+}
+
+func (s *OpsService) CreateMaintenanceWindow(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if err := normalizeOpsMaintenanceWindow(input); err != nil {
+		return nil, err
+	}
+	created, err := s.opsRepo.CreateMaintenanceWindow(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateStatusPageCache()
+	return created, nil
+}
+
+func (s *OpsService) UpdateMaintenanceWindow(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if input == nil || input.ID <= 0 {
+		return nil, infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOW", "invalid maintenance window")
+	}
+	if err := normalizeOpsMaintenanceWindow(input); err != nil {
+		return nil, err
+	}
+	updated, err := s.opsRepo.UpdateMaintenanceWindow(ctx, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, infraerrors.NotFound("OPS_MAINTENANCE_WINDOW_NOT_FOUND", "maintenance window not found")
+		}
+		return nil, err
+	}
+	s.invalidateStatusPageCache()
+	return updated, nil
+}
+
+func (s *OpsService) DeleteMaintenanceWindow(ctx context.Context, id int64) error {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return err
+	}
+	if s.opsRepo == nil {
+		return infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if id <= 0 {
+		return infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOW_ID", "invalid maintenance window id")
+	}
+	if err := s.opsRepo.DeleteMaintenanceWindow(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return infraerrors.NotFound("OPS_MAINTENANCE_WINDOW_NOT_FOUND", "maintenance window not found")
+		}
+		return err
+	}
+	s.invalidateStatusPageCache()
+	return nil
+}
+
+func normalizeOpsMaintenanceWindow(w *OpsMaintenanceWindow) error {
+	if w == nil {
+		return infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOW", "invalid maintenance window")
+	}
+	w.Title = strings.TrimSpace(w.Title)
+	w.Description = strings.TrimSpace(w.Description)
+	if w.Title == "" {
+		return infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOW", "title is required")
+	}
+	if len([]rune(w.Title)) > opsMaintenanceTitleMaxLen {
+		return infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOW", "title is too long")
+	}
+	if w.StartsAt.IsZero() || w.EndsAt.IsZero() || !w.EndsAt.After(w.StartsAt) {
+		return infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOW", "ends_at must be after starts_at")
+	}
+	platforms := make([]string, 0, len(w.Platforms))
+	seen := make(map[string]struct{}, len(w.Platforms))
+	for _, p := range w.Platforms {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		platforms = append(platforms, p)
+	}
+	w.Platforms = platforms
+	w.StartsAt = w.StartsAt.UTC()
+	w.EndsAt = w.EndsAt.UTC()
+	return nil
+}
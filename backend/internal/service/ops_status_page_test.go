@@ -0,0 +1,205 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatusPageTestService(repo *opsRepoMock, platforms map[string]*PlatformAvailability) *OpsService {
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	cfg.Ops.StatusPage.Enabled = true
+	cfg.Ops.StatusPage.IncidentSeverities = []string{"P0", "P1"}
+	cfg.Ops.StatusPage.CacheTTLSeconds = 60
+	return &OpsService{
+		opsRepo: repo,
+		cfg:     cfg,
+		getPlatformAvailability: func(ctx context.Context) (map[string]*PlatformAvailability, error) {
+			return platforms, nil
+		},
+	}
+}
+
+func TestOpsStatusFromErrorRate(t *testing.T) {
+	require.Equal(t, OpsComponentStatusOperational, opsStatusFromErrorRate(0, 5))
+	require.Equal(t, OpsComponentStatusOperational, opsStatusFromErrorRate(99, 1))
+	require.Equal(t, OpsComponentStatusDegraded, opsStatusFromErrorRate(90, 10))
+	require.Equal(t, OpsComponentStatusPartialOutage, opsStatusFromErrorRate(70, 30))
+	require.Equal(t, OpsComponentStatusMajorOutage, opsStatusFromErrorRate(0, 20))
+}
+
+func TestBuildOpsStatusComponents(t *testing.T) {
+	now := time.Now().UTC()
+	platforms := map[string]*PlatformAvailability{
+		"openai":    {Platform: "openai", TotalAccounts: 4, AvailableCount: 4},
+		"anthropic": {Platform: "anthropic", TotalAccounts: 4, AvailableCount: 1},
+		"gemini":    {Platform: "gemini", TotalAccounts: 2, AvailableCount: 0},
+		"sora":      {Platform: "sora", TotalAccounts: 0},
+	}
+	stats := []*OpsStatusModelStat{
+		{Platform: "openai", Model: "gpt-b", SuccessCount: 80, ErrorCount: 20},
+		{Platform: "openai", Model: "gpt-a", SuccessCount: 100},
+	}
+
+	components := buildOpsStatusComponents(platforms, stats, nil, false)
+	require.Len(t, components, 3)
+	require.Equal(t, "anthropic", components[0].Name)
+	require.Equal(t, OpsComponentStatusPartialOutage, components[0].Status)
+	require.Equal(t, OpsComponentStatusMajorOutage, components[1].Status)
+	// 20 / 200 = 10% 错误率
+	require.Equal(t, OpsComponentStatusDegraded, components[2].Status)
+	require.Empty(t, components[2].Components)
+
+	active := []*OpsMaintenanceWindow{
+		{Title: "upgrade", Platforms: []string{"openai", "gemini"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+	}
+	components = buildOpsStatusComponents(platforms, stats, active, true)
+	require.Equal(t, OpsComponentStatusPartialOutage, components[0].Status)
+	require.Equal(t, OpsComponentStatusMajorOutage, components[1].Status, "major outage must not be masked by maintenance")
+	require.Equal(t, OpsComponentStatusMaintenance, components[2].Status)
+	require.Len(t, components[2].Components, 2)
+	require.Equal(t, "gpt-a", components[2].Components[0].Model)
+	require.Equal(t, OpsComponentStatusMaintenance, components[2].Components[0].Status)
+}
+
+func TestGetPublicStatus_DisabledReturnsNotFound(t *testing.T) {
+	svc := newStatusPageTestService(&opsRepoMock{}, nil)
+	svc.cfg.Ops.StatusPage.Enabled = false
+
+	_, err := svc.GetPublicStatus(context.Background())
+	require.ErrorIs(t, err, ErrStatusPageDisabled)
+
+	svc.cfg.Ops.StatusPage.Enabled = true
+	svc.cfg.Ops.Enabled = false
+	_, err = svc.GetPublicStatus(context.Background())
+	require.ErrorIs(t, err, ErrStatusPageDisabled)
+}
+
+func TestGetPublicStatus_BuildsSanitizedSnapshotAndCaches(t *testing.T) {
+	now := time.Now().UTC()
+	resolvedAt := now.Add(-time.Hour)
+	calls := 0
+	repo := &opsRepoMock{
+		ListStatusModelStatsFn: func(ctx context.Context, since time.Time) ([]*OpsStatusModelStat, error) {
+			calls++
+			return nil, nil
+		},
+		ListMaintenanceWindowsFn: func(ctx context.Context, filter *OpsMaintenanceWindowFilter) ([]*OpsMaintenanceWindow, error) {
+			require.NotNil(t, filter.EndsAfter)
+			return []*OpsMaintenanceWindow{
+				{ID: 1, Title: "db upgrade", Platforms: []string{"anthropic"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)},
+				{ID: 2, Title: "network", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(25 * time.Hour)},
+			}, nil
+		},
+		ListAlertEventsFn: func(ctx context.Context, filter *OpsAlertEventFilter) ([]*OpsAlertEvent, error) {
+			require.NotNil(t, filter.StartTime)
+			return []*OpsAlertEvent{
+				{ID: 10, Severity: "P0", Status: OpsAlertStatusFiring, Title: "Anthropic errors", Description: "internal detail", Dimensions: map[string]any{"platform": "anthropic"}, FiredAt: now},
+				{ID: 11, Severity: "P1", Status: OpsAlertStatusResolved, Title: "Latency", FiredAt: now.Add(-2 * time.Hour), ResolvedAt: &resolvedAt},
+				{ID: 12, Severity: "P3", Status: OpsAlertStatusFiring, Title: "Noise", FiredAt: now},
+			}, nil
+		},
+	}
+	svc := newStatusPageTestService(repo, map[string]*PlatformAvailability{
+		"anthropic": {TotalAccounts: 2, AvailableCount: 2},
+		"openai":    {TotalAccounts: 2, AvailableCount: 1},
+	})
+
+	status, err := svc.GetPublicStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, OpsComponentStatusMaintenance, status.Components[0].Status)
+	require.Equal(t, OpsComponentStatusOperational, status.Components[1].Status)
+	require.Equal(t, OpsComponentStatusMaintenance, status.Status)
+	require.Len(t, status.ActiveMaintenance, 1)
+	require.Len(t, status.UpcomingMaintenance, 1)
+	require.Equal(t, []string{}, status.UpcomingMaintenance[0].Platforms)
+
+	require.Len(t, status.Incidents, 2)
+	require.Equal(t, OpsIncidentStatusOngoing, status.Incidents[0].Status)
+	require.Equal(t, "anthropic", status.Incidents[0].Platform)
+	require.Equal(t, OpsIncidentStatusResolved, status.Incidents[1].Status)
+	require.Equal(t, &resolvedAt, status.Incidents[1].ResolvedAt)
+
+	cached, err := svc.GetPublicStatus(context.Background())
+	require.NoError(t, err)
+	require.Same(t, status, cached)
+	require.Equal(t, 1, calls)
+
+	// 维护窗口变更后缓存失效
+	_, err = svc.CreateMaintenanceWindow(context.Background(), &OpsMaintenanceWindow{Title: "x", StartsAt: now, EndsAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = svc.GetPublicStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
+
+func TestGetPublicStatus_ServesStaleCacheOnError(t *testing.T) {
+	fail := false
+	repo := &opsRepoMock{
+		ListStatusModelStatsFn: func(ctx context.Context, since time.Time) ([]*OpsStatusModelStat, error) {
+			if fail {
+				return nil, errors.New("db down")
+			}
+			return nil, nil
+		},
+	}
+	svc := newStatusPageTestService(repo, map[string]*PlatformAvailability{"openai": {TotalAccounts: 1, AvailableCount: 1}})
+
+	first, err := svc.GetPublicStatus(context.Background())
+	require.NoError(t, err)
+
+	fail = true
+	svc.statusPageCachedAt = time.Now().Add(-time.Hour)
+	second, err := svc.GetPublicStatus(context.Background())
+	require.NoError(t, err)
+	require.Same(t, first, second)
+
+	svc.invalidateStatusPageCache()
+	_, err = svc.GetPublicStatus(context.Background())
+	require.Error(t, err)
+}
+
+func TestNormalizeOpsMaintenanceWindow(t *testing.T) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	w := &OpsMaintenanceWindow{
+		Title:     "  upgrade  ",
+		Platforms: []string{"OpenAI", " openai", "", "Gemini"},
+		StartsAt:  start,
+		EndsAt:    start.Add(time.Hour),
+	}
+	require.NoError(t, normalizeOpsMaintenanceWindow(w))
+	require.Equal(t, "upgrade", w.Title)
+	require.Equal(t, []string{"openai", "gemini"}, w.Platforms)
+	require.Equal(t, time.UTC, w.StartsAt.Location())
+
+	err := normalizeOpsMaintenanceWindow(&OpsMaintenanceWindow{Title: "x", StartsAt: start, EndsAt: start})
+	require.Equal(t, "INVALID_MAINTENANCE_WINDOW", infraerrors.Reason(err))
+	err = normalizeOpsMaintenanceWindow(&OpsMaintenanceWindow{StartsAt: start, EndsAt: start.Add(time.Hour)})
+	require.Equal(t, "INVALID_MAINTENANCE_WINDOW", infraerrors.Reason(err))
+}
+
+func TestUpdateMaintenanceWindow_NotFound(t *testing.T) {
+	repo := &opsRepoMock{
+		UpdateMaintenanceWindowFn: func(ctx context.Context, input *OpsMaintenanceWindow) (*OpsMaintenanceWindow, error) {
+			return nil, sql.ErrNoRows
+		},
+		DeleteMaintenanceWindowFn: func(ctx context.Context, id int64) error {
+			return sql.ErrNoRows
+		},
+	}
+	svc := newStatusPageTestService(repo, nil)
+	now := time.Now()
+
+	_, err := svc.UpdateMaintenanceWindow(context.Background(), &OpsMaintenanceWindow{ID: 9, Title: "x", StartsAt: now, EndsAt: now.Add(time.Minute)})
+	require.True(t, infraerrors.IsNotFound(err))
+	require.True(t, infraerrors.IsNotFound(svc.DeleteMaintenanceWindow(context.Background(), 9)))
+}
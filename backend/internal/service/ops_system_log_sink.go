@@ -23,10 +23,15 @@ type OpsSystemLogSinkHealth struct {
 	WrittenCount    uint64 `json:"written_count"`
 	AvgWriteDelayMs uint64 `json:"avg_write_delay_ms"`
 	LastError       string `json:"last_error"`
+
+	// ShippingSinks 外部日志投递 sink（Loki / Elasticsearch / HTTP / syslog）的健康状态
+	ShippingSinks []LogShippingSinkHealth `json:"shipping_sinks"`
 }
 
 type OpsSystemLogSink struct {
 	opsRepo OpsRepository
+	// shipping 与本 sink 并列挂载的外部日志投递，仅用于健康状态汇总
+	shipping *LogShippingService
 
 	queue chan *logger.LogEvent
 
@@ -249,7 +254,7 @@ func (s *OpsSystemLogSink) flushBatch(baseCtx context.Context, batch []*logger.L
 
 func (s *OpsSystemLogSink) Health() OpsSystemLogSinkHealth {
 	if s == nil {
-		return OpsSystemLogSinkHealth{}
+		return OpsSystemLogSinkHealth{ShippingSinks: []LogShippingSinkHealth{}}
 	}
 	written := atomic.LoadUint64(&s.writtenCount)
 	totalDelay := atomic.LoadUint64(&s.totalDelayNs)
@@ -267,7 +272,15 @@ func (s *OpsSystemLogSink) Health() OpsSystemLogSinkHealth {
 		WrittenCount:    written,
 		AvgWriteDelayMs: avgDelay,
 		LastError:       strings.TrimSpace(lastErr),
+		ShippingSinks:   s.shippingHealth(),
+	}
+}
+
+func (s *OpsSystemLogSink) shippingHealth() []LogShippingSinkHealth {
+	if health := s.shipping.Health(); health != nil {
+		return health
 	}
+	return []LogShippingSinkHealth{}
 }
 
 func copyMap(in map[string]any) map[string]any {
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 订单类型
+const (
+	PaymentOrderKindBalance      = "balance"
+	PaymentOrderKindSubscription = "subscription"
+)
+
+// 订单状态
+const (
+	PaymentOrderStatusPending     = "pending"
+	PaymentOrderStatusPaid        = "paid"
+	PaymentOrderStatusExpired     = "expired"
+	PaymentOrderStatusCanceled    = "canceled"
+	PaymentOrderStatusRefunded    = "refunded"
+	PaymentOrderStatusChargedBack = "charged_back"
+)
+
+var (
+	ErrPaymentDisabled             = infraerrors.Forbidden("PAYMENT_DISABLED", "payment is disabled")
+	ErrPaymentProviderUnavailable  = infraerrors.BadRequest("PAYMENT_PROVIDER_UNAVAILABLE", "payment provider is not enabled")
+	ErrPaymentOrderNotFound        = infraerrors.NotFound("PAYMENT_ORDER_NOT_FOUND", "payment order not found")
+	ErrPaymentOrderExists          = infraerrors.Conflict("PAYMENT_ORDER_EXISTS", "payment order already exists")
+	ErrPaymentOrderNotPending      = infraerrors.Conflict("PAYMENT_ORDER_NOT_PENDING", "payment order is not pending")
+	ErrPaymentOrderNotPaid         = infraerrors.Conflict("PAYMENT_ORDER_NOT_PAID", "payment order is not paid")
+	ErrPaymentAmountInvalid        = infraerrors.BadRequest("PAYMENT_AMOUNT_INVALID", "payment amount is out of range")
+	ErrPaymentAmountMismatch       = infraerrors.BadRequest("PAYMENT_AMOUNT_MISMATCH", "paid amount does not match order")
+	ErrPaymentPlanNotForSale       = infraerrors.BadRequest("PAYMENT_PLAN_NOT_FOR_SALE", "subscription plan is not available for purchase")
+	ErrPaymentSignatureInvalid     = infraerrors.Unauthorized("PAYMENT_SIGNATURE_INVALID", "payment callback signature is invalid")
+	ErrPaymentCallbackInvalid      = infraerrors.BadRequest("PAYMENT_CALLBACK_INVALID", "payment callback payload is invalid")
+	ErrPaymentPlanPriceNotFound    = infraerrors.NotFound("PAYMENT_PLAN_PRICE_NOT_FOUND", "subscription plan price not found")
+	ErrPaymentPlanCurrencyMismatch = infraerrors.BadRequest("PAYMENT_PLAN_CURRENCY_MISMATCH", "plan price currency must match payment currency")
+)
+
+// PaymentOrder 支付订单
+type PaymentOrder struct {
+	ID      int64
+	OrderNo string
+	UserID  int64
+	Kind    string
+	PlanID  *int64
+
+	Provider string
+	// Channel 子支付方式（如 epay 的 alipay / wxpay）
+	Channel  string
+	Amount   float64
+	Currency string
+	// CreditAmount 到账余额（USD），仅余额充值订单有效
+	CreditAmount float64
+
+	Status          string
+	ProviderTradeNo string
+	PaymentURL      string
+
+	// SubscriptionID / GrantedDays 记录订阅发放结果，退款时据此回收：
+	// GrantedDays > 0 表示在该订阅上续期了对应天数，退款时缩短；为 0 表示订阅由本订单单独创建，退款时撤销
+	SubscriptionID *int64
+	GrantedDays    int
+	RefundReason   string
+
+	ExpiresAt  time.Time
+	PaidAt     *time.Time
+	RefundedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// IsPending 待支付且未过期
+func (o *PaymentOrder) IsPending(now time.Time) bool {
+	return o != nil && o.Status == PaymentOrderStatusPending && now.Before(o.ExpiresAt)
+}
+
+// PaymentPlanPrice 订阅计划售价
+type PaymentPlanPrice struct {
+	PlanID    int64
+	Price     float64
+	Currency  string
+	Enabled   bool
+	UpdatedAt time.Time
+}
+
+// PaymentOrderFilter 订单列表筛选条件
+type PaymentOrderFilter struct {
+	UserID   *int64
+	Status   string
+	Provider string
+	// Search 按订单号 / 渠道流水号模糊匹配
+	Search string
+}
+
+// PaymentRepository 支付订单与计划售价存储
+type PaymentRepository interface {
+	CreateOrder(ctx context.Context, order *PaymentOrder) error
+	GetOrderByID(ctx context.Context, id int64) (*PaymentOrder, error)
+	GetOrderByNo(ctx context.Context, orderNo string) (*PaymentOrder, error)
+	GetOrderByProviderTradeNo(ctx context.Context, provider, tradeNo string) (*PaymentOrder, error)
+	// UpdateCheckout 写入渠道下单结果（支付链接、渠道流水号）
+	UpdateCheckout(ctx context.Context, id int64, paymentURL, providerTradeNo string) error
+	// MarkPaid 仅在 pending / expired / canceled 状态下标记为已支付，返回是否发生状态变更；
+	// providerTradeNo 为空时保留原值
+	MarkPaid(ctx context.Context, id int64, providerTradeNo string, paidAt time.Time) (bool, error)
+	// SetSubscriptionGrant 记录订阅发放结果
+	SetSubscriptionGrant(ctx context.Context, id, subscriptionID int64, grantedDays int) error
+	// MarkReversed 仅在 paid 状态下标记为退款 / 拒付，返回是否发生状态变更
+	MarkReversed(ctx context.Context, id int64, status, reason string, at time.Time) (bool, error)
+	// CancelOrder 用户取消待支付订单
+	CancelOrder(ctx context.Context, id, userID int64) (bool, error)
+	// ExpirePendingOrders 将过期的待支付订单标记为 expired
+	ExpirePendingOrders(ctx context.Context, now time.Time) (int64, error)
+	ListOrders(ctx context.Context, params pagination.PaginationParams, filter PaymentOrderFilter) ([]PaymentOrder, *pagination.PaginationResult, error)
+
+	ListPlanPrices(ctx context.Context) ([]PaymentPlanPrice, error)
+	GetPlanPrice(ctx context.Context, planID int64) (*PaymentPlanPrice, error)
+	UpsertPlanPrice(ctx context.Context, price *PaymentPlanPrice) error
+	DeletePlanPrice(ctx context.Context, planID int64) error
+}
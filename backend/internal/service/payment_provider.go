@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// 支付渠道标识（同时作为回调路径 /api/v1/payments/callback/:provider 的参数）
+const (
+	PaymentProviderStripe  = "stripe"
+	PaymentProviderEPay    = "epay"
+	PaymentProviderWebhook = "webhook"
+	PaymentProviderFake    = "fake"
+)
+
+// 回调事件类型
+const (
+	PaymentEventPaid       = "paid"
+	PaymentEventRefunded   = "refunded"
+	PaymentEventChargeback = "chargeback"
+	// PaymentEventIgnored 渠道推送了与订单状态无关的事件（如 Stripe 的其它事件类型），直接确认
+	PaymentEventIgnored = "ignored"
+)
+
+// PaymentCheckoutRequest 渠道下单参数
+type PaymentCheckoutRequest struct {
+	Order     *PaymentOrder
+	Subject   string
+	NotifyURL string
+	ReturnURL string
+	CancelURL string
+	UserEmail string
+}
+
+// PaymentCheckout 渠道下单结果
+type PaymentCheckout struct {
+	// PaymentURL 浏览器跳转的收银台地址
+	PaymentURL string
+	// ProviderTradeNo 渠道侧单号（如 Stripe Checkout Session ID），可为空
+	ProviderTradeNo string
+}
+
+// PaymentCallbackRequest 原始回调请求
+type PaymentCallbackRequest struct {
+	Method string
+	Header http.Header
+	Query  url.Values
+	Form   url.Values
+	Body   []byte
+}
+
+// PaymentEvent 验签后的回调事件
+type PaymentEvent struct {
+	// EventID 渠道事件唯一标识，用于幂等去重
+	EventID string
+	Type    string
+	// OrderNo 本系统订单号；部分渠道（如 Stripe 拒付）只能给出 ProviderTradeNo
+	OrderNo         string
+	ProviderTradeNo string
+	Amount          float64
+	Currency        string
+	Reason          string
+}
+
+// PaymentProvider 支付渠道适配器
+type PaymentProvider interface {
+	Name() string
+	// CreateCheckout 在渠道侧创建支付并返回收银台地址
+	CreateCheckout(ctx context.Context, req *PaymentCheckoutRequest) (*PaymentCheckout, error)
+	// ParseCallback 校验签名并解析回调；签名错误返回 ErrPaymentSignatureInvalid
+	ParseCallback(ctx context.Context, req *PaymentCallbackRequest) (*PaymentEvent, error)
+	// CallbackAck 处理成功后返回给渠道的响应体
+	CallbackAck() (contentType string, body string)
+}
+
+// PaymentChannelProvider 支持多种子支付方式的渠道（如 epay 的 alipay / wxpay）
+type PaymentChannelProvider interface {
+	Channels() []string
+}
+
+// PaymentRefunder 支持通过 API 发起原路退款的渠道；未实现的渠道需在商户后台手动退款
+type PaymentRefunder interface {
+	Refund(ctx context.Context, order *PaymentOrder, reason string) error
+}
+
+// buildPaymentProviders 按配置初始化已启用的支付渠道
+func buildPaymentProviders(cfg *config.PaymentConfig) map[string]PaymentProvider {
+	providers := make(map[string]PaymentProvider)
+	if cfg == nil {
+		return providers
+	}
+	if cfg.Stripe.Enabled {
+		providers[PaymentProviderStripe] = newStripePaymentProvider(cfg.Stripe)
+	}
+	if cfg.EPay.Enabled {
+		providers[PaymentProviderEPay] = newEPayPaymentProvider(cfg.EPay)
+	}
+	if cfg.Webhook.Enabled {
+		providers[PaymentProviderWebhook] = newWebhookPaymentProvider(cfg.Webhook)
+	}
+	if cfg.Fake.Enabled {
+		providers[PaymentProviderFake] = newFakePaymentProvider()
+	}
+	return providers
+}
+
+// paymentHMACSignature hex(HMAC-SHA256(secret, timestamp + "." + body))，Stripe 与通用 webhook 渠道共用
+func paymentHMACSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// paymentAmountEqual 金额按分比较，避免浮点误差
+func paymentAmountEqual(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
+
+// formatPaymentAmount 两位小数金额字符串
+func formatPaymentAmount(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// parsePaymentAmount 解析回调中的金额字符串，必须为正数
+func parsePaymentAmount(raw string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0, err
+	}
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount: %s", raw)
+	}
+	return amount, nil
+}
+
+// sortedPaymentParamKeys 按键名升序返回参数键（易支付签名要求）
+func sortedPaymentParamKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// paymentCallbackParams 合并 query 与 form 参数（form 优先），用于 GET / POST 两种通知方式
+func paymentCallbackParams(req *PaymentCallbackRequest) map[string]string {
+	params := make(map[string]string)
+	for k, v := range req.Query {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	for k, v := range req.Form {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	return params
+}
+
+func normalizePaymentCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const epayTradeStatusSuccess = "TRADE_SUCCESS"
+
+// epayPaymentProvider 易支付协议聚合支付（submit.php 跳转 + MD5 签名异步通知）
+type epayPaymentProvider struct {
+	cfg config.PaymentEPayConfig
+}
+
+func newEPayPaymentProvider(cfg config.PaymentEPayConfig) *epayPaymentProvider {
+	return &epayPaymentProvider{cfg: cfg}
+}
+
+func (p *epayPaymentProvider) Name() string { return PaymentProviderEPay }
+
+func (p *epayPaymentProvider) Channels() []string { return p.cfg.Channels }
+
+// CallbackAck 易支付要求返回纯文本 success，否则会重复通知
+func (p *epayPaymentProvider) CallbackAck() (string, string) {
+	return "text/plain; charset=utf-8", "success"
+}
+
+func (p *epayPaymentProvider) CreateCheckout(_ context.Context, req *PaymentCheckoutRequest) (*PaymentCheckout, error) {
+	order := req.Order
+	params := map[string]string{
+		"pid":          p.cfg.PID,
+		"type":         order.Channel,
+		"out_trade_no": order.OrderNo,
+		"notify_url":   req.NotifyURL,
+		"return_url":   req.ReturnURL,
+		"name":         req.Subject,
+		"money":        formatPaymentAmount(order.Amount),
+	}
+	params["sign"] = epaySign(params, p.cfg.Key)
+	params["sign_type"] = "MD5"
+
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return &PaymentCheckout{PaymentURL: p.cfg.Gateway + "/submit.php?" + values.Encode()}, nil
+}
+
+func (p *epayPaymentProvider) ParseCallback(_ context.Context, req *PaymentCallbackRequest) (*PaymentEvent, error) {
+	params := paymentCallbackParams(req)
+	sign := params["sign"]
+	if sign == "" || params["pid"] != p.cfg.PID {
+		return nil, ErrPaymentSignatureInvalid
+	}
+	expected := epaySign(params, p.cfg.Key)
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(sign)), []byte(expected)) != 1 {
+		return nil, ErrPaymentSignatureInvalid
+	}
+
+	tradeNo := params["trade_no"]
+	orderNo := params["out_trade_no"]
+	if orderNo == "" || tradeNo == "" {
+		return nil, ErrPaymentCallbackInvalid
+	}
+	if params["trade_status"] != epayTradeStatusSuccess {
+		return &PaymentEvent{EventID: tradeNo + ":" + params["trade_status"], Type: PaymentEventIgnored}, nil
+	}
+	amount, err := parsePaymentAmount(params["money"])
+	if err != nil {
+		return nil, ErrPaymentCallbackInvalid
+	}
+	return &PaymentEvent{
+		EventID:         tradeNo + ":" + epayTradeStatusSuccess,
+		Type:            PaymentEventPaid,
+		OrderNo:         orderNo,
+		ProviderTradeNo: tradeNo,
+		Amount:          amount,
+	}, nil
+}
+
+// epaySign md5(按键名升序拼接的 k=v&k=v + key)，跳过 sign、sign_type 与空值
+func epaySign(params map[string]string, key string) string {
+	var b strings.Builder
+	for _, k := range sortedPaymentParamKeys(params) {
+		v := params[k]
+		if k == "sign" || k == "sign_type" || v == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			_ = b.WriteByte('&')
+		}
+		_, _ = b.WriteString(k)
+		_ = b.WriteByte('=')
+		_, _ = b.WriteString(v)
+	}
+	_, _ = b.WriteString(key)
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// fakePaymentProvider 本地联调用的模拟渠道：支付链接直接指向回调地址，打开即视为支付成功。
+// 回调不校验签名，仅可在开发环境通过 payment.fake.enabled 开启。
+//
+// 回调参数（query 或 form）：order_no，status=paid|refunded|chargeback（默认 paid），amount（paid 时必填）
+type fakePaymentProvider struct{}
+
+func newFakePaymentProvider() *fakePaymentProvider {
+	return &fakePaymentProvider{}
+}
+
+func (p *fakePaymentProvider) Name() string { return PaymentProviderFake }
+
+func (p *fakePaymentProvider) CallbackAck() (string, string) {
+	return "text/plain; charset=utf-8", "success"
+}
+
+func (p *fakePaymentProvider) CreateCheckout(_ context.Context, req *PaymentCheckoutRequest) (*PaymentCheckout, error) {
+	values := url.Values{}
+	values.Set("order_no", req.Order.OrderNo)
+	values.Set("status", PaymentEventPaid)
+	values.Set("amount", formatPaymentAmount(req.Order.Amount))
+	return &PaymentCheckout{
+		PaymentURL:      req.NotifyURL + "?" + values.Encode(),
+		ProviderTradeNo: "fake_" + req.Order.OrderNo,
+	}, nil
+}
+
+func (p *fakePaymentProvider) ParseCallback(_ context.Context, req *PaymentCallbackRequest) (*PaymentEvent, error) {
+	params := paymentCallbackParams(req)
+	orderNo := strings.TrimSpace(params["order_no"])
+	if orderNo == "" {
+		return nil, ErrPaymentCallbackInvalid
+	}
+	status := strings.ToLower(strings.TrimSpace(params["status"]))
+	if status == "" {
+		status = PaymentEventPaid
+	}
+	switch status {
+	case PaymentEventPaid, PaymentEventRefunded, PaymentEventChargeback:
+	default:
+		return nil, ErrPaymentCallbackInvalid
+	}
+	evt := &PaymentEvent{
+		EventID:         orderNo + ":" + status,
+		Type:            status,
+		OrderNo:         orderNo,
+		ProviderTradeNo: "fake_" + orderNo,
+		Reason:          "fake " + status,
+	}
+	if raw := strings.TrimSpace(params["amount"]); raw != "" || status == PaymentEventPaid {
+		amount, err := parsePaymentAmount(raw)
+		if err != nil {
+			return nil, ErrPaymentCallbackInvalid
+		}
+		evt.Amount = amount
+	}
+	return evt, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+)
+
+const (
+	stripeSignatureTolerance = 5 * time.Minute
+	stripeRequestTimeout     = 15 * time.Second
+	stripeMinSessionLifetime = 30 * time.Minute
+)
+
+// stripeZeroDecimalCurrencies 无小数位币种，金额直接以元为单位提交
+var stripeZeroDecimalCurrencies = map[string]struct{}{
+	"BIF": {}, "CLP": {}, "DJF": {}, "GNF": {}, "JPY": {}, "KMF": {}, "KRW": {}, "MGA": {},
+	"PYG": {}, "RWF": {}, "UGX": {}, "VND": {}, "VUV": {}, "XAF": {}, "XOF": {}, "XPF": {},
+}
+
+// stripePaymentProvider Stripe Checkout（一次性支付）
+type stripePaymentProvider struct {
+	cfg     config.PaymentStripeConfig
+	now     func() time.Time
+	client  *http.Client
+	apiBase string
+}
+
+func newStripePaymentProvider(cfg config.PaymentStripeConfig) *stripePaymentProvider {
+	apiBase := cfg.APIBase
+	if apiBase == "" {
+		apiBase = "https://api.stripe.com"
+	}
+	return &stripePaymentProvider{cfg: cfg, now: time.Now, apiBase: apiBase}
+}
+
+func (p *stripePaymentProvider) Name() string { return PaymentProviderStripe }
+
+func (p *stripePaymentProvider) CallbackAck() (string, string) {
+	return "application/json", `{"received":true}`
+}
+
+func (p *stripePaymentProvider) CreateCheckout(ctx context.Context, req *PaymentCheckoutRequest) (*PaymentCheckout, error) {
+	order := req.Order
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", req.ReturnURL)
+	form.Set("cancel_url", req.CancelURL)
+	form.Set("client_reference_id", order.OrderNo)
+	form.Set("metadata[order_no]", order.OrderNo)
+	form.Set("payment_intent_data[metadata][order_no]", order.OrderNo)
+	// Stripe 要求 expires_at 距当前至少 30 分钟，更短的有效期由本地订单过期兜底
+	if order.ExpiresAt.Sub(p.now()) >= stripeMinSessionLifetime {
+		form.Set("expires_at", strconv.FormatInt(order.ExpiresAt.Unix(), 10))
+	}
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", strings.ToLower(order.Currency))
+	form.Set("line_items[0][price_data][unit_amount]", strconv.FormatInt(stripeMinorAmount(order.Amount, order.Currency), 10))
+	form.Set("line_items[0][price_data][product_data][name]", req.Subject)
+	if req.UserEmail != "" {
+		form.Set("customer_email", req.UserEmail)
+	}
+
+	var session struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := p.post(ctx, "/v1/checkout/sessions", form, order.OrderNo, &session); err != nil {
+		return nil, err
+	}
+	if session.URL == "" {
+		return nil, fmt.Errorf("stripe checkout session has no url")
+	}
+	return &PaymentCheckout{PaymentURL: session.URL, ProviderTradeNo: session.ID}, nil
+}
+
+// Refund 按 PaymentIntent 全额退款
+func (p *stripePaymentProvider) Refund(ctx context.Context, order *PaymentOrder, reason string) error {
+	if !strings.HasPrefix(order.ProviderTradeNo, "pi_") {
+		return fmt.Errorf("stripe order %s has no payment intent", order.OrderNo)
+	}
+	form := url.Values{}
+	form.Set("payment_intent", order.ProviderTradeNo)
+	form.Set("metadata[order_no]", order.OrderNo)
+	if reason != "" {
+		form.Set("metadata[reason]", truncateString(reason, 500))
+	}
+	return p.post(ctx, "/v1/refunds", form, "refund-"+order.OrderNo, nil)
+}
+
+func (p *stripePaymentProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) error {
+	client := p.client
+	if client == nil {
+		var err error
+		client, err = httpclient.GetClient(httpclient.Options{Timeout: stripeRequestTimeout})
+		if err != nil {
+			return err
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiBase+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.cfg.SecretKey)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("stripe request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read stripe response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return fmt.Errorf("stripe %s returned %d: %s", path, resp.StatusCode, apiErr.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+func (p *stripePaymentProvider) ParseCallback(_ context.Context, req *PaymentCallbackRequest) (*PaymentEvent, error) {
+	if err := p.verifySignature(req.Header.Get("Stripe-Signature"), req.Body); err != nil {
+		return nil, err
+	}
+	var evt stripeEvent
+	if err := json.Unmarshal(req.Body, &evt); err != nil || evt.ID == "" {
+		return nil, ErrPaymentCallbackInvalid
+	}
+
+	switch evt.Type {
+	case "checkout.session.completed", "checkout.session.async_payment_succeeded":
+		var session struct {
+			ClientReferenceID string            `json:"client_reference_id"`
+			Metadata          map[string]string `json:"metadata"`
+			AmountTotal       int64             `json:"amount_total"`
+			Currency          string            `json:"currency"`
+			PaymentStatus     string            `json:"payment_status"`
+			PaymentIntent     string            `json:"payment_intent"`
+		}
+		if err := json.Unmarshal(evt.Data.Object, &session); err != nil {
+			return nil, ErrPaymentCallbackInvalid
+		}
+		// 异步支付方式在 completed 时尚未到账，等待 async_payment_succeeded
+		if session.PaymentStatus != "paid" {
+			return &PaymentEvent{EventID: evt.ID, Type: PaymentEventIgnored}, nil
+		}
+		orderNo := session.ClientReferenceID
+		if orderNo == "" {
+			orderNo = session.Metadata["order_no"]
+		}
+		return &PaymentEvent{
+			EventID:         evt.ID,
+			Type:            PaymentEventPaid,
+			OrderNo:         orderNo,
+			ProviderTradeNo: session.PaymentIntent,
+			Amount:          stripeMajorAmount(session.AmountTotal, session.Currency),
+			Currency:        normalizePaymentCurrency(session.Currency),
+		}, nil
+
+	case "charge.refunded":
+		var charge struct {
+			Amount         int64             `json:"amount"`
+			AmountRefunded int64             `json:"amount_refunded"`
+			Currency       string            `json:"currency"`
+			PaymentIntent  string            `json:"payment_intent"`
+			Metadata       map[string]string `json:"metadata"`
+		}
+		if err := json.Unmarshal(evt.Data.Object, &charge); err != nil {
+			return nil, ErrPaymentCallbackInvalid
+		}
+		// 部分退款不回收权益，由管理员人工处理
+		if charge.AmountRefunded < charge.Amount {
+			return &PaymentEvent{EventID: evt.ID, Type: PaymentEventIgnored}, nil
+		}
+		return &PaymentEvent{
+			EventID:         evt.ID,
+			Type:            PaymentEventRefunded,
+			OrderNo:         charge.Metadata["order_no"],
+			ProviderTradeNo: charge.PaymentIntent,
+			Amount:          stripeMajorAmount(charge.AmountRefunded, charge.Currency),
+			Currency:        normalizePaymentCurrency(charge.Currency),
+			Reason:          "stripe refund",
+		}, nil
+
+	case "charge.dispute.created":
+		var dispute struct {
+			Amount        int64  `json:"amount"`
+			Currency      string `json:"currency"`
+			PaymentIntent string `json:"payment_intent"`
+			Reason        string `json:"reason"`
+		}
+		if err := json.Unmarshal(evt.Data.Object, &dispute); err != nil {
+			return nil, ErrPaymentCallbackInvalid
+		}
+		return &PaymentEvent{
+			EventID:         evt.ID,
+			Type:            PaymentEventChargeback,
+			ProviderTradeNo: dispute.PaymentIntent,
+			Amount:          stripeMajorAmount(dispute.Amount, dispute.Currency),
+			Currency:        normalizePaymentCurrency(dispute.Currency),
+			Reason:          "stripe dispute: " + dispute.Reason,
+		}, nil
+
+	default:
+		return &PaymentEvent{EventID: evt.ID, Type: PaymentEventIgnored}, nil
+	}
+}
+
+// verifySignature 校验 Stripe-Signature: t=<ts>,v1=<hex>[,v1=<hex>...]
+func (p *stripePaymentProvider) verifySignature(header string, body []byte) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrPaymentSignatureInvalid
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrPaymentSignatureInvalid
+	}
+	if math.Abs(float64(p.now().Unix()-ts)) > stripeSignatureTolerance.Seconds() {
+		return ErrPaymentSignatureInvalid
+	}
+	expected := paymentHMACSignature(p.cfg.WebhookSecret, timestamp, body)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrPaymentSignatureInvalid
+}
+
+func stripeMinorAmount(amount float64, currency string) int64 {
+	if _, ok := stripeZeroDecimalCurrencies[normalizePaymentCurrency(currency)]; ok {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+func stripeMajorAmount(amount int64, currency string) float64 {
+	if _, ok := stripeZeroDecimalCurrencies[normalizePaymentCurrency(currency)]; ok {
+		return float64(amount)
+	}
+	return float64(amount) / 100
+}
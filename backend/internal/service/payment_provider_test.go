@@ -0,0 +1,264 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEPaySign_SortsAndSkipsEmpty(t *testing.T) {
+	params := map[string]string{
+		"pid":       "1001",
+		"money":     "10.00",
+		"name":      "",
+		"sign":      "ignored",
+		"sign_type": "MD5",
+		"type":      "alipay",
+	}
+	// md5("money=10.00&pid=1001&type=alipaykey")
+	require.Equal(t, "af385a756d5546418d3062348244fbb3", epaySign(params, "key"))
+}
+
+func TestEPayProvider_CheckoutAndCallback(t *testing.T) {
+	p := newEPayPaymentProvider(config.PaymentEPayConfig{Gateway: "https://pay.example.com", PID: "1001", Key: "secret", Channels: []string{"alipay"}})
+
+	checkout, err := p.CreateCheckout(context.Background(), &PaymentCheckoutRequest{
+		Order:     &PaymentOrder{OrderNo: "P1", Channel: "alipay", Amount: 12.5},
+		Subject:   "Balance",
+		NotifyURL: "https://api.example.com/cb",
+		ReturnURL: "https://app.example.com/r",
+	})
+	require.NoError(t, err)
+	u, err := url.Parse(checkout.PaymentURL)
+	require.NoError(t, err)
+	require.Equal(t, "/submit.php", u.Path)
+	q := u.Query()
+	require.Equal(t, "12.50", q.Get("money"))
+	params := map[string]string{}
+	for k := range q {
+		params[k] = q.Get(k)
+	}
+	require.Equal(t, epaySign(params, "secret"), q.Get("sign"))
+
+	notify := map[string]string{
+		"pid":          "1001",
+		"trade_no":     "T100",
+		"out_trade_no": "P1",
+		"type":         "alipay",
+		"money":        "12.50",
+		"trade_status": "TRADE_SUCCESS",
+	}
+	query := url.Values{}
+	for k, v := range notify {
+		query.Set(k, v)
+	}
+	query.Set("sign", epaySign(notify, "secret"))
+	query.Set("sign_type", "MD5")
+
+	evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Query: query})
+	require.NoError(t, err)
+	require.Equal(t, PaymentEventPaid, evt.Type)
+	require.Equal(t, "P1", evt.OrderNo)
+	require.Equal(t, "T100", evt.ProviderTradeNo)
+	require.Equal(t, 12.5, evt.Amount)
+
+	query.Set("money", "0.01")
+	_, err = p.ParseCallback(context.Background(), &PaymentCallbackRequest{Query: query})
+	require.ErrorIs(t, err, ErrPaymentSignatureInvalid)
+}
+
+func TestEPayProvider_NonSuccessStatusIgnored(t *testing.T) {
+	p := newEPayPaymentProvider(config.PaymentEPayConfig{PID: "1001", Key: "secret"})
+	notify := map[string]string{"pid": "1001", "trade_no": "T1", "out_trade_no": "P1", "money": "1.00", "trade_status": "WAIT_BUYER_PAY"}
+	form := url.Values{}
+	for k, v := range notify {
+		form.Set(k, v)
+	}
+	form.Set("sign", epaySign(notify, "secret"))
+
+	evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Form: form})
+	require.NoError(t, err)
+	require.Equal(t, PaymentEventIgnored, evt.Type)
+}
+
+func stripeTestSignature(secret string, ts int64, body []byte) string {
+	timestamp := strconv.FormatInt(ts, 10)
+	return "t=" + timestamp + ",v1=" + paymentHMACSignature(secret, timestamp, body)
+}
+
+func TestStripeProvider_ParseCheckoutCompleted(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newStripePaymentProvider(config.PaymentStripeConfig{WebhookSecret: "whsec"})
+	p.now = func() time.Time { return now }
+
+	body := []byte(`{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"client_reference_id":"P1","amount_total":1250,"currency":"usd","payment_status":"paid","payment_intent":"pi_1"}}}`)
+	header := http.Header{}
+	header.Set("Stripe-Signature", stripeTestSignature("whsec", now.Unix(), body))
+
+	evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: header, Body: body})
+	require.NoError(t, err)
+	require.Equal(t, PaymentEventPaid, evt.Type)
+	require.Equal(t, "P1", evt.OrderNo)
+	require.Equal(t, "pi_1", evt.ProviderTradeNo)
+	require.Equal(t, 12.5, evt.Amount)
+	require.Equal(t, "USD", evt.Currency)
+}
+
+func TestStripeProvider_RejectsBadOrStaleSignature(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newStripePaymentProvider(config.PaymentStripeConfig{WebhookSecret: "whsec"})
+	p.now = func() time.Time { return now }
+	body := []byte(`{"id":"evt_1","type":"checkout.session.completed","data":{"object":{}}}`)
+
+	header := http.Header{}
+	header.Set("Stripe-Signature", stripeTestSignature("other", now.Unix(), body))
+	_, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: header, Body: body})
+	require.ErrorIs(t, err, ErrPaymentSignatureInvalid)
+
+	header.Set("Stripe-Signature", stripeTestSignature("whsec", now.Add(-10*time.Minute).Unix(), body))
+	_, err = p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: header, Body: body})
+	require.ErrorIs(t, err, ErrPaymentSignatureInvalid)
+}
+
+func TestStripeProvider_UnpaidSessionAndPartialRefundIgnored(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newStripePaymentProvider(config.PaymentStripeConfig{WebhookSecret: "whsec"})
+	p.now = func() time.Time { return now }
+
+	for _, body := range [][]byte{
+		[]byte(`{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"client_reference_id":"P1","payment_status":"unpaid"}}}`),
+		[]byte(`{"id":"evt_2","type":"charge.refunded","data":{"object":{"amount":1000,"amount_refunded":500,"currency":"usd","payment_intent":"pi_1"}}}`),
+		[]byte(`{"id":"evt_3","type":"customer.created","data":{"object":{}}}`),
+	} {
+		header := http.Header{}
+		header.Set("Stripe-Signature", stripeTestSignature("whsec", now.Unix(), body))
+		evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: header, Body: body})
+		require.NoError(t, err)
+		require.Equal(t, PaymentEventIgnored, evt.Type)
+	}
+}
+
+func TestStripeProvider_DisputeMapsToChargeback(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newStripePaymentProvider(config.PaymentStripeConfig{WebhookSecret: "whsec"})
+	p.now = func() time.Time { return now }
+	body := []byte(`{"id":"evt_9","type":"charge.dispute.created","data":{"object":{"amount":500,"currency":"jpy","payment_intent":"pi_9","reason":"fraudulent"}}}`)
+	header := http.Header{}
+	header.Set("Stripe-Signature", stripeTestSignature("whsec", now.Unix(), body))
+
+	evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: header, Body: body})
+	require.NoError(t, err)
+	require.Equal(t, PaymentEventChargeback, evt.Type)
+	require.Equal(t, "pi_9", evt.ProviderTradeNo)
+	require.Equal(t, float64(500), evt.Amount)
+}
+
+func TestStripeProvider_CreateCheckout(t *testing.T) {
+	var gotForm url.Values
+	var gotAuth, gotIdem string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/checkout/sessions", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		gotForm = r.PostForm
+		gotAuth = r.Header.Get("Authorization")
+		gotIdem = r.Header.Get("Idempotency-Key")
+		_, _ = w.Write([]byte(`{"id":"cs_1","url":"https://checkout.stripe.com/c/cs_1"}`))
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	p := newStripePaymentProvider(config.PaymentStripeConfig{SecretKey: "sk_test", APIBase: srv.URL})
+	p.now = func() time.Time { return now }
+	p.client = srv.Client()
+
+	checkout, err := p.CreateCheckout(context.Background(), &PaymentCheckoutRequest{
+		Order:   &PaymentOrder{OrderNo: "P1", Amount: 9.99, Currency: "USD", ExpiresAt: now.Add(10 * time.Minute)},
+		Subject: "Balance",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://checkout.stripe.com/c/cs_1", checkout.PaymentURL)
+	require.Equal(t, "cs_1", checkout.ProviderTradeNo)
+	require.Equal(t, "Bearer sk_test", gotAuth)
+	require.Equal(t, "P1", gotIdem)
+	require.Equal(t, "999", gotForm.Get("line_items[0][price_data][unit_amount]"))
+	require.Equal(t, "usd", gotForm.Get("line_items[0][price_data][currency]"))
+	// 有效期不足 30 分钟时不下发 expires_at
+	require.Empty(t, gotForm.Get("expires_at"))
+}
+
+func TestWebhookProvider_SignatureAndTolerance(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newWebhookPaymentProvider(config.PaymentWebhookConfig{Secret: "s3cret", TimestampToleranceSeconds: 60})
+	p.now = func() time.Time { return now }
+	body := []byte(`{"event_id":"e1","type":"PAID","order_no":"P1","trade_no":"X1","amount":5,"currency":"cny"}`)
+
+	sign := func(ts time.Time, secret string) http.Header {
+		timestamp := strconv.FormatInt(ts.Unix(), 10)
+		h := http.Header{}
+		h.Set(paymentWebhookTimestampHeader, timestamp)
+		h.Set(paymentWebhookSignatureHeader, "sha256="+paymentHMACSignature(secret, timestamp, body))
+		return h
+	}
+
+	evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: sign(now, "s3cret"), Body: body})
+	require.NoError(t, err)
+	require.Equal(t, PaymentEventPaid, evt.Type)
+	require.Equal(t, "CNY", evt.Currency)
+	require.Equal(t, "X1", evt.ProviderTradeNo)
+
+	_, err = p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: sign(now, "wrong"), Body: body})
+	require.ErrorIs(t, err, ErrPaymentSignatureInvalid)
+
+	_, err = p.ParseCallback(context.Background(), &PaymentCallbackRequest{Header: sign(now.Add(-2*time.Minute), "s3cret"), Body: body})
+	require.ErrorIs(t, err, ErrPaymentSignatureInvalid)
+}
+
+func TestWebhookProvider_CheckoutURLTemplate(t *testing.T) {
+	p := newWebhookPaymentProvider(config.PaymentWebhookConfig{CheckoutURL: "https://cashier.example.com/pay?o={order_no}&a={amount}&c={currency}&n={notify_url}"})
+	checkout, err := p.CreateCheckout(context.Background(), &PaymentCheckoutRequest{
+		Order:     &PaymentOrder{OrderNo: "P1", Amount: 3, Currency: "CNY"},
+		NotifyURL: "https://api.example.com/cb?x=1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://cashier.example.com/pay?o=P1&a=3.00&c=CNY&n="+url.QueryEscape("https://api.example.com/cb?x=1"), checkout.PaymentURL)
+}
+
+func TestFakeProvider_RoundTrip(t *testing.T) {
+	p := newFakePaymentProvider()
+	checkout, err := p.CreateCheckout(context.Background(), &PaymentCheckoutRequest{
+		Order:     &PaymentOrder{OrderNo: "P1", Amount: 8},
+		NotifyURL: "http://localhost/api/v1/payments/callback/fake",
+	})
+	require.NoError(t, err)
+	u, err := url.Parse(checkout.PaymentURL)
+	require.NoError(t, err)
+
+	evt, err := p.ParseCallback(context.Background(), &PaymentCallbackRequest{Query: u.Query()})
+	require.NoError(t, err)
+	require.Equal(t, PaymentEventPaid, evt.Type)
+	require.Equal(t, "P1", evt.OrderNo)
+	require.Equal(t, float64(8), evt.Amount)
+
+	_, err = p.ParseCallback(context.Background(), &PaymentCallbackRequest{Query: url.Values{"order_no": {"P1"}, "status": {"bogus"}}})
+	require.ErrorIs(t, err, ErrPaymentCallbackInvalid)
+}
+
+func TestPaymentCallbackIdempotencyKey(t *testing.T) {
+	require.Equal(t, "stripe:evt_1", paymentCallbackIdempotencyKey("stripe", "evt_1"))
+
+	long := strings.Repeat("x", 200)
+	key := paymentCallbackIdempotencyKey("webhook", long)
+	require.True(t, strings.HasPrefix(key, "webhook:sha256:"))
+	_, err := NormalizeIdempotencyKey(key)
+	require.NoError(t, err)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	paymentWebhookSignatureHeader = "X-Sub2API-Signature"
+	paymentWebhookTimestampHeader = "X-Sub2API-Timestamp"
+)
+
+// webhookPaymentProvider 通用 HMAC 回调渠道：跳转到自建收银台，由收银台以签名 JSON 回调订单状态
+type webhookPaymentProvider struct {
+	cfg config.PaymentWebhookConfig
+	now func() time.Time
+}
+
+func newWebhookPaymentProvider(cfg config.PaymentWebhookConfig) *webhookPaymentProvider {
+	return &webhookPaymentProvider{cfg: cfg, now: time.Now}
+}
+
+func (p *webhookPaymentProvider) Name() string { return PaymentProviderWebhook }
+
+func (p *webhookPaymentProvider) CallbackAck() (string, string) {
+	return "application/json", `{"ok":true}`
+}
+
+func (p *webhookPaymentProvider) CreateCheckout(_ context.Context, req *PaymentCheckoutRequest) (*PaymentCheckout, error) {
+	order := req.Order
+	replacer := strings.NewReplacer(
+		"{order_no}", url.QueryEscape(order.OrderNo),
+		"{amount}", formatPaymentAmount(order.Amount),
+		"{currency}", url.QueryEscape(order.Currency),
+		"{notify_url}", url.QueryEscape(req.NotifyURL),
+		"{return_url}", url.QueryEscape(req.ReturnURL),
+	)
+	return &PaymentCheckout{PaymentURL: replacer.Replace(p.cfg.CheckoutURL)}, nil
+}
+
+// paymentWebhookPayload 通用回调请求体
+type paymentWebhookPayload struct {
+	EventID  string  `json:"event_id"`
+	Type     string  `json:"type"`
+	OrderNo  string  `json:"order_no"`
+	TradeNo  string  `json:"trade_no"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	Reason   string  `json:"reason"`
+}
+
+func (p *webhookPaymentProvider) ParseCallback(_ context.Context, req *PaymentCallbackRequest) (*PaymentEvent, error) {
+	timestamp := strings.TrimSpace(req.Header.Get(paymentWebhookTimestampHeader))
+	signature := strings.TrimPrefix(strings.TrimSpace(req.Header.Get(paymentWebhookSignatureHeader)), "sha256=")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return nil, ErrPaymentSignatureInvalid
+	}
+	if math.Abs(float64(p.now().Unix()-ts)) > float64(p.cfg.TimestampToleranceSeconds) {
+		return nil, ErrPaymentSignatureInvalid
+	}
+	expected := paymentHMACSignature(p.cfg.Secret, timestamp, req.Body)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return nil, ErrPaymentSignatureInvalid
+	}
+
+	var payload paymentWebhookPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, ErrPaymentCallbackInvalid
+	}
+	payload.Type = strings.ToLower(strings.TrimSpace(payload.Type))
+	switch payload.Type {
+	case PaymentEventPaid, PaymentEventRefunded, PaymentEventChargeback:
+	default:
+		return nil, ErrPaymentCallbackInvalid
+	}
+	if payload.EventID == "" || (payload.OrderNo == "" && payload.TradeNo == "") {
+		return nil, ErrPaymentCallbackInvalid
+	}
+	return &PaymentEvent{
+		EventID:         payload.EventID,
+		Type:            payload.Type,
+		OrderNo:         payload.OrderNo,
+		ProviderTradeNo: payload.TradeNo,
+		Amount:          payload.Amount,
+		Currency:        normalizePaymentCurrency(payload.Currency),
+		Reason:          payload.Reason,
+	}, nil
+}
@@ -0,0 +1,824 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const (
+	paymentCallbackIdempotencyScope = "payment.callback"
+	paymentCallbackRoute            = "/api/v1/payments/callback/:provider"
+)
+
+// CreatePaymentOrderInput 用户下单参数
+type CreatePaymentOrderInput struct {
+	Kind     string
+	Amount   float64
+	PlanID   int64
+	Provider string
+	Channel  string
+}
+
+// PaymentOptions 用户端下单页展示的可选项
+type PaymentOptions struct {
+	Enabled     bool                    `json:"enabled"`
+	Currency    string                  `json:"currency"`
+	BalanceRate float64                 `json:"balance_rate"`
+	MinAmount   float64                 `json:"min_amount"`
+	MaxAmount   float64                 `json:"max_amount"`
+	Providers   []PaymentProviderOption `json:"providers"`
+	Plans       []PaymentPlanOption     `json:"plans"`
+}
+
+// PaymentProviderOption 可用支付渠道
+type PaymentProviderOption struct {
+	Name     string   `json:"name"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+// PaymentPlanOption 可购买的订阅计划
+type PaymentPlanOption struct {
+	Plan     SubscriptionPlan `json:"plan"`
+	Price    float64          `json:"price"`
+	Currency string           `json:"currency"`
+}
+
+// PaymentService 原生支付订单服务：下单、回调到账、退款/拒付回收
+type PaymentService struct {
+	cfg                  config.PaymentConfig
+	repo                 PaymentRepository
+	userRepo             UserRepository
+	balanceLedgerRepo    BalanceLedgerRepository
+	subscriptionService  *SubscriptionService
+	planRepo             SubscriptionPlanRepository
+	billingCacheService  *BillingCacheService
+	entClient            *dbent.Client
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+
+	providers map[string]PaymentProvider
+	now       func() time.Time
+}
+
+// NewPaymentService 创建支付订单服务
+func NewPaymentService(
+	cfg *config.Config,
+	repo PaymentRepository,
+	userRepo UserRepository,
+	balanceLedgerRepo BalanceLedgerRepository,
+	subscriptionService *SubscriptionService,
+	planRepo SubscriptionPlanRepository,
+	billingCacheService *BillingCacheService,
+	entClient *dbent.Client,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+) *PaymentService {
+	s := &PaymentService{
+		repo:                 repo,
+		userRepo:             userRepo,
+		balanceLedgerRepo:    balanceLedgerRepo,
+		subscriptionService:  subscriptionService,
+		planRepo:             planRepo,
+		billingCacheService:  billingCacheService,
+		entClient:            entClient,
+		authCacheInvalidator: authCacheInvalidator,
+		now:                  time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.Payment
+	}
+	s.providers = buildPaymentProviders(&s.cfg)
+	return s
+}
+
+func (s *PaymentService) requireEnabled() error {
+	if s == nil || !s.cfg.Enabled || s.repo == nil {
+		return ErrPaymentDisabled
+	}
+	return nil
+}
+
+func (s *PaymentService) provider(name string) (PaymentProvider, error) {
+	p, ok := s.providers[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, ErrPaymentProviderUnavailable
+	}
+	return p, nil
+}
+
+// GetOptions 返回下单页可用的渠道、金额范围与可购买的订阅计划
+func (s *PaymentService) GetOptions(ctx context.Context) (*PaymentOptions, error) {
+	if s == nil || !s.cfg.Enabled {
+		return &PaymentOptions{Enabled: false, Providers: []PaymentProviderOption{}, Plans: []PaymentPlanOption{}}, nil
+	}
+	out := &PaymentOptions{
+		Enabled:     true,
+		Currency:    s.cfg.Currency,
+		BalanceRate: s.cfg.BalanceRate,
+		MinAmount:   s.cfg.MinAmount,
+		MaxAmount:   s.cfg.MaxAmount,
+		Providers:   make([]PaymentProviderOption, 0, len(s.providers)),
+		Plans:       []PaymentPlanOption{},
+	}
+	for _, name := range []string{PaymentProviderStripe, PaymentProviderEPay, PaymentProviderWebhook, PaymentProviderFake} {
+		p, ok := s.providers[name]
+		if !ok {
+			continue
+		}
+		opt := PaymentProviderOption{Name: name}
+		if cp, ok := p.(PaymentChannelProvider); ok {
+			opt.Channels = cp.Channels()
+		}
+		out.Providers = append(out.Providers, opt)
+	}
+
+	if s.planRepo == nil {
+		return out, nil
+	}
+	prices, err := s.repo.ListPlanPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		if !price.Enabled || price.Currency != s.cfg.Currency {
+			continue
+		}
+		plan, err := s.planRepo.GetByID(ctx, price.PlanID)
+		if err != nil {
+			if errors.Is(err, ErrSubscriptionPlanNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if plan.Status != SubscriptionPlanStatusActive || plan.GroupID == nil {
+			continue
+		}
+		out.Plans = append(out.Plans, PaymentPlanOption{Plan: *plan, Price: price.Price, Currency: price.Currency})
+	}
+	return out, nil
+}
+
+// CreateOrder 创建订单并在渠道侧下单，返回带支付链接的订单
+func (s *PaymentService) CreateOrder(ctx context.Context, userID int64, input *CreatePaymentOrderInput) (*PaymentOrder, error) {
+	if err := s.requireEnabled(); err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return nil, infraerrors.BadRequest("INVALID_PAYMENT_ORDER", "order input is required")
+	}
+	provider, err := s.provider(input.Provider)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := resolvePaymentChannel(provider, input.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	now := s.now()
+	order := &PaymentOrder{
+		UserID:    userID,
+		Kind:      strings.TrimSpace(input.Kind),
+		Provider:  provider.Name(),
+		Channel:   channel,
+		Currency:  s.cfg.Currency,
+		Status:    PaymentOrderStatusPending,
+		ExpiresAt: now.Add(time.Duration(s.cfg.OrderExpireMinutes) * time.Minute),
+	}
+	var subject string
+	switch order.Kind {
+	case PaymentOrderKindBalance:
+		amount := math.Round(input.Amount*100) / 100
+		if amount <= 0 || amount < s.cfg.MinAmount || (s.cfg.MaxAmount > 0 && amount > s.cfg.MaxAmount) {
+			return nil, ErrPaymentAmountInvalid
+		}
+		order.Amount = amount
+		order.CreditAmount = roundTo8DP(amount * s.cfg.BalanceRate)
+		subject = fmt.Sprintf("余额充值 %s %s", formatPaymentAmount(amount), order.Currency)
+
+	case PaymentOrderKindSubscription:
+		plan, price, err := s.purchasablePlan(ctx, input.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		planID := plan.ID
+		order.PlanID = &planID
+		order.Amount = price.Price
+		subject = "订阅计划：" + plan.Name
+
+	default:
+		return nil, infraerrors.BadRequest("INVALID_PAYMENT_ORDER_KIND", "order kind must be balance or subscription")
+	}
+
+	orderNo, err := generatePaymentOrderNo(now)
+	if err != nil {
+		return nil, err
+	}
+	order.OrderNo = orderNo
+	if err := s.repo.CreateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	returnURL := s.returnURL(order.OrderNo)
+	checkout, err := provider.CreateCheckout(ctx, &PaymentCheckoutRequest{
+		Order:     order,
+		Subject:   subject,
+		NotifyURL: s.cfg.PublicBaseURL + "/api/v1/payments/callback/" + provider.Name(),
+		ReturnURL: returnURL,
+		CancelURL: returnURL,
+		UserEmail: user.Email,
+	})
+	if err != nil {
+		logger.LegacyPrintf("service.payment", "[Payment] create checkout failed: provider=%s order=%s err=%v", provider.Name(), order.OrderNo, err)
+		if _, cancelErr := s.repo.CancelOrder(ctx, order.ID, userID); cancelErr != nil {
+			logger.LegacyPrintf("service.payment", "[Payment] cancel order %s failed: %v", order.OrderNo, cancelErr)
+		}
+		return nil, infraerrors.ServiceUnavailable("PAYMENT_CHECKOUT_FAILED", "failed to create payment, please try again later").WithCause(err)
+	}
+	if err := s.repo.UpdateCheckout(ctx, order.ID, checkout.PaymentURL, checkout.ProviderTradeNo); err != nil {
+		return nil, err
+	}
+	order.PaymentURL = checkout.PaymentURL
+	if checkout.ProviderTradeNo != "" {
+		order.ProviderTradeNo = checkout.ProviderTradeNo
+	}
+	return order, nil
+}
+
+func (s *PaymentService) purchasablePlan(ctx context.Context, planID int64) (*SubscriptionPlan, *PaymentPlanPrice, error) {
+	if planID <= 0 || s.planRepo == nil {
+		return nil, nil, ErrPaymentPlanNotForSale
+	}
+	price, err := s.repo.GetPlanPrice(ctx, planID)
+	if err != nil {
+		if errors.Is(err, ErrPaymentPlanPriceNotFound) {
+			return nil, nil, ErrPaymentPlanNotForSale
+		}
+		return nil, nil, err
+	}
+	if !price.Enabled || price.Currency != s.cfg.Currency {
+		return nil, nil, ErrPaymentPlanNotForSale
+	}
+	plan, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, ErrSubscriptionPlanNotFound) {
+			return nil, nil, ErrPaymentPlanNotForSale
+		}
+		return nil, nil, err
+	}
+	if plan.Status != SubscriptionPlanStatusActive || plan.GroupID == nil {
+		return nil, nil, ErrPaymentPlanNotForSale
+	}
+	return plan, price, nil
+}
+
+func (s *PaymentService) returnURL(orderNo string) string {
+	base := s.cfg.ReturnURL
+	if base == "" {
+		base = s.cfg.PublicBaseURL + "/"
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+	q := u.Query()
+	q.Set("order_no", orderNo)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// GetUserOrder 获取当前用户的订单
+func (s *PaymentService) GetUserOrder(ctx context.Context, userID int64, orderNo string) (*PaymentOrder, error) {
+	if err := s.requireEnabled(); err != nil {
+		return nil, err
+	}
+	order, err := s.repo.GetOrderByNo(ctx, strings.TrimSpace(orderNo))
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrPaymentOrderNotFound
+	}
+	s.applyLocalExpiry(order)
+	return order, nil
+}
+
+// ListUserOrders 当前用户的订单列表
+func (s *PaymentService) ListUserOrders(ctx context.Context, userID int64, params pagination.PaginationParams, status string) ([]PaymentOrder, *pagination.PaginationResult, error) {
+	if err := s.requireEnabled(); err != nil {
+		return nil, nil, err
+	}
+	s.expirePendingOrders(ctx)
+	return s.repo.ListOrders(ctx, params, PaymentOrderFilter{UserID: &userID, Status: strings.TrimSpace(status)})
+}
+
+// CancelUserOrder 用户取消待支付订单
+func (s *PaymentService) CancelUserOrder(ctx context.Context, userID int64, orderNo string) error {
+	order, err := s.GetUserOrder(ctx, userID, orderNo)
+	if err != nil {
+		return err
+	}
+	if order.Status != PaymentOrderStatusPending {
+		return ErrPaymentOrderNotPending
+	}
+	ok, err := s.repo.CancelOrder(ctx, order.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPaymentOrderNotPending
+	}
+	return nil
+}
+
+// ListOrders 管理员订单列表
+func (s *PaymentService) ListOrders(ctx context.Context, params pagination.PaginationParams, filter PaymentOrderFilter) ([]PaymentOrder, *pagination.PaginationResult, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil, ErrPaymentDisabled
+	}
+	s.expirePendingOrders(ctx)
+	return s.repo.ListOrders(ctx, params, filter)
+}
+
+// GetOrder 管理员查看订单
+func (s *PaymentService) GetOrder(ctx context.Context, id int64) (*PaymentOrder, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrPaymentDisabled
+	}
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.applyLocalExpiry(order)
+	return order, nil
+}
+
+func (s *PaymentService) applyLocalExpiry(order *PaymentOrder) {
+	if order != nil && order.Status == PaymentOrderStatusPending && !order.IsPending(s.now()) {
+		order.Status = PaymentOrderStatusExpired
+	}
+}
+
+func (s *PaymentService) expirePendingOrders(ctx context.Context) {
+	if _, err := s.repo.ExpirePendingOrders(ctx, s.now()); err != nil {
+		logger.LegacyPrintf("service.payment", "[Payment] expire pending orders failed: %v", err)
+	}
+}
+
+// HandleCallback 校验并处理渠道回调，返回需回写给渠道的确认响应。
+// 同一渠道事件通过 IdempotencyCoordinator 去重，订单状态流转本身也带条件更新，重复投递不会重复到账。
+func (s *PaymentService) HandleCallback(ctx context.Context, providerName string, req *PaymentCallbackRequest) (string, string, error) {
+	if err := s.requireEnabled(); err != nil {
+		return "", "", err
+	}
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+	evt, err := provider.ParseCallback(ctx, req)
+	if err != nil {
+		logger.LegacyPrintf("service.payment", "[Payment] reject callback: provider=%s err=%v", provider.Name(), err)
+		return "", "", err
+	}
+	contentType, ack := provider.CallbackAck()
+	if evt.Type == PaymentEventIgnored {
+		return contentType, ack, nil
+	}
+
+	if err := s.executeCallbackIdempotent(ctx, provider.Name(), evt); err != nil {
+		return "", "", err
+	}
+	return contentType, ack, nil
+}
+
+func (s *PaymentService) executeCallbackIdempotent(ctx context.Context, providerName string, evt *PaymentEvent) error {
+	apply := func(ctx context.Context) (any, error) {
+		order, err := s.applyEvent(ctx, providerName, evt)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"order_no": order.OrderNo, "status": order.Status}, nil
+	}
+
+	coordinator := DefaultIdempotencyCoordinator()
+	if coordinator == nil {
+		_, err := apply(ctx)
+		return err
+	}
+	_, err := coordinator.Execute(ctx, IdempotencyExecuteOptions{
+		Scope:          paymentCallbackIdempotencyScope,
+		ActorScope:     "provider:" + providerName,
+		Method:         "POST",
+		Route:          paymentCallbackRoute,
+		IdempotencyKey: paymentCallbackIdempotencyKey(providerName, evt.EventID),
+		Payload:        evt,
+		RequireKey:     true,
+		TTL:            DefaultSystemOperationIdempotencyTTL(),
+	}, apply)
+	return err
+}
+
+// paymentCallbackIdempotencyKey 渠道事件 ID 可能超长或含不可打印字符，超出幂等键约束时取哈希
+func paymentCallbackIdempotencyKey(providerName, eventID string) string {
+	key := providerName + ":" + eventID
+	if normalized, err := NormalizeIdempotencyKey(key); err == nil && normalized == key {
+		return key
+	}
+	return providerName + ":sha256:" + HashIdempotencyKey(eventID)
+}
+
+func (s *PaymentService) applyEvent(ctx context.Context, providerName string, evt *PaymentEvent) (*PaymentOrder, error) {
+	var order *PaymentOrder
+	var err error
+	if evt.OrderNo != "" {
+		order, err = s.repo.GetOrderByNo(ctx, evt.OrderNo)
+	} else if evt.ProviderTradeNo != "" {
+		order, err = s.repo.GetOrderByProviderTradeNo(ctx, providerName, evt.ProviderTradeNo)
+	} else {
+		return nil, ErrPaymentCallbackInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 订单必须由同一渠道创建，防止通过其它渠道（如未签名的 fake 渠道）伪造到账
+	if order.Provider != providerName {
+		return nil, ErrPaymentOrderNotFound
+	}
+
+	switch evt.Type {
+	case PaymentEventPaid:
+		if err := s.fulfillOrder(ctx, order, evt); err != nil {
+			return nil, err
+		}
+	case PaymentEventRefunded, PaymentEventChargeback:
+		status := PaymentOrderStatusRefunded
+		if evt.Type == PaymentEventChargeback {
+			status = PaymentOrderStatusChargedBack
+		}
+		if err := s.reverseOrder(ctx, order, status, evt.Reason, BalanceLedgerActorProvider); err != nil {
+			// 未到账订单的退款通知无需回收，确认即可
+			if errors.Is(err, ErrPaymentOrderNotPaid) {
+				logger.LegacyPrintf("service.payment", "[Payment] ignore %s for unpaid order %s (status=%s)", evt.Type, order.OrderNo, order.Status)
+				return order, nil
+			}
+			return nil, err
+		}
+	default:
+		return nil, ErrPaymentCallbackInvalid
+	}
+	return s.repo.GetOrderByID(ctx, order.ID)
+}
+
+// fulfillOrder 订单到账：标记已支付并在同一事务中发放余额或订阅
+func (s *PaymentService) fulfillOrder(ctx context.Context, order *PaymentOrder, evt *PaymentEvent) error {
+	switch order.Status {
+	case PaymentOrderStatusPaid, PaymentOrderStatusRefunded, PaymentOrderStatusChargedBack:
+		return nil
+	}
+	if !paymentAmountEqual(evt.Amount, order.Amount) {
+		logger.LegacyPrintf("service.payment", "[Payment] amount mismatch: order=%s expected=%.2f got=%.2f", order.OrderNo, order.Amount, evt.Amount)
+		return ErrPaymentAmountMismatch
+	}
+	if evt.Currency != "" && evt.Currency != order.Currency {
+		logger.LegacyPrintf("service.payment", "[Payment] currency mismatch: order=%s expected=%s got=%s", order.OrderNo, order.Currency, evt.Currency)
+		return ErrPaymentAmountMismatch
+	}
+
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	txCtx := dbent.NewTxContext(ctx, tx)
+
+	// 先做条件状态流转：并发回调中只有一个事务能成功，其余在行锁释放后得到 false
+	updated, err := s.repo.MarkPaid(txCtx, order.ID, evt.ProviderTradeNo, s.now())
+	if err != nil {
+		return fmt.Errorf("mark order paid: %w", err)
+	}
+	if !updated {
+		return nil
+	}
+
+	var groupID int64
+	switch order.Kind {
+	case PaymentOrderKindBalance:
+		if err := s.balanceLedgerRepo.Apply(txCtx, &BalanceLedgerEntry{
+			UserID:        order.UserID,
+			EntryType:     BalanceLedgerEntryTopup,
+			Amount:        order.CreditAmount,
+			ReferenceType: BalanceLedgerRefPaymentOrder,
+			ReferenceID:   order.OrderNo,
+			ActorType:     BalanceLedgerActorProvider,
+			Notes:         fmt.Sprintf("%s %.2f %s", order.Provider, order.Amount, order.Currency),
+		}); err != nil {
+			return fmt.Errorf("update user balance: %w", err)
+		}
+	case PaymentOrderKindSubscription:
+		groupID, err = s.grantSubscription(txCtx, order)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported order kind: %s", order.Kind)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	logger.LegacyPrintf("service.payment", "[Payment] order paid: order=%s user=%d kind=%s amount=%.2f %s", order.OrderNo, order.UserID, order.Kind, order.Amount, order.Currency)
+	s.invalidateCaches(ctx, order.UserID, order.Kind, groupID)
+	return nil
+}
+
+func (s *PaymentService) grantSubscription(ctx context.Context, order *PaymentOrder) (int64, error) {
+	if order.PlanID == nil || s.planRepo == nil || s.subscriptionService == nil {
+		return 0, fmt.Errorf("subscription order %s has no plan", order.OrderNo)
+	}
+	plan, err := s.planRepo.GetByID(ctx, *order.PlanID)
+	if err != nil {
+		return 0, fmt.Errorf("subscription plan not found: %w", err)
+	}
+	if plan.GroupID == nil {
+		return 0, fmt.Errorf("subscription plan %d has no group_id", plan.ID)
+	}
+	groupID := *plan.GroupID
+
+	if err := s.userRepo.AddGroupToAllowedGroups(ctx, order.UserID, groupID); err != nil {
+		return 0, fmt.Errorf("add group to user allowed groups: %w", err)
+	}
+
+	if plan.IsPerRequest() {
+		// 按次模式：创建独立订阅，退款时整体撤销
+		sub, err := s.subscriptionService.CreateRequestQuotaSubscription(ctx, &AssignSubscriptionInput{
+			UserID:       order.UserID,
+			GroupID:      groupID,
+			ValidityDays: plan.ValidityDays,
+			Notes:        fmt.Sprintf("通过订单 %s 购买计划「%s」(%d 次)", order.OrderNo, plan.Name, plan.RequestQuota),
+			RequestQuota: plan.RequestQuota,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("create request quota subscription: %w", err)
+		}
+		return groupID, s.repo.SetSubscriptionGrant(ctx, order.ID, sub.ID, 0)
+	}
+
+	// USD 模式：创建/续期订阅
+	sub, extended, err := s.subscriptionService.AssignOrExtendSubscription(ctx, &AssignSubscriptionInput{
+		UserID:       order.UserID,
+		GroupID:      groupID,
+		ValidityDays: plan.ValidityDays,
+		Notes:        fmt.Sprintf("通过订单 %s 购买计划「%s」", order.OrderNo, plan.Name),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("assign subscription: %w", err)
+	}
+	grantedDays := 0
+	if extended {
+		grantedDays = plan.ValidityDays
+		if grantedDays <= 0 {
+			grantedDays = 30
+		}
+	}
+	return groupID, s.repo.SetSubscriptionGrant(ctx, order.ID, sub.ID, grantedDays)
+}
+
+// reverseOrder 退款 / 拒付：回收已发放的余额或订阅。余额允许被扣为负数，由后续充值抵扣。
+func (s *PaymentService) reverseOrder(ctx context.Context, order *PaymentOrder, status, reason, actorType string) error {
+	switch order.Status {
+	case PaymentOrderStatusRefunded, PaymentOrderStatusChargedBack:
+		return nil
+	case PaymentOrderStatusPaid:
+	default:
+		return ErrPaymentOrderNotPaid
+	}
+
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	txCtx := dbent.NewTxContext(ctx, tx)
+
+	updated, err := s.repo.MarkReversed(txCtx, order.ID, status, truncateString(reason, 500), s.now())
+	if err != nil {
+		return fmt.Errorf("mark order reversed: %w", err)
+	}
+	if !updated {
+		return nil
+	}
+
+	var groupID int64
+	switch order.Kind {
+	case PaymentOrderKindBalance:
+		entryType := BalanceLedgerEntryRefund
+		if status == PaymentOrderStatusChargedBack {
+			entryType = BalanceLedgerEntryChargeback
+		}
+		if err := s.balanceLedgerRepo.Apply(txCtx, &BalanceLedgerEntry{
+			UserID:        order.UserID,
+			EntryType:     entryType,
+			Amount:        -order.CreditAmount,
+			ReferenceType: BalanceLedgerRefPaymentOrder,
+			ReferenceID:   order.OrderNo,
+			ActorType:     actorType,
+			Notes:         truncateString(reason, 500),
+		}); err != nil {
+			return fmt.Errorf("revert user balance: %w", err)
+		}
+	case PaymentOrderKindSubscription:
+		groupID, err = s.revokeSubscriptionGrant(txCtx, order)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	logger.LegacyPrintf("service.payment", "[Payment] order %s: order=%s user=%d reason=%s", status, order.OrderNo, order.UserID, reason)
+	s.invalidateCaches(ctx, order.UserID, order.Kind, groupID)
+	return nil
+}
+
+// revokeSubscriptionGrant 续期的订阅缩短对应天数（缩短后会过期则直接撤销），单独创建的订阅直接撤销
+func (s *PaymentService) revokeSubscriptionGrant(ctx context.Context, order *PaymentOrder) (int64, error) {
+	if order.SubscriptionID == nil || s.subscriptionService == nil {
+		return 0, nil
+	}
+	sub, err := s.subscriptionService.GetByID(ctx, *order.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if order.GrantedDays > 0 {
+		_, err := s.subscriptionService.ExtendSubscription(ctx, sub.ID, -order.GrantedDays)
+		switch {
+		case err == nil:
+			return sub.GroupID, nil
+		case errors.Is(err, ErrAdjustWouldExpire):
+			// 剩余时长全部来自本订单，撤销订阅
+		case infraerrors.Reason(err) == "CANNOT_SHORTEN_EXPIRED":
+			return sub.GroupID, nil
+		default:
+			return 0, fmt.Errorf("shorten subscription: %w", err)
+		}
+	}
+	if err := s.subscriptionService.RevokeSubscription(ctx, sub.ID); err != nil {
+		return 0, fmt.Errorf("revoke subscription: %w", err)
+	}
+	return sub.GroupID, nil
+}
+
+// RefundOrder 管理员退款：viaProvider 为 true 且渠道支持 API 退款时先原路退款，再回收权益
+func (s *PaymentService) RefundOrder(ctx context.Context, id int64, reason string, viaProvider bool) (*PaymentOrder, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrPaymentDisabled
+	}
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != PaymentOrderStatusPaid {
+		return nil, ErrPaymentOrderNotPaid
+	}
+	if viaProvider {
+		provider, err := s.provider(order.Provider)
+		if err != nil {
+			return nil, err
+		}
+		refunder, ok := provider.(PaymentRefunder)
+		if !ok {
+			return nil, infraerrors.BadRequest("PAYMENT_REFUND_UNSUPPORTED", "provider does not support API refunds, refund in the merchant dashboard and retry without via_provider")
+		}
+		if err := refunder.Refund(ctx, order, reason); err != nil {
+			return nil, infraerrors.ServiceUnavailable("PAYMENT_REFUND_FAILED", "provider refund failed").WithCause(err)
+		}
+	}
+	if strings.TrimSpace(reason) == "" {
+		reason = "admin refund"
+	}
+	if err := s.reverseOrder(ctx, order, PaymentOrderStatusRefunded, reason, BalanceLedgerActorAdmin); err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrderByID(ctx, id)
+}
+
+// ListPlanPrices 管理员查看订阅计划售价
+func (s *PaymentService) ListPlanPrices(ctx context.Context) ([]PaymentPlanPrice, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrPaymentDisabled
+	}
+	return s.repo.ListPlanPrices(ctx)
+}
+
+// UpsertPlanPrice 设置订阅计划售价
+func (s *PaymentService) UpsertPlanPrice(ctx context.Context, price *PaymentPlanPrice) (*PaymentPlanPrice, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrPaymentDisabled
+	}
+	if price == nil || price.PlanID <= 0 {
+		return nil, infraerrors.BadRequest("INVALID_PLAN_ID", "invalid plan id")
+	}
+	price.Price = math.Round(price.Price*100) / 100
+	if price.Price <= 0 {
+		return nil, ErrPaymentAmountInvalid
+	}
+	price.Currency = normalizePaymentCurrency(price.Currency)
+	if price.Currency == "" {
+		price.Currency = s.cfg.Currency
+	}
+	if s.cfg.Currency != "" && price.Currency != s.cfg.Currency {
+		return nil, ErrPaymentPlanCurrencyMismatch
+	}
+	if s.planRepo != nil {
+		if _, err := s.planRepo.GetByID(ctx, price.PlanID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.UpsertPlanPrice(ctx, price); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPlanPrice(ctx, price.PlanID)
+}
+
+// DeletePlanPrice 删除订阅计划售价（计划不再可购买）
+func (s *PaymentService) DeletePlanPrice(ctx context.Context, planID int64) error {
+	if s == nil || s.repo == nil {
+		return ErrPaymentDisabled
+	}
+	return s.repo.DeletePlanPrice(ctx, planID)
+}
+
+func (s *PaymentService) invalidateCaches(ctx context.Context, userID int64, kind string, groupID int64) {
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+	if kind == PaymentOrderKindSubscription && groupID > 0 && s.subscriptionService != nil {
+		s.subscriptionService.InvalidateSubCache(userID, groupID)
+	}
+	if s.billingCacheService == nil {
+		return
+	}
+	go func() {
+		cacheCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if kind == PaymentOrderKindBalance {
+			_ = s.billingCacheService.InvalidateUserBalance(cacheCtx, userID)
+			return
+		}
+		if groupID > 0 {
+			_ = s.billingCacheService.InvalidateSubscription(cacheCtx, userID, groupID)
+		}
+	}()
+}
+
+func resolvePaymentChannel(provider PaymentProvider, channel string) (string, error) {
+	cp, ok := provider.(PaymentChannelProvider)
+	if !ok {
+		return "", nil
+	}
+	channels := cp.Channels()
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" && len(channels) > 0 {
+		return channels[0], nil
+	}
+	for _, c := range channels {
+		if c == channel {
+			return c, nil
+		}
+	}
+	return "", infraerrors.BadRequest("PAYMENT_CHANNEL_UNAVAILABLE", "payment channel is not enabled")
+}
+
+// generatePaymentOrderNo 形如 P20261017153000a1b2c3d4e5f6
+func generatePaymentOrderNo(now time.Time) (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate order no: %w", err)
+	}
+	return "P" + now.UTC().Format("20060102150405") + hex.EncodeToString(buf), nil
+}
+
+func roundTo8DP(v float64) float64 {
+	return math.Round(v*1e8) / 1e8
+}
@@ -0,0 +1,151 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type paymentRepoStub struct {
+	PaymentRepository
+	orders     map[string]*PaymentOrder
+	markPaid   int
+	markRevert int
+}
+
+func (r *paymentRepoStub) GetOrderByNo(_ context.Context, orderNo string) (*PaymentOrder, error) {
+	if o, ok := r.orders[orderNo]; ok {
+		cp := *o
+		return &cp, nil
+	}
+	return nil, ErrPaymentOrderNotFound
+}
+
+func (r *paymentRepoStub) GetOrderByID(_ context.Context, id int64) (*PaymentOrder, error) {
+	for _, o := range r.orders {
+		if o.ID == id {
+			cp := *o
+			return &cp, nil
+		}
+	}
+	return nil, ErrPaymentOrderNotFound
+}
+
+func (r *paymentRepoStub) MarkPaid(context.Context, int64, string, time.Time) (bool, error) {
+	r.markPaid++
+	return true, nil
+}
+
+func (r *paymentRepoStub) MarkReversed(context.Context, int64, string, string, time.Time) (bool, error) {
+	r.markRevert++
+	return true, nil
+}
+
+func newPaymentServiceForTest(repo PaymentRepository) *PaymentService {
+	cfg := &config.Config{Payment: config.PaymentConfig{
+		Enabled:  true,
+		Currency: "CNY",
+		Fake:     config.PaymentFakeConfig{Enabled: true},
+		EPay:     config.PaymentEPayConfig{Enabled: true, PID: "1", Key: "k", Channels: []string{"alipay"}},
+	}}
+	return NewPaymentService(cfg, repo, nil, nil, nil, nil, nil, nil, nil)
+}
+
+func TestPaymentService_HandleCallbackDisabled(t *testing.T) {
+	svc := NewPaymentService(&config.Config{}, &paymentRepoStub{}, nil, nil, nil, nil, nil, nil, nil)
+	_, _, err := svc.HandleCallback(context.Background(), PaymentProviderFake, &PaymentCallbackRequest{})
+	require.ErrorIs(t, err, ErrPaymentDisabled)
+}
+
+func TestPaymentService_CallbackRejectsProviderMismatch(t *testing.T) {
+	SetDefaultIdempotencyCoordinator(nil)
+	repo := &paymentRepoStub{orders: map[string]*PaymentOrder{
+		"P1": {ID: 1, OrderNo: "P1", Provider: PaymentProviderEPay, Amount: 10, Currency: "CNY", Status: PaymentOrderStatusPending, Kind: PaymentOrderKindBalance},
+	}}
+	svc := newPaymentServiceForTest(repo)
+
+	// 未签名的 fake 回调不能用于确认其它渠道创建的订单
+	_, _, err := svc.HandleCallback(context.Background(), PaymentProviderFake, &PaymentCallbackRequest{
+		Query: url.Values{"order_no": {"P1"}, "amount": {"10"}},
+	})
+	require.ErrorIs(t, err, ErrPaymentOrderNotFound)
+	require.Zero(t, repo.markPaid)
+}
+
+func TestPaymentService_CallbackAmountMismatch(t *testing.T) {
+	SetDefaultIdempotencyCoordinator(nil)
+	repo := &paymentRepoStub{orders: map[string]*PaymentOrder{
+		"P1": {ID: 1, OrderNo: "P1", Provider: PaymentProviderFake, Amount: 10, Currency: "CNY", Status: PaymentOrderStatusPending, Kind: PaymentOrderKindBalance},
+	}}
+	svc := newPaymentServiceForTest(repo)
+
+	_, _, err := svc.HandleCallback(context.Background(), PaymentProviderFake, &PaymentCallbackRequest{
+		Query: url.Values{"order_no": {"P1"}, "amount": {"9.99"}},
+	})
+	require.ErrorIs(t, err, ErrPaymentAmountMismatch)
+	require.Zero(t, repo.markPaid)
+}
+
+func TestPaymentService_CallbackAlreadyPaidIsAcked(t *testing.T) {
+	SetDefaultIdempotencyCoordinator(nil)
+	repo := &paymentRepoStub{orders: map[string]*PaymentOrder{
+		"P1": {ID: 1, OrderNo: "P1", Provider: PaymentProviderFake, Amount: 10, Currency: "CNY", Status: PaymentOrderStatusPaid, Kind: PaymentOrderKindBalance},
+	}}
+	svc := newPaymentServiceForTest(repo)
+
+	contentType, ack, err := svc.HandleCallback(context.Background(), PaymentProviderFake, &PaymentCallbackRequest{
+		Query: url.Values{"order_no": {"P1"}, "amount": {"10"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "success", ack)
+	require.Contains(t, contentType, "text/plain")
+	require.Zero(t, repo.markPaid)
+}
+
+func TestPaymentService_RefundOfUnpaidOrderIsAcked(t *testing.T) {
+	SetDefaultIdempotencyCoordinator(nil)
+	repo := &paymentRepoStub{orders: map[string]*PaymentOrder{
+		"P1": {ID: 1, OrderNo: "P1", Provider: PaymentProviderFake, Amount: 10, Currency: "CNY", Status: PaymentOrderStatusExpired, Kind: PaymentOrderKindBalance},
+	}}
+	svc := newPaymentServiceForTest(repo)
+
+	_, _, err := svc.HandleCallback(context.Background(), PaymentProviderFake, &PaymentCallbackRequest{
+		Query: url.Values{"order_no": {"P1"}, "status": {"refunded"}},
+	})
+	require.NoError(t, err)
+	require.Zero(t, repo.markRevert)
+}
+
+func TestPaymentService_RefundOrderRequiresPaid(t *testing.T) {
+	repo := &paymentRepoStub{orders: map[string]*PaymentOrder{
+		"P1": {ID: 7, OrderNo: "P1", Provider: PaymentProviderFake, Status: PaymentOrderStatusPending},
+	}}
+	svc := newPaymentServiceForTest(repo)
+
+	_, err := svc.RefundOrder(context.Background(), 7, "", false)
+	require.ErrorIs(t, err, ErrPaymentOrderNotPaid)
+}
+
+func TestResolvePaymentChannel(t *testing.T) {
+	epay := newEPayPaymentProvider(config.PaymentEPayConfig{Channels: []string{"alipay", "wxpay"}})
+	channel, err := resolvePaymentChannel(epay, "")
+	require.NoError(t, err)
+	require.Equal(t, "alipay", channel)
+
+	channel, err = resolvePaymentChannel(epay, "WXPAY")
+	require.NoError(t, err)
+	require.Equal(t, "wxpay", channel)
+
+	_, err = resolvePaymentChannel(epay, "qqpay")
+	require.Error(t, err)
+
+	channel, err = resolvePaymentChannel(newFakePaymentProvider(), "anything")
+	require.NoError(t, err)
+	require.Empty(t, channel)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 后付费账单状态
+const (
+	PostpaidStatementStatusIssued  = "issued"
+	PostpaidStatementStatusOverdue = "overdue"
+	PostpaidStatementStatusPaid    = "paid"
+)
+
+// 账单渲染格式
+const (
+	PostpaidStatementFormatHTML = "html"
+	PostpaidStatementFormatPDF  = "pdf"
+)
+
+// 账单日取值范围：限制在 28 日以内，保证每个月都有该日
+const (
+	MinBillingCycleDay = 1
+	MaxBillingCycleDay = 28
+)
+
+var (
+	ErrInvalidUserBillingMode = infraerrors.BadRequest("USER_BILLING_MODE_INVALID", "billing_mode must be prepaid or postpaid")
+	ErrInvalidCreditLimit     = infraerrors.BadRequest("CREDIT_LIMIT_INVALID", "credit_limit must be non-negative")
+	ErrInvalidBillingCycleDay = infraerrors.BadRequest("BILLING_CYCLE_DAY_INVALID", "billing_cycle_day must be between 1 and 28")
+	ErrCreditLimitExceeded    = infraerrors.Forbidden("CREDIT_LIMIT_EXCEEDED", "credit limit exceeded, please settle outstanding statements")
+
+	ErrPostpaidStatementNotFound    = infraerrors.NotFound("POSTPAID_STATEMENT_NOT_FOUND", "statement not found")
+	ErrPostpaidStatementExists      = infraerrors.Conflict("POSTPAID_STATEMENT_EXISTS", "statement already issued for this billing period")
+	ErrPostpaidStatementAlreadyPaid = infraerrors.Conflict("POSTPAID_STATEMENT_ALREADY_PAID", "statement is already paid")
+	ErrPostpaidStatementNoUsage     = infraerrors.BadRequest("POSTPAID_STATEMENT_NO_USAGE", "no postpaid usage in the last billing period")
+	ErrPostpaidUserRequired         = infraerrors.BadRequest("POSTPAID_USER_REQUIRED", "user is not on postpaid billing")
+	ErrPostpaidStatementFormat      = infraerrors.BadRequest("POSTPAID_STATEMENT_FORMAT_INVALID", "format must be html or pdf")
+)
+
+// PostpaidStatementLine 账单明细：账期内同一 API Key / 分组 / 模型的请求汇总
+type PostpaidStatementLine struct {
+	APIKeyID            int64   `json:"api_key_id"`
+	APIKeyName          string  `json:"api_key_name"`
+	GroupID             *int64  `json:"group_id,omitempty"`
+	GroupName           string  `json:"group_name,omitempty"`
+	Model               string  `json:"model"`
+	RequestCount        int64   `json:"request_count"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Amount              float64 `json:"amount"`
+}
+
+// PostpaidStatement 后付费用户的月度账单
+type PostpaidStatement struct {
+	ID          int64
+	StatementNo string
+	UserID      int64
+	// UserEmail 仅查询时回填，用于列表与渲染
+	UserEmail string
+
+	// 账期 [PeriodStart, PeriodEnd)
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Status      string
+
+	// Subtotal 账期内后付费请求的实际扣费；TaxAmount = Subtotal * TaxRate；Total = Subtotal + TaxAmount
+	Subtotal     float64
+	TaxRate      float64
+	TaxAmount    float64
+	Total        float64
+	RequestCount int64
+	LineItems    []PostpaidStatementLine
+
+	DueAt            time.Time
+	PaidAt           *time.Time
+	PaidBy           *int64
+	PaymentReference string
+	Notes            string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// IsPaid 账单是否已付款
+func (s *PostpaidStatement) IsPaid() bool {
+	return s.Status == PostpaidStatementStatusPaid
+}
+
+// PostpaidStatementFilter 账单查询条件
+type PostpaidStatementFilter struct {
+	UserID int64
+	Status string
+}
+
+// PostpaidAccount 需要出账的后付费用户
+type PostpaidAccount struct {
+	UserID          int64
+	BillingCycleDay int
+}
+
+// PostpaidPayment 标记付款的信息
+type PostpaidPayment struct {
+	PaidAt    time.Time
+	PaidBy    int64
+	Reference string
+	Notes     string
+}
+
+// PostpaidStatementRepository 后付费账单存储
+type PostpaidStatementRepository interface {
+	// ListPostpaidAccounts 返回所有未删除的后付费用户
+	ListPostpaidAccounts(ctx context.Context) ([]PostpaidAccount, error)
+	// ExistsForPeriod 用户在该账期是否已出账
+	ExistsForPeriod(ctx context.Context, userID int64, periodStart time.Time) (bool, error)
+	// AggregateUsage 汇总用户在 [start, end) 内计入余额的后付费请求，按 API Key / 分组 / 模型分组
+	AggregateUsage(ctx context.Context, userID int64, start, end time.Time) ([]PostpaidStatementLine, error)
+	// Create 写入账单，回填 ID 与时间戳；同一用户同一账期已存在时返回 ErrPostpaidStatementExists
+	Create(ctx context.Context, statement *PostpaidStatement) error
+	GetByID(ctx context.Context, id int64) (*PostpaidStatement, error)
+	List(ctx context.Context, params pagination.PaginationParams, filter PostpaidStatementFilter) ([]PostpaidStatement, *pagination.PaginationResult, error)
+
+	// MarkOverdue 将 due_at 早于 now 的未付款账单置为 overdue，返回更新数量
+	MarkOverdue(ctx context.Context, now time.Time) (int64, error)
+	// SuspendOverdueAPIKeys 将存在逾期账单的用户的 active API Key 置为 overdue，返回受影响的用户 ID
+	SuspendOverdueAPIKeys(ctx context.Context) ([]int64, error)
+	// MarkPaid 条件流转 issued / overdue → paid；账单已付款时返回 false
+	MarkPaid(ctx context.Context, id int64, payment PostpaidPayment) (bool, error)
+	// RestoreAPIKeysIfSettled 用户已无逾期账单时将其 overdue API Key 恢复为 active，返回恢复数量
+	RestoreAPIKeysIfSettled(ctx context.Context, userID int64) (int64, error)
+}
+
+// NormalizeUserBillingMode 规范化用户计费模式，空值视为预付费
+func NormalizeUserBillingMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		return BillingModePrepaid
+	}
+	return mode
+}
+
+// IsValidUserBillingMode 校验用户计费模式
+func IsValidUserBillingMode(mode string) bool {
+	return mode == BillingModePrepaid || mode == BillingModePostpaid
+}
+
+// NormalizeBillingCycleDay 未设置（0）时默认每月 1 日出账，越界值收敛到合法范围
+func NormalizeBillingCycleDay(day int) int {
+	if day < MinBillingCycleDay {
+		return MinBillingCycleDay
+	}
+	if day > MaxBillingCycleDay {
+		return MaxBillingCycleDay
+	}
+	return day
+}
+
+// validateUserBillingSettings 校验管理员提交的后付费配置
+func validateUserBillingSettings(mode string, creditLimit float64, cycleDay int) error {
+	if !IsValidUserBillingMode(mode) {
+		return ErrInvalidUserBillingMode
+	}
+	if creditLimit < 0 {
+		return ErrInvalidCreditLimit
+	}
+	if cycleDay < MinBillingCycleDay || cycleDay > MaxBillingCycleDay {
+		return ErrInvalidBillingCycleDay
+	}
+	return nil
+}
+
+// lastClosedPostpaidPeriod 返回 now 时刻最近一个已结束的账期 [start, end)。
+// 账期以每月账单日 00:00 (UTC) 为界。
+func lastClosedPostpaidPeriod(now time.Time, cycleDay int) (time.Time, time.Time) {
+	cycleDay = NormalizeBillingCycleDay(cycleDay)
+	now = now.UTC()
+	end := time.Date(now.Year(), now.Month(), cycleDay, 0, 0, 0, 0, time.UTC)
+	if now.Before(end) {
+		end = end.AddDate(0, -1, 0)
+	}
+	return end.AddDate(0, -1, 0), end
+}
+
+// postpaidStatementNo 账单编号由账期起始日与用户 ID 决定，重复出账会命中唯一索引
+func postpaidStatementNo(userID int64, periodStart time.Time) string {
+	return fmt.Sprintf("PS%s-%06d", periodStart.UTC().Format("20060102"), userID)
+}
+
+// buildPostpaidStatement 由明细计算小计、税额与合计
+func buildPostpaidStatement(userID int64, start, end time.Time, lines []PostpaidStatementLine, taxRate float64, dueAt time.Time) *PostpaidStatement {
+	st := &PostpaidStatement{
+		StatementNo: postpaidStatementNo(userID, start),
+		UserID:      userID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Status:      PostpaidStatementStatusIssued,
+		TaxRate:     taxRate,
+		LineItems:   lines,
+		DueAt:       dueAt,
+	}
+	for _, line := range lines {
+		st.Subtotal += line.Amount
+		st.RequestCount += line.RequestCount
+	}
+	st.Subtotal = roundTo8DP(st.Subtotal)
+	st.TaxAmount = roundTo8DP(st.Subtotal * taxRate)
+	st.Total = roundTo8DP(st.Subtotal + st.TaxAmount)
+	return st
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const postpaidBillingRunTimeout = 5 * time.Minute
+
+// PostpaidRunResult 一轮出账与逾期检查的结果
+type PostpaidRunResult struct {
+	Issued         int   `json:"issued"`
+	MarkedOverdue  int64 `json:"marked_overdue"`
+	SuspendedUsers int   `json:"suspended_users"`
+}
+
+// MarkPostpaidStatementPaidInput 管理员标记账单已付款
+type MarkPostpaidStatementPaidInput struct {
+	Reference string
+	Notes     string
+}
+
+// PostpaidBillingService 后付费（月结）账单：按账单日汇总上一账期的 usage_logs 出账，
+// 逾期未付时暂停用户的 API Key，标记付款后冲抵透支余额并恢复 API Key。
+type PostpaidBillingService struct {
+	repo                 PostpaidStatementRepository
+	userRepo             UserRepository
+	balanceLedgerRepo    BalanceLedgerRepository
+	billingCacheService  *BillingCacheService
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+	entClient            *dbent.Client
+
+	interval      time.Duration
+	taxRate       float64
+	dueDays       int
+	issuerName    string
+	issuerDetails string
+	now           func() time.Time
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewPostpaidBillingService 创建后付费账单服务
+func NewPostpaidBillingService(
+	repo PostpaidStatementRepository,
+	userRepo UserRepository,
+	balanceLedgerRepo BalanceLedgerRepository,
+	billingCacheService *BillingCacheService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+	entClient *dbent.Client,
+	cfg *config.Config,
+) *PostpaidBillingService {
+	s := &PostpaidBillingService{
+		repo:                 repo,
+		userRepo:             userRepo,
+		balanceLedgerRepo:    balanceLedgerRepo,
+		billingCacheService:  billingCacheService,
+		authCacheInvalidator: authCacheInvalidator,
+		entClient:            entClient,
+		interval:             time.Hour,
+		dueDays:              15,
+		issuerName:           "Sub2API",
+		now:                  time.Now,
+		stopCh:               make(chan struct{}),
+	}
+	if cfg != nil {
+		pc := cfg.Postpaid
+		if pc.CheckIntervalSeconds > 0 {
+			s.interval = time.Duration(pc.CheckIntervalSeconds) * time.Second
+		}
+		if pc.TaxRate >= 0 && pc.TaxRate < 1 {
+			s.taxRate = pc.TaxRate
+		}
+		if pc.DueDays >= 0 {
+			s.dueDays = pc.DueDays
+		}
+		if pc.IssuerName != "" {
+			s.issuerName = pc.IssuerName
+		}
+		s.issuerDetails = pc.IssuerDetails
+	}
+	return s
+}
+
+func (s *PostpaidBillingService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		logger.LegacyPrintf("service.postpaid", "[Postpaid] started interval=%s due_days=%d tax_rate=%.4f", s.interval, s.dueDays, s.taxRate)
+		s.wg.Add(1)
+		go s.runLoop()
+	})
+}
+
+func (s *PostpaidBillingService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+		logger.LegacyPrintf("service.postpaid", "[Postpaid] stopped")
+	})
+}
+
+func (s *PostpaidBillingService) runLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.runScheduled()
+	for {
+		select {
+		case <-ticker.C:
+			s.runScheduled()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *PostpaidBillingService) runScheduled() {
+	ctx, cancel := context.WithTimeout(context.Background(), postpaidBillingRunTimeout)
+	defer cancel()
+
+	result, err := s.RunOnce(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.postpaid", "[Postpaid] run failed: %v", err)
+		return
+	}
+	if result.Issued > 0 || result.MarkedOverdue > 0 || result.SuspendedUsers > 0 {
+		logger.LegacyPrintf("service.postpaid", "[Postpaid] issued=%d overdue=%d suspended_users=%d", result.Issued, result.MarkedOverdue, result.SuspendedUsers)
+	}
+}
+
+// RunOnce 为账期已结束的后付费用户出账，并处理逾期账单。多实例并发执行时由唯一索引保证每个账期只出一张账单。
+func (s *PostpaidBillingService) RunOnce(ctx context.Context) (*PostpaidRunResult, error) {
+	result := &PostpaidRunResult{}
+	accounts, err := s.repo.ListPostpaidAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list postpaid accounts: %w", err)
+	}
+	now := s.now()
+	for _, account := range accounts {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		start, end := lastClosedPostpaidPeriod(now, account.BillingCycleDay)
+		exists, err := s.repo.ExistsForPeriod(ctx, account.UserID, start)
+		if err != nil {
+			logger.LegacyPrintf("service.postpaid", "[Postpaid] check statement failed: user=%d err=%v", account.UserID, err)
+			continue
+		}
+		if exists {
+			continue
+		}
+		st, err := s.issue(ctx, account.UserID, start, end, now)
+		if err != nil {
+			if !errors.Is(err, ErrPostpaidStatementExists) && !errors.Is(err, ErrPostpaidStatementNoUsage) {
+				logger.LegacyPrintf("service.postpaid", "[Postpaid] issue statement failed: user=%d period=%s err=%v", account.UserID, start.Format("2006-01-02"), err)
+			}
+			continue
+		}
+		logger.LegacyPrintf("service.postpaid", "[Postpaid] statement issued: no=%s user=%d total=%.8f", st.StatementNo, st.UserID, st.Total)
+		result.Issued++
+	}
+
+	marked, err := s.repo.MarkOverdue(ctx, now)
+	if err != nil {
+		return result, fmt.Errorf("mark overdue statements: %w", err)
+	}
+	result.MarkedOverdue = marked
+
+	// 每轮都检查：逾期用户在暂停后新建的 Key 也会被暂停
+	userIDs, err := s.repo.SuspendOverdueAPIKeys(ctx)
+	if err != nil {
+		return result, fmt.Errorf("suspend overdue api keys: %w", err)
+	}
+	for _, userID := range userIDs {
+		s.invalidateAuthCache(ctx, userID)
+		logger.LegacyPrintf("service.postpaid", "[Postpaid] api keys suspended for overdue statements: user=%d", userID)
+	}
+	result.SuspendedUsers = len(userIDs)
+	return result, nil
+}
+
+func (s *PostpaidBillingService) issue(ctx context.Context, userID int64, start, end, now time.Time) (*PostpaidStatement, error) {
+	lines, err := s.repo.AggregateUsage(ctx, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate usage: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, ErrPostpaidStatementNoUsage
+	}
+	st := buildPostpaidStatement(userID, start, end, lines, s.taxRate, now.Add(time.Duration(s.dueDays)*24*time.Hour))
+	if err := s.repo.Create(ctx, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// GenerateForUser 立即为用户的上一个已结束账期出账（管理员补出账单）
+func (s *PostpaidBillingService) GenerateForUser(ctx context.Context, userID int64) (*PostpaidStatement, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsPostpaid() {
+		return nil, ErrPostpaidUserRequired
+	}
+	now := s.now()
+	start, end := lastClosedPostpaidPeriod(now, user.BillingCycleDay)
+	st, err := s.issue(ctx, userID, start, end, now)
+	if err != nil {
+		return nil, err
+	}
+	st.UserEmail = user.Email
+	return st, nil
+}
+
+// ListStatements 分页查询账单
+func (s *PostpaidBillingService) ListStatements(ctx context.Context, params pagination.PaginationParams, filter PostpaidStatementFilter) ([]PostpaidStatement, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, params, filter)
+}
+
+// GetStatement 返回账单详情（含明细）
+func (s *PostpaidBillingService) GetStatement(ctx context.Context, id int64) (*PostpaidStatement, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetUserStatement 返回属于该用户的账单，不属于时视为不存在
+func (s *PostpaidBillingService) GetUserStatement(ctx context.Context, userID, id int64) (*PostpaidStatement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.UserID != userID {
+		return nil, ErrPostpaidStatementNotFound
+	}
+	return st, nil
+}
+
+// MarkPaid 标记账单已付款：以账单小计冲抵透支余额（税额不计入余额），用户不再有逾期账单时恢复其 API Key
+func (s *PostpaidBillingService) MarkPaid(ctx context.Context, id, operatorID int64, input *MarkPostpaidStatementPaidInput) (*PostpaidStatement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsPaid() {
+		return nil, ErrPostpaidStatementAlreadyPaid
+	}
+	payment := PostpaidPayment{PaidAt: s.now(), PaidBy: operatorID}
+	if input != nil {
+		payment.Reference = truncateString(input.Reference, 128)
+		payment.Notes = input.Notes
+	}
+
+	var restored int64
+	err = s.withTx(ctx, func(txCtx context.Context) error {
+		updated, err := s.repo.MarkPaid(txCtx, st.ID, payment)
+		if err != nil {
+			return fmt.Errorf("mark statement paid: %w", err)
+		}
+		if !updated {
+			return ErrPostpaidStatementAlreadyPaid
+		}
+		if st.Subtotal > 0 && s.balanceLedgerRepo != nil {
+			entry := &BalanceLedgerEntry{
+				UserID:        st.UserID,
+				EntryType:     BalanceLedgerEntryPostpaidPayment,
+				Amount:        st.Subtotal,
+				ReferenceType: BalanceLedgerRefPostpaidStatement,
+				ReferenceID:   st.StatementNo,
+				ActorType:     BalanceLedgerActorAdmin,
+				Notes:         truncateString(payment.Reference, 500),
+			}
+			if operatorID > 0 {
+				entry.ActorID = &operatorID
+			}
+			if err := s.balanceLedgerRepo.Apply(txCtx, entry); err != nil {
+				return fmt.Errorf("credit user balance: %w", err)
+			}
+		}
+		restored, err = s.repo.RestoreAPIKeysIfSettled(txCtx, st.UserID)
+		if err != nil {
+			return fmt.Errorf("restore api keys: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LegacyPrintf("service.postpaid", "[Postpaid] statement paid: no=%s user=%d subtotal=%.8f restored_keys=%d", st.StatementNo, st.UserID, st.Subtotal, restored)
+	s.invalidateAuthCache(ctx, st.UserID)
+	if s.billingCacheService != nil && st.Subtotal > 0 {
+		go func(userID int64) {
+			cacheCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = s.billingCacheService.InvalidateUserBalance(cacheCtx, userID)
+		}(st.UserID)
+	}
+	return s.repo.GetByID(ctx, st.ID)
+}
+
+func (s *PostpaidBillingService) withTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.entClient == nil {
+		return fn(ctx)
+	}
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(dbent.NewTxContext(ctx, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *PostpaidBillingService) invalidateAuthCache(ctx context.Context, userID int64) {
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+}
@@ -0,0 +1,246 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type postpaidStatementRepoStub struct {
+	PostpaidStatementRepository
+	accounts  []PostpaidAccount
+	existing  map[int64]bool
+	lines     map[int64][]PostpaidStatementLine
+	created   []*PostpaidStatement
+	statement *PostpaidStatement
+	overdue   []int64
+	payment   *PostpaidPayment
+	restored  []int64
+}
+
+func (s *postpaidStatementRepoStub) ListPostpaidAccounts(context.Context) ([]PostpaidAccount, error) {
+	return s.accounts, nil
+}
+
+func (s *postpaidStatementRepoStub) ExistsForPeriod(_ context.Context, userID int64, _ time.Time) (bool, error) {
+	return s.existing[userID], nil
+}
+
+func (s *postpaidStatementRepoStub) AggregateUsage(_ context.Context, userID int64, _, _ time.Time) ([]PostpaidStatementLine, error) {
+	return s.lines[userID], nil
+}
+
+func (s *postpaidStatementRepoStub) Create(_ context.Context, st *PostpaidStatement) error {
+	st.ID = int64(len(s.created) + 1)
+	s.created = append(s.created, st)
+	return nil
+}
+
+func (s *postpaidStatementRepoStub) GetByID(_ context.Context, id int64) (*PostpaidStatement, error) {
+	if s.statement == nil || s.statement.ID != id {
+		return nil, ErrPostpaidStatementNotFound
+	}
+	cp := *s.statement
+	return &cp, nil
+}
+
+func (s *postpaidStatementRepoStub) MarkOverdue(context.Context, time.Time) (int64, error) {
+	return int64(len(s.overdue)), nil
+}
+
+func (s *postpaidStatementRepoStub) SuspendOverdueAPIKeys(context.Context) ([]int64, error) {
+	return s.overdue, nil
+}
+
+func (s *postpaidStatementRepoStub) MarkPaid(_ context.Context, _ int64, payment PostpaidPayment) (bool, error) {
+	if s.statement.IsPaid() {
+		return false, nil
+	}
+	s.payment = &payment
+	s.statement.Status = PostpaidStatementStatusPaid
+	return true, nil
+}
+
+func (s *postpaidStatementRepoStub) RestoreAPIKeysIfSettled(_ context.Context, userID int64) (int64, error) {
+	s.restored = append(s.restored, userID)
+	return 2, nil
+}
+
+type postpaidLedgerRepoStub struct {
+	BalanceLedgerRepository
+	entries []*BalanceLedgerEntry
+}
+
+func (s *postpaidLedgerRepoStub) Apply(_ context.Context, entry *BalanceLedgerEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+type postpaidAuthInvalidatorStub struct {
+	userIDs []int64
+}
+
+func (s *postpaidAuthInvalidatorStub) InvalidateAuthCacheByKey(context.Context, string) {}
+
+func (s *postpaidAuthInvalidatorStub) InvalidateAuthCacheByUserID(_ context.Context, userID int64) {
+	s.userIDs = append(s.userIDs, userID)
+}
+
+func (s *postpaidAuthInvalidatorStub) InvalidateAuthCacheByGroupID(context.Context, int64) {}
+
+func TestLastClosedPostpaidPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		day       int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "after cycle day",
+			now:       time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC),
+			day:       15,
+			wantStart: time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "before cycle day",
+			now:       time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+			day:       15,
+			wantStart: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "exactly at boundary",
+			now:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			day:       0,
+			wantStart: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := lastClosedPostpaidPeriod(tt.now, tt.day)
+			require.Equal(t, tt.wantStart, start)
+			require.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestBuildPostpaidStatementTotals(t *testing.T) {
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	lines := []PostpaidStatementLine{
+		{APIKeyID: 1, Model: "claude-sonnet-4", RequestCount: 3, Amount: 1.5},
+		{APIKeyID: 2, Model: "gpt-5", RequestCount: 2, Amount: 0.25},
+	}
+
+	st := buildPostpaidStatement(42, start, start.AddDate(0, 1, 0), lines, 0.1, start.AddDate(0, 1, 15))
+	require.Equal(t, "PS20260201-000042", st.StatementNo)
+	require.Equal(t, PostpaidStatementStatusIssued, st.Status)
+	require.Equal(t, int64(5), st.RequestCount)
+	require.InDelta(t, 1.75, st.Subtotal, 1e-9)
+	require.InDelta(t, 0.175, st.TaxAmount, 1e-9)
+	require.InDelta(t, 1.925, st.Total, 1e-9)
+}
+
+func TestValidateUserBillingSettings(t *testing.T) {
+	require.NoError(t, validateUserBillingSettings(BillingModePostpaid, 100, 28))
+	require.ErrorIs(t, validateUserBillingSettings("monthly", 0, 1), ErrInvalidUserBillingMode)
+	require.ErrorIs(t, validateUserBillingSettings(BillingModePostpaid, -1, 1), ErrInvalidCreditLimit)
+	require.ErrorIs(t, validateUserBillingSettings(BillingModePostpaid, 0, 29), ErrInvalidBillingCycleDay)
+}
+
+func TestUserCreditHeadroom(t *testing.T) {
+	require.Zero(t, (&User{BillingMode: BillingModePrepaid, CreditLimit: 50}).CreditHeadroom())
+	require.Equal(t, 50.0, (&User{BillingMode: BillingModePostpaid, CreditLimit: 50}).CreditHeadroom())
+}
+
+func TestUsageBillingType(t *testing.T) {
+	postpaid := &User{BillingMode: BillingModePostpaid}
+	require.Equal(t, BillingTypeSubscription, usageBillingType(postpaid, nil, &UserSubscription{}))
+	require.Equal(t, BillingTypePostpaid, usageBillingType(postpaid, &APIKey{}, nil))
+	require.Equal(t, BillingTypeBalance, usageBillingType(&User{}, &APIKey{}, nil))
+}
+
+func TestPostpaidBillingService_RunOnceIssuesAndSuspends(t *testing.T) {
+	repo := &postpaidStatementRepoStub{
+		accounts: []PostpaidAccount{{UserID: 1, BillingCycleDay: 1}, {UserID: 2, BillingCycleDay: 1}, {UserID: 3, BillingCycleDay: 1}},
+		existing: map[int64]bool{2: true},
+		lines: map[int64][]PostpaidStatementLine{
+			1: {{APIKeyID: 10, Model: "claude-sonnet-4", RequestCount: 4, Amount: 2}},
+		},
+		overdue: []int64{7},
+	}
+	invalidator := &postpaidAuthInvalidatorStub{}
+	svc := NewPostpaidBillingService(repo, nil, nil, nil, invalidator, nil, &config.Config{Postpaid: config.PostpaidConfig{DueDays: 10}})
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	result, err := svc.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Issued)
+	require.Equal(t, int64(1), result.MarkedOverdue)
+	require.Equal(t, 1, result.SuspendedUsers)
+	require.Len(t, repo.created, 1)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), repo.created[0].PeriodStart)
+	require.Equal(t, now.AddDate(0, 0, 10), repo.created[0].DueAt)
+	require.Equal(t, []int64{7}, invalidator.userIDs)
+}
+
+func TestPostpaidBillingService_MarkPaidCreditsSubtotal(t *testing.T) {
+	repo := &postpaidStatementRepoStub{statement: &PostpaidStatement{
+		ID:          5,
+		StatementNo: "PS20260201-000001",
+		UserID:      1,
+		Status:      PostpaidStatementStatusOverdue,
+		Subtotal:    12.5,
+		TaxAmount:   1.25,
+		Total:       13.75,
+	}}
+	ledger := &postpaidLedgerRepoStub{}
+	invalidator := &postpaidAuthInvalidatorStub{}
+	svc := NewPostpaidBillingService(repo, nil, ledger, nil, invalidator, nil, nil)
+
+	st, err := svc.MarkPaid(context.Background(), 5, 9, &MarkPostpaidStatementPaidInput{Reference: "wire-001"})
+	require.NoError(t, err)
+	require.True(t, st.IsPaid())
+	require.Equal(t, "wire-001", repo.payment.Reference)
+	require.Equal(t, int64(9), repo.payment.PaidBy)
+	require.Len(t, ledger.entries, 1)
+	require.Equal(t, BalanceLedgerEntryPostpaidPayment, ledger.entries[0].EntryType)
+	require.Equal(t, 12.5, ledger.entries[0].Amount)
+	require.Equal(t, "PS20260201-000001", ledger.entries[0].ReferenceID)
+	require.Equal(t, []int64{1}, repo.restored)
+	require.Equal(t, []int64{1}, invalidator.userIDs)
+
+	_, err = svc.MarkPaid(context.Background(), 5, 9, nil)
+	require.ErrorIs(t, err, ErrPostpaidStatementAlreadyPaid)
+}
+
+func TestPostpaidBillingService_RenderStatement(t *testing.T) {
+	svc := NewPostpaidBillingService(nil, nil, nil, nil, nil, nil, &config.Config{Postpaid: config.PostpaidConfig{IssuerName: "Acme (Billing)"}})
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	st := buildPostpaidStatement(1, start, start.AddDate(0, 1, 0), []PostpaidStatementLine{
+		{APIKeyID: 1, APIKeyName: "<prod>", Model: "claude-sonnet-4", RequestCount: 1, Amount: 0.5},
+	}, 0, start.AddDate(0, 1, 15))
+
+	html, err := svc.RenderStatement(st, "html")
+	require.NoError(t, err)
+	require.Equal(t, "PS20260201-000001.html", html.Filename)
+	require.Contains(t, string(html.Content), "&lt;prod&gt;")
+
+	pdf, err := svc.RenderStatement(st, "PDF")
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", pdf.ContentType)
+	require.True(t, bytes.HasPrefix(pdf.Content, []byte("%PDF-")))
+	require.Contains(t, string(pdf.Content), `Acme \(Billing\)`)
+
+	_, err = svc.RenderStatement(st, "docx")
+	require.ErrorIs(t, err, ErrPostpaidStatementFormat)
+}
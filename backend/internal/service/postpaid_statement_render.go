@@ -0,0 +1,343 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// PostpaidStatementDocument 渲染后的账单文件
+type PostpaidStatementDocument struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// RenderStatement 将账单渲染为 HTML 或 PDF
+func (s *PostpaidBillingService) RenderStatement(st *PostpaidStatement, format string) (*PostpaidStatementDocument, error) {
+	if st == nil {
+		return nil, ErrPostpaidStatementNotFound
+	}
+	view := newPostpaidStatementView(st, s.issuerName, s.issuerDetails)
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", PostpaidStatementFormatHTML:
+		content, err := renderPostpaidStatementHTML(view)
+		if err != nil {
+			return nil, err
+		}
+		return &PostpaidStatementDocument{Filename: st.StatementNo + ".html", ContentType: "text/html; charset=utf-8", Content: content}, nil
+	case PostpaidStatementFormatPDF:
+		return &PostpaidStatementDocument{Filename: st.StatementNo + ".pdf", ContentType: "application/pdf", Content: renderPostpaidStatementPDF(view)}, nil
+	default:
+		return nil, ErrPostpaidStatementFormat
+	}
+}
+
+// postpaidStatementView 渲染用的账单视图（金额与日期已格式化）
+type postpaidStatementView struct {
+	IssuerName    string
+	IssuerDetails []string
+	StatementNo   string
+	Status        string
+	BillTo        string
+	UserID        int64
+	Period        string
+	IssuedAt      string
+	DueAt         string
+	PaidAt        string
+	PaymentRef    string
+	RequestCount  int64
+	Lines         []postpaidStatementLineView
+	Subtotal      string
+	TaxLabel      string
+	TaxAmount     string
+	Total         string
+}
+
+type postpaidStatementLineView struct {
+	APIKey              string
+	Group               string
+	Model               string
+	RequestCount        int64
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	Amount              string
+}
+
+func newPostpaidStatementView(st *PostpaidStatement, issuerName, issuerDetails string) *postpaidStatementView {
+	const day = "2006-01-02"
+	v := &postpaidStatementView{
+		IssuerName:  issuerName,
+		StatementNo: st.StatementNo,
+		Status:      strings.ToUpper(st.Status),
+		BillTo:      st.UserEmail,
+		UserID:      st.UserID,
+		// 账期结束时间为开区间，展示为最后一天
+		Period:       st.PeriodStart.UTC().Format(day) + " - " + st.PeriodEnd.UTC().Add(-time.Second).Format(day),
+		IssuedAt:     st.CreatedAt.UTC().Format(day),
+		DueAt:        st.DueAt.UTC().Format(day),
+		PaymentRef:   st.PaymentReference,
+		RequestCount: st.RequestCount,
+		Subtotal:     formatStatementAmount(st.Subtotal),
+		TaxLabel:     fmt.Sprintf("Tax (%s%%)", strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", st.TaxRate*100), "0"), ".")),
+		TaxAmount:    formatStatementAmount(st.TaxAmount),
+		Total:        formatStatementAmount(st.Total),
+	}
+	if st.CreatedAt.IsZero() {
+		v.IssuedAt = ""
+	}
+	if st.PaidAt != nil {
+		v.PaidAt = st.PaidAt.UTC().Format(day)
+	}
+	for _, line := range strings.Split(issuerDetails, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			v.IssuerDetails = append(v.IssuerDetails, line)
+		}
+	}
+	for _, line := range st.LineItems {
+		lv := postpaidStatementLineView{
+			APIKey:              line.APIKeyName,
+			Group:               line.GroupName,
+			Model:               line.Model,
+			RequestCount:        line.RequestCount,
+			InputTokens:         line.InputTokens,
+			OutputTokens:        line.OutputTokens,
+			CacheCreationTokens: line.CacheCreationTokens,
+			CacheReadTokens:     line.CacheReadTokens,
+			Amount:              formatStatementAmount(line.Amount),
+		}
+		if lv.APIKey == "" {
+			lv.APIKey = fmt.Sprintf("#%d", line.APIKeyID)
+		}
+		if lv.Group == "" && line.GroupID != nil {
+			lv.Group = fmt.Sprintf("#%d", *line.GroupID)
+		}
+		v.Lines = append(v.Lines, lv)
+	}
+	return v
+}
+
+// formatStatementAmount 账单金额保留 4 位小数（按请求计费的单价通常低于 1 美分）
+func formatStatementAmount(v float64) string {
+	return fmt.Sprintf("$%.4f", v)
+}
+
+var postpaidStatementHTMLTemplate = template.Must(template.New("statement").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Statement {{.StatementNo}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;color:#1f2937;margin:40px;font-size:13px}
+h1{font-size:22px;margin:0 0 4px}
+.muted{color:#6b7280}
+.header{display:flex;justify-content:space-between;margin-bottom:24px}
+table{width:100%;border-collapse:collapse;margin-top:16px}
+th,td{padding:6px 8px;border-bottom:1px solid #e5e7eb;text-align:left}
+th{background:#f9fafb;font-weight:600}
+td.num,th.num{text-align:right;font-variant-numeric:tabular-nums}
+.totals{margin-left:auto;width:320px;margin-top:16px}
+.totals td{border:none;padding:4px 8px}
+.totals tr.total td{font-weight:700;border-top:2px solid #1f2937}
+.status{display:inline-block;padding:2px 8px;border-radius:4px;background:#eef2ff;font-weight:600}
+</style>
+</head>
+<body>
+<div class="header">
+  <div>
+    <h1>{{.IssuerName}}</h1>
+    {{range .IssuerDetails}}<div class="muted">{{.}}</div>{{end}}
+  </div>
+  <div style="text-align:right">
+    <h1>Statement</h1>
+    <div>No. {{.StatementNo}}</div>
+    <div><span class="status">{{.Status}}</span></div>
+  </div>
+</div>
+<table>
+  <tr><th>Bill to</th><td>{{.BillTo}} (user #{{.UserID}})</td><th>Billing period</th><td>{{.Period}} (UTC)</td></tr>
+  <tr><th>Issued</th><td>{{.IssuedAt}}</td><th>Due</th><td>{{.DueAt}}</td></tr>
+  {{if .PaidAt}}<tr><th>Paid</th><td>{{.PaidAt}}</td><th>Payment reference</th><td>{{.PaymentRef}}</td></tr>{{end}}
+</table>
+<table>
+  <thead>
+    <tr><th>API key</th><th>Group</th><th>Model</th><th class="num">Requests</th><th class="num">Input tokens</th><th class="num">Output tokens</th><th class="num">Cache write</th><th class="num">Cache read</th><th class="num">Amount</th></tr>
+  </thead>
+  <tbody>
+  {{range .Lines}}
+    <tr><td>{{.APIKey}}</td><td>{{.Group}}</td><td>{{.Model}}</td><td class="num">{{.RequestCount}}</td><td class="num">{{.InputTokens}}</td><td class="num">{{.OutputTokens}}</td><td class="num">{{.CacheCreationTokens}}</td><td class="num">{{.CacheReadTokens}}</td><td class="num">{{.Amount}}</td></tr>
+  {{end}}
+  </tbody>
+</table>
+<table class="totals">
+  <tr><td>Requests</td><td class="num">{{.RequestCount}}</td></tr>
+  <tr><td>Subtotal</td><td class="num">{{.Subtotal}}</td></tr>
+  <tr><td>{{.TaxLabel}}</td><td class="num">{{.TaxAmount}}</td></tr>
+  <tr class="total"><td>Total (USD)</td><td class="num">{{.Total}}</td></tr>
+</table>
+</body>
+</html>
+`))
+
+func renderPostpaidStatementHTML(v *postpaidStatementView) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := postpaidStatementHTMLTemplate.Execute(&buf, v); err != nil {
+		return nil, fmt.Errorf("render statement html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF 版式：A4 纵向，等宽字体 Courier 9pt，按字符宽度对齐表格列
+const (
+	statementPDFPageWidth    = 595
+	statementPDFPageHeight   = 842
+	statementPDFMargin       = 40
+	statementPDFFontSize     = 9
+	statementPDFLeading      = 12
+	statementPDFLinesPerPage = (statementPDFPageHeight - 2*statementPDFMargin) / statementPDFLeading
+)
+
+// statementPDFLine 一行文本；Bold 使用 Courier-Bold
+type statementPDFLine struct {
+	Text string
+	Bold bool
+}
+
+func postpaidStatementPDFLines(v *postpaidStatementView) []statementPDFLine {
+	lines := []statementPDFLine{{Text: v.IssuerName, Bold: true}}
+	for _, d := range v.IssuerDetails {
+		lines = append(lines, statementPDFLine{Text: d})
+	}
+	lines = append(lines,
+		statementPDFLine{},
+		statementPDFLine{Text: "STATEMENT " + v.StatementNo + "   [" + v.Status + "]", Bold: true},
+		statementPDFLine{Text: fmt.Sprintf("Bill to:        %s (user #%d)", v.BillTo, v.UserID)},
+		statementPDFLine{Text: "Billing period: " + v.Period + " (UTC)"},
+		statementPDFLine{Text: "Issued:         " + v.IssuedAt},
+		statementPDFLine{Text: "Due:            " + v.DueAt},
+	)
+	if v.PaidAt != "" {
+		lines = append(lines, statementPDFLine{Text: "Paid:           " + v.PaidAt + "  " + v.PaymentRef})
+	}
+
+	const row = "%-14s %-12s %-22s %8s %10s %10s %12s"
+	lines = append(lines,
+		statementPDFLine{},
+		statementPDFLine{Text: fmt.Sprintf(row, "API key", "Group", "Model", "Requests", "Input", "Output", "Amount"), Bold: true},
+		statementPDFLine{Text: strings.Repeat("-", 94)},
+	)
+	for _, l := range v.Lines {
+		lines = append(lines, statementPDFLine{Text: fmt.Sprintf(row,
+			clipStatementText(l.APIKey, 14), clipStatementText(l.Group, 12), clipStatementText(l.Model, 22),
+			fmt.Sprint(l.RequestCount), fmt.Sprint(l.InputTokens), fmt.Sprint(l.OutputTokens), l.Amount,
+		)})
+	}
+	const total = "%80s %13s"
+	lines = append(lines,
+		statementPDFLine{Text: strings.Repeat("-", 94)},
+		statementPDFLine{Text: fmt.Sprintf(total, "Requests", fmt.Sprint(v.RequestCount))},
+		statementPDFLine{Text: fmt.Sprintf(total, "Subtotal", v.Subtotal)},
+		statementPDFLine{Text: fmt.Sprintf(total, v.TaxLabel, v.TaxAmount)},
+		statementPDFLine{Text: fmt.Sprintf(total, "Total (USD)", v.Total), Bold: true},
+	)
+	return lines
+}
+
+// clipStatementText 截断超出列宽的文本
+func clipStatementText(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "~"
+}
+
+// renderPostpaidStatementPDF 生成最小 PDF 1.4 文档：标准 Type1 字体无需嵌入，
+// 仅支持 Latin-1 字符，其余字符以 ? 代替。
+func renderPostpaidStatementPDF(v *postpaidStatementView) []byte {
+	lines := postpaidStatementPDFLines(v)
+	var pages [][]statementPDFLine
+	for len(lines) > statementPDFLinesPerPage {
+		pages = append(pages, lines[:statementPDFLinesPerPage])
+		lines = lines[statementPDFLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// 对象编号：1 Catalog，2 Pages，3 Courier，4 Courier-Bold，之后每页占 Page + Contents 两个对象
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // Pages，待页面对象编号确定后填充
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for i, page := range pages {
+		pageObj := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+		stream := statementPDFContentStream(page, i+1, len(pages))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				statementPDFPageWidth, statementPDFPageHeight, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func statementPDFContentStream(lines []statementPDFLine, pageNo, pageCount int) string {
+	var b strings.Builder
+	b.WriteString("BT\n")
+	fmt.Fprintf(&b, "%d TL\n%d %d Td\n", statementPDFLeading, statementPDFMargin, statementPDFPageHeight-statementPDFMargin)
+	for _, line := range lines {
+		font := "F1"
+		if line.Bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&b, "/%s %d Tf\n(%s) Tj T*\n", font, statementPDFFontSize, escapeStatementPDFText(line.Text))
+	}
+	b.WriteString("ET\n")
+	if pageCount > 1 {
+		fmt.Fprintf(&b, "BT /F1 8 Tf %d %d Td (Page %d of %d) Tj ET\n", statementPDFPageWidth-statementPDFMargin-70, statementPDFMargin/2, pageNo, pageCount)
+	}
+	return b.String()
+}
+
+// escapeStatementPDFText 转义 PDF 字符串中的 \ ( )，非 Latin-1 字符替换为 ?
+func escapeStatementPDFText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -50,6 +50,12 @@ var (
 		Mode:                    "chat",
 		SupportsPromptCaching:   true,
 	}
+	// openAIAudioFallbackPricing 音频模型静态价格（LiteLLM 数据缺失时回退）
+	openAIAudioFallbackPricing = map[string]*LiteLLMModelPricing{
+		"whisper-1": {InputCostPerSecond: 1e-04, LiteLLMProvider: "openai", Mode: "audio_transcription"}, // $0.006 per minute
+		"tts-1":     {InputCostPerCharacter: 1.5e-05, LiteLLMProvider: "openai", Mode: "audio_speech"},   // $15 per 1M characters
+		"tts-1-hd":  {InputCostPerCharacter: 3e-05, LiteLLMProvider: "openai", Mode: "audio_speech"},     // $30 per 1M characters
+	}
 )
 
 // LiteLLMModelPricing LiteLLM价格数据结构
@@ -70,7 +76,9 @@ type LiteLLMModelPricing struct {
 	LiteLLMProvider                     string  `json:"litellm_provider"`
 	Mode                                string  `json:"mode"`
 	SupportsPromptCaching               bool    `json:"supports_prompt_caching"`
-	OutputCostPerImage                  float64 `json:"output_cost_per_image"` // 图片生成模型每张图片价格
+	OutputCostPerImage                  float64 `json:"output_cost_per_image"`    // 图片生成模型每张图片价格
+	InputCostPerSecond                  float64 `json:"input_cost_per_second"`    // 音频转写/翻译每秒价格
+	InputCostPerCharacter               float64 `json:"input_cost_per_character"` // 语音合成每字符价格
 }
 
 // PricingRemoteClient 远程价格数据获取接口
@@ -94,6 +102,8 @@ type LiteLLMRawEntry struct {
 	Mode                                string   `json:"mode"`
 	SupportsPromptCaching               bool     `json:"supports_prompt_caching"`
 	OutputCostPerImage                  *float64 `json:"output_cost_per_image"`
+	InputCostPerSecond                  *float64 `json:"input_cost_per_second"`
+	InputCostPerCharacter               *float64 `json:"input_cost_per_character"`
 }
 
 // PricingService 动态价格服务
@@ -369,8 +379,9 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 			continue
 		}
 
-		// 只保留有有效价格的条目
-		if entry.InputCostPerToken == nil && entry.OutputCostPerToken == nil {
+		// 只保留有有效价格的条目（音频模型按秒/字符计价，没有 token 价格）
+		if entry.InputCostPerToken == nil && entry.OutputCostPerToken == nil &&
+			entry.InputCostPerSecond == nil && entry.InputCostPerCharacter == nil {
 			continue
 		}
 
@@ -408,6 +419,12 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		if entry.OutputCostPerImage != nil {
 			pricing.OutputCostPerImage = *entry.OutputCostPerImage
 		}
+		if entry.InputCostPerSecond != nil {
+			pricing.InputCostPerSecond = *entry.InputCostPerSecond
+		}
+		if entry.InputCostPerCharacter != nil {
+			pricing.InputCostPerCharacter = *entry.InputCostPerCharacter
+		}
 
 		result[modelName] = pricing
 	}
@@ -548,6 +565,11 @@ func (s *PricingService) GetModelPricing(modelName string) *LiteLLMModelPricing
 		}
 	}
 
+	// 音频模型静态价格回退（需在模糊匹配前，避免 tts-1 误匹配 tts-1-hd）
+	if pricing, ok := openAIAudioFallbackPricing[lookupCandidates[0]]; ok {
+		return pricing
+	}
+
 	// 3. 尝试模糊匹配（去掉版本号后缀）
 	// claude-opus-4-5-20251101 -> claude-opus-4.5
 	baseName := s.extractBaseName(lookupCandidates[0])
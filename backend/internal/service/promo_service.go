@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ var (
 type PromoService struct {
 	promoRepo            PromoCodeRepository
 	userRepo             UserRepository
+	balanceLedgerRepo    BalanceLedgerRepository
 	billingCacheService  *BillingCacheService
 	entClient            *dbent.Client
 	authCacheInvalidator APIKeyAuthCacheInvalidator
@@ -35,6 +37,7 @@ type PromoService struct {
 func NewPromoService(
 	promoRepo PromoCodeRepository,
 	userRepo UserRepository,
+	balanceLedgerRepo BalanceLedgerRepository,
 	billingCacheService *BillingCacheService,
 	entClient *dbent.Client,
 	authCacheInvalidator APIKeyAuthCacheInvalidator,
@@ -42,6 +45,7 @@ func NewPromoService(
 	return &PromoService{
 		promoRepo:            promoRepo,
 		userRepo:             userRepo,
+		balanceLedgerRepo:    balanceLedgerRepo,
 		billingCacheService:  billingCacheService,
 		entClient:            entClient,
 		authCacheInvalidator: authCacheInvalidator,
@@ -123,8 +127,16 @@ func (s *PromoService) ApplyPromoCode(ctx context.Context, userID int64, code st
 		return ErrPromoCodeAlreadyUsed
 	}
 
-	// 增加用户余额
-	if err := s.userRepo.UpdateBalance(txCtx, userID, promoCode.BonusAmount); err != nil {
+	// 增加用户余额并记录账本流水
+	if err := s.balanceLedgerRepo.Apply(txCtx, &BalanceLedgerEntry{
+		UserID:        userID,
+		EntryType:     BalanceLedgerEntryPromo,
+		Amount:        promoCode.BonusAmount,
+		ReferenceType: BalanceLedgerRefPromoCode,
+		ReferenceID:   strconv.FormatInt(promoCode.ID, 10),
+		ActorType:     BalanceLedgerActorUser,
+		ActorID:       &userID,
+	}); err != nil {
 		return fmt.Errorf("update user balance: %w", err)
 	}
 
@@ -119,6 +119,15 @@ func (s *RateLimitService) SetTokenCacheInvalidator(invalidator TokenCacheInvali
 	s.tokenCacheInvalidator = invalidator
 }
 
+// EnableSharedAccountHealth 启用多实例共享的熔断状态与健康度计数（gateway.scheduling.account_health_mode=redis）。
+func (s *RateLimitService) EnableSharedAccountHealth(ctx context.Context, store AccountHealthStore, syncInterval time.Duration) {
+	if s == nil || store == nil {
+		return
+	}
+	s.circuitBreaker.EnableDistributed(ctx, store, syncInterval)
+	s.healthTracker.EnableDistributed(ctx, store)
+}
+
 // CircuitBreaker 返回账号熔断器实例（供调度选号时检查）。
 func (s *RateLimitService) CircuitBreaker() *AccountCircuitBreaker {
 	if s == nil {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -50,11 +51,7 @@ type RedeemCodeRepository interface {
 	List(ctx context.Context, params pagination.PaginationParams) ([]RedeemCode, *pagination.PaginationResult, error)
 	ListWithFilters(ctx context.Context, params pagination.PaginationParams, codeType, status, search string) ([]RedeemCode, *pagination.PaginationResult, error)
 	ListByUser(ctx context.Context, userID int64, limit int) ([]RedeemCode, error)
-	// ListByUserPaginated returns paginated balance/concurrency history for a specific user.
-	// codeType filter is optional - pass empty string to return all types.
-	ListByUserPaginated(ctx context.Context, userID int64, params pagination.PaginationParams, codeType string) ([]RedeemCode, *pagination.PaginationResult, error)
-	// SumPositiveBalanceByUser returns the total recharged amount (sum of positive balance values) for a user.
-	SumPositiveBalanceByUser(ctx context.Context, userID int64) (float64, error)
+	ListByIDs(ctx context.Context, ids []int64) ([]RedeemCode, error)
 }
 
 // GenerateCodesRequest 生成兑换码请求
@@ -76,6 +73,7 @@ type RedeemCodeResponse struct {
 type RedeemService struct {
 	redeemRepo           RedeemCodeRepository
 	userRepo             UserRepository
+	balanceLedgerRepo    BalanceLedgerRepository
 	subscriptionService  *SubscriptionService
 	planRepo             SubscriptionPlanRepository
 	cache                RedeemCache
@@ -89,6 +87,7 @@ type RedeemService struct {
 func NewRedeemService(
 	redeemRepo RedeemCodeRepository,
 	userRepo UserRepository,
+	balanceLedgerRepo BalanceLedgerRepository,
 	subscriptionService *SubscriptionService,
 	planRepo SubscriptionPlanRepository,
 	cache RedeemCache,
@@ -100,6 +99,7 @@ func NewRedeemService(
 	return &RedeemService{
 		redeemRepo:           redeemRepo,
 		userRepo:             userRepo,
+		balanceLedgerRepo:    balanceLedgerRepo,
 		subscriptionService:  subscriptionService,
 		planRepo:             planRepo,
 		cache:                cache,
@@ -356,8 +356,16 @@ func (s *RedeemService) Redeem(ctx context.Context, userID int64, code string) (
 	// 执行兑换逻辑（兑换码已被锁定，此时可安全操作）
 	switch redeemCode.Type {
 	case RedeemTypeBalance:
-		// 增加用户余额
-		if err := s.userRepo.UpdateBalance(txCtx, userID, redeemCode.Value); err != nil {
+		// 增加用户余额并记录账本流水
+		if err := s.balanceLedgerRepo.Apply(txCtx, &BalanceLedgerEntry{
+			UserID:        userID,
+			EntryType:     BalanceLedgerEntryRedeem,
+			Amount:        redeemCode.Value,
+			ReferenceType: BalanceLedgerRefRedeemCode,
+			ReferenceID:   strconv.FormatInt(redeemCode.ID, 10),
+			ActorType:     BalanceLedgerActorUser,
+			ActorID:       &userID,
+		}); err != nil {
 			return nil, fmt.Errorf("update user balance: %w", err)
 		}
 
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// MaxHedgeBudgetPercent 分组对冲预算上限（百分比）
+const MaxHedgeBudgetPercent = 100
+
+// HedgePlan 单次请求的对冲计划：主请求在 Delay 内未产生首字节时可发起对冲。
+type HedgePlan struct {
+	GroupID       int64
+	Delay         time.Duration
+	latencyKey    string
+	budgetPercent float64
+}
+
+// RequestHedgeService 对冲请求策略。
+// 按分组+模型+是否流式统计近期首字节延迟，取分位数作为对冲延迟；
+// 按分组预算限制对冲请求占比，避免额外请求过度消耗上游配额。
+// 统计数据仅保存在本实例内存中。
+type RequestHedgeService struct {
+	cfg *config.Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	latencies map[string]*hedgeLatencyWindow
+	budgets   map[int64]*hedgeBudgetWindow
+}
+
+type hedgeLatencyWindow struct {
+	samples []float64
+	next    int
+	full    bool
+}
+
+type hedgeBudgetWindow struct {
+	startedAt time.Time
+	requests  int64
+	hedges    int64
+}
+
+// NewRequestHedgeService 创建对冲请求策略服务
+func NewRequestHedgeService(cfg *config.Config) *RequestHedgeService {
+	return &RequestHedgeService{
+		cfg:       cfg,
+		now:       time.Now,
+		latencies: make(map[string]*hedgeLatencyWindow),
+		budgets:   make(map[int64]*hedgeBudgetWindow),
+	}
+}
+
+func (s *RequestHedgeService) hedgingConfig() *config.GatewayHedgingConfig {
+	if s == nil || s.cfg == nil || !s.cfg.Gateway.Hedging.Enabled {
+		return nil
+	}
+	return &s.cfg.Gateway.Hedging
+}
+
+// Plan 为请求生成对冲计划，并计入分组预算的请求数；全局关闭或分组未配置预算时返回 nil。
+func (s *RequestHedgeService) Plan(group *Group, model string, stream bool) *HedgePlan {
+	cfg := s.hedgingConfig()
+	if cfg == nil || group == nil || group.ID <= 0 || group.HedgeBudgetPercent <= 0 {
+		return nil
+	}
+	key := strconv.FormatInt(group.ID, 10) + "|" + model + "|" + strconv.FormatBool(stream)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budgetWindowLocked(group.ID, cfg).requests++
+	return &HedgePlan{
+		GroupID:       group.ID,
+		Delay:         s.delayLocked(key, cfg),
+		latencyKey:    key,
+		budgetPercent: normalizeHedgeBudgetPercent(group.HedgeBudgetPercent),
+	}
+}
+
+// TryAcquireBudget 尝试占用一次对冲预算：窗口内对冲数不得超过请求数 × 预算百分比。
+func (s *RequestHedgeService) TryAcquireBudget(plan *HedgePlan) bool {
+	cfg := s.hedgingConfig()
+	if cfg == nil || plan == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.budgetWindowLocked(plan.GroupID, cfg)
+	if float64(window.hedges+1) > float64(window.requests)*plan.budgetPercent/100 {
+		return false
+	}
+	window.hedges++
+	return true
+}
+
+// ObserveFirstByte 记录一次成功请求的首字节延迟（非流式为完整响应耗时）。
+func (s *RequestHedgeService) ObserveFirstByte(plan *HedgePlan, latency time.Duration) {
+	cfg := s.hedgingConfig()
+	if cfg == nil || plan == nil || latency <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.latencies[plan.latencyKey]
+	if window == nil {
+		window = &hedgeLatencyWindow{samples: make([]float64, cfg.SampleSize)}
+		s.latencies[plan.latencyKey] = window
+	}
+	window.samples[window.next] = float64(latency.Milliseconds())
+	window.next++
+	if window.next == len(window.samples) {
+		window.next = 0
+		window.full = true
+	}
+}
+
+func (s *RequestHedgeService) budgetWindowLocked(groupID int64, cfg *config.GatewayHedgingConfig) *hedgeBudgetWindow {
+	now := s.now()
+	window := s.budgets[groupID]
+	if window == nil || now.Sub(window.startedAt) >= time.Duration(cfg.BudgetWindowSeconds)*time.Second {
+		window = &hedgeBudgetWindow{startedAt: now}
+		s.budgets[groupID] = window
+	}
+	return window
+}
+
+func (s *RequestHedgeService) delayLocked(key string, cfg *config.GatewayHedgingConfig) time.Duration {
+	delayMs := float64(cfg.InitialDelayMs)
+	if window := s.latencies[key]; window != nil {
+		n := window.next
+		if window.full {
+			n = len(window.samples)
+		}
+		if n > 0 && n >= cfg.MinSamples {
+			sorted := make([]float64, n)
+			copy(sorted, window.samples[:n])
+			sort.Float64s(sorted)
+			idx := int(math.Ceil(cfg.DelayPercentile/100*float64(n))) - 1
+			if idx < 0 {
+				idx = 0
+			}
+			delayMs = sorted[idx]
+		}
+	}
+	delayMs = math.Max(delayMs, float64(cfg.MinDelayMs))
+	if cfg.MaxDelayMs > 0 {
+		delayMs = math.Min(delayMs, float64(cfg.MaxDelayMs))
+	}
+	return time.Duration(delayMs) * time.Millisecond
+}
+
+func normalizeHedgeBudgetPercent(v float64) float64 {
+	if v <= 0 || math.IsNaN(v) {
+		return 0
+	}
+	if v > MaxHedgeBudgetPercent {
+		return MaxHedgeBudgetPercent
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestHedgeTestService() *RequestHedgeService {
+	cfg := &config.Config{}
+	cfg.Gateway.Hedging = config.GatewayHedgingConfig{
+		Enabled:             true,
+		DelayPercentile:     90,
+		MinDelayMs:          100,
+		MaxDelayMs:          5000,
+		InitialDelayMs:      3000,
+		MinSamples:          10,
+		SampleSize:          20,
+		BudgetWindowSeconds: 60,
+	}
+	return NewRequestHedgeService(cfg)
+}
+
+func TestRequestHedgeService_PlanDisabled(t *testing.T) {
+	svc := newRequestHedgeTestService()
+	require.Nil(t, svc.Plan(&Group{ID: 1}, "claude-sonnet-4", false), "zero budget disables hedging")
+	require.Nil(t, svc.Plan(nil, "claude-sonnet-4", false))
+
+	var nilSvc *RequestHedgeService
+	require.Nil(t, nilSvc.Plan(&Group{ID: 1, HedgeBudgetPercent: 10}, "m", false))
+	require.False(t, nilSvc.TryAcquireBudget(&HedgePlan{}))
+
+	svc.cfg.Gateway.Hedging.Enabled = false
+	require.Nil(t, svc.Plan(&Group{ID: 1, HedgeBudgetPercent: 10}, "m", false))
+}
+
+func TestRequestHedgeService_DelayFromPercentile(t *testing.T) {
+	svc := newRequestHedgeTestService()
+	group := &Group{ID: 1, HedgeBudgetPercent: 10}
+
+	plan := svc.Plan(group, "m", true)
+	require.Equal(t, 3*time.Second, plan.Delay, "initial delay before enough samples")
+
+	for i := 1; i <= 10; i++ {
+		svc.ObserveFirstByte(plan, time.Duration(i*200)*time.Millisecond)
+	}
+	require.Equal(t, 1800*time.Millisecond, svc.Plan(group, "m", true).Delay)
+	require.Equal(t, 3*time.Second, svc.Plan(group, "m", false).Delay, "samples are tracked per stream mode")
+
+	for i := 0; i < 20; i++ {
+		svc.ObserveFirstByte(plan, 10*time.Millisecond)
+	}
+	require.Equal(t, 100*time.Millisecond, svc.Plan(group, "m", true).Delay, "old samples roll out and min delay applies")
+
+	for i := 0; i < 20; i++ {
+		svc.ObserveFirstByte(plan, time.Minute)
+	}
+	require.Equal(t, 5*time.Second, svc.Plan(group, "m", true).Delay)
+}
+
+func TestRequestHedgeService_Budget(t *testing.T) {
+	svc := newRequestHedgeTestService()
+	now := time.Unix(1_700_000_000, 0)
+	svc.now = func() time.Time { return now }
+	group := &Group{ID: 2, HedgeBudgetPercent: 20}
+
+	var plan *HedgePlan
+	for i := 0; i < 10; i++ {
+		plan = svc.Plan(group, "m", false)
+	}
+	require.True(t, svc.TryAcquireBudget(plan))
+	require.True(t, svc.TryAcquireBudget(plan))
+	require.False(t, svc.TryAcquireBudget(plan), "20% of 10 requests allows 2 hedges")
+
+	now = now.Add(time.Minute)
+	plan = svc.Plan(group, "m", false)
+	require.False(t, svc.TryAcquireBudget(plan), "new window starts from zero requests")
+	for i := 0; i < 4; i++ {
+		plan = svc.Plan(group, "m", false)
+	}
+	require.True(t, svc.TryAcquireBudget(plan))
+}
+
+func TestNormalizeHedgeBudgetPercent(t *testing.T) {
+	require.Zero(t, normalizeHedgeBudgetPercent(-5))
+	require.Equal(t, 12.5, normalizeHedgeBudgetPercent(12.5))
+	require.Equal(t, float64(MaxHedgeBudgetPercent), normalizeHedgeBudgetPercent(250))
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// ResponseCacheHeader 响应缓存状态响应头（hit/miss）
+	ResponseCacheHeader = "x-sub2api-cache"
+
+	DefaultResponseCacheTTLSeconds    = 3600
+	MaxResponseCacheTTLSeconds        = 7 * 24 * 3600
+	DefaultResponseCacheHitMultiplier = 0.1
+
+	defaultResponseCacheMaxEntryBytes = 2 * 1024 * 1024
+)
+
+// responseCacheVolatileFields 不影响上游输出、但会随客户端会话变化的请求字段，
+// 计算指纹前剔除，避免同一请求因 metadata/user 不同而无法命中。
+var responseCacheVolatileFields = []string{"metadata", "user"}
+
+// ResponseCacheEntry 缓存的响应：按客户端协议原样保存（流式请求保存完整 SSE 字节流）。
+type ResponseCacheEntry struct {
+	StatusCode          int       `json:"status_code"`
+	ContentType         string    `json:"content_type"`
+	Body                []byte    `json:"body"`
+	Stream              bool      `json:"stream"`
+	Model               string    `json:"model"`
+	BillingModel        string    `json:"billing_model,omitempty"`
+	UpstreamModel       string    `json:"upstream_model,omitempty"`
+	AccountID           int64     `json:"account_id"`
+	InputTokens         int       `json:"input_tokens"`
+	OutputTokens        int       `json:"output_tokens"`
+	CacheCreationTokens int       `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int       `json:"cache_read_tokens,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// ResponseCache 响应缓存存储（Redis）
+type ResponseCache interface {
+	// Get 读取缓存；未命中返回 nil, nil
+	Get(ctx context.Context, groupID int64, fingerprint string) (*ResponseCacheEntry, error)
+	Set(ctx context.Context, groupID int64, fingerprint string, entry *ResponseCacheEntry, ttl time.Duration) error
+	// PurgeGroup 删除指定分组的全部缓存，返回删除条数
+	PurgeGroup(ctx context.Context, groupID int64) (int64, error)
+	// PurgeAll 删除全部分组的缓存，返回删除条数
+	PurgeAll(ctx context.Context) (int64, error)
+}
+
+// ResponseCacheHitInfo 标记一次请求由响应缓存直接返回，计费时按 Multiplier 折算原始费用
+type ResponseCacheHitInfo struct {
+	Multiplier float64
+}
+
+type responseCacheHitContextKey struct{}
+
+// WithResponseCacheHit 在 context 中标记响应缓存命中信息。
+func WithResponseCacheHit(ctx context.Context, info *ResponseCacheHitInfo) context.Context {
+	if ctx == nil || info == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, responseCacheHitContextKey{}, info)
+}
+
+// ResponseCacheHitFromContext 读取响应缓存命中信息；非缓存命中请求返回 nil。
+func ResponseCacheHitFromContext(ctx context.Context) *ResponseCacheHitInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(responseCacheHitContextKey{}).(*ResponseCacheHitInfo)
+	return info
+}
+
+// applyResponseCacheHitBilling 对缓存命中请求按分组配置的倍率折算费用，并标记缓存命中。
+func applyResponseCacheHitBilling(ctx context.Context, usageLog *UsageLog, p *postUsageBillingParams) {
+	info := ResponseCacheHitFromContext(ctx)
+	if info == nil {
+		return
+	}
+	if usageLog != nil {
+		usageLog.ResponseCacheHit = true
+	}
+	if info.Multiplier < 0 || info.Multiplier == 1 {
+		return
+	}
+	scaleUsageBillingCost(usageLog, p, info.Multiplier)
+}
+
+// tagUsageLogWithResponseCacheHit 标记缓存命中请求的使用记录；计费类型保持订阅/余额/后付费不变，
+// 后付费账单等按计费类型汇总的统计不会把预付费期间的缓存命中计入。
+func tagUsageLogWithResponseCacheHit(ctx context.Context, usageLog *UsageLog) {
+	if usageLog != nil && ResponseCacheHitFromContext(ctx) != nil {
+		usageLog.ResponseCacheHit = true
+	}
+}
+
+func normalizeResponseCacheTTLSeconds(v *int) int {
+	if v == nil || *v <= 0 {
+		return DefaultResponseCacheTTLSeconds
+	}
+	if *v > MaxResponseCacheTTLSeconds {
+		return MaxResponseCacheTTLSeconds
+	}
+	return *v
+}
+
+func normalizeResponseCacheHitMultiplier(v *float64) float64 {
+	if v == nil || *v < 0 || math.IsNaN(*v) || math.IsInf(*v, 0) {
+		return DefaultResponseCacheHitMultiplier
+	}
+	return *v
+}
+
+// ResponseCacheService 分组级精确匹配响应缓存。
+// 仅缓存确定性请求（显式 temperature=0），按规范化后的请求体指纹命中。
+type ResponseCacheService struct {
+	cache ResponseCache
+	cfg   *config.Config
+}
+
+// NewResponseCacheService 创建响应缓存服务
+func NewResponseCacheService(cache ResponseCache, cfg *config.Config) *ResponseCacheService {
+	return &ResponseCacheService{cache: cache, cfg: cfg}
+}
+
+// Enabled 判断分组是否开启响应缓存
+func (s *ResponseCacheService) Enabled(group *Group) bool {
+	return s != nil && s.cache != nil && group != nil && group.ID > 0 && group.ResponseCacheEnabled
+}
+
+// MaxEntryBytes 返回单条缓存响应体上限
+func (s *ResponseCacheService) MaxEntryBytes() int {
+	if s != nil && s.cfg != nil && s.cfg.Gateway.ResponseCacheMaxEntryBytes > 0 {
+		return s.cfg.Gateway.ResponseCacheMaxEntryBytes
+	}
+	return defaultResponseCacheMaxEntryBytes
+}
+
+// Get 按指纹读取分组缓存；读取失败按未命中处理。
+func (s *ResponseCacheService) Get(ctx context.Context, group *Group, fingerprint string) *ResponseCacheEntry {
+	if !s.Enabled(group) || fingerprint == "" {
+		return nil
+	}
+	entry, err := s.cache.Get(ctx, group.ID, fingerprint)
+	if err != nil {
+		logger.LegacyPrintf("service.response_cache", "get failed: group=%d err=%v", group.ID, err)
+		return nil
+	}
+	return entry
+}
+
+// Store 写入分组缓存（失败仅记录日志）。
+func (s *ResponseCacheService) Store(ctx context.Context, group *Group, fingerprint string, entry *ResponseCacheEntry) {
+	if !s.Enabled(group) || fingerprint == "" || entry == nil || len(entry.Body) == 0 || len(entry.Body) > s.MaxEntryBytes() {
+		return
+	}
+	ttl := normalizeResponseCacheTTLSeconds(&group.ResponseCacheTTLSeconds)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if err := s.cache.Set(ctx, group.ID, fingerprint, entry, time.Duration(ttl)*time.Second); err != nil {
+		logger.LegacyPrintf("service.response_cache", "set failed: group=%d err=%v", group.ID, err)
+	}
+}
+
+// HitMultiplier 返回分组缓存命中计费倍率
+func (s *ResponseCacheService) HitMultiplier(group *Group) float64 {
+	if group == nil {
+		return DefaultResponseCacheHitMultiplier
+	}
+	return normalizeResponseCacheHitMultiplier(&group.ResponseCacheHitMultiplier)
+}
+
+// PurgeGroup 清空指定分组的响应缓存
+func (s *ResponseCacheService) PurgeGroup(ctx context.Context, groupID int64) (int64, error) {
+	if s == nil || s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.PurgeGroup(ctx, groupID)
+}
+
+// PurgeAll 清空全部响应缓存
+func (s *ResponseCacheService) PurgeAll(ctx context.Context) (int64, error) {
+	if s == nil || s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.PurgeAll(ctx)
+}
+
+var errResponseCacheNotDeterministic = errors.New("request is not deterministic")
+
+// ResponseCacheFingerprint 计算请求的规范化指纹。
+// 仅显式设置 temperature=0（且 n 不大于 1）的请求可缓存；
+// 指纹基于端点 + 键排序后的请求体（剔除 metadata/user，stream=false 视同未设置）。
+func ResponseCacheFingerprint(endpoint string, body []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil {
+		return "", err
+	}
+	if !isDeterministicRequest(payload) {
+		return "", errResponseCacheNotDeterministic
+	}
+	// temperature 已确认为 0，统一写法避免 0 / 0.0 产生不同指纹
+	payload["temperature"] = json.Number("0")
+	for _, key := range responseCacheVolatileFields {
+		delete(payload, key)
+	}
+	if stream, ok := payload["stream"].(bool); ok && !stream {
+		delete(payload, "stream")
+	}
+	canonical, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return HashUsageRequestPayload(append([]byte(endpoint+"\n"), canonical...)), nil
+}
+
+func isDeterministicRequest(payload map[string]any) bool {
+	temperature, ok := payload["temperature"].(json.Number)
+	if !ok {
+		return false
+	}
+	if v, err := temperature.Float64(); err != nil || v != 0 {
+		return false
+	}
+	if n, ok := payload["n"].(json.Number); ok {
+		if v, err := n.Int64(); err != nil || v > 1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type responseCacheStub struct {
+	entries map[string]*ResponseCacheEntry
+	lastTTL time.Duration
+}
+
+func newResponseCacheStub() *responseCacheStub {
+	return &responseCacheStub{entries: map[string]*ResponseCacheEntry{}}
+}
+
+func (s *responseCacheStub) Get(_ context.Context, _ int64, fingerprint string) (*ResponseCacheEntry, error) {
+	return s.entries[fingerprint], nil
+}
+
+func (s *responseCacheStub) Set(_ context.Context, _ int64, fingerprint string, entry *ResponseCacheEntry, ttl time.Duration) error {
+	s.entries[fingerprint] = entry
+	s.lastTTL = ttl
+	return nil
+}
+
+func (s *responseCacheStub) PurgeGroup(context.Context, int64) (int64, error) {
+	n := int64(len(s.entries))
+	s.entries = map[string]*ResponseCacheEntry{}
+	return n, nil
+}
+
+func (s *responseCacheStub) PurgeAll(ctx context.Context) (int64, error) {
+	return s.PurgeGroup(ctx, 0)
+}
+
+func TestResponseCacheFingerprint(t *testing.T) {
+	fp1, err := ResponseCacheFingerprint("/v1/messages", []byte(`{"model":"claude-sonnet-4","temperature":0,"messages":[{"role":"user","content":"hi"}],"metadata":{"user_id":"a"}}`))
+	require.NoError(t, err)
+	fp2, err := ResponseCacheFingerprint("/v1/messages", []byte(`{"messages":[{"role":"user","content":"hi"}],"stream":false,"temperature":0.0,"model":"claude-sonnet-4","metadata":{"user_id":"b"}}`))
+	require.NoError(t, err)
+	require.Equal(t, fp1, fp2, "key order, metadata and stream=false must not affect the fingerprint")
+
+	fp3, err := ResponseCacheFingerprint("/v1/chat/completions", []byte(`{"model":"claude-sonnet-4","temperature":0,"messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	require.NotEqual(t, fp1, fp3, "endpoint is part of the fingerprint")
+
+	fp4, err := ResponseCacheFingerprint("/v1/messages", []byte(`{"model":"claude-sonnet-4","temperature":0,"stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	require.NotEqual(t, fp1, fp4)
+
+	for _, body := range []string{
+		`{"model":"m","messages":[]}`,
+		`{"model":"m","temperature":0.7,"messages":[]}`,
+		`{"model":"m","temperature":0,"n":2,"messages":[]}`,
+		`not json`,
+	} {
+		_, err := ResponseCacheFingerprint("/v1/messages", []byte(body))
+		require.Error(t, err, body)
+	}
+}
+
+func TestResponseCacheService_StoreAndGet(t *testing.T) {
+	stub := newResponseCacheStub()
+	svc := NewResponseCacheService(stub, &config.Config{Gateway: config.GatewayConfig{ResponseCacheMaxEntryBytes: 16}})
+	group := &Group{ID: 7, ResponseCacheEnabled: true, ResponseCacheTTLSeconds: 60, ResponseCacheHitMultiplier: 0.2}
+	ctx := context.Background()
+
+	require.False(t, svc.Enabled(&Group{ID: 7}))
+	require.Nil(t, svc.Get(ctx, &Group{ID: 7}, "fp"))
+
+	svc.Store(ctx, group, "fp", &ResponseCacheEntry{Body: []byte("0123456789abcdefX")})
+	require.Nil(t, svc.Get(ctx, group, "fp"), "oversized responses must not be cached")
+
+	svc.Store(ctx, group, "fp", &ResponseCacheEntry{Body: []byte(`{"ok":true}`), Model: "m"})
+	entry := svc.Get(ctx, group, "fp")
+	require.NotNil(t, entry)
+	require.Equal(t, "m", entry.Model)
+	require.False(t, entry.CreatedAt.IsZero())
+	require.Equal(t, 60*time.Second, stub.lastTTL)
+	require.InDelta(t, 0.2, svc.HitMultiplier(group), 1e-12)
+
+	n, err := svc.PurgeGroup(ctx, group.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	require.Nil(t, svc.Get(ctx, group, "fp"))
+}
+
+func TestApplyResponseCacheHitBilling(t *testing.T) {
+	cost := &CostBreakdown{InputCost: 1, OutputCost: 3, TotalCost: 4, ActualCost: 4}
+	usageLog := &UsageLog{InputCost: 1, OutputCost: 3, TotalCost: 4, ActualCost: 4, BillingType: BillingTypePostpaid}
+	p := &postUsageBillingParams{Cost: cost}
+
+	applyResponseCacheHitBilling(context.Background(), usageLog, p)
+	require.Same(t, cost, p.Cost)
+	require.False(t, usageLog.ResponseCacheHit)
+
+	ctx := WithResponseCacheHit(context.Background(), &ResponseCacheHitInfo{Multiplier: 0.1})
+	applyResponseCacheHitBilling(ctx, usageLog, p)
+	require.True(t, usageLog.ResponseCacheHit)
+	// 计费类型保持实际扣费方式，后付费账单只汇总后付费期间的命中
+	require.Equal(t, BillingTypePostpaid, usageLog.BillingType)
+	require.InDelta(t, 0.4, p.Cost.ActualCost, 1e-12)
+	require.InDelta(t, 0.4, usageLog.TotalCost, 1e-12)
+	require.InDelta(t, 4, cost.ActualCost, 1e-12, "original breakdown must stay untouched")
+}
+
+func TestNormalizeResponseCacheSettings(t *testing.T) {
+	neg := -1
+	huge := MaxResponseCacheTTLSeconds + 1
+	ok := 120
+	require.Equal(t, DefaultResponseCacheTTLSeconds, normalizeResponseCacheTTLSeconds(nil))
+	require.Equal(t, DefaultResponseCacheTTLSeconds, normalizeResponseCacheTTLSeconds(&neg))
+	require.Equal(t, MaxResponseCacheTTLSeconds, normalizeResponseCacheTTLSeconds(&huge))
+	require.Equal(t, 120, normalizeResponseCacheTTLSeconds(&ok))
+
+	negMultiplier := -0.5
+	zero := 0.0
+	require.InDelta(t, DefaultResponseCacheHitMultiplier, normalizeResponseCacheHitMultiplier(nil), 1e-12)
+	require.InDelta(t, DefaultResponseCacheHitMultiplier, normalizeResponseCacheHitMultiplier(&negMultiplier), 1e-12)
+	require.Zero(t, normalizeResponseCacheHitMultiplier(&zero))
+}
@@ -825,41 +825,11 @@ func firstMediaURL(urls []string) string {
 }
 
 func (s *SoraGatewayService) buildSoraMediaURL(path string, rawQuery string) string {
-	if path == "" {
-		return path
+	var cfg *config.Config
+	if s != nil {
+		cfg = s.cfg
 	}
-	prefix := "/sora/media"
-	values := url.Values{}
-	if rawQuery != "" {
-		if parsed, err := url.ParseQuery(rawQuery); err == nil {
-			values = parsed
-		}
-	}
-
-	signKey := ""
-	ttlSeconds := 0
-	if s != nil && s.cfg != nil {
-		signKey = strings.TrimSpace(s.cfg.Gateway.SoraMediaSigningKey)
-		ttlSeconds = s.cfg.Gateway.SoraMediaSignedURLTTLSeconds
-	}
-	values.Del("sig")
-	values.Del("expires")
-	signingQuery := values.Encode()
-	if signKey != "" && ttlSeconds > 0 {
-		expires := time.Now().Add(time.Duration(ttlSeconds) * time.Second).Unix()
-		signature := SignSoraMediaURL(path, signingQuery, expires, signKey)
-		if signature != "" {
-			values.Set("expires", strconv.FormatInt(expires, 10))
-			values.Set("sig", signature)
-			prefix = "/sora/media-signed"
-		}
-	}
-
-	encoded := values.Encode()
-	if encoded == "" {
-		return prefix + path
-	}
-	return prefix + path + "?" + encoded
+	return buildSoraMediaURL(cfg, path, rawQuery)
 }
 
 func (s *SoraGatewayService) prepareSoraStream(c *gin.Context, requestID string) {
@@ -153,7 +153,6 @@ func (r *stubUserRepoForQuota) List(context.Context, pagination.PaginationParams
 func (r *stubUserRepoForQuota) ListWithFilters(context.Context, pagination.PaginationParams, UserListFilters) ([]User, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
-func (r *stubUserRepoForQuota) UpdateBalance(context.Context, int64, float64) error { return nil }
 func (r *stubUserRepoForQuota) DeductBalance(context.Context, int64, float64) error { return nil }
 func (r *stubUserRepoForQuota) UpdateConcurrency(context.Context, int64, int) error { return nil }
 func (r *stubUserRepoForQuota) ExistsByEmail(context.Context, string) (bool, error) {
@@ -4,8 +4,12 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
 )
 
 // SignSoraMediaURL 生成 Sora 媒体临时签名
@@ -46,3 +50,42 @@ func buildSoraMediaSignPayload(path string, query string) string {
 	}
 	return path + "?" + query
 }
+
+// buildSoraMediaURL 生成本地媒体访问路径；配置了签名密钥与有效期时返回 /sora/media-signed 临时签名地址。
+func buildSoraMediaURL(cfg *config.Config, path string, rawQuery string) string {
+	if path == "" {
+		return path
+	}
+	prefix := "/sora/media"
+	values := url.Values{}
+	if rawQuery != "" {
+		if parsed, err := url.ParseQuery(rawQuery); err == nil {
+			values = parsed
+		}
+	}
+
+	signKey := ""
+	ttlSeconds := 0
+	if cfg != nil {
+		signKey = strings.TrimSpace(cfg.Gateway.SoraMediaSigningKey)
+		ttlSeconds = cfg.Gateway.SoraMediaSignedURLTTLSeconds
+	}
+	values.Del("sig")
+	values.Del("expires")
+	signingQuery := values.Encode()
+	if signKey != "" && ttlSeconds > 0 {
+		expires := time.Now().Add(time.Duration(ttlSeconds) * time.Second).Unix()
+		signature := SignSoraMediaURL(path, signingQuery, expires, signKey)
+		if signature != "" {
+			values.Set("expires", strconv.FormatInt(expires, 10))
+			values.Set("sig", signature)
+			prefix = "/sora/media-signed"
+		}
+	}
+
+	encoded := values.Encode()
+	if encoded == "" {
+		return prefix + path
+	}
+	return prefix + path + "?" + encoded
+}
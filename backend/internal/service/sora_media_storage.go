@@ -144,6 +144,48 @@ func (s *SoraMediaStorage) StoreFromURLs(ctx context.Context, mediaType string,
 	return results, nil
 }
 
+// StoreBytes 将内存中的媒体（如 Images API 生成的图片）落地，返回相对路径
+func (s *SoraMediaStorage) StoreBytes(mediaType, ext string, data []byte) (string, error) {
+	if s == nil || !s.Enabled() {
+		return "", errors.New("media storage is not enabled")
+	}
+	if !s.ready {
+		if err := s.EnsureLocalDirs(); err != nil {
+			return "", err
+		}
+	}
+	if s.maxDownloadBytes > 0 && int64(len(data)) > s.maxDownloadBytes {
+		return "", fmt.Errorf("media size exceeds limit: %d", len(data))
+	}
+	root := s.imageRoot
+	if mediaType == "video" {
+		root = s.videoRoot
+	}
+	ext = normalizeSoraFileExt(ext)
+	if ext == "" {
+		ext = ".bin"
+	}
+
+	storageRoot, err := os.OpenRoot(root)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = storageRoot.Close() }()
+
+	datePath := time.Now().Format("2006/01/02")
+	datePathFS := filepath.FromSlash(datePath)
+	if err := storageRoot.MkdirAll(datePathFS, 0o755); err != nil {
+		return "", err
+	}
+	filename := uuid.NewString() + ext
+	filePath := filepath.Join(datePathFS, filename)
+	if err := storageRoot.WriteFile(filePath, data, 0o644); err != nil {
+		removePartialDownload(storageRoot, filePath)
+		return "", err
+	}
+	return path.Join("/", mediaType, datePath, filename), nil
+}
+
 // TotalSizeByRelativePaths 统计本地存储路径总大小（仅统计 /image 和 /video 路径）。
 func (s *SoraMediaStorage) TotalSizeByRelativePaths(paths []string) (int64, error) {
 	if s == nil || len(paths) == 0 {
@@ -211,6 +211,7 @@ func (s *TokenRefreshService) processRefresh() {
 			if err := s.refreshWithRetry(ctx, account, refresher, executor, refreshWindow); err != nil {
 				if errors.Is(err, errRefreshSkipped) {
 					skipped++
+					recordTokenRefreshMetrics(account.Platform, tokenRefreshResultSkipped)
 				} else {
 					slog.Warn("token_refresh.account_refresh_failed",
 						"account_id", account.ID,
@@ -218,6 +219,7 @@ func (s *TokenRefreshService) processRefresh() {
 						"error", err,
 					)
 					failed++
+					recordTokenRefreshMetrics(account.Platform, tokenRefreshResultFailed)
 				}
 			} else {
 				slog.Info("token_refresh.account_refreshed",
@@ -225,6 +227,7 @@ func (s *TokenRefreshService) processRefresh() {
 					"account_name", account.Name,
 				)
 				refreshed++
+				recordTokenRefreshMetrics(account.Platform, tokenRefreshResultRefreshed)
 			}
 
 			// 每个账号只由一个refresher处理
@@ -3,6 +3,7 @@ package service
 import (
 	"encoding/json"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
@@ -125,3 +126,84 @@ func anthropicLikeErrorTypeForStatus(status int) string {
 		return "upstream_error"
 	}
 }
+
+// 载荷抓取脱敏规则：JSON 字段名沿用 isSensitiveKey（凭据类字段整体替换），
+// 字符串内容再按以下规则替换凭据与 PII；URL 查询参数中的凭据沿用 sanitizeUpstreamErrorMessage。
+var payloadCaptureRedactionRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/=-]{8,}`), "Bearer [REDACTED]"},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), "[REDACTED_API_KEY]"},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), "[REDACTED_API_KEY]"},
+	{regexp.MustCompile(`\bya29\.[0-9A-Za-z._-]+`), "[REDACTED_TOKEN]"},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`), "[REDACTED_JWT]"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+}
+
+func redactPayloadCaptureText(text string) string {
+	if text == "" {
+		return text
+	}
+	text = sanitizeUpstreamErrorMessage(text)
+	for _, rule := range payloadCaptureRedactionRules {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	return text
+}
+
+func redactPayloadCaptureJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, vv := range t {
+			if isSensitiveKey(k) {
+				out[k] = "[REDACTED]"
+				continue
+			}
+			out[k] = redactPayloadCaptureJSON(vv)
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(t))
+		for _, vv := range t {
+			out = append(out, redactPayloadCaptureJSON(vv))
+		}
+		return out
+	case string:
+		return redactPayloadCaptureText(t)
+	default:
+		return v
+	}
+}
+
+// redactPayloadCaptureBody 合法 JSON 返回脱敏后的结构，其它内容（截断的 JSON、SSE 文本）按文本脱敏
+func redactPayloadCaptureBody(body []byte) any {
+	if len(body) == 0 {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		return redactPayloadCaptureJSON(decoded)
+	}
+	return redactPayloadCaptureText(string(body))
+}
+
+func redactPayloadCaptureHeaders(headers http.Header) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(headers))
+	for key, values := range headers {
+		normalized := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+		switch {
+		case normalized == "cookie" || normalized == "set_cookie":
+			out[key] = "[REDACTED]"
+		case isSensitiveKey(normalized) || strings.HasSuffix(normalized, "_key"):
+			out[key] = redactAuthHeaderValue(strings.Join(values, ", "))
+		default:
+			out[key] = redactPayloadCaptureText(strings.Join(values, ", "))
+		}
+	}
+	return out
+}
@@ -9,8 +9,20 @@ import (
 const (
 	BillingTypeBalance      int8 = 0 // 钱包余额
 	BillingTypeSubscription int8 = 1 // 订阅套餐
+	BillingTypePostpaid     int8 = 3 // 后付费（余额透支，按月出账）
 )
 
+// usageBillingType 使用记录的计费类型：有订阅走订阅；后付费用户的余额扣费（不含按次配额）记为后付费
+func usageBillingType(user *User, apiKey *APIKey, subscription *UserSubscription) int8 {
+	if subscription != nil {
+		return BillingTypeSubscription
+	}
+	if user != nil && user.IsPostpaid() && (apiKey == nil || !apiKey.HasRemainingEffectiveRequestQuota()) {
+		return BillingTypePostpaid
+	}
+	return BillingTypeBalance
+}
+
 type RequestType int16
 
 const (
@@ -153,6 +165,25 @@ type UsageLog struct {
 	ImageSize  *string
 	MediaType  *string
 
+	// 音频字段：转写/翻译按时长计费，语音合成按输入字符数计费
+	AudioDurationSeconds float64
+	AudioCharacters      int
+
+	// Batch 字段（仅 batch 执行器发起的请求）
+	BatchID       *string
+	BatchCustomID *string
+	// BatchMultiplier batch 计费折扣倍率快照
+	BatchMultiplier *float64
+
+	// TraceID OpenTelemetry trace id（请求未携带有效 trace 时为空）
+	TraceID *string
+
+	// PriceSource 计费所用价格来源：group / litellm / fallback（按次、图片等非 token 计费为空）
+	PriceSource *string
+
+	// ResponseCacheHit 是否为响应缓存命中（计费类型仍按订阅/余额/后付费记录）
+	ResponseCacheHit bool
+
 	CreatedAt time.Time
 
 	User         *User
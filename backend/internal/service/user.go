@@ -33,6 +33,11 @@ type User struct {
 	SoraStorageQuotaBytes int64 // 用户级 Sora 存储配额（0 表示使用分组或系统默认值）
 	SoraStorageUsedBytes  int64 // Sora 存储已用量
 
+	// 后付费（月结）配置
+	BillingMode     string  // prepaid / postpaid
+	CreditLimit     float64 // 后付费信用额度：余额最低可透支至 -CreditLimit
+	BillingCycleDay int     // 账单日（1-28），每月该日 00:00 (UTC) 结束上一账期
+
 	// TOTP 双因素认证字段
 	TotpSecretEncrypted *string    // AES-256-GCM 加密的 TOTP 密钥
 	TotpEnabled         bool       // 是否启用 TOTP
@@ -50,6 +55,19 @@ func (u *User) IsActive() bool {
 	return u.Status == StatusActive
 }
 
+// IsPostpaid 是否为后付费（月结）用户
+func (u *User) IsPostpaid() bool {
+	return u.BillingMode == BillingModePostpaid
+}
+
+// CreditHeadroom 余额可透支的额度；预付费用户为 0
+func (u *User) CreditHeadroom() float64 {
+	if !u.IsPostpaid() || u.CreditLimit < 0 {
+		return 0
+	}
+	return u.CreditLimit
+}
+
 // CanBindGroup checks whether a user can bind to a given group.
 // For standard groups:
 // - Public groups (non-exclusive): all users can bind
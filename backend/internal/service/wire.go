@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
+	dbent "github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/google/wire"
@@ -131,6 +133,20 @@ func ProvideDashboardAggregationService(repo DashboardAggregationRepository, tim
 	return svc
 }
 
+// ProvideBatchService 创建并启动 OpenAI Batch API 执行服务
+func ProvideBatchService(repo BatchRepository, store BatchFileStore, apiKeyRepo APIKeyRepository, timingWheel *TimingWheelService, cfg *config.Config) *BatchService {
+	svc := NewBatchService(repo, store, apiKeyRepo, timingWheel, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvidePayloadCaptureService 创建并启动运维载荷抓取服务
+func ProvidePayloadCaptureService(opsRepo OpsRepository, settingRepo SettingRepository, store PayloadCaptureStore, cfg *config.Config) *PayloadCaptureService {
+	svc := NewPayloadCaptureService(opsRepo, settingRepo, store, cfg)
+	svc.Start()
+	return svc
+}
+
 // ProvideUsageCleanupService 创建并启动使用记录清理任务服务
 func ProvideUsageCleanupService(repo UsageCleanupRepository, timingWheel *TimingWheelService, dashboardAgg *DashboardAggregationService, cfg *config.Config) *UsageCleanupService {
 	svc := NewUsageCleanupService(repo, timingWheel, dashboardAgg, cfg)
@@ -214,12 +230,17 @@ func ProvideRateLimitService(
 	settingService *SettingService,
 	tokenCacheInvalidator TokenCacheInvalidator,
 	accountThrottleService *AccountThrottleService,
+	accountHealthStore AccountHealthStore,
 ) *RateLimitService {
 	svc := NewRateLimitService(accountRepo, usageRepo, cfg, geminiQuotaService, tempUnschedCache, NewAccountCircuitBreaker())
 	svc.SetTimeoutCounterCache(timeoutCounterCache)
 	svc.SetSettingService(settingService)
 	svc.SetTokenCacheInvalidator(tokenCacheInvalidator)
 	svc.SetAccountThrottleService(accountThrottleService)
+	if cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Gateway.Scheduling.AccountHealthMode), config.AccountHealthModeRedis) {
+		syncInterval := time.Duration(cfg.Gateway.Scheduling.AccountHealthSyncIntervalSeconds) * time.Second
+		svc.EnableSharedAccountHealth(context.Background(), accountHealthStore, syncInterval)
+	}
 	return svc
 }
 
@@ -256,10 +277,11 @@ func ProvideOpsAlertEvaluatorService(
 	opsService *OpsService,
 	opsRepo OpsRepository,
 	emailService *EmailService,
+	notificationService *OpsNotificationService,
 	redisClient *redis.Client,
 	cfg *config.Config,
 ) *OpsAlertEvaluatorService {
-	svc := NewOpsAlertEvaluatorService(opsService, opsRepo, emailService, redisClient, cfg)
+	svc := NewOpsAlertEvaluatorService(opsService, opsRepo, emailService, notificationService, redisClient, cfg)
 	svc.Start()
 	return svc
 }
@@ -276,10 +298,22 @@ func ProvideOpsCleanupService(
 	return svc
 }
 
-func ProvideOpsSystemLogSink(opsRepo OpsRepository) *OpsSystemLogSink {
+// ProvideLogShippingService 创建并启动外部日志投递（未配置 sink 时为空操作）
+func ProvideLogShippingService(cfg *config.Config) *LogShippingService {
+	svc := NewLogShippingService(cfg)
+	svc.Start()
+	return svc
+}
+
+func ProvideOpsSystemLogSink(opsRepo OpsRepository, shipping *LogShippingService) *OpsSystemLogSink {
 	sink := NewOpsSystemLogSink(opsRepo)
+	sink.shipping = shipping
 	sink.Start()
-	logger.SetSink(sink)
+	if shipping.Enabled() {
+		logger.SetSink(logger.MultiSink(sink, shipping))
+	} else {
+		logger.SetSink(sink)
+	}
 	return sink
 }
 
@@ -340,6 +374,34 @@ func ProvideSystemOperationLockService(repo IdempotencyRepository, cfg *config.C
 	return NewSystemOperationLockService(repo, buildIdempotencyConfig(cfg))
 }
 
+// ProvideBalanceLedgerService creates and starts BalanceLedgerService.
+func ProvideBalanceLedgerService(
+	repo BalanceLedgerRepository,
+	userRepo UserRepository,
+	notificationService *OpsNotificationService,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) *BalanceLedgerService {
+	svc := NewBalanceLedgerService(repo, userRepo, notificationService, redisClient, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvidePostpaidBillingService creates and starts PostpaidBillingService.
+func ProvidePostpaidBillingService(
+	repo PostpaidStatementRepository,
+	userRepo UserRepository,
+	balanceLedgerRepo BalanceLedgerRepository,
+	billingCacheService *BillingCacheService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+	entClient *dbent.Client,
+	cfg *config.Config,
+) *PostpaidBillingService {
+	svc := NewPostpaidBillingService(repo, userRepo, balanceLedgerRepo, billingCacheService, authCacheInvalidator, entClient, cfg)
+	svc.Start()
+	return svc
+}
+
 func ProvideIdempotencyCleanupService(repo IdempotencyRepository, cfg *config.Config) *IdempotencyCleanupService {
 	svc := NewIdempotencyCleanupService(repo, cfg)
 	svc.Start()
@@ -384,10 +446,12 @@ func ProvideOpsScheduledReportService(
 	opsService *OpsService,
 	userService *UserService,
 	emailService *EmailService,
+	notificationService *OpsNotificationService,
+	backupService *BackupService,
 	redisClient *redis.Client,
 	cfg *config.Config,
 ) *OpsScheduledReportService {
-	svc := NewOpsScheduledReportService(opsService, userService, emailService, redisClient, cfg)
+	svc := NewOpsScheduledReportService(opsService, userService, emailService, notificationService, backupService, redisClient, cfg)
 	svc.Start()
 	return svc
 }
@@ -435,6 +499,7 @@ var ProviderSet = wire.NewSet(
 	NewAccountService,
 	NewProxyService,
 	NewRedeemService,
+	NewPaymentService,
 	NewPromoService,
 	NewUsageService,
 	NewDashboardService,
@@ -464,14 +529,19 @@ var ProviderSet = wire.NewSet(
 	ProvideOpenAITokenProvider,
 	ProvideClaudeTokenProvider,
 	NewAntigravityGatewayService,
+	NewImageGenerationService,
+	NewResponseCacheService,
+	NewRequestHedgeService,
 	ProvideRateLimitService,
 	NewAccountUsageService,
 	NewAccountTestService,
 	ProvideSettingService,
 	NewDataManagementService,
 	ProvideBackupService,
+	ProvideLogShippingService,
 	ProvideOpsSystemLogSink,
 	NewOpsService,
+	NewOpsNotificationService,
 	ProvideOpsMetricsCollector,
 	ProvideOpsAggregationService,
 	ProvideOpsAlertEvaluatorService,
@@ -495,6 +565,8 @@ var ProviderSet = wire.NewSet(
 	ProvideTimingWheelService,
 	ProvideDashboardAggregationService,
 	ProvideUsageCleanupService,
+	ProvideBatchService,
+	ProvidePayloadCaptureService,
 	ProvideDeferredService,
 	NewAntigravityQuotaFetcher,
 	NewUserAttributeService,
@@ -504,10 +576,12 @@ var ProviderSet = wire.NewSet(
 	NewAccountThrottleService,
 	ProvideAccountThrottleRecoveryService,
 	NewTLSFingerprintProfileService,
-	NewDigestSessionStore,
+	ProvideDigestSessionStore,
 	ProvideIdempotencyCoordinator,
 	ProvideSystemOperationLockService,
 	ProvideIdempotencyCleanupService,
+	ProvideBalanceLedgerService,
+	ProvidePostpaidBillingService,
 	ProvideScheduledTestService,
 	ProvideScheduledTestRunnerService,
 	NewGroupCapacityService,
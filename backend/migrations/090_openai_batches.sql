@@ -0,0 +1,50 @@
+-- 090_openai_batches.sql
+-- OpenAI Batch API：上传文件(batch_files) + 批处理任务(batch_jobs)
+
+-- 1. 上传/输出文件元数据（文件内容存放在本地磁盘或 S3）
+CREATE TABLE IF NOT EXISTS batch_files (
+  id VARCHAR(64) PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  api_key_id BIGINT NOT NULL,
+  purpose VARCHAR(32) NOT NULL,
+  filename VARCHAR(255) NOT NULL DEFAULT '',
+  bytes BIGINT NOT NULL DEFAULT 0,
+  storage_key TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_files_user_created ON batch_files(user_id, created_at DESC);
+
+-- 2. 批处理任务
+CREATE TABLE IF NOT EXISTS batch_jobs (
+  id VARCHAR(64) PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  api_key_id BIGINT NOT NULL,
+  group_id BIGINT,
+  endpoint VARCHAR(64) NOT NULL,
+  input_file_id VARCHAR(64) NOT NULL,
+  output_file_id VARCHAR(64),
+  error_file_id VARCHAR(64),
+  completion_window VARCHAR(16) NOT NULL DEFAULT '24h',
+  status VARCHAR(20) NOT NULL DEFAULT 'validating',
+  total_count INT NOT NULL DEFAULT 0,
+  completed_count INT NOT NULL DEFAULT 0,
+  failed_count INT NOT NULL DEFAULT 0,
+  metadata JSONB,
+  errors JSONB,
+  client_ip VARCHAR(64) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  in_progress_at TIMESTAMPTZ,
+  finalizing_at TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  failed_at TIMESTAMPTZ,
+  cancelling_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ,
+  expired_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_user_created ON batch_jobs(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_status_created ON batch_jobs(status, created_at);
@@ -0,0 +1,13 @@
+-- 091_anthropic_message_batches.sql
+-- Anthropic Message Batches 复用 batch_jobs 执行器；usage_logs 记录 batch 来源与折扣倍率
+
+-- 1. 区分 OpenAI Batch 与 Anthropic Message Batch（决定 ID 前缀、结果格式与对外接口）
+ALTER TABLE batch_jobs ADD COLUMN IF NOT EXISTS api_format VARCHAR(16) NOT NULL DEFAULT 'openai';
+
+DROP INDEX IF EXISTS idx_batch_jobs_user_created;
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_user_format_created ON batch_jobs(user_id, api_format, created_at DESC);
+
+-- 2. 使用记录标记 batch 请求
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS batch_id VARCHAR(64);
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS batch_custom_id VARCHAR(128);
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS batch_multiplier DECIMAL(10,4);
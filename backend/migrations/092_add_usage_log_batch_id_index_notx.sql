@@ -0,0 +1,4 @@
+-- Support usage log lookups by batch job id.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_usage_logs_batch_id
+ON usage_logs (batch_id)
+WHERE batch_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- 093_usage_log_audio_fields.sql
+-- 音频接口计费字段：转写/翻译按音频时长（秒），语音合成按输入字符数
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS audio_duration_seconds DECIMAL(12,3) NOT NULL DEFAULT 0;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS audio_characters INT NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- 094_group_response_cache.sql
+-- 分组级精确匹配响应缓存：开关、有效期与缓存命中计费倍率；使用记录单独标记缓存命中
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS response_cache_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS response_cache_ttl_seconds INT NOT NULL DEFAULT 3600;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS response_cache_hit_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.1;
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS response_cache_hit BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- 095_group_hedge_budget.sql
+-- 分组级对冲请求预算：对冲（额外发起的第二次上游请求）占请求数的百分比上限，0 表示关闭
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS hedge_budget_percent DECIMAL(5,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- 096_group_schedule_strategy.sql
+-- 分组级账号调度策略：priority_lru / least_load / ewma_latency / cost_aware，空字符串表示使用网关默认策略
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS schedule_strategy VARCHAR(32) NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- 097_usage_log_trace_id.sql
+-- 使用记录关联 OpenTelemetry trace id，便于从账单记录跳转到分布式追踪
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS trace_id VARCHAR(32);
@@ -0,0 +1,54 @@
+-- 098_ops_notification_channels.sql
+-- 运维告警通知渠道：webhook / Slack / 飞书 / 钉钉 / 企业微信 / Telegram，规则按渠道路由并记录投递日志
+
+CREATE TABLE IF NOT EXISTS ops_notification_channels (
+    id BIGSERIAL PRIMARY KEY,
+
+    name VARCHAR(128) NOT NULL,
+    channel_type VARCHAR(32) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- 渠道连接参数：url / secret / bot_token / chat_id / headers
+    config JSONB NOT NULL DEFAULT '{}'::jsonb,
+    -- 可选的 text/template 消息模板，空表示使用内置模板
+    template TEXT NOT NULL DEFAULT '',
+    -- 最低投递级别（P0-P3），空表示不过滤
+    min_severity VARCHAR(16) NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ops_notification_channels_name_unique
+    ON ops_notification_channels (name);
+
+ALTER TABLE ops_alert_rules ADD COLUMN IF NOT EXISTS notify_channel_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS ops_notification_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+
+    -- 渠道被删除后保留投递记录，因此不建外键并冗余名称/类型
+    channel_id BIGINT NOT NULL,
+    channel_name VARCHAR(128) NOT NULL DEFAULT '',
+    channel_type VARCHAR(32) NOT NULL DEFAULT '',
+
+    alert_event_id BIGINT REFERENCES ops_alert_events(id) ON DELETE CASCADE,
+    source VARCHAR(32) NOT NULL,
+
+    status VARCHAR(16) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    http_status INT,
+    error_message TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_notification_deliveries_event
+    ON ops_notification_deliveries (alert_event_id)
+    WHERE alert_event_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ops_notification_deliveries_channel_created
+    ON ops_notification_deliveries (channel_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_ops_notification_deliveries_created_at
+    ON ops_notification_deliveries (created_at);
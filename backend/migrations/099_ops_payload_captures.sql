@@ -0,0 +1,32 @@
+-- 099_ops_payload_captures.sql
+-- 请求/响应载荷抽样抓取：载荷脱敏压缩后存放在本地或 S3，此表记录元数据并用于按 TTL 清理
+
+CREATE TABLE IF NOT EXISTS ops_payload_captures (
+    -- 与请求追踪事件中的 capture_id 对应
+    id VARCHAR(64) PRIMARY KEY,
+
+    client_request_id VARCHAR(64) NOT NULL DEFAULT '',
+    rule_name VARCHAR(128) NOT NULL DEFAULT '',
+    storage_key TEXT NOT NULL,
+
+    platform VARCHAR(32) NOT NULL DEFAULT '',
+    model VARCHAR(128) NOT NULL DEFAULT '',
+    request_path VARCHAR(256) NOT NULL DEFAULT '',
+    status_code INT NOT NULL DEFAULT 0,
+    user_id BIGINT,
+    api_key_id BIGINT,
+    group_id BIGINT,
+
+    -- 压缩后的对象大小；truncated 表示至少一段载荷超过 max_body_bytes 被截断
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT false,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_payload_captures_client_request_id
+    ON ops_payload_captures (client_request_id);
+
+CREATE INDEX IF NOT EXISTS idx_ops_payload_captures_expires_at
+    ON ops_payload_captures (expires_at);
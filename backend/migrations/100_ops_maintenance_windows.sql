@@ -0,0 +1,18 @@
+-- 100_ops_maintenance_windows.sql
+-- 运维计划维护窗口：展示在公开状态页上，窗口内受影响平台的组件状态显示为维护中
+
+CREATE TABLE IF NOT EXISTS ops_maintenance_windows (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- 受影响的平台，空数组表示全部平台
+    platforms TEXT[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT ops_maintenance_windows_time_range CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_maintenance_windows_ends_at
+    ON ops_maintenance_windows (ends_at);
@@ -0,0 +1,55 @@
+-- 101_payment_orders.sql
+-- 原生支付订单：用户自助充值余额或购买订阅计划，支付回调到账后自动发放权益，退款/拒付时回收
+
+CREATE TABLE IF NOT EXISTS payment_orders (
+    id BIGSERIAL PRIMARY KEY,
+    order_no VARCHAR(64) NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- balance: 充值余额；subscription: 购买订阅计划
+    kind VARCHAR(20) NOT NULL,
+    plan_id BIGINT,
+    -- 支付渠道（stripe / epay / webhook / fake）及子渠道（如 epay 的 alipay / wxpay）
+    provider VARCHAR(32) NOT NULL,
+    channel VARCHAR(32) NOT NULL DEFAULT '',
+    amount DECIMAL(20,2) NOT NULL,
+    currency VARCHAR(8) NOT NULL,
+    -- 到账余额（USD），仅 kind=balance 时有效
+    credit_amount DECIMAL(20,8) NOT NULL DEFAULT 0,
+    -- pending / paid / expired / canceled / refunded / charged_back
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    provider_trade_no VARCHAR(128) NOT NULL DEFAULT '',
+    payment_url TEXT NOT NULL DEFAULT '',
+    -- 订阅发放结果，退款时据此回收
+    subscription_id BIGINT,
+    granted_days INT NOT NULL DEFAULT 0,
+    refund_reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    refunded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_orders_order_no
+    ON payment_orders (order_no);
+
+CREATE INDEX IF NOT EXISTS idx_payment_orders_user_created
+    ON payment_orders (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_payment_orders_provider_trade_no
+    ON payment_orders (provider, provider_trade_no)
+    WHERE provider_trade_no <> '';
+
+CREATE INDEX IF NOT EXISTS idx_payment_orders_pending_expires
+    ON payment_orders (expires_at)
+    WHERE status = 'pending';
+
+-- 订阅计划售价（订阅计划表本身不含价格）
+CREATE TABLE IF NOT EXISTS payment_plan_prices (
+    plan_id BIGINT PRIMARY KEY REFERENCES subscription_plans(id) ON DELETE CASCADE,
+    price DECIMAL(20,2) NOT NULL,
+    currency VARCHAR(8) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT payment_plan_prices_positive CHECK (price > 0)
+);
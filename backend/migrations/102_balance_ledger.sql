@@ -0,0 +1,69 @@
+-- 102_balance_ledger.sql
+-- 余额流水账本：记录每一次余额变动（充值、兑换、优惠码、管理员调整、请求扣费汇总、退款等），只追加不修改，
+-- 用于对账与用户账单。请求扣费先按用户累计到 balance_ledger_usage_pending，再由后台任务周期性汇总写入账本。
+
+CREATE TABLE IF NOT EXISTS balance_ledger (
+    id BIGSERIAL PRIMARY KEY,
+    -- 不设外键：用户被物理删除后流水仍需保留
+    user_id BIGINT NOT NULL,
+    -- opening / topup / redeem / promo / admin_adjust / usage / refund / chargeback
+    entry_type VARCHAR(32) NOT NULL,
+    -- 本次变动金额（USD），正数为入账，负数为扣减
+    amount DECIMAL(20,8) NOT NULL,
+    -- 变动后的账本余额（上一条流水的 balance_after + amount，不含尚未汇总的请求扣费）
+    balance_after DECIMAL(20,8) NOT NULL,
+    -- 关联对象（redeem_code / promo_code / payment_order / admin_adjustment 等）
+    reference_type VARCHAR(32) NOT NULL DEFAULT '',
+    reference_id VARCHAR(128) NOT NULL DEFAULT '',
+    -- 操作方：system / user / admin / provider
+    actor_type VARCHAR(20) NOT NULL DEFAULT 'system',
+    actor_id BIGINT,
+    -- usage 汇总条目包含的请求数与时间范围
+    request_count BIGINT NOT NULL DEFAULT 0,
+    period_start TIMESTAMPTZ,
+    period_end TIMESTAMPTZ,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_user_id ON balance_ledger (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_type_created ON balance_ledger (entry_type, created_at DESC);
+-- 同一业务对象的同类流水只能记录一次，防止重复入账
+CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_ledger_reference
+    ON balance_ledger (user_id, entry_type, reference_type, reference_id)
+    WHERE reference_id <> '';
+
+-- 账本只允许追加
+CREATE OR REPLACE FUNCTION balance_ledger_reject_mutation() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'balance_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_balance_ledger_append_only ON balance_ledger;
+CREATE TRIGGER trg_balance_ledger_append_only
+    BEFORE UPDATE OR DELETE ON balance_ledger
+    FOR EACH ROW EXECUTE FUNCTION balance_ledger_reject_mutation();
+
+DROP TRIGGER IF EXISTS trg_balance_ledger_no_truncate ON balance_ledger;
+CREATE TRIGGER trg_balance_ledger_no_truncate
+    BEFORE TRUNCATE ON balance_ledger
+    FOR EACH STATEMENT EXECUTE FUNCTION balance_ledger_reject_mutation();
+
+-- 尚未汇总入账的请求扣费（每用户一行，与 users.balance 在同一事务中累加）
+CREATE TABLE IF NOT EXISTS balance_ledger_usage_pending (
+    user_id BIGINT PRIMARY KEY,
+    amount DECIMAL(20,8) NOT NULL DEFAULT 0,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    first_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_usage_pending_first_at ON balance_ledger_usage_pending (first_at);
+
+-- 期初余额：上线前的余额无法追溯来源，以 opening 流水作为账本起点
+INSERT INTO balance_ledger (user_id, entry_type, amount, balance_after, reference_type, reference_id, actor_type, notes)
+SELECT u.id, 'opening', u.balance, u.balance, 'migration', '102', 'system', 'opening balance at ledger rollout'
+FROM users u
+WHERE u.balance <> 0
+  AND NOT EXISTS (SELECT 1 FROM balance_ledger l WHERE l.user_id = u.id);
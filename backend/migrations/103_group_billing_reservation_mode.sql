@@ -0,0 +1,4 @@
+-- 103_group_billing_reservation_mode.sql
+-- 分组级计费预留模式：optimistic（默认，请求完成后扣费）/ strict（请求前按预估最大费用预留，防止并发透支）
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS billing_reservation_mode VARCHAR(16) NOT NULL DEFAULT 'optimistic';
@@ -0,0 +1,52 @@
+-- 104_postpaid_billing.sql
+-- 后付费（月结）用户：允许余额透支至信用额度，按账单日汇总上一账期的 usage_logs 出具账单，
+-- 逾期未付时暂停该用户的 API Key，管理员标记付款后恢复。
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS billing_mode VARCHAR(16) NOT NULL DEFAULT 'prepaid';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(20,8) NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS billing_cycle_day INT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_users_billing_mode_postpaid
+    ON users (id)
+    WHERE billing_mode = 'postpaid' AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS postpaid_statements (
+    id BIGSERIAL PRIMARY KEY,
+    statement_no VARCHAR(64) NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- 账期 [period_start, period_end)
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    -- issued / overdue / paid
+    status VARCHAR(20) NOT NULL DEFAULT 'issued',
+    -- 金额（USD）：小计为账期内后付费请求的实际扣费，税额 = 小计 * 税率
+    subtotal DECIMAL(20,8) NOT NULL DEFAULT 0,
+    tax_rate DECIMAL(10,6) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(20,8) NOT NULL DEFAULT 0,
+    total DECIMAL(20,8) NOT NULL DEFAULT 0,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    -- 按 API Key / 分组 / 模型汇总的明细
+    line_items JSONB NOT NULL DEFAULT '[]'::jsonb,
+    due_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    paid_by BIGINT,
+    payment_reference VARCHAR(128) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_postpaid_statements_statement_no
+    ON postpaid_statements (statement_no);
+
+-- 同一用户同一账期只出一张账单
+CREATE UNIQUE INDEX IF NOT EXISTS idx_postpaid_statements_user_period
+    ON postpaid_statements (user_id, period_start);
+
+CREATE INDEX IF NOT EXISTS idx_postpaid_statements_status_due
+    ON postpaid_statements (due_at)
+    WHERE status = 'issued';
+
+CREATE INDEX IF NOT EXISTS idx_postpaid_statements_overdue_user
+    ON postpaid_statements (user_id)
+    WHERE status = 'overdue';
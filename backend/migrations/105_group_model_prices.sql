@@ -0,0 +1,10 @@
+-- 105_group_model_prices.sql
+-- 分组自定义模型价格：按模型模式覆盖全局价格表（USD per MTok），并记录每条使用记录的价格来源
+
+-- 格式: {"model_pattern": {"input_price": 15, "output_price": 75, "cache_read_price": 1.5, "cache_write_price": 18.75}, ...}
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS model_prices JSONB DEFAULT '{}';
+
+COMMENT ON COLUMN groups.model_prices IS '分组模型价格：{"model_pattern": {"input_price": ..., "output_price": ..., "cache_read_price": ..., "cache_write_price": ...}}，支持通配符匹配，未设置的项沿用全局价格';
+
+-- 价格来源：group / litellm / fallback（历史记录为空）
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS price_source VARCHAR(16);